@@ -0,0 +1,219 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termdash
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// timeNow is used to timestamp the audit records, can be replaced in tests.
+var timeNow = time.Now
+
+// AuditAction is the kind of the user action in an AuditRecord.
+type AuditAction string
+
+// AuditAction values.
+const (
+	// AuditKey is a pressed key that doesn't type a character, e.g. KeyEnter
+	// or KeyCtrlS.
+	AuditKey AuditAction = "key"
+	// AuditText is a typed printable character. Only the number of typed
+	// characters is recorded, since they can be e.g. a password.
+	AuditText AuditAction = "text"
+	// AuditClick is a pressed mouse button. Dragging the mouse with the
+	// button held down is recorded as a single click.
+	AuditClick AuditAction = "click"
+	// AuditWheel is a turn of the mouse wheel.
+	AuditWheel AuditAction = "wheel"
+	// AuditPaste is pasted text.
+	AuditPaste AuditAction = "paste"
+	// AuditCommit is text committed by an input method editor. The
+	// compositions that precede it aren't recorded.
+	AuditCommit AuditAction = "commit"
+	// AuditFocus is a change of the focused container.
+	AuditFocus AuditAction = "focus"
+	// AuditCommand is a completed key sequence bound to a function, either
+	// via the KeyBindings option or the container.KeyBindings option.
+	AuditCommand AuditAction = "command"
+	// AuditLock is the dashboard getting locked, see LockOnIdle.
+	AuditLock AuditAction = "lock"
+	// AuditUnlock is the dashboard getting unlocked.
+	AuditUnlock AuditAction = "unlock"
+	// AuditUnlockFailed is a rejected passphrase.
+	AuditUnlockFailed AuditAction = "unlock_failed"
+)
+
+// AuditRecord is a user action recorded by the AuditLog option. Each record
+// is written as a JSON object on a separate line, fields that don't apply to
+// the action are omitted.
+type AuditRecord struct {
+	// Time is when the action happened.
+	Time time.Time `json:"time"`
+	// Action is the kind of the action.
+	Action AuditAction `json:"action"`
+	// Key is the pressed key, set for AuditKey.
+	Key string `json:"key,omitempty"`
+	// Button is the mouse button, set for AuditClick and AuditWheel.
+	Button string `json:"button,omitempty"`
+	// Position is the position of the mouse on the terminal, set for
+	// AuditClick and AuditWheel.
+	Position *image.Point `json:"position,omitempty"`
+	// Length is the number of characters of the typed, pasted or committed
+	// text, set for AuditText, AuditPaste and AuditCommit. The text itself
+	// isn't recorded.
+	Length int `json:"length,omitempty"`
+	// Container is the ID of the focused container, set for AuditFocus.
+	// Empty if the container doesn't have an ID.
+	Container string `json:"container,omitempty"`
+	// Keys is the key sequence of the command, set for AuditCommand.
+	Keys string `json:"keys,omitempty"`
+	// Description is the description of the command, set for AuditCommand
+	// if the key binding has one.
+	Description string `json:"description,omitempty"`
+}
+
+// auditLog writes the audit records.
+// This object is thread-safe.
+type auditLog struct {
+	// enc encodes the records to the writer.
+	enc *json.Encoder
+	// button is the last mouse button, used to record a drag only once.
+	button mouse.Button
+
+	// mu protects the auditLog.
+	mu sync.Mutex
+}
+
+// newAuditLog returns a new auditLog that writes to the writer.
+func newAuditLog(w io.Writer) *auditLog {
+	return &auditLog{
+		enc:    json.NewEncoder(w),
+		button: mouse.ButtonRelease,
+	}
+}
+
+// record timestamps and writes the record.
+func (al *auditLog) record(r *AuditRecord) error {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	r.Time = timeNow()
+	if err := al.enc.Encode(r); err != nil {
+		return fmt.Errorf("failed to write the audit record: %v", err)
+	}
+	return nil
+}
+
+// input records the input event. Mouse events other than button presses and
+// the wheel aren't recorded. Typed, pasted and committed text is recorded
+// only by its length.
+func (al *auditLog) input(ev terminalapi.Event) error {
+	switch e := ev.(type) {
+	case *terminalapi.Keyboard:
+		if e.Key >= 0 && unicode.IsPrint(rune(e.Key)) {
+			return al.record(&AuditRecord{
+				Action: AuditText,
+				Length: 1,
+			})
+		}
+		return al.record(&AuditRecord{
+			Action: AuditKey,
+			Key:    e.Key.String(),
+		})
+
+	case *terminalapi.Mouse:
+		al.mu.Lock()
+		prev := al.button
+		al.button = e.Button
+		al.mu.Unlock()
+
+		r := &AuditRecord{
+			Button:   e.Button.String(),
+			Position: &image.Point{e.Position.X, e.Position.Y},
+		}
+		switch e.Button {
+		case mouse.ButtonLeft, mouse.ButtonRight, mouse.ButtonMiddle:
+			if e.Button == prev {
+				return nil
+			}
+			r.Action = AuditClick
+		case mouse.ButtonWheelUp, mouse.ButtonWheelDown:
+			r.Action = AuditWheel
+		default:
+			return nil
+		}
+		return al.record(r)
+
+	case *terminalapi.Paste:
+		return al.record(&AuditRecord{
+			Action: AuditPaste,
+			Length: len([]rune(e.Text)),
+		})
+
+	case *terminalapi.Commit:
+		return al.record(&AuditRecord{
+			Action: AuditCommit,
+			Length: len([]rune(e.Text)),
+		})
+	}
+	return nil
+}
+
+// audit records the action if the AuditLog option was provided.
+func (td *termdash) audit(r *AuditRecord) {
+	if td.auditLog == nil {
+		return
+	}
+	if err := td.auditLog.record(r); err != nil {
+		td.handleError(err)
+	}
+}
+
+// auditInput records the input event if the AuditLog option was provided.
+func (td *termdash) auditInput(ev terminalapi.Event) {
+	if td.auditLog == nil {
+		return
+	}
+	if err := td.auditLog.input(ev); err != nil {
+		td.handleError(err)
+	}
+}
+
+// auditFocus records the change of the focused container.
+func (td *termdash) auditFocus(id string) {
+	td.audit(&AuditRecord{
+		Action:    AuditFocus,
+		Container: id,
+	})
+}
+
+// auditCommand records the completed key binding.
+func (td *termdash) auditCommand(b keybinding.Binding) {
+	td.audit(&AuditRecord{
+		Action:      AuditCommand,
+		Keys:        b.Sequence.String(),
+		Description: b.Description,
+	})
+}
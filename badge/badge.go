@@ -0,0 +1,213 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package badge implements small indicators drawn over a corner of a
+// container, e.g. the count of unread items or a colored dot that signals an
+// alert. See container.Badge.
+package badge
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/private/wrap"
+)
+
+// Corner identifies the corner the badge is drawn in.
+type Corner int
+
+// String implements fmt.Stringer()
+func (c Corner) String() string {
+	if n, ok := cornerNames[c]; ok {
+		return n
+	}
+	return "CornerUnknown"
+}
+
+// cornerNames maps Corner values to human readable names.
+var cornerNames = map[Corner]string{
+	CornerTopRight:    "CornerTopRight",
+	CornerTopLeft:     "CornerTopLeft",
+	CornerBottomRight: "CornerBottomRight",
+	CornerBottomLeft:  "CornerBottomLeft",
+}
+
+const (
+	// CornerTopRight is the top right corner.
+	CornerTopRight Corner = iota
+	// CornerTopLeft is the top left corner.
+	CornerTopLeft
+	// CornerBottomRight is the bottom right corner.
+	CornerBottomRight
+	// CornerBottomLeft is the bottom left corner.
+	CornerBottomLeft
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*Badge)
+}
+
+// option implements Option.
+type option func(*Badge)
+
+// set implements Option.set.
+func (o option) set(b *Badge) {
+	o(b)
+}
+
+// At sets the corner the badge is drawn in.
+// Defaults to CornerTopRight.
+func At(c Corner) Option {
+	return option(func(b *Badge) {
+		b.corner = c
+	})
+}
+
+// CellOpts sets the cell options of the badge.
+// Defaults to white text on red background for text badges and to red
+// foreground color for dots.
+func CellOpts(opts ...cell.Option) Option {
+	return option(func(b *Badge) {
+		b.cellOpts = opts
+	})
+}
+
+// Badge is a short text drawn over a corner of an area.
+// This object is immutable, create a new badge to change it.
+type Badge struct {
+	// text is the text of the badge.
+	text string
+	// padded indicates that the text has one cell of padding on each side.
+	padded bool
+
+	corner   Corner
+	cellOpts []cell.Option
+}
+
+// New returns a badge that displays the text, e.g. "new". The text must fit
+// onto a single line.
+func New(text string, opts ...Option) (*Badge, error) {
+	if text == "" {
+		return nil, errors.New("the text of the badge cannot be empty")
+	}
+	if err := wrap.ValidText(text); err != nil {
+		return nil, fmt.Errorf("invalid badge text: %v", err)
+	}
+	if strings.Contains(text, "\n") {
+		return nil, fmt.Errorf("invalid badge text %q, cannot contain newline characters", text)
+	}
+	return newBadge(text, true, []cell.Option{
+		cell.FgColor(cell.ColorWhite),
+		cell.BgColor(cell.ColorRed),
+	}, opts...)
+}
+
+// MaxCount is the largest count displayed by badges created with Count,
+// larger counts are displayed as MaxCount followed by a plus sign.
+const MaxCount = 99
+
+// Count returns a badge that displays the count, e.g. of unread items.
+// Returns a nil badge if the count is zero or negative, which removes the
+// badge when provided to container.Badge.
+func Count(n int, opts ...Option) (*Badge, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	text := fmt.Sprintf("%d", n)
+	if n > MaxCount {
+		text = fmt.Sprintf("%d+", MaxCount)
+	}
+	return New(text, opts...)
+}
+
+// dotRune is the rune drawn by badges created with Dot.
+const dotRune = '●'
+
+// Dot returns a badge that displays a colored dot, e.g. to signal an alert.
+func Dot(color cell.Color, opts ...Option) (*Badge, error) {
+	return newBadge(string(dotRune), false, []cell.Option{
+		cell.FgColor(color),
+	}, opts...)
+}
+
+// newBadge returns a new badge with the default cell options and the
+// provided options applied.
+func newBadge(text string, padded bool, cellOpts []cell.Option, opts ...Option) (*Badge, error) {
+	b := &Badge{
+		text:     text,
+		padded:   padded,
+		corner:   CornerTopRight,
+		cellOpts: cellOpts,
+	}
+	for _, o := range opts {
+		o.set(b)
+	}
+	if _, ok := cornerNames[b.corner]; !ok {
+		return nil, fmt.Errorf("invalid corner %v", b.corner)
+	}
+	return b, nil
+}
+
+// Text returns the text of the badge.
+func (b *Badge) Text() string {
+	return b.text
+}
+
+// Area returns the area the badge occupies when drawn over the provided
+// area. Returns a zero area if the badge doesn't fit.
+func (b *Badge) Area(ar image.Rectangle) image.Rectangle {
+	width := runewidth.StringWidth(b.text)
+	if b.padded {
+		width += 2
+	}
+	if width > ar.Dx() || ar.Dy() < 1 {
+		return image.ZR
+	}
+
+	switch b.corner {
+	case CornerTopLeft:
+		return image.Rect(ar.Min.X, ar.Min.Y, ar.Min.X+width, ar.Min.Y+1)
+	case CornerBottomRight:
+		return image.Rect(ar.Max.X-width, ar.Max.Y-1, ar.Max.X, ar.Max.Y)
+	case CornerBottomLeft:
+		return image.Rect(ar.Min.X, ar.Max.Y-1, ar.Min.X+width, ar.Max.Y)
+	default:
+		return image.Rect(ar.Max.X-width, ar.Min.Y, ar.Max.X, ar.Min.Y+1)
+	}
+}
+
+// Draw draws the badge onto the canvas, which must have the size of the
+// area returned by Area.
+func (b *Badge) Draw(cvs *canvas.Canvas) error {
+	if err := cvs.SetAreaCells(cvs.Area(), ' ', b.cellOpts...); err != nil {
+		return err
+	}
+	start := image.Point{0, 0}
+	if b.padded {
+		start.X++
+	}
+	return draw.Text(cvs, b.text, start,
+		draw.TextMaxX(cvs.Area().Max.X),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		draw.TextCellOpts(b.cellOpts...),
+	)
+}
@@ -0,0 +1,194 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package badge
+
+import (
+	"image"
+	"testing"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+)
+
+func TestBadge(t *testing.T) {
+	ar := image.Rect(2, 1, 12, 6)
+	tests := []struct {
+		desc     string
+		badge    func() (*Badge, error)
+		wantNil  bool
+		wantText string
+		wantArea image.Rectangle
+		// want is the expected content of the canvas of the badge.
+		want    func(size image.Point) *faketerm.Terminal
+		wantErr bool
+	}{
+		{
+			desc: "fails on empty text",
+			badge: func() (*Badge, error) {
+				return New("")
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on text with a newline",
+			badge: func() (*Badge, error) {
+				return New("a\nb")
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on invalid corner",
+			badge: func() (*Badge, error) {
+				return New("a", At(Corner(-1)))
+			},
+			wantErr: true,
+		},
+		{
+			desc: "zero count has no badge",
+			badge: func() (*Badge, error) {
+				return Count(0)
+			},
+			wantNil: true,
+		},
+		{
+			desc: "count in the top right corner",
+			badge: func() (*Badge, error) {
+				return Count(7)
+			},
+			wantText: "7",
+			wantArea: image.Rect(9, 1, 12, 2),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				opts := []cell.Option{
+					cell.FgColor(cell.ColorWhite),
+					cell.BgColor(cell.ColorRed),
+				}
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ', opts...)
+				testdraw.MustText(cvs, "7", image.Point{1, 0}, draw.TextCellOpts(opts...))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "large count in the top left corner",
+			badge: func() (*Badge, error) {
+				return Count(1000, At(CornerTopLeft), CellOpts(cell.FgColor(cell.ColorBlue)))
+			},
+			wantText: "99+",
+			wantArea: image.Rect(2, 1, 7, 2),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, " 99+ ", image.Point{0, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorBlue)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "dot in the bottom right corner",
+			badge: func() (*Badge, error) {
+				return Dot(cell.ColorGreen, At(CornerBottomRight))
+			},
+			wantText: "●",
+			wantArea: image.Rect(11, 5, 12, 6),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetCell(cvs, image.Point{0, 0}, '●', cell.FgColor(cell.ColorGreen))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "text in the bottom left corner",
+			badge: func() (*Badge, error) {
+				return New("new", At(CornerBottomLeft))
+			},
+			wantText: "new",
+			wantArea: image.Rect(2, 5, 7, 6),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				opts := []cell.Option{
+					cell.FgColor(cell.ColorWhite),
+					cell.BgColor(cell.ColorRed),
+				}
+				testdraw.MustText(cvs, " new ", image.Point{0, 0}, draw.TextCellOpts(opts...))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "text that doesn't fit has no area",
+			badge: func() (*Badge, error) {
+				return New("very long text")
+			},
+			wantText: "very long text",
+			wantArea: image.ZR,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			b, err := tc.badge()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("badge => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if (b == nil) != tc.wantNil {
+				t.Fatalf("badge => %v, wantNil: %v", b, tc.wantNil)
+			}
+			if b == nil {
+				return
+			}
+
+			if got := b.Text(); got != tc.wantText {
+				t.Errorf("Text => %q, want %q", got, tc.wantText)
+			}
+			gotArea := b.Area(ar)
+			if gotArea != tc.wantArea {
+				t.Errorf("Area => %v, want %v", gotArea, tc.wantArea)
+			}
+			if gotArea == image.ZR {
+				return
+			}
+
+			c, err := canvas.New(image.Rectangle{Max: gotArea.Size()})
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := b.Draw(c); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
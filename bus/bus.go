@@ -0,0 +1,164 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bus implements a publish/subscribe bus that lets widgets and
+// application code exchange messages without references to each other.
+//
+// Messages are identified by their Go type. Subscribers register for a type
+// of message by providing a value of that type, e.g. the zero value, and
+// receive all the messages of that type:
+//
+//	b.Subscribe(bus.RangeChanged{}, func(msg interface{}) {
+//		rc := msg.(bus.RangeChanged)
+//		...
+//	})
+//	b.Publish(bus.RangeChanged{Start: start, End: end})
+//
+// Each termdash instance has its own bus, which it provides to the widgets
+// that implement widgetapi.BusUser, see the termdash.MessageBus option.
+// Applications can define their own message types.
+package bus
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Handler is called with the published messages of the type it subscribed
+// to. The handlers are called in the goroutine that publishes the message,
+// so they must be thread-safe and should return quickly. Handlers are
+// allowed to publish messages and to call the other methods of the bus.
+type Handler func(msg interface{})
+
+// subscription is a handler subscribed to a type of message.
+type subscription struct {
+	// msgType is the type of the messages delivered to the handler.
+	msgType reflect.Type
+	// handler receives the messages.
+	handler Handler
+}
+
+// Bus delivers published messages to the subscribers.
+// This object is thread-safe.
+type Bus struct {
+	// mu protects the bus.
+	mu sync.Mutex
+
+	// subs are the subscriptions keyed by their IDs.
+	subs map[int]*subscription
+	// nextID is the ID of the next subscription.
+	nextID int
+	// closed indicates that the bus was closed.
+	closed bool
+}
+
+// New returns a new bus.
+func New() *Bus {
+	return &Bus{
+		subs: map[int]*subscription{},
+	}
+}
+
+// Subscribe registers the handler for messages of the same type as the
+// provided example message.
+// Returns a function that cancels the subscription. Subscribing to a closed
+// bus has no effect.
+func (b *Bus) Subscribe(example interface{}, h Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return func() {}
+	}
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = &subscription{
+		msgType: reflect.TypeOf(example),
+		handler: h,
+	}
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs, id)
+	}
+}
+
+// Publish delivers the message to the handlers subscribed to its type in the
+// order they subscribed. Returns after all the handlers returned. Messages
+// published on a closed bus are dropped.
+func (b *Bus) Publish(msg interface{}) {
+	t := reflect.TypeOf(msg)
+	b.mu.Lock()
+	var ids []int
+	for id, s := range b.subs {
+		if s.msgType == t {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	var handlers []Handler
+	for _, id := range ids {
+		handlers = append(handlers, b.subs[id].handler)
+	}
+	b.mu.Unlock()
+
+	// Mutex must be released when calling the handlers, they might publish
+	// messages themselves.
+	for _, h := range handlers {
+		h(msg)
+	}
+}
+
+// Close removes all the subscriptions, any messages published afterwards are
+// dropped.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.subs = map[int]*subscription{}
+}
+
+// SelectionChanged is published when the user selects items in a widget.
+type SelectionChanged struct {
+	// Source is the widget or the component that published the message.
+	Source interface{}
+	// Selected are the indexes of the selected items.
+	Selected []int
+}
+
+// RangeChanged is published when the time range displayed by the dashboard
+// changes.
+type RangeChanged struct {
+	// Source is the widget or the component that published the message.
+	Source interface{}
+	// Start and End are the bounds of the range.
+	Start, End time.Time
+	// Last is the duration of ranges that end at the current time, zero for
+	// ranges with a fixed start and end.
+	Last time.Duration
+}
+
+// AlertFired is published when a condition that requires attention occurs,
+// e.g. a value crossed a threshold.
+type AlertFired struct {
+	// Source is the widget or the component that published the message.
+	Source interface{}
+	// Name identifies the alert.
+	Name string
+	// Message describes the alert to the user.
+	Message string
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bus
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// recorder records the messages delivered to its handlers.
+type recorder struct {
+	got []string
+}
+
+// handler returns a handler that records the messages it receives.
+func (r *recorder) handler(name string) Handler {
+	return func(msg interface{}) {
+		r.got = append(r.got, fmt.Sprintf("%s: %v", name, msg))
+	}
+}
+
+func TestBus(t *testing.T) {
+	tests := []struct {
+		desc string
+		// run subscribes the recorder and publishes messages.
+		run  func(b *Bus, r *recorder)
+		want []string
+	}{
+		{
+			desc: "no subscribers",
+			run: func(b *Bus, r *recorder) {
+				b.Publish("hello")
+			},
+		},
+		{
+			desc: "delivers messages by type",
+			run: func(b *Bus, r *recorder) {
+				b.Subscribe("", r.handler("string"))
+				b.Subscribe(0, r.handler("int"))
+				b.Subscribe(AlertFired{}, r.handler("alert"))
+				b.Publish("hello")
+				b.Publish(42)
+				b.Publish(AlertFired{Name: "cpu"})
+				b.Publish(3.14)
+			},
+			want: []string{
+				"string: hello",
+				"int: 42",
+				"alert: {<nil> cpu }",
+			},
+		},
+		{
+			desc: "pointers and values are different types",
+			run: func(b *Bus, r *recorder) {
+				b.Subscribe(&SelectionChanged{}, r.handler("pointer"))
+				b.Publish(SelectionChanged{})
+			},
+		},
+		{
+			desc: "delivers in the order of subscription",
+			run: func(b *Bus, r *recorder) {
+				for i := 0; i < 5; i++ {
+					b.Subscribe("", r.handler(fmt.Sprint(i)))
+				}
+				b.Publish("msg")
+			},
+			want: []string{"0: msg", "1: msg", "2: msg", "3: msg", "4: msg"},
+		},
+		{
+			desc: "unsubscribes",
+			run: func(b *Bus, r *recorder) {
+				b.Subscribe("", r.handler("first"))
+				cancel := b.Subscribe("", r.handler("second"))
+				b.Publish("before")
+				cancel()
+				cancel()
+				b.Publish("after")
+			},
+			want: []string{
+				"first: before",
+				"second: before",
+				"first: after",
+			},
+		},
+		{
+			desc: "handlers can publish",
+			run: func(b *Bus, r *recorder) {
+				b.Subscribe("", func(msg interface{}) {
+					b.Publish(len(msg.(string)))
+				})
+				b.Subscribe(0, r.handler("len"))
+				b.Publish("hello")
+			},
+			want: []string{"len: 5"},
+		},
+		{
+			desc: "drops messages after close",
+			run: func(b *Bus, r *recorder) {
+				b.Subscribe("", r.handler("before"))
+				b.Close()
+				b.Subscribe("", r.handler("after"))
+				b.Publish("hello")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			b := New()
+			r := &recorder{}
+			tc.run(b, r)
+			if diff := pretty.Compare(tc.want, r.got); diff != "" {
+				t.Errorf("messages => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// arrangement.go contains code that saves and restores the runtime
+// arrangement of the containers.
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Arrangement is the arrangement of the containers the user can change while
+// the dashboard runs, i.e. the sizes of the splits and the maximized
+// container. Containers are identified by their IDs, the arrangement of
+// containers created without the ID() option isn't saved.
+//
+// Containers don't have a collapsed or hidden state of their own, so panels
+// the application collapses or hides, e.g. with Update in response to
+// BorderButtons, aren't part of the arrangement. Neither are splits with a
+// side collapsed to zero size, since SplitWeights cannot restore it. The
+// application has to save and restore such state itself.
+//
+// Arrangements can be encoded as JSON, see the workspace package.
+type Arrangement struct {
+	// Splits maps the IDs of split containers to the sizes of their first and
+	// second child containers, applied as SplitWeights.
+	Splits map[string][2]int `json:"splits,omitempty"`
+	// Maximized is the ID of the maximized container, empty if no container
+	// is maximized.
+	Maximized string `json:"maximized,omitempty"`
+}
+
+// Arrangement returns the current arrangement of the containers in the tree.
+// The sizes of splits without SplitWeights are measured as last drawn, so
+// splits that weren't drawn yet, e.g. under a maximized container, are only
+// saved if they have weights.
+func (c *Container) Arrangement() (*Arrangement, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	root := rootCont(c)
+	a := &Arrangement{
+		Splits: map[string][2]int{},
+	}
+	if m := root.maximized; m != nil {
+		a.Maximized = m.opts.id
+	}
+
+	var errStr string
+	preOrder(root, &errStr, visitFunc(func(cur *Container) error {
+		if cur.first == nil || cur.opts.id == "" {
+			return nil
+		}
+		if w := cur.opts.splitWeights; w != nil {
+			a.Splits[cur.opts.id] = [2]int{w.first, w.second}
+			return nil
+		}
+
+		ar, err := cur.splitArea()
+		if err != nil {
+			return err
+		}
+		total := cur.splitSize(ar)
+		first := cur.splitCells(ar)
+		if first > 0 && total-first > 0 {
+			a.Splits[cur.opts.id] = [2]int{first, total - first}
+		}
+		return nil
+	}))
+	if errStr != "" {
+		return nil, errors.New(errStr)
+	}
+	return a, nil
+}
+
+// Arrange restores the arrangement of the containers returned by
+// Arrangement. Splits and the maximized container whose IDs aren't found in
+// the tree are skipped, so that arrangements saved by an older version of
+// the layout can still be restored.
+func (c *Container) Arrange(a *Arrangement) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if a == nil {
+		return errors.New("the arrangement cannot be nil")
+	}
+	for id, s := range a.Splits {
+		if s[0] <= 0 || s[1] <= 0 {
+			return fmt.Errorf("invalid sizes %d:%d of split %q, both must be positive integers", s[0], s[1], id)
+		}
+	}
+
+	root := rootCont(c)
+	var errStr string
+	preOrder(root, &errStr, visitFunc(func(cur *Container) error {
+		s, ok := a.Splits[cur.opts.id]
+		if !ok || cur.first == nil || cur.opts.id == "" {
+			return nil
+		}
+		cur.opts.splitFixed = DefaultSplitFixed
+		cur.opts.splitPercent = DefaultSplitPercent
+		cur.opts.splitWeights = &splitWeights{
+			first:  s[0],
+			second: s[1],
+		}
+		return nil
+	}))
+	// The layout changed, remove content left over from the old layout.
+	root.clearNeeded = true
+
+	var maximized *Container
+	if a.Maximized != "" {
+		// Skips the container if the ID isn't found.
+		maximized, _ = findID(root, a.Maximized)
+	}
+	root.maximize(maximized)
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+)
+
+// arrangedTerm returns a terminal with the root container split into three
+// containers with borders.
+func arrangedTerm() (*faketerm.Terminal, *Container, error) {
+	ft := faketerm.MustNew(image.Point{20, 4})
+	c, err := New(
+		ft,
+		ID("root"),
+		SplitVertical(
+			Left(
+				ID("left"),
+				SplitVertical(
+					Left(Border(linestyle.Light)),
+					Right(Border(linestyle.Light)),
+					SplitWeights(2, 3),
+				),
+			),
+			Right(ID("right"), Border(linestyle.Light)),
+			SplitPercent(40),
+		),
+	)
+	return ft, c, err
+}
+
+func TestArrangement(t *testing.T) {
+	_, c, err := arrangedTerm()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	got, err := c.Arrangement()
+	if err != nil {
+		t.Fatalf("Arrangement => unexpected error: %v", err)
+	}
+	want := &Arrangement{
+		Splits: map[string][2]int{
+			"root": {8, 12},
+			"left": {2, 3},
+		},
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("Arrangement => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	if err := c.Maximize("right"); err != nil {
+		t.Fatalf("Maximize => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	got, err = c.Arrangement()
+	if err != nil {
+		t.Fatalf("Arrangement => unexpected error: %v", err)
+	}
+	want = &Arrangement{
+		Splits: map[string][2]int{
+			"left": {2, 3},
+		},
+		Maximized: "right",
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("Arrangement => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestArrange(t *testing.T) {
+	tests := []struct {
+		desc    string
+		a       *Arrangement
+		want    func(size image.Point) *faketerm.Terminal
+		wantErr bool
+	}{
+		{
+			desc:    "fails on a nil arrangement",
+			wantErr: true,
+		},
+		{
+			desc: "fails on sizes that aren't positive",
+			a: &Arrangement{
+				Splits: map[string][2]int{
+					"root": {0, 1},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "resizes the splits",
+			a: &Arrangement{
+				Splits: map[string][2]int{
+					"root": {1, 1},
+					"left": {1, 1},
+				},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return bordersTerm(size, image.Rect(0, 0, 5, 4), image.Rect(5, 0, 10, 4), image.Rect(10, 0, 20, 4))
+			},
+		},
+		{
+			desc: "skips unknown IDs and containers that aren't split",
+			a: &Arrangement{
+				Splits: map[string][2]int{
+					"unknown": {1, 1},
+					"right":   {1, 1},
+					"root":    {1, 1},
+				},
+				Maximized: "unknown",
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return bordersTerm(size, image.Rect(0, 0, 4, 4), image.Rect(4, 0, 10, 4), image.Rect(10, 0, 20, 4))
+			},
+		},
+		{
+			desc: "maximizes the container",
+			a: &Arrangement{
+				Maximized: "right",
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, cvs.Area(), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, c, err := arrangedTerm()
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			err = c.Arrange(tc.a)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Arrange => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(got.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
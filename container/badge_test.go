@@ -0,0 +1,189 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"image"
+	"testing"
+
+	"github.com/mum4k/termdash/badge"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+)
+
+// mustBadge returns the badge or panics.
+func mustBadge(b *badge.Badge, err error) *badge.Badge {
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// mustDrawBadge draws the badge over the area of the terminal.
+func mustDrawBadge(ft *faketerm.Terminal, ar image.Rectangle, b *badge.Badge) {
+	cvs := testcanvas.MustNew(b.Area(ar))
+	if err := b.Draw(cvs); err != nil {
+		panic(err)
+	}
+	testcanvas.MustApply(cvs, ft)
+}
+
+func TestBadge(t *testing.T) {
+	tests := []struct {
+		desc     string
+		termSize image.Point
+		opts     []Option
+		want     func(size image.Point) *faketerm.Terminal
+	}{
+		{
+			desc:     "draws the badge over the border",
+			termSize: image.Point{10, 5},
+			opts: []Option{
+				Border(linestyle.Light),
+				Badge(mustBadge(badge.Count(3))),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, cvs.Area(), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testdraw.MustText(cvs, " 3 ", image.Point{7, 0}, draw.TextCellOpts(
+					cell.FgColor(cell.ColorWhite),
+					cell.BgColor(cell.ColorRed),
+				))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "draws the badges of sub containers",
+			termSize: image.Point{10, 5},
+			opts: []Option{
+				SplitVertical(
+					Left(
+						Border(linestyle.Light),
+						Badge(mustBadge(badge.Dot(cell.ColorGreen, badge.At(badge.CornerBottomLeft)))),
+					),
+					Right(
+						Border(linestyle.Light),
+					),
+				),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 5, 5))
+				testdraw.MustBorder(cvs, image.Rect(5, 0, 10, 5))
+				testcanvas.MustSetCell(cvs, image.Point{0, 4}, '●', cell.FgColor(cell.ColorGreen))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "draws the badge of the parent over sub containers",
+			termSize: image.Point{10, 5},
+			opts: []Option{
+				Badge(mustBadge(badge.New("!", badge.CellOpts(cell.FgColor(cell.ColorBlue))))),
+				SplitVertical(
+					Left(
+						Border(linestyle.Light),
+					),
+					Right(
+						Border(linestyle.Light),
+					),
+				),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 5, 5))
+				testdraw.MustBorder(cvs, image.Rect(5, 0, 10, 5))
+				testdraw.MustText(cvs, " ! ", image.Point{7, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorBlue)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "skips the badge that doesn't fit",
+			termSize: image.Point{4, 3},
+			opts: []Option{
+				Badge(mustBadge(badge.Count(100))),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := faketerm.MustNew(tc.termSize)
+			c, err := New(got, tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(got.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestBadgeUpdate(t *testing.T) {
+	got := faketerm.MustNew(image.Point{10, 5})
+	c, err := New(got,
+		ID("root"),
+		Border(linestyle.Light),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	b := mustBadge(badge.Count(120, badge.At(badge.CornerBottomRight)))
+	if err := c.Update("root", Badge(b)); err != nil {
+		t.Fatalf("Update => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	want := faketerm.MustNew(got.Size())
+	cvs := testcanvas.MustNew(want.Area())
+	testdraw.MustBorder(cvs, cvs.Area(), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+	testcanvas.MustApply(cvs, want)
+	mustDrawBadge(want, want.Area(), b)
+	if diff := faketerm.Diff(want, got); diff != "" {
+		t.Errorf("Draw => %v", diff)
+	}
+
+	if err := c.Update("root", Badge(nil)); err != nil {
+		t.Fatalf("Update => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	want = faketerm.MustNew(got.Size())
+	cvs = testcanvas.MustNew(want.Area())
+	testdraw.MustBorder(cvs, cvs.Area(), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+	testcanvas.MustApply(cvs, want)
+	if diff := faketerm.Diff(want, got); diff != "" {
+		t.Errorf("Draw => %v", diff)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// borderbutton.go contains code that draws and clicks the buttons in the
+// corners of container borders.
+
+import (
+	"errors"
+	"image"
+
+	"github.com/mum4k/termdash/badge"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Glyphs commonly used on border buttons of window-like panels.
+const (
+	// CloseGlyph is the glyph of a button that closes the panel.
+	CloseGlyph = '✕'
+	// CollapseGlyph is the glyph of a button that collapses the panel.
+	CollapseGlyph = '−'
+	// MaximizeGlyph is the glyph of a button that maximizes the panel.
+	MaximizeGlyph = '⤢'
+)
+
+// BorderButton is a small button drawn in a corner of the border of a
+// container, see the BorderButtons option.
+type BorderButton struct {
+	// Glyph is the rune displayed as the button, e.g. CloseGlyph.
+	Glyph rune
+	// OnClick is called when the button is clicked with the left mouse
+	// button. The callback is called without holding the container lock, it
+	// can update the container, e.g. to remove the panel.
+	OnClick func() error
+	// CellOpts are the cell options of the glyph. Defaults to the color of
+	// the border.
+	CellOpts []cell.Option
+}
+
+// borderButtons stores the buttons in the border of a container.
+type borderButtons struct {
+	// corner is the corner of the border the buttons are drawn in.
+	corner badge.Corner
+	// buttons are the buttons in the order they are drawn, from left to
+	// right.
+	buttons []BorderButton
+}
+
+// borderButtonPress is a border button that was pressed with the left mouse
+// button and not yet released.
+type borderButtonPress struct {
+	// cont is the container whose border has the button.
+	cont *Container
+	// idx is the index of the button.
+	idx int
+}
+
+// borderButtonAreas returns the areas of the border buttons of the container
+// in the order the buttons were provided. The buttons are separated from
+// each other and from the corner by one cell of the border line.
+// Returns nil if the container has no buttons or border or if the buttons
+// don't fit into the border.
+func (c *Container) borderButtonAreas() []image.Rectangle {
+	bb := c.opts.borderButtons
+	if bb == nil || len(bb.buttons) == 0 || !c.hasBorder() {
+		return nil
+	}
+
+	width := len(bb.buttons) - 1
+	for _, b := range bb.buttons {
+		width += runewidth.RuneWidth(b.Glyph)
+	}
+	// Two corners and the border line between each corner and the buttons.
+	if c.area.Dx() < width+4 || c.area.Dy() < 2 {
+		return nil
+	}
+
+	var x, y int
+	switch bb.corner {
+	case badge.CornerTopLeft, badge.CornerBottomLeft:
+		x = c.area.Min.X + 2
+	default:
+		x = c.area.Max.X - 2 - width
+	}
+	switch bb.corner {
+	case badge.CornerBottomLeft, badge.CornerBottomRight:
+		y = c.area.Max.Y - 1
+	default:
+		y = c.area.Min.Y
+	}
+
+	var areas []image.Rectangle
+	for _, b := range bb.buttons {
+		w := runewidth.RuneWidth(b.Glyph)
+		areas = append(areas, image.Rect(x, y, x+w, y+1))
+		x += w + 1
+	}
+	return areas
+}
+
+// drawBorderButtons draws the border buttons of the container on the canvas
+// of its border. The cell options are applied on top of the border ones.
+func drawBorderButtons(c *Container, cvs *canvas.Canvas, borderOpts []cell.Option) error {
+	for i, ar := range c.borderButtonAreas() {
+		b := c.opts.borderButtons.buttons[i]
+		opts := append(append([]cell.Option(nil), borderOpts...), b.CellOpts...)
+		if _, err := cvs.SetCell(ar.Min.Sub(c.area.Min), b.Glyph, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// borderButtonAt returns the innermost container with a border button at the
+// point and the index of the button. Returns a nil container if there is no
+// border button at the point.
+func borderButtonAt(c *Container, p image.Point) (*Container, int, error) {
+	var (
+		errStr string
+		target *Container
+		idx    int
+	)
+	preOrder(c, &errStr, visitFunc(func(cur *Container) error {
+		for i, ar := range cur.borderButtonAreas() {
+			if p.In(ar) {
+				target = cur
+				idx = i
+			}
+		}
+		return nil
+	}))
+	if errStr != "" {
+		return nil, 0, errors.New(errStr)
+	}
+	return target, idx, nil
+}
+
+// borderButtonMouse processes mouse events that click border buttons. The
+// button is clicked when the left mouse button is pressed and released over
+// it, the events in between are consumed.
+// Returns nil if the event wasn't consumed, otherwise a function that must be
+// called without holding c.mu.
+// Caller must hold c.mu.
+func (c *Container) borderButtonMouse(m *terminalapi.Mouse) (func() error, error) {
+	root := rootCont(c)
+	if root.readOnly {
+		return nil, nil
+	}
+
+	target, idx, err := borderButtonAt(root, m.Position)
+	if err != nil {
+		return nil, err
+	}
+
+	if pr := root.pressed; pr != nil {
+		if m.Button == mouse.ButtonLeft {
+			return func() error { return nil }, nil
+		}
+		// Any other button, including the release, ends the press.
+		root.pressed = nil
+		if m.Button != mouse.ButtonRelease || target != pr.cont || idx != pr.idx {
+			return func() error { return nil }, nil
+		}
+		fn := target.opts.borderButtons.buttons[idx].OnClick
+		return fn, nil
+	}
+
+	if m.Button != mouse.ButtonLeft || target == nil {
+		return nil, nil
+	}
+	root.pressed = &borderButtonPress{
+		cont: target,
+		idx:  idx,
+	}
+	return func() error { return nil }, nil
+}
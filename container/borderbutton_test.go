@@ -0,0 +1,353 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/badge"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// noClick is a border button callback that does nothing.
+func noClick() error { return nil }
+
+func TestBorderButtons(t *testing.T) {
+	tests := []struct {
+		desc     string
+		termSize image.Point
+		opts     []Option
+		want     func(size image.Point) *faketerm.Terminal
+		wantErr  bool
+	}{
+		{
+			desc:     "fails on an invalid corner",
+			termSize: image.Point{10, 3},
+			opts: []Option{
+				BorderButtons(badge.Corner(-1), BorderButton{Glyph: CloseGlyph, OnClick: noClick}),
+			},
+			wantErr: true,
+		},
+		{
+			desc:     "fails on a control character as the glyph",
+			termSize: image.Point{10, 3},
+			opts: []Option{
+				BorderButtons(badge.CornerTopRight, BorderButton{Glyph: '\t', OnClick: noClick}),
+			},
+			wantErr: true,
+		},
+		{
+			desc:     "fails on a combining character as the glyph",
+			termSize: image.Point{10, 3},
+			opts: []Option{
+				BorderButtons(badge.CornerTopRight, BorderButton{Glyph: '\u0301', OnClick: noClick}),
+			},
+			wantErr: true,
+		},
+		{
+			desc:     "fails without a callback",
+			termSize: image.Point{10, 3},
+			opts: []Option{
+				BorderButtons(badge.CornerTopRight, BorderButton{Glyph: CloseGlyph}),
+			},
+			wantErr: true,
+		},
+		{
+			desc:     "draws the buttons in the top right corner",
+			termSize: image.Point{10, 3},
+			opts: []Option{
+				Border(linestyle.Light),
+				BorderButtons(badge.CornerTopRight,
+					BorderButton{Glyph: CollapseGlyph, OnClick: noClick},
+					BorderButton{Glyph: CloseGlyph, OnClick: noClick, CellOpts: []cell.Option{cell.FgColor(cell.ColorRed)}},
+				),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, cvs.Area(), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustSetCell(cvs, image.Point{5, 0}, CollapseGlyph, cell.FgColor(cell.ColorYellow))
+				testcanvas.MustSetCell(cvs, image.Point{7, 0}, CloseGlyph, cell.FgColor(cell.ColorRed))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "draws the buttons in the bottom left corner of a sub container",
+			termSize: image.Point{10, 3},
+			opts: []Option{
+				SplitVertical(
+					Left(
+						Border(linestyle.Light),
+						BorderButtons(badge.CornerBottomLeft, BorderButton{Glyph: MaximizeGlyph, OnClick: noClick}),
+					),
+					Right(
+						Border(linestyle.Light),
+					),
+				),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 5, 3))
+				testdraw.MustBorder(cvs, image.Rect(5, 0, 10, 3))
+				testcanvas.MustSetCell(cvs, image.Point{2, 2}, MaximizeGlyph)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "draws the buttons over the border title",
+			termSize: image.Point{10, 3},
+			opts: []Option{
+				Border(linestyle.Light),
+				BorderTitle("abcdefgh"),
+				BorderButtons(badge.CornerTopLeft, BorderButton{Glyph: CloseGlyph, OnClick: noClick}),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				opts := []cell.Option{cell.FgColor(cell.ColorYellow)}
+				testdraw.MustBorder(cvs, cvs.Area(),
+					draw.BorderCellOpts(opts...),
+					draw.BorderTitle("abcdefgh", draw.OverrunModeThreeDot, opts...),
+				)
+				testcanvas.MustSetCell(cvs, image.Point{2, 0}, CloseGlyph, opts...)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "skips the buttons that don't fit",
+			termSize: image.Point{5, 3},
+			opts: []Option{
+				Border(linestyle.Light),
+				BorderButtons(badge.CornerTopRight,
+					BorderButton{Glyph: CollapseGlyph, OnClick: noClick},
+					BorderButton{Glyph: CloseGlyph, OnClick: noClick},
+				),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, cvs.Area(), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "skips the buttons without a border",
+			termSize: image.Point{10, 3},
+			opts: []Option{
+				BorderButtons(badge.CornerTopRight, BorderButton{Glyph: CloseGlyph, OnClick: noClick}),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := faketerm.MustNew(tc.termSize)
+			c, err := New(got, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(got.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestBorderButtonsClick(t *testing.T) {
+	tests := []struct {
+		desc string
+		// opts returns the options of the root container, the buttons record
+		// their clicks with the callback.
+		opts        func(click func(name string) func() error) []Option
+		readOnly    bool
+		events      []terminalapi.Event
+		wantClicked []string
+	}{
+		{
+			desc: "press and release over the button clicks it",
+			opts: func(click func(name string) func() error) []Option {
+				return []Option{
+					Border(linestyle.Light),
+					BorderButtons(badge.CornerTopRight,
+						BorderButton{Glyph: CollapseGlyph, OnClick: click("collapse")},
+						BorderButton{Glyph: CloseGlyph, OnClick: click("close")},
+					),
+				}
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{7, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{7, 0}, Button: mouse.ButtonRelease},
+				&terminalapi.Mouse{Position: image.Point{5, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{5, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{5, 0}, Button: mouse.ButtonRelease},
+			},
+			wantClicked: []string{"close", "collapse"},
+		},
+		{
+			desc: "releasing outside of the button doesn't click it",
+			opts: func(click func(name string) func() error) []Option {
+				return []Option{
+					Border(linestyle.Light),
+					BorderButtons(badge.CornerTopRight,
+						BorderButton{Glyph: CollapseGlyph, OnClick: click("collapse")},
+						BorderButton{Glyph: CloseGlyph, OnClick: click("close")},
+					),
+				}
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{7, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{5, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{5, 0}, Button: mouse.ButtonRelease},
+				&terminalapi.Mouse{Position: image.Point{6, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{7, 0}, Button: mouse.ButtonRelease},
+			},
+		},
+		{
+			desc: "clicks the button on the boundary of a resizable split",
+			opts: func(click func(name string) func() error) []Option {
+				return []Option{
+					SplitHorizontal(
+						Top(Border(linestyle.Light)),
+						Bottom(
+							Border(linestyle.Light),
+							BorderButtons(badge.CornerTopLeft, BorderButton{Glyph: CloseGlyph, OnClick: click("close")}),
+						),
+						SplitResizable(),
+					),
+				}
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{2, 2}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{2, 2}, Button: mouse.ButtonRelease},
+			},
+			wantClicked: []string{"close"},
+		},
+		{
+			desc: "the buttons don't work in the read-only mode",
+			opts: func(click func(name string) func() error) []Option {
+				return []Option{
+					Border(linestyle.Light),
+					BorderButtons(badge.CornerTopRight, BorderButton{Glyph: CloseGlyph, OnClick: click("close")}),
+				}
+			},
+			readOnly: true,
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{7, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{7, 0}, Button: mouse.ButtonRelease},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			var gotClicked []string
+			click := func(name string) func() error {
+				return func() error {
+					gotClicked = append(gotClicked, name)
+					return nil
+				}
+			}
+
+			ft := faketerm.MustNew(image.Point{10, 4})
+			c, err := New(ft, tc.opts(click)...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			c.SetReadOnly(tc.readOnly)
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			for _, ev := range tc.events {
+				if err := c.processEvent(ev); err != nil {
+					t.Fatalf("processEvent(%v) => unexpected error: %v", ev, err)
+				}
+			}
+			if diff := pretty.Compare(tc.wantClicked, gotClicked); diff != "" {
+				t.Errorf("BorderButton.OnClick => unexpected clicks, diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBorderButtonsUpdate(t *testing.T) {
+	var clicked int
+	ft := faketerm.MustNew(image.Point{10, 3})
+	c, err := New(ft,
+		ID("root"),
+		Border(linestyle.Light),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	// The callback removes the buttons.
+	onClick := func() error {
+		clicked++
+		return c.Update("root", BorderButtons(badge.CornerTopRight))
+	}
+	if err := c.Update("root", BorderButtons(badge.CornerTopRight, BorderButton{Glyph: CloseGlyph, OnClick: onClick})); err != nil {
+		t.Fatalf("Update => unexpected error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := c.Draw(); err != nil {
+			t.Fatalf("Draw => unexpected error: %v", err)
+		}
+		for _, ev := range []terminalapi.Event{
+			&terminalapi.Mouse{Position: image.Point{7, 0}, Button: mouse.ButtonLeft},
+			&terminalapi.Mouse{Position: image.Point{7, 0}, Button: mouse.ButtonRelease},
+		} {
+			if err := c.processEvent(ev); err != nil {
+				t.Fatalf("processEvent(%v) => unexpected error: %v", ev, err)
+			}
+		}
+	}
+	if clicked != 1 {
+		t.Errorf("OnClick => called %d times, want 1", clicked)
+	}
+
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	want := faketerm.MustNew(ft.Size())
+	cvs := testcanvas.MustNew(want.Area())
+	testdraw.MustBorder(cvs, cvs.Area(), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+	testcanvas.MustApply(cvs, want)
+	if diff := faketerm.Diff(want, ft); diff != "" {
+		t.Errorf("Draw => %v", diff)
+	}
+}
@@ -25,9 +25,14 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"strings"
 	"sync"
 
+	"github.com/mum4k/termdash/gesture"
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/keyboard"
 	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/mouse"
 	"github.com/mum4k/termdash/private/alignfor"
 	"github.com/mum4k/termdash/private/area"
 	"github.com/mum4k/termdash/private/event"
@@ -66,6 +71,73 @@ type Container struct {
 	// have changed.
 	clearNeeded bool
 
+	// drawnSize is the size of the terminal when the entire tree was last
+	// drawn. Only set on the root container.
+	drawnSize image.Point
+
+	// notify is the function provided to widgets that implement
+	// widgetapi.Notifier. Only set on the root container.
+	notify func()
+
+	// lastDrawn is the content last drawn by the widget, if the widget
+	// implements widgetapi.ChangeTracker.
+	lastDrawn *drawnWidget
+
+	// drag is the boundary between split containers that is being dragged
+	// with the mouse. Only set on the root container.
+	drag *splitDrag
+
+	// pressed is the border button that was pressed with the mouse and not
+	// yet released. Only set on the root container.
+	pressed *borderButtonPress
+
+	// maximized is the container that fills the terminal, nil if no
+	// container is maximized. Only set on the root container.
+	maximized *Container
+
+	// responsive is the state of the container before the options of the
+	// matching breakpoints were applied, nil if no breakpoint matches.
+	responsive *responsiveState
+
+	// scroll is the position of the visible part of the widget canvas if the
+	// container is Scrollable.
+	scroll image.Point
+
+	// hover is the innermost container under the mouse pointer as of the
+	// last mouse event and pointer is the position of the pointer. Only set
+	// on the root container.
+	hover   *Container
+	pointer image.Point
+
+	// tooltip is the container whose tooltip is being displayed or nil and
+	// tooltipDrawn is the area of the tooltip box drawn on the last call to
+	// Draw, empty if none was drawn. Only set on the root container.
+	tooltip      *tooltipState
+	tooltipDrawn image.Rectangle
+
+	// globalKeyBindings are the key sequences processed regardless of which
+	// container is focused, nil if not provided. Only set on the root
+	// container.
+	globalKeyBindings *keybinding.Registry
+
+	// onFocus and onCommand are called when the focused container changes
+	// and when a bound key sequence is completed, nil if not provided. Only
+	// set on the root container.
+	onFocus   func(id string)
+	onCommand func(b keybinding.Binding)
+
+	// readOnly indicates that the container tree is in the read-only mode.
+	// Only set on the root container.
+	readOnly bool
+
+	// gestures recognizes touch gestures in the mouse events. Only set on the
+	// root container.
+	gestures *gesture.Recognizer
+
+	// lifecycle tracks the widgets whose lifecycle hooks were called. Only
+	// set on the root container.
+	lifecycle lifecycleState
+
 	// mu protects the container tree.
 	// All containers in the tree share the same lock.
 	mu *sync.Mutex
@@ -81,9 +153,10 @@ func (c *Container) String() string {
 // applies the provided options.
 func New(t terminalapi.Terminal, opts ...Option) (*Container, error) {
 	root := &Container{
-		term: t,
-		opts: newOptions( /* parent = */ nil),
-		mu:   &sync.Mutex{},
+		term:     t,
+		opts:     newOptions( /* parent = */ nil),
+		gestures: newGestureRecognizer(),
+		mu:       &sync.Mutex{},
 	}
 
 	// Initially the root is focused.
@@ -94,6 +167,7 @@ func New(t terminalapi.Terminal, opts ...Option) (*Container, error) {
 	if err := validateOptions(root); err != nil {
 		return nil, err
 	}
+	root.lifecycleHooks()()
 	return root, nil
 }
 
@@ -164,6 +238,29 @@ func (c *Container) widgetArea() (image.Rectangle, error) {
 	return aligned, nil
 }
 
+// canvasPoint converts the point on the terminal to a point on the canvas of
+// the widget, taking scrolling into account. Returns false if the point falls
+// outside of the visible part of the canvas.
+// Caller must hold c.mu.
+func (c *Container) canvasPoint(p image.Point) (image.Point, bool, error) {
+	wa, err := c.widgetArea()
+	if err != nil {
+		return image.ZP, false, err
+	}
+	vp, err := c.viewport()
+	if err != nil {
+		return image.ZP, false, err
+	}
+	if vp != nil {
+		cp, ok := vp.toCanvas(p)
+		return cp, ok, nil
+	}
+	if !p.In(wa) {
+		return image.ZP, false, nil
+	}
+	return p.Sub(wa.Min), true, nil
+}
+
 // split splits the container's usable area into child areas.
 // Panics if the container isn't configured for a split.
 func (c *Container) split() (image.Rectangle, image.Rectangle, error) {
@@ -171,17 +268,42 @@ func (c *Container) split() (image.Rectangle, image.Rectangle, error) {
 	if err != nil {
 		return image.ZR, image.ZR, err
 	}
-	if c.opts.splitFixed > DefaultSplitFixed {
-		if c.opts.split == splitTypeVertical {
-			return area.VSplitCells(ar, c.opts.splitFixed)
-		}
-		return area.HSplitCells(ar, c.opts.splitFixed)
+
+	cells := c.splitCells(ar)
+	if c.opts.split == splitTypeVertical {
+		return area.VSplitCells(ar, cells)
 	}
+	return area.HSplitCells(ar, cells)
+}
 
+// splitSize returns the size of the area along the axis the container is
+// split on.
+func (c *Container) splitSize(ar image.Rectangle) int {
 	if c.opts.split == splitTypeVertical {
-		return area.VSplit(ar, c.opts.splitPercent)
+		return ar.Dx()
 	}
-	return area.HSplit(ar, c.opts.splitPercent)
+	return ar.Dy()
+}
+
+// splitCells returns the size of the first child container in cells when the
+// area is split.
+func (c *Container) splitCells(ar image.Rectangle) int {
+	total := c.splitSize(ar)
+
+	var cells int
+	switch {
+	case c.opts.splitFixed > DefaultSplitFixed:
+		cells = c.opts.splitFixed
+	case c.opts.splitWeights != nil:
+		w := c.opts.splitWeights
+		cells = total * w.first / (w.first + w.second)
+	default:
+		cells = total * c.opts.splitPercent / 100
+	}
+	if c.opts.splitLimits != nil {
+		cells = c.opts.splitLimits.apply(cells, total)
+	}
+	return cells
 }
 
 // createFirst creates and returns the first sub container of this container.
@@ -207,13 +329,80 @@ func (c *Container) createSecond(opts []Option) error {
 // Draw draws this container and all of its sub containers.
 func (c *Container) Draw() error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	err := c.draw()
+	// Breakpoints might have placed or removed widgets.
+	hooks := c.lifecycleHooks()
+	c.mu.Unlock()
+
+	hooks()
+	return err
+}
+
+// DrawSubtree draws only the container with the specified ID and all of its
+// sub containers, leaving the rest of the terminal unchanged.
+// This is cheaper than Draw when only a small part of the dashboard changes
+// often. Falls back to drawing all the containers if the layout needs to be
+// recalculated, e.g. when the terminal was resized or the containers were
+// updated since the last call to Draw, when a displayed tooltip moves or
+// disappears, when the read-only indicator is displayed or when a container
+// is maximized.
+// The argument id must match exactly one container that was created with the
+// matching ID() option.
+func (c *Container) DrawSubtree(id string) error {
+	c.mu.Lock()
+	err := c.drawSubtree(id)
+	hooks := c.lifecycleHooks()
+	c.mu.Unlock()
+
+	hooks()
+	return err
+}
 
-	if c.clearNeeded {
+// drawSubtree implements DrawSubtree, the caller must hold c.mu.
+func (c *Container) drawSubtree(id string) error {
+	root := rootCont(c)
+	target, err := findID(root, id)
+	if err != nil {
+		return err
+	}
+	if c.clearNeeded || root.drawnSize != root.term.Size() || root.readOnly || root.maximized != nil {
+		return c.draw()
+	}
+	moved, err := c.tooltipMoved()
+	if err != nil {
+		return err
+	}
+	if moved {
+		return c.draw()
+	}
+	if err := drawSubtree(target); err != nil {
+		return err
+	}
+	// The subtree might be under the tooltip box.
+	return c.drawTooltip()
+}
+
+// draw implements Draw, the caller must hold c.mu.
+func (c *Container) draw() error {
+	// The areas of the containers change with the size of the terminal and
+	// the tooltip box moves along with them.
+	if c.clearNeeded || (!c.tooltipDrawn.Empty() && c.drawnSize != c.term.Size()) {
 		if err := c.term.Clear(); err != nil {
 			return fmt.Errorf("term.Clear => error: %v", err)
 		}
 		c.clearNeeded = false
+	} else {
+		// The tooltip box covers other containers, clear its area if it
+		// won't be displayed anymore or moves elsewhere.
+		moved, err := c.tooltipMoved()
+		if err != nil {
+			return err
+		}
+		if moved {
+			if err := c.clearTooltip(); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Update the area we are tracking for focus in case the terminal size
@@ -223,7 +412,13 @@ func (c *Container) Draw() error {
 		return err
 	}
 	c.focusTracker.updateArea(ar)
-	return drawTree(c)
+	if err := drawTree(c); err != nil {
+		return err
+	}
+	if err := c.drawTooltip(); err != nil {
+		return err
+	}
+	return c.drawReadOnly()
 }
 
 // Update updates container with the specified id by setting the provided
@@ -234,26 +429,50 @@ func (c *Container) Draw() error {
 // matching ID() option. The argument id must not be an empty string.
 func (c *Container) Update(id string, opts ...Option) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	err := c.update(id, opts...)
+	hooks := c.lifecycleHooks()
+	c.mu.Unlock()
+
+	hooks()
+	return err
+}
 
+// update implements Update, the caller must hold c.mu.
+func (c *Container) update(id string, opts ...Option) error {
 	target, err := findID(c, id)
 	if err != nil {
 		return err
 	}
 	c.clearNeeded = true
+	// The dragged boundary, the pressed border button and the hovered
+	// container might not exist after the update.
+	c.drag = nil
+	c.pressed = nil
+	c.hover = nil
+	c.tooltip = nil
 
+	// The options update the container as it is without any breakpoints, the
+	// breakpoints are evaluated again on the next draw.
+	target.revert()
 	if err := applyOptions(target, opts...); err != nil {
 		return err
 	}
 	if err := validateOptions(c); err != nil {
 		return err
 	}
+	if notify := rootCont(c).notify; notify != nil {
+		setNotifyFunc(target, notify)
+	}
 
 	// The currently focused container might not be reachable anymore, because
 	// it was under the target. If that is so, move the focus up to the target.
 	if !c.focusTracker.reachableFrom(c) {
 		c.focusTracker.setActive(target)
 	}
+	// The maximized container might have been removed as well.
+	if c.maximized != nil && !reachable(c, c.maximized) {
+		c.maximized = nil
+	}
 	return nil
 }
 
@@ -268,6 +487,21 @@ func (c *Container) updateFocus(m *terminalapi.Mouse) {
 	c.focusTracker.mouse(target, m)
 }
 
+// Inject processes the event as if it was received from the terminal, e.g. a
+// Keyboard event is delivered to the focused widget. Supports the Keyboard,
+// Mouse, Paste, Composition and Commit events. Injected events aren't
+// forwarded to the termdash subscribers.
+//
+// This allows widgets to generate input for other widgets, like an on-screen
+// keyboard. It is safe to call this method from the Keyboard and Mouse
+// methods of widgets or from callbacks they call.
+func (c *Container) Inject(ev terminalapi.Event) error {
+	c.mu.Lock()
+	root := rootCont(c)
+	c.mu.Unlock()
+	return root.processEvent(ev)
+}
+
 // processEvent processes events delivered to the container.
 func (c *Container) processEvent(ev terminalapi.Event) error {
 	// This is done in two stages.
@@ -278,11 +512,19 @@ func (c *Container) processEvent(ev terminalapi.Event) error {
 	//    because some widgets might try to mutate the container when they
 	//    receive the event, like dynamically change the layout.
 	c.mu.Lock()
+	focused := c.focusTracker.container
 	sendFn, err := c.prepareEvTargets(ev)
+	onFocus := rootCont(c).onFocus
+	newFocused := c.focusTracker.container
+	hooks := c.lifecycleHooks()
 	c.mu.Unlock()
+	hooks()
 	if err != nil {
 		return err
 	}
+	if onFocus != nil && newFocused != focused {
+		onFocus(newFocused.opts.id)
+	}
 	return sendFn()
 }
 
@@ -293,26 +535,98 @@ func (c *Container) processEvent(ev terminalapi.Event) error {
 func (c *Container) prepareEvTargets(ev terminalapi.Event) (func() error, error) {
 	switch e := ev.(type) {
 	case *terminalapi.Mouse:
-		c.updateFocus(ev.(*terminalapi.Mouse))
-
-		targets, err := c.mouseEvTargets(e)
+		gestureFn, err := c.gestureTarget(e)
 		if err != nil {
 			return nil, err
 		}
+		fn, err := c.prepareMouseEvTargets(e)
+		if err != nil || gestureFn == nil {
+			return fn, err
+		}
+		return func() error {
+			if err := fn(); err != nil {
+				return err
+			}
+			return gestureFn()
+		}, nil
+
+	case *terminalapi.Keyboard:
+		// Typing hides the tooltip until the delay elapses again.
+		rootCont(c).tooltip = nil
+
+		if fn, err := c.resizeKeyboard(e); err != nil || fn != nil {
+			return fn, err
+		}
+		if consumed, err := c.scrollKeyboard(e); err != nil || consumed {
+			return func() error { return nil }, err
+		}
+		if b, fn, consumed := c.matchKeyBindings(e); consumed {
+			onCommand := rootCont(c).onCommand
+			return func() error {
+				if b != nil && onCommand != nil {
+					onCommand(*b)
+				}
+				if fn == nil {
+					return nil
+				}
+				return fn()
+			}, nil
+		}
+
+		if rootCont(c).readOnly {
+			return func() error { return nil }, nil
+		}
+		targets := c.keyEvTargets()
 		return func() error {
-			for _, mt := range targets {
-				if err := mt.widget.Mouse(mt.ev); err != nil {
+			for _, w := range targets {
+				if err := w.Keyboard(e); err != nil {
 					return err
 				}
 			}
 			return nil
 		}, nil
 
-	case *terminalapi.Keyboard:
+	case *terminalapi.Paste:
+		rootCont(c).tooltip = nil
+		if rootCont(c).readOnly {
+			return func() error { return nil }, nil
+		}
+
 		targets := c.keyEvTargets()
 		return func() error {
 			for _, w := range targets {
-				if err := w.Keyboard(e); err != nil {
+				if err := paste(w, e.Text); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil
+
+	case *terminalapi.Composition:
+		if rootCont(c).readOnly {
+			return func() error { return nil }, nil
+		}
+
+		targets := c.keyEvTargets()
+		return func() error {
+			for _, w := range targets {
+				if cp, ok := w.(widgetapi.Composer); ok {
+					cp.Compose(e.Text, e.Cursor)
+				}
+			}
+			return nil
+		}, nil
+
+	case *terminalapi.Commit:
+		rootCont(c).tooltip = nil
+		if rootCont(c).readOnly {
+			return func() error { return nil }, nil
+		}
+
+		targets := c.keyEvTargets()
+		return func() error {
+			for _, w := range targets {
+				if err := commit(w, e.Text); err != nil {
 					return err
 				}
 			}
@@ -324,6 +638,104 @@ func (c *Container) prepareEvTargets(ev terminalapi.Event) (func() error, error)
 	}
 }
 
+// prepareMouseEvTargets processes the mouse event on behalf of the container
+// (tracks focus, clicks border buttons, resizes splits and scrolls widgets) and returns a closure
+// that delivers it to widgets.
+// Caller must hold c.mu.
+func (c *Container) prepareMouseEvTargets(m *terminalapi.Mouse) (func() error, error) {
+	c.updateFocus(m)
+	root := rootCont(c)
+	root.hover = pointCont(c, m.Position)
+	root.pointer = m.Position
+
+	// Border buttons can be on the boundary between split containers.
+	if fn, err := c.borderButtonMouse(m); err != nil || fn != nil {
+		return fn, err
+	}
+	if fn, err := c.resizeMouse(m); err != nil || fn != nil {
+		return fn, err
+	}
+
+	if consumed, err := c.scrollMouse(m); err != nil || consumed {
+		return func() error { return nil }, err
+	}
+	if root.readOnly && !readOnlyMouse(m) {
+		return func() error { return nil }, nil
+	}
+
+	targets, err := c.shown().mouseEvTargets(m)
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		for _, mt := range targets {
+			if err := mt.widget.Mouse(mt.ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// matchKeyBindings matches the keyboard event against the key bindings of the
+// focused container and its ancestors, starting with the focused container,
+// and then against the global key bindings.
+// Returns true if the key was consumed by one of the registries and the bound
+// function if the key completed a sequence.
+// Caller must hold c.mu.
+func (c *Container) matchKeyBindings(k *terminalapi.Keyboard) (*keybinding.Binding, keybinding.HandlerFn, bool) {
+	for cur := c.focusTracker.container; cur != nil; cur = cur.parent {
+		if cur.opts.keyBindings == nil {
+			continue
+		}
+		if b, fn, consumed := cur.opts.keyBindings.MatchBinding(k); consumed {
+			return b, fn, true
+		}
+	}
+	if global := rootCont(c).globalKeyBindings; global != nil {
+		return global.MatchBinding(k)
+	}
+	return nil, nil, false
+}
+
+// pasteReplacer normalizes the line endings in pasted text.
+var pasteReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// paste delivers the pasted text to the widget. Widgets that don't implement
+// widgetapi.Paster receive a keyboard event for each character.
+func paste(w widgetapi.Widget, text string) error {
+	if p, ok := w.(widgetapi.Paster); ok {
+		p.Paste(text)
+		return nil
+	}
+	for _, r := range pasteReplacer.Replace(text) {
+		k := keyboard.Key(r)
+		if r == '\n' {
+			k = keyboard.KeyEnter
+		}
+		if err := w.Keyboard(&terminalapi.Keyboard{Key: k}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commit delivers the text committed by an input method editor to the widget.
+// Widgets that don't implement widgetapi.Composer receive a keyboard event
+// for each character.
+func commit(w widgetapi.Widget, text string) error {
+	if cp, ok := w.(widgetapi.Composer); ok {
+		cp.Commit(text)
+		return nil
+	}
+	for _, r := range text {
+		if err := w.Keyboard(&terminalapi.Keyboard{Key: keyboard.Key(r)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // keyEvTargets returns those widgets found in the container that should
 // receive this keyboard event.
 // Caller must hold c.mu.
@@ -369,7 +781,14 @@ type mouseEvTarget struct {
 }
 
 // newMouseEvTarget returns a new newMouseEvTarget.
-func newMouseEvTarget(w widgetapi.Widget, wArea image.Rectangle, ev *terminalapi.Mouse) *mouseEvTarget {
+// The viewport is nil unless the widget is being scrolled.
+func newMouseEvTarget(w widgetapi.Widget, wArea image.Rectangle, vp *viewport, ev *terminalapi.Mouse) *mouseEvTarget {
+	if vp != nil {
+		return &mouseEvTarget{
+			widget: w,
+			ev:     adjustScrolledMouseEv(ev, vp),
+		}
+	}
 	return &mouseEvTarget{
 		widget: w,
 		ev:     adjustMouseEv(ev, wArea),
@@ -397,7 +816,15 @@ func (c *Container) mouseEvTargets(m *terminalapi.Mouse) ([]*mouseEvTarget, erro
 		if err != nil {
 			return err
 		}
+		vp := cur.scrollViewport(wa, cur.widgetNeedSize())
+		visible := wa
+		if vp != nil {
+			visible = vp.area
+		}
 
+		if m.Button == mouse.ButtonNone && !wOpts.WantHover {
+			return nil
+		}
 		switch wOpts.WantMouse {
 		case widgetapi.MouseScopeNone:
 			// Widget doesn't want any mouse events.
@@ -405,19 +832,19 @@ func (c *Container) mouseEvTargets(m *terminalapi.Mouse) ([]*mouseEvTarget, erro
 
 		case widgetapi.MouseScopeWidget:
 			// Only if the event falls inside of the widget's canvas.
-			if m.Position.In(wa) {
-				widgets = append(widgets, newMouseEvTarget(cur.opts.widget, wa, m))
+			if m.Position.In(visible) {
+				widgets = append(widgets, newMouseEvTarget(cur.opts.widget, wa, vp, m))
 			}
 
 		case widgetapi.MouseScopeContainer:
 			// Only if the event falls inside the widget's parent container.
 			if m.Position.In(cur.area) {
-				widgets = append(widgets, newMouseEvTarget(cur.opts.widget, wa, m))
+				widgets = append(widgets, newMouseEvTarget(cur.opts.widget, wa, vp, m))
 			}
 
 		case widgetapi.MouseScopeGlobal:
 			// Widget wants all mouse events.
-			widgets = append(widgets, newMouseEvTarget(cur.opts.widget, wa, m))
+			widgets = append(widgets, newMouseEvTarget(cur.opts.widget, wa, vp, m))
 		}
 		return nil
 	}))
@@ -428,6 +855,119 @@ func (c *Container) mouseEvTargets(m *terminalapi.Mouse) ([]*mouseEvTarget, erro
 	return widgets, nil
 }
 
+// SetNotifyFunc provides the function to all the widgets in the container
+// tree that implement widgetapi.Notifier, including widgets placed by future
+// calls to Update.
+// This method is private to termdash, stability isn't guaranteed and changes
+// won't be backward compatible.
+func (c *Container) SetNotifyFunc(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	root := rootCont(c)
+	root.notify = fn
+	setNotifyFunc(root, fn)
+}
+
+// SetGlobalKeyBindings provides key bindings that are processed regardless
+// of which container is focused. They are matched after the key bindings of
+// the focused container and its ancestors and before the keys are forwarded
+// to widgets.
+// This method is private to termdash, stability isn't guaranteed and changes
+// won't be backward compatible.
+func (c *Container) SetGlobalKeyBindings(r *keybinding.Registry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rootCont(c).globalKeyBindings = r
+}
+
+// SetFocusFunc provides a function that is called with the ID of the newly
+// focused container each time the user moves the focus. The function is
+// called without holding the container lock, before the event that moved
+// the focus is delivered to the widgets.
+// This method is private to termdash, stability isn't guaranteed and changes
+// won't be backward compatible.
+func (c *Container) SetFocusFunc(fn func(id string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rootCont(c).onFocus = fn
+}
+
+// SetCommandFunc provides a function that is called with the binding each
+// time the user completes a key sequence bound via the KeyBindings option or
+// the global key bindings. The function is called just before the bound
+// function.
+// This method is private to termdash, stability isn't guaranteed and changes
+// won't be backward compatible.
+func (c *Container) SetCommandFunc(fn func(b keybinding.Binding)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rootCont(c).onCommand = fn
+}
+
+// KeyConflicts returns an error if any of the keys the containers in the tree
+// process conflict with the provided key bindings. This includes the scroll
+// keys, the keys that resize splits and the key sequences bound via the
+// KeyBindings option. Keys processed by widgets aren't known to the
+// containers and aren't checked.
+// This method is private to termdash, stability isn't guaranteed and changes
+// won't be backward compatible.
+func (c *Container) KeyConflicts(r *keybinding.Registry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errStr string
+	preOrder(rootCont(c), &errStr, visitFunc(func(cur *Container) error {
+		for _, seq := range containerKeys(cur) {
+			if err := r.Conflict(seq); err != nil {
+				if cur.opts.id == "" {
+					return fmt.Errorf("a key of a container: %v", err)
+				}
+				return fmt.Errorf("a key of container %q: %v", cur.opts.id, err)
+			}
+		}
+		return nil
+	}))
+	if errStr != "" {
+		return errors.New(errStr)
+	}
+	return nil
+}
+
+// containerKeys returns the keys processed by the container itself.
+func containerKeys(c *Container) []keybinding.Sequence {
+	var keys []keybinding.Sequence
+	if sk := c.opts.scrollKeys; sk != nil {
+		for _, k := range []keyboard.Key{sk.up, sk.down, sk.left, sk.right} {
+			keys = append(keys, keybinding.Sequence{k})
+		}
+	}
+	if sr := c.opts.splitResize; sr != nil && sr.keys {
+		keys = append(keys, keybinding.Sequence{sr.decrease}, keybinding.Sequence{sr.increase})
+	}
+	if c.opts.keyBindings != nil {
+		for _, b := range c.opts.keyBindings.Bindings() {
+			keys = append(keys, b.Sequence)
+		}
+	}
+	return keys
+}
+
+// setNotifyFunc provides the function to widgets that implement
+// widgetapi.Notifier in the container and all of its sub containers.
+// Caller must hold c.mu.
+func setNotifyFunc(c *Container, fn func()) {
+	var errStr string
+	preOrder(c, &errStr, visitFunc(func(c *Container) error {
+		for _, w := range []widgetapi.Widget{c.opts.widget, c.opts.statusBar} {
+			if n, ok := w.(widgetapi.Notifier); ok {
+				n.SetNotifyFunc(fn)
+			}
+		}
+		return nil
+	}))
+}
+
 // Subscribe tells the container to subscribe itself and widgets to the
 // provided event distribution system.
 // This method is private to termdash, stability isn't guaranteed and changes
@@ -444,6 +984,9 @@ func (c *Container) Subscribe(eds *event.DistributionSystem) {
 	want := []terminalapi.Event{
 		&terminalapi.Keyboard{},
 		&terminalapi.Mouse{},
+		&terminalapi.Paste{},
+		&terminalapi.Composition{},
+		&terminalapi.Commit{},
 	}
 	eds.Subscribe(want, func(ev terminalapi.Event) {
 		if err := c.processEvent(ev); err != nil {
@@ -452,6 +995,19 @@ func (c *Container) Subscribe(eds *event.DistributionSystem) {
 	}, event.MaxRepetitive(maxReps))
 }
 
+// adjustScrolledMouseEv adjusts the mouse event relative to the canvas of a
+// widget that is being scrolled.
+func adjustScrolledMouseEv(m *terminalapi.Mouse, vp *viewport) *terminalapi.Mouse {
+	p, ok := vp.toCanvas(m.Position)
+	if !ok {
+		p = image.Point{-1, -1}
+	}
+	return &terminalapi.Mouse{
+		Position: p,
+		Button:   m.Button,
+	}
+}
+
 // adjustMouseEv adjusts the mouse event relative to the widget area.
 func adjustMouseEv(m *terminalapi.Mouse, wArea image.Rectangle) *terminalapi.Mouse {
 	// The sent mouse coordinate is relative to the widget canvas, i.e. zero
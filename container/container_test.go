@@ -15,14 +15,19 @@
 package container
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/kylelemons/godebug/pretty"
+
 	"github.com/mum4k/termdash/align"
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/gesture"
+	"github.com/mum4k/termdash/keybinding"
 	"github.com/mum4k/termdash/keyboard"
 	"github.com/mum4k/termdash/linestyle"
 	"github.com/mum4k/termdash/mouse"
@@ -91,6 +96,26 @@ func TestNew(t *testing.T) {
 			},
 			wantContainerErr: true,
 		},
+		{
+			desc:     "fails on ScrollKeys with duplicate keys",
+			termSize: image.Point{10, 10},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					Scrollable(),
+					ScrollKeys(keyboard.KeyArrowUp, keyboard.KeyArrowUp, keyboard.KeyArrowLeft, keyboard.KeyArrowRight),
+				)
+			},
+			wantContainerErr: true,
+		},
+		{
+			desc:     "fails on nil KeyBindings",
+			termSize: image.Point{10, 10},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(ft, KeyBindings(nil))
+			},
+			wantContainerErr: true,
+		},
 		{
 			desc:     "fails on invalid option on the first vertical child container",
 			termSize: image.Point{10, 10},
@@ -510,6 +535,82 @@ func TestNew(t *testing.T) {
 			},
 			wantContainerErr: true,
 		},
+		{
+			desc:     "fails on SplitWeights with a zero weight",
+			termSize: image.Point{10, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitHorizontal(
+						Top(),
+						Bottom(),
+						SplitWeights(0, 1),
+					),
+				)
+			},
+			wantContainerErr: true,
+		},
+		{
+			desc:     "fails when both SplitWeights and SplitPercent are specified",
+			termSize: image.Point{10, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitHorizontal(
+						Top(),
+						Bottom(),
+						SplitWeights(1, 2),
+						SplitPercent(20),
+					),
+				)
+			},
+			wantContainerErr: true,
+		},
+		{
+			desc:     "fails on negative SplitMinCells",
+			termSize: image.Point{10, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitHorizontal(
+						Top(),
+						Bottom(),
+						SplitMinCells(-1, 0),
+					),
+				)
+			},
+			wantContainerErr: true,
+		},
+		{
+			desc:     "fails on negative SplitMaxCells",
+			termSize: image.Point{10, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitHorizontal(
+						Top(),
+						Bottom(),
+						SplitMaxCells(0, -1),
+					),
+				)
+			},
+			wantContainerErr: true,
+		},
+		{
+			desc:     "fails on SplitResizeKeys with duplicate keys",
+			termSize: image.Point{10, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitHorizontal(
+						Top(),
+						Bottom(),
+						SplitResizeKeys('+', '+'),
+					),
+				)
+			},
+			wantContainerErr: true,
+		},
 		{
 			desc:     "fails on SplitFixed less than -1",
 			termSize: image.Point{10, 20},
@@ -681,6 +782,163 @@ func TestNew(t *testing.T) {
 				return ft
 			},
 		},
+		{
+			desc:     "horizontal split by weights",
+			termSize: image.Point{10, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitHorizontal(
+						Top(
+							Border(linestyle.Light),
+						),
+						Bottom(
+							Border(linestyle.Light),
+						),
+						SplitWeights(1, 3),
+					),
+				)
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 10, 5))
+				testdraw.MustBorder(cvs, image.Rect(0, 5, 10, 20))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "split respects the minimum size of the second container",
+			termSize: image.Point{10, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitHorizontal(
+						Top(
+							Border(linestyle.Light),
+						),
+						Bottom(
+							Border(linestyle.Light),
+						),
+						SplitPercent(90),
+						SplitMinCells(0, 6),
+					),
+				)
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 10, 14))
+				testdraw.MustBorder(cvs, image.Rect(0, 14, 10, 20))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "split respects the maximum size of the first container",
+			termSize: image.Point{10, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitHorizontal(
+						Top(
+							Border(linestyle.Light),
+						),
+						Bottom(
+							Border(linestyle.Light),
+						),
+						SplitWeights(1, 1),
+						SplitMaxCells(4, 0),
+					),
+				)
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 10, 4))
+				testdraw.MustBorder(cvs, image.Rect(0, 4, 10, 20))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "split respects the maximum size of the second container",
+			termSize: image.Point{10, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitHorizontal(
+						Top(
+							Border(linestyle.Light),
+						),
+						Bottom(
+							Border(linestyle.Light),
+						),
+						SplitFixed(2),
+						SplitMaxCells(0, 12),
+					),
+				)
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 10, 8))
+				testdraw.MustBorder(cvs, image.Rect(0, 8, 10, 20))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "second container collapses when both minimums don't fit",
+			termSize: image.Point{10, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitHorizontal(
+						Top(
+							Border(linestyle.Light),
+						),
+						Bottom(
+							Border(linestyle.Light),
+						),
+						SplitMinCells(12, 12),
+					),
+				)
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 10, 20))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "first container collapses when only the second minimum fits",
+			termSize: image.Point{10, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitHorizontal(
+						Top(
+							Border(linestyle.Light),
+						),
+						Bottom(
+							Border(linestyle.Light),
+						),
+						SplitMinCells(25, 12),
+					),
+				)
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 10, 20))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
 		{
 			desc:     "horizontal split, parent and children have borders",
 			termSize: image.Point{10, 10},
@@ -1065,6 +1323,32 @@ func (eh *errorHandler) handle(err error) {
 	eh.err = err
 }
 
+// pasteWidget is a fakewidget.Mirror that displays the pasted text.
+type pasteWidget struct {
+	*fakewidget.Mirror
+}
+
+// Paste implements widgetapi.Paster.Paste.
+func (pw *pasteWidget) Paste(text string) {
+	pw.Text(text)
+}
+
+// composeWidget is a fakewidget.Mirror that displays the composed and the
+// committed text.
+type composeWidget struct {
+	*fakewidget.Mirror
+}
+
+// Compose implements widgetapi.Composer.Compose.
+func (cw *composeWidget) Compose(preedit string, cursor int) {
+	cw.Text(fmt.Sprintf("[%s:%d]", preedit, cursor))
+}
+
+// Commit implements widgetapi.Composer.Commit.
+func (cw *composeWidget) Commit(text string) {
+	cw.Text(text)
+}
+
 func TestKeyboard(t *testing.T) {
 	tests := []struct {
 		desc      string
@@ -1260,19 +1544,322 @@ func TestKeyboard(t *testing.T) {
 			},
 			wantErr: true,
 		},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.desc, func(t *testing.T) {
-			got, err := faketerm.New(tc.termSize)
-			if err != nil {
-				t.Fatalf("faketerm.New => unexpected error: %v", err)
-			}
-
-			c, err := tc.container(got)
-			if err != nil {
-				t.Fatalf("tc.container => unexpected error: %v", err)
-			}
+		{
+			desc:     "key bindings consume the keys of bound sequences",
+			termSize: image.Point{40, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				w := fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})
+				kb, err := keybinding.New()
+				if err != nil {
+					return nil, err
+				}
+				if err := kb.Bind(keybinding.Sequence{keyboard.KeyCtrlX, keyboard.KeyCtrlS}, func() error {
+					w.Text("saved")
+					return nil
+				}); err != nil {
+					return nil, err
+				}
+				return New(
+					ft,
+					KeyBindings(kb),
+					PlaceWidget(w),
+				)
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: keyboard.KeyCtrlX},
+				&terminalapi.Keyboard{Key: keyboard.KeyCtrlS},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				mirror := fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})
+				mirror.Text("saved")
+				fakewidget.MustDrawWithMirror(
+					mirror,
+					ft,
+					testcanvas.MustNew(ft.Area()),
+					&widgetapi.Meta{Focused: true},
+					&terminalapi.Keyboard{Key: 'a'},
+				)
+				return ft
+			},
+		},
+		{
+			desc:     "key bindings of the focused container take precedence",
+			termSize: image.Point{40, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				w := fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})
+				outer, err := keybinding.New()
+				if err != nil {
+					return nil, err
+				}
+				if err := outer.Bind(keybinding.Sequence{keyboard.KeyCtrlG}, func() error {
+					w.Text("outer")
+					return nil
+				}); err != nil {
+					return nil, err
+				}
+				inner, err := keybinding.New()
+				if err != nil {
+					return nil, err
+				}
+				if err := inner.Bind(keybinding.Sequence{keyboard.KeyCtrlG}, func() error {
+					w.Text("inner")
+					return nil
+				}); err != nil {
+					return nil, err
+				}
+				return New(
+					ft,
+					KeyBindings(outer),
+					SplitVertical(
+						Left(
+							KeyBindings(inner),
+							PlaceWidget(w),
+						),
+						Right(),
+					),
+				)
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonRelease},
+				&terminalapi.Keyboard{Key: keyboard.KeyCtrlG},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				mirror := fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})
+				mirror.Text("inner")
+				fakewidget.MustDrawWithMirror(
+					mirror,
+					ft,
+					testcanvas.MustNew(image.Rect(0, 0, 20, 20)),
+					&widgetapi.Meta{Focused: true},
+				)
+				return ft
+			},
+		},
+		{
+			desc:     "global key bindings apply after the key bindings of containers",
+			termSize: image.Point{40, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				w := fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})
+				kb, err := keybinding.New()
+				if err != nil {
+					return nil, err
+				}
+				if err := kb.Bind(keybinding.Sequence{keyboard.KeyCtrlG}, func() error {
+					w.Text("container")
+					return nil
+				}); err != nil {
+					return nil, err
+				}
+				global, err := keybinding.New()
+				if err != nil {
+					return nil, err
+				}
+				if err := global.Bind(keybinding.Sequence{keyboard.KeyCtrlG}, func() error {
+					w.Text("global")
+					return nil
+				}); err != nil {
+					return nil, err
+				}
+				if err := global.Bind(keybinding.Sequence{keyboard.KeyCtrlQ}, func() error {
+					w.Text("quit")
+					return nil
+				}); err != nil {
+					return nil, err
+				}
+				c, err := New(
+					ft,
+					KeyBindings(kb),
+					PlaceWidget(w),
+				)
+				if err != nil {
+					return nil, err
+				}
+				c.SetGlobalKeyBindings(global)
+				return c, nil
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyCtrlG},
+				&terminalapi.Keyboard{Key: keyboard.KeyCtrlQ},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				mirror := fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})
+				mirror.Text("container")
+				mirror.Text("quit")
+				fakewidget.MustDrawWithMirror(
+					mirror,
+					ft,
+					testcanvas.MustNew(ft.Area()),
+					&widgetapi.Meta{Focused: true},
+				)
+				return ft
+			},
+		},
+		{
+			desc:     "forwards errors from functions bound to key sequences",
+			termSize: image.Point{40, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				kb, err := keybinding.New()
+				if err != nil {
+					return nil, err
+				}
+				if err := kb.Bind(keybinding.Sequence{keyboard.KeyCtrlG}, func() error {
+					return errors.New("bound function failed")
+				}); err != nil {
+					return nil, err
+				}
+				return New(
+					ft,
+					KeyBindings(kb),
+					PlaceWidget(fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})),
+				)
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyCtrlG},
+			},
+			wantProcessed: 2, // The error is also an event.
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				fakewidget.MustDraw(
+					ft,
+					testcanvas.MustNew(ft.Area()),
+					&widgetapi.Meta{Focused: true},
+					widgetapi.Options{},
+				)
+				return ft
+			},
+			wantErr: true,
+		},
+		{
+			desc:     "pasted text delivered as keyboard events",
+			termSize: image.Point{40, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					PlaceWidget(fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})),
+				)
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Paste{Text: "ab\r\n"},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				fakewidget.MustDraw(
+					ft,
+					testcanvas.MustNew(ft.Area()),
+					&widgetapi.Meta{Focused: true},
+					widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused},
+					&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				)
+				return ft
+			},
+		},
+		{
+			desc:     "pasted text delivered in one piece to widgets that implement Paster",
+			termSize: image.Point{40, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					PlaceWidget(&pasteWidget{
+						Mirror: fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused}),
+					}),
+				)
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Paste{Text: "pasted"},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				mirror := fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})
+				mirror.Text("pasted")
+				fakewidget.MustDrawWithMirror(
+					mirror,
+					ft,
+					testcanvas.MustNew(ft.Area()),
+					&widgetapi.Meta{Focused: true},
+				)
+				return ft
+			},
+		},
+		{
+			desc:     "committed text delivered as keyboard events, composition ignored",
+			termSize: image.Point{40, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					PlaceWidget(fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})),
+				)
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Composition{Text: "ni", Cursor: 2},
+				&terminalapi.Commit{Text: "你"},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				fakewidget.MustDraw(
+					ft,
+					testcanvas.MustNew(ft.Area()),
+					&widgetapi.Meta{Focused: true},
+					widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused},
+					&terminalapi.Keyboard{Key: '你'},
+				)
+				return ft
+			},
+		},
+		{
+			desc:     "composition and committed text delivered to widgets that implement Composer",
+			termSize: image.Point{40, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					PlaceWidget(&composeWidget{
+						Mirror: fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused}),
+					}),
+				)
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Composition{Text: "ni", Cursor: 2},
+				&terminalapi.Commit{Text: "你"},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				mirror := fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})
+				mirror.Text("[ni:2]")
+				mirror.Text("你")
+				fakewidget.MustDrawWithMirror(
+					mirror,
+					ft,
+					testcanvas.MustNew(ft.Area()),
+					&widgetapi.Meta{Focused: true},
+				)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := faketerm.New(tc.termSize)
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+
+			c, err := tc.container(got)
+			if err != nil {
+				t.Fatalf("tc.container => unexpected error: %v", err)
+			}
 
 			eds := event.NewDistributionSystem()
 			eh := &errorHandler{}
@@ -1320,6 +1907,114 @@ func TestKeyboard(t *testing.T) {
 	}
 }
 
+func TestKeyConflicts(t *testing.T) {
+	tests := []struct {
+		desc    string
+		opts    func() ([]Option, error)
+		global  []keybinding.Sequence
+		wantErr bool
+	}{
+		{
+			desc: "no conflicts",
+			opts: func() ([]Option, error) {
+				return []Option{
+					ScrollKeys(keyboard.KeyArrowUp, keyboard.KeyArrowDown, keyboard.KeyArrowLeft, keyboard.KeyArrowRight),
+				}, nil
+			},
+			global: []keybinding.Sequence{{keyboard.KeyCtrlQ}},
+		},
+		{
+			desc: "conflicts with scroll keys",
+			opts: func() ([]Option, error) {
+				return []Option{
+					ScrollKeys(keyboard.KeyArrowUp, keyboard.KeyArrowDown, keyboard.KeyArrowLeft, keyboard.KeyArrowRight),
+				}, nil
+			},
+			global:  []keybinding.Sequence{{keyboard.KeyArrowUp}},
+			wantErr: true,
+		},
+		{
+			desc: "conflicts with split resize keys of a sub container",
+			opts: func() ([]Option, error) {
+				return []Option{
+					SplitVertical(
+						Left(),
+						Right(
+							SplitHorizontal(
+								Top(),
+								Bottom(),
+								SplitResizeKeys('-', '+'),
+							),
+						),
+					),
+				}, nil
+			},
+			global:  []keybinding.Sequence{{'+'}},
+			wantErr: true,
+		},
+		{
+			desc: "conflicts with key bindings of a container",
+			opts: func() ([]Option, error) {
+				kb, err := keybinding.New()
+				if err != nil {
+					return nil, err
+				}
+				if err := kb.Bind(keybinding.Sequence{'g', 'g'}, func() error { return nil }); err != nil {
+					return nil, err
+				}
+				return []Option{
+					ID("root"),
+					KeyBindings(kb),
+				}, nil
+			},
+			global:  []keybinding.Sequence{{'g'}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ft, err := faketerm.New(image.Point{20, 20})
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			opts, err := tc.opts()
+			if err != nil {
+				t.Fatalf("opts => unexpected error: %v", err)
+			}
+			c, err := New(ft, opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			global, err := keybinding.New()
+			if err != nil {
+				t.Fatalf("keybinding.New => unexpected error: %v", err)
+			}
+			for _, seq := range tc.global {
+				if err := global.Bind(seq, func() error { return nil }); err != nil {
+					t.Fatalf("Bind => unexpected error: %v", err)
+				}
+			}
+
+			err = c.KeyConflicts(global)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("KeyConflicts => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// gestureWidget is a fakewidget.Mirror that displays the received gestures.
+type gestureWidget struct {
+	*fakewidget.Mirror
+}
+
+// Gesture implements widgetapi.GestureHandler.Gesture.
+func (gw *gestureWidget) Gesture(g *gesture.Gesture) error {
+	gw.Text(fmt.Sprintf("%v%v", g.Position, g.Direction))
+	return nil
+}
+
 func TestMouse(t *testing.T) {
 	tests := []struct {
 		desc      string
@@ -1332,6 +2027,41 @@ func TestMouse(t *testing.T) {
 		want          func(size image.Point) *faketerm.Terminal
 		wantErr       bool
 	}{
+		{
+			desc:     "gesture delivered to the widget where it started",
+			termSize: image.Point{60, 20},
+			container: func(ft *faketerm.Terminal) (*Container, error) {
+				return New(
+					ft,
+					SplitVertical(
+						Left(),
+						Right(
+							PlaceWidget(&gestureWidget{
+								Mirror: fakewidget.New(widgetapi.Options{}),
+							}),
+						),
+					),
+				)
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{32, 3}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{34, 3}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{36, 4}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				mirror := fakewidget.New(widgetapi.Options{})
+				mirror.Text("(2,3)DirectionRight")
+				fakewidget.MustDrawWithMirror(
+					mirror,
+					ft,
+					testcanvas.MustNew(image.Rect(30, 0, 60, 20)),
+					&widgetapi.Meta{Focused: true},
+				)
+				return ft
+			},
+		},
 		{
 			desc:     "mouse click outside of the terminal is ignored",
 			termSize: image.Point{10, 10},
@@ -1994,6 +2724,135 @@ func TestMouse(t *testing.T) {
 	}
 }
 
+func TestInject(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{40, 20})
+	c, err := New(
+		ft,
+		SplitVertical(
+			Left(
+				PlaceWidget(fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})),
+			),
+			Right(
+				NoFocus(),
+				PlaceWidget(fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})),
+			),
+		),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	events := []terminalapi.Event{
+		// Focus the left container.
+		&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft},
+		&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonRelease},
+		// Clicking the right container doesn't move the focus.
+		&terminalapi.Mouse{Position: image.Point{39, 19}, Button: mouse.ButtonLeft},
+		&terminalapi.Mouse{Position: image.Point{39, 19}, Button: mouse.ButtonRelease},
+		&terminalapi.Keyboard{Key: 'a'},
+	}
+	for _, ev := range events {
+		if err := c.Inject(ev); err != nil {
+			t.Fatalf("Inject(%v) => unexpected error: %v", ev, err)
+		}
+	}
+	if err := c.Inject(&terminalapi.Resize{}); err == nil {
+		t.Errorf("Inject(Resize) => got nil error, want an error for an unsupported event")
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	want := faketerm.MustNew(ft.Size())
+	fakewidget.MustDraw(
+		want,
+		testcanvas.MustNew(image.Rect(0, 0, 20, 20)),
+		&widgetapi.Meta{Focused: true},
+		widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused},
+		&terminalapi.Keyboard{Key: 'a'},
+	)
+	fakewidget.MustDraw(
+		want,
+		testcanvas.MustNew(image.Rect(20, 0, 40, 20)),
+		&widgetapi.Meta{},
+		widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused},
+	)
+	if diff := faketerm.Diff(want, ft); diff != "" {
+		t.Errorf("Draw => %v", diff)
+	}
+}
+
+func TestFocusAndCommandFuncs(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{40, 20})
+	kb, err := keybinding.New()
+	if err != nil {
+		t.Fatalf("keybinding.New => unexpected error: %v", err)
+	}
+	if err := kb.Bind(keybinding.Sequence{keyboard.KeyCtrlS}, func() error { return nil }, keybinding.Description("Save")); err != nil {
+		t.Fatalf("Bind => unexpected error: %v", err)
+	}
+	c, err := New(
+		ft,
+		SplitVertical(
+			Left(
+				ID("left"),
+				KeyBindings(kb),
+				PlaceWidget(fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})),
+			),
+			Right(
+				ID("right"),
+				PlaceWidget(fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})),
+			),
+		),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	var got []string
+	c.SetFocusFunc(func(id string) {
+		got = append(got, "focus "+id)
+	})
+	c.SetCommandFunc(func(b keybinding.Binding) {
+		got = append(got, fmt.Sprintf("command %v %s", b.Sequence, b.Description))
+	})
+
+	events := []terminalapi.Event{
+		// Focus the left container.
+		&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft},
+		&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonRelease},
+		// Clicking the focused container again doesn't change the focus.
+		&terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonLeft},
+		&terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonRelease},
+		&terminalapi.Keyboard{Key: keyboard.KeyCtrlS},
+		// Keys that aren't bound aren't commands.
+		&terminalapi.Keyboard{Key: 'a'},
+		// Focus the right container.
+		&terminalapi.Mouse{Position: image.Point{39, 19}, Button: mouse.ButtonLeft},
+		&terminalapi.Mouse{Position: image.Point{39, 19}, Button: mouse.ButtonRelease},
+	}
+	for _, ev := range events {
+		if err := c.Inject(ev); err != nil {
+			t.Fatalf("Inject(%v) => unexpected error: %v", ev, err)
+		}
+	}
+
+	want := []string{
+		"focus left",
+		"command KeyCtrlS Save",
+		"focus right",
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("unexpected calls (-want, +got):\n%s", diff)
+	}
+}
+
 func TestUpdate(t *testing.T) {
 	tests := []struct {
 		desc       string
@@ -20,32 +20,61 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"time"
 
 	"github.com/mum4k/termdash/cell"
 	"github.com/mum4k/termdash/private/area"
 	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/braille"
 	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/fallback"
 	"github.com/mum4k/termdash/widgetapi"
 )
 
 // drawTree draws this container and all of its sub containers.
 func drawTree(c *Container) error {
-	var errStr string
-
 	root := rootCont(c)
 	size := root.term.Size()
-	ar, err := root.opts.margin.apply(image.Rect(0, 0, size.X, size.Y))
-	if err != nil {
+	full := image.Rect(0, 0, size.X, size.Y)
+	if err := root.respond(full); err != nil {
+		return err
+	}
+	bar, rest := root.statusBarAreas(full)
+	if m := root.maximized; m != nil {
+		if err := drawMaximized(root, m, rest); err != nil {
+			return err
+		}
+	} else {
+		ar, err := root.opts.margin.apply(rest)
+		if err != nil {
+			return err
+		}
+		root.area = ar
+		if err := drawSubtree(root); err != nil {
+			return err
+		}
+	}
+	if err := root.drawStatusBar(bar); err != nil {
 		return err
 	}
-	root.area = ar
+	root.drawnSize = size
+	return nil
+}
 
-	preOrder(root, &errStr, visitFunc(func(c *Container) error {
+// drawSubtree draws the container and all of its sub containers.
+// The area of the container must already be set, i.e. the container must have
+// been drawn as part of the entire tree before.
+func drawSubtree(c *Container) error {
+	var errStr string
+	preOrder(c, &errStr, visitFunc(func(c *Container) error {
 		first, second, err := c.split()
 		if err != nil {
 			return err
 		}
 		if c.first != nil {
+			if err := c.first.respond(first); err != nil {
+				return err
+			}
 			ar, err := c.first.opts.margin.apply(first)
 			if err != nil {
 				return err
@@ -54,6 +83,9 @@ func drawTree(c *Container) error {
 		}
 
 		if c.second != nil {
+			if err := c.second.respond(second); err != nil {
+				return err
+			}
 			ar, err := c.second.opts.margin.apply(second)
 			if err != nil {
 				return err
@@ -65,6 +97,36 @@ func drawTree(c *Container) error {
 	if errStr != "" {
 		return errors.New(errStr)
 	}
+	// The badges of the ancestors can overlap the subtree.
+	return drawBadges(rootCont(c))
+}
+
+// drawBadges draws the badges of the container and all of its sub
+// containers. The badges are drawn after all the containers, so that the
+// borders and widgets of sub containers don't overwrite them.
+func drawBadges(c *Container) error {
+	var errStr string
+	preOrder(c, &errStr, visitFunc(func(c *Container) error {
+		b := c.opts.badge
+		if b == nil {
+			return nil
+		}
+		ar := b.Area(c.area)
+		if ar == image.ZR {
+			return nil
+		}
+		cvs, err := canvas.New(ar)
+		if err != nil {
+			return err
+		}
+		if err := b.Draw(cvs); err != nil {
+			return err
+		}
+		return applyCanvas(c, cvs)
+	}))
+	if errStr != "" {
+		return errors.New(errStr)
+	}
 	return nil
 }
 
@@ -99,7 +161,10 @@ func drawBorder(c *Container) error {
 	); err != nil {
 		return err
 	}
-	return cvs.Apply(c.term)
+	if err := drawBorderButtons(c, cvs, cOpts); err != nil {
+		return err
+	}
+	return applyCanvas(c, cvs)
 }
 
 // drawWidget requests the widget to draw on the canvas.
@@ -116,29 +181,78 @@ func drawWidget(c *Container) error {
 		return nil
 	}
 
-	needSize := image.Point{1, 1}
-	wOpts := c.opts.widget.Options()
-	if wOpts.MinimumSize.X > 0 && wOpts.MinimumSize.Y > 0 {
-		needSize = wOpts.MinimumSize
+	meta := &widgetapi.Meta{
+		Focused:      c.focusTracker.isActive(c),
+		Capabilities: c.term.Capabilities(),
 	}
 
+	needSize := c.widgetNeedSize()
 	if widgetArea.Dx() < needSize.X || widgetArea.Dy() < needSize.Y {
+		c.lastDrawn = nil
+		if vp := c.scrollViewport(widgetArea, needSize); vp != nil {
+			return drawScrolled(c, vp, meta)
+		}
 		return drawResize(c, c.usable())
 	}
+	if ld := c.lastDrawn; ld != nil && ld.reusable(c.opts.widget, widgetArea, meta, c.opts.inherited.refreshInterval) {
+		return ld.cvs.Apply(c.term)
+	}
 
 	cvs, err := canvas.New(widgetArea)
 	if err != nil {
 		return err
 	}
 
-	meta := &widgetapi.Meta{
-		Focused: c.focusTracker.isActive(c),
-	}
-
 	if err := c.opts.widget.Draw(cvs, meta); err != nil {
 		return err
 	}
-	return cvs.Apply(c.term)
+	if err := applyCanvas(c, cvs); err != nil {
+		return err
+	}
+
+	c.lastDrawn = nil
+	_, tracker := c.opts.widget.(widgetapi.ChangeTracker)
+	if tracker || c.opts.inherited.refreshInterval > 0 {
+		c.lastDrawn = &drawnWidget{
+			widget: c.opts.widget,
+			area:   widgetArea,
+			meta:   *meta,
+			cvs:    cvs,
+			at:     timeNow(),
+		}
+	}
+	return nil
+}
+
+// drawnWidget is the content drawn by a widget that implements
+// widgetapi.ChangeTracker or that is in a container with the RefreshInterval
+// option.
+type drawnWidget struct {
+	// widget is the widget that drew the content.
+	widget widgetapi.Widget
+	// area is the area of the canvas.
+	area image.Rectangle
+	// meta is the metadata passed to Draw.
+	meta widgetapi.Meta
+	// cvs is the canvas the widget drew on.
+	cvs *canvas.Canvas
+	// at is when the widget drew the content.
+	at time.Time
+}
+
+// reusable determines if the drawn content can be used instead of asking the
+// widget to draw on a canvas with the specified area and metadata. Content
+// drawn less than the refresh interval ago is reused even if the widget
+// changed.
+func (dw *drawnWidget) reusable(w widgetapi.Widget, ar image.Rectangle, meta *widgetapi.Meta, refresh time.Duration) bool {
+	if dw.widget != w || dw.area != ar || dw.meta != *meta {
+		return false
+	}
+	if refresh > 0 && timeNow().Sub(dw.at) < refresh {
+		return true
+	}
+	ct, ok := w.(widgetapi.ChangeTracker)
+	return ok && !ct.Changed()
 }
 
 // drawResize draws an unicode character indicating that the size is too small to draw this container.
@@ -155,7 +269,7 @@ func drawResize(c *Container, area image.Rectangle) error {
 	if err := draw.ResizeNeeded(cvs); err != nil {
 		return err
 	}
-	return cvs.Apply(c.term)
+	return applyCanvas(c, cvs)
 }
 
 // drawCont draws the container and its widget.
@@ -173,3 +287,23 @@ func drawCont(c *Container) error {
 	}
 	return nil
 }
+
+// applyCanvas applies the canvas to the terminal, replacing any runes the
+// terminal cannot display and halving the density of braille patterns if
+// requested.
+func applyCanvas(c *Container, cvs *canvas.Canvas) error {
+	if rootCont(c).opts.brailleHalfDensity {
+		if err := braille.HalfDensity(cvs); err != nil {
+			return err
+		}
+	}
+	if glyph := rootCont(c).opts.emojiFallback; glyph != 0 {
+		if err := fallback.Emoji(cvs, glyph); err != nil {
+			return err
+		}
+	}
+	if err := fallback.Canvas(cvs, c.term.Capabilities().Unicode); err != nil {
+		return err
+	}
+	return cvs.Apply(c.term)
+}
@@ -16,16 +16,20 @@ package container
 
 import (
 	"image"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/mum4k/termdash/align"
 	"github.com/mum4k/termdash/cell"
 	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/private/canvas"
 	"github.com/mum4k/termdash/private/canvas/testcanvas"
 	"github.com/mum4k/termdash/private/draw"
 	"github.com/mum4k/termdash/private/draw/testdraw"
 	"github.com/mum4k/termdash/private/faketerm"
 	"github.com/mum4k/termdash/private/fakewidget"
+	"github.com/mum4k/termdash/terminal/terminalapi"
 	"github.com/mum4k/termdash/widgetapi"
 )
 
@@ -1145,3 +1149,433 @@ func TestDrawHandlesTerminalResize(t *testing.T) {
 		})
 	}
 }
+
+func TestDrawFallsBackToTerminalCapabilities(t *testing.T) {
+	tests := []struct {
+		desc    string
+		unicode terminalapi.UnicodeLevel
+		want    string
+	}{
+		{
+			desc:    "full unicode support",
+			unicode: terminalapi.UnicodeFull,
+			want: "┌───────┐\n" +
+				"│┌─────┐│\n" +
+				"││(7,3)││\n" +
+				"│└─────┘│\n" +
+				"└───────┘\n",
+		},
+		{
+			desc:    "ASCII only",
+			unicode: terminalapi.UnicodeASCII,
+			want: "+-------+\n" +
+				"|+-----+|\n" +
+				"||(7,3)||\n" +
+				"|+-----+|\n" +
+				"+-------+\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			caps := faketerm.DefaultCapabilities
+			caps.Unicode = tc.unicode
+			got := faketerm.MustNew(image.Point{9, 5}, faketerm.WithCapabilities(caps))
+			c, err := New(
+				got,
+				Border(linestyle.Light),
+				PlaceWidget(fakewidget.New(widgetapi.Options{})),
+			)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			if gotStr := got.String(); gotStr != tc.want {
+				t.Errorf("Draw => got:\n%s\nwant:\n%s", gotStr, tc.want)
+			}
+		})
+	}
+}
+
+// changeTracker is a fake widget that implements widgetapi.ChangeTracker and
+// counts the calls to Draw.
+type changeTracker struct {
+	*fakewidget.Mirror
+
+	mu      sync.Mutex
+	changed bool
+	draws   int
+}
+
+// Draw implements widgetapi.Widget.Draw.
+func (ct *changeTracker) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.changed = false
+	ct.draws++
+	return ct.Mirror.Draw(cvs, meta)
+}
+
+// Changed implements widgetapi.ChangeTracker.Changed.
+func (ct *changeTracker) Changed() bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.changed
+}
+
+func TestDrawSkipsUnchangedWidgets(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{9, 5})
+	ct := &changeTracker{
+		Mirror:  fakewidget.New(widgetapi.Options{}),
+		changed: true,
+	}
+	c, err := New(ft, PlaceWidget(ct))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	steps := []struct {
+		desc      string
+		change    func() error
+		wantDraws int
+	}{
+		{
+			desc:      "draws the widget the first time",
+			wantDraws: 1,
+		},
+		{
+			desc:      "skips the widget that didn't change",
+			wantDraws: 1,
+		},
+		{
+			desc: "draws the widget that changed",
+			change: func() error {
+				ct.mu.Lock()
+				defer ct.mu.Unlock()
+				ct.changed = true
+				return nil
+			},
+			wantDraws: 2,
+		},
+		{
+			desc: "draws the widget when the terminal size changes",
+			change: func() error {
+				return ft.Resize(image.Point{10, 5})
+			},
+			wantDraws: 3,
+		},
+	}
+
+	for _, step := range steps {
+		if step.change != nil {
+			if err := step.change(); err != nil {
+				t.Fatalf("%s: change => unexpected error: %v", step.desc, err)
+			}
+		}
+		if err := c.Draw(); err != nil {
+			t.Fatalf("%s: Draw => unexpected error: %v", step.desc, err)
+		}
+		if ct.draws != step.wantDraws {
+			t.Errorf("%s: got %d calls to Draw, want %d", step.desc, ct.draws, step.wantDraws)
+		}
+
+		want := faketerm.MustNew(ft.Size())
+		cvs := testcanvas.MustNew(want.Area())
+		fakewidget.MustDraw(want, cvs, &widgetapi.Meta{Capabilities: faketerm.DefaultCapabilities}, widgetapi.Options{})
+		if diff := faketerm.Diff(want, ft); diff != "" {
+			t.Errorf("%s: Draw => %v", step.desc, diff)
+		}
+	}
+}
+
+// drawCounter is a fake widget that counts the calls to Draw.
+type drawCounter struct {
+	*fakewidget.Mirror
+
+	mu    sync.Mutex
+	draws int
+}
+
+// Draw implements widgetapi.Widget.Draw.
+func (dc *drawCounter) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.draws++
+	return dc.Mirror.Draw(cvs, meta)
+}
+
+func TestDrawRefreshInterval(t *testing.T) {
+	if _, err := New(faketerm.MustNew(image.Point{9, 5}), RefreshInterval(-1)); err == nil {
+		t.Errorf("New => got nil error, want one for a negative RefreshInterval")
+	}
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	ft := faketerm.MustNew(image.Point{40, 5})
+	lazy := &drawCounter{Mirror: fakewidget.New(widgetapi.Options{})}
+	prompt := &drawCounter{Mirror: fakewidget.New(widgetapi.Options{})}
+	c, err := New(
+		ft,
+		RefreshInterval(time.Minute),
+		SplitVertical(
+			Left(PlaceWidget(lazy)),
+			Right(
+				RefreshInterval(0),
+				PlaceWidget(prompt),
+			),
+		),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	steps := []struct {
+		desc            string
+		change          func() error
+		wantLazyDraws   int
+		wantPromptDraws int
+	}{
+		{
+			desc:            "draws both widgets the first time",
+			wantLazyDraws:   1,
+			wantPromptDraws: 1,
+		},
+		{
+			desc: "throttles the lazy widget",
+			change: func() error {
+				lazy.Text("changed")
+				now = now.Add(30 * time.Second)
+				return nil
+			},
+			wantLazyDraws:   1,
+			wantPromptDraws: 2,
+		},
+		{
+			desc: "draws the lazy widget after the interval",
+			change: func() error {
+				now = now.Add(30 * time.Second)
+				return nil
+			},
+			wantLazyDraws:   2,
+			wantPromptDraws: 3,
+		},
+		{
+			desc: "draws the lazy widget when the terminal size changes",
+			change: func() error {
+				return ft.Resize(image.Point{44, 5})
+			},
+			wantLazyDraws:   3,
+			wantPromptDraws: 4,
+		},
+	}
+
+	for _, step := range steps {
+		if step.change != nil {
+			if err := step.change(); err != nil {
+				t.Fatalf("%s: change => unexpected error: %v", step.desc, err)
+			}
+		}
+		if err := c.Draw(); err != nil {
+			t.Fatalf("%s: Draw => unexpected error: %v", step.desc, err)
+		}
+		if lazy.draws != step.wantLazyDraws {
+			t.Errorf("%s: got %d calls to Draw of the lazy widget, want %d", step.desc, lazy.draws, step.wantLazyDraws)
+		}
+		if prompt.draws != step.wantPromptDraws {
+			t.Errorf("%s: got %d calls to Draw of the prompt widget, want %d", step.desc, prompt.draws, step.wantPromptDraws)
+		}
+	}
+
+	// The throttled content is displayed again rather than cleared.
+	if err := ft.Clear(); err != nil {
+		t.Fatalf("Clear => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	want := faketerm.MustNew(ft.Size())
+	mirror := fakewidget.New(widgetapi.Options{})
+	mirror.Text("changed")
+	fakewidget.MustDrawWithMirror(mirror, want, testcanvas.MustNew(image.Rect(0, 0, 22, 5)), &widgetapi.Meta{Capabilities: faketerm.DefaultCapabilities})
+	fakewidget.MustDraw(want, testcanvas.MustNew(image.Rect(22, 0, 44, 5)), &widgetapi.Meta{Capabilities: faketerm.DefaultCapabilities}, widgetapi.Options{})
+	if diff := faketerm.Diff(want, ft); diff != "" {
+		t.Errorf("Draw => %v", diff)
+	}
+}
+
+func TestDrawSubtree(t *testing.T) {
+	tests := []struct {
+		desc    string
+		id      string
+		resize  *image.Point
+		want    func(size image.Point) *faketerm.Terminal
+		wantErr bool
+	}{
+		{
+			desc:    "fails on an unknown container ID",
+			id:      "unknown",
+			wantErr: true,
+		},
+		{
+			desc: "draws only the subtree",
+			id:   "left",
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				left := fakewidget.New(widgetapi.Options{})
+				left.Text("a")
+				fakewidget.MustDrawWithMirror(left, ft, testcanvas.MustNew(image.Rect(0, 0, 15, 5)), &widgetapi.Meta{})
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(15, 0, 30, 5)), &widgetapi.Meta{}, widgetapi.Options{})
+				return ft
+			},
+		},
+		{
+			desc:   "draws everything when the terminal was resized",
+			id:     "left",
+			resize: &image.Point{32, 5},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				left := fakewidget.New(widgetapi.Options{})
+				left.Text("a")
+				fakewidget.MustDrawWithMirror(left, ft, testcanvas.MustNew(image.Rect(0, 0, 16, 5)), &widgetapi.Meta{})
+				right := fakewidget.New(widgetapi.Options{})
+				right.Text("b")
+				fakewidget.MustDrawWithMirror(right, ft, testcanvas.MustNew(image.Rect(16, 0, 32, 5)), &widgetapi.Meta{})
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := faketerm.MustNew(image.Point{30, 5})
+			left := fakewidget.New(widgetapi.Options{})
+			right := fakewidget.New(widgetapi.Options{})
+			c, err := New(
+				got,
+				SplitVertical(
+					Left(ID("left"), PlaceWidget(left)),
+					Right(ID("right"), PlaceWidget(right)),
+				),
+			)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			left.Text("a")
+			right.Text("b")
+			if tc.resize != nil {
+				if err := got.Resize(*tc.resize); err != nil {
+					t.Fatalf("Resize => unexpected error: %v", err)
+				}
+			}
+
+			err = c.DrawSubtree(tc.id)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("DrawSubtree => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := faketerm.Diff(tc.want(got.Size()), got); diff != "" {
+				t.Errorf("DrawSubtree => %v", diff)
+			}
+		})
+	}
+}
+
+func TestEmojiFallback(t *testing.T) {
+	if _, err := New(faketerm.MustNew(image.Point{9, 5}), EmojiFallback('\u0301')); err == nil {
+		t.Errorf("New => got nil error, want one for a combining EmojiFallback")
+	}
+
+	tests := []struct {
+		desc string
+		opts []Option
+		want string
+	}{
+		{
+			desc: "draws emoji sequences as is by default",
+			want: "👨\u200d👩",
+		},
+		{
+			desc: "replaces emoji sequences with the glyph",
+			opts: []Option{EmojiFallback('*')},
+			want: "*",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			size := image.Point{12, 3}
+			got := faketerm.MustNew(size)
+			mirror := fakewidget.New(widgetapi.Options{})
+			mirror.Text("👨\u200d👩")
+			c, err := New(got, append(tc.opts, PlaceWidget(mirror))...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			want := faketerm.MustNew(size)
+			wantMirror := fakewidget.New(widgetapi.Options{})
+			wantMirror.Text(tc.want)
+			fakewidget.MustDrawWithMirror(wantMirror, want, testcanvas.MustNew(want.Area()), &widgetapi.Meta{})
+			if diff := faketerm.Diff(want, got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestBrailleHalfDensity(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		want string
+	}{
+		{
+			desc: "draws braille patterns with full density by default",
+			want: "⣿⠉",
+		},
+		{
+			desc: "moves the right column of braille patterns into the left one",
+			opts: []Option{BrailleHalfDensity()},
+			want: "⡇⠁",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			size := image.Point{12, 3}
+			got := faketerm.MustNew(size)
+			mirror := fakewidget.New(widgetapi.Options{})
+			mirror.Text("⣿⠉")
+			c, err := New(got, append(tc.opts, PlaceWidget(mirror))...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			want := faketerm.MustNew(size)
+			wantMirror := fakewidget.New(widgetapi.Options{})
+			wantMirror.Text(tc.want)
+			fakewidget.MustDrawWithMirror(wantMirror, want, testcanvas.MustNew(want.Area()), &widgetapi.Meta{})
+			if diff := faketerm.Diff(want, got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
@@ -41,6 +41,27 @@ func pointCont(c *Container, p image.Point) *Container {
 	return cont
 }
 
+// focusable determines if mouse clicks can focus the container, i.e. if
+// neither the container nor any of its ancestors has the NoFocus option.
+func focusable(c *Container) bool {
+	for ; c != nil; c = c.parent {
+		if c.opts.noFocus {
+			return false
+		}
+	}
+	return true
+}
+
+// FocusedArea returns the area of the terminal occupied by the focused
+// container, including its border. Useful to treat the focused container
+// differently when post-processing the drawn frames, see the postprocess
+// package.
+func (c *Container) FocusedArea() image.Rectangle {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.focusTracker.container.area
+}
+
 // focusTracker tracks the active (focused) container.
 // This is not thread-safe, the implementation assumes that the owner of
 // focusTracker performs locking.
@@ -87,7 +108,7 @@ func (ft *focusTracker) mouse(target *Container, m *terminalapi.Mouse) {
 	case bs == button.Down:
 		ft.candidate = target
 	case bs == button.Up && clicked:
-		if target == ft.candidate {
+		if target == ft.candidate && focusable(target) {
 			ft.container = target
 		}
 	}
@@ -453,3 +453,35 @@ func TestFocusTrackerMouse(t *testing.T) {
 		})
 	}
 }
+
+func TestFocusedArea(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{10, 4})
+	c, err := New(
+		ft,
+		SplitVertical(
+			Left(),
+			Right(),
+		),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if got, want := c.FocusedArea(), image.Rect(0, 0, 10, 4); got != want {
+		t.Errorf("FocusedArea => %v, want %v", got, want)
+	}
+
+	for _, ev := range []terminalapi.Event{
+		&terminalapi.Mouse{Position: image.Point{9, 3}, Button: mouse.ButtonLeft},
+		&terminalapi.Mouse{Position: image.Point{9, 3}, Button: mouse.ButtonRelease},
+	} {
+		if err := c.Inject(ev); err != nil {
+			t.Fatalf("Inject(%v) => unexpected error: %v", ev, err)
+		}
+	}
+	if got, want := c.FocusedArea(), image.Rect(5, 0, 10, 4); got != want {
+		t.Errorf("FocusedArea => %v, want %v", got, want)
+	}
+}
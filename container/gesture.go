@@ -0,0 +1,69 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// gesture.go contains code that delivers touch gestures to widgets.
+
+import (
+	"github.com/mum4k/termdash/gesture"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// gestureTarget feeds the mouse event to the gesture recognizer and returns
+// a function that delivers the recognized gesture to the widget under the
+// position where the gesture started. Returns nil if no gesture was recognized
+// or the widget doesn't implement widgetapi.GestureHandler.
+// Caller must hold c.mu.
+func (c *Container) gestureTarget(m *terminalapi.Mouse) (func() error, error) {
+	root := rootCont(c)
+	g := root.gestures.Mouse(m)
+	if g == nil {
+		return nil, nil
+	}
+	if root.readOnly && g.Kind != gesture.KindSwipe {
+		// Only swipes navigate, taps could change the widget.
+		return nil, nil
+	}
+
+	target := pointCont(c, g.Position)
+	if target == nil || !target.hasWidget() {
+		return nil, nil
+	}
+	gh, ok := target.opts.widget.(widgetapi.GestureHandler)
+	if !ok {
+		return nil, nil
+	}
+
+	p, ok, err := target.canvasPoint(g.Position)
+	if err != nil || !ok {
+		return nil, err
+	}
+	adjusted := *g
+	adjusted.Position = p
+	return func() error {
+		return gh.Gesture(&adjusted)
+	}, nil
+}
+
+// newGestureRecognizer returns the recognizer used by the root container.
+func newGestureRecognizer() *gesture.Recognizer {
+	r, err := gesture.NewRecognizer()
+	if err != nil {
+		// The default options are always valid.
+		panic(err)
+	}
+	return r
+}
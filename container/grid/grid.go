@@ -58,9 +58,14 @@ func (b *Builder) Build() ([]container.Option, error) {
 //   Each individual width or height is in the range 0 < v < 100.
 //   The sum of all widths is <= 100.
 //   The sum of all heights is <= 100.
+//   Weighted Rows or Columns aren't mixed with any other elements.
 // Argument fixedSizeParent indicates if any of the parent elements uses fixed
 // size splitType.
 func validate(elems []Element, fixedSizeParent bool) error {
+	if err := validateWeighted(elems); err != nil {
+		return err
+	}
+
 	heightPercSum := 0
 	widthPercSum := 0
 	for _, elem := range elems {
@@ -71,6 +76,9 @@ func validate(elems []Element, fixedSizeParent bool) error {
 					return fmt.Errorf("invalid row %v, must be a value in the range %d < v < %d", e, min, max)
 				}
 			}
+			if e.splitType == splitTypeWeighted && e.weight <= 0 {
+				return fmt.Errorf("invalid row %v, the weight must be a positive integer", e)
+			}
 			heightPercSum += e.heightPerc
 
 			if fixedSizeParent && e.splitType == splitTypeRelative {
@@ -88,6 +96,9 @@ func validate(elems []Element, fixedSizeParent bool) error {
 					return fmt.Errorf("invalid column %v, must be a value in the range %d < v < %d", e, min, max)
 				}
 			}
+			if e.splitType == splitTypeWeighted && e.weight <= 0 {
+				return fmt.Errorf("invalid column %v, the weight must be a positive integer", e)
+			}
 			widthPercSum += e.widthPerc
 
 			if fixedSizeParent && e.splitType == splitTypeRelative {
@@ -112,6 +123,45 @@ func validate(elems []Element, fixedSizeParent bool) error {
 	return nil
 }
 
+// validateWeighted validates that weighted Rows or Columns are only mixed
+// with weighted elements of the same kind at one element level.
+func validateWeighted(elems []Element) error {
+	var rows, cols, others int
+	for _, elem := range elems {
+		switch e := elem.(type) {
+		case *row:
+			if e.splitType == splitTypeWeighted {
+				rows++
+				continue
+			}
+		case *col:
+			if e.splitType == splitTypeWeighted {
+				cols++
+				continue
+			}
+		}
+		others++
+	}
+	if (rows > 0 && cols+others > 0) || (cols > 0 && rows+others > 0) {
+		return fmt.Errorf("weighted rows or columns cannot be mixed with other elements at the same level, got: %v", elems)
+	}
+	return nil
+}
+
+// weightSum returns the sum of weights of the weighted Rows and Columns.
+func weightSum(elems []Element) int {
+	sum := 0
+	for _, elem := range elems {
+		switch e := elem.(type) {
+		case *row:
+			sum += e.weight
+		case *col:
+			sum += e.weight
+		}
+	}
+	return sum
+}
+
 // build recursively builds the container options according to the elements
 // that were added to the builder.
 // The parentHeightPerc and parentWidthPerc percent indicate the relative size
@@ -132,9 +182,12 @@ func build(elems []Element, parentHeightPerc, parentWidthPerc int) []container.O
 			childHeightPerc := parentHeightPerc - e.heightPerc
 
 			var splitOpts []container.SplitOption
-			if e.splitType == splitTypeRelative {
+			switch e.splitType {
+			case splitTypeRelative:
 				splitOpts = append(splitOpts, container.SplitPercent(perc))
-			} else {
+			case splitTypeWeighted:
+				splitOpts = append(splitOpts, container.SplitWeights(e.weight, weightSum(elems)))
+			default:
 				splitOpts = append(splitOpts, container.SplitFixed(e.heightFixed))
 			}
 
@@ -154,9 +207,12 @@ func build(elems []Element, parentHeightPerc, parentWidthPerc int) []container.O
 			childWidthPerc := parentWidthPerc - e.widthPerc
 
 			var splitOpts []container.SplitOption
-			if e.splitType == splitTypeRelative {
+			switch e.splitType {
+			case splitTypeRelative:
 				splitOpts = append(splitOpts, container.SplitPercent(perc))
-			} else {
+			case splitTypeWeighted:
+				splitOpts = append(splitOpts, container.SplitWeights(e.weight, weightSum(elems)))
+			default:
 				splitOpts = append(splitOpts, container.SplitFixed(e.widthFixed))
 			}
 
@@ -227,11 +283,13 @@ func (st splitType) String() string {
 var splitTypeNames = map[splitType]string{
 	splitTypeRelative: "splitTypeRelative",
 	splitTypeFixed:    "splitTypeFixed",
+	splitTypeWeighted: "splitTypeWeighted",
 }
 
 const (
 	splitTypeRelative splitType = iota
 	splitTypeFixed
+	splitTypeWeighted
 )
 
 // row is a row in the grid.
@@ -248,6 +306,10 @@ type row struct {
 	// Only set when splitType is splitTypeFixed.
 	heightFixed int
 
+	// weight is the height of this row relative to the other rows.
+	// Only set when splitType is splitTypeWeighted.
+	weight int
+
 	// subElem are the sub Rows or Columns or a single widget.
 	subElem []Element
 
@@ -260,7 +322,7 @@ func (row) isElement() {}
 
 // String implements fmt.Stringer.
 func (r *row) String() string {
-	return fmt.Sprintf("row{splitType:%v, heightPerc:%d, heightFixed:%d, sub:%v}", r.splitType, r.heightPerc, r.heightFixed, r.subElem)
+	return fmt.Sprintf("row{splitType:%v, heightPerc:%d, heightFixed:%d, weight:%d, sub:%v}", r.splitType, r.heightPerc, r.heightFixed, r.weight, r.subElem)
 }
 
 // col is a column in the grid.
@@ -277,6 +339,10 @@ type col struct {
 	// Only set when splitType is splitTypeRelative.
 	widthFixed int
 
+	// weight is the width of this column relative to the other columns.
+	// Only set when splitType is splitTypeWeighted.
+	weight int
+
 	// subElem are the sub Rows or Columns or a single widget.
 	subElem []Element
 
@@ -289,7 +355,7 @@ func (col) isElement() {}
 
 // String implements fmt.Stringer.
 func (c *col) String() string {
-	return fmt.Sprintf("col{splitType:%v, widthPerc:%d, widthFixed:%d, sub:%v}", c.splitType, c.widthPerc, c.widthFixed, c.subElem)
+	return fmt.Sprintf("col{splitType:%v, widthPerc:%d, widthFixed:%d, weight:%d, sub:%v}", c.splitType, c.widthPerc, c.widthFixed, c.weight, c.subElem)
 }
 
 // widget is a widget placed into the grid.
@@ -363,6 +429,32 @@ func RowHeightFixedWithOpts(heightCells int, cOpts []container.Option, subElemen
 	}
 }
 
+// RowWeighted creates a row whose height is relative to the other weighted
+// rows at the same level, e.g. three rows with weights 2, 1 and 1 get half,
+// quarter and quarter of the parent element height respectively.
+// The weight must be a positive integer. Weighted rows cannot be mixed with
+// any other elements at the same level.
+// The subElements can be either a single Widget or any combination of Rows and
+// Columns.
+func RowWeighted(weight int, subElements ...Element) Element {
+	return &row{
+		splitType: splitTypeWeighted,
+		weight:    weight,
+		subElem:   subElements,
+	}
+}
+
+// RowWeightedWithOpts is like RowWeighted, but also allows to apply
+// additional options to the container that represents the row.
+func RowWeightedWithOpts(weight int, cOpts []container.Option, subElements ...Element) Element {
+	return &row{
+		splitType: splitTypeWeighted,
+		weight:    weight,
+		subElem:   subElements,
+		cOpts:     cOpts,
+	}
+}
+
 // ColWidthPerc creates a column of the specified relative width.
 // The width is supplied as width percentage of the parent element.
 // The sum of all widths at the same level cannot be larger than 100%. If it
@@ -417,6 +509,32 @@ func ColWidthFixedWithOpts(widthCells int, cOpts []container.Option, subElements
 	}
 }
 
+// ColWeighted creates a column whose width is relative to the other weighted
+// columns at the same level, e.g. three columns with weights 2, 1 and 1 get
+// half, quarter and quarter of the parent element width respectively.
+// The weight must be a positive integer. Weighted columns cannot be mixed
+// with any other elements at the same level.
+// The subElements can be either a single Widget or any combination of Rows and
+// Columns.
+func ColWeighted(weight int, subElements ...Element) Element {
+	return &col{
+		splitType: splitTypeWeighted,
+		weight:    weight,
+		subElem:   subElements,
+	}
+}
+
+// ColWeightedWithOpts is like ColWeighted, but also allows to apply
+// additional options to the container that represents the column.
+func ColWeightedWithOpts(weight int, cOpts []container.Option, subElements ...Element) Element {
+	return &col{
+		splitType: splitTypeWeighted,
+		weight:    weight,
+		subElem:   subElements,
+		cOpts:     cOpts,
+	}
+}
+
 // Widget adds a widget into the Row or Column.
 // The options will be applied to the container that directly holds this
 // widget.
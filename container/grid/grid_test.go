@@ -578,6 +578,88 @@ func TestBuilder(t *testing.T) {
 				return ft
 			},
 		},
+		{
+			desc:     "fails when Row weight is zero",
+			termSize: image.Point{10, 10},
+			builder: func() *Builder {
+				b := New()
+				b.Add(
+					RowWeighted(0),
+					RowWeighted(1),
+				)
+				return b
+			}(),
+			wantErr: true,
+		},
+		{
+			desc:     "fails when weighted Columns are mixed with relative Columns",
+			termSize: image.Point{10, 10},
+			builder: func() *Builder {
+				b := New()
+				b.Add(
+					ColWeighted(1),
+					ColWidthPerc(50),
+				)
+				return b
+			}(),
+			wantErr: true,
+		},
+		{
+			desc:     "fails when weighted Rows are mixed with weighted Columns",
+			termSize: image.Point{10, 10},
+			builder: func() *Builder {
+				b := New()
+				b.Add(
+					RowWeighted(1),
+					ColWeighted(1),
+				)
+				return b
+			}(),
+			wantErr: true,
+		},
+		{
+			desc:     "three weighted rows",
+			termSize: image.Point{10, 20},
+			builder: func() *Builder {
+				b := New()
+				b.Add(
+					RowWeighted(2, Widget(mirror())),
+					RowWeighted(1, Widget(mirror())),
+					RowWeightedWithOpts(1, []container.Option{container.Border(linestyle.Light)}, Widget(mirror())),
+				)
+				return b
+			}(),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(0, 0, 10, 10)), &widgetapi.Meta{}, widgetapi.Options{})
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(0, 10, 10, 15)), &widgetapi.Meta{}, widgetapi.Options{})
+				cvs := testcanvas.MustNew(image.Rect(0, 15, 10, 20))
+				testdraw.MustBorder(cvs, cvs.Area())
+				testcanvas.MustApply(cvs, ft)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(1, 16, 9, 19)), &widgetapi.Meta{}, widgetapi.Options{})
+				return ft
+			},
+		},
+		{
+			desc:     "three weighted columns",
+			termSize: image.Point{40, 10},
+			builder: func() *Builder {
+				b := New()
+				b.Add(
+					ColWeighted(1, Widget(mirror())),
+					ColWeighted(1, Widget(mirror())),
+					ColWeighted(2, Widget(mirror())),
+				)
+				return b
+			}(),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(0, 0, 10, 10)), &widgetapi.Meta{}, widgetapi.Options{})
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(10, 0, 20, 10)), &widgetapi.Meta{}, widgetapi.Options{})
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(20, 0, 40, 10)), &widgetapi.Meta{}, widgetapi.Options{})
+				return ft
+			},
+		},
 		{
 			desc:     "two equal columns",
 			termSize: image.Point{20, 10},
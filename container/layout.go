@@ -0,0 +1,115 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// layout.go contains code that describes the structure of the container tree.
+
+import (
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// Layout describes a container in the tree and its sub containers, i.e. the
+// splits, borders and widgets the tree was created with. Options that don't
+// affect the structure of the tree, e.g. margins or key bindings, aren't
+// described.
+//
+// Layouts can be serialized as JSON or YAML documents, see the layoutspec
+// package.
+type Layout struct {
+	// ID is the identifier of the container set by the ID() option.
+	ID string
+
+	// Border is the style of the border around the container.
+	Border linestyle.LineStyle
+	// BorderTitle is the title within the border and BorderTitleHAlign is
+	// its alignment.
+	BorderTitle       string
+	BorderTitleHAlign align.Horizontal
+	// BorderColor is the color of the border, including the color inherited
+	// from the parent container.
+	BorderColor cell.Color
+
+	// Widget is the widget placed in the container, nil if the container
+	// doesn't have a widget.
+	Widget widgetapi.Widget
+	// WidgetKind is the registered kind the widget was created from, empty
+	// if it wasn't recorded with the WidgetKind option.
+	WidgetKind string
+
+	// Split describes the sub containers, nil if the container isn't split.
+	Split *LayoutSplit
+}
+
+// LayoutSplit describes how a container is split.
+type LayoutSplit struct {
+	// Vertical is true if the container is split vertically (left and right)
+	// and false if it is split horizontally (top and bottom).
+	Vertical bool
+
+	// Percent is the size of the first container as a percentage of the
+	// available space, see SplitPercent.
+	Percent int
+	// Fixed is the size of the first container in cells or DefaultSplitFixed
+	// if the split doesn't have a fixed size, see SplitFixed.
+	Fixed int
+	// Weights are the relative sizes of the first and the second container
+	// or nil if the split doesn't have weights, see SplitWeights.
+	Weights *[2]int
+
+	// First is the left or the top container.
+	First *Layout
+	// Second is the right or the bottom container.
+	Second *Layout
+}
+
+// Layout returns the layout of the container tree, starting with the root
+// container.
+func (c *Container) Layout() *Layout {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return rootCont(c).layout()
+}
+
+// layout recursively describes the container and its sub containers.
+func (c *Container) layout() *Layout {
+	l := &Layout{
+		ID:                c.opts.id,
+		Border:            c.opts.border,
+		BorderTitle:       c.opts.borderTitle,
+		BorderTitleHAlign: c.opts.borderTitleHAlign,
+		BorderColor:       c.opts.inherited.borderColor,
+		Widget:            c.opts.widget,
+		WidgetKind:        c.opts.widgetKind,
+	}
+	if c.first == nil {
+		return l
+	}
+
+	l.Split = &LayoutSplit{
+		Vertical: c.opts.split == splitTypeVertical,
+		Percent:  c.opts.splitPercent,
+		Fixed:    c.opts.splitFixed,
+		First:    c.first.layout(),
+		Second:   c.second.layout(),
+	}
+	if w := c.opts.splitWeights; w != nil {
+		l.Split.Weights = &[2]int{w.first, w.second}
+	}
+	return l
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/private/fakewidget"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+func TestLayout(t *testing.T) {
+	w := fakewidget.New(widgetapi.Options{})
+	c, err := New(
+		faketerm.MustNew(image.Point{20, 10}),
+		ID("root"),
+		BorderColor(cell.ColorRed),
+		SplitVertical(
+			Left(
+				SplitHorizontal(
+					Top(Border(linestyle.Light), BorderTitle("top"), BorderTitleAlignRight()),
+					Bottom(PlaceWidget(w)),
+					SplitWeights(1, 2),
+				),
+			),
+			Right(ID("right"), BorderColor(cell.ColorBlue)),
+			SplitFixed(5),
+		),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	want := &Layout{
+		ID:          "root",
+		BorderColor: cell.ColorRed,
+		Split: &LayoutSplit{
+			Vertical: true,
+			Percent:  DefaultSplitPercent,
+			Fixed:    5,
+			First: &Layout{
+				BorderColor: cell.ColorRed,
+				Split: &LayoutSplit{
+					Percent: DefaultSplitPercent,
+					Fixed:   DefaultSplitFixed,
+					Weights: &[2]int{1, 2},
+					First: &Layout{
+						Border:            linestyle.Light,
+						BorderTitle:       "top",
+						BorderTitleHAlign: align.HorizontalRight,
+						BorderColor:       cell.ColorRed,
+					},
+					Second: &Layout{
+						BorderColor: cell.ColorRed,
+						Widget:      w,
+					},
+				},
+			},
+			Second: &Layout{
+				ID:          "right",
+				BorderColor: cell.ColorBlue,
+			},
+		},
+	}
+	got := c.Layout()
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("Layout => unexpected diff (-want, +got):\n%s", diff)
+	}
+	if got.Split.First.Split.Second.Widget != w {
+		t.Errorf("Layout => got widget %v, want %v", got.Split.First.Split.Second.Widget, w)
+	}
+}
+
+func TestWidgetKind(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{20, 10})
+	if _, err := New(ft, WidgetKind("fake")); err == nil {
+		t.Errorf("New => got nil error, want one for WidgetKind without a widget")
+	}
+
+	w := fakewidget.New(widgetapi.Options{})
+	c, err := New(ft, ID("root"), PlaceWidget(w), WidgetKind("fake"))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if got, want := c.Layout().WidgetKind, "fake"; got != want {
+		t.Errorf("Layout => got WidgetKind %q, want %q", got, want)
+	}
+
+	// Placing another widget forgets the kind.
+	if err := c.Update("root", PlaceWidget(w)); err != nil {
+		t.Fatalf("Update => unexpected error: %v", err)
+	}
+	if got := c.Layout().WidgetKind; got != "" {
+		t.Errorf("Layout => got WidgetKind %q after PlaceWidget, want it forgotten", got)
+	}
+}
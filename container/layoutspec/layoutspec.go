@@ -0,0 +1,426 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package layoutspec builds container layouts from declarative JSON or YAML
+// documents.
+//
+// A document describes a tree of containers. Each container either holds a
+// widget identified by a name, or is split into two child containers:
+//
+//	{
+//	  "border": "light",
+//	  "borderTitle": "Dashboard",
+//	  "split": {
+//	    "direction": "vertical",
+//	    "percent": 30,
+//	    "first": {"id": "menu", "widget": "menu"},
+//	    "second": {"widget": "chart"}
+//	  }
+//	}
+//
+// The widgets are created by the application and provided by their names when
+// the layout is built, so the layout can be changed by end users without
+// recompiling the application. Containers can also hold new widgets of kinds
+// registered via widgetapi.Register, e.g. {"kind": "clock.Analog"}.
+//
+// The same document can be written as YAML:
+//
+//	border: light
+//	borderTitle: Dashboard
+//	split:
+//	  direction: vertical
+//	  percent: 30
+//	  first: {id: menu, widget: menu}
+//	  second: {widget: chart}
+//
+// FromContainer describes an existing container tree as a Spec, which can be
+// serialized to provide a starting point for end users.
+package layoutspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/widgetapi"
+	"gopkg.in/yaml.v2"
+)
+
+// Spec describes a container and its content.
+type Spec struct {
+	// ID is the identifier of the container, see container.ID.
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+
+	// Border is the style of the border around the container, one of "none",
+	// "light", "double" or "round". Defaults to no border.
+	Border string `json:"border,omitempty" yaml:"border,omitempty"`
+	// BorderTitle is the text title within the border.
+	BorderTitle string `json:"borderTitle,omitempty" yaml:"borderTitle,omitempty"`
+	// BorderTitleAlign aligns the border title, one of "left", "center" or
+	// "right". Defaults to "left".
+	BorderTitleAlign string `json:"borderTitleAlign,omitempty" yaml:"borderTitleAlign,omitempty"`
+	// BorderColor is the number of the color of the border, see
+	// cell.ColorNumber. Defaults to the terminal default color.
+	BorderColor *int `json:"borderColor,omitempty" yaml:"borderColor,omitempty"`
+
+	// Widget is the name of the widget placed in the container.
+	// Cannot be combined with Kind or Split.
+	Widget string `json:"widget,omitempty" yaml:"widget,omitempty"`
+
+	// Kind is a kind of widgets registered via widgetapi.Register, a new
+	// widget of the kind is placed in the container.
+	// Cannot be combined with Widget or Split.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+
+	// Split splits the container into two child containers.
+	// Cannot be combined with Widget or Kind.
+	Split *Split `json:"split,omitempty" yaml:"split,omitempty"`
+}
+
+// Split describes how a container is split.
+// At most one of Percent, Fixed and Weights can be set. If none is set, the
+// container is split in half.
+type Split struct {
+	// Direction is either "vertical" (left and right) or "horizontal" (top and
+	// bottom).
+	Direction string `json:"direction" yaml:"direction"`
+
+	// Percent is the size of the first container as a percentage of the
+	// available space, see container.SplitPercent.
+	Percent int `json:"percent,omitempty" yaml:"percent,omitempty"`
+	// Fixed is the size of the first container in cells, see
+	// container.SplitFixed.
+	Fixed *int `json:"fixed,omitempty" yaml:"fixed,omitempty"`
+	// Weights are the relative sizes of the first and the second container,
+	// see container.SplitWeights.
+	Weights []int `json:"weights,omitempty" yaml:"weights,omitempty,flow"`
+
+	// First is the left or the top container.
+	First *Spec `json:"first" yaml:"first"`
+	// Second is the right or the bottom container.
+	Second *Spec `json:"second" yaml:"second"`
+}
+
+// Parse parses a JSON document into a Spec.
+// Fields that aren't part of the Spec are reported as errors to make typos in
+// hand written documents visible.
+func Parse(data []byte) (*Spec, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var s Spec
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("unable to parse the layout: %v", err)
+	}
+	if dec.More() {
+		return nil, errors.New("unable to parse the layout: unexpected data after the top level object")
+	}
+	return &s, nil
+}
+
+// ParseYAML parses a YAML document into a Spec.
+// Like Parse, fields that aren't part of the Spec are reported as errors.
+func ParseYAML(data []byte) (*Spec, error) {
+	var s Spec
+	if err := yaml.UnmarshalStrict(data, &s); err != nil {
+		return nil, fmt.Errorf("unable to parse the layout: %v", err)
+	}
+	return &s, nil
+}
+
+// Marshal serializes the Spec into an indented JSON document that can be
+// parsed by Parse.
+func Marshal(s *Spec) ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// MarshalYAML serializes the Spec into a YAML document that can be parsed by
+// ParseYAML.
+func MarshalYAML(s *Spec) ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// Build parses the JSON document and returns the container options that
+// create the described layout. The widgets are looked up by their names in
+// the provided map.
+func Build(data []byte, widgets map[string]widgetapi.Widget) ([]container.Option, error) {
+	s, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.Options(widgets)
+}
+
+// BuildYAML is like Build, but parses a YAML document.
+func BuildYAML(data []byte, widgets map[string]widgetapi.Widget) ([]container.Option, error) {
+	s, err := ParseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.Options(widgets)
+}
+
+// Options returns the container options that create the layout described by
+// the Spec. The widgets are looked up by their names in the provided map.
+// The returned options are meant for the root container, see container.New.
+func (s *Spec) Options(widgets map[string]widgetapi.Widget) ([]container.Option, error) {
+	return s.options("root", widgets)
+}
+
+// lineStyles maps the names of line styles to their values.
+var lineStyles = map[string]linestyle.LineStyle{
+	"none":   linestyle.None,
+	"light":  linestyle.Light,
+	"double": linestyle.Double,
+	"round":  linestyle.Round,
+}
+
+// titleAligns maps the names of the alignments of the border title to the
+// corresponding container options.
+var titleAligns = map[string]func() container.Option{
+	"left":   container.BorderTitleAlignLeft,
+	"center": container.BorderTitleAlignCenter,
+	"right":  container.BorderTitleAlignRight,
+}
+
+// options recursively converts the Spec into container options.
+// The path identifies the Spec in error messages.
+func (s *Spec) options(path string, widgets map[string]widgetapi.Widget) ([]container.Option, error) {
+	var opts []container.Option
+	if s.ID != "" {
+		opts = append(opts, container.ID(s.ID))
+	}
+
+	if s.Border != "" {
+		ls, ok := lineStyles[s.Border]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown border %q", path, s.Border)
+		}
+		opts = append(opts, container.Border(ls))
+	}
+	if s.BorderTitle != "" {
+		opts = append(opts, container.BorderTitle(s.BorderTitle))
+	}
+	if s.BorderTitleAlign != "" {
+		ta, ok := titleAligns[s.BorderTitleAlign]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown borderTitleAlign %q", path, s.BorderTitleAlign)
+		}
+		opts = append(opts, ta())
+	}
+	if s.BorderColor != nil {
+		opts = append(opts, container.BorderColor(cell.ColorNumber(*s.BorderColor)))
+	}
+
+	set := 0
+	for _, ok := range []bool{s.Widget != "", s.Kind != "", s.Split != nil} {
+		if ok {
+			set++
+		}
+	}
+	switch {
+	case set > 1:
+		return nil, fmt.Errorf("%s: a container can either hold a widget, a widget kind or be split, only one of these can be set", path)
+
+	case s.Widget != "":
+		w, ok := widgets[s.Widget]
+		if !ok {
+			return nil, fmt.Errorf("%s: no widget named %q was provided", path, s.Widget)
+		}
+		opts = append(opts, container.PlaceWidget(w))
+
+	case s.Kind != "":
+		w, err := widgetapi.NewOf(s.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		opts = append(opts, container.PlaceWidget(w), container.WidgetKind(s.Kind))
+
+	case s.Split != nil:
+		o, err := s.Split.option(path, widgets)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, o)
+	}
+	return opts, nil
+}
+
+// option converts the Split into a container option.
+func (sp *Split) option(path string, widgets map[string]widgetapi.Widget) (container.Option, error) {
+	if sp.First == nil || sp.Second == nil {
+		return nil, fmt.Errorf("%s: a split must have both the first and the second container", path)
+	}
+
+	var splitOpts []container.SplitOption
+	set := 0
+	if sp.Percent != 0 {
+		splitOpts = append(splitOpts, container.SplitPercent(sp.Percent))
+		set++
+	}
+	if sp.Fixed != nil {
+		splitOpts = append(splitOpts, container.SplitFixed(*sp.Fixed))
+		set++
+	}
+	if sp.Weights != nil {
+		if len(sp.Weights) != 2 {
+			return nil, fmt.Errorf("%s: split weights must have exactly two values, got %v", path, sp.Weights)
+		}
+		splitOpts = append(splitOpts, container.SplitWeights(sp.Weights[0], sp.Weights[1]))
+		set++
+	}
+	if set > 1 {
+		return nil, fmt.Errorf("%s: only one of percent, fixed and weights can be set on a split", path)
+	}
+
+	first, err := sp.First.options(path+".first", widgets)
+	if err != nil {
+		return nil, err
+	}
+	second, err := sp.Second.options(path+".second", widgets)
+	if err != nil {
+		return nil, err
+	}
+
+	switch sp.Direction {
+	case "vertical":
+		return container.SplitVertical(
+			container.Left(first...),
+			container.Right(second...),
+			splitOpts...,
+		), nil
+	case "horizontal":
+		return container.SplitHorizontal(
+			container.Top(first...),
+			container.Bottom(second...),
+			splitOpts...,
+		), nil
+	default:
+		return nil, fmt.Errorf("%s: unknown split direction %q, must be vertical or horizontal", path, sp.Direction)
+	}
+}
+
+// FromContainer describes the layout of the container tree as a Spec, i.e.
+// the reverse of Options. The widgets placed in the containers are looked up
+// in the provided map and described by their names, every widget in the tree
+// must be in the map unless it was created from a kind, e.g. by Options. Container options the Spec cannot describe, e.g.
+// margins or key bindings, are omitted.
+func FromContainer(c *container.Container, widgets map[string]widgetapi.Widget) (*Spec, error) {
+	var names []string
+	for name := range widgets {
+		names = append(names, name)
+	}
+	// Widgets provided under multiple names get the first one.
+	sort.Strings(names)
+	widgetNames := map[widgetapi.Widget]string{}
+	for _, name := range names {
+		if _, ok := widgetNames[widgets[name]]; !ok {
+			widgetNames[widgets[name]] = name
+		}
+	}
+	return fromLayout("root", c.Layout(), cell.ColorDefault, widgetNames)
+}
+
+// fromLayout recursively converts the container layout into a Spec.
+// The parentColor is the border color of the parent container, the color is
+// only set on the Spec when it differs, since child containers inherit it.
+// The path identifies the container in error messages.
+func fromLayout(path string, l *container.Layout, parentColor cell.Color, widgetNames map[widgetapi.Widget]string) (*Spec, error) {
+	s := &Spec{
+		ID:          l.ID,
+		BorderTitle: l.BorderTitle,
+	}
+
+	if l.Border != linestyle.None {
+		name, ok := lineStyleName(l.Border)
+		if !ok {
+			return nil, fmt.Errorf("%s: border %v cannot be described", path, l.Border)
+		}
+		s.Border = name
+	}
+	switch l.BorderTitleHAlign {
+	case align.HorizontalLeft:
+	case align.HorizontalCenter:
+		s.BorderTitleAlign = "center"
+	case align.HorizontalRight:
+		s.BorderTitleAlign = "right"
+	default:
+		return nil, fmt.Errorf("%s: border title alignment %v cannot be described", path, l.BorderTitleHAlign)
+	}
+	if l.BorderColor != parentColor {
+		// Colors are off-by-one due to cell.ColorDefault being zero, see
+		// cell.ColorNumber.
+		n := int(l.BorderColor) - 1
+		s.BorderColor = &n
+	}
+
+	switch {
+	case l.WidgetKind != "":
+		s.Kind = l.WidgetKind
+
+	case l.Widget != nil:
+		name, ok := widgetNames[l.Widget]
+		if !ok {
+			return nil, fmt.Errorf("%s: the widget %T placed in the container wasn't provided", path, l.Widget)
+		}
+		s.Widget = name
+	}
+
+	if sp := l.Split; sp != nil {
+		split := &Split{
+			Direction: "horizontal",
+		}
+		if sp.Vertical {
+			split.Direction = "vertical"
+		}
+		switch {
+		case sp.Weights != nil:
+			split.Weights = []int{sp.Weights[0], sp.Weights[1]}
+		case sp.Fixed != container.DefaultSplitFixed:
+			fixed := sp.Fixed
+			split.Fixed = &fixed
+		case sp.Percent != container.DefaultSplitPercent:
+			split.Percent = sp.Percent
+		}
+
+		first, err := fromLayout(path+".first", sp.First, l.BorderColor, widgetNames)
+		if err != nil {
+			return nil, err
+		}
+		second, err := fromLayout(path+".second", sp.Second, l.BorderColor, widgetNames)
+		if err != nil {
+			return nil, err
+		}
+		split.First = first
+		split.Second = second
+		s.Split = split
+	}
+	return s, nil
+}
+
+// lineStyleName returns the name of the line style in the lineStyles map.
+func lineStyleName(ls linestyle.LineStyle) (string, bool) {
+	for name, v := range lineStyles {
+		if v == ls {
+			return name, true
+		}
+	}
+	return "", false
+}
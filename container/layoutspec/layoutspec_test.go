@@ -0,0 +1,663 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layoutspec
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/private/fakewidget"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// mirror returns a new fake widget.
+func mirror() *fakewidget.Mirror {
+	return fakewidget.New(widgetapi.Options{})
+}
+
+func init() {
+	widgetapi.Register("layoutspec.mirror", func() (widgetapi.Widget, error) {
+		return mirror(), nil
+	})
+}
+
+// drawDiff draws containers created with the wanted and the got options and
+// returns the difference between the terminals.
+func drawDiff(t *testing.T, wantOpts, gotOpts []container.Option) string {
+	t.Helper()
+
+	size := image.Point{50, 20}
+	got := faketerm.MustNew(size)
+	gotCont, err := container.New(got, gotOpts...)
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+	if err := gotCont.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	want := faketerm.MustNew(size)
+	wantCont, err := container.New(want, wantOpts...)
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+	if err := wantCont.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	return faketerm.Diff(want, got)
+}
+
+func TestBuild(t *testing.T) {
+	w1 := mirror()
+	w2 := mirror()
+	widgets := map[string]widgetapi.Widget{
+		"w1": w1,
+		"w2": w2,
+	}
+
+	tests := []struct {
+		desc    string
+		doc     string
+		widgets map[string]widgetapi.Widget
+		// want are the options that create the expected layout.
+		want    []container.Option
+		wantErr bool
+	}{
+		{
+			desc:    "fails on invalid JSON",
+			doc:     `{"widget": `,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unknown fields",
+			doc:     `{"widgte": "w1"}`,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on data after the object",
+			doc:     `{"widget": "w1"} {}`,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unknown border",
+			doc:     `{"border": "dotted"}`,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unknown title alignment",
+			doc:     `{"borderTitleAlign": "top"}`,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unknown widget",
+			doc:     `{"widget": "w3"}`,
+			widgets: widgets,
+			wantErr: true,
+		},
+		{
+			desc: "fails on widget combined with a split",
+			doc: `{
+				"widget": "w1",
+				"split": {"direction": "vertical", "first": {}, "second": {}}
+			}`,
+			widgets: widgets,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on widget combined with a kind",
+			doc:     `{"widget": "w1", "kind": "layoutspec.mirror"}`,
+			widgets: widgets,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unregistered kind",
+			doc:     `{"kind": "layoutspec.unknown"}`,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on split without the second container",
+			doc:     `{"split": {"direction": "vertical", "first": {}}}`,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unknown split direction",
+			doc:     `{"split": {"direction": "diagonal", "first": {}, "second": {}}}`,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on multiple split sizes",
+			doc:     `{"split": {"direction": "vertical", "percent": 20, "fixed": 3, "first": {}, "second": {}}}`,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on wrong number of weights",
+			doc:     `{"split": {"direction": "vertical", "weights": [1, 2, 3], "first": {}, "second": {}}}`,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on invalid option in a nested container",
+			doc:     `{"split": {"direction": "vertical", "first": {}, "second": {"border": "dotted"}}}`,
+			wantErr: true,
+		},
+		{
+			desc: "empty layout",
+			doc:  `{}`,
+		},
+		{
+			desc: "widget with a border and a title",
+			doc: `{
+				"id": "main",
+				"border": "light",
+				"borderTitle": "hello",
+				"borderTitleAlign": "right",
+				"borderColor": 1,
+				"widget": "w1"
+			}`,
+			widgets: widgets,
+			want: []container.Option{
+				container.ID("main"),
+				container.Border(linestyle.Light),
+				container.BorderTitle("hello"),
+				container.BorderTitleAlignRight(),
+				container.BorderColor(cell.ColorNumber(1)),
+				container.PlaceWidget(w1),
+			},
+		},
+		{
+			desc: "widget of a registered kind",
+			doc:  `{"border": "light", "kind": "layoutspec.mirror"}`,
+			want: []container.Option{
+				container.Border(linestyle.Light),
+				container.PlaceWidget(mirror()),
+			},
+		},
+		{
+			desc: "nested splits",
+			doc: `{
+				"split": {
+					"direction": "vertical",
+					"percent": 30,
+					"first": {"border": "round", "widget": "w1"},
+					"second": {
+						"split": {
+							"direction": "horizontal",
+							"weights": [1, 3],
+							"first": {"border": "double"},
+							"second": {"widget": "w2"}
+						}
+					}
+				}
+			}`,
+			widgets: widgets,
+			want: []container.Option{
+				container.SplitVertical(
+					container.Left(
+						container.Border(linestyle.Round),
+						container.PlaceWidget(w1),
+					),
+					container.Right(
+						container.SplitHorizontal(
+							container.Top(container.Border(linestyle.Double)),
+							container.Bottom(container.PlaceWidget(w2)),
+							container.SplitWeights(1, 3),
+						),
+					),
+					container.SplitPercent(30),
+				),
+			},
+		},
+		{
+			desc: "fixed split",
+			doc: `{
+				"split": {
+					"direction": "horizontal",
+					"fixed": 0,
+					"first": {"border": "light"},
+					"second": {"border": "light"}
+				}
+			}`,
+			want: []container.Option{
+				container.SplitHorizontal(
+					container.Top(container.Border(linestyle.Light)),
+					container.Bottom(container.Border(linestyle.Light)),
+					container.SplitFixed(0),
+				),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			opts, err := Build([]byte(tc.doc), tc.widgets)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Build => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := drawDiff(t, tc.want, opts); diff != "" {
+				t.Errorf("Build => %v", diff)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	fixed := 3
+	color := 2
+	want := &Spec{
+		ID:          "root",
+		Border:      "light",
+		BorderColor: &color,
+		Split: &Split{
+			Direction: "vertical",
+			Fixed:     &fixed,
+			First:     &Spec{Widget: "w1"},
+			Second: &Spec{
+				Split: &Split{
+					Direction: "horizontal",
+					Weights:   []int{2, 1},
+					First:     &Spec{BorderTitle: "top"},
+					Second:    &Spec{Widget: "w2"},
+				},
+			},
+		},
+	}
+
+	data, err := Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal => unexpected error: %v", err)
+	}
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("Parse(Marshal) => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBuildYAML(t *testing.T) {
+	w1 := mirror()
+	w2 := mirror()
+	widgets := map[string]widgetapi.Widget{
+		"w1": w1,
+		"w2": w2,
+	}
+
+	tests := []struct {
+		desc    string
+		doc     string
+		want    []container.Option
+		wantErr bool
+	}{
+		{
+			desc:    "fails on invalid YAML",
+			doc:     "split: [",
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unknown fields",
+			doc:     "widgte: w1",
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unknown widget",
+			doc:     "widget: w3",
+			wantErr: true,
+		},
+		{
+			desc: "empty layout",
+			doc:  "",
+		},
+		{
+			desc: "nested splits",
+			doc: `
+id: main
+border: light
+borderTitle: hello
+borderTitleAlign: center
+borderColor: 1
+split:
+  direction: vertical
+  fixed: 10
+  first: {border: round, widget: w1}
+  second:
+    split:
+      direction: horizontal
+      weights: [1, 3]
+      first: {border: double}
+      second: {widget: w2}
+`,
+			want: []container.Option{
+				container.ID("main"),
+				container.Border(linestyle.Light),
+				container.BorderTitle("hello"),
+				container.BorderTitleAlignCenter(),
+				container.BorderColor(cell.ColorNumber(1)),
+				container.SplitVertical(
+					container.Left(
+						container.Border(linestyle.Round),
+						container.PlaceWidget(w1),
+					),
+					container.Right(
+						container.SplitHorizontal(
+							container.Top(container.Border(linestyle.Double)),
+							container.Bottom(container.PlaceWidget(w2)),
+							container.SplitWeights(1, 3),
+						),
+					),
+					container.SplitFixed(10),
+				),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			opts, err := BuildYAML([]byte(tc.doc), widgets)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("BuildYAML => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := drawDiff(t, tc.want, opts); diff != "" {
+				t.Errorf("BuildYAML => %v", diff)
+			}
+		})
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	fixed := 0
+	color := 2
+	want := &Spec{
+		ID:               "root",
+		Border:           "light",
+		BorderTitleAlign: "right",
+		BorderColor:      &color,
+		Split: &Split{
+			Direction: "vertical",
+			Fixed:     &fixed,
+			First:     &Spec{Widget: "w1"},
+			Second: &Spec{
+				Split: &Split{
+					Direction: "horizontal",
+					Weights:   []int{2, 1},
+					First:     &Spec{BorderTitle: "top"},
+					Second:    &Spec{Kind: "layoutspec.mirror"},
+				},
+			},
+		},
+	}
+
+	data, err := MarshalYAML(want)
+	if err != nil {
+		t.Fatalf("MarshalYAML => unexpected error: %v", err)
+	}
+	got, err := ParseYAML(data)
+	if err != nil {
+		t.Fatalf("ParseYAML => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("ParseYAML(MarshalYAML) => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestFromContainer(t *testing.T) {
+	w1 := mirror()
+	w2 := mirror()
+	widgets := map[string]widgetapi.Widget{
+		"w1": w1,
+		"w2": w2,
+	}
+	intPtr := func(i int) *int {
+		return &i
+	}
+
+	tests := []struct {
+		desc    string
+		opts    []container.Option
+		widgets map[string]widgetapi.Widget
+		want    *Spec
+		wantErr bool
+	}{
+		{
+			desc: "fails on a widget that wasn't provided",
+			opts: []container.Option{
+				container.PlaceWidget(w1),
+			},
+			widgets: map[string]widgetapi.Widget{
+				"w2": w2,
+			},
+			wantErr: true,
+		},
+		{
+			desc: "empty container",
+			want: &Spec{},
+		},
+		{
+			desc: "widget with a border and a title",
+			opts: []container.Option{
+				container.ID("main"),
+				container.Border(linestyle.Double),
+				container.BorderTitle("hello"),
+				container.BorderTitleAlignCenter(),
+				container.BorderColor(cell.ColorNumber(3)),
+				container.PlaceWidget(w2),
+			},
+			widgets: widgets,
+			want: &Spec{
+				ID:               "main",
+				Border:           "double",
+				BorderTitle:      "hello",
+				BorderTitleAlign: "center",
+				BorderColor:      intPtr(3),
+				Widget:           "w2",
+			},
+		},
+		{
+			desc: "nested splits with inherited border color",
+			opts: []container.Option{
+				container.BorderColor(cell.ColorNumber(1)),
+				container.SplitVertical(
+					container.Left(
+						container.Border(linestyle.Round),
+						container.PlaceWidget(w1),
+					),
+					container.Right(
+						container.SplitHorizontal(
+							container.Top(
+								container.Border(linestyle.Light),
+								container.BorderColor(cell.ColorNumber(4)),
+							),
+							container.Bottom(container.PlaceWidget(w2)),
+							container.SplitWeights(1, 3),
+						),
+					),
+					container.SplitPercent(30),
+				),
+			},
+			widgets: widgets,
+			want: &Spec{
+				BorderColor: intPtr(1),
+				Split: &Split{
+					Direction: "vertical",
+					Percent:   30,
+					First: &Spec{
+						Border: "round",
+						Widget: "w1",
+					},
+					Second: &Spec{
+						Split: &Split{
+							Direction: "horizontal",
+							Weights:   []int{1, 3},
+							First: &Spec{
+								Border:      "light",
+								BorderColor: intPtr(4),
+							},
+							Second: &Spec{Widget: "w2"},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "split in half and fixed split",
+			opts: []container.Option{
+				container.SplitHorizontal(
+					container.Top(
+						container.SplitVertical(
+							container.Left(),
+							container.Right(),
+							container.SplitFixed(0),
+						),
+					),
+					container.Bottom(),
+				),
+			},
+			want: &Spec{
+				Split: &Split{
+					Direction: "horizontal",
+					First: &Spec{
+						Split: &Split{
+							Direction: "vertical",
+							Fixed:     intPtr(0),
+							First:     &Spec{},
+							Second:    &Spec{},
+						},
+					},
+					Second: &Spec{},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			c, err := container.New(faketerm.MustNew(image.Point{50, 20}), tc.opts...)
+			if err != nil {
+				t.Fatalf("container.New => unexpected error: %v", err)
+			}
+
+			got, err := FromContainer(c, tc.widgets)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("FromContainer => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("FromContainer => unexpected diff (-want, +got):\n%s", diff)
+			}
+
+			// The Spec recreates the same layout.
+			opts, err := got.Options(tc.widgets)
+			if err != nil {
+				t.Fatalf("Options => unexpected error: %v", err)
+			}
+			if diff := drawDiff(t, tc.opts, opts); diff != "" {
+				t.Errorf("Options(FromContainer) => %v", diff)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	widgets := map[string]widgetapi.Widget{
+		"w1": mirror(),
+	}
+
+	tests := []struct {
+		desc    string
+		doc     string
+		parse   func([]byte) (*Spec, error)
+		build   func([]byte, map[string]widgetapi.Widget) ([]container.Option, error)
+		marshal func(*Spec) ([]byte, error)
+	}{
+		{
+			desc: "JSON",
+			doc: `{
+				"id": "root",
+				"border": "light",
+				"split": {
+					"direction": "vertical",
+					"percent": 30,
+					"first": {"kind": "layoutspec.mirror"},
+					"second": {"borderTitle": "w1", "widget": "w1"}
+				}
+			}`,
+			parse:   Parse,
+			build:   Build,
+			marshal: Marshal,
+		},
+		{
+			desc: "YAML",
+			doc: `
+id: root
+border: light
+split:
+  direction: horizontal
+  weights: [1, 2]
+  first: {kind: layoutspec.mirror}
+  second: {borderTitle: w1, widget: w1}
+`,
+			parse:   ParseYAML,
+			build:   BuildYAML,
+			marshal: MarshalYAML,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			want, err := tc.parse([]byte(tc.doc))
+			if err != nil {
+				t.Fatalf("parse => unexpected error: %v", err)
+			}
+			opts, err := tc.build([]byte(tc.doc), widgets)
+			if err != nil {
+				t.Fatalf("build => unexpected error: %v", err)
+			}
+			c, err := container.New(faketerm.MustNew(image.Point{50, 20}), opts...)
+			if err != nil {
+				t.Fatalf("container.New => unexpected error: %v", err)
+			}
+
+			s, err := FromContainer(c, widgets)
+			if err != nil {
+				t.Fatalf("FromContainer => unexpected error: %v", err)
+			}
+			data, err := tc.marshal(s)
+			if err != nil {
+				t.Fatalf("marshal => unexpected error: %v", err)
+			}
+			got, err := tc.parse(data)
+			if err != nil {
+				t.Fatalf("parse => unexpected error: %v", err)
+			}
+			if diff := pretty.Compare(want, got); diff != "" {
+				t.Errorf("parse(marshal(FromContainer)) => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
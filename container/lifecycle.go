@@ -0,0 +1,183 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// lifecycle.go delivers the lifecycle hooks to widgets.
+
+import (
+	"time"
+
+	"github.com/mum4k/termdash/bus"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// lifecycleState tracks the widgets in the container tree in order to call
+// their lifecycle hooks, see widgetapi.Mounter. Only set on the root
+// container.
+type lifecycleState struct {
+	// mounted are the widgets in the container tree in the order they were
+	// mounted.
+	mounted []widgetapi.Widget
+
+	// focused is the widget in the focused container or nil.
+	focused widgetapi.Widget
+
+	// theme is the theme provided via SetTheme or nil.
+	theme *widgetapi.Theme
+
+	// bus is the message bus provided via SetBus or nil.
+	bus *bus.Bus
+}
+
+// treeWidgets returns the widgets in the container and all of its sub
+// containers, each widget only once.
+// Caller must hold c.mu.
+func treeWidgets(c *Container) []widgetapi.Widget {
+	var widgets []widgetapi.Widget
+	seen := map[widgetapi.Widget]bool{}
+	var errStr string
+	preOrder(c, &errStr, visitFunc(func(cur *Container) error {
+		for _, w := range []widgetapi.Widget{cur.opts.widget, cur.opts.statusBar} {
+			if w != nil && !seen[w] {
+				seen[w] = true
+				widgets = append(widgets, w)
+			}
+		}
+		return nil
+	}))
+	return widgets
+}
+
+// lifecycleHooks records the changes of the widgets in the tree and of the
+// focused widget since the last call and returns a function that calls the
+// hooks of the affected widgets. The caller must call the returned function
+// after releasing c.mu.
+// Caller must hold c.mu.
+func (c *Container) lifecycleHooks() func() {
+	root := rootCont(c)
+	ls := &root.lifecycle
+	widgets := treeWidgets(root)
+
+	now := map[widgetapi.Widget]bool{}
+	for _, w := range widgets {
+		now[w] = true
+	}
+	before := map[widgetapi.Widget]bool{}
+	var unmounted []widgetapi.Widget
+	for _, w := range ls.mounted {
+		before[w] = true
+		if !now[w] {
+			unmounted = append(unmounted, w)
+		}
+	}
+	var mounted []widgetapi.Widget
+	for _, w := range widgets {
+		if !before[w] {
+			mounted = append(mounted, w)
+		}
+	}
+	ls.mounted = widgets
+
+	lost := ls.focused
+	gained := root.focusTracker.container.opts.widget
+	if lost == gained {
+		lost, gained = nil, nil
+	} else {
+		ls.focused = gained
+	}
+	var theme *widgetapi.Theme
+	if ls.theme != nil {
+		t := *ls.theme
+		theme = &t
+	}
+	b := ls.bus
+
+	return func() {
+		for _, w := range unmounted {
+			if m, ok := w.(widgetapi.Mounter); ok {
+				m.Unmount()
+			}
+		}
+		for _, w := range mounted {
+			if m, ok := w.(widgetapi.Mounter); ok {
+				m.Mount()
+			}
+			if t, ok := w.(widgetapi.Themer); ok && theme != nil {
+				t.SetTheme(*theme)
+			}
+			if u, ok := w.(widgetapi.BusUser); ok && b != nil {
+				u.SetBus(b)
+			}
+		}
+		if f, ok := lost.(widgetapi.FocusObserver); ok && now[lost] {
+			f.FocusChanged(false)
+		}
+		if f, ok := gained.(widgetapi.FocusObserver); ok {
+			f.FocusChanged(true)
+		}
+	}
+}
+
+// SetTheme provides the theme to all the widgets in the container tree that
+// implement widgetapi.Themer, including widgets placed by future calls to
+// Update.
+func (c *Container) SetTheme(t widgetapi.Theme) {
+	c.mu.Lock()
+	root := rootCont(c)
+	root.lifecycle.theme = &t
+	widgets := root.lifecycle.mounted
+	c.mu.Unlock()
+
+	for _, w := range widgets {
+		if th, ok := w.(widgetapi.Themer); ok {
+			th.SetTheme(t)
+		}
+	}
+}
+
+// SetBus provides the message bus to all the widgets in the container tree
+// that implement widgetapi.BusUser, including widgets placed by future calls
+// to Update.
+// This method is private to termdash, stability isn't guaranteed and changes
+// won't be backward compatible.
+func (c *Container) SetBus(b *bus.Bus) {
+	c.mu.Lock()
+	root := rootCont(c)
+	root.lifecycle.bus = b
+	widgets := root.lifecycle.mounted
+	c.mu.Unlock()
+
+	for _, w := range widgets {
+		if u, ok := w.(widgetapi.BusUser); ok {
+			u.SetBus(b)
+		}
+	}
+}
+
+// Tick calls the Tick method of all the widgets in the container tree that
+// implement widgetapi.Ticker.
+// This method is private to termdash, stability isn't guaranteed and changes
+// won't be backward compatible.
+func (c *Container) Tick(now time.Time) {
+	c.mu.Lock()
+	widgets := rootCont(c).lifecycle.mounted
+	c.mu.Unlock()
+
+	for _, w := range widgets {
+		if t, ok := w.(widgetapi.Ticker); ok {
+			t.Tick(now)
+		}
+	}
+}
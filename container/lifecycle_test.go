@@ -0,0 +1,203 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/bus"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/private/fakewidget"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// hookLog records the calls of lifecycle hooks.
+type hookLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+// add records a call.
+func (hl *hookLog) add(format string, args ...interface{}) {
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+	hl.calls = append(hl.calls, fmt.Sprintf(format, args...))
+}
+
+// take returns the recorded calls and forgets them.
+func (hl *hookLog) take() []string {
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+	calls := hl.calls
+	hl.calls = nil
+	return calls
+}
+
+// hookWidget is a widget that records the calls of its lifecycle hooks.
+type hookWidget struct {
+	*fakewidget.Mirror
+	name string
+	log  *hookLog
+}
+
+// newHookWidget returns a new hookWidget.
+func newHookWidget(name string, log *hookLog) *hookWidget {
+	return &hookWidget{
+		Mirror: fakewidget.New(widgetapi.Options{}),
+		name:   name,
+		log:    log,
+	}
+}
+
+// Mount implements widgetapi.Mounter.Mount.
+func (hw *hookWidget) Mount() {
+	hw.log.add("%s mount", hw.name)
+}
+
+// Unmount implements widgetapi.Mounter.Unmount.
+func (hw *hookWidget) Unmount() {
+	hw.log.add("%s unmount", hw.name)
+}
+
+// FocusChanged implements widgetapi.FocusObserver.FocusChanged.
+func (hw *hookWidget) FocusChanged(focused bool) {
+	hw.log.add("%s focused %v", hw.name, focused)
+}
+
+// SetTheme implements widgetapi.Themer.SetTheme.
+func (hw *hookWidget) SetTheme(t widgetapi.Theme) {
+	hw.log.add("%s theme %s", hw.name, t.Name)
+}
+
+// SetBus implements widgetapi.BusUser.SetBus.
+func (hw *hookWidget) SetBus(b *bus.Bus) {
+	hw.log.add("%s bus", hw.name)
+}
+
+// Tick implements widgetapi.Ticker.Tick.
+func (hw *hookWidget) Tick(now time.Time) {
+	hw.log.add("%s tick %d", hw.name, now.Unix())
+}
+
+func TestLifecycle(t *testing.T) {
+	log := &hookLog{}
+	a := newHookWidget("a", log)
+	b := newHookWidget("b", log)
+	c := newHookWidget("c", log)
+
+	ft := faketerm.MustNew(image.Point{30, 10})
+	cont, err := New(
+		ft,
+		SplitVertical(
+			Left(ID("left"), PlaceWidget(a)),
+			Right(ID("right"), PlaceWidget(b)),
+		),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	steps := []struct {
+		desc   string
+		action func() error
+		want   []string
+	}{
+		{
+			desc:   "New mounts the widgets",
+			action: func() error { return nil },
+			want:   []string{"a mount", "b mount"},
+		},
+		{
+			desc:   "Draw doesn't call any hooks",
+			action: cont.Draw,
+		},
+		{
+			desc: "focusing a container focuses its widget",
+			action: func() error {
+				for _, ev := range click(image.Point{1, 1}) {
+					if err := cont.Inject(ev); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			want: []string{"a focused true"},
+		},
+		{
+			desc: "moving the focus",
+			action: func() error {
+				for _, ev := range click(image.Point{20, 1}) {
+					if err := cont.Inject(ev); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			want: []string{"a focused false", "b focused true"},
+		},
+		{
+			desc: "setting the theme",
+			action: func() error {
+				cont.SetTheme(widgetapi.Theme{Name: "dark", Accent: cell.ColorRed})
+				return nil
+			},
+			want: []string{"a theme dark", "b theme dark"},
+		},
+		{
+			desc: "setting the bus",
+			action: func() error {
+				cont.SetBus(bus.New())
+				return nil
+			},
+			want: []string{"a bus", "b bus"},
+		},
+		{
+			desc: "replacing a focused widget",
+			action: func() error {
+				return cont.Update("right", PlaceWidget(c))
+			},
+			want: []string{"b unmount", "c mount", "c theme dark", "c bus", "c focused true"},
+		},
+		{
+			desc: "ticking",
+			action: func() error {
+				cont.Tick(time.Unix(42, 0))
+				return nil
+			},
+			want: []string{"a tick 42", "c tick 42"},
+		},
+		{
+			desc: "removing all widgets",
+			action: func() error {
+				return cont.Update("left", SplitHorizontal(Top(), Bottom()))
+			},
+			want: []string{"a unmount"},
+		},
+	}
+
+	for _, s := range steps {
+		if err := s.action(); err != nil {
+			t.Fatalf("%s => unexpected error: %v", s.desc, err)
+		}
+		if diff := pretty.Compare(s.want, log.take()); diff != "" {
+			t.Errorf("%s => unexpected diff (-want, +got):\n%s", s.desc, diff)
+		}
+	}
+}
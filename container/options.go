@@ -20,11 +20,18 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"strings"
+	"time"
 
 	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/badge"
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/keyboard"
 	"github.com/mum4k/termdash/linestyle"
 	"github.com/mum4k/termdash/private/area"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/private/wrap"
 	"github.com/mum4k/termdash/widgetapi"
 )
 
@@ -59,10 +66,36 @@ func validateSplits(c *Container) error {
 			c.opts.splitPercent,
 		)
 	}
+	if w := c.opts.splitWeights; w != nil && (c.opts.splitFixed > DefaultSplitFixed || c.opts.splitPercent != DefaultSplitPercent) {
+		return fmt.Errorf(
+			"splitWeights `%d:%d` cannot be set together with splitFixed `%v` or splitPercent `%v`",
+			w.first, w.second,
+			c.opts.splitFixed,
+			c.opts.splitPercent,
+		)
+	}
 
 	return nil
 }
 
+// validateScrollKeys validates the keys that scroll the container.
+func validateScrollKeys(c *Container) error {
+	sk := c.opts.scrollKeys
+	if sk == nil {
+		return nil
+	}
+	keys := map[keyboard.Key]bool{
+		sk.up:    true,
+		sk.down:  true,
+		sk.left:  true,
+		sk.right: true,
+	}
+	if len(keys) != 4 {
+		return fmt.Errorf("invalid ScrollKeys(up:%v, down:%v, left:%v, right:%v), the keys must be unique", sk.up, sk.down, sk.left, sk.right)
+	}
+	return nil
+}
+
 // validateOptions validates options set in the container tree.
 func validateOptions(c *Container) error {
 	var errStr string
@@ -74,6 +107,9 @@ func validateOptions(c *Container) error {
 		if err := validateSplits(c); err != nil {
 			return err
 		}
+		if err := validateScrollKeys(c); err != nil {
+			return err
+		}
 
 		return nil
 	})
@@ -102,11 +138,17 @@ type options struct {
 	split        splitType
 	splitPercent int
 	splitFixed   int
+	splitWeights *splitWeights
+	splitLimits  *splitLimits
+	splitResize  *splitResize
 
 	// widget is the widget in the container.
 	// A container can have either two sub containers (left and right) or a
 	// widget. But not both.
 	widget widgetapi.Widget
+	// widgetKind is the registered kind the widget was created from, empty
+	// if not known.
+	widgetKind string
 
 	// Alignment of the widget if present.
 	hAlign align.Horizontal
@@ -123,6 +165,57 @@ type options struct {
 
 	// margin is a space reserved on the outside of the container.
 	margin margin
+
+	// scrollable indicates if the container scrolls the widget when the
+	// widget needs more space than the container provides.
+	scrollable bool
+	// scrollKeys are the keys that scroll the widget, nil if the widget
+	// cannot be scrolled using the keyboard.
+	scrollKeys *scrollKeys
+
+	// tooltip is the text displayed when the container is hovered or
+	// focused.
+	tooltip         string
+	tooltipDelay    time.Duration
+	tooltipCellOpts []cell.Option
+
+	// keyBindings are the key sequences processed while the container or any
+	// of its sub containers is focused, nil if not provided.
+	keyBindings *keybinding.Registry
+
+	// noFocus indicates that mouse clicks don't focus the container.
+	noFocus bool
+
+	// sensitive indicates that the container displays sensitive content.
+	sensitive bool
+
+	// badge is drawn over a corner of the container, nil if not provided.
+	badge *badge.Badge
+
+	// borderButtons are drawn in a corner of the border, nil if not provided.
+	borderButtons *borderButtons
+
+	// readOnlyIndicator is the text displayed while the dashboard is in the
+	// read-only mode. Only used on the root container.
+	readOnlyIndicator         string
+	readOnlyIndicatorCellOpts []cell.Option
+
+	// emojiFallback replaces emoji sequences when drawn on the terminal,
+	// zero if they are drawn as is. Only used on the root container.
+	emojiFallback rune
+
+	// brailleHalfDensity indicates that the braille patterns are drawn with
+	// half of their horizontal resolution. Only used on the root container.
+	brailleHalfDensity bool
+
+	// statusBar is the widget displayed along the top or the bottom edge of
+	// the terminal, nil if not provided. Only used on the root container.
+	statusBar    widgetapi.Widget
+	statusBarPos align.Vertical
+
+	// responsive are the options applied while the size of the container
+	// meets their breakpoints.
+	responsive []*responsiveRule
 }
 
 // margin stores the configured margin for the container.
@@ -173,12 +266,59 @@ func (p *padding) apply(ar image.Rectangle) (image.Rectangle, error) {
 	return ar, nil
 }
 
+// splitWeights stores the relative sizes of the two sides of a split.
+type splitWeights struct {
+	first  int
+	second int
+}
+
+// splitLimits stores the minimum and maximum sizes in cells of the two sides
+// of a split. Zero means no limit.
+type splitLimits struct {
+	firstMin  int
+	firstMax  int
+	secondMin int
+	secondMax int
+}
+
+// apply adjusts the size of the first side of a split of the total size so
+// that both sides respect their limits if possible.
+// When the total size cannot fit the minimum sizes of both sides, one of them
+// collapses to zero size. The second side collapses if the first one fits,
+// otherwise the first one collapses.
+func (sl *splitLimits) apply(first, total int) int {
+	if sl.secondMax > 0 && total-first > sl.secondMax {
+		first = total - sl.secondMax
+	}
+	if sl.firstMax > 0 && first > sl.firstMax {
+		first = sl.firstMax
+	}
+	if first < sl.firstMin {
+		first = sl.firstMin
+	}
+	if total-first < sl.secondMin {
+		first = total - sl.secondMin
+	}
+
+	if first >= sl.firstMin && total-first >= sl.secondMin {
+		return first
+	}
+	if total >= sl.firstMin && total > 0 {
+		// Only the first side fits, collapse the second one.
+		return total
+	}
+	return 0
+}
+
 // inherited contains options that are inherited by child containers.
 type inherited struct {
 	// borderColor is the color used for the border.
 	borderColor cell.Color
 	// focusedColor is the color used for the border when focused.
 	focusedColor cell.Color
+	// refreshInterval is the minimum duration between redraws of the
+	// widget, zero if the widget redraws on every Draw.
+	refreshInterval time.Duration
 }
 
 // newOptions returns a new options instance with the default values.
@@ -193,6 +333,13 @@ func newOptions(parent *options) *options {
 		vAlign:       align.VerticalMiddle,
 		splitPercent: DefaultSplitPercent,
 		splitFixed:   DefaultSplitFixed,
+		tooltipDelay: DefaultTooltipDelay,
+
+		readOnlyIndicator: DefaultReadOnlyIndicator,
+		readOnlyIndicatorCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorBlack),
+			cell.BgColor(cell.ColorYellow),
+		},
 	}
 	if parent != nil {
 		opts.inherited = parent.inherited
@@ -264,6 +411,134 @@ func SplitFixed(cells int) SplitOption {
 	})
 }
 
+// SplitWeights sets the relative sizes of the two containers created by the
+// split. E.g. SplitWeights(2, 1) makes the first container twice as large as
+// the second one.
+// A container split only ever has two sides, so SplitWeights takes exactly two
+// weights. To divide the space among N containers by weight, use the
+// RowWeighted and ColWeighted elements of the grid package, which translate N
+// weights into nested splits.
+// Both weights must be positive integers.
+// Only one of SplitWeights(), SplitFixed() and SplitPercent() can be specified
+// per container.
+func SplitWeights(first, second int) SplitOption {
+	return splitOption(func(opts *options) error {
+		if first <= 0 || second <= 0 {
+			return fmt.Errorf("invalid split weights %d:%d, both must be positive integers", first, second)
+		}
+		opts.splitWeights = &splitWeights{
+			first:  first,
+			second: second,
+		}
+		return nil
+	})
+}
+
+// SplitMinCells sets the minimum size in cells of the two containers created
+// by the split. The sizes determined by SplitPercent, SplitFixed or
+// SplitWeights are adjusted so that both containers get at least the minimum
+// size. If the available space cannot fit both minimums, the second container
+// collapses to zero size and isn't drawn, or the first one if only the second
+// one fits. Zero means no minimum.
+// The values must be zero or positive integers.
+func SplitMinCells(first, second int) SplitOption {
+	return splitOption(func(opts *options) error {
+		if first < 0 || second < 0 {
+			return fmt.Errorf("invalid SplitMinCells(%d, %d), the values must be zero or positive integers", first, second)
+		}
+		if opts.splitLimits == nil {
+			opts.splitLimits = &splitLimits{}
+		}
+		opts.splitLimits.firstMin = first
+		opts.splitLimits.secondMin = second
+		return nil
+	})
+}
+
+// SplitMaxCells sets the maximum size in cells of the two containers created
+// by the split. The sizes determined by SplitPercent, SplitFixed or
+// SplitWeights are adjusted so that neither container exceeds its maximum.
+// If the available space is larger than both maximums, the second container
+// gets the remainder. The minimums set by SplitMinCells take precedence over
+// the maximums. Zero means no maximum.
+// The values must be zero or positive integers.
+func SplitMaxCells(first, second int) SplitOption {
+	return splitOption(func(opts *options) error {
+		if first < 0 || second < 0 {
+			return fmt.Errorf("invalid SplitMaxCells(%d, %d), the values must be zero or positive integers", first, second)
+		}
+		if opts.splitLimits == nil {
+			opts.splitLimits = &splitLimits{}
+		}
+		opts.splitLimits.firstMax = first
+		opts.splitLimits.secondMax = second
+		return nil
+	})
+}
+
+// SplitResizable allows the user to resize the two containers created by the
+// split by dragging the boundary between them with the left mouse button.
+// The boundary consists of the last row or column of cells of the first
+// container and the first row or column of cells of the second container.
+// Mouse events that drag the boundary aren't delivered to any widgets.
+func SplitResizable() SplitOption {
+	return splitOption(func(opts *options) error {
+		if opts.splitResize == nil {
+			opts.splitResize = &splitResize{}
+		}
+		opts.splitResize.mouse = true
+		return nil
+	})
+}
+
+// SplitResizeKeys configures keyboard keys that move the boundary between the
+// two containers created by the split by one cell, decreasing or increasing
+// the size of the first container. The keys apply while the focused
+// container is this container or any of its sub containers. If multiple
+// splits use the same keys, the innermost one is resized.
+// These keys aren't delivered to any widgets. The keys must be unique.
+func SplitResizeKeys(decrease, increase keyboard.Key) SplitOption {
+	return splitOption(func(opts *options) error {
+		if decrease == increase {
+			return fmt.Errorf("invalid SplitResizeKeys(decrease:%v, increase:%v), the keys must be unique", decrease, increase)
+		}
+		if opts.splitResize == nil {
+			opts.splitResize = &splitResize{}
+		}
+		opts.splitResize.keys = true
+		opts.splitResize.decrease = decrease
+		opts.splitResize.increase = increase
+		return nil
+	})
+}
+
+// SplitOnResize sets a function that is called with the sizes in cells of
+// the two containers after the user resized them using the mouse or the
+// keyboard, see SplitResizable and SplitResizeKeys. When resizing with the
+// mouse, the function is called once the mouse button is released.
+// The sizes can be persisted and provided to SplitWeights to restore the
+// layout later.
+// The function is called synchronously from the event processing and should
+// return quickly.
+func SplitOnResize(fn func(first, second int)) SplitOption {
+	return splitOption(func(opts *options) error {
+		if opts.splitResize == nil {
+			opts.splitResize = &splitResize{}
+		}
+		opts.splitResize.onResize = fn
+		return nil
+	})
+}
+
+// resetSplitOptions resets the options of a split to their default values.
+func resetSplitOptions(opts *options) {
+	opts.splitPercent = DefaultSplitPercent
+	opts.splitFixed = DefaultSplitFixed
+	opts.splitWeights = nil
+	opts.splitLimits = nil
+	opts.splitResize = nil
+}
+
 // SplitVertical splits the container along the vertical axis into two sub
 // containers. The use of this option removes any widget placed at this
 // container, containers with sub containers cannot contain widgets.
@@ -271,6 +546,8 @@ func SplitVertical(l LeftOption, r RightOption, opts ...SplitOption) Option {
 	return option(func(c *Container) error {
 		c.opts.split = splitTypeVertical
 		c.opts.widget = nil
+		c.opts.widgetKind = ""
+		resetSplitOptions(c.opts)
 		for _, opt := range opts {
 			if err := opt.setSplit(c.opts); err != nil {
 				return err
@@ -291,6 +568,8 @@ func SplitHorizontal(t TopOption, b BottomOption, opts ...SplitOption) Option {
 	return option(func(c *Container) error {
 		c.opts.split = splitTypeHorizontal
 		c.opts.widget = nil
+		c.opts.widgetKind = ""
+		resetSplitOptions(c.opts)
 		for _, opt := range opts {
 			if err := opt.setSplit(c.opts); err != nil {
 				return err
@@ -325,6 +604,7 @@ func ID(id string) Option {
 func Clear() Option {
 	return option(func(c *Container) error {
 		c.opts.widget = nil
+		c.opts.widgetKind = ""
 		c.first = nil
 		c.second = nil
 		return nil
@@ -337,12 +617,27 @@ func Clear() Option {
 func PlaceWidget(w widgetapi.Widget) Option {
 	return option(func(c *Container) error {
 		c.opts.widget = w
+		c.opts.widgetKind = ""
 		c.first = nil
 		c.second = nil
 		return nil
 	})
 }
 
+// WidgetKind records the kind the widget placed in the container was created
+// from, see widgetapi.Register. The kind is reported by Container.Layout, so
+// that the layoutspec package can describe the widget by its kind. Must
+// follow the PlaceWidget option, which forgets the recorded kind.
+func WidgetKind(kind string) Option {
+	return option(func(c *Container) error {
+		if c.opts.widget == nil {
+			return fmt.Errorf("WidgetKind(%q) requires a widget placed in the container", kind)
+		}
+		c.opts.widgetKind = kind
+		return nil
+	})
+}
+
 // MarginTop sets reserved space outside of the container at its top.
 // The provided number is the absolute margin in cells and must be zero or a
 // positive integer. Only one of MarginTop or MarginTopPercent can be specified.
@@ -694,6 +989,288 @@ func FocusedColor(color cell.Color) Option {
 	})
 }
 
+// RefreshInterval marks the container as a zone that refreshes lazily. Its
+// widget is asked to draw at most once per the provided duration, the last
+// drawn content is displayed again on the redraws in between. The widget is
+// always redrawn when the size of the container or its focus changes.
+//
+// Terminal backends only transmit the cells that changed since the last
+// flush, so throttling decorative or fast changing widgets keeps the
+// dashboard responsive over low bandwidth links like SSH or serial
+// consoles. Containers without this option remain a priority, their
+// widgets update on every redraw.
+//
+// This option is inherited to sub containers created by container splits.
+// Provide a zero duration to a sub container to make it refresh on every
+// redraw again.
+func RefreshInterval(d time.Duration) Option {
+	return option(func(c *Container) error {
+		if d < 0 {
+			return fmt.Errorf("invalid RefreshInterval(%v), must be zero or a positive duration", d)
+		}
+		c.opts.inherited.refreshInterval = d
+		return nil
+	})
+}
+
+// Scrollable configures the container to scroll the widget when the widget
+// needs more space than the container provides, i.e. when the container is
+// smaller than the minimum size the widget specifies in its options.
+// The widget then draws on a canvas of its minimum size and the container
+// displays the visible part along with vertical and horizontal scrollbars as
+// needed. Mouse wheel events above the container scroll its content and
+// aren't delivered to any widgets. Mouse events delivered to the widget are
+// relative to its canvas, not to the visible part.
+//
+// Without this option the container displays a character indicating that a
+// resize is needed instead of the widget.
+func Scrollable() Option {
+	return option(func(c *Container) error {
+		c.opts.scrollable = true
+		return nil
+	})
+}
+
+// NoFocus configures the container so that clicking it with the mouse doesn't
+// move the keyboard focus to it or to any of its sub containers, the focus
+// stays where it was. Mouse events are still delivered to the widgets.
+// Useful for widgets that generate input for other widgets, like an
+// on-screen keyboard, see Container.Inject.
+func NoFocus() Option {
+	return option(func(c *Container) error {
+		c.opts.noFocus = true
+		return nil
+	})
+}
+
+// Sensitive marks the content of the container and its sub containers as
+// sensitive, the whole area of the container including its border is masked
+// while the privacy mode is enabled, see Container.SensitiveAreas. Use
+// widgets that implement widgetapi.Sensitive to mask only parts of their
+// content.
+func Sensitive() Option {
+	return option(func(c *Container) error {
+		c.opts.sensitive = true
+		return nil
+	})
+}
+
+// KeyBindings attaches a registry of key sequences to the container. The
+// registry receives the keyboard events while the container or any of its sub
+// containers is focused. Registries of the inner containers receive the
+// events first.
+//
+// Keys that start, continue or complete a bound sequence aren't delivered to
+// any widgets, so sequences should start with keys the widgets don't use,
+// e.g. a control key. Errors returned from the bound functions are handled
+// like errors returned from widgets.
+func KeyBindings(r *keybinding.Registry) Option {
+	return option(func(c *Container) error {
+		if r == nil {
+			return errors.New("invalid KeyBindings, the registry cannot be nil")
+		}
+		c.opts.keyBindings = r
+		return nil
+	})
+}
+
+// ScrollKeys configures the keyboard keys that scroll the content of a
+// Scrollable container while the container is focused. These keys aren't
+// delivered to any widgets while the content of the container is being
+// scrolled. The keys must be unique.
+// If not provided, the content can only be scrolled using the mouse.
+func ScrollKeys(up, down, left, right keyboard.Key) Option {
+	return option(func(c *Container) error {
+		c.opts.scrollKeys = &scrollKeys{
+			up:    up,
+			down:  down,
+			left:  left,
+			right: right,
+		}
+		return nil
+	})
+}
+
+// Tooltip sets a text that is displayed in a small box near the container
+// while the mouse pointer is over the container or while the container or any
+// of its sub containers is focused. The box is drawn over other containers
+// and positioned so that it stays on the terminal.
+//
+// The tooltip appears on the first redraw after the container was hovered or
+// focused for the duration set by TooltipDelay. Keyboard events hide the
+// tooltip until the delay elapses again. Whether the pointer can hover
+// without clicking depends on the terminal reporting mouse motion.
+//
+// The text can contain multiple lines separated by newline characters.
+func Tooltip(text string) Option {
+	return option(func(c *Container) error {
+		if text != "" {
+			if err := wrap.ValidText(text); err != nil {
+				return fmt.Errorf("invalid Tooltip: %v", err)
+			}
+		}
+		c.opts.tooltip = text
+		return nil
+	})
+}
+
+// Badge draws the badge over a corner of the container, on top of its border
+// and its content, e.g. to display the count of unread items or to signal an
+// alert. The badge isn't drawn if it doesn't fit into the container.
+// Use Container.Update to change the badge at runtime, a nil badge removes
+// it.
+func Badge(b *badge.Badge) Option {
+	return option(func(c *Container) error {
+		c.opts.badge = b
+		return nil
+	})
+}
+
+// BorderButtons places the buttons in the corner of the border of the
+// container, e.g. to close, collapse or maximize window-like panels with the
+// mouse. The buttons are drawn from left to right over the border line and
+// the border title, separated by one cell of the border line. The buttons
+// aren't drawn if the container has no border or if they don't fit into it.
+// Calling this option again replaces the buttons, provide no buttons to
+// remove them.
+func BorderButtons(corner badge.Corner, buttons ...BorderButton) Option {
+	return option(func(c *Container) error {
+		switch corner {
+		case badge.CornerTopRight, badge.CornerTopLeft, badge.CornerBottomRight, badge.CornerBottomLeft:
+		default:
+			return fmt.Errorf("invalid BorderButtons corner %v", corner)
+		}
+		for i, b := range buttons {
+			if err := wrap.ValidText(string(b.Glyph)); err != nil {
+				return fmt.Errorf("invalid glyph of border button #%d: %v", i, err)
+			}
+			if runewidth.RuneWidth(b.Glyph) == 0 {
+				return fmt.Errorf("invalid glyph of border button #%d: %q has zero width", i, b.Glyph)
+			}
+			if b.OnClick == nil {
+				return fmt.Errorf("border button #%d must have an OnClick callback", i)
+			}
+		}
+		c.opts.borderButtons = &borderButtons{
+			corner:  corner,
+			buttons: append([]BorderButton(nil), buttons...),
+		}
+		return nil
+	})
+}
+
+// DefaultTooltipDelay is the default value for the TooltipDelay option.
+const DefaultTooltipDelay = 500 * time.Millisecond
+
+// TooltipDelay sets how long the container must be hovered or focused before
+// its tooltip is displayed. Must be zero or a positive duration.
+// Defaults to DefaultTooltipDelay.
+func TooltipDelay(d time.Duration) Option {
+	return option(func(c *Container) error {
+		if d < 0 {
+			return fmt.Errorf("invalid TooltipDelay(%v), must be zero or a positive duration", d)
+		}
+		c.opts.tooltipDelay = d
+		return nil
+	})
+}
+
+// TooltipCellOpts sets the cell options of the tooltip box, e.g. its colors.
+// Defaults to the default terminal colors.
+func TooltipCellOpts(opts ...cell.Option) Option {
+	return option(func(c *Container) error {
+		c.opts.tooltipCellOpts = opts
+		return nil
+	})
+}
+
+// DefaultReadOnlyIndicator is the default value for the ReadOnlyIndicator
+// option.
+const DefaultReadOnlyIndicator = "READ ONLY"
+
+// ReadOnlyIndicator sets the text displayed in the top right corner of the
+// terminal while the dashboard is in the read-only mode, see SetReadOnly.
+// An empty text disables the indicator. Only has an effect when provided to
+// the root container.
+// Defaults to DefaultReadOnlyIndicator.
+func ReadOnlyIndicator(text string) Option {
+	return option(func(c *Container) error {
+		if text != "" {
+			if err := wrap.ValidText(text); err != nil {
+				return fmt.Errorf("invalid ReadOnlyIndicator: %v", err)
+			}
+			if strings.Contains(text, "\n") {
+				return fmt.Errorf("invalid ReadOnlyIndicator %q, cannot contain newline characters", text)
+			}
+		}
+		c.opts.readOnlyIndicator = text
+		return nil
+	})
+}
+
+// EmojiFallback replaces the emoji sequences with the glyph when drawing on
+// the terminal, for terminals that cannot display them. The emoji sequences
+// are emoji joined with a zero width joiner, emoji with a presentation
+// selector or a skin tone modifier and flags. The glyph must be a half-width
+// or a full-width rune, sequences narrower than the glyph are replaced with
+// '?'. Zero disables the replacement. Only has an effect when provided to the
+// root container.
+// Defaults to zero, i.e. the sequences are drawn as is.
+func EmojiFallback(glyph rune) Option {
+	return option(func(c *Container) error {
+		if glyph != 0 {
+			if rw := runewidth.RuneWidth(glyph); rw < 1 || rw > 2 || runewidth.IsCombining(glyph) {
+				return fmt.Errorf("invalid EmojiFallback %q, must be a half-width or a full-width rune", glyph)
+			}
+		}
+		c.opts.emojiFallback = glyph
+		return nil
+	})
+}
+
+// BrailleHalfDensity draws the braille patterns, e.g. the lines of the
+// LineChart widget, with half of their horizontal resolution. Fewer cells
+// change between frames, which keeps redrawing fast on very large terminals.
+// Only has an effect when provided to the root container.
+func BrailleHalfDensity() Option {
+	return option(func(c *Container) error {
+		c.opts.brailleHalfDensity = true
+		return nil
+	})
+}
+
+// StatusBar attaches the widget as a status bar along the top or the bottom
+// edge of the terminal, outside of the layout of the containers. The status
+// bar occupies one row and the containers share the rest of the terminal.
+// Typically used with the statusbar widget, but accepts any widget. The
+// widget doesn't receive keyboard or mouse events.
+// Can only be provided to the root container. A nil widget removes the status
+// bar.
+func StatusBar(w widgetapi.Widget, v align.Vertical) Option {
+	return option(func(c *Container) error {
+		if c.parent != nil {
+			return errors.New("the StatusBar option can only be provided to the root container")
+		}
+		if v != align.VerticalTop && v != align.VerticalBottom {
+			return fmt.Errorf("invalid StatusBar position %v, must be either %v or %v", v, align.VerticalTop, align.VerticalBottom)
+		}
+		c.opts.statusBar = w
+		c.opts.statusBarPos = v
+		return nil
+	})
+}
+
+// ReadOnlyIndicatorCellOpts sets the cell options of the read-only
+// indicator, see ReadOnlyIndicator. Only has an effect when provided to the
+// root container.
+// Defaults to black text on yellow background.
+func ReadOnlyIndicatorCellOpts(opts ...cell.Option) Option {
+	return option(func(c *Container) error {
+		c.opts.readOnlyIndicatorCellOpts = opts
+		return nil
+	})
+}
+
 // splitType identifies how a container is split.
 type splitType int
 
@@ -0,0 +1,87 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// privacy.go locates the sensitive content on the terminal.
+
+import (
+	"image"
+
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// SensitiveAreas returns the areas of the terminal that display sensitive
+// content as of the last draw, i.e. the areas of the containers with the
+// Sensitive option and the areas reported by widgets that implement
+// widgetapi.Sensitive. Pass it to postprocess.NewPrivacy to mask these areas
+// while the dashboard is screen-shared.
+func (c *Container) SensitiveAreas() []image.Rectangle {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		errStr string
+		areas  []image.Rectangle
+	)
+	preOrder(rootCont(c), &errStr, visitFunc(func(c *Container) error {
+		if ancestorSensitive(c.parent) {
+			return nil // Already covered by the ancestor.
+		}
+		if c.opts.sensitive {
+			areas = append(areas, c.area)
+			return nil
+		}
+		s, ok := c.opts.widget.(widgetapi.Sensitive)
+		if !ok {
+			return nil
+		}
+		for _, ar := range s.SensitiveAreas() {
+			if t := widgetToTerm(c, ar); !t.Empty() {
+				areas = append(areas, t)
+			}
+		}
+		return nil
+	}))
+	return areas
+}
+
+// ancestorSensitive determines if the container or any of its ancestors has
+// the Sensitive option.
+func ancestorSensitive(c *Container) bool {
+	for ; c != nil; c = c.parent {
+		if c.opts.sensitive {
+			return true
+		}
+	}
+	return false
+}
+
+// widgetToTerm translates an area on the widget canvas to the visible area
+// on the terminal. Masks the whole widget area if its position cannot be
+// determined, erring on the side of privacy.
+func widgetToTerm(c *Container, ar image.Rectangle) image.Rectangle {
+	wa, err := c.widgetArea()
+	if err != nil {
+		return c.area
+	}
+	vp, err := c.viewport()
+	if err != nil {
+		return wa
+	}
+	if vp != nil {
+		return ar.Sub(vp.offset).Add(vp.area.Min).Intersect(vp.area)
+	}
+	return ar.Add(wa.Min).Intersect(wa)
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/private/fakewidget"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// sensitiveWidget is a fake widget that reports sensitive areas.
+type sensitiveWidget struct {
+	*fakewidget.Mirror
+
+	areas []image.Rectangle
+}
+
+// SensitiveAreas implements widgetapi.Sensitive.SensitiveAreas.
+func (sw *sensitiveWidget) SensitiveAreas() []image.Rectangle {
+	return sw.areas
+}
+
+func TestSensitiveAreas(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts func() []Option
+		want []image.Rectangle
+	}{
+		{
+			desc: "no sensitive content",
+			opts: func() []Option {
+				return []Option{
+					PlaceWidget(fakewidget.New(widgetapi.Options{})),
+				}
+			},
+		},
+		{
+			desc: "sensitive container",
+			opts: func() []Option {
+				return []Option{
+					SplitVertical(
+						Left(),
+						Right(Sensitive()),
+					),
+				}
+			},
+			want: []image.Rectangle{image.Rect(10, 0, 20, 10)},
+		},
+		{
+			desc: "sub containers of a sensitive container are covered by it",
+			opts: func() []Option {
+				return []Option{
+					Sensitive(),
+					SplitVertical(
+						Left(Sensitive()),
+						Right(PlaceWidget(&sensitiveWidget{
+							Mirror: fakewidget.New(widgetapi.Options{}),
+							areas:  []image.Rectangle{image.Rect(0, 0, 1, 1)},
+						})),
+					),
+				}
+			},
+			want: []image.Rectangle{image.Rect(0, 0, 20, 10)},
+		},
+		{
+			desc: "translates the areas of the widget to the terminal",
+			opts: func() []Option {
+				return []Option{
+					SplitVertical(
+						Left(),
+						Right(
+							Border(linestyle.Light),
+							PlaceWidget(&sensitiveWidget{
+								Mirror: fakewidget.New(widgetapi.Options{}),
+								areas: []image.Rectangle{
+									image.Rect(0, 0, 2, 1),
+									image.Rect(6, 7, 10, 10),
+									image.Rect(20, 20, 21, 21),
+								},
+							}),
+						),
+					),
+				}
+			},
+			want: []image.Rectangle{
+				image.Rect(11, 1, 13, 2),
+				image.Rect(17, 8, 19, 9),
+			},
+		},
+		{
+			desc: "translates the areas of a scrolled widget",
+			opts: func() []Option {
+				return []Option{
+					Scrollable(),
+					PlaceWidget(&sensitiveWidget{
+						Mirror: fakewidget.New(widgetapi.Options{MinimumSize: image.Point{20, 20}}),
+						areas: []image.Rectangle{
+							image.Rect(0, 5, 5, 15),
+						},
+					}),
+				}
+			},
+			want: []image.Rectangle{
+				// The horizontal scrollbar occupies the last row.
+				image.Rect(0, 5, 5, 9),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			c, err := New(faketerm.MustNew(image.Point{20, 10}), tc.opts()...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got := c.SensitiveAreas()
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("SensitiveAreas => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
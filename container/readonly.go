@@ -0,0 +1,102 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// readonly.go contains code that implements the read-only mode.
+
+import (
+	"image"
+
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// SetReadOnly switches the read-only mode of the whole container tree on or
+// off. Useful for observers of shared sessions that should be able to look
+// around the dashboard without changing it.
+//
+// While in the read-only mode, widgets don't receive keyboard and paste
+// events, they only receive mouse wheel events and swipe gestures, so that
+// they can be scrolled. The user can still move the focus, scroll the
+// containers and resize the splits. The key sequences bound via the
+// KeyBindings option still run their functions, functions that change the
+// dashboard should check ReadOnly.
+//
+// The mode is indicated in the top right corner of the terminal, see the
+// ReadOnlyIndicator option.
+func (c *Container) SetReadOnly(readOnly bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	root := rootCont(c)
+	if root.readOnly == readOnly {
+		return
+	}
+	root.readOnly = readOnly
+	// Removes the indicator.
+	root.clearNeeded = true
+}
+
+// ReadOnly asserts whether the container tree is in the read-only mode, see
+// SetReadOnly.
+func (c *Container) ReadOnly() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return rootCont(c).readOnly
+}
+
+// readOnlyMouse asserts whether the mouse event can be delivered to widgets
+// in the read-only mode.
+func readOnlyMouse(m *terminalapi.Mouse) bool {
+	switch m.Button {
+	case mouse.ButtonWheelUp, mouse.ButtonWheelDown, mouse.ButtonNone:
+		return true
+	default:
+		return false
+	}
+}
+
+// drawReadOnly draws the read-only indicator in the top right corner of the
+// terminal if the container tree is in the read-only mode.
+// Caller must hold c.mu.
+func (c *Container) drawReadOnly() error {
+	root := rootCont(c)
+	text := root.opts.readOnlyIndicator
+	if !root.readOnly || text == "" {
+		return nil
+	}
+
+	// One cell of padding on each side.
+	width := runewidth.StringWidth(text) + 2
+	size := c.term.Size()
+	if width > size.X || size.Y < 1 {
+		return nil
+	}
+	cvs, err := canvas.New(image.Rect(size.X-width, 0, size.X, 1))
+	if err != nil {
+		return err
+	}
+	cellOpts := root.opts.readOnlyIndicatorCellOpts
+	if err := cvs.SetAreaCells(cvs.Area(), ' ', cellOpts...); err != nil {
+		return err
+	}
+	if err := draw.Text(cvs, text, image.Point{1, 0}, draw.TextCellOpts(cellOpts...)); err != nil {
+		return err
+	}
+	return cvs.Apply(c.term)
+}
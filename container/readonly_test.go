@@ -0,0 +1,214 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"image"
+	"testing"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/private/fakewidget"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// readOnlyWidgetOpts are the options of the widget used in the read-only
+// tests.
+var readOnlyWidgetOpts = widgetapi.Options{
+	WantKeyboard: widgetapi.KeyScopeFocused,
+	WantMouse:    widgetapi.MouseScopeWidget,
+}
+
+// mustDrawIndicator draws the read-only indicator with the text at the top
+// right corner of the terminal.
+func mustDrawIndicator(ft *faketerm.Terminal, text string, opts ...cell.Option) {
+	width := len(text) + 2
+	cvs := testcanvas.MustNew(image.Rect(ft.Area().Max.X-width, 0, ft.Area().Max.X, 1))
+	testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ', opts...)
+	testdraw.MustText(cvs, text, image.Point{1, 0}, draw.TextCellOpts(opts...))
+	testcanvas.MustApply(cvs, ft)
+}
+
+func TestReadOnly(t *testing.T) {
+	termSize := image.Point{30, 10}
+	defaultIndicatorOpts := []cell.Option{
+		cell.FgColor(cell.ColorBlack),
+		cell.BgColor(cell.ColorYellow),
+	}
+
+	tests := []struct {
+		desc   string
+		opts   []Option
+		events []terminalapi.Event
+		// writable indicates that the read-only mode is switched off after
+		// the events and before the later events are processed.
+		writable   bool
+		later      []terminalapi.Event
+		want       func(size image.Point) *faketerm.Terminal
+		wantNewErr bool
+	}{
+		{
+			desc:       "fails on indicator with newline",
+			opts:       []Option{ReadOnlyIndicator("a\nb")},
+			wantNewErr: true,
+		},
+		{
+			desc: "keyboard and paste events don't reach the widget",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Paste{Text: "b"},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(ft.Area()), &widgetapi.Meta{Focused: true}, readOnlyWidgetOpts)
+				mustDrawIndicator(ft, DefaultReadOnlyIndicator, defaultIndicatorOpts...)
+				return ft
+			},
+		},
+		{
+			desc: "clicks don't reach the widget, the wheel does",
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonWheelUp},
+				&terminalapi.Mouse{Position: image.Point{2, 2}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{2, 2}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(ft.Area()), &widgetapi.Meta{Focused: true}, readOnlyWidgetOpts,
+					&terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonWheelUp},
+				)
+				mustDrawIndicator(ft, DefaultReadOnlyIndicator, defaultIndicatorOpts...)
+				return ft
+			},
+		},
+		{
+			desc: "custom indicator",
+			opts: []Option{
+				ReadOnlyIndicator("RO"),
+				ReadOnlyIndicatorCellOpts(cell.BgColor(cell.ColorRed)),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(ft.Area()), &widgetapi.Meta{Focused: true}, readOnlyWidgetOpts)
+				mustDrawIndicator(ft, "RO", cell.BgColor(cell.ColorRed))
+				return ft
+			},
+		},
+		{
+			desc: "indicator can be disabled",
+			opts: []Option{ReadOnlyIndicator("")},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(ft.Area()), &widgetapi.Meta{Focused: true}, readOnlyWidgetOpts)
+				return ft
+			},
+		},
+		{
+			desc: "leaving the read-only mode removes the indicator and delivers events",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+			},
+			writable: true,
+			later: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'b'},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(ft.Area()), &widgetapi.Meta{Focused: true}, readOnlyWidgetOpts,
+					&terminalapi.Keyboard{Key: 'b'},
+				)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := faketerm.MustNew(termSize)
+			opts := append([]Option{PlaceWidget(fakewidget.New(readOnlyWidgetOpts))}, tc.opts...)
+			c, err := New(got, opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Fatalf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			c.SetReadOnly(true)
+			if !c.ReadOnly() {
+				t.Errorf("ReadOnly => false, want true")
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			for _, ev := range tc.events {
+				if err := c.Inject(ev); err != nil {
+					t.Fatalf("Inject(%v) => unexpected error: %v", ev, err)
+				}
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			if tc.writable {
+				c.SetReadOnly(false)
+				for _, ev := range tc.later {
+					if err := c.Inject(ev); err != nil {
+						t.Fatalf("Inject(%v) => unexpected error: %v", ev, err)
+					}
+				}
+				if err := c.Draw(); err != nil {
+					t.Fatalf("Draw => unexpected error: %v", err)
+				}
+			}
+
+			if diff := faketerm.Diff(tc.want(termSize), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestReadOnlyKeepsNavigation(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{40, 20})
+	c, err := New(
+		ft,
+		SplitVertical(
+			Left(PlaceWidget(fakewidget.New(readOnlyWidgetOpts))),
+			Right(PlaceWidget(fakewidget.New(readOnlyWidgetOpts))),
+		),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	c.SetReadOnly(true)
+
+	for _, ev := range click(image.Point{30, 5}) {
+		if err := c.Inject(ev); err != nil {
+			t.Fatalf("Inject(%v) => unexpected error: %v", ev, err)
+		}
+	}
+	if !c.focusTracker.isActive(c.second) {
+		t.Errorf("clicking the right container in the read-only mode didn't focus it")
+	}
+}
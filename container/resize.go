@@ -0,0 +1,219 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// resize.go contains code that resizes split containers at runtime.
+
+import (
+	"errors"
+	"image"
+
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// splitResize stores the options of a split that can be resized at runtime.
+type splitResize struct {
+	// mouse indicates if the split can be resized by dragging the boundary
+	// between the child containers with the mouse.
+	mouse bool
+	// keys indicates if the split can be resized using the keyboard.
+	keys     bool
+	decrease keyboard.Key
+	increase keyboard.Key
+	// onResize is called with the new sizes of the child containers.
+	onResize func(first, second int)
+}
+
+// splitDrag tracks a boundary between split containers that is being dragged
+// with the mouse.
+type splitDrag struct {
+	// cont is the split container whose boundary is being dragged.
+	cont *Container
+	// offset is the distance in cells between the mouse pointer and the
+	// boundary when the boundary was grabbed.
+	offset int
+	// moved indicates if the boundary moved since it was grabbed.
+	moved bool
+}
+
+// splitArea returns the area the container splits between its child
+// containers.
+func (c *Container) splitArea() (image.Rectangle, error) {
+	return c.opts.padding.apply(c.usable())
+}
+
+// splitPos returns the position of the point along the axis the container is
+// split on, relative to the area.
+func (c *Container) splitPos(ar image.Rectangle, p image.Point) int {
+	if c.opts.split == splitTypeVertical {
+		return p.X - ar.Min.X
+	}
+	return p.Y - ar.Min.Y
+}
+
+// resizeTo moves the boundary between the child containers so that the first
+// one gets the specified number of cells. Both child containers keep at least
+// one cell, the limits set by SplitMinCells and SplitMaxCells still apply.
+// The new sizes are stored as split weights, so they scale with the terminal.
+func (c *Container) resizeTo(cells int) error {
+	ar, err := c.splitArea()
+	if err != nil {
+		return err
+	}
+	total := c.splitSize(ar)
+	if total < 2 {
+		return nil
+	}
+
+	cells = clampInt(cells, 1, total-1)
+	c.opts.splitFixed = DefaultSplitFixed
+	c.opts.splitPercent = DefaultSplitPercent
+	c.opts.splitWeights = &splitWeights{
+		first:  cells,
+		second: total - cells,
+	}
+	// The layout changed, remove content left over from the old layout.
+	rootCont(c).clearNeeded = true
+	return nil
+}
+
+// reportResize returns a function that reports the current sizes of the
+// child containers to the callback provided via SplitOnResize.
+// The returned function must be called without holding c.mu.
+func (c *Container) reportResize() func() error {
+	fn := c.opts.splitResize.onResize
+	w := c.opts.splitWeights
+	return func() error {
+		if fn != nil && w != nil {
+			fn(w.first, w.second)
+		}
+		return nil
+	}
+}
+
+// splitBoundaryAt returns the innermost container that can be resized with
+// the mouse and whose boundary between the child containers is at the point.
+// The boundary consists of the last cell of the first child container and
+// the first cell of the second one.
+// Also returns the distance between the point and the boundary.
+func splitBoundaryAt(c *Container, p image.Point) (*Container, int, error) {
+	var (
+		errStr string
+		target *Container
+		offset int
+	)
+	preOrder(c, &errStr, visitFunc(func(cur *Container) error {
+		if cur.first == nil || cur.opts.splitResize == nil || !cur.opts.splitResize.mouse {
+			return nil
+		}
+		ar, err := cur.splitArea()
+		if err != nil {
+			return err
+		}
+		if !p.In(ar) {
+			return nil
+		}
+
+		boundary := cur.splitCells(ar)
+		if pos := cur.splitPos(ar, p); pos == boundary-1 || pos == boundary {
+			target = cur
+			offset = boundary - pos
+		}
+		return nil
+	}))
+	if errStr != "" {
+		return nil, 0, errors.New(errStr)
+	}
+	return target, offset, nil
+}
+
+// resizeMouse processes mouse events that drag the boundaries between split
+// containers.
+// Returns nil if the event wasn't consumed, otherwise a function that must be
+// called without holding c.mu.
+// Caller must hold c.mu.
+func (c *Container) resizeMouse(m *terminalapi.Mouse) (func() error, error) {
+	root := rootCont(c)
+	if d := root.drag; d != nil {
+		if m.Button != mouse.ButtonLeft {
+			// Any other button, including the release, ends the drag.
+			root.drag = nil
+			if !d.moved {
+				return func() error { return nil }, nil
+			}
+			return d.cont.reportResize(), nil
+		}
+
+		ar, err := d.cont.splitArea()
+		if err != nil {
+			return nil, err
+		}
+		if err := d.cont.resizeTo(d.cont.splitPos(ar, m.Position) + d.offset); err != nil {
+			return nil, err
+		}
+		d.moved = true
+		return func() error { return nil }, nil
+	}
+
+	if m.Button != mouse.ButtonLeft {
+		return nil, nil
+	}
+	target, offset, err := splitBoundaryAt(root, m.Position)
+	if err != nil || target == nil {
+		return nil, err
+	}
+	root.drag = &splitDrag{
+		cont:   target,
+		offset: offset,
+	}
+	return func() error { return nil }, nil
+}
+
+// resizeKeyboard processes keyboard events that resize split containers.
+// The key resizes the nearest split container that contains the focused
+// container and was configured with matching keys via SplitResizeKeys.
+// Returns nil if the event wasn't consumed, otherwise a function that must be
+// called without holding c.mu.
+// Caller must hold c.mu.
+func (c *Container) resizeKeyboard(k *terminalapi.Keyboard) (func() error, error) {
+	for cur := c.focusTracker.container; cur != nil; cur = cur.parent {
+		sr := cur.opts.splitResize
+		if cur.first == nil || sr == nil || !sr.keys {
+			continue
+		}
+
+		var delta int
+		switch k.Key {
+		case sr.decrease:
+			delta = -1
+		case sr.increase:
+			delta = 1
+		default:
+			continue
+		}
+
+		ar, err := cur.splitArea()
+		if err != nil {
+			return nil, err
+		}
+		if err := cur.resizeTo(cur.splitCells(ar) + delta); err != nil {
+			return nil, err
+		}
+		return cur.reportResize(), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,290 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// bordersTerm returns a terminal with light borders drawn around the areas.
+func bordersTerm(size image.Point, areas ...image.Rectangle) *faketerm.Terminal {
+	ft := faketerm.MustNew(size)
+	cvs := testcanvas.MustNew(ft.Area())
+	for _, ar := range areas {
+		testdraw.MustBorder(cvs, ar)
+	}
+	testcanvas.MustApply(cvs, ft)
+	return ft
+}
+
+func TestSplitResize(t *testing.T) {
+	tests := []struct {
+		desc string
+		// split returns the options of the split container. The callback
+		// records the reported sizes.
+		split       func(onResize func(first, second int)) Option
+		termSize    image.Point
+		events      []terminalapi.Event
+		want        func(size image.Point) *faketerm.Terminal
+		wantResized [][2]int
+	}{
+		{
+			desc: "dragging the boundary with the mouse resizes the containers",
+			split: func(onResize func(first, second int)) Option {
+				return SplitVertical(
+					Left(Border(linestyle.Light)),
+					Right(Border(linestyle.Light)),
+					SplitResizable(),
+					SplitOnResize(onResize),
+				)
+			},
+			termSize: image.Point{20, 4},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{10, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{8, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{5, 2}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{5, 2}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return bordersTerm(size, image.Rect(0, 0, 5, 4), image.Rect(5, 0, 20, 4))
+			},
+			wantResized: [][2]int{{5, 15}},
+		},
+		{
+			desc: "the boundary can be grabbed on the last cell of the first container",
+			split: func(onResize func(first, second int)) Option {
+				return SplitVertical(
+					Left(Border(linestyle.Light)),
+					Right(Border(linestyle.Light)),
+					SplitResizable(),
+					SplitOnResize(onResize),
+				)
+			},
+			termSize: image.Point{20, 4},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{9, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{13, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{13, 1}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return bordersTerm(size, image.Rect(0, 0, 14, 4), image.Rect(14, 0, 20, 4))
+			},
+			wantResized: [][2]int{{14, 6}},
+		},
+		{
+			desc: "both containers keep at least one cell",
+			split: func(onResize func(first, second int)) Option {
+				return SplitVertical(
+					Left(),
+					Right(Border(linestyle.Light)),
+					SplitResizable(),
+					SplitOnResize(onResize),
+				)
+			},
+			termSize: image.Point{20, 4},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{10, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{0, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{0, 1}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return bordersTerm(size, image.Rect(1, 0, 20, 4))
+			},
+			wantResized: [][2]int{{1, 19}},
+		},
+		{
+			desc: "dragging outside of the boundary doesn't resize",
+			split: func(onResize func(first, second int)) Option {
+				return SplitVertical(
+					Left(Border(linestyle.Light)),
+					Right(Border(linestyle.Light)),
+					SplitResizable(),
+					SplitOnResize(onResize),
+				)
+			},
+			termSize: image.Point{20, 4},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{5, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{2, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{2, 1}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				// The click focuses the left container.
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 10, 4), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testdraw.MustBorder(cvs, image.Rect(10, 0, 20, 4))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "split isn't resizable without the option",
+			split: func(onResize func(first, second int)) Option {
+				return SplitVertical(
+					Left(Border(linestyle.Light)),
+					Right(Border(linestyle.Light)),
+					SplitOnResize(onResize),
+				)
+			},
+			termSize: image.Point{20, 4},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{10, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{5, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{5, 1}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				// The click focuses the left container.
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 10, 4), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testdraw.MustBorder(cvs, image.Rect(10, 0, 20, 4))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "keys resize the split",
+			split: func(onResize func(first, second int)) Option {
+				return SplitHorizontal(
+					Top(Border(linestyle.Light)),
+					Bottom(Border(linestyle.Light)),
+					SplitResizeKeys('-', '+'),
+					SplitOnResize(onResize),
+				)
+			},
+			termSize: image.Point{10, 10},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: '+'},
+				&terminalapi.Keyboard{Key: '+'},
+				&terminalapi.Keyboard{Key: '+'},
+				&terminalapi.Keyboard{Key: '-'},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return bordersTerm(size, image.Rect(0, 0, 10, 7), image.Rect(0, 7, 10, 10))
+			},
+			wantResized: [][2]int{{6, 4}, {7, 3}, {8, 2}, {7, 3}},
+		},
+		{
+			desc: "keys resize the split that contains the focused container",
+			split: func(onResize func(first, second int)) Option {
+				return SplitHorizontal(
+					Top(
+						SplitVertical(
+							Left(Border(linestyle.Light)),
+							Right(Border(linestyle.Light)),
+							SplitResizeKeys('<', '>'),
+							SplitOnResize(onResize),
+						),
+					),
+					Bottom(Border(linestyle.Light)),
+				)
+			},
+			termSize: image.Point{20, 10},
+			events: []terminalapi.Event{
+				// Focus the left container.
+				&terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonRelease},
+				&terminalapi.Keyboard{Key: '>'},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 11, 5), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testdraw.MustBorder(cvs, image.Rect(11, 0, 20, 5))
+				testdraw.MustBorder(cvs, image.Rect(0, 5, 20, 10))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantResized: [][2]int{{11, 9}},
+		},
+		{
+			desc: "keys don't resize splits that don't contain the focused container",
+			split: func(onResize func(first, second int)) Option {
+				return SplitHorizontal(
+					Top(
+						SplitVertical(
+							Left(Border(linestyle.Light)),
+							Right(Border(linestyle.Light)),
+							SplitResizeKeys('<', '>'),
+							SplitOnResize(onResize),
+						),
+					),
+					Bottom(Border(linestyle.Light)),
+				)
+			},
+			termSize: image.Point{20, 10},
+			events: []terminalapi.Event{
+				// Focus the bottom container.
+				&terminalapi.Mouse{Position: image.Point{1, 7}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{1, 7}, Button: mouse.ButtonRelease},
+				&terminalapi.Keyboard{Key: '>'},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 10, 5))
+				testdraw.MustBorder(cvs, image.Rect(10, 0, 20, 5))
+				testdraw.MustBorder(cvs, image.Rect(0, 5, 20, 10), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			var gotResized [][2]int
+			onResize := func(first, second int) {
+				gotResized = append(gotResized, [2]int{first, second})
+			}
+
+			got := faketerm.MustNew(tc.termSize)
+			c, err := New(got, tc.split(onResize))
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			for _, ev := range tc.events {
+				if err := c.processEvent(ev); err != nil {
+					t.Fatalf("processEvent(%v) => unexpected error: %v", ev, err)
+				}
+				if err := c.Draw(); err != nil {
+					t.Fatalf("Draw => unexpected error: %v", err)
+				}
+			}
+
+			if diff := faketerm.Diff(tc.want(tc.termSize), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+			if diff := pretty.Compare(tc.wantResized, gotResized); diff != "" {
+				t.Errorf("SplitOnResize => unexpected sizes, diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// responsive.go contains logic of the options that depend on the size of the
+// container.
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/mum4k/termdash/private/canvas"
+)
+
+// Breakpoint is a condition on the size of the area allocated to a container,
+// i.e. the area including its margin. The limits are inclusive, zero means no
+// limit.
+type Breakpoint struct {
+	// MinWidth and MaxWidth are the limits of the width in cells.
+	MinWidth int
+	MaxWidth int
+	// MinHeight and MaxHeight are the limits of the height in cells.
+	MinHeight int
+	MaxHeight int
+}
+
+// validate validates the breakpoint.
+func (bp Breakpoint) validate() error {
+	if bp.MinWidth < 0 || bp.MaxWidth < 0 || bp.MinHeight < 0 || bp.MaxHeight < 0 {
+		return fmt.Errorf("invalid Breakpoint%+v, the limits cannot be negative", bp)
+	}
+	if bp == (Breakpoint{}) {
+		return fmt.Errorf("invalid Breakpoint%+v, at least one limit must be set", bp)
+	}
+	if bp.MaxWidth > 0 && bp.MinWidth > bp.MaxWidth {
+		return fmt.Errorf("invalid Breakpoint%+v, MinWidth cannot be larger than MaxWidth", bp)
+	}
+	if bp.MaxHeight > 0 && bp.MinHeight > bp.MaxHeight {
+		return fmt.Errorf("invalid Breakpoint%+v, MinHeight cannot be larger than MaxHeight", bp)
+	}
+	return nil
+}
+
+// matches determines if an area of the provided size meets the breakpoint.
+func (bp Breakpoint) matches(size image.Point) bool {
+	switch {
+	case size.X < bp.MinWidth || (bp.MaxWidth > 0 && size.X > bp.MaxWidth):
+		return false
+	case size.Y < bp.MinHeight || (bp.MaxHeight > 0 && size.Y > bp.MaxHeight):
+		return false
+	default:
+		return true
+	}
+}
+
+// responsiveRule are options applied while the breakpoint matches.
+type responsiveRule struct {
+	bp   Breakpoint
+	opts []Option
+}
+
+// responsiveState is the container as it was before the options of the
+// matching breakpoints were applied.
+type responsiveState struct {
+	opts   options
+	first  *Container
+	second *Container
+	// matching are the indexes of the rules that matched.
+	matching []int
+}
+
+// responsiveOption implements Option.
+type responsiveOption struct {
+	rule *responsiveRule
+}
+
+// set implements Option.set.
+func (ro *responsiveOption) set(c *Container) error {
+	if err := ro.rule.bp.validate(); err != nil {
+		return err
+	}
+	for _, opt := range ro.rule.opts {
+		if _, ok := opt.(*responsiveOption); ok {
+			return errors.New("the Responsive options cannot be nested")
+		}
+	}
+
+	// Apply the options on a copy of the container, so that invalid options
+	// are reported now rather than when the breakpoint first matches.
+	scratch := &Container{
+		parent:       c.parent,
+		term:         c.term,
+		focusTracker: c.focusTracker,
+		opts:         newOptions(c.opts),
+		mu:           c.mu,
+	}
+	if err := applyOptions(scratch, ro.rule.opts...); err != nil {
+		return fmt.Errorf("invalid options of Responsive(%+v): %v", ro.rule.bp, err)
+	}
+	c.opts.responsive = append(c.opts.responsive, ro.rule)
+	return nil
+}
+
+// Responsive applies the options to the container while the size of the area
+// allocated to it meets the breakpoint. This can be used to adjust the layout
+// to small terminals, e.g. hide the border or replace a widget with a more
+// compact one.
+// The breakpoints are evaluated whenever the container is drawn. The options
+// of all the matching breakpoints are applied in the order they were provided
+// on top of the other options of the container. Once a breakpoint stops
+// matching, the container returns to its previous options. Changes made by
+// Container.Update to the sub containers created by the options of a matching
+// breakpoint are lost when it stops matching.
+// This option can be provided multiple times, but cannot be nested.
+func Responsive(bp Breakpoint, opts ...Option) Option {
+	return &responsiveOption{
+		rule: &responsiveRule{
+			bp:   bp,
+			opts: opts,
+		},
+	}
+}
+
+// matchingRules returns indexes of the responsive rules whose breakpoints
+// match an area of the provided size.
+func (c *Container) matchingRules(size image.Point) []int {
+	var matching []int
+	for i, r := range c.opts.responsive {
+		if r.bp.matches(size) {
+			matching = append(matching, i)
+		}
+	}
+	return matching
+}
+
+// revert returns the container to the options it had before the options of
+// the matching breakpoints were applied.
+func (c *Container) revert() {
+	if c.responsive == nil {
+		return
+	}
+	*c.opts = c.responsive.opts
+	c.first = c.responsive.first
+	c.second = c.responsive.second
+	c.responsive = nil
+}
+
+// respond applies the options of the breakpoints that match the area
+// allocated to the container. When the set of matching breakpoints changed,
+// the area is cleared, since the layout of the container changes.
+// Caller must hold c.mu.
+func (c *Container) respond(ar image.Rectangle) error {
+	if len(c.opts.responsive) == 0 {
+		return nil
+	}
+
+	matching := c.matchingRules(ar.Size())
+	var prev []int
+	if c.responsive != nil {
+		prev = c.responsive.matching
+	}
+	if sameInts(prev, matching) {
+		return nil
+	}
+
+	c.revert()
+	if len(matching) > 0 {
+		c.responsive = &responsiveState{
+			opts:     *c.opts,
+			first:    c.first,
+			second:   c.second,
+			matching: matching,
+		}
+		for _, i := range matching {
+			if err := applyOptions(c, c.opts.responsive[i].opts...); err != nil {
+				return err
+			}
+		}
+	}
+
+	root := rootCont(c)
+	if err := validateOptions(root); err != nil {
+		return err
+	}
+	// The dragged boundary and the hovered container might not exist anymore.
+	root.drag = nil
+	root.hover = nil
+	root.tooltip = nil
+	if root.notify != nil {
+		setNotifyFunc(c, root.notify)
+	}
+	if !c.focusTracker.reachableFrom(root) {
+		c.focusTracker.setActive(c)
+	}
+
+	if ar.Empty() {
+		return nil
+	}
+	cvs, err := canvas.New(ar)
+	if err != nil {
+		return err
+	}
+	return cvs.Apply(c.term)
+}
+
+// sameInts determines if the two slices contain the same values.
+func sameInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,243 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"image"
+	"testing"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/private/fakewidget"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+func TestResponsive(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		// sizes are the sizes of the terminal on the consecutive calls to
+		// Draw, the terminal is compared after the last one.
+		sizes []image.Point
+		// update if not nil is called before the last call to Draw.
+		update     func(*Container) error
+		want       func(size image.Point) *faketerm.Terminal
+		wantNewErr bool
+	}{
+		{
+			desc:       "fails on a negative limit",
+			opts:       []Option{Responsive(Breakpoint{MaxWidth: -1}, Border(linestyle.None))},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on a breakpoint without limits",
+			opts:       []Option{Responsive(Breakpoint{}, Border(linestyle.None))},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails when the minimum is above the maximum",
+			opts:       []Option{Responsive(Breakpoint{MinHeight: 5, MaxHeight: 4}, Border(linestyle.None))},
+			wantNewErr: true,
+		},
+		{
+			desc: "fails on nested breakpoints",
+			opts: []Option{
+				Responsive(Breakpoint{MaxWidth: 10},
+					Responsive(Breakpoint{MaxHeight: 10}, Border(linestyle.None)),
+				),
+			},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on invalid options of a breakpoint",
+			opts:       []Option{Responsive(Breakpoint{MaxWidth: 10}, MarginTop(-1))},
+			wantNewErr: true,
+		},
+		{
+			desc: "breakpoint doesn't match",
+			opts: []Option{
+				Border(linestyle.Light),
+				Responsive(Breakpoint{MaxWidth: 9}, Border(linestyle.None)),
+			},
+			sizes: []image.Point{{10, 5}},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, cvs.Area(), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "hides the border on a narrow terminal",
+			opts: []Option{
+				Border(linestyle.Light),
+				Responsive(Breakpoint{MaxWidth: 9}, Border(linestyle.None)),
+			},
+			sizes: []image.Point{{10, 5}, {9, 5}},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+		{
+			desc: "restores the border when the terminal grows",
+			opts: []Option{
+				Border(linestyle.Light),
+				Responsive(Breakpoint{MaxWidth: 9}, Border(linestyle.None)),
+			},
+			sizes: []image.Point{{9, 5}, {10, 5}},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, cvs.Area(), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "applies options of all the matching breakpoints in order",
+			opts: []Option{
+				Border(linestyle.Light),
+				Responsive(Breakpoint{MaxHeight: 5}, BorderTitle("short")),
+				Responsive(Breakpoint{MinWidth: 10, MaxWidth: 20}, BorderTitle("medium")),
+			},
+			sizes: []image.Point{{15, 5}},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(
+					cvs,
+					cvs.Area(),
+					draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)),
+					draw.BorderTitle("medium", draw.OverrunModeThreeDot, cell.FgColor(cell.ColorYellow)),
+				)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "evaluates the area allocated to a sub container",
+			opts: []Option{
+				SplitVertical(
+					Left(
+						PlaceWidget(fakewidget.New(widgetapi.Options{})),
+						Responsive(Breakpoint{MaxWidth: 10}, Clear()),
+					),
+					Right(
+						PlaceWidget(fakewidget.New(widgetapi.Options{})),
+						Responsive(Breakpoint{MaxWidth: 9}, Clear()),
+					),
+				),
+			},
+			sizes: []image.Point{{30, 5}, {21, 5}},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(10, 0, 21, 5)), &widgetapi.Meta{}, widgetapi.Options{})
+				return ft
+			},
+		},
+		{
+			desc: "updates the options without the breakpoint",
+			opts: []Option{
+				ID("root"),
+				Border(linestyle.Light),
+				Responsive(Breakpoint{MaxWidth: 9}, Border(linestyle.None)),
+			},
+			sizes: []image.Point{{9, 5}, {10, 5}},
+			update: func(c *Container) error {
+				return c.Update("root", BorderTitle("new"))
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(
+					cvs,
+					cvs.Area(),
+					draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)),
+					draw.BorderTitle("new", draw.OverrunModeThreeDot, cell.FgColor(cell.ColorYellow)),
+				)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ft := faketerm.MustNew(image.Point{30, 5})
+			c, err := New(ft, tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Fatalf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			for i, size := range tc.sizes {
+				if err := ft.Resize(size); err != nil {
+					t.Fatalf("Resize => unexpected error: %v", err)
+				}
+				if i == len(tc.sizes)-1 && tc.update != nil {
+					if err := tc.update(c); err != nil {
+						t.Fatalf("update => unexpected error: %v", err)
+					}
+				}
+				if err := c.Draw(); err != nil {
+					t.Fatalf("Draw => unexpected error: %v", err)
+				}
+			}
+			if diff := faketerm.Diff(tc.want(ft.Size()), ft); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestResponsiveMovesFocus(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{30, 5})
+	c, err := New(
+		ft,
+		SplitVertical(
+			Left(ID("left")),
+			Right(ID("right")),
+		),
+		Responsive(Breakpoint{MaxWidth: 20}, Clear()),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	right, err := findID(c, "right")
+	if err != nil {
+		t.Fatalf("findID => unexpected error: %v", err)
+	}
+	c.focusTracker.setActive(right)
+
+	if err := ft.Resize(image.Point{20, 5}); err != nil {
+		t.Fatalf("Resize => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if !c.focusTracker.isActive(c) {
+		t.Errorf("Draw => the root container isn't focused after the focused sub container was removed")
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// scroll.go contains code that scrolls the content of widgets that don't fit
+// into their containers.
+
+import (
+	"image"
+
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// The runes used to draw the scrollbars.
+const (
+	scrollTrackVRune = '│'
+	scrollTrackHRune = '─'
+	scrollThumbRune  = '█'
+)
+
+// viewport is the visible part of a widget canvas that is larger than the
+// area of its container.
+type viewport struct {
+	// area is the area of the terminal the visible part is drawn on.
+	area image.Rectangle
+	// size is the size of the canvas the widget draws on.
+	size image.Point
+	// offset is the position of the visible part on the widget canvas.
+	offset image.Point
+	// vBar and hBar are the areas of the vertical and the horizontal
+	// scrollbars or zero areas if the scrollbar isn't needed.
+	vBar image.Rectangle
+	hBar image.Rectangle
+}
+
+// clamp returns the offset adjusted so that the viewport stays within the
+// widget canvas.
+func (vp *viewport) clamp(offset image.Point) image.Point {
+	maxOffset := vp.size.Sub(vp.area.Size())
+	return image.Point{
+		clampInt(offset.X, 0, maxOffset.X),
+		clampInt(offset.Y, 0, maxOffset.Y),
+	}
+}
+
+// clampInt returns the value limited to the range low <= value <= high.
+func clampInt(value, low, high int) int {
+	if value < low {
+		return low
+	}
+	if value > high {
+		return high
+	}
+	return value
+}
+
+// toCanvas translates a point on the terminal into a point on the widget
+// canvas. Returns false if the point falls outside of the viewport.
+func (vp *viewport) toCanvas(p image.Point) (image.Point, bool) {
+	if !p.In(vp.area) {
+		return image.Point{}, false
+	}
+	return p.Sub(vp.area.Min).Add(vp.offset), true
+}
+
+// scrollViewport returns the viewport of a scrollable container whose widget
+// needs more space than the widget area provides.
+// Returns nil if the container isn't scrollable, the widget fits or the area
+// is too small to fit the scrollbars.
+// Clamps the scrolling offset of the container so that it stays within the
+// widget canvas.
+func (c *Container) scrollViewport(wa image.Rectangle, need image.Point) *viewport {
+	if !c.opts.scrollable {
+		return nil
+	}
+
+	var needV, needH bool
+	view := wa
+	// Adding one scrollbar reduces the space, so the other one might become
+	// necessary too.
+	for i := 0; i < 2; i++ {
+		needV = view.Dy() < need.Y
+		needH = view.Dx() < need.X
+		view = wa
+		if needV {
+			view.Max.X--
+		}
+		if needH {
+			view.Max.Y--
+		}
+	}
+	if !needV && !needH {
+		return nil
+	}
+	if view.Dx() < 1 || view.Dy() < 1 {
+		return nil
+	}
+
+	vp := &viewport{
+		area: view,
+		size: view.Size(),
+	}
+	if need.X > vp.size.X {
+		vp.size.X = need.X
+	}
+	if need.Y > vp.size.Y {
+		vp.size.Y = need.Y
+	}
+	if needV {
+		vp.vBar = image.Rect(view.Max.X, view.Min.Y, view.Max.X+1, view.Max.Y)
+	}
+	if needH {
+		vp.hBar = image.Rect(view.Min.X, view.Max.Y, view.Max.X, view.Max.Y+1)
+	}
+
+	c.scroll = vp.clamp(c.scroll)
+	vp.offset = c.scroll
+	return vp
+}
+
+// viewport returns the viewport of the container as of the last draw or nil
+// if the widget isn't being scrolled.
+func (c *Container) viewport() (*viewport, error) {
+	if !c.hasWidget() {
+		return nil, nil
+	}
+	wa, err := c.widgetArea()
+	if err != nil {
+		return nil, err
+	}
+	return c.scrollViewport(wa, c.widgetNeedSize()), nil
+}
+
+// widgetNeedSize returns the minimum size the widget needs to draw.
+func (c *Container) widgetNeedSize() image.Point {
+	needSize := image.Point{1, 1}
+	wOpts := c.opts.widget.Options()
+	if wOpts.MinimumSize.X > 0 && wOpts.MinimumSize.Y > 0 {
+		needSize = wOpts.MinimumSize
+	}
+	return needSize
+}
+
+// drawScrolled requests the widget to draw on a canvas of the size it needs
+// and draws the visible part of it along with the scrollbars.
+func drawScrolled(c *Container, vp *viewport, meta *widgetapi.Meta) error {
+	wCvs, err := canvas.New(image.Rect(0, 0, vp.size.X, vp.size.Y))
+	if err != nil {
+		return err
+	}
+	if err := c.opts.widget.Draw(wCvs, meta); err != nil {
+		return err
+	}
+
+	visible := vp.area.Union(vp.vBar).Union(vp.hBar)
+	cvs, err := canvas.New(visible)
+	if err != nil {
+		return err
+	}
+	viewAr := vp.area.Sub(visible.Min)
+	if err := copyViewport(wCvs, vp.offset, cvs, viewAr); err != nil {
+		return err
+	}
+
+	if vp.vBar != image.ZR {
+		bar := vp.vBar.Sub(visible.Min)
+		start, length := scrollThumb(bar.Dy(), vp.area.Dy(), vp.size.Y, vp.offset.Y)
+		if err := drawScrollbar(cvs, bar, image.Rect(bar.Min.X, bar.Min.Y+start, bar.Max.X, bar.Min.Y+start+length), scrollTrackVRune); err != nil {
+			return err
+		}
+	}
+	if vp.hBar != image.ZR {
+		bar := vp.hBar.Sub(visible.Min)
+		start, length := scrollThumb(bar.Dx(), vp.area.Dx(), vp.size.X, vp.offset.X)
+		if err := drawScrollbar(cvs, bar, image.Rect(bar.Min.X+start, bar.Min.Y, bar.Min.X+start+length, bar.Max.Y), scrollTrackHRune); err != nil {
+			return err
+		}
+	}
+	return applyCanvas(c, cvs)
+}
+
+// copyViewport copies the part of the source canvas that starts at the offset
+// into the area on the destination canvas.
+// Wide runes that don't fit into the area are replaced with spaces.
+func copyViewport(src *canvas.Canvas, offset image.Point, dst *canvas.Canvas, ar image.Rectangle) error {
+	for x := 0; x < ar.Dx(); x++ {
+		for y := 0; y < ar.Dy(); y++ {
+			c, err := src.Cell(offset.Add(image.Point{x, y}))
+			if err != nil {
+				return err
+			}
+
+			p := ar.Min.Add(image.Point{x, y})
+			if c.Rune == 0 {
+				if x > 0 && partialAt(src, offset.Add(image.Point{x, y})) {
+					// Already covered by the wide rune copied before.
+					continue
+				}
+				if err := dst.SetCellOpts(p, c.Opts); err != nil {
+					return err
+				}
+				continue
+			}
+
+			r := c.Rune
+			if x+runewidth.CellWidth(r, c.Combining) > ar.Dx() {
+				r = ' '
+			}
+			cells, err := dst.SetCell(p, r, c.Opts)
+			if err != nil {
+				return err
+			}
+			if r != c.Rune {
+				continue
+			}
+			for _, cr := range c.Combining {
+				// Combining characters attach to the cell before the point.
+				if _, err := dst.SetCell(image.Point{p.X + cells, p.Y}, cr); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// partialAt determines if the point on the canvas is the partial cell that
+// follows a wide rune.
+func partialAt(cvs *canvas.Canvas, p image.Point) bool {
+	if p.X == 0 {
+		return false
+	}
+	prev, err := cvs.Cell(image.Point{p.X - 1, p.Y})
+	if err != nil {
+		return false
+	}
+	return runewidth.CellWidth(prev.Rune, prev.Combining) > 1
+}
+
+// scrollThumb returns the start and the length of the scrollbar thumb on a
+// track of the specified length.
+func scrollThumb(track, visible, total, offset int) (int, int) {
+	length := track * visible / total
+	if length < 1 {
+		length = 1
+	}
+	if total == visible {
+		return 0, length
+	}
+	return offset * (track - length) / (total - visible), length
+}
+
+// drawScrollbar draws the scrollbar track and its thumb.
+func drawScrollbar(cvs *canvas.Canvas, track, thumb image.Rectangle, trackRune rune) error {
+	if err := draw.Rectangle(cvs, track, draw.RectChar(trackRune)); err != nil {
+		return err
+	}
+	return draw.Rectangle(cvs, thumb, draw.RectChar(scrollThumbRune))
+}
+
+// scrollBy scrolls the viewport of the container by the specified number of
+// cells. The offset is clamped on the next draw.
+func (c *Container) scrollBy(vp *viewport, delta image.Point) {
+	c.scroll = vp.clamp(vp.offset.Add(delta))
+}
+
+// scrollMouse scrolls the viewport of the container under the mouse pointer
+// if the event is a mouse wheel event.
+// Returns true if the event was consumed.
+// Caller must hold c.mu.
+func (c *Container) scrollMouse(m *terminalapi.Mouse) (bool, error) {
+	var delta int
+	switch m.Button {
+	case mouse.ButtonWheelUp:
+		delta = -1
+	case mouse.ButtonWheelDown:
+		delta = 1
+	default:
+		return false, nil
+	}
+
+	target := pointCont(c, m.Position)
+	if target == nil {
+		return false, nil
+	}
+	vp, err := target.viewport()
+	if err != nil || vp == nil {
+		return false, err
+	}
+	if vp.vBar != image.ZR {
+		target.scrollBy(vp, image.Point{0, delta})
+	} else {
+		target.scrollBy(vp, image.Point{delta, 0})
+	}
+	return true, nil
+}
+
+// scrollKeyboard scrolls the viewport of the focused container if the key is
+// one of its scroll keys.
+// Returns true if the event was consumed.
+// Caller must hold c.mu.
+func (c *Container) scrollKeyboard(k *terminalapi.Keyboard) (bool, error) {
+	target := c.focusTracker.container
+	sk := target.opts.scrollKeys
+	if sk == nil {
+		return false, nil
+	}
+
+	var delta image.Point
+	switch k.Key {
+	case sk.up:
+		delta = image.Point{0, -1}
+	case sk.down:
+		delta = image.Point{0, 1}
+	case sk.left:
+		delta = image.Point{-1, 0}
+	case sk.right:
+		delta = image.Point{1, 0}
+	default:
+		return false, nil
+	}
+
+	vp, err := target.viewport()
+	if err != nil || vp == nil {
+		return false, err
+	}
+	target.scrollBy(vp, delta)
+	return true, nil
+}
+
+// scrollKeys are the keys that scroll the content of the container.
+type scrollKeys struct {
+	up    keyboard.Key
+	down  keyboard.Key
+	left  keyboard.Key
+	right keyboard.Key
+}
@@ -0,0 +1,233 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"image"
+	"sync"
+	"testing"
+
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// patternWidget fills its canvas with a pattern that identifies every cell
+// and records the mouse events it receives.
+type patternWidget struct {
+	mu        sync.Mutex
+	opts      widgetapi.Options
+	lastMouse *terminalapi.Mouse
+	keys      int
+}
+
+// patternRune returns the rune the patternWidget draws at the point.
+func patternRune(p image.Point) rune {
+	return rune('0' + p.Y*6 + p.X)
+}
+
+// Draw implements widgetapi.Widget.Draw.
+func (pw *patternWidget) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	ar := cvs.Area()
+	for x := ar.Min.X; x < ar.Max.X; x++ {
+		for y := ar.Min.Y; y < ar.Max.Y; y++ {
+			p := image.Point{x, y}
+			if _, err := cvs.SetCell(p, patternRune(p)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Keyboard implements widgetapi.Widget.Keyboard.
+func (pw *patternWidget) Keyboard(k *terminalapi.Keyboard) error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.keys++
+	return nil
+}
+
+// Mouse implements widgetapi.Widget.Mouse.
+func (pw *patternWidget) Mouse(m *terminalapi.Mouse) error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.lastMouse = m
+	return nil
+}
+
+// Options implements widgetapi.Widget.Options.
+func (pw *patternWidget) Options() widgetapi.Options {
+	return pw.opts
+}
+
+// scrolledTerm returns a 4x4 terminal that displays a 3x3 part of the
+// patternWidget at the offset along with the scrollbars.
+func scrolledTerm(offset image.Point, vThumb, hThumb int) *faketerm.Terminal {
+	ft := faketerm.MustNew(image.Point{4, 4})
+	cvs := testcanvas.MustNew(ft.Area())
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 3; y++ {
+			testcanvas.MustSetCell(cvs, image.Point{x, y}, patternRune(offset.Add(image.Point{x, y})))
+		}
+	}
+	for i := 0; i < 3; i++ {
+		vr, hr := scrollTrackVRune, scrollTrackHRune
+		if i == vThumb {
+			vr = scrollThumbRune
+		}
+		if i == hThumb {
+			hr = scrollThumbRune
+		}
+		testcanvas.MustSetCell(cvs, image.Point{3, i}, vr)
+		testcanvas.MustSetCell(cvs, image.Point{i, 3}, hr)
+	}
+	testcanvas.MustApply(cvs, ft)
+	return ft
+}
+
+func TestScrollable(t *testing.T) {
+	pw := &patternWidget{
+		opts: widgetapi.Options{
+			MinimumSize:  image.Point{6, 6},
+			WantKeyboard: widgetapi.KeyScopeFocused,
+			WantMouse:    widgetapi.MouseScopeWidget,
+		},
+	}
+	ft := faketerm.MustNew(image.Point{4, 4})
+	c, err := New(
+		ft,
+		PlaceWidget(pw),
+		Scrollable(),
+		ScrollKeys(keyboard.KeyArrowUp, keyboard.KeyArrowDown, keyboard.KeyArrowLeft, keyboard.KeyArrowRight),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	steps := []struct {
+		desc      string
+		events    []terminalapi.Event
+		want      *faketerm.Terminal
+		wantMouse *terminalapi.Mouse
+		wantKeys  int
+	}{
+		{
+			desc: "draws the top left corner with scrollbars",
+			want: scrolledTerm(image.Point{0, 0}, 0, 0),
+		},
+		{
+			desc: "mouse wheel scrolls vertically",
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonWheelDown},
+				&terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonWheelDown},
+			},
+			want: scrolledTerm(image.Point{0, 2}, 1, 0),
+		},
+		{
+			desc: "keyboard scrolls horizontally and stops at the edge",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+			},
+			want: scrolledTerm(image.Point{3, 2}, 1, 2),
+		},
+		{
+			desc: "keys other than scroll keys reach the widget",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+			},
+			want:     scrolledTerm(image.Point{3, 2}, 1, 2),
+			wantKeys: 1,
+		},
+		{
+			desc: "mouse events are relative to the widget canvas",
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 0}, Button: mouse.ButtonRight},
+			},
+			want:      scrolledTerm(image.Point{3, 2}, 1, 2),
+			wantMouse: &terminalapi.Mouse{Position: image.Point{4, 2}, Button: mouse.ButtonRight},
+			wantKeys:  1,
+		},
+		{
+			desc: "mouse wheel scrolls back up",
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonWheelUp},
+				&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonWheelUp},
+				&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonWheelUp},
+			},
+			want:      scrolledTerm(image.Point{3, 0}, 0, 2),
+			wantMouse: &terminalapi.Mouse{Position: image.Point{4, 2}, Button: mouse.ButtonRight},
+			wantKeys:  1,
+		},
+	}
+
+	for _, step := range steps {
+		if err := c.Draw(); err != nil {
+			t.Fatalf("%s: Draw => unexpected error: %v", step.desc, err)
+		}
+		for _, ev := range step.events {
+			if err := c.processEvent(ev); err != nil {
+				t.Fatalf("%s: processEvent(%v) => unexpected error: %v", step.desc, ev, err)
+			}
+		}
+		if err := c.Draw(); err != nil {
+			t.Fatalf("%s: Draw => unexpected error: %v", step.desc, err)
+		}
+		if diff := faketerm.Diff(step.want, ft); diff != "" {
+			t.Errorf("%s: Draw => %v", step.desc, diff)
+		}
+
+		pw.mu.Lock()
+		gotMouse, gotKeys := pw.lastMouse, pw.keys
+		pw.mu.Unlock()
+		if step.wantMouse == nil && gotMouse != nil || step.wantMouse != nil && (gotMouse == nil || *gotMouse != *step.wantMouse) {
+			t.Errorf("%s: widget got mouse event %v, want %v", step.desc, gotMouse, step.wantMouse)
+		}
+		if gotKeys != step.wantKeys {
+			t.Errorf("%s: widget got %d keyboard events, want %d", step.desc, gotKeys, step.wantKeys)
+		}
+	}
+}
+
+func TestScrollableNotNeeded(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{6, 6})
+	pw := &patternWidget{
+		opts: widgetapi.Options{MinimumSize: image.Point{6, 6}},
+	}
+	c, err := New(ft, PlaceWidget(pw), Scrollable())
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	want := faketerm.MustNew(ft.Size())
+	cvs := testcanvas.MustNew(want.Area())
+	if err := pw.Draw(cvs, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	testcanvas.MustApply(cvs, want)
+	if diff := faketerm.Diff(want, ft); diff != "" {
+		t.Errorf("Draw => %v", diff)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// statusbar.go contains code that draws the status bar.
+
+import (
+	"image"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// statusBarAreas splits the terminal area into the row of the status bar and
+// the area left for the containers. The status bar area is empty if there is
+// no status bar or the terminal is too small to fit it.
+// Caller must hold c.mu.
+func (c *Container) statusBarAreas(full image.Rectangle) (bar, rest image.Rectangle) {
+	if c.opts.statusBar == nil || full.Dy() < 2 {
+		return image.ZR, full
+	}
+	if c.opts.statusBarPos == align.VerticalTop {
+		return image.Rect(full.Min.X, full.Min.Y, full.Max.X, full.Min.Y+1),
+			image.Rect(full.Min.X, full.Min.Y+1, full.Max.X, full.Max.Y)
+	}
+	return image.Rect(full.Min.X, full.Max.Y-1, full.Max.X, full.Max.Y),
+		image.Rect(full.Min.X, full.Min.Y, full.Max.X, full.Max.Y-1)
+}
+
+// drawStatusBar draws the status bar widget in the area.
+// Caller must hold c.mu.
+func (c *Container) drawStatusBar(ar image.Rectangle) error {
+	w := c.opts.statusBar
+	if w == nil || ar.Empty() {
+		return nil
+	}
+
+	cvs, err := canvas.New(ar)
+	if err != nil {
+		return err
+	}
+	if min := w.Options().MinimumSize; ar.Dx() < min.X || ar.Dy() < min.Y {
+		if err := draw.ResizeNeeded(cvs); err != nil {
+			return err
+		}
+		return applyCanvas(c, cvs)
+	}
+
+	meta := &widgetapi.Meta{
+		Capabilities: c.term.Capabilities(),
+	}
+	if err := w.Draw(cvs, meta); err != nil {
+		return err
+	}
+	return applyCanvas(c, cvs)
+}
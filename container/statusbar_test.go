@@ -0,0 +1,172 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// rowWidget is a widget that fills its canvas with a rune.
+type rowWidget struct {
+	r rune
+}
+
+// Draw implements widgetapi.Widget.Draw.
+func (rw *rowWidget) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	return cvs.SetAreaCells(cvs.Area(), rw.r)
+}
+
+// Keyboard implements widgetapi.Widget.Keyboard.
+func (*rowWidget) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("unimplemented")
+}
+
+// Mouse implements widgetapi.Widget.Mouse.
+func (*rowWidget) Mouse(m *terminalapi.Mouse) error {
+	return errors.New("unimplemented")
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*rowWidget) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize: image.Point{1, 1},
+		MaximumSize: image.Point{0, 1},
+	}
+}
+
+// mustDrawRow fills the row of the terminal with the rune.
+func mustDrawRow(ft *faketerm.Terminal, y int, r rune) {
+	cvs := testcanvas.MustNew(image.Rect(0, y, ft.Area().Max.X, y+1))
+	testcanvas.MustSetAreaCells(cvs, cvs.Area(), r)
+	testcanvas.MustApply(cvs, ft)
+}
+
+func TestStatusBar(t *testing.T) {
+	tests := []struct {
+		desc       string
+		termSize   image.Point
+		opts       []Option
+		want       func(size image.Point) *faketerm.Terminal
+		wantNewErr bool
+	}{
+		{
+			desc:       "fails on invalid position",
+			termSize:   image.Point{10, 5},
+			opts:       []Option{StatusBar(&rowWidget{r: 's'}, align.VerticalMiddle)},
+			wantNewErr: true,
+		},
+		{
+			desc:     "fails on a sub container",
+			termSize: image.Point{10, 5},
+			opts: []Option{
+				SplitVertical(
+					Left(StatusBar(&rowWidget{r: 's'}, align.VerticalBottom)),
+					Right(),
+				),
+			},
+			wantNewErr: true,
+		},
+		{
+			desc:     "status bar at the bottom",
+			termSize: image.Point{10, 5},
+			opts: []Option{
+				Border(linestyle.Light),
+				StatusBar(&rowWidget{r: 's'}, align.VerticalBottom),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 10, 4), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustApply(cvs, ft)
+				mustDrawRow(ft, 4, 's')
+				return ft
+			},
+		},
+		{
+			desc:     "status bar at the top",
+			termSize: image.Point{10, 5},
+			opts: []Option{
+				Border(linestyle.Light),
+				StatusBar(&rowWidget{r: 's'}, align.VerticalTop),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 1, 10, 5), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustApply(cvs, ft)
+				mustDrawRow(ft, 0, 's')
+				return ft
+			},
+		},
+		{
+			desc:     "no status bar on a terminal with a single row",
+			termSize: image.Point{10, 1},
+			opts: []Option{
+				StatusBar(&rowWidget{r: 's'}, align.VerticalTop),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+		{
+			desc:     "a nil widget removes the status bar",
+			termSize: image.Point{10, 5},
+			opts: []Option{
+				Border(linestyle.Light),
+				StatusBar(&rowWidget{r: 's'}, align.VerticalTop),
+				StatusBar(nil, align.VerticalTop),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, cvs.Area(), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := faketerm.MustNew(tc.termSize)
+			c, err := New(got, tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Fatalf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(got.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// tooltip.go contains code that displays tooltips of containers.
+
+import (
+	"image"
+	"strings"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// tooltipState tracks the container whose tooltip is being displayed.
+type tooltipState struct {
+	// cont is the container whose tooltip is displayed or whose widget
+	// provided the tooltip.
+	cont *Container
+	// text is the displayed text.
+	text string
+	// region is the area of the widget's canvas the tooltip belongs to if
+	// it was provided by the widget, see widgetapi.Tooltipper.
+	region image.Rectangle
+	// hovered indicates that the tooltip is displayed because the mouse
+	// pointer is over the container, otherwise it is displayed because the
+	// container is focused.
+	hovered bool
+	// since is the time when the container started being hovered or focused.
+	since time.Time
+}
+
+// timeNow returns the current time.
+// Replaced from tests.
+var timeNow = time.Now
+
+// tooltipCont returns the container or the closest of its ancestors that has
+// a tooltip or nil if there isn't any.
+func tooltipCont(c *Container) *Container {
+	for ; c != nil; c = c.parent {
+		if c.opts.tooltip != "" {
+			return c
+		}
+	}
+	return nil
+}
+
+// widgetTooltip returns the tooltip the widget in the container provides at
+// the point on the terminal or nil if there isn't any.
+// Caller must hold c.mu.
+func widgetTooltip(c *Container, p image.Point) (*widgetapi.Tooltip, error) {
+	if c == nil || !c.hasWidget() {
+		return nil, nil
+	}
+	tt, ok := c.opts.widget.(widgetapi.Tooltipper)
+	if !ok {
+		return nil, nil
+	}
+	cp, ok, err := c.canvasPoint(p)
+	if err != nil || !ok {
+		return nil, err
+	}
+	for _, t := range tt.Tooltips() {
+		if cp.In(t.Area) && t.Text != "" {
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+// updateTooltip determines which tooltip should be displayed. The tooltip of
+// the widget under the mouse pointer takes precedence over the tooltip of the
+// container under the mouse pointer, which takes precedence over the tooltip
+// of the focused container.
+// Caller must hold c.mu.
+func (c *Container) updateTooltip() error {
+	root := rootCont(c)
+	wt, err := widgetTooltip(root.hover, root.pointer)
+	if err != nil {
+		return err
+	}
+
+	next := &tooltipState{
+		hovered: true,
+	}
+	if wt != nil {
+		next.cont = root.hover
+		next.text = wt.Text
+		next.region = wt.Area
+	} else if target := tooltipCont(root.hover); target != nil {
+		next.cont = target
+		next.text = target.opts.tooltip
+	} else if target := tooltipCont(c.focusTracker.container); target != nil {
+		next.cont = target
+		next.text = target.opts.tooltip
+		next.hovered = false
+	}
+
+	ts := root.tooltip
+	switch {
+	case next.cont == nil:
+		root.tooltip = nil
+	case ts == nil || ts.cont != next.cont || ts.hovered != next.hovered || ts.text != next.text || ts.region != next.region:
+		next.since = timeNow()
+		root.tooltip = next
+	}
+	return nil
+}
+
+// tooltipArea returns the area of the tooltip box of the specified size. The
+// box is placed below the mouse pointer or below the focused container if it
+// fits onto the terminal, otherwise above it.
+// Returns false if the box doesn't fit onto the terminal.
+func (c *Container) tooltipArea(term image.Rectangle, size image.Point) (image.Rectangle, bool) {
+	root := rootCont(c)
+	ts := root.tooltip
+
+	var below, above image.Point
+	if ts.hovered {
+		below = root.pointer.Add(image.Point{1, 1})
+		above = image.Point{below.X, root.pointer.Y - size.Y}
+	} else {
+		below = image.Point{ts.cont.area.Min.X, ts.cont.area.Max.Y}
+		above = image.Point{ts.cont.area.Min.X, ts.cont.area.Min.Y - size.Y}
+	}
+
+	start := below
+	if start.Y+size.Y > term.Max.Y {
+		start = above
+	}
+	if size.X > term.Dx() || size.Y > term.Dy() {
+		return image.ZR, false
+	}
+	start.X = clampInt(start.X, term.Min.X, term.Max.X-size.X)
+	start.Y = clampInt(start.Y, term.Min.Y, term.Max.Y-size.Y)
+	return image.Rectangle{start, start.Add(size)}, true
+}
+
+// tooltipBox is a tooltip box ready to be drawn.
+type tooltipBox struct {
+	// area is the area of the box on the terminal.
+	area image.Rectangle
+	// lines are the lines of the tooltip text.
+	lines []string
+	// cellOpts are the cell options of the box.
+	cellOpts []cell.Option
+}
+
+// nextTooltip returns the tooltip box that should be displayed if a container
+// has been hovered or focused for longer than its tooltip delay, otherwise
+// nil.
+// Caller must hold c.mu.
+func (c *Container) nextTooltip() (*tooltipBox, error) {
+	if err := c.updateTooltip(); err != nil {
+		return nil, err
+	}
+	ts := rootCont(c).tooltip
+	if ts == nil || timeNow().Sub(ts.since) < ts.cont.opts.tooltipDelay {
+		return nil, nil
+	}
+
+	lines := strings.Split(ts.text, "\n")
+	var width int
+	for _, l := range lines {
+		if w := runewidth.StringWidth(l); w > width {
+			width = w
+		}
+	}
+	// One cell for the border and one for padding on each side.
+	size := image.Point{width + 4, len(lines) + 2}
+	term := image.Rectangle{image.ZP, c.term.Size()}
+	ar, ok := c.tooltipArea(term, size)
+	if !ok {
+		return nil, nil
+	}
+	return &tooltipBox{
+		area:     ar,
+		lines:    lines,
+		cellOpts: ts.cont.opts.tooltipCellOpts,
+	}, nil
+}
+
+// tooltipMoved asserts whether the tooltip box drawn on the last call to Draw
+// moves elsewhere or disappears when the box is drawn next.
+// Caller must hold c.mu.
+func (c *Container) tooltipMoved() (bool, error) {
+	root := rootCont(c)
+	if root.tooltipDrawn.Empty() {
+		return false, nil
+	}
+	box, err := c.nextTooltip()
+	if err != nil {
+		return false, err
+	}
+	return box == nil || box.area != root.tooltipDrawn, nil
+}
+
+// clearTooltip clears the area of the tooltip box drawn on the last call to
+// Draw. Not every container sets all the cells of its area when drawn, so the
+// box would otherwise remain visible over them.
+// Caller must hold c.mu.
+func (c *Container) clearTooltip() error {
+	root := rootCont(c)
+	if root.tooltipDrawn.Empty() {
+		return nil
+	}
+	cvs, err := canvas.New(root.tooltipDrawn)
+	if err != nil {
+		return err
+	}
+	return cvs.Apply(c.term)
+}
+
+// drawTooltip draws the tooltip over the containers if a container has been
+// hovered or focused for longer than its tooltip delay.
+// Caller must hold c.mu.
+func (c *Container) drawTooltip() error {
+	root := rootCont(c)
+	root.tooltipDrawn = image.ZR
+	box, err := c.nextTooltip()
+	if err != nil || box == nil {
+		return err
+	}
+
+	cvs, err := canvas.New(box.area)
+	if err != nil {
+		return err
+	}
+	if err := cvs.SetAreaCells(cvs.Area(), ' ', box.cellOpts...); err != nil {
+		return err
+	}
+	if err := draw.Border(cvs, cvs.Area(), draw.BorderCellOpts(box.cellOpts...)); err != nil {
+		return err
+	}
+	for i, l := range box.lines {
+		if err := draw.Text(cvs, l, image.Point{2, 1 + i}, draw.TextCellOpts(box.cellOpts...)); err != nil {
+			return err
+		}
+	}
+	if err := cvs.Apply(c.term); err != nil {
+		return err
+	}
+	root.tooltipDrawn = box.area
+	return nil
+}
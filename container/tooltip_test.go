@@ -0,0 +1,456 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/private/fakewidget"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// tooltipTerm returns a terminal of the specified size with a tooltip box
+// drawn at the area.
+func tooltipTerm(size image.Point, ar image.Rectangle, lines ...string) *faketerm.Terminal {
+	ft := faketerm.MustNew(size)
+	cvs := testcanvas.MustNew(ar)
+	testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ')
+	testdraw.MustBorder(cvs, cvs.Area())
+	for i, l := range lines {
+		testdraw.MustText(cvs, l, image.Point{2, 1 + i})
+	}
+	testcanvas.MustApply(cvs, ft)
+	return ft
+}
+
+// click returns the mouse events of a left click at the point.
+func click(p image.Point) []terminalapi.Event {
+	return []terminalapi.Event{
+		&terminalapi.Mouse{Position: p, Button: mouse.ButtonLeft},
+		&terminalapi.Mouse{Position: p, Button: mouse.ButtonRelease},
+	}
+}
+
+func TestTooltip(t *testing.T) {
+	termSize := image.Point{20, 10}
+	tests := []struct {
+		desc    string
+		top     []Option
+		bottom  []Option
+		events  []terminalapi.Event
+		elapsed time.Duration
+		// later are events processed after the time elapsed.
+		later      []terminalapi.Event
+		want       func(size image.Point) *faketerm.Terminal
+		wantNewErr bool
+	}{
+		{
+			desc:       "fails on tooltip with control characters",
+			top:        []Option{Tooltip("a\tb")},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on negative TooltipDelay",
+			top:        []Option{Tooltip("hi"), TooltipDelay(-1)},
+			wantNewErr: true,
+		},
+		{
+			desc: "no tooltip when the container isn't hovered or focused",
+			top:  []Option{Tooltip("hi"), TooltipDelay(0)},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+		{
+			desc:   "displays the tooltip below the mouse pointer",
+			top:    []Option{Tooltip("hi"), TooltipDelay(0)},
+			events: click(image.Point{1, 1}),
+			want: func(size image.Point) *faketerm.Terminal {
+				return tooltipTerm(size, image.Rect(2, 2, 8, 5), "hi")
+			},
+		},
+		{
+			desc:   "displays tooltip of the parent container",
+			top:    []Option{Tooltip("hi"), TooltipDelay(0), SplitVertical(Left(), Right())},
+			events: click(image.Point{1, 1}),
+			want: func(size image.Point) *faketerm.Terminal {
+				return tooltipTerm(size, image.Rect(2, 2, 8, 5), "hi")
+			},
+		},
+		{
+			desc:   "displays multi-line tooltip",
+			top:    []Option{Tooltip("hi\nthere"), TooltipDelay(0)},
+			events: click(image.Point{1, 1}),
+			want: func(size image.Point) *faketerm.Terminal {
+				return tooltipTerm(size, image.Rect(2, 2, 11, 6), "hi", "there")
+			},
+		},
+		{
+			desc:   "displays the tooltip above the mouse pointer when it doesn't fit below",
+			bottom: []Option{Tooltip("hi"), TooltipDelay(0)},
+			events: click(image.Point{1, 8}),
+			want: func(size image.Point) *faketerm.Terminal {
+				return tooltipTerm(size, image.Rect(2, 5, 8, 8), "hi")
+			},
+		},
+		{
+			desc:   "keeps the tooltip on the terminal",
+			top:    []Option{Tooltip("hi"), TooltipDelay(0)},
+			events: click(image.Point{18, 1}),
+			want: func(size image.Point) *faketerm.Terminal {
+				return tooltipTerm(size, image.Rect(14, 2, 20, 5), "hi")
+			},
+		},
+		{
+			desc: "displays the tooltip below the focused container",
+			top:  []Option{Tooltip("hi"), TooltipDelay(0)},
+			events: append(
+				click(image.Point{1, 1}),
+				&terminalapi.Mouse{Position: image.Point{1, 7}, Button: mouse.ButtonRelease},
+			),
+			want: func(size image.Point) *faketerm.Terminal {
+				return tooltipTerm(size, image.Rect(0, 5, 6, 8), "hi")
+			},
+		},
+		{
+			desc:   "moves the tooltip with the mouse pointer",
+			top:    []Option{Tooltip("hi"), TooltipDelay(0)},
+			events: click(image.Point{1, 1}),
+			later: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{10, 1}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return tooltipTerm(size, image.Rect(11, 2, 17, 5), "hi")
+			},
+		},
+		{
+			desc:   "moves the tooltip from the mouse pointer to the focused container",
+			top:    []Option{Tooltip("hi"), TooltipDelay(0)},
+			events: click(image.Point{1, 1}),
+			later: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 7}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return tooltipTerm(size, image.Rect(0, 5, 6, 8), "hi")
+			},
+		},
+		{
+			desc:   "doesn't display tooltip if the text doesn't fit onto the terminal",
+			top:    []Option{Tooltip("this text is too long for the terminal"), TooltipDelay(0)},
+			events: click(image.Point{1, 1}),
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+		{
+			desc:    "no tooltip before the delay elapses",
+			top:     []Option{Tooltip("hi"), TooltipDelay(time.Second)},
+			events:  click(image.Point{1, 1}),
+			elapsed: 999 * time.Millisecond,
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+		{
+			desc:    "displays the tooltip after the delay elapses",
+			top:     []Option{Tooltip("hi"), TooltipDelay(time.Second)},
+			events:  click(image.Point{1, 1}),
+			elapsed: time.Second,
+			want: func(size image.Point) *faketerm.Terminal {
+				return tooltipTerm(size, image.Rect(2, 2, 8, 5), "hi")
+			},
+		},
+		{
+			desc:    "keyboard events hide the tooltip and restart the delay",
+			top:     []Option{Tooltip("hi"), TooltipDelay(time.Second)},
+			events:  click(image.Point{1, 1}),
+			elapsed: time.Second,
+			later: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			timeNow = func() time.Time { return now }
+			defer func() { timeNow = time.Now }()
+
+			ft := faketerm.MustNew(termSize)
+			c, err := New(
+				ft,
+				SplitHorizontal(
+					Top(tc.top...),
+					Bottom(tc.bottom...),
+				),
+			)
+			if (err != nil) != tc.wantNewErr {
+				t.Fatalf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			for _, ev := range tc.events {
+				if err := c.processEvent(ev); err != nil {
+					t.Fatalf("processEvent(%v) => unexpected error: %v", ev, err)
+				}
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			if tc.elapsed > 0 {
+				now = now.Add(tc.elapsed)
+				if err := c.Draw(); err != nil {
+					t.Fatalf("Draw => unexpected error: %v", err)
+				}
+			}
+			for _, ev := range tc.later {
+				if err := c.processEvent(ev); err != nil {
+					t.Fatalf("processEvent(%v) => unexpected error: %v", ev, err)
+				}
+			}
+			if len(tc.later) > 0 {
+				if err := c.Draw(); err != nil {
+					t.Fatalf("Draw => unexpected error: %v", err)
+				}
+			}
+
+			if diff := faketerm.Diff(tc.want(ft.Size()), ft); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestTooltipKeepsTerminal(t *testing.T) {
+	marker := image.Point{15, 8}
+	// drawTooltip draws containers with a displayed tooltip onto the
+	// terminal.
+	drawTooltip := func(ft *faketerm.Terminal) *Container {
+		c, err := New(
+			ft,
+			SplitHorizontal(
+				Top(
+					ID("top"),
+					Tooltip("hi"),
+					TooltipDelay(0),
+					PlaceWidget(fakewidget.New(widgetapi.Options{})),
+				),
+				Bottom(ID("bottom")),
+			),
+		)
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := c.Draw(); err != nil {
+			t.Fatalf("Draw => unexpected error: %v", err)
+		}
+		for _, ev := range click(image.Point{1, 1}) {
+			if err := c.processEvent(ev); err != nil {
+				t.Fatalf("processEvent(%v) => unexpected error: %v", ev, err)
+			}
+		}
+		if err := c.Draw(); err != nil {
+			t.Fatalf("Draw => unexpected error: %v", err)
+		}
+		return c
+	}
+
+	want := faketerm.MustNew(image.Point{20, 10})
+	drawTooltip(want)
+	if err := want.SetCell(marker, 'x'); err != nil {
+		t.Fatalf("SetCell => unexpected error: %v", err)
+	}
+
+	ft := faketerm.MustNew(want.Size())
+	c := drawTooltip(ft)
+	// The bottom container doesn't set the cells of its area, the marker
+	// is only removed if the entire terminal is cleared.
+	if err := ft.SetCell(marker, 'x'); err != nil {
+		t.Fatalf("SetCell => unexpected error: %v", err)
+	}
+
+	if err := c.DrawSubtree("top"); err != nil {
+		t.Fatalf("DrawSubtree => unexpected error: %v", err)
+	}
+	if diff := faketerm.Diff(want, ft); diff != "" {
+		t.Errorf("DrawSubtree => %v", diff)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if diff := faketerm.Diff(want, ft); diff != "" {
+		t.Errorf("Draw => %v", diff)
+	}
+}
+
+// tooltipWidget is a widget that provides tooltips and records the mouse
+// events it receives.
+type tooltipWidget struct {
+	*fakewidget.Mirror
+	tips []widgetapi.Tooltip
+	mice []*terminalapi.Mouse
+}
+
+// Mouse implements widgetapi.Widget.Mouse.
+func (tw *tooltipWidget) Mouse(m *terminalapi.Mouse) error {
+	tw.mice = append(tw.mice, m)
+	return nil
+}
+
+// Tooltips implements widgetapi.Tooltipper.
+func (tw *tooltipWidget) Tooltips() []widgetapi.Tooltip {
+	return tw.tips
+}
+
+func TestWidgetTooltip(t *testing.T) {
+	hover := func(p image.Point) *terminalapi.Mouse {
+		return &terminalapi.Mouse{Position: p, Button: mouse.ButtonNone}
+	}
+	tips := []widgetapi.Tooltip{
+		{Area: image.Rect(0, 0, 5, 5), Text: "widget"},
+	}
+
+	tests := []struct {
+		desc      string
+		wOpts     widgetapi.Options
+		tips      []widgetapi.Tooltip
+		events    []terminalapi.Event
+		wantText  string
+		wantMouse int
+	}{
+		{
+			desc:     "widget tooltip takes precedence over the container tooltip",
+			tips:     tips,
+			events:   []terminalapi.Event{hover(image.Point{3, 3})},
+			wantText: "widget",
+		},
+		{
+			desc:     "falls back to the container tooltip outside of widget regions",
+			tips:     tips,
+			events:   []terminalapi.Event{hover(image.Point{15, 8})},
+			wantText: "cont",
+		},
+		{
+			desc: "ignores widget tooltips without text",
+			tips: []widgetapi.Tooltip{
+				{Area: image.Rect(0, 0, 5, 5)},
+			},
+			events:   []terminalapi.Event{hover(image.Point{3, 3})},
+			wantText: "cont",
+		},
+		{
+			desc: "follows the pointer between widget regions",
+			tips: append(tips, widgetapi.Tooltip{
+				Area: image.Rect(5, 0, 10, 5),
+				Text: "other",
+			}),
+			events: []terminalapi.Event{
+				hover(image.Point{3, 3}),
+				hover(image.Point{8, 3}),
+			},
+			wantText: "other",
+		},
+		{
+			desc: "doesn't deliver hover events to widgets that don't want them",
+			wOpts: widgetapi.Options{
+				WantMouse: widgetapi.MouseScopeWidget,
+			},
+			tips:     tips,
+			events:   []terminalapi.Event{hover(image.Point{3, 3})},
+			wantText: "widget",
+		},
+		{
+			desc: "delivers hover events to widgets that want them",
+			wOpts: widgetapi.Options{
+				WantMouse: widgetapi.MouseScopeWidget,
+				WantHover: true,
+			},
+			events: []terminalapi.Event{
+				hover(image.Point{3, 3}),
+				hover(image.Point{7, 6}),
+			},
+			wantText:  "cont",
+			wantMouse: 2,
+		},
+		{
+			desc: "delivers clicks regardless of WantHover",
+			wOpts: widgetapi.Options{
+				WantMouse: widgetapi.MouseScopeWidget,
+			},
+			events:    click(image.Point{6, 6}),
+			wantText:  "cont",
+			wantMouse: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ft := faketerm.MustNew(image.Point{20, 10})
+			w := &tooltipWidget{
+				Mirror: fakewidget.New(tc.wOpts),
+				tips:   tc.tips,
+			}
+			c, err := New(
+				ft,
+				Tooltip("cont"),
+				TooltipDelay(0),
+				MarginLeft(1),
+				MarginTop(1),
+				PlaceWidget(w),
+			)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			for _, ev := range tc.events {
+				if err := c.processEvent(ev); err != nil {
+					t.Fatalf("processEvent(%v) => unexpected error: %v", ev, err)
+				}
+				if err := c.Draw(); err != nil {
+					t.Fatalf("Draw => unexpected error: %v", err)
+				}
+			}
+
+			var gotText string
+			if ts := c.tooltip; ts != nil {
+				gotText = ts.text
+			}
+			if gotText != tc.wantText {
+				t.Errorf("tooltip text => %q, want %q", gotText, tc.wantText)
+			}
+			if got := len(w.mice); got != tc.wantMouse {
+				t.Errorf("widget received %d mouse events, want %d", got, tc.wantMouse)
+			}
+		})
+	}
+}
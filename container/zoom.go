@@ -0,0 +1,155 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+// zoom.go contains code that maximizes a container to fill the terminal.
+
+import (
+	"errors"
+	"image"
+)
+
+// Maximize temporarily maximizes the container with the specified ID, so that
+// it fills the whole terminal like a zoomed tmux pane. Only the maximized
+// container and its sub containers are drawn and receive mouse events, the
+// layout and the state of the other containers are preserved until Restore
+// is called. The status bar stays in place.
+// The focus moves to the maximized container unless it or one of its sub
+// containers is focused. Maximizing a container replaces the one that is
+// currently maximized.
+// The argument id must match exactly one container that was created with the
+// matching ID() option.
+func (c *Container) Maximize(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	root := rootCont(c)
+	target, err := findID(root, id)
+	if err != nil {
+		return err
+	}
+	root.maximize(target)
+	return nil
+}
+
+// Restore draws all the containers again after Maximize. Does nothing if no
+// container is maximized.
+func (c *Container) Restore() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rootCont(c).maximize(nil)
+}
+
+// ToggleMaximize maximizes the focused container or restores the layout if a
+// container is already maximized, see Maximize.
+func (c *Container) ToggleMaximize() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	root := rootCont(c)
+	if root.maximized != nil {
+		root.maximize(nil)
+		return
+	}
+	root.maximize(c.focusTracker.container)
+}
+
+// Maximized returns the ID of the maximized container and true, or false if
+// no container is maximized. The ID is empty if the maximized container was
+// created without the ID() option, e.g. when maximized by ToggleMaximize.
+func (c *Container) Maximized() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := rootCont(c).maximized
+	if m == nil {
+		return "", false
+	}
+	return m.opts.id, true
+}
+
+// maximize maximizes the container, nil restores the layout.
+// Caller must hold c.mu and call this on the root container.
+func (c *Container) maximize(target *Container) {
+	if target == c {
+		// The root container always fills the terminal.
+		target = nil
+	}
+	if c.maximized == target {
+		return
+	}
+	c.maximized = target
+	// The layout changed, remove the containers that are no longer drawn.
+	c.clearNeeded = true
+	c.drag = nil
+	c.pressed = nil
+	c.hover = nil
+	c.tooltip = nil
+
+	if target != nil && !c.focusTracker.reachableFrom(target) {
+		c.focusTracker.setActive(target)
+	}
+}
+
+// shown returns the container whose subtree is drawn, i.e. the maximized
+// container or the root container if none is maximized.
+// Caller must hold c.mu.
+func (c *Container) shown() *Container {
+	root := rootCont(c)
+	if root.maximized != nil {
+		return root.maximized
+	}
+	return root
+}
+
+// reachable asserts whether the target container is in the tree under the
+// node.
+func reachable(node, target *Container) bool {
+	var (
+		errStr string
+		found  bool
+	)
+	preOrder(node, &errStr, visitFunc(func(c *Container) error {
+		if c == target {
+			found = true
+		}
+		return nil
+	}))
+	return found
+}
+
+// drawMaximized draws the maximized container in the area available to the
+// root container. The other containers get zero areas, so that they aren't
+// found under the mouse pointer.
+func drawMaximized(root, m *Container, ar image.Rectangle) error {
+	var errStr string
+	preOrder(root, &errStr, visitFunc(func(c *Container) error {
+		c.area = image.ZR
+		return nil
+	}))
+	if errStr != "" {
+		return errors.New(errStr)
+	}
+
+	if err := m.respond(ar); err != nil {
+		return err
+	}
+	mar, err := m.opts.margin.apply(ar)
+	if err != nil {
+		return err
+	}
+	m.area = mar
+	return drawSubtree(m)
+}
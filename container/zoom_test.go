@@ -0,0 +1,228 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"image"
+	"testing"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+func TestMaximize(t *testing.T) {
+	tests := []struct {
+		desc string
+		// action maximizes or restores the containers.
+		action  func(c *Container) error
+		want    func(size image.Point) *faketerm.Terminal
+		wantErr bool
+	}{
+		{
+			desc: "fails on an unknown ID",
+			action: func(c *Container) error {
+				return c.Maximize("unknown")
+			},
+			wantErr: true,
+		},
+		{
+			desc: "draws only the maximized container",
+			action: func(c *Container) error {
+				return c.Maximize("right")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, cvs.Area(), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "maximizing the root container does nothing",
+			action: func(c *Container) error {
+				return c.Maximize("root")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return bordersTerm(size, image.Rect(0, 0, 10, 4), image.Rect(10, 0, 20, 4))
+			},
+		},
+		{
+			desc: "restores the layout",
+			action: func(c *Container) error {
+				if err := c.Maximize("right"); err != nil {
+					return err
+				}
+				if err := c.Draw(); err != nil {
+					return err
+				}
+				c.Restore()
+				return nil
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, image.Rect(0, 0, 10, 4))
+				testdraw.MustBorder(cvs, image.Rect(10, 0, 20, 4), draw.BorderCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "restores the layout when the maximized container is removed",
+			action: func(c *Container) error {
+				if err := c.Maximize("right"); err != nil {
+					return err
+				}
+				return c.Update("root", SplitVertical(
+					Left(Border(linestyle.Light)),
+					Right(Border(linestyle.Light)),
+					SplitPercent(30),
+				))
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return bordersTerm(size, image.Rect(0, 0, 6, 4), image.Rect(6, 0, 20, 4))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := faketerm.MustNew(image.Point{20, 4})
+			c, err := New(
+				got,
+				ID("root"),
+				SplitVertical(
+					Left(ID("left"), Border(linestyle.Light)),
+					Right(ID("right"), Border(linestyle.Light)),
+				),
+			)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			err = tc.action(c)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("action => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(got.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestToggleMaximize(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{20, 4})
+	c, err := New(
+		ft,
+		SplitVertical(
+			Left(ID("left")),
+			Right(ID("right")),
+		),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	click := func(p image.Point) {
+		t.Helper()
+		for _, ev := range []terminalapi.Event{
+			&terminalapi.Mouse{Position: p, Button: mouse.ButtonLeft},
+			&terminalapi.Mouse{Position: p, Button: mouse.ButtonRelease},
+		} {
+			if err := c.Inject(ev); err != nil {
+				t.Fatalf("Inject(%v) => unexpected error: %v", ev, err)
+			}
+		}
+	}
+
+	// The root container is focused, there is nothing to maximize.
+	c.ToggleMaximize()
+	if _, ok := c.Maximized(); ok {
+		t.Errorf("Maximized => got true, want false when the root container is focused")
+	}
+
+	click(image.Point{1, 1})
+	c.ToggleMaximize()
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if id, ok := c.Maximized(); !ok || id != "left" {
+		t.Errorf("Maximized => %q, %v, want %q, true", id, ok, "left")
+	}
+	if got, want := c.FocusedArea(), image.Rect(0, 0, 20, 4); got != want {
+		t.Errorf("FocusedArea => %v, want %v", got, want)
+	}
+
+	// The right container isn't drawn, clicks where it was focus the
+	// maximized one.
+	click(image.Point{15, 1})
+	if got, want := c.FocusedArea(), image.Rect(0, 0, 20, 4); got != want {
+		t.Errorf("FocusedArea => %v, want %v", got, want)
+	}
+
+	c.ToggleMaximize()
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if _, ok := c.Maximized(); ok {
+		t.Errorf("Maximized => got true, want false after the layout was restored")
+	}
+	if got, want := c.FocusedArea(), image.Rect(0, 0, 10, 4); got != want {
+		t.Errorf("FocusedArea => %v, want %v", got, want)
+	}
+}
+
+func TestMaximizeMovesFocus(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{20, 4})
+	c, err := New(
+		ft,
+		SplitVertical(
+			Left(ID("left")),
+			Right(ID("right")),
+		),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := c.Maximize("right"); err != nil {
+		t.Fatalf("Maximize => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if got, want := c.FocusedArea(), image.Rect(0, 0, 20, 4); got != want {
+		t.Errorf("FocusedArea => %v, want %v", got, want)
+	}
+}
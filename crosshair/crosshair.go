@@ -0,0 +1,142 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crosshair links the inspection crosshairs of charts.
+//
+// Charts that share a Link display their crosshairs at the same position on
+// the X axis. When the user moves the crosshair on one of the charts, the
+// chart publishes the position, e.g. the timestamp of the value under the
+// mouse pointer, and all the other charts highlight the same position:
+//
+//	link := crosshair.NewLink()
+//	cpu, err := linechart.New(linechart.CrosshairLink(link))
+//	...
+//	mem, err := linechart.New(linechart.CrosshairLink(link))
+//
+// Application code can subscribe to the link to learn about the position,
+// e.g. to display the values at the timestamp in a text widget.
+package crosshair
+
+import (
+	"sort"
+	"sync"
+)
+
+// SubscriberFn is called with the position of the crosshair each time it
+// changes. The ok argument is false when the crosshair was removed.
+//
+// The function must be thread-safe as the widgets publish the position from
+// the goroutines that process mouse events.
+type SubscriberFn func(pos float64, ok bool)
+
+// Link shares the position of the crosshair between charts.
+// This object is thread-safe.
+type Link struct {
+	// mu protects the link.
+	mu sync.Mutex
+
+	// pos is the position of the crosshair, valid only if source isn't nil.
+	pos float64
+	// source is the chart that published the position or nil if there is
+	// no crosshair.
+	source interface{}
+
+	// subscribers are called when the position changes, keyed by the
+	// subscription IDs.
+	subscribers map[int]SubscriberFn
+	// nextID is the ID of the next subscription.
+	nextID int
+}
+
+// NewLink returns a new link without a crosshair.
+func NewLink() *Link {
+	return &Link{
+		subscribers: map[int]SubscriberFn{},
+	}
+}
+
+// Position returns the position of the crosshair. Returns false if there is
+// no crosshair.
+func (l *Link) Position() (float64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.pos, l.source != nil
+}
+
+// Set moves the crosshair to the position. The source identifies the chart
+// that published the position, usually the chart itself, see Clear.
+func (l *Link) Set(source interface{}, pos float64) {
+	l.mu.Lock()
+	changed := l.source == nil || l.pos != pos
+	l.source = source
+	l.pos = pos
+	l.mu.Unlock()
+
+	if changed {
+		l.notify(pos, true)
+	}
+}
+
+// Clear removes the crosshair if it was last set by the source. This way,
+// a chart the mouse pointer left doesn't remove the crosshair that the
+// pointer is moving on another chart.
+func (l *Link) Clear(source interface{}) {
+	l.mu.Lock()
+	if l.source == nil || l.source != source {
+		l.mu.Unlock()
+		return
+	}
+	l.source = nil
+	l.pos = 0
+	l.mu.Unlock()
+
+	l.notify(0, false)
+}
+
+// Subscribe registers a function that is called each time the position of
+// the crosshair changes.
+// Returns a function that cancels the subscription.
+func (l *Link) Subscribe(fn SubscriberFn) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id := l.nextID
+	l.nextID++
+	l.subscribers[id] = fn
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.subscribers, id)
+	}
+}
+
+// notify calls the subscribers in the order they subscribed.
+func (l *Link) notify(pos float64, ok bool) {
+	l.mu.Lock()
+	var ids []int
+	for id := range l.subscribers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	var fns []SubscriberFn
+	for _, id := range ids {
+		fns = append(fns, l.subscribers[id])
+	}
+	l.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(pos, ok)
+	}
+}
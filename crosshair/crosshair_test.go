@@ -0,0 +1,117 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crosshair
+
+import (
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// event is a call of a subscriber.
+type event struct {
+	pos float64
+	ok  bool
+}
+
+func TestLink(t *testing.T) {
+	tests := []struct {
+		desc       string
+		actions    func(l *Link)
+		wantPos    float64
+		wantOK     bool
+		wantEvents []event
+	}{
+		{
+			desc:    "no crosshair initially",
+			actions: func(l *Link) {},
+		},
+		{
+			desc: "set publishes the position",
+			actions: func(l *Link) {
+				l.Set("a", 1)
+				l.Set("b", 2)
+			},
+			wantPos:    2,
+			wantOK:     true,
+			wantEvents: []event{{1, true}, {2, true}},
+		},
+		{
+			desc: "setting the same position doesn't notify",
+			actions: func(l *Link) {
+				l.Set("a", 1)
+				l.Set("b", 1)
+			},
+			wantPos:    1,
+			wantOK:     true,
+			wantEvents: []event{{1, true}},
+		},
+		{
+			desc: "clear by the source removes the crosshair",
+			actions: func(l *Link) {
+				l.Set("a", 1)
+				l.Clear("a")
+			},
+			wantEvents: []event{{1, true}, {0, false}},
+		},
+		{
+			desc: "clear by another source is ignored",
+			actions: func(l *Link) {
+				l.Set("a", 1)
+				l.Clear("b")
+			},
+			wantPos:    1,
+			wantOK:     true,
+			wantEvents: []event{{1, true}},
+		},
+		{
+			desc: "clear without crosshair is ignored",
+			actions: func(l *Link) {
+				l.Clear("a")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			l := NewLink()
+			var got []event
+			l.Subscribe(func(pos float64, ok bool) {
+				got = append(got, event{pos, ok})
+			})
+			tc.actions(l)
+
+			pos, ok := l.Position()
+			if pos != tc.wantPos || ok != tc.wantOK {
+				t.Errorf("Position => %v, %v, want %v, %v", pos, ok, tc.wantPos, tc.wantOK)
+			}
+			if diff := pretty.Compare(tc.wantEvents, got); diff != "" {
+				t.Errorf("SubscriberFn => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	l := NewLink()
+	var calls int
+	unsubscribe := l.Subscribe(func(float64, bool) { calls++ })
+	l.Set("a", 1)
+	unsubscribe()
+	l.Set("a", 2)
+	if calls != 1 {
+		t.Errorf("SubscriberFn called %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termdash
+
+// dialog.go contains modal dialogs that ask the user for a confirmation or
+// a line of text.
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// dialog is a modal dialog displayed over the dashboard.
+type dialog struct {
+	// msg is the message displayed to the user.
+	msg string
+	// prompt indicates that the dialog asks for a line of text instead of a
+	// confirmation.
+	prompt bool
+	// input is the text entered so far.
+	input []rune
+
+	// confirmCh and promptCh receive the answer of the dialog, depending on
+	// its kind. They are closed once the dialog is dismissed.
+	confirmCh chan bool
+	promptCh  chan string
+
+	// done is closed once the dialog is dismissed.
+	done chan struct{}
+}
+
+// title returns the title displayed in the border of the dialog.
+func (d *dialog) title() string {
+	if d.prompt {
+		return "Input"
+	}
+	return "Confirm"
+}
+
+// lines returns the lines displayed in the dialog.
+func (d *dialog) lines() []string {
+	lines := strings.Split(d.msg, "\n")
+	if d.prompt {
+		return append(lines, "", "> "+string(d.input))
+	}
+	return append(lines, "", "[y]es / [n]o")
+}
+
+// close closes the channels of the dialog.
+func (d *dialog) close() {
+	if d.prompt {
+		close(d.promptCh)
+	} else {
+		close(d.confirmCh)
+	}
+	close(d.done)
+}
+
+// openDialog queues the dialog behind the already displayed ones and
+// redraws the terminal. The dialog is dismissed without an answer when the
+// context expires.
+func (td *termdash) openDialog(ctx context.Context, d *dialog) {
+	d.done = make(chan struct{})
+	td.mu.Lock()
+	td.dialogs = append(td.dialogs, d)
+	if err := td.redraw(); err != nil {
+		td.handleError(err)
+	}
+	td.mu.Unlock()
+
+	go func() {
+		select {
+		case <-d.done:
+		case <-ctx.Done():
+			td.mu.Lock()
+			defer td.mu.Unlock()
+			if td.dismiss(d) {
+				d.close()
+			}
+		case <-td.closeCh:
+			// The terminal is no longer drawn on.
+			td.mu.Lock()
+			defer td.mu.Unlock()
+			if td.remove(d) {
+				d.close()
+			}
+		}
+	}()
+}
+
+// remove removes the dialog from the requested dialogs. Returns false if
+// the dialog was already removed.
+// The caller must hold td.mu.
+func (td *termdash) remove(d *dialog) bool {
+	for i, other := range td.dialogs {
+		if other == d {
+			td.dialogs = append(td.dialogs[:i], td.dialogs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// dismiss removes the dialog and redraws the terminal. Returns false if the
+// dialog was already dismissed.
+// The caller must hold td.mu.
+func (td *termdash) dismiss(d *dialog) bool {
+	if !td.remove(d) {
+		return false
+	}
+	// Remove the dialog from the terminal.
+	td.clearNeeded = true
+	if err := td.redraw(); err != nil {
+		td.handleError(err)
+	}
+	return true
+}
+
+// answer dismisses the dialog and sends the answer on its channel.
+// The caller must hold td.mu.
+func (td *termdash) answer(d *dialog, confirmed bool) {
+	if !td.dismiss(d) {
+		return
+	}
+	if d.prompt {
+		if confirmed {
+			d.promptCh <- string(d.input)
+		}
+	} else {
+		d.confirmCh <- confirmed
+	}
+	d.close()
+}
+
+// dialogInput processes the input event while a dialog is displayed.
+// Returns false if no dialog is displayed and the event should be processed
+// as usual.
+func (td *termdash) dialogInput(ev terminalapi.Event) bool {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+
+	if len(td.dialogs) == 0 {
+		return false
+	}
+	d := td.dialogs[0]
+
+	switch e := ev.(type) {
+	case *terminalapi.Keyboard:
+		if !d.prompt {
+			switch e.Key {
+			case 'y', 'Y', keyboard.KeyEnter:
+				td.answer(d, true)
+			case 'n', 'N', keyboard.KeyEsc:
+				td.answer(d, false)
+			}
+			return true
+		}
+
+		switch {
+		case e.Key == keyboard.KeyEnter:
+			td.answer(d, true)
+			return true
+		case e.Key == keyboard.KeyEsc:
+			td.answer(d, false)
+			return true
+		case e.Key == keyboard.KeyBackspace || e.Key == keyboard.KeyBackspace2:
+			if len(d.input) > 0 {
+				d.input = d.input[:len(d.input)-1]
+			}
+		case e.Key > 0 && unicode.IsPrint(rune(e.Key)):
+			d.input = append(d.input, rune(e.Key))
+		default:
+			return true
+		}
+
+	case *terminalapi.Paste:
+		if !d.prompt {
+			return true
+		}
+		for _, r := range e.Text {
+			if unicode.IsPrint(r) {
+				d.input = append(d.input, r)
+			}
+		}
+
+	case *terminalapi.Commit:
+		if !d.prompt {
+			return true
+		}
+		for _, r := range e.Text {
+			if unicode.IsPrint(r) {
+				d.input = append(d.input, r)
+			}
+		}
+
+	default:
+		// Other input is ignored while a dialog is displayed.
+		return true
+	}
+
+	if err := td.redraw(); err != nil {
+		td.handleError(err)
+	}
+	return true
+}
+
+// Confirm displays a modal dialog with the message that asks the user to
+// confirm it with the 'y' or Enter keys or to decline it with the 'n' or
+// Escape keys. The answer is sent on the returned channel, which is closed
+// afterwards. While the dialog is displayed, it receives all the keyboard
+// input and other input is ignored. Dialogs requested while another one is
+// displayed are displayed after it in the order they were requested.
+//
+// The dialog is dismissed and the channel closed without an answer if the
+// context expires or the controller is closed first. The message can
+// contain multiple lines separated by "\n".
+//
+// Confirm doesn't block, so a flow that needs the answer can wait for it on
+// the channel, e.g. from a goroutine started by a key binding.
+func (c *Controller) Confirm(ctx context.Context, msg string) <-chan bool {
+	ch := make(chan bool, 1)
+	if c.td == nil {
+		close(ch)
+		return ch
+	}
+	c.td.openDialog(ctx, &dialog{
+		msg:       msg,
+		confirmCh: ch,
+	})
+	return ch
+}
+
+// Prompt displays a modal dialog with the message that asks the user to
+// enter a line of text. Enter sends the text on the returned channel, which
+// is closed afterwards. Escape closes the channel without sending the
+// text. Otherwise behaves like Confirm.
+func (c *Controller) Prompt(ctx context.Context, msg string) <-chan string {
+	ch := make(chan string, 1)
+	if c.td == nil {
+		close(ch)
+		return ch
+	}
+	c.td.openDialog(ctx, &dialog{
+		msg:      msg,
+		prompt:   true,
+		promptCh: ch,
+	})
+	return ch
+}
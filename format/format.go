@@ -0,0 +1,367 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package format implements formatting of numbers for the labels and the
+// values displayed by widgets, e.g. byte sizes with SI or IEC units,
+// durations, percentages or currencies:
+//
+//	f := format.IEC("B")
+//	f(1536) // "1.5KiB"
+//
+// The separators of the numbers and the layouts of times follow the locale
+// set by SetLocale, individual formatters can use other locales, see
+// UseLocale.
+//
+// A Formatter is a func(float64) string, so the same formatter can be
+// provided to all the widgets that display numbers, e.g. to
+// gauge.ValueFormatter, barchart.ValueFormatter,
+// linechart.YAxisFormattedValues, candlestick.Formatter,
+// heatmap.ValueFormatter, leaderboard.ValueFormatter or StatusBar.SetValue,
+// and all the numbers on the dashboard format consistently.
+package format
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formatter formats the value into text. Formatters return an empty string
+// for NaN values.
+type Formatter func(v float64) string
+
+// Option is used to provide options to the formatters.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options stores the provided options.
+type options struct {
+	// digits is the number of significant digits.
+	digits int
+	// decimals is the fixed number of decimal places, negative when the
+	// numbers are rounded to significant digits instead.
+	decimals int
+	// grouping indicates if the thousands are separated.
+	grouping bool
+	// locale is the locale provided via UseLocale, nil to use the current
+	// locale.
+	locale *Locale
+}
+
+// newOptions returns options with the default values set.
+func newOptions(defaults []Option, opts []Option) *options {
+	o := &options{
+		digits:   DefaultDigits,
+		decimals: -1,
+	}
+	for _, opt := range defaults {
+		opt.set(o)
+	}
+	for _, opt := range opts {
+		opt.set(o)
+	}
+	return o
+}
+
+// DefaultDigits is the default value for the Digits option.
+const DefaultDigits = 3
+
+// Digits rounds the numbers to the specified number of significant digits,
+// trailing zeros after the decimal point are omitted. Values smaller than
+// one are treated as one.
+// This is the default with DefaultDigits for all the formatters except
+// Currency.
+func Digits(n int) Option {
+	return option(func(opts *options) {
+		if n < 1 {
+			n = 1
+		}
+		opts.digits = n
+		opts.decimals = -1
+	})
+}
+
+// Decimals rounds the numbers to the specified number of decimal places and
+// always displays all of them, e.g. "1.50". Negative values are treated as
+// zero.
+// This is the default for the Currency formatter, which has two decimal
+// places.
+func Decimals(n int) Option {
+	return option(func(opts *options) {
+		if n < 0 {
+			n = 0
+		}
+		opts.decimals = n
+	})
+}
+
+// Grouping separates the thousands in the integer part of the numbers, e.g.
+// "1,234,567", with the separator of the locale. The Currency formatter
+// groups the thousands by default.
+func Grouping(enabled bool) Option {
+	return option(func(opts *options) {
+		opts.grouping = enabled
+	})
+}
+
+// localeOrCurrent returns the locale to format with.
+func (o *options) localeOrCurrent() Locale {
+	if o.locale != nil {
+		return *o.locale
+	}
+	return CurrentLocale()
+}
+
+// round rounds the value according to the options.
+func (o *options) round(v float64) float64 {
+	if v == 0 || math.IsInf(v, 0) {
+		return v
+	}
+	if o.decimals >= 0 {
+		p := math.Pow10(o.decimals)
+		return math.Round(v*p) / p
+	}
+	exp := int(math.Floor(math.Log10(math.Abs(v))))
+	p := math.Pow10(o.digits - 1 - exp)
+	return math.Round(v*p) / p
+}
+
+// format formats the rounded value according to the options.
+func (o *options) format(v float64) string {
+	if math.IsInf(v, 1) {
+		return "Inf"
+	}
+	if math.IsInf(v, -1) {
+		return "-Inf"
+	}
+	if v == 0 {
+		// Avoids formatting negative zero as "-0".
+		v = 0
+	}
+
+	var s string
+	if o.decimals >= 0 {
+		s = strconv.FormatFloat(v, 'f', o.decimals, 64)
+	} else {
+		var dec int
+		if v != 0 {
+			exp := int(math.Floor(math.Log10(math.Abs(v))))
+			dec = o.digits - 1 - exp
+		}
+		if dec < 0 {
+			dec = 0
+		}
+		s = strconv.FormatFloat(v, 'f', dec, 64)
+		if strings.Contains(s, ".") {
+			s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+		}
+	}
+	return o.localize(s)
+}
+
+// localize replaces the separators in the formatted number with the ones of
+// the locale and separates the thousands if requested.
+func (o *options) localize(s string) string {
+	loc := o.localeOrCurrent()
+	var sign string
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		intPart, frac = s[:i], loc.Decimal+s[i+1:]
+	}
+	if !o.grouping {
+		return sign + intPart + frac
+	}
+
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(loc.Group)
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String() + frac
+}
+
+// unit is a unit of a scale, e.g. the kilo prefix or an hour.
+type unit struct {
+	// suffix is appended to the value.
+	suffix string
+	// size is the size of the unit in the base unit.
+	size float64
+}
+
+// scale formats the value in the largest of the units that is smaller than
+// the absolute value, the units must be sorted from the smallest. Values
+// smaller than the smallest unit use the smallest unit, except zero which
+// uses the base unit, i.e. the unit of size one, if there is one.
+func (o *options) scale(v float64, units []unit) string {
+	i := 0
+	abs := math.Abs(v)
+	for j, u := range units {
+		if abs >= u.size || (v == 0 && u.size == 1) {
+			i = j
+		}
+	}
+
+	for {
+		rounded := o.round(v / units[i].size)
+		// Rounding can carry the value over to the next unit, e.g. 999.9
+		// rounded to 3 digits is 1000.
+		if i < len(units)-1 && math.Abs(rounded)*units[i].size >= units[i+1].size {
+			v = rounded * units[i].size
+			i++
+			continue
+		}
+		return o.format(rounded) + units[i].suffix
+	}
+}
+
+// siUnits are the SI prefixes.
+var siUnits = []unit{
+	{"p", 1e-12},
+	{"n", 1e-9},
+	{"µ", 1e-6},
+	{"m", 1e-3},
+	{"", 1},
+	{"k", 1e3},
+	{"M", 1e6},
+	{"G", 1e9},
+	{"T", 1e12},
+	{"P", 1e15},
+	{"E", 1e18},
+}
+
+// SI returns a formatter that formats the values with the SI prefixes and
+// the unit, e.g. "1.5kB" for 1500 with unit "B" or "250ms" for 0.25 with
+// unit "s". The unit can be empty.
+func SI(u string, opts ...Option) Formatter {
+	o := newOptions(nil, opts)
+	return func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return o.scale(v, siUnits) + u
+	}
+}
+
+// iecUnits are the IEC binary prefixes.
+var iecUnits = []unit{
+	{"", 1},
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"Ti", 1 << 40},
+	{"Pi", 1 << 50},
+	{"Ei", 1 << 60},
+}
+
+// IEC returns a formatter that formats the values with the IEC binary
+// prefixes, i.e. multiples of 1024, and the unit, e.g. "1.5KiB" for 1536
+// with unit "B". The unit can be empty.
+func IEC(u string, opts ...Option) Formatter {
+	o := newOptions(nil, opts)
+	return func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return o.scale(v, iecUnits) + u
+	}
+}
+
+// durationUnits are the units of durations.
+var durationUnits = []unit{
+	{"ns", float64(time.Nanosecond)},
+	{"µs", float64(time.Microsecond)},
+	{"ms", float64(time.Millisecond)},
+	{"s", float64(time.Second)},
+	{"m", float64(time.Minute)},
+	{"h", float64(time.Hour)},
+	{"d", float64(24 * time.Hour)},
+}
+
+// Duration returns a formatter that formats the values as durations in the
+// largest fitting unit, e.g. "1.5h". The values are in the provided unit,
+// e.g. time.Second if the values are seconds.
+func Duration(u time.Duration, opts ...Option) Formatter {
+	o := newOptions(nil, opts)
+	return func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		if v == 0 {
+			return o.format(0) + "s"
+		}
+		return o.scale(v*float64(u), durationUnits)
+	}
+}
+
+// Percent returns a formatter that formats the values as percentages, e.g.
+// "42.5%". The values are in percent, i.e. 100 is displayed as "100%".
+func Percent(opts ...Option) Formatter {
+	o := newOptions(nil, opts)
+	return func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return o.format(o.round(v)) + "%"
+	}
+}
+
+// Currency returns a formatter that formats the values as amounts of money
+// in the currency with the symbol, e.g. "$1,234.50" or "-$3.00". The symbol
+// follows the amount in locales with Locale.CurrencyAfter, e.g. "1.234,50 €".
+// Defaults to two decimal places with the thousands grouped.
+func Currency(symbol string, opts ...Option) Formatter {
+	o := newOptions([]Option{Decimals(2), Grouping(true)}, opts)
+	return func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		s := o.format(o.round(v))
+		if o.localeOrCurrent().CurrencyAfter {
+			return s + " " + symbol
+		}
+		if strings.HasPrefix(s, "-") {
+			return "-" + symbol + s[1:]
+		}
+		return symbol + s
+	}
+}
+
+// Number returns a formatter that formats the values as plain numbers, by
+// default rounded to DefaultDigits significant digits, e.g. "1230" or
+// "0.00123".
+func Number(opts ...Option) Formatter {
+	o := newOptions(nil, opts)
+	return func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return o.format(o.round(v))
+	}
+}
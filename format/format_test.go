@@ -0,0 +1,243 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFormatters(t *testing.T) {
+	tests := []struct {
+		desc  string
+		f     Formatter
+		value float64
+		want  string
+	}{
+		{
+			desc:  "NaN",
+			f:     SI("B"),
+			value: math.NaN(),
+			want:  "",
+		},
+		{
+			desc:  "infinity",
+			f:     Number(),
+			value: math.Inf(-1),
+			want:  "-Inf",
+		},
+		{
+			desc:  "SI zero",
+			f:     SI("B"),
+			value: 0,
+			want:  "0B",
+		},
+		{
+			desc:  "SI without a prefix",
+			f:     SI("B"),
+			value: 999,
+			want:  "999B",
+		},
+		{
+			desc:  "SI kilo",
+			f:     SI("B"),
+			value: 1500,
+			want:  "1.5kB",
+		},
+		{
+			desc:  "SI giga with more digits",
+			f:     SI("", Digits(5)),
+			value: 1234567890,
+			want:  "1.2346G",
+		},
+		{
+			desc:  "SI rounding carries over to the next prefix",
+			f:     SI("B"),
+			value: 999999,
+			want:  "1MB",
+		},
+		{
+			desc:  "SI negative",
+			f:     SI("W"),
+			value: -2500,
+			want:  "-2.5kW",
+		},
+		{
+			desc:  "SI milli",
+			f:     SI("s"),
+			value: 0.25,
+			want:  "250ms",
+		},
+		{
+			desc:  "SI micro",
+			f:     SI("s"),
+			value: 0.0000015,
+			want:  "1.5µs",
+		},
+		{
+			desc:  "SI with decimals",
+			f:     SI("B", Decimals(2)),
+			value: 1500,
+			want:  "1.50kB",
+		},
+		{
+			desc:  "IEC without a prefix",
+			f:     IEC("B"),
+			value: 1000,
+			want:  "1000B",
+		},
+		{
+			desc:  "IEC kibi",
+			f:     IEC("B"),
+			value: 1536,
+			want:  "1.5KiB",
+		},
+		{
+			desc:  "IEC gibi",
+			f:     IEC("B"),
+			value: 3 << 30,
+			want:  "3GiB",
+		},
+		{
+			desc:  "IEC rounding carries over to the next prefix",
+			f:     IEC("B", Digits(4)),
+			value: 1023.7,
+			want:  "1KiB",
+		},
+		{
+			desc:  "duration zero",
+			f:     Duration(time.Second),
+			value: 0,
+			want:  "0s",
+		},
+		{
+			desc:  "duration in seconds",
+			f:     Duration(time.Second),
+			value: 42,
+			want:  "42s",
+		},
+		{
+			desc:  "duration in hours",
+			f:     Duration(time.Second),
+			value: 5400,
+			want:  "1.5h",
+		},
+		{
+			desc:  "duration in days",
+			f:     Duration(time.Hour),
+			value: 36,
+			want:  "1.5d",
+		},
+		{
+			desc:  "duration in milliseconds",
+			f:     Duration(time.Millisecond),
+			value: 2.5,
+			want:  "2.5ms",
+		},
+		{
+			desc:  "duration carries over to minutes",
+			f:     Duration(time.Second, Digits(2)),
+			value: 59.99,
+			want:  "1m",
+		},
+		{
+			desc:  "negative duration",
+			f:     Duration(time.Second),
+			value: -90,
+			want:  "-1.5m",
+		},
+		{
+			desc:  "percent",
+			f:     Percent(),
+			value: 42.54,
+			want:  "42.5%",
+		},
+		{
+			desc:  "percent with decimals",
+			f:     Percent(Decimals(0)),
+			value: 99.6,
+			want:  "100%",
+		},
+		{
+			desc:  "currency",
+			f:     Currency("$"),
+			value: 1234.5,
+			want:  "$1,234.50",
+		},
+		{
+			desc:  "negative currency",
+			f:     Currency("€"),
+			value: -1234567,
+			want:  "-€1,234,567.00",
+		},
+		{
+			desc:  "currency rounded to zero",
+			f:     Currency("$"),
+			value: -0.001,
+			want:  "$0.00",
+		},
+		{
+			desc:  "currency without grouping",
+			f:     Currency("$", Grouping(false), Decimals(0)),
+			value: 1234.5,
+			want:  "$1235",
+		},
+		{
+			desc:  "number with significant digits",
+			f:     Number(),
+			value: 1234,
+			want:  "1230",
+		},
+		{
+			desc:  "number smaller than one",
+			f:     Number(),
+			value: 0.0012345,
+			want:  "0.00123",
+		},
+		{
+			desc:  "number with one digit",
+			f:     Number(Digits(0)),
+			value: 0.56,
+			want:  "0.6",
+		},
+		{
+			desc:  "number with grouping",
+			f:     Number(Digits(7), Grouping(true)),
+			value: -1234567,
+			want:  "-1,234,567",
+		},
+		{
+			desc:  "number with grouping and decimals",
+			f:     Number(Decimals(1), Grouping(true)),
+			value: 123456.78,
+			want:  "123,456.8",
+		},
+		{
+			desc:  "number with negative decimals",
+			f:     Number(Decimals(-1)),
+			value: 1.5,
+			want:  "2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := tc.f(tc.value); got != tc.want {
+				t.Errorf("Formatter(%v) => %q, want %q", tc.value, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+// locale.go contains the locale dependent formatting.
+
+import (
+	"sync"
+	"time"
+)
+
+// Locale describes how numbers and times are formatted in a region.
+type Locale struct {
+	// Decimal separates the integer part of numbers from the decimal places.
+	Decimal string
+	// Group separates the thousands, see the Grouping option.
+	Group string
+	// CurrencyAfter indicates that the currency symbol follows the amount,
+	// e.g. "1.234,50 €".
+	CurrencyAfter bool
+
+	// Hour12 indicates that times are displayed with the 12-hour clock,
+	// e.g. "3:04PM", instead of the 24-hour clock, e.g. "15:04".
+	Hour12 bool
+	// DateLayout is the layout of dates as accepted by time.Format.
+	DateLayout string
+	// WeekStart is the first day of the week, e.g. in calendars.
+	WeekStart time.Weekday
+}
+
+// ClockLayout returns the layout of the time of day as accepted by
+// time.Format.
+func (l Locale) ClockLayout() string {
+	if l.Hour12 {
+		return "3:04PM"
+	}
+	return "15:04"
+}
+
+// DateTimeLayout returns the layout of the date followed by the time of day
+// as accepted by time.Format.
+func (l Locale) DateTimeLayout() string {
+	return l.DateLayout + " " + l.ClockLayout()
+}
+
+// Weekdays returns the days of the week in the order they are displayed,
+// i.e. starting with WeekStart.
+func (l Locale) Weekdays() []time.Weekday {
+	var days []time.Weekday
+	for i := 0; i < 7; i++ {
+		days = append(days, (l.WeekStart+time.Weekday(i))%7)
+	}
+	return days
+}
+
+// StartOfWeek returns the midnight at the start of the week that contains
+// the time, in the location of the time.
+func (l Locale) StartOfWeek(t time.Time) time.Time {
+	days := (int(t.Weekday()) - int(l.WeekStart) + 7) % 7
+	y, m, d := t.Date()
+	return time.Date(y, m, d-days, 0, 0, 0, 0, t.Location())
+}
+
+// Predefined locales.
+var (
+	// DefaultLocale is the locale used unless SetLocale is called. Formats
+	// numbers like EnUS, dates according to ISO 8601 with the 24-hour clock
+	// and the weeks start on Monday.
+	DefaultLocale = Locale{
+		Decimal:    ".",
+		Group:      ",",
+		DateLayout: "2006-01-02",
+		WeekStart:  time.Monday,
+	}
+
+	// EnUS is the locale of the United States.
+	EnUS = Locale{
+		Decimal:    ".",
+		Group:      ",",
+		Hour12:     true,
+		DateLayout: "01/02/2006",
+		WeekStart:  time.Sunday,
+	}
+
+	// EnGB is the locale of the United Kingdom.
+	EnGB = Locale{
+		Decimal:    ".",
+		Group:      ",",
+		DateLayout: "02/01/2006",
+		WeekStart:  time.Monday,
+	}
+
+	// DeDE is the locale of Germany.
+	DeDE = Locale{
+		Decimal:       ",",
+		Group:         ".",
+		CurrencyAfter: true,
+		DateLayout:    "02.01.2006",
+		WeekStart:     time.Monday,
+	}
+
+	// FrFR is the locale of France.
+	FrFR = Locale{
+		Decimal:       ",",
+		Group:         " ",
+		CurrencyAfter: true,
+		DateLayout:    "02/01/2006",
+		WeekStart:     time.Monday,
+	}
+
+	// JaJP is the locale of Japan.
+	JaJP = Locale{
+		Decimal:    ".",
+		Group:      ",",
+		DateLayout: "2006/01/02",
+		WeekStart:  time.Sunday,
+	}
+)
+
+var (
+	// localeMu protects locale.
+	localeMu sync.RWMutex
+	// locale is the locale set by SetLocale.
+	locale = DefaultLocale
+)
+
+// SetLocale sets the locale used by all the formatters that weren't
+// provided with the UseLocale option, including the formatters created
+// before the call.
+// This function is thread-safe.
+func SetLocale(l Locale) {
+	localeMu.Lock()
+	defer localeMu.Unlock()
+	locale = l
+}
+
+// CurrentLocale returns the locale set by SetLocale, DefaultLocale if it
+// wasn't called.
+// This function is thread-safe.
+func CurrentLocale() Locale {
+	localeMu.RLock()
+	defer localeMu.RUnlock()
+	return locale
+}
+
+// UseLocale makes the formatter use the provided locale instead of the one
+// set by SetLocale, e.g. to format the numbers of one widget differently.
+func UseLocale(l Locale) Option {
+	return option(func(opts *options) {
+		opts.locale = &l
+	})
+}
+
+// TimeFormatter formats the time into text.
+type TimeFormatter func(t time.Time) string
+
+// timeFormatter returns a formatter that formats the times with the layout
+// of the locale.
+func timeFormatter(layout func(Locale) string, opts []Option) TimeFormatter {
+	o := newOptions(nil, opts)
+	return func(t time.Time) string {
+		return t.Format(layout(o.localeOrCurrent()))
+	}
+}
+
+// Clock returns a formatter that formats the time of day, e.g. "15:04" or
+// "3:04PM" depending on the locale.
+func Clock(opts ...Option) TimeFormatter {
+	return timeFormatter(Locale.ClockLayout, opts)
+}
+
+// Date returns a formatter that formats the date according to the locale,
+// e.g. "2006-01-02" or "01/02/2006".
+func Date(opts ...Option) TimeFormatter {
+	return timeFormatter(func(l Locale) string { return l.DateLayout }, opts)
+}
+
+// DateTime returns a formatter that formats the date and the time of day
+// according to the locale, e.g. "2006-01-02 15:04".
+func DateTime(opts ...Option) TimeFormatter {
+	return timeFormatter(Locale.DateTimeLayout, opts)
+}
@@ -0,0 +1,202 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestLocaleFormatters(t *testing.T) {
+	tests := []struct {
+		desc  string
+		f     Formatter
+		value float64
+		want  string
+	}{
+		{
+			desc:  "decimal separator",
+			f:     SI("B", UseLocale(DeDE)),
+			value: 1500,
+			want:  "1,5kB",
+		},
+		{
+			desc:  "group separator",
+			f:     Number(Digits(7), Grouping(true), UseLocale(DeDE)),
+			value: 1234567.5,
+			want:  "1.234.568",
+		},
+		{
+			desc:  "group and decimal separators",
+			f:     Number(Decimals(2), Grouping(true), UseLocale(FrFR)),
+			value: -1234.5,
+			want:  "-1 234,50",
+		},
+		{
+			desc:  "currency symbol after the amount",
+			f:     Currency("€", UseLocale(DeDE)),
+			value: -1234.5,
+			want:  "-1.234,50 €",
+		},
+		{
+			desc:  "currency symbol before the amount",
+			f:     Currency("£", UseLocale(EnGB)),
+			value: 1234.5,
+			want:  "£1,234.50",
+		},
+		{
+			desc:  "percent",
+			f:     Percent(UseLocale(FrFR)),
+			value: 42.54,
+			want:  "42,5%",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := tc.f(tc.value); got != tc.want {
+				t.Errorf("Formatter(%v) => %q, want %q", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetLocale(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+
+	f := Currency("€")
+	clock := Clock()
+	own := Number(UseLocale(EnUS))
+	ts := time.Date(2020, 3, 4, 15, 4, 0, 0, time.UTC)
+
+	if got, want := CurrentLocale(), DefaultLocale; got != want {
+		t.Errorf("CurrentLocale => %v, want %v", got, want)
+	}
+	if got, want := f(1234.5), "€1,234.50"; got != want {
+		t.Errorf("Currency => %q, want %q", got, want)
+	}
+	if got, want := clock(ts), "15:04"; got != want {
+		t.Errorf("Clock => %q, want %q", got, want)
+	}
+
+	SetLocale(DeDE)
+	if got, want := f(1234.5), "1.234,50 €"; got != want {
+		t.Errorf("Currency after SetLocale => %q, want %q", got, want)
+	}
+	if got, want := own(1.5), "1.5"; got != want {
+		t.Errorf("Number with UseLocale after SetLocale => %q, want %q", got, want)
+	}
+
+	SetLocale(EnUS)
+	if got, want := clock(ts), "3:04PM"; got != want {
+		t.Errorf("Clock after SetLocale => %q, want %q", got, want)
+	}
+}
+
+func TestTimeFormatters(t *testing.T) {
+	ts := time.Date(2020, 3, 4, 15, 4, 0, 0, time.UTC)
+	tests := []struct {
+		desc string
+		f    TimeFormatter
+		want string
+	}{
+		{
+			desc: "24-hour clock",
+			f:    Clock(UseLocale(EnGB)),
+			want: "15:04",
+		},
+		{
+			desc: "12-hour clock",
+			f:    Clock(UseLocale(EnUS)),
+			want: "3:04PM",
+		},
+		{
+			desc: "date",
+			f:    Date(UseLocale(DeDE)),
+			want: "04.03.2020",
+		},
+		{
+			desc: "date and time",
+			f:    DateTime(UseLocale(EnUS)),
+			want: "03/04/2020 3:04PM",
+		},
+		{
+			desc: "date and time in the default locale",
+			f:    DateTime(UseLocale(DefaultLocale)),
+			want: "2020-03-04 15:04",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := tc.f(ts); got != tc.want {
+				t.Errorf("TimeFormatter => %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWeeks(t *testing.T) {
+	tests := []struct {
+		desc          string
+		locale        Locale
+		time          time.Time
+		wantWeekdays  []time.Weekday
+		wantWeekStart time.Time
+	}{
+		{
+			desc:   "week starts on Monday",
+			locale: DeDE,
+			// Sunday.
+			time: time.Date(2020, 3, 8, 15, 4, 0, 0, time.UTC),
+			wantWeekdays: []time.Weekday{
+				time.Monday, time.Tuesday, time.Wednesday, time.Thursday,
+				time.Friday, time.Saturday, time.Sunday,
+			},
+			wantWeekStart: time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:   "week starts on Sunday",
+			locale: EnUS,
+			// Sunday.
+			time: time.Date(2020, 3, 8, 15, 4, 0, 0, time.UTC),
+			wantWeekdays: []time.Weekday{
+				time.Sunday, time.Monday, time.Tuesday, time.Wednesday,
+				time.Thursday, time.Friday, time.Saturday,
+			},
+			wantWeekStart: time.Date(2020, 3, 8, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:          "week across months",
+			locale:        EnUS,
+			time:          time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC),
+			wantWeekdays:  EnUS.Weekdays(),
+			wantWeekStart: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if diff := pretty.Compare(tc.wantWeekdays, tc.locale.Weekdays()); diff != "" {
+				t.Errorf("Weekdays => unexpected diff (-want, +got):\n%s", diff)
+			}
+			if got := tc.locale.StartOfWeek(tc.time); !got.Equal(tc.wantWeekStart) {
+				t.Errorf("StartOfWeek => %v, want %v", got, tc.wantWeekStart)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termdash
+
+// freeze.go contains code that freezes and resumes the data updates.
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/freeze"
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// FreezeKey binds the key sequence to a function that freezes or resumes the
+// data updates the application routes through the gate, see the freeze
+// package. While the gate is frozen, termdash displays an indicator with the
+// number of buffered updates in the top right corner of the terminal.
+// The sequence is bound in the registry provided via the KeyBindings option,
+// which is required. When the gate resumes with the freeze.PolicyCatchUp, the
+// buffered updates run in the goroutine that processes the input events.
+func FreezeKey(g *freeze.Gate, seq keybinding.Sequence) Option {
+	return option(func(td *termdash) {
+		td.freeze = g
+		td.freezeKey = seq
+	})
+}
+
+// bindFreezeKey binds the FreezeKey sequence in the key bindings.
+func (td *termdash) bindFreezeKey() error {
+	if td.freezeKey == nil {
+		return nil
+	}
+	if td.freeze == nil {
+		return errors.New("the gate of the FreezeKey cannot be nil")
+	}
+	if err := td.keyBindings.Bind(td.freezeKey, td.toggleFreeze, keybinding.Description("Freeze or resume the data updates")); err != nil {
+		return fmt.Errorf("invalid FreezeKey: %v", err)
+	}
+	return nil
+}
+
+// toggleFreeze freezes or resumes the gate and redraws the terminal.
+func (td *termdash) toggleFreeze() error {
+	// Resuming might apply the buffered updates, which lock the widgets.
+	// Don't hold td.mu meanwhile, the widgets might be drawn concurrently.
+	td.freeze.Toggle()
+
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	if !td.freeze.Frozen() {
+		// Remove the indicator from the terminal.
+		td.clearNeeded = true
+	}
+	return td.redraw()
+}
+
+// freezeIndicator returns the text of the indicator displayed while the gate
+// is frozen.
+func freezeIndicator(buffered, dropped int) string {
+	s := fmt.Sprintf(" FROZEN, %d buffered", buffered)
+	if dropped > 0 {
+		s += fmt.Sprintf(", %d dropped", dropped)
+	}
+	return s + " "
+}
+
+// drawFreeze draws the indicator onto the top right corner of the terminal
+// if the gate is frozen. The indicator isn't drawn if it doesn't fit onto
+// the terminal.
+func drawFreeze(t terminalapi.Terminal, g *freeze.Gate) error {
+	if g == nil || !g.Frozen() {
+		return nil
+	}
+
+	text := freezeIndicator(g.Pending())
+	width := runewidth.StringWidth(text)
+	term := t.Size()
+	if width > term.X || term.Y < 1 {
+		return nil
+	}
+	cvs, err := canvas.New(image.Rect(term.X-width, 0, term.X, 1))
+	if err != nil {
+		return err
+	}
+	if err := draw.Text(cvs, text, image.Point{0, 0}, draw.TextCellOpts(
+		cell.FgColor(cell.ColorBlack),
+		cell.BgColor(cell.ColorYellow),
+	)); err != nil {
+		return err
+	}
+	return cvs.Apply(t)
+}
@@ -0,0 +1,293 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package freeze implements a gate that pauses the application of data
+// updates to the widgets, so that the operators can inspect the dashboard at
+// a moment in time while the data keeps arriving.
+//
+// The application routes its updates through the gate:
+//
+//	g.Apply(func() {
+//		if err := lc.Series("rps", values); err != nil {
+//			...
+//		}
+//	})
+//
+// The updates run immediately unless the gate is frozen, in which case they
+// are buffered until the gate resumes. See the termdash.FreezeKey option
+// that binds a key to freezing and resuming.
+package freeze
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Policy determines what happens with the buffered updates when the gate
+// resumes.
+type Policy int
+
+// String implements fmt.Stringer()
+func (p Policy) String() string {
+	if n, ok := policyNames[p]; ok {
+		return n
+	}
+	return "PolicyUnknown"
+}
+
+// policyNames maps Policy values to human readable names.
+var policyNames = map[Policy]string{
+	PolicyCatchUp: "PolicyCatchUp",
+	PolicyDiscard: "PolicyDiscard",
+}
+
+const (
+	// PolicyCatchUp applies the buffered updates in the order they were
+	// provided, so the widgets catch up with the data.
+	PolicyCatchUp Policy = iota
+
+	// PolicyDiscard drops the buffered updates, the widgets only display
+	// updates provided after the gate resumed.
+	PolicyDiscard
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	policy   Policy
+	limit    int
+	onChange func(frozen bool)
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if _, ok := policyNames[o.policy]; !ok {
+		return fmt.Errorf("unsupported ResumePolicy(%v)", o.policy)
+	}
+	if o.limit < 0 {
+		return fmt.Errorf("invalid Limit(%d), must be zero or a positive number", o.limit)
+	}
+	return nil
+}
+
+// ResumePolicy sets what happens with the buffered updates when the gate
+// resumes.
+// Defaults to PolicyCatchUp.
+func ResumePolicy(p Policy) Option {
+	return option(func(opts *options) {
+		opts.policy = p
+	})
+}
+
+// Limit sets the maximum number of buffered updates, the oldest updates are
+// dropped when the gate buffers more. Zero means no limit, which is the
+// default.
+func Limit(updates int) Option {
+	return option(func(opts *options) {
+		opts.limit = updates
+	})
+}
+
+// OnChange sets a function that is called each time the gate freezes or
+// resumes, e.g. to display the state on the dashboard. The function is
+// called without holding any locks of the gate, it must be thread-safe.
+func OnChange(fn func(frozen bool)) Option {
+	return option(func(opts *options) {
+		opts.onChange = fn
+	})
+}
+
+// update is a buffered update.
+type update struct {
+	// key identifies updates provided via ApplyLatest, empty for the others.
+	key string
+	fn  func()
+}
+
+// Gate applies or buffers the updates.
+// This object is thread-safe.
+type Gate struct {
+	// mu protects the Gate.
+	mu sync.Mutex
+
+	// frozen indicates that the gate is frozen.
+	frozen bool
+	// draining indicates that the buffered updates are being applied after
+	// the gate resumed. New updates are buffered behind them.
+	draining bool
+	// pending are the buffered updates, the oldest first.
+	pending []*update
+	// dropped is the number of updates dropped because of the Limit since
+	// the gate last froze.
+	dropped int
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new Gate that isn't frozen.
+func New(opts ...Option) (*Gate, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt.set(o)
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	return &Gate{opts: o}, nil
+}
+
+// Apply runs the update, or buffers it if the gate is frozen.
+// Buffered updates run in the goroutine that resumes the gate.
+func (g *Gate) Apply(fn func()) {
+	g.apply(&update{fn: fn})
+}
+
+// ApplyLatest is like Apply, but the gate only buffers the latest update
+// with the key, it replaces any buffered update with the same key. Useful
+// for updates that replace the content of a widget, e.g. all the values of
+// a series, where only the most recent one matters.
+func (g *Gate) ApplyLatest(key string, fn func()) {
+	g.apply(&update{key: key, fn: fn})
+}
+
+// apply implements Apply and ApplyLatest.
+func (g *Gate) apply(u *update) {
+	g.mu.Lock()
+	if !g.frozen && !g.draining {
+		g.mu.Unlock()
+		u.fn()
+		return
+	}
+	defer g.mu.Unlock()
+
+	if u.key != "" {
+		for i, p := range g.pending {
+			if p.key == u.key {
+				g.pending = append(g.pending[:i], g.pending[i+1:]...)
+				break
+			}
+		}
+	}
+	g.pending = append(g.pending, u)
+	if over := len(g.pending) - g.opts.limit; g.opts.limit > 0 && over > 0 {
+		g.pending = g.pending[over:]
+		g.dropped += over
+	}
+}
+
+// Freeze freezes the gate, the updates provided afterwards are buffered
+// until Resume is called. Has no effect if the gate is already frozen.
+func (g *Gate) Freeze() {
+	g.mu.Lock()
+	if g.frozen {
+		g.mu.Unlock()
+		return
+	}
+	g.frozen = true
+	g.dropped = 0
+	g.mu.Unlock()
+
+	if fn := g.opts.onChange; fn != nil {
+		fn(true)
+	}
+}
+
+// Resume resumes the gate. According to the ResumePolicy, the buffered
+// updates are either applied before Resume returns or dropped. Has no
+// effect if the gate isn't frozen.
+func (g *Gate) Resume() {
+	g.mu.Lock()
+	if !g.frozen {
+		g.mu.Unlock()
+		return
+	}
+	g.frozen = false
+	if g.opts.policy == PolicyDiscard {
+		g.pending = nil
+	}
+	drain := !g.draining
+	g.draining = drain
+	g.mu.Unlock()
+
+	if fn := g.opts.onChange; fn != nil {
+		fn(false)
+	}
+	if drain {
+		g.drain()
+	}
+}
+
+// drain applies the buffered updates until there are none or the gate is
+// frozen again. Updates provided meanwhile are buffered behind them, so all
+// the updates are applied in order.
+func (g *Gate) drain() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for !g.frozen && len(g.pending) > 0 {
+		pending := g.pending
+		g.pending = nil
+		g.mu.Unlock()
+		for i, u := range pending {
+			u.fn()
+			if g.Frozen() {
+				// Keep the rest for the next Resume.
+				g.mu.Lock()
+				rest := append([]*update{}, pending[i+1:]...)
+				g.pending = append(rest, g.pending...)
+				g.mu.Unlock()
+				break
+			}
+		}
+		g.mu.Lock()
+	}
+	g.draining = false
+}
+
+// Toggle freezes the gate if it isn't frozen and resumes it otherwise.
+func (g *Gate) Toggle() {
+	if g.Frozen() {
+		g.Resume()
+	} else {
+		g.Freeze()
+	}
+}
+
+// Frozen asserts whether the gate is frozen.
+func (g *Gate) Frozen() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.frozen
+}
+
+// Pending returns the number of buffered updates and the number of updates
+// dropped because of the Limit since the gate last froze.
+func (g *Gate) Pending() (buffered, dropped int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.pending), g.dropped
+}
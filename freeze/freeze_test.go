@@ -0,0 +1,226 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package freeze
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// action is performed on the gate in a test.
+type action func(g *Gate, record func(string) func())
+
+func apply(name string) action {
+	return func(g *Gate, record func(string) func()) {
+		g.Apply(record(name))
+	}
+}
+
+func applyLatest(key, name string) action {
+	return func(g *Gate, record func(string) func()) {
+		g.ApplyLatest(key, record(name))
+	}
+}
+
+func freeze(g *Gate, _ func(string) func()) { g.Freeze() }
+func resume(g *Gate, _ func(string) func()) { g.Resume() }
+func toggle(g *Gate, _ func(string) func()) { g.Toggle() }
+
+func TestGate(t *testing.T) {
+	tests := []struct {
+		desc        string
+		opts        []Option
+		actions     []action
+		want        []string
+		wantFrozen  bool
+		wantPending int
+		wantDropped int
+		wantErr     bool
+	}{
+		{
+			desc:    "fails on unsupported ResumePolicy",
+			opts:    []Option{ResumePolicy(Policy(-1))},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on negative Limit",
+			opts:    []Option{Limit(-1)},
+			wantErr: true,
+		},
+		{
+			desc:    "applies the updates immediately when not frozen",
+			actions: []action{apply("a"), apply("b")},
+			want:    []string{"a", "b"},
+		},
+		{
+			desc:        "buffers the updates while frozen",
+			actions:     []action{apply("a"), freeze, apply("b"), apply("c")},
+			want:        []string{"a"},
+			wantFrozen:  true,
+			wantPending: 2,
+		},
+		{
+			desc:    "catches up in order on resume by default",
+			actions: []action{freeze, apply("a"), apply("b"), resume, apply("c")},
+			want:    []string{"a", "b", "c"},
+		},
+		{
+			desc:    "discards the buffered updates with the policy",
+			opts:    []Option{ResumePolicy(PolicyDiscard)},
+			actions: []action{freeze, apply("a"), apply("b"), resume, apply("c")},
+			want:    []string{"c"},
+		},
+		{
+			desc:        "drops the oldest updates over the limit",
+			opts:        []Option{Limit(2)},
+			actions:     []action{freeze, apply("a"), apply("b"), apply("c")},
+			wantFrozen:  true,
+			wantPending: 2,
+			wantDropped: 1,
+		},
+		{
+			desc:        "applies the remaining updates over the limit",
+			opts:        []Option{Limit(2)},
+			actions:     []action{freeze, apply("a"), apply("b"), apply("c"), resume},
+			want:        []string{"b", "c"},
+			wantDropped: 1,
+		},
+		{
+			desc: "buffers only the latest update with the same key",
+			actions: []action{
+				freeze,
+				applyLatest("series", "series 1"),
+				apply("a"),
+				applyLatest("series", "series 2"),
+				applyLatest("other", "other"),
+				resume,
+			},
+			want: []string{"a", "series 2", "other"},
+		},
+		{
+			desc:    "applies updates with a key immediately when not frozen",
+			actions: []action{applyLatest("k", "1"), applyLatest("k", "2")},
+			want:    []string{"1", "2"},
+		},
+		{
+			desc:        "toggles",
+			actions:     []action{toggle, apply("a"), toggle, apply("b"), toggle, apply("c")},
+			want:        []string{"a", "b"},
+			wantFrozen:  true,
+			wantPending: 1,
+		},
+		{
+			desc:    "repeated freeze and resume have no effect",
+			actions: []action{freeze, freeze, apply("a"), resume, resume, apply("b")},
+			want:    []string{"a", "b"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g, err := New(tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var got []string
+			record := func(name string) func() {
+				return func() {
+					got = append(got, name)
+				}
+			}
+			for _, a := range tc.actions {
+				a(g, record)
+			}
+
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("applied updates => unexpected diff (-want, +got):\n%s", diff)
+			}
+			if got := g.Frozen(); got != tc.wantFrozen {
+				t.Errorf("Frozen => %v, want %v", got, tc.wantFrozen)
+			}
+			gotPending, gotDropped := g.Pending()
+			if gotPending != tc.wantPending || gotDropped != tc.wantDropped {
+				t.Errorf("Pending => %d, %d, want %d, %d", gotPending, gotDropped, tc.wantPending, tc.wantDropped)
+			}
+		})
+	}
+}
+
+func TestUpdatesDuringCatchUp(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	var got []string
+	g.Freeze()
+	g.Apply(func() {
+		got = append(got, "a")
+		// Updates provided while catching up wait for the buffered ones.
+		g.Apply(func() { got = append(got, "c") })
+	})
+	g.Apply(func() {
+		got = append(got, "b")
+	})
+	g.Resume()
+	if diff := pretty.Compare([]string{"a", "b", "c"}, got); diff != "" {
+		t.Errorf("applied updates => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	got = nil
+	g.Freeze()
+	for i := 0; i < 3; i++ {
+		i := i
+		g.Apply(func() {
+			got = append(got, fmt.Sprint(i))
+			if i == 0 {
+				// Freezing while catching up keeps the rest buffered.
+				g.Freeze()
+			}
+		})
+	}
+	g.Resume()
+	if pending, _ := g.Pending(); !g.Frozen() || pending != 2 {
+		t.Errorf("Frozen, Pending => %v, %d, want true, 2", g.Frozen(), pending)
+	}
+	g.Resume()
+	if diff := pretty.Compare([]string{"0", "1", "2"}, got); diff != "" {
+		t.Errorf("applied updates => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	var got []bool
+	g, err := New(OnChange(func(frozen bool) {
+		got = append(got, frozen)
+	}))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	g.Freeze()
+	g.Freeze()
+	g.Resume()
+	g.Resume()
+	g.Toggle()
+	if diff := pretty.Compare([]bool{true, false, true}, got); diff != "" {
+		t.Errorf("OnChange => unexpected calls (-want, +got):\n%s", diff)
+	}
+}
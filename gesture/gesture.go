@@ -0,0 +1,291 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gesture recognizes touch gestures in mouse events.
+//
+// Terminal emulators on touch screens report taps and drags as mouse events.
+// A Recognizer turns a press of the left button followed by a release into a
+// tap, a long press or a swipe. Containers run a Recognizer on all mouse
+// events and deliver the gestures to widgets that implement
+// widgetapi.GestureHandler.
+package gesture
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Kind is the kind of a gesture.
+type Kind int
+
+// String implements fmt.Stringer()
+func (k Kind) String() string {
+	if n, ok := kindNames[k]; ok {
+		return n
+	}
+	return "KindUnknown"
+}
+
+// kindNames maps Kind values to human readable names.
+var kindNames = map[Kind]string{
+	KindTap:       "KindTap",
+	KindLongPress: "KindLongPress",
+	KindSwipe:     "KindSwipe",
+}
+
+// Kinds of gestures.
+const (
+	kindUnknown Kind = iota
+
+	// KindTap is a short press and release at the same position.
+	KindTap
+
+	// KindLongPress is a press held for at least the LongPress duration and
+	// released at the same position.
+	KindLongPress
+
+	// KindSwipe is a press that moved by at least the SwipeDistance before
+	// it was released.
+	KindSwipe
+)
+
+// Direction is the direction of a swipe.
+type Direction int
+
+// String implements fmt.Stringer()
+func (d Direction) String() string {
+	if n, ok := directionNames[d]; ok {
+		return n
+	}
+	return "DirectionUnknown"
+}
+
+// directionNames maps Direction values to human readable names.
+var directionNames = map[Direction]string{
+	DirectionNone:  "DirectionNone",
+	DirectionUp:    "DirectionUp",
+	DirectionDown:  "DirectionDown",
+	DirectionLeft:  "DirectionLeft",
+	DirectionRight: "DirectionRight",
+}
+
+// Directions of swipes.
+const (
+	// DirectionNone is used for gestures other than swipes.
+	DirectionNone Direction = iota
+	DirectionUp
+	DirectionDown
+	DirectionLeft
+	DirectionRight
+)
+
+// Gesture is a recognized gesture.
+type Gesture struct {
+	// Kind is the kind of the gesture.
+	Kind Kind
+
+	// Position is where the gesture started.
+	Position image.Point
+
+	// Direction is the direction of a swipe, DirectionNone for other
+	// gestures.
+	Direction Direction
+
+	// Distance is the number of cells a swipe moved along its direction,
+	// zero for other gestures.
+	Distance int
+}
+
+// String implements fmt.Stringer()
+func (g *Gesture) String() string {
+	if g.Kind == KindSwipe {
+		return fmt.Sprintf("Gesture{%v, %v, %v, %d}", g.Kind, g.Position, g.Direction, g.Distance)
+	}
+	return fmt.Sprintf("Gesture{%v, %v}", g.Kind, g.Position)
+}
+
+// Option is used to provide options to NewRecognizer().
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// options stores the provided options.
+type options struct {
+	longPress     time.Duration
+	swipeDistance int
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.longPress <= 0 {
+		return fmt.Errorf("invalid LongPress(%v), must be a positive duration", o.longPress)
+	}
+	if o.swipeDistance < 1 {
+		return fmt.Errorf("invalid SwipeDistance(%d), must be a positive number", o.swipeDistance)
+	}
+	return nil
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// DefaultLongPress is the default value for the LongPress option.
+const DefaultLongPress = 500 * time.Millisecond
+
+// LongPress sets how long a press must be held to be recognized as a long
+// press instead of a tap.
+// Defaults to DefaultLongPress.
+func LongPress(d time.Duration) Option {
+	return option(func(opts *options) {
+		opts.longPress = d
+	})
+}
+
+// DefaultSwipeDistance is the default value for the SwipeDistance option.
+const DefaultSwipeDistance = 3
+
+// SwipeDistance sets the minimum number of cells a press must move to be
+// recognized as a swipe instead of a tap or a long press.
+// Defaults to DefaultSwipeDistance.
+func SwipeDistance(cells int) Option {
+	return option(func(opts *options) {
+		opts.swipeDistance = cells
+	})
+}
+
+// timeNow returns the current time.
+// Replaced from tests.
+var timeNow = time.Now
+
+// Recognizer recognizes gestures in a stream of mouse events.
+//
+// Terminals don't report anything while a press is held still, so long
+// presses are recognized when the press is released.
+//
+// This object isn't thread-safe.
+type Recognizer struct {
+	// pressed indicates that the left button is being held.
+	pressed bool
+	// start is the position and since the time of the press.
+	start image.Point
+	since time.Time
+
+	// opts are the provided options.
+	opts *options
+}
+
+// NewRecognizer returns a new Recognizer.
+func NewRecognizer(opts ...Option) (*Recognizer, error) {
+	opt := &options{
+		longPress:     DefaultLongPress,
+		swipeDistance: DefaultSwipeDistance,
+	}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Recognizer{
+		opts: opt,
+	}, nil
+}
+
+// Mouse processes the mouse event and returns the gesture it completed or
+// nil if it didn't complete one.
+func (r *Recognizer) Mouse(m *terminalapi.Mouse) *Gesture {
+	switch m.Button {
+	case mouse.ButtonLeft:
+		if !r.pressed {
+			// Further ButtonLeft events are the drag of the press.
+			r.pressed = true
+			r.start = m.Position
+			r.since = timeNow()
+		}
+		return nil
+
+	case mouse.ButtonRelease:
+		if !r.pressed {
+			return nil
+		}
+		r.pressed = false
+		return r.gesture(m.Position)
+
+	default:
+		// Other buttons abandon the gesture.
+		r.pressed = false
+		return nil
+	}
+}
+
+// gesture returns the gesture of a press released at the position.
+func (r *Recognizer) gesture(end image.Point) *Gesture {
+	delta := end.Sub(r.start)
+	dir, dist := direction(delta)
+	switch {
+	case dist >= r.opts.swipeDistance:
+		return &Gesture{
+			Kind:      KindSwipe,
+			Position:  r.start,
+			Direction: dir,
+			Distance:  dist,
+		}
+	case timeNow().Sub(r.since) >= r.opts.longPress:
+		return &Gesture{
+			Kind:     KindLongPress,
+			Position: r.start,
+		}
+	default:
+		return &Gesture{
+			Kind:     KindTap,
+			Position: r.start,
+		}
+	}
+}
+
+// direction returns the direction along the axis with the larger movement and
+// the distance moved along it.
+func direction(delta image.Point) (Direction, int) {
+	x, y := abs(delta.X), abs(delta.Y)
+	switch {
+	case x == 0 && y == 0:
+		return DirectionNone, 0
+	case x >= y && delta.X > 0:
+		return DirectionRight, x
+	case x >= y:
+		return DirectionLeft, x
+	case delta.Y > 0:
+		return DirectionDown, y
+	default:
+		return DirectionUp, y
+	}
+}
+
+// abs returns the absolute value of the integer.
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
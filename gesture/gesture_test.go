@@ -0,0 +1,200 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gesture
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+func TestNewRecognizer(t *testing.T) {
+	tests := []struct {
+		desc    string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			desc: "succeeds with default options",
+		},
+		{
+			desc: "succeeds with custom options",
+			opts: []Option{
+				LongPress(time.Second),
+				SwipeDistance(1),
+			},
+		},
+		{
+			desc:    "fails on zero LongPress",
+			opts:    []Option{LongPress(0)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on zero SwipeDistance",
+			opts:    []Option{SwipeDistance(0)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := NewRecognizer(tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("NewRecognizer => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// event is a mouse event and the time elapsed before it arrived.
+type event struct {
+	m     *terminalapi.Mouse
+	after time.Duration
+}
+
+func TestMouse(t *testing.T) {
+	tests := []struct {
+		desc   string
+		events []event
+		want   []*Gesture
+	}{
+		{
+			desc: "tap",
+			events: []event{
+				{m: &terminalapi.Mouse{Position: image.Point{1, 2}, Button: mouse.ButtonLeft}},
+				{m: &terminalapi.Mouse{Position: image.Point{1, 2}, Button: mouse.ButtonRelease}, after: 100 * time.Millisecond},
+			},
+			want: []*Gesture{
+				{Kind: KindTap, Position: image.Point{1, 2}},
+			},
+		},
+		{
+			desc: "movement shorter than the swipe distance is a tap",
+			events: []event{
+				{m: &terminalapi.Mouse{Position: image.Point{1, 2}, Button: mouse.ButtonLeft}},
+				{m: &terminalapi.Mouse{Position: image.Point{3, 3}, Button: mouse.ButtonRelease}},
+			},
+			want: []*Gesture{
+				{Kind: KindTap, Position: image.Point{1, 2}},
+			},
+		},
+		{
+			desc: "long press",
+			events: []event{
+				{m: &terminalapi.Mouse{Position: image.Point{1, 2}, Button: mouse.ButtonLeft}},
+				{m: &terminalapi.Mouse{Position: image.Point{1, 2}, Button: mouse.ButtonRelease}, after: DefaultLongPress},
+			},
+			want: []*Gesture{
+				{Kind: KindLongPress, Position: image.Point{1, 2}},
+			},
+		},
+		{
+			desc: "swipe right with a drag",
+			events: []event{
+				{m: &terminalapi.Mouse{Position: image.Point{1, 2}, Button: mouse.ButtonLeft}},
+				{m: &terminalapi.Mouse{Position: image.Point{3, 2}, Button: mouse.ButtonLeft}},
+				{m: &terminalapi.Mouse{Position: image.Point{6, 3}, Button: mouse.ButtonRelease}, after: time.Second},
+			},
+			want: []*Gesture{
+				{Kind: KindSwipe, Position: image.Point{1, 2}, Direction: DirectionRight, Distance: 5},
+			},
+		},
+		{
+			desc: "swipe left",
+			events: []event{
+				{m: &terminalapi.Mouse{Position: image.Point{5, 0}, Button: mouse.ButtonLeft}},
+				{m: &terminalapi.Mouse{Position: image.Point{2, 0}, Button: mouse.ButtonRelease}},
+			},
+			want: []*Gesture{
+				{Kind: KindSwipe, Position: image.Point{5, 0}, Direction: DirectionLeft, Distance: 3},
+			},
+		},
+		{
+			desc: "swipe up",
+			events: []event{
+				{m: &terminalapi.Mouse{Position: image.Point{0, 5}, Button: mouse.ButtonLeft}},
+				{m: &terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonRelease}},
+			},
+			want: []*Gesture{
+				{Kind: KindSwipe, Position: image.Point{0, 5}, Direction: DirectionUp, Distance: 4},
+			},
+		},
+		{
+			desc: "swipe down",
+			events: []event{
+				{m: &terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft}},
+				{m: &terminalapi.Mouse{Position: image.Point{0, 3}, Button: mouse.ButtonRelease}},
+			},
+			want: []*Gesture{
+				{Kind: KindSwipe, Position: image.Point{0, 0}, Direction: DirectionDown, Distance: 3},
+			},
+		},
+		{
+			desc: "release without a press isn't a gesture",
+			events: []event{
+				{m: &terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonRelease}},
+			},
+		},
+		{
+			desc: "other buttons abandon the gesture",
+			events: []event{
+				{m: &terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft}},
+				{m: &terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonRight}},
+				{m: &terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonRelease}},
+			},
+		},
+		{
+			desc: "recognizes consecutive gestures",
+			events: []event{
+				{m: &terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft}},
+				{m: &terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonRelease}},
+				{m: &terminalapi.Mouse{Position: image.Point{4, 4}, Button: mouse.ButtonLeft}},
+				{m: &terminalapi.Mouse{Position: image.Point{4, 4}, Button: mouse.ButtonRelease}},
+			},
+			want: []*Gesture{
+				{Kind: KindTap, Position: image.Point{0, 0}},
+				{Kind: KindTap, Position: image.Point{4, 4}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			timeNow = func() time.Time { return now }
+			defer func() { timeNow = time.Now }()
+
+			r, err := NewRecognizer()
+			if err != nil {
+				t.Fatalf("NewRecognizer => unexpected error: %v", err)
+			}
+
+			var got []*Gesture
+			for _, ev := range tc.events {
+				now = now.Add(ev.after)
+				if g := r.Mouse(ev.m); g != nil {
+					got = append(got, g)
+				}
+			}
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("Mouse => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
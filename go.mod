@@ -7,4 +7,5 @@ require (
 	github.com/kylelemons/godebug v1.1.0
 	github.com/mattn/go-runewidth v0.0.9
 	github.com/nsf/termbox-go v0.0.0-20200204031403-4d2b513ad8be
+	gopkg.in/yaml.v2 v2.4.0
 )
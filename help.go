@@ -0,0 +1,54 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termdash
+
+// help.go contains code that displays the overlay listing the global key
+// bindings.
+
+import (
+	"fmt"
+
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// helpTitle is the title displayed in the border of the help overlay.
+const helpTitle = "Key bindings"
+
+// helpLines returns the lines of the help overlay, one for each binding with
+// the key sequences aligned into a column.
+func helpLines(bindings []keybinding.Binding) []string {
+	var seqWidth int
+	for _, b := range bindings {
+		if w := runewidth.StringWidth(b.Sequence.String()); w > seqWidth {
+			seqWidth = w
+		}
+	}
+
+	var lines []string
+	for _, b := range bindings {
+		seq := b.Sequence.String()
+		pad := seqWidth - runewidth.StringWidth(seq)
+		lines = append(lines, fmt.Sprintf("%s%*s  %s", seq, pad, "", b.Description))
+	}
+	return lines
+}
+
+// drawHelp draws the help overlay listing the bindings in the middle of the
+// terminal.
+func drawHelp(t terminalapi.Terminal, bindings []keybinding.Binding) error {
+	return drawOverlay(t, helpTitle, helpLines(bindings))
+}
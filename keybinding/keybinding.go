@@ -0,0 +1,353 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package keybinding implements a registry of key sequences.
+//
+// A key sequence is a chord of keys pressed one after another, e.g. "g g" or
+// "Ctrl+X Ctrl+S". Applications bind sequences to functions on a Registry and
+// feed it the keyboard events, the Registry runs the bound function once the
+// whole sequence was pressed. Registries can be attached to containers, see
+// container.KeyBindings, registered as global shortcuts, see
+// termdash.KeyBindings, or used by widgets in their Keyboard method.
+package keybinding
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Sequence is a sequence of keys that are pressed one after another.
+type Sequence []keyboard.Key
+
+// String implements fmt.Stringer.
+func (s Sequence) String() string {
+	var keys []string
+	for _, k := range s {
+		keys = append(keys, k.String())
+	}
+	return strings.Join(keys, " ")
+}
+
+// hasPrefix determines if the sequence starts with the prefix.
+func (s Sequence) hasPrefix(prefix Sequence) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i, k := range prefix {
+		if s[i] != k {
+			return false
+		}
+	}
+	return true
+}
+
+// HandlerFn is called when the bound key sequence is pressed.
+//
+// The function is called from the goroutine that processes keyboard events
+// and must be thread-safe. If the function returns an error, it is forwarded
+// to the caller of Registry.Keyboard, which in the case of containers causes a
+// panic, unless the user provided a termdash.ErrorHandler.
+type HandlerFn func() error
+
+// Option is used to provide options to New().
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// options stores the provided options.
+type options struct {
+	timeout time.Duration
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.timeout <= 0 {
+		return fmt.Errorf("invalid Timeout(%v), must be a positive duration", o.timeout)
+	}
+	return nil
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// DefaultTimeout is the default value for the Timeout option.
+const DefaultTimeout = time.Second
+
+// Timeout sets how long the Registry waits for the next key of a sequence.
+// A partially pressed sequence is abandoned if the next key doesn't arrive in
+// time.
+// Defaults to DefaultTimeout.
+func Timeout(d time.Duration) Option {
+	return option(func(opts *options) {
+		opts.timeout = d
+	})
+}
+
+// timeNow returns the current time.
+// Replaced from tests.
+var timeNow = time.Now
+
+// BindOption is used to provide options to Registry.Bind().
+type BindOption interface {
+	// set sets the provided option.
+	set(*binding)
+}
+
+// bindOption implements BindOption.
+type bindOption func(*binding)
+
+// set implements BindOption.set.
+func (o bindOption) set(b *binding) {
+	o(b)
+}
+
+// Description sets a human readable description of what the bound function
+// does, e.g. "Quit" or "Save the file". The descriptions are listed by
+// Registry.Bindings and displayed by the termdash.HelpKey overlay.
+func Description(text string) BindOption {
+	return bindOption(func(b *binding) {
+		b.desc = text
+	})
+}
+
+// binding is a key sequence bound to a function.
+type binding struct {
+	seq  Sequence
+	fn   HandlerFn
+	desc string
+}
+
+// Binding describes a bound key sequence.
+type Binding struct {
+	// Sequence is the bound key sequence.
+	Sequence Sequence
+	// Description is the description provided via the Description option.
+	Description string
+}
+
+// Registry maps key sequences to functions.
+//
+// This object is thread-safe.
+type Registry struct {
+	// bindings are the bound key sequences in the order they were bound.
+	bindings []*binding
+
+	// pending are the keys of a partially pressed sequence and last is the
+	// time the last of them was pressed.
+	pending Sequence
+	last    time.Time
+
+	// mu protects the Registry.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new empty Registry.
+func New(opts ...Option) (*Registry, error) {
+	opt := &options{
+		timeout: DefaultTimeout,
+	}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Registry{
+		opts: opt,
+	}, nil
+}
+
+// Bind binds the key sequence to the function.
+// Returns an error if the sequence is empty or if it conflicts with an already
+// bound sequence, see Conflict.
+func (r *Registry) Bind(seq Sequence, fn HandlerFn, opts ...BindOption) error {
+	if len(seq) == 0 {
+		return errors.New("the key sequence cannot be empty")
+	}
+	if fn == nil {
+		return fmt.Errorf("the function bound to key sequence %v cannot be nil", seq)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.conflict(seq); err != nil {
+		return err
+	}
+	b := &binding{
+		seq: append(Sequence(nil), seq...),
+		fn:  fn,
+	}
+	for _, o := range opts {
+		o.set(b)
+	}
+	r.bindings = append(r.bindings, b)
+	return nil
+}
+
+// Conflict returns an error if the key sequence conflicts with a bound
+// sequence, i.e. if either one is a prefix of the other, since the longer one
+// could never be pressed.
+// Useful to verify at setup time that keys used elsewhere in the application
+// don't collide with the bindings.
+func (r *Registry) Conflict(seq Sequence) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.conflict(seq)
+}
+
+// conflict implements Conflict.
+// Caller must hold r.mu.
+func (r *Registry) conflict(seq Sequence) error {
+	for _, b := range r.bindings {
+		if b.seq.hasPrefix(seq) || seq.hasPrefix(b.seq) {
+			return fmt.Errorf("key sequence %v conflicts with the already bound sequence %v", seq, b.seq)
+		}
+	}
+	return nil
+}
+
+// Bindings returns the bound key sequences in the order they were bound.
+func (r *Registry) Bindings() []Binding {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var res []Binding
+	for _, b := range r.bindings {
+		res = append(res, Binding{
+			Sequence:    append(Sequence(nil), b.seq...),
+			Description: b.desc,
+		})
+	}
+	return res
+}
+
+// Unbind removes the binding of the key sequence.
+// Returns false if the sequence wasn't bound.
+func (r *Registry) Unbind(seq Sequence) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, b := range r.bindings {
+		if len(b.seq) == len(seq) && b.seq.hasPrefix(seq) {
+			r.bindings = append(r.bindings[:i], r.bindings[i+1:]...)
+			r.pending = nil
+			return true
+		}
+	}
+	return false
+}
+
+// Pending returns the keys of a partially pressed sequence or nil if there
+// isn't any. Useful to display a hint while the user presses a sequence.
+func (r *Registry) Pending() Sequence {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.expired() {
+		return nil
+	}
+	return append(Sequence(nil), r.pending...)
+}
+
+// expired determines if the partially pressed sequence timed out.
+// Caller must hold r.mu.
+func (r *Registry) expired() bool {
+	return len(r.pending) > 0 && timeNow().Sub(r.last) > r.opts.timeout
+}
+
+// Match processes the keyboard event without calling the bound function.
+// Returns true if the key started, continued or completed a bound sequence,
+// in which case it shouldn't be processed further. Returns the bound function
+// if the key completed a sequence, the caller is responsible for calling it.
+//
+// A key that doesn't continue a partially pressed sequence abandons it and is
+// then matched as the first key of a sequence.
+func (r *Registry) Match(k *terminalapi.Keyboard) (HandlerFn, bool) {
+	_, fn, consumed := r.MatchBinding(k)
+	return fn, consumed
+}
+
+// MatchBinding is like Match, but also returns the binding whose sequence the
+// key completed, nil if it didn't complete any.
+func (r *Registry) MatchBinding(k *terminalapi.Keyboard) (*Binding, HandlerFn, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.expired() {
+		r.pending = nil
+	}
+	b, ok := r.match(append(r.pending, k.Key))
+	if !ok {
+		if len(r.pending) == 0 {
+			return nil, nil, false
+		}
+		r.pending = nil
+		b, ok = r.match(Sequence{k.Key})
+	}
+	if b == nil {
+		return nil, nil, ok
+	}
+	return &Binding{
+		Sequence:    append(Sequence(nil), b.seq...),
+		Description: b.desc,
+	}, b.fn, true
+}
+
+// match matches the pressed keys against the bindings and updates the
+// partially pressed sequence. Returns the binding if the keys completed its
+// sequence.
+// Caller must hold r.mu.
+func (r *Registry) match(pressed Sequence) (*binding, bool) {
+	for _, b := range r.bindings {
+		if !b.seq.hasPrefix(pressed) {
+			continue
+		}
+		if len(b.seq) == len(pressed) {
+			r.pending = nil
+			return b, true
+		}
+		r.pending = append(Sequence(nil), pressed...)
+		r.last = timeNow()
+		return nil, true
+	}
+	return nil, false
+}
+
+// Keyboard processes the keyboard event and calls the bound function if the
+// key completed a sequence. Returns true if the key was consumed, see Match.
+// The function is called after the Registry is unlocked, so it can call the
+// methods of the Registry.
+func (r *Registry) Keyboard(k *terminalapi.Keyboard) (bool, error) {
+	fn, consumed := r.Match(k)
+	if fn == nil {
+		return consumed, nil
+	}
+	return true, fn()
+}
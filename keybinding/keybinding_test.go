@@ -0,0 +1,420 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keybinding
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		desc    string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			desc: "succeeds with default options",
+		},
+		{
+			desc: "succeeds with a custom timeout",
+			opts: []Option{Timeout(time.Minute)},
+		},
+		{
+			desc:    "fails on zero timeout",
+			opts:    []Option{Timeout(0)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := New(tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	noop := func() error { return nil }
+	tests := []struct {
+		desc    string
+		bound   []Sequence
+		seq     Sequence
+		fn      HandlerFn
+		wantErr bool
+	}{
+		{
+			desc: "binds a sequence",
+			seq:  Sequence{keyboard.KeyCtrlX, keyboard.KeyCtrlS},
+			fn:   noop,
+		},
+		{
+			desc:  "binds sequences with a common prefix",
+			bound: []Sequence{{keyboard.KeyCtrlX, keyboard.KeyCtrlS}},
+			seq:   Sequence{keyboard.KeyCtrlX, keyboard.KeyCtrlC},
+			fn:    noop,
+		},
+		{
+			desc:    "fails on empty sequence",
+			fn:      noop,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on nil function",
+			seq:     Sequence{'g'},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on already bound sequence",
+			bound:   []Sequence{{'g', 'g'}},
+			seq:     Sequence{'g', 'g'},
+			fn:      noop,
+			wantErr: true,
+		},
+		{
+			desc:    "fails when the sequence is a prefix of a bound sequence",
+			bound:   []Sequence{{'g', 'g'}},
+			seq:     Sequence{'g'},
+			fn:      noop,
+			wantErr: true,
+		},
+		{
+			desc:    "fails when a bound sequence is a prefix of the sequence",
+			bound:   []Sequence{{'g'}},
+			seq:     Sequence{'g', 'g'},
+			fn:      noop,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := New()
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			for _, seq := range tc.bound {
+				if err := r.Bind(seq, noop); err != nil {
+					t.Fatalf("Bind(%v) => unexpected error: %v", seq, err)
+				}
+			}
+
+			err = r.Bind(tc.seq, tc.fn)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Bind(%v) => unexpected error: %v, wantErr: %v", tc.seq, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// pressed records the names of the bindings whose functions were called.
+type pressed []string
+
+// fn returns a function that records the name when called.
+func (p *pressed) fn(name string) HandlerFn {
+	return func() error {
+		*p = append(*p, name)
+		return nil
+	}
+}
+
+// key is a pressed key and the time elapsed before it was pressed.
+type key struct {
+	key   keyboard.Key
+	after time.Duration
+}
+
+func TestKeyboard(t *testing.T) {
+	tests := []struct {
+		desc         string
+		opts         []Option
+		keys         []key
+		want         []string
+		wantConsumed []bool
+		wantPending  Sequence
+	}{
+		{
+			desc: "single key binding",
+			keys: []key{
+				{key: keyboard.KeyCtrlQ},
+			},
+			want:         []string{"quit"},
+			wantConsumed: []bool{true},
+		},
+		{
+			desc: "unbound keys aren't consumed",
+			keys: []key{
+				{key: 'a'},
+				{key: keyboard.KeyCtrlS},
+			},
+			wantConsumed: []bool{false, false},
+		},
+		{
+			desc: "two key sequence",
+			keys: []key{
+				{key: keyboard.KeyCtrlX},
+				{key: keyboard.KeyCtrlS},
+			},
+			want:         []string{"save"},
+			wantConsumed: []bool{true, true},
+		},
+		{
+			desc: "sequences with a common prefix",
+			keys: []key{
+				{key: keyboard.KeyCtrlX},
+				{key: keyboard.KeyCtrlC},
+				{key: keyboard.KeyCtrlX},
+				{key: keyboard.KeyCtrlS},
+			},
+			want:         []string{"close", "save"},
+			wantConsumed: []bool{true, true, true, true},
+		},
+		{
+			desc: "repeated key sequence",
+			keys: []key{
+				{key: 'g'},
+				{key: 'g'},
+				{key: 'g'},
+			},
+			want:         []string{"top"},
+			wantConsumed: []bool{true, true, true},
+			wantPending:  Sequence{'g'},
+		},
+		{
+			desc: "a key that doesn't continue the sequence abandons it",
+			keys: []key{
+				{key: keyboard.KeyCtrlX},
+				{key: 'a'},
+				{key: keyboard.KeyCtrlS},
+			},
+			wantConsumed: []bool{true, false, false},
+		},
+		{
+			desc: "a key that doesn't continue the sequence can start a new one",
+			keys: []key{
+				{key: keyboard.KeyCtrlX},
+				{key: 'g'},
+				{key: 'g'},
+			},
+			want:         []string{"top"},
+			wantConsumed: []bool{true, true, true},
+		},
+		{
+			desc: "sequence continues within the timeout",
+			keys: []key{
+				{key: keyboard.KeyCtrlX},
+				{key: keyboard.KeyCtrlS, after: DefaultTimeout},
+			},
+			want:         []string{"save"},
+			wantConsumed: []bool{true, true},
+		},
+		{
+			desc: "sequence abandoned after the timeout",
+			keys: []key{
+				{key: keyboard.KeyCtrlX},
+				{key: keyboard.KeyCtrlS, after: DefaultTimeout + time.Millisecond},
+			},
+			wantConsumed: []bool{true, false},
+		},
+		{
+			desc: "custom timeout",
+			opts: []Option{Timeout(time.Millisecond)},
+			keys: []key{
+				{key: 'g'},
+				{key: 'g', after: 2 * time.Millisecond},
+			},
+			wantConsumed: []bool{true, true},
+			wantPending:  Sequence{'g'},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			timeNow = func() time.Time { return now }
+			defer func() { timeNow = time.Now }()
+
+			r, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			var got pressed
+			bindings := map[string]Sequence{
+				"quit":  {keyboard.KeyCtrlQ},
+				"save":  {keyboard.KeyCtrlX, keyboard.KeyCtrlS},
+				"close": {keyboard.KeyCtrlX, keyboard.KeyCtrlC},
+				"top":   {'g', 'g'},
+			}
+			for name, seq := range bindings {
+				if err := r.Bind(seq, got.fn(name)); err != nil {
+					t.Fatalf("Bind(%v) => unexpected error: %v", seq, err)
+				}
+			}
+
+			var gotConsumed []bool
+			for _, k := range tc.keys {
+				now = now.Add(k.after)
+				consumed, err := r.Keyboard(&terminalapi.Keyboard{Key: k.key})
+				if err != nil {
+					t.Fatalf("Keyboard(%v) => unexpected error: %v", k.key, err)
+				}
+				gotConsumed = append(gotConsumed, consumed)
+			}
+
+			if diff := pretty.Compare(tc.want, []string(got)); diff != "" {
+				t.Errorf("Keyboard => unexpected called functions, diff (-want, +got):\n%s", diff)
+			}
+			if diff := pretty.Compare(tc.wantConsumed, gotConsumed); diff != "" {
+				t.Errorf("Keyboard => unexpected consumed, diff (-want, +got):\n%s", diff)
+			}
+			if diff := pretty.Compare(tc.wantPending, r.Pending()); diff != "" {
+				t.Errorf("Pending => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestKeyboardError(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := r.Bind(Sequence{keyboard.KeyCtrlQ}, func() error {
+		return errors.New("failed")
+	}); err != nil {
+		t.Fatalf("Bind => unexpected error: %v", err)
+	}
+	if _, err := r.Keyboard(&terminalapi.Keyboard{Key: keyboard.KeyCtrlQ}); err == nil {
+		t.Errorf("Keyboard => got nil error, want the error from the bound function")
+	}
+}
+
+func TestUnbind(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	var got pressed
+	seq := Sequence{'g', 'g'}
+	if err := r.Bind(seq, got.fn("top")); err != nil {
+		t.Fatalf("Bind => unexpected error: %v", err)
+	}
+
+	if r.Unbind(Sequence{'g'}) {
+		t.Errorf("Unbind(%v) => true, want false for a sequence that isn't bound", Sequence{'g'})
+	}
+	if !r.Unbind(seq) {
+		t.Errorf("Unbind(%v) => false, want true", seq)
+	}
+	if consumed, err := r.Keyboard(&terminalapi.Keyboard{Key: 'g'}); err != nil || consumed {
+		t.Errorf("Keyboard => %v, %v, want false, <nil> after the sequence was unbound", consumed, err)
+	}
+	if err := r.Bind(Sequence{'g'}, got.fn("g")); err != nil {
+		t.Errorf("Bind => unexpected error after the conflicting sequence was unbound: %v", err)
+	}
+}
+
+func TestSequenceString(t *testing.T) {
+	seq := Sequence{keyboard.KeyCtrlX, 's'}
+	if got, want := seq.String(), "KeyCtrlX s"; got != want {
+		t.Errorf("String => %q, want %q", got, want)
+	}
+}
+
+func TestBindings(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	noop := func() error { return nil }
+	if err := r.Bind(Sequence{keyboard.KeyCtrlX, 's'}, noop, Description("Save")); err != nil {
+		t.Fatalf("Bind => unexpected error: %v", err)
+	}
+	if err := r.Bind(Sequence{'q'}, noop); err != nil {
+		t.Fatalf("Bind => unexpected error: %v", err)
+	}
+
+	want := []Binding{
+		{Sequence: Sequence{keyboard.KeyCtrlX, 's'}, Description: "Save"},
+		{Sequence: Sequence{'q'}},
+	}
+	if diff := pretty.Compare(want, r.Bindings()); diff != "" {
+		t.Errorf("Bindings => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestMatchBinding(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	noop := func() error { return nil }
+	if err := r.Bind(Sequence{keyboard.KeyCtrlX, 's'}, noop, Description("Save")); err != nil {
+		t.Fatalf("Bind => unexpected error: %v", err)
+	}
+
+	b, fn, consumed := r.MatchBinding(&terminalapi.Keyboard{Key: keyboard.KeyCtrlX})
+	if b != nil || fn != nil || !consumed {
+		t.Errorf("MatchBinding(KeyCtrlX) => %v, %v, %v, want <nil>, <nil>, true", b, fn != nil, consumed)
+	}
+
+	b, fn, consumed = r.MatchBinding(&terminalapi.Keyboard{Key: 's'})
+	if fn == nil || !consumed {
+		t.Fatalf("MatchBinding(s) => %v, %v, want a function, true", fn != nil, consumed)
+	}
+	want := &Binding{Sequence: Sequence{keyboard.KeyCtrlX, 's'}, Description: "Save"}
+	if diff := pretty.Compare(want, b); diff != "" {
+		t.Errorf("MatchBinding(s) => unexpected binding diff (-want, +got):\n%s", diff)
+	}
+
+	b, fn, consumed = r.MatchBinding(&terminalapi.Keyboard{Key: 'a'})
+	if b != nil || fn != nil || consumed {
+		t.Errorf("MatchBinding(a) => %v, %v, %v, want <nil>, <nil>, false", b, fn != nil, consumed)
+	}
+}
+
+func TestConflict(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := r.Bind(Sequence{'g', 'g'}, func() error { return nil }); err != nil {
+		t.Fatalf("Bind => unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		seq     Sequence
+		wantErr bool
+	}{
+		{seq: Sequence{'g'}, wantErr: true},
+		{seq: Sequence{'g', 'g', 'x'}, wantErr: true},
+		{seq: Sequence{'g', 'x'}},
+		{seq: Sequence{'x'}},
+	}
+	for _, tc := range tests {
+		err := r.Conflict(tc.seq)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Conflict(%v) => unexpected error: %v, wantErr: %v", tc.seq, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termdash
+
+// lock.go contains code that detects inactivity and locks the dashboard.
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// lockTitle is the title displayed in the border of the lock overlay.
+const lockTitle = "Locked"
+
+// lockState is the state of a locked dashboard.
+type lockState struct {
+	// input is the passphrase entered so far.
+	input []rune
+	// failed indicates that the last entered passphrase was rejected.
+	failed bool
+}
+
+// lines returns the lines displayed in the lock overlay.
+func (ls *lockState) lines() []string {
+	lines := []string{
+		"Enter the passphrase to unlock.",
+		"",
+		"> " + strings.Repeat("*", len(ls.input)),
+	}
+	if ls.failed {
+		lines = append(lines, "", "Wrong passphrase, try again.")
+	}
+	return lines
+}
+
+// isInput determines if the event is an input from the user.
+func isInput(ev terminalapi.Event) bool {
+	switch ev.(type) {
+	case *terminalapi.Keyboard, *terminalapi.Mouse, *terminalapi.Paste, *terminalapi.Composition, *terminalapi.Commit:
+		return true
+	default:
+		return false
+	}
+}
+
+// idle is called when no input arrived for the IdleTimeout.
+func (td *termdash) idle() {
+	if td.onIdle != nil {
+		td.onIdle()
+	}
+	if td.lockVerify == nil {
+		return
+	}
+
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	td.lock = &lockState{}
+	td.clearNeeded = true
+	td.audit(&AuditRecord{Action: AuditLock})
+	if err := td.redraw(); err != nil {
+		td.handleError(err)
+	}
+}
+
+// restartIdle restarts the measuring of the inactivity.
+func (td *termdash) restartIdle() {
+	if td.idleTimer != nil {
+		td.idleTimer.Reset(td.idleTimeout)
+	}
+}
+
+// lockInput processes the input event while the dashboard is locked.
+// Returns false if the dashboard isn't locked and the event should be
+// processed as usual.
+func (td *termdash) lockInput(ev terminalapi.Event) bool {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+
+	ls := td.lock
+	if ls == nil {
+		return false
+	}
+	k, ok := ev.(*terminalapi.Keyboard)
+	if !ok {
+		// Other input is ignored while locked.
+		return true
+	}
+
+	switch {
+	case k.Key == keyboard.KeyEnter:
+		pass := string(ls.input)
+		ls.input = nil
+		// The function is provided by the user, don't hold the lock while
+		// it runs.
+		td.mu.Unlock()
+		ok := td.lockVerify(pass)
+		td.mu.Lock()
+		if ok {
+			td.lock = nil
+			td.clearNeeded = true
+			td.restartIdle()
+			td.audit(&AuditRecord{Action: AuditUnlock})
+		} else {
+			ls.failed = true
+			td.audit(&AuditRecord{Action: AuditUnlockFailed})
+		}
+
+	case k.Key == keyboard.KeyBackspace || k.Key == keyboard.KeyBackspace2:
+		if len(ls.input) > 0 {
+			ls.input = ls.input[:len(ls.input)-1]
+		}
+
+	case k.Key == keyboard.KeyEsc:
+		ls.input = nil
+
+	case k.Key > 0 && unicode.IsPrint(rune(k.Key)):
+		ls.input = append(ls.input, rune(k.Key))
+	}
+
+	if err := td.redraw(); err != nil {
+		td.handleError(err)
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termdash
+
+// maximize.go contains code that maximizes and restores containers.
+
+import (
+	"fmt"
+
+	"github.com/mum4k/termdash/keybinding"
+)
+
+// MaximizeKey binds the key sequence to a function that maximizes the focused
+// container to fill the whole terminal or restores the layout if a container
+// is maximized, see container.Container.Maximize.
+// The sequence is bound in the registry provided via the KeyBindings option,
+// which is required.
+func MaximizeKey(seq keybinding.Sequence) Option {
+	return option(func(td *termdash) {
+		td.maximizeKey = seq
+	})
+}
+
+// bindMaximizeKey binds the MaximizeKey sequence in the key bindings.
+func (td *termdash) bindMaximizeKey() error {
+	if td.maximizeKey == nil {
+		return nil
+	}
+	if err := td.keyBindings.Bind(td.maximizeKey, td.toggleMaximize, keybinding.Description("Maximize or restore the focused container")); err != nil {
+		return fmt.Errorf("invalid MaximizeKey: %v", err)
+	}
+	return nil
+}
+
+// toggleMaximize maximizes the focused container of the displayed screen or
+// restores its layout and redraws the terminal.
+func (td *termdash) toggleMaximize() error {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	td.container.ToggleMaximize()
+	return td.redraw()
+}
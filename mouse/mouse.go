@@ -34,6 +34,7 @@ var buttonNames = map[Button]string{
 	ButtonRelease:   "ButtonRelease",
 	ButtonWheelUp:   "ButtonWheelUp",
 	ButtonWheelDown: "ButtonWheelDown",
+	ButtonNone:      "ButtonNone",
 }
 
 // Buttons recognized on the mouse.
@@ -45,4 +46,9 @@ const (
 	ButtonRelease
 	ButtonWheelUp
 	ButtonWheelDown
+
+	// ButtonNone indicates that the mouse pointer moved without any pressed
+	// buttons, i.e. it hovers. Only reported by terminals that support it,
+	// see terminalapi.Capabilities.Hover.
+	ButtonNone
 )
@@ -0,0 +1,62 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termdash
+
+// overlay.go contains code that draws boxes over the dashboard.
+
+import (
+	"image"
+
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// drawOverlay draws a box with the title in its border and the lines of text
+// in the middle of the terminal. The box isn't drawn if it doesn't fit onto
+// the terminal.
+func drawOverlay(t terminalapi.Terminal, title string, lines []string) error {
+	width := runewidth.StringWidth(title)
+	for _, l := range lines {
+		if w := runewidth.StringWidth(l); w > width {
+			width = w
+		}
+	}
+
+	// One cell for the border and one for padding on each side.
+	size := image.Point{width + 4, len(lines) + 2}
+	term := t.Size()
+	if size.X > term.X || size.Y > term.Y {
+		return nil
+	}
+	start := term.Sub(size).Div(2)
+	cvs, err := canvas.New(image.Rectangle{start, start.Add(size)})
+	if err != nil {
+		return err
+	}
+	if err := cvs.SetAreaCells(cvs.Area(), ' '); err != nil {
+		return err
+	}
+	if err := draw.Border(cvs, cvs.Area(), draw.BorderTitle(title, draw.OverrunModeThreeDot)); err != nil {
+		return err
+	}
+	for i, l := range lines {
+		if err := draw.Text(cvs, l, image.Point{2, 1 + i}); err != nil {
+			return err
+		}
+	}
+	return cvs.Apply(t)
+}
@@ -0,0 +1,269 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pages
+
+// breadcrumbs.go contains the widget that displays the path of the router.
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// BreadcrumbsOption is used to provide options to Breadcrumbs().
+type BreadcrumbsOption interface {
+	// set sets the provided option.
+	set(*breadcrumbsOptions)
+}
+
+// breadcrumbsOptions stores the provided options.
+type breadcrumbsOptions struct {
+	rootLabel      string
+	separator      string
+	cellOpts       []cell.Option
+	activeCellOpts []cell.Option
+}
+
+// breadcrumbsOption implements BreadcrumbsOption.
+type breadcrumbsOption func(*breadcrumbsOptions)
+
+// set implements BreadcrumbsOption.set.
+func (o breadcrumbsOption) set(opts *breadcrumbsOptions) {
+	o(opts)
+}
+
+// CrumbRootLabel sets the label of the crumb that represents the root path
+// "/". The root crumb is only displayed if a route matches the root path.
+// Defaults to "Home".
+func CrumbRootLabel(label string) BreadcrumbsOption {
+	return breadcrumbsOption(func(o *breadcrumbsOptions) {
+		o.rootLabel = label
+	})
+}
+
+// CrumbSeparator sets the text displayed between the crumbs.
+// Defaults to " > ".
+func CrumbSeparator(sep string) BreadcrumbsOption {
+	return breadcrumbsOption(func(o *breadcrumbsOptions) {
+		o.separator = sep
+	})
+}
+
+// CrumbCellOpts sets the cell options of the crumbs and the separators.
+func CrumbCellOpts(opts ...cell.Option) BreadcrumbsOption {
+	return breadcrumbsOption(func(o *breadcrumbsOptions) {
+		o.cellOpts = opts
+	})
+}
+
+// CrumbActiveCellOpts sets the cell options of the last crumb, i.e. the one
+// that represents the displayed path.
+// Defaults to black text on white background.
+func CrumbActiveCellOpts(opts ...cell.Option) BreadcrumbsOption {
+	return breadcrumbsOption(func(o *breadcrumbsOptions) {
+		o.activeCellOpts = opts
+	})
+}
+
+// crumb is a segment of the displayed path.
+type crumb struct {
+	// label is the displayed text.
+	label string
+	// path is the path up to and including the segment.
+	path string
+	// linked indicates that a route matches the path.
+	linked bool
+	// start and end are the positions of the label on the row as of the last
+	// call to Draw.
+	start, end int
+}
+
+// Breadcrumbs displays the segments of the path displayed by a router on a
+// single row. Clicking on a segment navigates to the path up to and
+// including the segment if a route matches it.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Breadcrumbs struct {
+	// router is the router whose path is displayed.
+	router *Router
+
+	// crumbs are the displayed crumbs as of the last call to Draw.
+	crumbs []*crumb
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the Breadcrumbs.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *breadcrumbsOptions
+}
+
+// Breadcrumbs returns a new widget that displays the path of the router.
+// Place it into a container outside of the one the router replaces.
+func (r *Router) Breadcrumbs(opts ...BreadcrumbsOption) *Breadcrumbs {
+	opt := &breadcrumbsOptions{
+		rootLabel: "Home",
+		separator: " > ",
+		activeCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorBlack),
+			cell.BgColor(cell.ColorWhite),
+		},
+	}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	bc := &Breadcrumbs{
+		router: r,
+		opts:   opt,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.crumbs = append(r.crumbs, bc)
+	return bc
+}
+
+// changed notifies the infrastructure that the displayed path changed.
+func (bc *Breadcrumbs) changed() {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if bc.notify != nil {
+		bc.notify()
+	}
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (bc *Breadcrumbs) SetNotifyFunc(fn func()) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.notify = fn
+}
+
+// crumbsFor returns the crumbs of the path.
+func (bc *Breadcrumbs) crumbsFor(path string) []*crumb {
+	var crumbs []*crumb
+	if bc.router.Matches("/") {
+		crumbs = append(crumbs, &crumb{
+			label:  bc.opts.rootLabel,
+			path:   "/",
+			linked: true,
+		})
+	}
+	segments := splitPath(path)
+	for i, s := range segments {
+		p := "/" + strings.Join(segments[:i+1], "/")
+		crumbs = append(crumbs, &crumb{
+			label:  s,
+			path:   p,
+			linked: bc.router.Matches(p),
+		})
+	}
+	return crumbs
+}
+
+// Draw draws the Breadcrumbs widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (bc *Breadcrumbs) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	path := bc.router.Path()
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	bc.crumbs = bc.crumbsFor(path)
+	width := cvs.Area().Dx()
+	x := 0
+	for i, c := range bc.crumbs {
+		if i > 0 {
+			if x >= width {
+				break
+			}
+			if err := draw.Text(cvs, bc.opts.separator, image.Point{x, 0},
+				draw.TextMaxX(width),
+				draw.TextOverrunMode(draw.OverrunModeThreeDot),
+				draw.TextCellOpts(bc.opts.cellOpts...),
+			); err != nil {
+				return err
+			}
+			x += runewidth.StringWidth(bc.opts.separator)
+		}
+		if x >= width {
+			break
+		}
+
+		opts := bc.opts.cellOpts
+		if i == len(bc.crumbs)-1 {
+			opts = bc.opts.activeCellOpts
+		}
+		if err := draw.Text(cvs, c.label, image.Point{x, 0},
+			draw.TextMaxX(width),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(opts...),
+		); err != nil {
+			return err
+		}
+		c.start = x
+		c.end = x + runewidth.StringWidth(c.label)
+		x = c.end
+	}
+	return nil
+}
+
+// Keyboard input isn't supported on the Breadcrumbs widget.
+func (*Breadcrumbs) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the Breadcrumbs widget doesn't support keyboard events")
+}
+
+// Mouse navigates to the path of the clicked crumb.
+// Implements widgetapi.Widget.Mouse.
+func (bc *Breadcrumbs) Mouse(m *terminalapi.Mouse) error {
+	if m.Button != mouse.ButtonLeft {
+		return nil
+	}
+
+	bc.mu.Lock()
+	var path string
+	for _, c := range bc.crumbs {
+		if c.linked && m.Position.Y == 0 && m.Position.X >= c.start && m.Position.X < c.end {
+			path = c.path
+		}
+	}
+	bc.mu.Unlock()
+
+	if path == "" {
+		return nil
+	}
+	return bc.router.Navigate(path)
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*Breadcrumbs) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		MaximumSize:  image.Point{0, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+}
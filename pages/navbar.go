@@ -0,0 +1,215 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pages
+
+// navbar.go contains the widget that displays the names of the pages.
+
+import (
+	"errors"
+	"image"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// NavBarOption is used to provide options to NavBar().
+type NavBarOption interface {
+	// set sets the provided option.
+	set(*navBarOptions)
+}
+
+// navBarOptions stores the provided options.
+type navBarOptions struct {
+	cellOpts       []cell.Option
+	activeCellOpts []cell.Option
+}
+
+// navBarOption implements NavBarOption.
+type navBarOption func(*navBarOptions)
+
+// set implements NavBarOption.set.
+func (o navBarOption) set(opts *navBarOptions) {
+	o(opts)
+}
+
+// NavCellOpts sets the cell options of the names of the pages that aren't
+// displayed.
+func NavCellOpts(opts ...cell.Option) NavBarOption {
+	return navBarOption(func(o *navBarOptions) {
+		o.cellOpts = opts
+	})
+}
+
+// NavActiveCellOpts sets the cell options of the name of the displayed page.
+// Defaults to black text on white background.
+func NavActiveCellOpts(opts ...cell.Option) NavBarOption {
+	return navBarOption(func(o *navBarOptions) {
+		o.activeCellOpts = opts
+	})
+}
+
+// navItem is the position of a page name on the row.
+type navItem struct {
+	start, end int
+	index      int
+}
+
+// NavBar displays the names of the pages on a single row and highlights the
+// displayed page. Clicking on a name displays the page, scrolling the mouse
+// wheel displays the next or the previous page.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type NavBar struct {
+	// pages are the displayed pages.
+	pages *Pages
+
+	// items are the positions of the names as of the last call to Draw.
+	items []navItem
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the NavBar.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *navBarOptions
+}
+
+// NavBar returns a new widget that displays the names of the pages. Place it
+// into a container outside of the one the pages replace.
+func (p *Pages) NavBar(opts ...NavBarOption) *NavBar {
+	opt := &navBarOptions{
+		activeCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorBlack),
+			cell.BgColor(cell.ColorWhite),
+		},
+	}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	nb := &NavBar{
+		pages: p,
+		opts:  opt,
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.navBars = append(p.navBars, nb)
+	return nb
+}
+
+// changed notifies the infrastructure that the displayed page changed.
+func (nb *NavBar) changed() {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+	if nb.notify != nil {
+		nb.notify()
+	}
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (nb *NavBar) SetNotifyFunc(fn func()) {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+	nb.notify = fn
+}
+
+// Draw draws the NavBar widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (nb *NavBar) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	nb.pages.mu.Lock()
+	current := nb.pages.current
+	nb.pages.mu.Unlock()
+
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+
+	nb.items = nil
+	width := cvs.Area().Dx()
+	x := 0
+	for i, name := range nb.pages.Names() {
+		if x >= width {
+			break
+		}
+		opts := nb.opts.cellOpts
+		if i == current {
+			opts = nb.opts.activeCellOpts
+		}
+		// One cell of padding on each side of the name.
+		text := " " + name + " "
+		if err := draw.Text(cvs, text, image.Point{x, 0},
+			draw.TextMaxX(width),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(opts...),
+		); err != nil {
+			return err
+		}
+		end := x + runewidth.StringWidth(text)
+		nb.items = append(nb.items, navItem{start: x, end: end, index: i})
+		// One cell gap between the names.
+		x = end + 1
+	}
+	return nil
+}
+
+// Keyboard input isn't supported on the NavBar widget.
+func (*NavBar) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the NavBar widget doesn't support keyboard events")
+}
+
+// Mouse displays the clicked page.
+// Implements widgetapi.Widget.Mouse.
+func (nb *NavBar) Mouse(m *terminalapi.Mouse) error {
+	switch m.Button {
+	case mouse.ButtonWheelUp:
+		return nb.pages.Previous()
+	case mouse.ButtonWheelDown:
+		return nb.pages.Next()
+	case mouse.ButtonLeft:
+	default:
+		return nil
+	}
+
+	nb.mu.Lock()
+	index := -1
+	for _, it := range nb.items {
+		if m.Position.Y == 0 && m.Position.X >= it.start && m.Position.X < it.end {
+			index = it.index
+		}
+	}
+	nb.mu.Unlock()
+
+	if index < 0 {
+		return nil
+	}
+	return nb.pages.switchTo(index)
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*NavBar) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		MaximumSize:  image.Point{0, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+}
@@ -0,0 +1,399 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pages switches a container between named full-screen layouts.
+//
+// Applications with multiple screens register all their pages up front and
+// switch between them by name, instead of calling container.Update with the
+// options of each layout. The switch can be animated, bound to keys with
+// Bind, or done by clicking on the page names displayed by the NavBar widget.
+//
+// Applications whose screens depend on parameters, e.g. a screen per host,
+// use the Router instead. The Router builds the layout for a path like
+// "/hosts/web1" from the route that matches it, keeps the history of the
+// navigation for Back and Forward and displays the path in the Breadcrumbs
+// widget.
+//
+// Setup and onboarding flows use the Wizard, which displays its steps in
+// order and only continues to the next step once the gate of the displayed
+// step validated the input. The StepIndicator and Controls widgets display
+// the progress and the buttons that navigate the steps.
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/keybinding"
+)
+
+// Option is used to provide options to New().
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// page is a named layout.
+type page struct {
+	name string
+	opts []container.Option
+}
+
+// options stores the provided options.
+type options struct {
+	pages      []*page
+	transition Transition
+	duration   time.Duration
+	onSwitch   func(name string)
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if len(o.pages) == 0 {
+		return errors.New("at least one Page must be provided")
+	}
+	seen := map[string]bool{}
+	for _, p := range o.pages {
+		if p.name == "" {
+			return errors.New("the page name cannot be an empty string")
+		}
+		if seen[p.name] {
+			return fmt.Errorf("duplicate page name %q", p.name)
+		}
+		seen[p.name] = true
+	}
+	if _, ok := transitionNames[o.transition]; !ok {
+		return fmt.Errorf("unsupported transition %v", o.transition)
+	}
+	if o.transition != TransitionNone && o.duration <= 0 {
+		return fmt.Errorf("invalid transition duration %v, must be positive", o.duration)
+	}
+	return nil
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// Page registers a page with the name and the container options of its
+// layout. The pages are ordered as provided, the first page is displayed
+// initially.
+// The options are applied to a container that is created for the page, so
+// each page starts from a clean container regardless of the pages displayed
+// before it.
+func Page(name string, opts ...container.Option) Option {
+	return option(func(o *options) {
+		o.pages = append(o.pages, &page{
+			name: name,
+			opts: opts,
+		})
+	})
+}
+
+// Transition is an animation displayed when switching pages.
+type Transition int
+
+// String implements fmt.Stringer()
+func (t Transition) String() string {
+	if n, ok := transitionNames[t]; ok {
+		return n
+	}
+	return "TransitionUnknown"
+}
+
+// transitionNames maps Transition values to human readable names.
+var transitionNames = map[Transition]string{
+	TransitionNone:      "TransitionNone",
+	TransitionWipeLeft:  "TransitionWipeLeft",
+	TransitionWipeRight: "TransitionWipeRight",
+	TransitionWipeUp:    "TransitionWipeUp",
+	TransitionWipeDown:  "TransitionWipeDown",
+}
+
+const (
+	// TransitionNone displays the new page immediately.
+	TransitionNone Transition = iota
+
+	// TransitionWipeLeft reveals the new page from the right edge towards
+	// the left.
+	TransitionWipeLeft
+
+	// TransitionWipeRight reveals the new page from the left edge towards
+	// the right.
+	TransitionWipeRight
+
+	// TransitionWipeUp reveals the new page from the bottom edge upwards.
+	TransitionWipeUp
+
+	// TransitionWipeDown reveals the new page from the top edge downwards.
+	TransitionWipeDown
+)
+
+// Animate animates switching of pages with the transition that takes the
+// specified duration. The animation updates the layout every FrameInterval,
+// it is only visible if the dashboard is redrawn at least as often, see the
+// termdash.RedrawInterval option.
+// Defaults to TransitionNone.
+func Animate(t Transition, d time.Duration) Option {
+	return option(func(o *options) {
+		o.transition = t
+		o.duration = d
+	})
+}
+
+// OnSwitch sets a function that is called with the name of the page each
+// time a different page is displayed. The function is called synchronously
+// from SwitchTo after the layout was updated, it must not block.
+func OnSwitch(fn func(name string)) Option {
+	return option(func(o *options) {
+		o.onSwitch = fn
+	})
+}
+
+// FrameInterval is the time between frames of the transition animations.
+const FrameInterval = 40 * time.Millisecond
+
+// frameInterval can be replaced in tests.
+var frameInterval = FrameInterval
+
+// timeNow can be replaced in tests.
+var timeNow = time.Now
+
+// Pages switches a container between the registered pages.
+//
+// This object is thread-safe.
+type Pages struct {
+	// cont is the container and id identifies the container whose content
+	// the pages replace.
+	cont *container.Container
+	id   string
+
+	// current is the index of the displayed page.
+	current int
+
+	// gen counts the switches of pages, an animation stops once a newer
+	// switch happens.
+	gen int
+
+	// navBars are the created NavBar widgets.
+	navBars []*NavBar
+
+	// mu protects the state of the Pages.
+	mu sync.Mutex
+	// switchMu serializes updates of the container, it is held while the
+	// container is updated, unlike mu which is also acquired by NavBar
+	// widgets while the container is being drawn.
+	switchMu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns new Pages that replace the content of the container with the
+// specified id in the tree of containers rooted at the provided container.
+// Displays the first page immediately.
+func New(c *container.Container, id string, opts ...Option) (*Pages, error) {
+	opt := &options{}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	p := &Pages{
+		cont: c,
+		id:   id,
+		opts: opt,
+	}
+	if err := p.cont.Update(p.id, layout(opt.pages[0])...); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// fill is a split size that makes the first container take all the space.
+const fill = math.MaxInt32
+
+// layout returns the options that display the page.
+func layout(pg *page) []container.Option {
+	return []container.Option{
+		container.SplitHorizontal(
+			container.Top(pg.opts...),
+			container.Bottom(container.NoFocus()),
+			container.SplitFixed(fill),
+		),
+	}
+}
+
+// frame returns the options that display the page partially revealed by the
+// transition. The progress is in the range 0 < progress < 1.
+func frame(pg *page, t Transition, progress float64) []container.Option {
+	perc := int(math.Round(progress * 100))
+	if perc < 1 {
+		perc = 1
+	} else if perc > 99 {
+		perc = 99
+	}
+
+	blank := []container.Option{container.NoFocus()}
+	var split container.Option
+	switch t {
+	case TransitionWipeLeft:
+		split = container.SplitVertical(container.Left(blank...), container.Right(pg.opts...), container.SplitPercent(100-perc))
+	case TransitionWipeRight:
+		split = container.SplitVertical(container.Left(pg.opts...), container.Right(blank...), container.SplitPercent(perc))
+	case TransitionWipeUp:
+		split = container.SplitHorizontal(container.Top(blank...), container.Bottom(pg.opts...), container.SplitPercent(100-perc))
+	default:
+		split = container.SplitHorizontal(container.Top(pg.opts...), container.Bottom(blank...), container.SplitPercent(perc))
+	}
+	return []container.Option{split}
+}
+
+// Names returns the names of the pages in the order they were registered.
+func (p *Pages) Names() []string {
+	var names []string
+	for _, pg := range p.opts.pages {
+		names = append(names, pg.name)
+	}
+	return names
+}
+
+// Current returns the name of the displayed page.
+func (p *Pages) Current() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.opts.pages[p.current].name
+}
+
+// SwitchTo displays the page with the name. Does nothing if the page is
+// already displayed.
+func (p *Pages) SwitchTo(name string) error {
+	for i, pg := range p.opts.pages {
+		if pg.name == name {
+			return p.switchTo(i)
+		}
+	}
+	return fmt.Errorf("no page named %q", name)
+}
+
+// Next displays the page registered after the displayed one, wrapping around
+// to the first page.
+func (p *Pages) Next() error {
+	return p.step(1)
+}
+
+// Previous displays the page registered before the displayed one, wrapping
+// around to the last page.
+func (p *Pages) Previous() error {
+	return p.step(-1)
+}
+
+// step displays the page at the offset from the displayed one.
+func (p *Pages) step(offset int) error {
+	p.mu.Lock()
+	count := len(p.opts.pages)
+	next := ((p.current+offset)%count + count) % count
+	p.mu.Unlock()
+	return p.switchTo(next)
+}
+
+// switchTo displays the page with the index.
+func (p *Pages) switchTo(i int) error {
+	p.switchMu.Lock()
+	p.mu.Lock()
+	if i == p.current {
+		p.mu.Unlock()
+		p.switchMu.Unlock()
+		return nil
+	}
+	pg := p.opts.pages[i]
+	animate := p.opts.transition != TransitionNone
+	p.mu.Unlock()
+
+	opts := layout(pg)
+	if animate {
+		opts = frame(pg, p.opts.transition, 0)
+	}
+	if err := p.cont.Update(p.id, opts...); err != nil {
+		p.switchMu.Unlock()
+		return err
+	}
+
+	p.mu.Lock()
+	p.current = i
+	p.gen++
+	gen := p.gen
+	navBars := append([]*NavBar(nil), p.navBars...)
+	p.mu.Unlock()
+	p.switchMu.Unlock()
+
+	if animate {
+		go p.animate(pg, gen) // Stops when done or on the next switch.
+	}
+	for _, nb := range navBars {
+		nb.changed()
+	}
+	if p.opts.onSwitch != nil {
+		p.opts.onSwitch(pg.name)
+	}
+	return nil
+}
+
+// animate displays the frames of the transition to the page until the
+// transition completes or a newer switch happens.
+func (p *Pages) animate(pg *page, gen int) {
+	start := timeNow()
+	ticker := time.NewTicker(frameInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		progress := float64(timeNow().Sub(start)) / float64(p.opts.duration)
+		opts := frame(pg, p.opts.transition, progress)
+		if progress >= 1 {
+			opts = layout(pg)
+		}
+
+		p.switchMu.Lock()
+		p.mu.Lock()
+		stale := p.gen != gen
+		p.mu.Unlock()
+		if !stale {
+			// The frames only differ from the first one in the size of
+			// the split, which SwitchTo already applied successfully.
+			p.cont.Update(p.id, opts...)
+		}
+		p.switchMu.Unlock()
+
+		if stale || progress >= 1 {
+			return
+		}
+	}
+}
+
+// Bind binds the key sequences that display the next and the previous page
+// in the registry, e.g. one provided to termdash.KeyBindings.
+func (p *Pages) Bind(r *keybinding.Registry, next, prev keybinding.Sequence) error {
+	if err := r.Bind(next, p.Next, keybinding.Description("Next page")); err != nil {
+		return err
+	}
+	return r.Bind(prev, p.Previous, keybinding.Description("Previous page"))
+}
@@ -0,0 +1,496 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pages
+
+import (
+	"image"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// fillWidget fills its canvas with a rune.
+type fillWidget struct {
+	r rune
+}
+
+// Draw implements widgetapi.Widget.Draw.
+func (fw *fillWidget) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	return cvs.SetAreaCells(cvs.Area(), fw.r)
+}
+
+// Keyboard implements widgetapi.Widget.Keyboard.
+func (*fillWidget) Keyboard(k *terminalapi.Keyboard) error {
+	return nil
+}
+
+// Mouse implements widgetapi.Widget.Mouse.
+func (*fillWidget) Mouse(m *terminalapi.Mouse) error {
+	return nil
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*fillWidget) Options() widgetapi.Options {
+	return widgetapi.Options{}
+}
+
+// rows returns the content of the terminal as rows of text, the zero runes
+// are replaced by spaces.
+func rows(ft *faketerm.Terminal) []string {
+	b := ft.BackBuffer()
+	size := b.Size()
+	var res []string
+	for y := 0; y < size.Y; y++ {
+		var row []rune
+		for x := 0; x < size.X; x++ {
+			r := b[x][y].Rune
+			if r == 0 {
+				r = ' '
+			}
+			row = append(row, r)
+		}
+		res = append(res, string(row))
+	}
+	return res
+}
+
+// testPages returns a terminal and a container with a navigation bar on the
+// top row and pages "a", "b" and "c" below it that fill the area with their
+// name.
+func testPages(t *testing.T, opts ...Option) (*faketerm.Terminal, *container.Container, *Pages) {
+	t.Helper()
+	ft := faketerm.MustNew(image.Point{12, 3})
+	c, err := container.New(
+		ft,
+		container.SplitHorizontal(
+			container.Top(container.ID("nav")),
+			container.Bottom(container.ID("pages")),
+			container.SplitFixed(1),
+		),
+	)
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+
+	all := []Option{
+		Page("a", container.PlaceWidget(&fillWidget{'a'})),
+		Page("b", container.PlaceWidget(&fillWidget{'b'})),
+		Page("c", container.PlaceWidget(&fillWidget{'c'})),
+	}
+	p, err := New(c, "pages", append(all, opts...)...)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := c.Update("nav", container.PlaceWidget(p.NavBar())); err != nil {
+		t.Fatalf("Update => unexpected error: %v", err)
+	}
+	return ft, c, p
+}
+
+// mustDraw draws the container and returns the rows of the terminal.
+func mustDraw(t *testing.T, ft *faketerm.Terminal, c *container.Container) []string {
+	t.Helper()
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	return rows(ft)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		desc    string
+		id      string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			desc:    "fails without pages",
+			id:      "pages",
+			wantErr: true,
+		},
+		{
+			desc:    "fails on empty page name",
+			id:      "pages",
+			opts:    []Option{Page("")},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on duplicate page name",
+			id:      "pages",
+			opts:    []Option{Page("a"), Page("a")},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unsupported transition",
+			id:      "pages",
+			opts:    []Option{Page("a"), Animate(Transition(-1), time.Second)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on transition without duration",
+			id:      "pages",
+			opts:    []Option{Page("a"), Animate(TransitionWipeLeft, 0)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unknown container",
+			id:      "unknown",
+			opts:    []Option{Page("a")},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on invalid page options",
+			id:      "pages",
+			opts:    []Option{Page("a", container.MarginTop(-1))},
+			wantErr: true,
+		},
+		{
+			desc: "succeeds",
+			id:   "pages",
+			opts: []Option{Page("a"), Page("b"), Animate(TransitionWipeLeft, time.Second)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			c, err := container.New(faketerm.MustNew(image.Point{10, 10}), container.ID("pages"))
+			if err != nil {
+				t.Fatalf("container.New => unexpected error: %v", err)
+			}
+			_, err = New(c, tc.id, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSwitching(t *testing.T) {
+	tests := []struct {
+		desc        string
+		action      func(*Pages) error
+		wantErr     bool
+		wantCurrent string
+		want        []string
+	}{
+		{
+			desc:        "displays the first page initially",
+			action:      func(p *Pages) error { return nil },
+			wantCurrent: "a",
+			want: []string{
+				" a   b   c  ",
+				"aaaaaaaaaaaa",
+				"aaaaaaaaaaaa",
+			},
+		},
+		{
+			desc:        "switches to the named page",
+			action:      func(p *Pages) error { return p.SwitchTo("c") },
+			wantCurrent: "c",
+			want: []string{
+				" a   b   c  ",
+				"cccccccccccc",
+				"cccccccccccc",
+			},
+		},
+		{
+			desc:        "fails on unknown page",
+			action:      func(p *Pages) error { return p.SwitchTo("d") },
+			wantErr:     true,
+			wantCurrent: "a",
+		},
+		{
+			desc:        "next page",
+			action:      func(p *Pages) error { return p.Next() },
+			wantCurrent: "b",
+		},
+		{
+			desc: "next page wraps around",
+			action: func(p *Pages) error {
+				if err := p.SwitchTo("c"); err != nil {
+					return err
+				}
+				return p.Next()
+			},
+			wantCurrent: "a",
+		},
+		{
+			desc:        "previous page wraps around",
+			action:      func(p *Pages) error { return p.Previous() },
+			wantCurrent: "c",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ft, c, p := testPages(t)
+			err := tc.action(p)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("action => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if got := p.Current(); got != tc.wantCurrent {
+				t.Errorf("Current => %q, want %q", got, tc.wantCurrent)
+			}
+			if tc.want == nil {
+				return
+			}
+			if diff := pretty.Compare(tc.want, mustDraw(t, ft, c)); diff != "" {
+				t.Errorf("Draw => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPagesDontShareOptions(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{4, 2})
+	c, err := container.New(ft, container.ID("pages"))
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+	p, err := New(c, "pages",
+		Page("margin", container.MarginLeft(2), container.PlaceWidget(&fillWidget{'a'})),
+		Page("plain", container.PlaceWidget(&fillWidget{'b'})),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := p.SwitchTo("plain"); err != nil {
+		t.Fatalf("SwitchTo => unexpected error: %v", err)
+	}
+
+	want := []string{
+		"bbbb",
+		"bbbb",
+	}
+	if diff := pretty.Compare(want, mustDraw(t, ft, c)); diff != "" {
+		t.Errorf("Draw => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestOnSwitch(t *testing.T) {
+	var got []string
+	_, _, p := testPages(t, OnSwitch(func(name string) {
+		got = append(got, name)
+	}))
+	for _, name := range []string{"b", "b", "c"} {
+		if err := p.SwitchTo(name); err != nil {
+			t.Fatalf("SwitchTo => unexpected error: %v", err)
+		}
+	}
+
+	want := []string{"b", "c"}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("OnSwitch => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBind(t *testing.T) {
+	_, _, p := testPages(t)
+	r, err := keybinding.New()
+	if err != nil {
+		t.Fatalf("keybinding.New => unexpected error: %v", err)
+	}
+	if err := p.Bind(r, keybinding.Sequence{keyboard.KeyTab}, keybinding.Sequence{keyboard.KeyCtrlP}); err != nil {
+		t.Fatalf("Bind => unexpected error: %v", err)
+	}
+
+	for _, k := range []keyboard.Key{keyboard.KeyTab, keyboard.KeyTab, keyboard.KeyCtrlP} {
+		if _, err := r.Keyboard(&terminalapi.Keyboard{Key: k}); err != nil {
+			t.Fatalf("Keyboard => unexpected error: %v", err)
+		}
+	}
+	if got, want := p.Current(), "b"; got != want {
+		t.Errorf("Current => %q, want %q", got, want)
+	}
+	if err := p.Bind(r, keybinding.Sequence{keyboard.KeyTab}, keybinding.Sequence{keyboard.KeyCtrlN}); err == nil {
+		t.Errorf("Bind => got nil error on conflicting sequence, want an error")
+	}
+}
+
+func TestNavBarMouse(t *testing.T) {
+	tests := []struct {
+		desc        string
+		events      []*terminalapi.Mouse
+		wantCurrent string
+	}{
+		{
+			desc: "click on a name displays the page",
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{9, 0}, Button: mouse.ButtonLeft},
+				{Position: image.Point{9, 0}, Button: mouse.ButtonRelease},
+			},
+			wantCurrent: "c",
+		},
+		{
+			desc: "click between names is ignored",
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{3, 0}, Button: mouse.ButtonLeft},
+			},
+			wantCurrent: "a",
+		},
+		{
+			desc: "wheel displays the next and previous pages",
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{0, 0}, Button: mouse.ButtonWheelDown},
+				{Position: image.Point{0, 0}, Button: mouse.ButtonWheelDown},
+				{Position: image.Point{0, 0}, Button: mouse.ButtonWheelUp},
+			},
+			wantCurrent: "b",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ft, c, p := testPages(t)
+			mustDraw(t, ft, c)
+			for _, ev := range tc.events {
+				if err := c.Inject(ev); err != nil {
+					t.Fatalf("Inject => unexpected error: %v", err)
+				}
+			}
+			if got := p.Current(); got != tc.wantCurrent {
+				t.Errorf("Current => %q, want %q", got, tc.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestNavBarNotifies(t *testing.T) {
+	_, _, p := testPages(t)
+	nb := p.NavBar()
+	var mu sync.Mutex
+	notified := 0
+	nb.SetNotifyFunc(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		notified++
+	})
+	if err := p.Next(); err != nil {
+		t.Fatalf("Next => unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if notified != 1 {
+		t.Errorf("notified %d times, want 1", notified)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	tests := []struct {
+		transition Transition
+		progress   float64
+		want       []string
+	}{
+		{
+			transition: TransitionWipeLeft,
+			progress:   0.5,
+			want: []string{
+				"   bbb",
+				"   bbb",
+			},
+		},
+		{
+			transition: TransitionWipeRight,
+			progress:   0.5,
+			want: []string{
+				"bbb   ",
+				"bbb   ",
+			},
+		},
+		{
+			transition: TransitionWipeUp,
+			progress:   0.5,
+			want: []string{
+				"      ",
+				"bbbbbb",
+			},
+		},
+		{
+			transition: TransitionWipeDown,
+			progress:   0.5,
+			want: []string{
+				"bbbbbb",
+				"      ",
+			},
+		},
+		{
+			transition: TransitionWipeDown,
+			progress:   0,
+			want: []string{
+				"      ",
+				"      ",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.transition.String(), func(t *testing.T) {
+			ft := faketerm.MustNew(image.Point{6, 2})
+			c, err := container.New(ft, container.ID("pages"))
+			if err != nil {
+				t.Fatalf("container.New => unexpected error: %v", err)
+			}
+			pg := &page{name: "b", opts: []container.Option{container.PlaceWidget(&fillWidget{'b'})}}
+			if err := c.Update("pages", frame(pg, tc.transition, tc.progress)...); err != nil {
+				t.Fatalf("Update => unexpected error: %v", err)
+			}
+			if diff := pretty.Compare(tc.want, mustDraw(t, ft, c)); diff != "" {
+				t.Errorf("frame => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAnimate(t *testing.T) {
+	defer func(fi time.Duration) { frameInterval = fi }(frameInterval)
+	frameInterval = time.Millisecond
+
+	ft, c, p := testPages(t, Animate(TransitionWipeRight, 20*time.Millisecond))
+	if err := p.SwitchTo("b"); err != nil {
+		t.Fatalf("SwitchTo => unexpected error: %v", err)
+	}
+	if got := mustDraw(t, ft, c); strings.Contains(got[1], "a") {
+		t.Errorf("Draw => %q, the previous page is still displayed", got)
+	}
+
+	want := []string{
+		" a   b   c  ",
+		"bbbbbbbbbbbb",
+		"bbbbbbbbbbbb",
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := mustDraw(t, ft, c)
+		if pretty.Compare(want, got) == "" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Draw => %q, the animation didn't complete, want %q", got, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
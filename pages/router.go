@@ -0,0 +1,388 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pages
+
+// router.go contains a router that builds pages from paths and keeps the
+// navigation history.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/keybinding"
+)
+
+// Params are the values of the parameters in a route pattern, keyed by the
+// parameter name without the colon.
+type Params map[string]string
+
+// Builder returns the container options of the layout for a route. The
+// params contain the values the path provided for the parameters in the
+// route pattern.
+type Builder func(params Params) ([]container.Option, error)
+
+// RouterOption is used to provide options to NewRouter().
+type RouterOption interface {
+	// set sets the provided option.
+	set(*routerOptions)
+}
+
+// route is a pattern and the builder of its layout.
+type route struct {
+	pattern  string
+	segments []string
+	build    Builder
+}
+
+// match returns the parameters if the path segments match the route.
+func (rt *route) match(segments []string) (Params, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+	params := Params{}
+	for i, s := range rt.segments {
+		if strings.HasPrefix(s, ":") {
+			params[s[1:]] = segments[i]
+			continue
+		}
+		if s != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// routerOptions stores the provided options.
+type routerOptions struct {
+	routes       []*route
+	initialPath  string
+	historyLimit int
+	onNavigate   func(path string, params Params)
+}
+
+// validate validates the provided options.
+func (o *routerOptions) validate() error {
+	if len(o.routes) == 0 {
+		return errors.New("at least one Route must be provided")
+	}
+	seen := map[string]bool{}
+	for _, rt := range o.routes {
+		if rt.build == nil {
+			return fmt.Errorf("the route %q must have a builder", rt.pattern)
+		}
+		params := map[string]bool{}
+		var key []string
+		for _, s := range rt.segments {
+			if !strings.HasPrefix(s, ":") {
+				key = append(key, s)
+				continue
+			}
+			if s == ":" {
+				return fmt.Errorf("the route %q has a parameter without a name", rt.pattern)
+			}
+			if params[s] {
+				return fmt.Errorf("the route %q has a duplicate parameter %q", rt.pattern, s)
+			}
+			params[s] = true
+			key = append(key, ":")
+		}
+		k := strings.Join(key, "/")
+		if seen[k] {
+			return fmt.Errorf("the route %q matches the same paths as a route registered before it", rt.pattern)
+		}
+		seen[k] = true
+	}
+	if min := 1; o.historyLimit < min {
+		return fmt.Errorf("invalid HistoryLimit(%d), must be at least %d", o.historyLimit, min)
+	}
+	return nil
+}
+
+// routerOption implements RouterOption.
+type routerOption func(*routerOptions)
+
+// set implements RouterOption.set.
+func (o routerOption) set(opts *routerOptions) {
+	o(opts)
+}
+
+// Route registers a route with the pattern and the builder of its layout.
+// The pattern is a slash separated path, segments starting with a colon are
+// parameters that match any value, e.g. "/hosts/:name" matches "/hosts/web1"
+// and builds the layout with Params{"name": "web1"}.
+// The layout is built again each time the route is navigated to, including
+// by Back and Forward.
+func Route(pattern string, build Builder) RouterOption {
+	return routerOption(func(o *routerOptions) {
+		o.routes = append(o.routes, &route{
+			pattern:  pattern,
+			segments: splitPath(pattern),
+			build:    build,
+		})
+	})
+}
+
+// InitialPath sets the path displayed by NewRouter, e.g. a deep link
+// provided on the command line.
+// Defaults to "/".
+func InitialPath(path string) RouterOption {
+	return routerOption(func(o *routerOptions) {
+		o.initialPath = path
+	})
+}
+
+// DefaultHistoryLimit is the default value for the HistoryLimit option.
+const DefaultHistoryLimit = 100
+
+// HistoryLimit sets the maximum number of paths remembered in the navigation
+// history, the oldest paths are forgotten first.
+// Defaults to DefaultHistoryLimit.
+func HistoryLimit(n int) RouterOption {
+	return routerOption(func(o *routerOptions) {
+		o.historyLimit = n
+	})
+}
+
+// OnNavigate sets a function that is called with the path and the parameters
+// each time a path is displayed. The function is called synchronously from
+// Navigate, Back and Forward after the layout was updated, it must not block.
+func OnNavigate(fn func(path string, params Params)) RouterOption {
+	return routerOption(func(o *routerOptions) {
+		o.onNavigate = fn
+	})
+}
+
+// splitPath splits the path into its segments, ignoring empty segments.
+func splitPath(path string) []string {
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// cleanPath returns the canonical form of the path.
+func cleanPath(path string) string {
+	return "/" + strings.Join(splitPath(path), "/")
+}
+
+// Router displays layouts built for paths in a container and keeps the
+// history of the navigation.
+//
+// This object is thread-safe.
+type Router struct {
+	// cont is the container and id identifies the container whose content
+	// the router replaces.
+	cont *container.Container
+	id   string
+
+	// history are the visited paths and pos is the index of the displayed
+	// one.
+	history []string
+	pos     int
+
+	// params are the parameters of the displayed path.
+	params Params
+
+	// crumbs are the created Breadcrumbs widgets.
+	crumbs []*Breadcrumbs
+
+	// mu protects the state of the Router.
+	mu sync.Mutex
+	// navMu serializes updates of the container, see Pages.switchMu.
+	navMu sync.Mutex
+
+	// opts are the provided options.
+	opts *routerOptions
+}
+
+// NewRouter returns a new Router that replaces the content of the container
+// with the specified id in the tree of containers rooted at the provided
+// container. Displays the initial path immediately.
+func NewRouter(c *container.Container, id string, opts ...RouterOption) (*Router, error) {
+	opt := &routerOptions{
+		initialPath:  "/",
+		historyLimit: DefaultHistoryLimit,
+	}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	r := &Router{
+		cont: c,
+		id:   id,
+		opts: opt,
+	}
+	if err := r.Navigate(opt.initialPath); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// resolve returns the route that matches the path and its parameters.
+func (r *Router) resolve(path string) (*route, Params, error) {
+	segments := splitPath(path)
+	for _, rt := range r.opts.routes {
+		if params, ok := rt.match(segments); ok {
+			return rt, params, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no route matches the path %q", path)
+}
+
+// Matches asserts whether a route matches the path.
+func (r *Router) Matches(path string) bool {
+	_, _, err := r.resolve(path)
+	return err == nil
+}
+
+// Path returns the displayed path.
+func (r *Router) Path() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.history[r.pos]
+}
+
+// Params returns a copy of the parameters of the displayed path.
+func (r *Router) Params() Params {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	params := Params{}
+	for k, v := range r.params {
+		params[k] = v
+	}
+	return params
+}
+
+// CanBack asserts whether there is a path to go back to in the history.
+func (r *Router) CanBack() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pos > 0
+}
+
+// CanForward asserts whether there is a path to go forward to in the
+// history.
+func (r *Router) CanForward() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pos < len(r.history)-1
+}
+
+// Navigate displays the path and records it in the history, forgetting any
+// paths Back went back from. Does nothing if the path is already displayed.
+// Returns an error if no route matches the path or its builder fails, the
+// displayed path doesn't change in that case.
+func (r *Router) Navigate(path string) error {
+	path = cleanPath(path)
+	return r.display(path, func() {
+		if len(r.history) > 0 {
+			r.history = r.history[:r.pos+1]
+		}
+		r.history = append(r.history, path)
+		if over := len(r.history) - r.opts.historyLimit; over > 0 {
+			r.history = r.history[over:]
+		}
+		r.pos = len(r.history) - 1
+	})
+}
+
+// Back displays the previous path in the history. Does nothing if there is
+// no such path.
+func (r *Router) Back() error {
+	return r.step(-1)
+}
+
+// Forward displays the next path in the history, i.e. the one Back went
+// back from. Does nothing if there is no such path.
+func (r *Router) Forward() error {
+	return r.step(1)
+}
+
+// step displays the path at the offset from the displayed one in the
+// history.
+func (r *Router) step(offset int) error {
+	r.mu.Lock()
+	pos := r.pos + offset
+	if pos < 0 || pos >= len(r.history) {
+		r.mu.Unlock()
+		return nil
+	}
+	path := r.history[pos]
+	r.mu.Unlock()
+
+	return r.display(path, func() {
+		r.pos = pos
+	})
+}
+
+// display builds and displays the layout for the path and then updates the
+// history with the provided function.
+func (r *Router) display(path string, record func()) error {
+	r.navMu.Lock()
+	r.mu.Lock()
+	if len(r.history) > 0 && r.history[r.pos] == path {
+		r.mu.Unlock()
+		r.navMu.Unlock()
+		return nil
+	}
+	r.mu.Unlock()
+
+	rt, params, err := r.resolve(path)
+	if err != nil {
+		r.navMu.Unlock()
+		return err
+	}
+	opts, err := rt.build(params)
+	if err != nil {
+		r.navMu.Unlock()
+		return fmt.Errorf("the builder of route %q failed for path %q: %v", rt.pattern, path, err)
+	}
+	if err := r.cont.Update(r.id, layout(&page{name: path, opts: opts})...); err != nil {
+		r.navMu.Unlock()
+		return err
+	}
+
+	r.mu.Lock()
+	record()
+	r.params = params
+	crumbs := append([]*Breadcrumbs(nil), r.crumbs...)
+	r.mu.Unlock()
+	r.navMu.Unlock()
+
+	for _, bc := range crumbs {
+		bc.changed()
+	}
+	if r.opts.onNavigate != nil {
+		r.opts.onNavigate(path, params)
+	}
+	return nil
+}
+
+// Bind binds the key sequences that go back and forward in the history in
+// the registry, e.g. one provided to termdash.KeyBindings.
+func (r *Router) Bind(reg *keybinding.Registry, back, forward keybinding.Sequence) error {
+	if err := reg.Bind(back, r.Back, keybinding.Description("Go back")); err != nil {
+		return err
+	}
+	return reg.Bind(forward, r.Forward, keybinding.Description("Go forward"))
+}
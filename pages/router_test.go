@@ -0,0 +1,481 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pages
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// testRoutes are routes whose layouts fill the area with a rune. The host
+// pages use the first letter of the host name.
+func testRoutes() []RouterOption {
+	fill := func(r rune) Builder {
+		return func(Params) ([]container.Option, error) {
+			return []container.Option{container.PlaceWidget(&fillWidget{r})}, nil
+		}
+	}
+	return []RouterOption{
+		Route("/", fill('h')),
+		Route("/hosts", fill('l')),
+		Route("/hosts/:name", func(p Params) ([]container.Option, error) {
+			if p["name"] == "bad" {
+				return nil, errors.New("bad host")
+			}
+			return []container.Option{container.PlaceWidget(&fillWidget{rune(p["name"][0])})}, nil
+		}),
+		Route("/hosts/:name/cpu/:core", fill('c')),
+	}
+}
+
+// testRouter returns a terminal and a container with breadcrumbs on the top
+// row and a router below them.
+func testRouter(t *testing.T, crumbOpts []BreadcrumbsOption, opts ...RouterOption) (*faketerm.Terminal, *container.Container, *Router) {
+	t.Helper()
+	ft := faketerm.MustNew(image.Point{20, 2})
+	c, err := container.New(
+		ft,
+		container.SplitHorizontal(
+			container.Top(container.ID("nav")),
+			container.Bottom(container.ID("body")),
+			container.SplitFixed(1),
+		),
+	)
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+
+	r, err := NewRouter(c, "body", append(testRoutes(), opts...)...)
+	if err != nil {
+		t.Fatalf("NewRouter => unexpected error: %v", err)
+	}
+	if err := c.Update("nav", container.PlaceWidget(r.Breadcrumbs(append([]BreadcrumbsOption{CrumbActiveCellOpts()}, crumbOpts...)...))); err != nil {
+		t.Fatalf("Update => unexpected error: %v", err)
+	}
+	return ft, c, r
+}
+
+// nopBuilder builds an empty layout.
+func nopBuilder(Params) ([]container.Option, error) {
+	return nil, nil
+}
+
+func TestNewRouter(t *testing.T) {
+	tests := []struct {
+		desc    string
+		id      string
+		opts    []RouterOption
+		wantErr bool
+	}{
+		{
+			desc:    "fails without routes",
+			id:      "body",
+			wantErr: true,
+		},
+		{
+			desc:    "fails on a route without a builder",
+			id:      "body",
+			opts:    []RouterOption{Route("/", nil)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on a parameter without a name",
+			id:      "body",
+			opts:    []RouterOption{Route("/hosts/:", nopBuilder)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on a duplicate parameter",
+			id:      "body",
+			opts:    []RouterOption{Route("/:a/:a", nopBuilder)},
+			wantErr: true,
+		},
+		{
+			desc: "fails on routes that match the same paths",
+			id:   "body",
+			opts: []RouterOption{
+				Route("/", nopBuilder),
+				Route("/hosts/:name", nopBuilder),
+				Route("/hosts/:host", nopBuilder),
+			},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on invalid history limit",
+			id:      "body",
+			opts:    []RouterOption{Route("/", nopBuilder), HistoryLimit(0)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails when no route matches the initial path",
+			id:      "body",
+			opts:    []RouterOption{Route("/hosts", nopBuilder)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails when the builder fails",
+			id:      "body",
+			opts:    append(testRoutes(), InitialPath("/hosts/bad")),
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unknown container",
+			id:      "unknown",
+			opts:    []RouterOption{Route("/", nopBuilder)},
+			wantErr: true,
+		},
+		{
+			desc: "succeeds with a deep link",
+			id:   "body",
+			opts: append(testRoutes(), InitialPath("hosts/web1/cpu/0/")),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			c, err := container.New(faketerm.MustNew(image.Point{10, 10}), container.ID("body"))
+			if err != nil {
+				t.Fatalf("container.New => unexpected error: %v", err)
+			}
+			_, err = NewRouter(c, tc.id, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("NewRouter => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	tests := []struct {
+		desc        string
+		opts        []RouterOption
+		action      func(*Router) error
+		wantErr     bool
+		wantPath    string
+		wantParams  Params
+		wantBack    bool
+		wantForward bool
+		want        []string
+	}{
+		{
+			desc:       "displays the initial path",
+			action:     func(*Router) error { return nil },
+			wantPath:   "/",
+			wantParams: Params{},
+			want: []string{
+				"Home                ",
+				"hhhhhhhhhhhhhhhhhhhh",
+			},
+		},
+		{
+			desc: "navigates to a path with parameters",
+			action: func(r *Router) error {
+				return r.Navigate("/hosts/web1")
+			},
+			wantPath:   "/hosts/web1",
+			wantParams: Params{"name": "web1"},
+			wantBack:   true,
+			want: []string{
+				"Home > hosts > web1 ",
+				"wwwwwwwwwwwwwwwwwwww",
+			},
+		},
+		{
+			desc: "fails on path without a route",
+			action: func(r *Router) error {
+				return r.Navigate("/unknown")
+			},
+			wantErr:    true,
+			wantPath:   "/",
+			wantParams: Params{},
+			want: []string{
+				"Home                ",
+				"hhhhhhhhhhhhhhhhhhhh",
+			},
+		},
+		{
+			desc: "fails when the builder fails",
+			action: func(r *Router) error {
+				return r.Navigate("/hosts/bad")
+			},
+			wantErr:    true,
+			wantPath:   "/",
+			wantParams: Params{},
+			want: []string{
+				"Home                ",
+				"hhhhhhhhhhhhhhhhhhhh",
+			},
+		},
+		{
+			desc: "goes back and forward",
+			action: func(r *Router) error {
+				for _, p := range []string{"/hosts", "/hosts/db"} {
+					if err := r.Navigate(p); err != nil {
+						return err
+					}
+				}
+				if err := r.Back(); err != nil {
+					return err
+				}
+				if err := r.Back(); err != nil {
+					return err
+				}
+				return r.Forward()
+			},
+			wantPath:    "/hosts",
+			wantParams:  Params{},
+			wantBack:    true,
+			wantForward: true,
+			want: []string{
+				"Home > hosts        ",
+				"llllllllllllllllllll",
+			},
+		},
+		{
+			desc: "navigating forgets the paths gone back from",
+			action: func(r *Router) error {
+				for _, p := range []string{"/hosts", "/hosts/db"} {
+					if err := r.Navigate(p); err != nil {
+						return err
+					}
+				}
+				if err := r.Back(); err != nil {
+					return err
+				}
+				if err := r.Navigate("/hosts/app"); err != nil {
+					return err
+				}
+				return r.Forward()
+			},
+			wantPath:   "/hosts/app",
+			wantParams: Params{"name": "app"},
+			wantBack:   true,
+			want: []string{
+				"Home > hosts > app  ",
+				"aaaaaaaaaaaaaaaaaaaa",
+			},
+		},
+		{
+			desc: "back and forward do nothing at the ends of history",
+			action: func(r *Router) error {
+				if err := r.Back(); err != nil {
+					return err
+				}
+				return r.Forward()
+			},
+			wantPath:   "/",
+			wantParams: Params{},
+			want: []string{
+				"Home                ",
+				"hhhhhhhhhhhhhhhhhhhh",
+			},
+		},
+		{
+			desc: "navigating to the displayed path doesn't add to history",
+			action: func(r *Router) error {
+				return r.Navigate("//")
+			},
+			wantPath:   "/",
+			wantParams: Params{},
+			want: []string{
+				"Home                ",
+				"hhhhhhhhhhhhhhhhhhhh",
+			},
+		},
+		{
+			desc: "forgets the oldest paths over the history limit",
+			opts: []RouterOption{HistoryLimit(2)},
+			action: func(r *Router) error {
+				for _, p := range []string{"/hosts", "/hosts/db"} {
+					if err := r.Navigate(p); err != nil {
+						return err
+					}
+				}
+				for i := 0; i < 3; i++ {
+					if err := r.Back(); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			wantPath:    "/hosts",
+			wantParams:  Params{},
+			wantForward: true,
+			want: []string{
+				"Home > hosts        ",
+				"llllllllllllllllllll",
+			},
+		},
+		{
+			desc: "trims long paths",
+			action: func(r *Router) error {
+				return r.Navigate("/hosts/web1/cpu/0")
+			},
+			wantPath:   "/hosts/web1/cpu/0",
+			wantParams: Params{"name": "web1", "core": "0"},
+			wantBack:   true,
+			want: []string{
+				"Home > hosts > web1…",
+				"cccccccccccccccccccc",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ft, c, r := testRouter(t, nil, tc.opts...)
+			err := tc.action(r)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("action => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if got := r.Path(); got != tc.wantPath {
+				t.Errorf("Path => %q, want %q", got, tc.wantPath)
+			}
+			if diff := pretty.Compare(tc.wantParams, r.Params()); diff != "" {
+				t.Errorf("Params => unexpected diff (-want, +got):\n%s", diff)
+			}
+			if got := r.CanBack(); got != tc.wantBack {
+				t.Errorf("CanBack => %v, want %v", got, tc.wantBack)
+			}
+			if got := r.CanForward(); got != tc.wantForward {
+				t.Errorf("CanForward => %v, want %v", got, tc.wantForward)
+			}
+			if diff := pretty.Compare(tc.want, mustDraw(t, ft, c)); diff != "" {
+				t.Errorf("Draw => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOnNavigate(t *testing.T) {
+	var got []string
+	_, _, r := testRouter(t, nil, OnNavigate(func(path string, params Params) {
+		got = append(got, path+" "+params["name"])
+	}))
+	if err := r.Navigate("/hosts/web1"); err != nil {
+		t.Fatalf("Navigate => unexpected error: %v", err)
+	}
+	if err := r.Back(); err != nil {
+		t.Fatalf("Back => unexpected error: %v", err)
+	}
+	want := []string{"/ ", "/hosts/web1 web1", "/ "}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("OnNavigate => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestRouterBind(t *testing.T) {
+	_, _, r := testRouter(t, nil)
+	reg, err := keybinding.New()
+	if err != nil {
+		t.Fatalf("keybinding.New => unexpected error: %v", err)
+	}
+	if err := r.Bind(reg, keybinding.Sequence{keyboard.KeyCtrlB}, keybinding.Sequence{keyboard.KeyCtrlF}); err != nil {
+		t.Fatalf("Bind => unexpected error: %v", err)
+	}
+	if err := r.Navigate("/hosts"); err != nil {
+		t.Fatalf("Navigate => unexpected error: %v", err)
+	}
+
+	for _, k := range []keyboard.Key{keyboard.KeyCtrlB, keyboard.KeyCtrlF, keyboard.KeyCtrlB} {
+		if _, err := reg.Keyboard(&terminalapi.Keyboard{Key: k}); err != nil {
+			t.Fatalf("Keyboard => unexpected error: %v", err)
+		}
+	}
+	if got, want := r.Path(), "/"; got != want {
+		t.Errorf("Path => %q, want %q", got, want)
+	}
+}
+
+func TestBreadcrumbsMouse(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []BreadcrumbsOption
+		path     string
+		events   []*terminalapi.Mouse
+		wantPath string
+	}{
+		{
+			desc: "click on a crumb navigates to its path",
+			path: "/hosts/web1/cpu/0",
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{8, 0}, Button: mouse.ButtonLeft},
+				{Position: image.Point{8, 0}, Button: mouse.ButtonRelease},
+			},
+			wantPath: "/hosts",
+		},
+		{
+			desc: "click on the root crumb",
+			path: "/hosts/web1",
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{0, 0}, Button: mouse.ButtonLeft},
+			},
+			wantPath: "/",
+		},
+		{
+			desc: "click on a separator is ignored",
+			path: "/hosts/web1",
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{5, 0}, Button: mouse.ButtonLeft},
+			},
+			wantPath: "/hosts/web1",
+		},
+		{
+			desc: "click on a crumb without a route is ignored",
+			opts: []BreadcrumbsOption{CrumbRootLabel("~"), CrumbSeparator("/")},
+			path: "/hosts/web1/cpu/0",
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{13, 0}, Button: mouse.ButtonLeft},
+			},
+			wantPath: "/hosts/web1/cpu/0",
+		},
+		{
+			desc: "click on a crumb with custom separator",
+			opts: []BreadcrumbsOption{CrumbRootLabel("~"), CrumbSeparator("/")},
+			path: "/hosts/web1/cpu/0",
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{8, 0}, Button: mouse.ButtonLeft},
+			},
+			wantPath: "/hosts/web1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ft, c, r := testRouter(t, tc.opts)
+			if err := r.Navigate(tc.path); err != nil {
+				t.Fatalf("Navigate => unexpected error: %v", err)
+			}
+			mustDraw(t, ft, c)
+			for _, ev := range tc.events {
+				if err := c.Inject(ev); err != nil {
+					t.Fatalf("Inject => unexpected error: %v", err)
+				}
+			}
+			if got := r.Path(); got != tc.wantPath {
+				t.Errorf("Path => %q, want %q", got, tc.wantPath)
+			}
+		})
+	}
+}
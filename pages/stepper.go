@@ -0,0 +1,490 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pages
+
+// stepper.go contains the widgets that display the progress of the wizard
+// and the buttons that navigate it.
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// StepIndicatorOption is used to provide options to StepIndicator().
+type StepIndicatorOption interface {
+	// set sets the provided option.
+	set(*stepIndicatorOptions)
+}
+
+// stepIndicatorOptions stores the provided options.
+type stepIndicatorOptions struct {
+	separator      string
+	cellOpts       []cell.Option
+	activeCellOpts []cell.Option
+	doneCellOpts   []cell.Option
+}
+
+// stepIndicatorOption implements StepIndicatorOption.
+type stepIndicatorOption func(*stepIndicatorOptions)
+
+// set implements StepIndicatorOption.set.
+func (o stepIndicatorOption) set(opts *stepIndicatorOptions) {
+	o(opts)
+}
+
+// StepSeparator sets the text displayed between the steps.
+// Defaults to " > ".
+func StepSeparator(sep string) StepIndicatorOption {
+	return stepIndicatorOption(func(o *stepIndicatorOptions) {
+		o.separator = sep
+	})
+}
+
+// StepCellOpts sets the cell options of the steps that follow the displayed
+// step and of the separators.
+func StepCellOpts(opts ...cell.Option) StepIndicatorOption {
+	return stepIndicatorOption(func(o *stepIndicatorOptions) {
+		o.cellOpts = opts
+	})
+}
+
+// StepActiveCellOpts sets the cell options of the displayed step.
+// Defaults to black text on white background.
+func StepActiveCellOpts(opts ...cell.Option) StepIndicatorOption {
+	return stepIndicatorOption(func(o *stepIndicatorOptions) {
+		o.activeCellOpts = opts
+	})
+}
+
+// StepDoneCellOpts sets the cell options of the steps that precede the
+// displayed step and of all the steps once the wizard is finished.
+// Defaults to green text.
+func StepDoneCellOpts(opts ...cell.Option) StepIndicatorOption {
+	return stepIndicatorOption(func(o *stepIndicatorOptions) {
+		o.doneCellOpts = opts
+	})
+}
+
+// StepIndicator displays the numbered titles of the steps of a wizard on a
+// single row and highlights the displayed step. Clicking on a step that
+// precedes the displayed one goes back to it.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type StepIndicator struct {
+	// wizard is the displayed wizard.
+	wizard *Wizard
+
+	// items are the positions of the steps as of the last call to Draw.
+	items []navItem
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the StepIndicator.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *stepIndicatorOptions
+}
+
+// StepIndicator returns a new widget that displays the progress of the
+// wizard. Place it into a container outside of the one the wizard replaces.
+func (w *Wizard) StepIndicator(opts ...StepIndicatorOption) *StepIndicator {
+	opt := &stepIndicatorOptions{
+		separator: " > ",
+		activeCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorBlack),
+			cell.BgColor(cell.ColorWhite),
+		},
+		doneCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorGreen),
+		},
+	}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	si := &StepIndicator{
+		wizard: w,
+		opts:   opt,
+	}
+	w.addWidget(si)
+	return si
+}
+
+// changed implements wizardWidget.changed.
+func (si *StepIndicator) changed() {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	if si.notify != nil {
+		si.notify()
+	}
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (si *StepIndicator) SetNotifyFunc(fn func()) {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	si.notify = fn
+}
+
+// Draw draws the StepIndicator widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (si *StepIndicator) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	si.wizard.mu.Lock()
+	current := si.wizard.current
+	finished := si.wizard.finished
+	si.wizard.mu.Unlock()
+
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	si.items = nil
+	width := cvs.Area().Dx()
+	x := 0
+	for i, title := range si.wizard.Titles() {
+		if i > 0 {
+			if x >= width {
+				break
+			}
+			if err := draw.Text(cvs, si.opts.separator, image.Point{x, 0},
+				draw.TextMaxX(width),
+				draw.TextOverrunMode(draw.OverrunModeThreeDot),
+				draw.TextCellOpts(si.opts.cellOpts...),
+			); err != nil {
+				return err
+			}
+			x += runewidth.StringWidth(si.opts.separator)
+		}
+		if x >= width {
+			break
+		}
+
+		var opts []cell.Option
+		switch {
+		case finished || i < current:
+			opts = si.opts.doneCellOpts
+		case i == current:
+			opts = si.opts.activeCellOpts
+		default:
+			opts = si.opts.cellOpts
+		}
+		label := fmt.Sprintf("%d. %s", i+1, title)
+		if err := draw.Text(cvs, label, image.Point{x, 0},
+			draw.TextMaxX(width),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(opts...),
+		); err != nil {
+			return err
+		}
+		end := x + runewidth.StringWidth(label)
+		si.items = append(si.items, navItem{start: x, end: end, index: i})
+		x = end
+	}
+	return nil
+}
+
+// Keyboard input isn't supported on the StepIndicator widget.
+func (*StepIndicator) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the StepIndicator widget doesn't support keyboard events")
+}
+
+// Mouse goes back to the clicked step.
+// Implements widgetapi.Widget.Mouse.
+func (si *StepIndicator) Mouse(m *terminalapi.Mouse) error {
+	if m.Button != mouse.ButtonLeft {
+		return nil
+	}
+
+	si.mu.Lock()
+	index := -1
+	for _, it := range si.items {
+		if m.Position.Y == 0 && m.Position.X >= it.start && m.Position.X < it.end {
+			index = it.index
+		}
+	}
+	si.mu.Unlock()
+
+	if index < 0 {
+		return nil
+	}
+	return si.wizard.backTo(index)
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*StepIndicator) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		MaximumSize:  image.Point{0, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+}
+
+// ControlsOption is used to provide options to Controls().
+type ControlsOption interface {
+	// set sets the provided option.
+	set(*controlsOptions)
+}
+
+// controlsOptions stores the provided options.
+type controlsOptions struct {
+	backLabel        string
+	nextLabel        string
+	finishLabel      string
+	cellOpts         []cell.Option
+	disabledCellOpts []cell.Option
+	errCellOpts      []cell.Option
+}
+
+// validate validates the provided options.
+func (o *controlsOptions) validate() error {
+	if o.backLabel == "" || o.nextLabel == "" || o.finishLabel == "" {
+		return errors.New("the labels of the controls cannot be empty strings")
+	}
+	return nil
+}
+
+// controlsOption implements ControlsOption.
+type controlsOption func(*controlsOptions)
+
+// set implements ControlsOption.set.
+func (o controlsOption) set(opts *controlsOptions) {
+	o(opts)
+}
+
+// ControlLabels sets the labels of the buttons that go back, continue to the
+// next step and finish the wizard on the last step.
+// Defaults to "< Back", "Next >" and "Finish".
+func ControlLabels(back, next, finish string) ControlsOption {
+	return controlsOption(func(o *controlsOptions) {
+		o.backLabel = back
+		o.nextLabel = next
+		o.finishLabel = finish
+	})
+}
+
+// ControlCellOpts sets the cell options of the buttons.
+// Defaults to black text on white background.
+func ControlCellOpts(opts ...cell.Option) ControlsOption {
+	return controlsOption(func(o *controlsOptions) {
+		o.cellOpts = opts
+	})
+}
+
+// ControlDisabledCellOpts sets the cell options of the buttons that do
+// nothing when clicked, e.g. the back button on the first step.
+// Defaults to white text on black background.
+func ControlDisabledCellOpts(opts ...cell.Option) ControlsOption {
+	return controlsOption(func(o *controlsOptions) {
+		o.disabledCellOpts = opts
+	})
+}
+
+// ControlErrorCellOpts sets the cell options of the error returned by the
+// gate that failed.
+// Defaults to red text.
+func ControlErrorCellOpts(opts ...cell.Option) ControlsOption {
+	return controlsOption(func(o *controlsOptions) {
+		o.errCellOpts = opts
+	})
+}
+
+// Controls displays the buttons that go back and continue to the next step
+// of a wizard on a single row, followed by the error of the gate that
+// stopped the user from continuing.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Controls struct {
+	// wizard is the navigated wizard.
+	wizard *Wizard
+
+	// back and next are the positions of the buttons as of the last call to
+	// Draw.
+	back, next navItem
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the Controls.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *controlsOptions
+}
+
+// Controls returns a new widget that displays the buttons that navigate the
+// wizard. Place it into a container outside of the one the wizard replaces.
+func (w *Wizard) Controls(opts ...ControlsOption) (*Controls, error) {
+	opt := &controlsOptions{
+		backLabel:   "< Back",
+		nextLabel:   "Next >",
+		finishLabel: "Finish",
+		cellOpts: []cell.Option{
+			cell.FgColor(cell.ColorBlack),
+			cell.BgColor(cell.ColorWhite),
+		},
+		disabledCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorWhite),
+			cell.BgColor(cell.ColorBlack),
+		},
+		errCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorRed),
+		},
+	}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	ctrl := &Controls{
+		wizard: w,
+		opts:   opt,
+	}
+	w.addWidget(ctrl)
+	return ctrl, nil
+}
+
+// changed implements wizardWidget.changed.
+func (ctrl *Controls) changed() {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if ctrl.notify != nil {
+		ctrl.notify()
+	}
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (ctrl *Controls) SetNotifyFunc(fn func()) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ctrl.notify = fn
+}
+
+// drawButton draws the label of a button with one cell of padding on each
+// side and returns its position.
+func drawButton(cvs *canvas.Canvas, label string, x int, opts []cell.Option) (navItem, error) {
+	width := cvs.Area().Dx()
+	if x >= width {
+		return navItem{}, nil
+	}
+	text := " " + label + " "
+	if err := draw.Text(cvs, text, image.Point{x, 0},
+		draw.TextMaxX(width),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		draw.TextCellOpts(opts...),
+	); err != nil {
+		return navItem{}, err
+	}
+	return navItem{start: x, end: x + runewidth.StringWidth(text)}, nil
+}
+
+// Draw draws the Controls widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (ctrl *Controls) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	ctrl.wizard.mu.Lock()
+	current := ctrl.wizard.current
+	finished := ctrl.wizard.finished
+	invalid := ctrl.wizard.invalid
+	ctrl.wizard.mu.Unlock()
+	last := current == len(ctrl.wizard.opts.steps)-1
+
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	backOpts := ctrl.opts.cellOpts
+	if finished || current == 0 {
+		backOpts = ctrl.opts.disabledCellOpts
+	}
+	back, err := drawButton(cvs, ctrl.opts.backLabel, 0, backOpts)
+	if err != nil {
+		return err
+	}
+	ctrl.back = back
+
+	nextLabel := ctrl.opts.nextLabel
+	if last {
+		nextLabel = ctrl.opts.finishLabel
+	}
+	nextOpts := ctrl.opts.cellOpts
+	if finished {
+		nextOpts = ctrl.opts.disabledCellOpts
+	}
+	// One cell gap between the buttons.
+	next, err := drawButton(cvs, nextLabel, back.end+1, nextOpts)
+	if err != nil {
+		return err
+	}
+	ctrl.next = next
+
+	if x := next.end + 1; invalid != nil && x < cvs.Area().Dx() {
+		if err := draw.Text(cvs, invalid.Error(), image.Point{x, 0},
+			draw.TextMaxX(cvs.Area().Dx()),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(ctrl.opts.errCellOpts...),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Keyboard input isn't supported on the Controls widget, use Wizard.Bind
+// instead.
+func (*Controls) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the Controls widget doesn't support keyboard events")
+}
+
+// Mouse navigates the wizard when a button is clicked.
+// Implements widgetapi.Widget.Mouse.
+func (ctrl *Controls) Mouse(m *terminalapi.Mouse) error {
+	if m.Button != mouse.ButtonLeft || m.Position.Y != 0 {
+		return nil
+	}
+
+	ctrl.mu.Lock()
+	back, next := ctrl.back, ctrl.next
+	ctrl.mu.Unlock()
+
+	switch x := m.Position.X; {
+	case x >= back.start && x < back.end:
+		return ctrl.wizard.Back()
+	case x >= next.start && x < next.end:
+		// A failed gate is displayed, not returned.
+		_, err := ctrl.wizard.next()
+		return err
+	}
+	return nil
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*Controls) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		MaximumSize:  image.Point{0, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+}
@@ -0,0 +1,337 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pages
+
+// wizard.go contains a wizard that guides the user through a sequence of
+// steps.
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/keybinding"
+)
+
+// Gate validates the input on a step of the wizard before the user can
+// continue to the next step. Returns an error that describes the problem if
+// the input isn't valid, the error is displayed by the Controls widget.
+type Gate func() error
+
+// WizardOption is used to provide options to NewWizard().
+type WizardOption interface {
+	// set sets the provided option.
+	set(*wizardOptions)
+}
+
+// step is a step of the wizard.
+type step struct {
+	title string
+	gate  Gate
+	opts  []container.Option
+}
+
+// wizardOptions stores the provided options.
+type wizardOptions struct {
+	steps    []*step
+	onStep   func(index int, title string)
+	onFinish func()
+}
+
+// validate validates the provided options.
+func (o *wizardOptions) validate() error {
+	if len(o.steps) == 0 {
+		return errors.New("at least one Step must be provided")
+	}
+	for i, s := range o.steps {
+		if s.title == "" {
+			return fmt.Errorf("the title of step %d cannot be an empty string", i+1)
+		}
+	}
+	return nil
+}
+
+// wizardOption implements WizardOption.
+type wizardOption func(*wizardOptions)
+
+// set implements WizardOption.set.
+func (o wizardOption) set(opts *wizardOptions) {
+	o(opts)
+}
+
+// Step registers a step with the title, the gate that validates its input
+// and the container options of its layout. The gate can be nil if the step
+// doesn't need validation. The steps are ordered as provided.
+// Like with Page, each step starts from a clean container, so widgets that
+// hold the input must be created once and placed by the options.
+func Step(title string, gate Gate, opts ...container.Option) WizardOption {
+	return wizardOption(func(o *wizardOptions) {
+		o.steps = append(o.steps, &step{
+			title: title,
+			gate:  gate,
+			opts:  opts,
+		})
+	})
+}
+
+// OnStep sets a function that is called with the index and the title of the
+// step each time a different step is displayed. The function is called
+// synchronously from Next and Back, it must not block.
+func OnStep(fn func(index int, title string)) WizardOption {
+	return wizardOption(func(o *wizardOptions) {
+		o.onStep = fn
+	})
+}
+
+// OnFinish sets a function that is called once the gate of the last step
+// passes. The function is called synchronously from Next, it must not
+// block.
+func OnFinish(fn func()) WizardOption {
+	return wizardOption(func(o *wizardOptions) {
+		o.onFinish = fn
+	})
+}
+
+// wizardWidget is a widget that displays the state of the wizard.
+type wizardWidget interface {
+	// changed notifies the infrastructure that the state changed.
+	changed()
+}
+
+// Wizard displays the steps of a multi-step flow one at a time, e.g. the
+// setup of a command line tool. The user continues to the next step only
+// once the gate of the displayed step passes and can go back to the
+// previous steps at any time. The StepIndicator and Controls widgets display
+// the progress and the buttons that navigate.
+//
+// This object is thread-safe.
+type Wizard struct {
+	// pages display the layouts of the steps.
+	pages *Pages
+
+	// current is the index of the displayed step.
+	current int
+	// finished indicates that the gate of the last step passed.
+	finished bool
+	// invalid is the error returned by the last gate that failed, it is
+	// cleared once the displayed step changes.
+	invalid error
+
+	// widgets are the created widgets.
+	widgets []wizardWidget
+
+	// mu protects the state of the Wizard.
+	mu sync.Mutex
+	// navMu serializes the navigation, it is held while the gates run.
+	navMu sync.Mutex
+
+	// opts are the provided options.
+	opts *wizardOptions
+}
+
+// NewWizard returns a new Wizard that replaces the content of the container
+// with the specified id in the tree of containers rooted at the provided
+// container. Displays the first step immediately.
+func NewWizard(c *container.Container, id string, opts ...WizardOption) (*Wizard, error) {
+	opt := &wizardOptions{}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+
+	// The titles don't have to be unique, so the pages are named by the
+	// index of the step.
+	var pgs []Option
+	for i, s := range opt.steps {
+		pgs = append(pgs, Page(strconv.Itoa(i), s.opts...))
+	}
+	p, err := New(c, id, pgs...)
+	if err != nil {
+		return nil, err
+	}
+	return &Wizard{
+		pages: p,
+		opts:  opt,
+	}, nil
+}
+
+// Titles returns the titles of the steps in the order they were registered.
+func (w *Wizard) Titles() []string {
+	var titles []string
+	for _, s := range w.opts.steps {
+		titles = append(titles, s.title)
+	}
+	return titles
+}
+
+// Current returns the index of the displayed step.
+func (w *Wizard) Current() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Finished asserts whether the gate of the last step passed.
+func (w *Wizard) Finished() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.finished
+}
+
+// Err returns the error of the gate that stopped the last call to Next, or
+// nil if the displayed step changed since.
+func (w *Wizard) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.invalid
+}
+
+// Next validates the input on the displayed step with its gate and displays
+// the next step if the gate passes. On the last step, Next finishes the
+// wizard instead. Returns the error of the gate if it fails. Does nothing
+// once the wizard is finished.
+func (w *Wizard) Next() error {
+	gateErr, err := w.next()
+	if err != nil {
+		return err
+	}
+	return gateErr
+}
+
+// next is like Next, but returns the error of the gate separately from the
+// errors that prevent the wizard from working.
+func (w *Wizard) next() (gateErr, err error) {
+	w.navMu.Lock()
+	defer w.navMu.Unlock()
+
+	w.mu.Lock()
+	if w.finished {
+		w.mu.Unlock()
+		return nil, nil
+	}
+	i := w.current
+	w.mu.Unlock()
+
+	// The gate runs without holding mu, since it usually reads the state of
+	// widgets that are being drawn alongside the StepIndicator.
+	if gate := w.opts.steps[i].gate; gate != nil {
+		if gateErr := gate(); gateErr != nil {
+			w.mu.Lock()
+			w.invalid = gateErr
+			w.mu.Unlock()
+			w.notify()
+			return gateErr, nil
+		}
+	}
+
+	if i == len(w.opts.steps)-1 {
+		w.mu.Lock()
+		w.finished = true
+		w.invalid = nil
+		w.mu.Unlock()
+		w.notify()
+		if w.opts.onFinish != nil {
+			w.opts.onFinish()
+		}
+		return nil, nil
+	}
+	return nil, w.display(i + 1)
+}
+
+// Back displays the previous step without validating the input on the
+// displayed step. Does nothing on the first step or once the wizard is
+// finished.
+func (w *Wizard) Back() error {
+	w.navMu.Lock()
+	defer w.navMu.Unlock()
+
+	w.mu.Lock()
+	i := w.current
+	finished := w.finished
+	w.mu.Unlock()
+	if finished || i == 0 {
+		return nil
+	}
+	return w.display(i - 1)
+}
+
+// backTo displays the step with the index if it precedes the displayed
+// step.
+func (w *Wizard) backTo(i int) error {
+	w.navMu.Lock()
+	defer w.navMu.Unlock()
+
+	w.mu.Lock()
+	current := w.current
+	finished := w.finished
+	w.mu.Unlock()
+	if finished || i >= current {
+		return nil
+	}
+	return w.display(i)
+}
+
+// display displays the step with the index. The caller must hold navMu.
+func (w *Wizard) display(i int) error {
+	if err := w.pages.switchTo(i); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.current = i
+	w.invalid = nil
+	w.mu.Unlock()
+
+	w.notify()
+	if w.opts.onStep != nil {
+		w.opts.onStep(i, w.opts.steps[i].title)
+	}
+	return nil
+}
+
+// notify notifies the widgets that the state changed.
+func (w *Wizard) notify() {
+	w.mu.Lock()
+	widgets := append([]wizardWidget(nil), w.widgets...)
+	w.mu.Unlock()
+	for _, ww := range widgets {
+		ww.changed()
+	}
+}
+
+// addWidget registers a widget that displays the state of the wizard.
+func (w *Wizard) addWidget(ww wizardWidget) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.widgets = append(w.widgets, ww)
+}
+
+// Bind binds the key sequences that continue to the next step and go back
+// to the previous step in the registry, e.g. one provided to
+// termdash.KeyBindings. A gate that fails on the key press is only displayed
+// by the Controls widget, it isn't returned as an error of the key binding.
+func (w *Wizard) Bind(r *keybinding.Registry, next, back keybinding.Sequence) error {
+	nextFn := func() error {
+		_, err := w.next()
+		return err
+	}
+	if err := r.Bind(next, nextFn, keybinding.Description("Next step")); err != nil {
+		return err
+	}
+	return r.Bind(back, w.Back, keybinding.Description("Previous step"))
+}
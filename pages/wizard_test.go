@@ -0,0 +1,387 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pages
+
+import (
+	"errors"
+	"image"
+	"sync"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// gateSwitch is a gate that fails until it is opened.
+type gateSwitch struct {
+	mu   sync.Mutex
+	open bool
+}
+
+// gate implements Gate.
+func (gs *gateSwitch) gate() error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if !gs.open {
+		return errors.New("name required")
+	}
+	return nil
+}
+
+// setOpen opens or closes the gate.
+func (gs *gateSwitch) setOpen(open bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.open = open
+}
+
+// testWizard returns a terminal and a container with a step indicator on the
+// top row, controls on the bottom row and steps "Account", "Network" and
+// "Done" between them that fill the area with the first letter of their
+// title. The gate of the first step is controlled by the returned
+// gateSwitch.
+func testWizard(t *testing.T, opts ...WizardOption) (*faketerm.Terminal, *container.Container, *Wizard, *gateSwitch) {
+	t.Helper()
+	ft := faketerm.MustNew(image.Point{36, 3})
+	c, err := container.New(
+		ft,
+		container.SplitHorizontal(
+			container.Top(container.ID("indicator")),
+			container.Bottom(
+				container.SplitHorizontal(
+					container.Top(container.ID("steps")),
+					container.Bottom(container.ID("controls")),
+					container.SplitFixed(1),
+				),
+			),
+			container.SplitFixed(1),
+		),
+	)
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+
+	gs := &gateSwitch{}
+	all := []WizardOption{
+		Step("Account", gs.gate, container.PlaceWidget(&fillWidget{'a'})),
+		Step("Network", nil, container.PlaceWidget(&fillWidget{'n'})),
+		Step("Done", nil, container.PlaceWidget(&fillWidget{'d'})),
+	}
+	w, err := NewWizard(c, "steps", append(all, opts...)...)
+	if err != nil {
+		t.Fatalf("NewWizard => unexpected error: %v", err)
+	}
+	ctrl, err := w.Controls()
+	if err != nil {
+		t.Fatalf("Controls => unexpected error: %v", err)
+	}
+	if err := c.Update("indicator", container.PlaceWidget(w.StepIndicator())); err != nil {
+		t.Fatalf("Update => unexpected error: %v", err)
+	}
+	if err := c.Update("controls", container.PlaceWidget(ctrl)); err != nil {
+		t.Fatalf("Update => unexpected error: %v", err)
+	}
+	return ft, c, w, gs
+}
+
+func TestNewWizard(t *testing.T) {
+	tests := []struct {
+		desc    string
+		opts    []WizardOption
+		wantErr bool
+	}{
+		{
+			desc:    "fails without steps",
+			wantErr: true,
+		},
+		{
+			desc: "fails on an empty title",
+			opts: []WizardOption{
+				Step("", nil),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "titles don't have to be unique",
+			opts: []WizardOption{
+				Step("Step", nil),
+				Step("Step", nil),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ft := faketerm.MustNew(image.Point{10, 3})
+			c, err := container.New(ft, container.ID("root"))
+			if err != nil {
+				t.Fatalf("container.New => unexpected error: %v", err)
+			}
+			_, err = NewWizard(c, "root", tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("NewWizard => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestControlsFailsOnEmptyLabel(t *testing.T) {
+	_, _, w, _ := testWizard(t)
+	if _, err := w.Controls(ControlLabels("Back", "", "Finish")); err == nil {
+		t.Errorf("Controls => got nil error, want an error")
+	}
+}
+
+func TestWizardNavigation(t *testing.T) {
+	var steps []string
+	finished := 0
+	ft, c, w, gs := testWizard(t,
+		OnStep(func(index int, title string) {
+			steps = append(steps, title)
+		}),
+		OnFinish(func() {
+			finished++
+		}),
+	)
+
+	want := []string{
+		"1. Account > 2. Network > 3. Done   ",
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		" < Back   Next >                    ",
+	}
+	if diff := pretty.Compare(want, mustDraw(t, ft, c)); diff != "" {
+		t.Fatalf("initial draw => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	if err := w.Next(); err == nil {
+		t.Fatalf("Next => got nil error, want the error of the gate")
+	}
+	if got, want := w.Current(), 0; got != want {
+		t.Errorf("Current => %d, want %d", got, want)
+	}
+	want = []string{
+		"1. Account > 2. Network > 3. Done   ",
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		" < Back   Next >  name required     ",
+	}
+	if diff := pretty.Compare(want, mustDraw(t, ft, c)); diff != "" {
+		t.Errorf("after a failed gate => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	gs.setOpen(true)
+	for i := 0; i < 2; i++ {
+		if err := w.Next(); err != nil {
+			t.Fatalf("Next => unexpected error: %v", err)
+		}
+	}
+	if w.Err() != nil {
+		t.Errorf("Err => %v, want nil after the step changed", w.Err())
+	}
+	want = []string{
+		"1. Account > 2. Network > 3. Done   ",
+		"dddddddddddddddddddddddddddddddddddd",
+		" < Back   Finish                    ",
+	}
+	if diff := pretty.Compare(want, mustDraw(t, ft, c)); diff != "" {
+		t.Errorf("on the last step => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	if err := w.Back(); err != nil {
+		t.Fatalf("Back => unexpected error: %v", err)
+	}
+	// Going back doesn't run the gate, closing it only stops the first step.
+	gs.setOpen(false)
+	if err := w.Back(); err != nil {
+		t.Fatalf("Back => unexpected error: %v", err)
+	}
+	if err := w.Back(); err != nil {
+		t.Fatalf("Back on the first step => unexpected error: %v", err)
+	}
+	gs.setOpen(true)
+	for i := 0; i < 4; i++ {
+		if err := w.Next(); err != nil {
+			t.Fatalf("Next => unexpected error: %v", err)
+		}
+	}
+	if !w.Finished() {
+		t.Errorf("Finished => false, want true")
+	}
+	if err := w.Back(); err != nil {
+		t.Fatalf("Back after finishing => unexpected error: %v", err)
+	}
+	if got, want := w.Current(), 2; got != want {
+		t.Errorf("Current => %d, want %d", got, want)
+	}
+
+	wantSteps := []string{"Network", "Done", "Network", "Account", "Network", "Done"}
+	if diff := pretty.Compare(wantSteps, steps); diff != "" {
+		t.Errorf("OnStep => unexpected diff (-want, +got):\n%s", diff)
+	}
+	if finished != 1 {
+		t.Errorf("OnFinish called %d times, want 1", finished)
+	}
+}
+
+func TestWizardBind(t *testing.T) {
+	_, _, w, gs := testWizard(t)
+	reg, err := keybinding.New()
+	if err != nil {
+		t.Fatalf("keybinding.New => unexpected error: %v", err)
+	}
+	if err := w.Bind(reg, keybinding.Sequence{keyboard.KeyCtrlN}, keybinding.Sequence{keyboard.KeyCtrlP}); err != nil {
+		t.Fatalf("Bind => unexpected error: %v", err)
+	}
+
+	press := func(k keyboard.Key) {
+		t.Helper()
+		if _, err := reg.Keyboard(&terminalapi.Keyboard{Key: k}); err != nil {
+			t.Fatalf("Keyboard => unexpected error: %v", err)
+		}
+	}
+	// The failed gate isn't an error of the key binding.
+	press(keyboard.KeyCtrlN)
+	if w.Err() == nil {
+		t.Errorf("Err => nil, want the error of the gate")
+	}
+
+	gs.setOpen(true)
+	press(keyboard.KeyCtrlN)
+	press(keyboard.KeyCtrlN)
+	press(keyboard.KeyCtrlP)
+	if got, want := w.Current(), 1; got != want {
+		t.Errorf("Current => %d, want %d", got, want)
+	}
+}
+
+func TestWizardMouse(t *testing.T) {
+	tests := []struct {
+		desc        string
+		open        bool
+		events      []*terminalapi.Mouse
+		wantCurrent int
+		wantErr     bool
+	}{
+		{
+			desc: "click on next with a failed gate",
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{10, 2}, Button: mouse.ButtonLeft},
+				{Position: image.Point{10, 2}, Button: mouse.ButtonRelease},
+			},
+			wantCurrent: 0,
+			wantErr:     true,
+		},
+		{
+			desc: "click on next with a passed gate",
+			open: true,
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{10, 2}, Button: mouse.ButtonLeft},
+				{Position: image.Point{10, 2}, Button: mouse.ButtonRelease},
+			},
+			wantCurrent: 1,
+		},
+		{
+			desc: "click on back",
+			open: true,
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{10, 2}, Button: mouse.ButtonLeft},
+				{Position: image.Point{1, 2}, Button: mouse.ButtonLeft},
+			},
+			wantCurrent: 0,
+		},
+		{
+			desc: "click between the buttons is ignored",
+			open: true,
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{8, 2}, Button: mouse.ButtonLeft},
+			},
+			wantCurrent: 0,
+		},
+		{
+			desc: "click on a preceding step goes back to it",
+			open: true,
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{10, 2}, Button: mouse.ButtonLeft},
+				{Position: image.Point{10, 2}, Button: mouse.ButtonLeft},
+				{Position: image.Point{15, 0}, Button: mouse.ButtonLeft},
+			},
+			wantCurrent: 1,
+		},
+		{
+			desc: "click on a following step is ignored",
+			open: true,
+			events: []*terminalapi.Mouse{
+				{Position: image.Point{30, 0}, Button: mouse.ButtonLeft},
+			},
+			wantCurrent: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ft, c, w, gs := testWizard(t)
+			gs.setOpen(tc.open)
+			for _, ev := range tc.events {
+				// Draw before each event, the widgets record the positions
+				// of the buttons and steps when drawn.
+				mustDraw(t, ft, c)
+				if err := c.Inject(ev); err != nil {
+					t.Fatalf("Inject => unexpected error: %v", err)
+				}
+			}
+			if got := w.Current(); got != tc.wantCurrent {
+				t.Errorf("Current => %d, want %d", got, tc.wantCurrent)
+			}
+			if gotErr := w.Err() != nil; gotErr != tc.wantErr {
+				t.Errorf("Err => %v, wantErr: %v", w.Err(), tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestWizardNotifies(t *testing.T) {
+	_, _, w, gs := testWizard(t)
+	si := w.StepIndicator()
+	ctrl, err := w.Controls()
+	if err != nil {
+		t.Fatalf("Controls => unexpected error: %v", err)
+	}
+	var mu sync.Mutex
+	notified := 0
+	fn := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		notified++
+	}
+	si.SetNotifyFunc(fn)
+	ctrl.SetNotifyFunc(fn)
+
+	// A failed gate and a step change notify both widgets.
+	w.Next()
+	gs.setOpen(true)
+	if err := w.Next(); err != nil {
+		t.Fatalf("Next => unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if notified != 4 {
+		t.Errorf("notified %d times, want 4", notified)
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package perfmode bundles the options that keep full screen dashboards
+// responsive on very large terminals, e.g. on ultrawide monitors where the
+// terminal has 300 columns and 100 rows or more.
+//
+// The performance mode limits the number of cells written to the terminal by
+// each flush, skips frames of the widgets and halves the horizontal
+// resolution of braille patterns, so that a redraw stays within the frame
+// budget instead of visibly lagging:
+//
+//	m, err := perfmode.New()
+//	if err != nil {
+//		...
+//	}
+//	t, err := tcell.New(m.TcellOptions()...)
+//	if err != nil {
+//		...
+//	}
+//	var opts []container.Option
+//	if perfmode.Large(t.Size()) {
+//		opts = append(opts, m.ContainerOptions()...)
+//	}
+//	c, err := container.New(t, append(opts, ...)...)
+//
+// The changes that don't fit into a flush are written by the following
+// flushes. With termdash.RedrawOnChange, termdash redraws until all of them
+// are written, even if nothing else changes.
+//
+// The terminal options have no visible effect on small terminals, since the
+// flushes there don't reach the limit. The container options reduce the
+// quality of the dashboard, so they are best applied only to large
+// terminals.
+package perfmode
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/terminal/tcell"
+	"github.com/mum4k/termdash/terminal/termbox"
+)
+
+const (
+	// LargeWidth is the number of columns from which a terminal is large.
+	LargeWidth = 300
+	// LargeHeight is the number of rows from which a terminal is large.
+	LargeHeight = 100
+)
+
+// Large asserts whether a terminal of the size is large enough to benefit
+// from the performance mode.
+func Large(size image.Point) bool {
+	return size.X >= LargeWidth && size.Y >= LargeHeight
+}
+
+const (
+	// DefaultFlushCells is the default limit of cells written by one flush,
+	// a fifth of a terminal with LargeWidth and LargeHeight.
+	DefaultFlushCells = LargeWidth * LargeHeight / 5
+	// DefaultFrameSkip is the default minimal interval between redraws of
+	// each widget.
+	DefaultFrameSkip = 250 * time.Millisecond
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	flushCells  int
+	frameSkip   time.Duration
+	fullBraille bool
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.flushCells < 0 {
+		return fmt.Errorf("invalid FlushCells(%d), must be zero or a positive number", o.flushCells)
+	}
+	if o.frameSkip < 0 {
+		return fmt.Errorf("invalid FrameSkip(%v), must be zero or a positive duration", o.frameSkip)
+	}
+	return nil
+}
+
+// FlushCells sets the maximum number of cells written to the terminal by one
+// flush, the remaining changes are written by the following flushes. Zero
+// disables the limit.
+// Defaults to DefaultFlushCells.
+func FlushCells(cells int) Option {
+	return option(func(opts *options) {
+		opts.flushCells = cells
+	})
+}
+
+// FrameSkip sets the minimal interval between redraws of each widget, the
+// widgets skip the frames in between. Zero redraws the widgets on every
+// frame.
+// Defaults to DefaultFrameSkip.
+func FrameSkip(d time.Duration) Option {
+	return option(func(opts *options) {
+		opts.frameSkip = d
+	})
+}
+
+// FullBrailleDensity keeps the full horizontal resolution of braille
+// patterns, e.g. for dashboards whose charts need the detail.
+// By default the resolution is halved.
+func FullBrailleDensity() Option {
+	return option(func(opts *options) {
+		opts.fullBraille = true
+	})
+}
+
+// Mode is the performance mode, it provides the options of the terminal and
+// the container.
+type Mode struct {
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new performance mode.
+func New(opts ...Option) (*Mode, error) {
+	o := &options{
+		flushCells: DefaultFlushCells,
+		frameSkip:  DefaultFrameSkip,
+	}
+	for _, opt := range opts {
+		opt.set(o)
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	return &Mode{opts: o}, nil
+}
+
+// TcellOptions returns the options of the tcell terminal.
+func (m *Mode) TcellOptions() []tcell.Option {
+	return []tcell.Option{
+		tcell.FlushCells(m.opts.flushCells),
+	}
+}
+
+// TermboxOptions returns the options of the termbox terminal.
+func (m *Mode) TermboxOptions() []termbox.Option {
+	return []termbox.Option{
+		termbox.FlushCells(m.opts.flushCells),
+	}
+}
+
+// ContainerOptions returns the options of the root container.
+func (m *Mode) ContainerOptions() []container.Option {
+	opts := []container.Option{
+		container.RefreshInterval(m.opts.frameSkip),
+	}
+	if !m.opts.fullBraille {
+		opts = append(opts, container.BrailleHalfDensity())
+	}
+	return opts
+}
@@ -0,0 +1,133 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfmode
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/private/fakewidget"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+func TestLarge(t *testing.T) {
+	tests := []struct {
+		desc string
+		size image.Point
+		want bool
+	}{
+		{
+			desc: "regular terminal",
+			size: image.Point{80, 24},
+		},
+		{
+			desc: "wide but short terminal",
+			size: image.Point{LargeWidth, LargeHeight - 1},
+		},
+		{
+			desc: "tall but narrow terminal",
+			size: image.Point{LargeWidth - 1, LargeHeight},
+		},
+		{
+			desc: "large terminal",
+			size: image.Point{LargeWidth, LargeHeight},
+			want: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := Large(tc.size); got != tc.want {
+				t.Errorf("Large(%v) => %v, want %v", tc.size, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMode(t *testing.T) {
+	tests := []struct {
+		desc    string
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		{
+			desc:    "fails on negative FlushCells",
+			opts:    []Option{FlushCells(-1)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on negative FrameSkip",
+			opts:    []Option{FrameSkip(-time.Second)},
+			wantErr: true,
+		},
+		{
+			desc: "halves the braille density by default",
+			want: "⡇",
+		},
+		{
+			desc: "keeps the full braille density",
+			opts: []Option{FullBrailleDensity()},
+			want: "⣿",
+		},
+		{
+			desc: "accepts zero limits",
+			opts: []Option{FlushCells(0), FrameSkip(0)},
+			want: "⡇",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			m, err := New(tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := len(m.TcellOptions()); got != 1 {
+				t.Errorf("TcellOptions => got %d options, want 1", got)
+			}
+			if got := len(m.TermboxOptions()); got != 1 {
+				t.Errorf("TermboxOptions => got %d options, want 1", got)
+			}
+
+			size := image.Point{12, 3}
+			got := faketerm.MustNew(size)
+			mirror := fakewidget.New(widgetapi.Options{})
+			mirror.Text("⣿")
+			c, err := container.New(got, append(m.ContainerOptions(), container.PlaceWidget(mirror))...)
+			if err != nil {
+				t.Fatalf("container.New => unexpected error: %v", err)
+			}
+			if err := c.Draw(); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			want := faketerm.MustNew(size)
+			wantMirror := fakewidget.New(widgetapi.Options{})
+			wantMirror.Text(tc.want)
+			fakewidget.MustDrawWithMirror(wantMirror, want, testcanvas.MustNew(want.Area()), &widgetapi.Meta{})
+			if diff := faketerm.Diff(want, got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postprocess
+
+// filters.go contains the filters provided by this package.
+
+import (
+	"image"
+
+	"github.com/mum4k/termdash/cell"
+)
+
+// visit calls the function for each point of the frame that falls within the
+// area.
+func visit(f *Frame, ar image.Rectangle, fn func(p image.Point) error) error {
+	ar = ar.Intersect(f.Area())
+	for y := ar.Min.Y; y < ar.Max.Y; y++ {
+		for x := ar.Min.X; x < ar.Max.X; x++ {
+			if err := fn(image.Point{x, y}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Dim sets the foreground color of all the cells outside of the area
+// returned by the function to the color. The function is called on each
+// frame, e.g. pass container.FocusedArea to dim the containers that aren't
+// focused.
+func Dim(color cell.Color, except func() image.Rectangle) Filter {
+	return func(f *Frame) error {
+		keep := except()
+		return visit(f, f.Area(), func(p image.Point) error {
+			if p.In(keep) {
+				return nil
+			}
+			return f.SetCellOpts(p, cell.FgColor(color))
+		})
+	}
+}
+
+// Redact replaces the runes in the areas returned by the function with the
+// rune, leaving the empty cells and the spaces in place so the layout stays
+// recognizable. The function is called on each frame.
+func Redact(r rune, areas func() []image.Rectangle) Filter {
+	return func(f *Frame) error {
+		for _, ar := range areas() {
+			if err := visit(f, ar, func(p image.Point) error {
+				c, err := f.Cell(p)
+				if err != nil {
+					return err
+				}
+				if c.Rune == 0 || c.Rune == ' ' {
+					return nil
+				}
+				return f.SetRune(p, r)
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Scanlines sets the background color of every other row of the frame to the
+// color, starting with the second row.
+func Scanlines(color cell.Color) Filter {
+	return func(f *Frame) error {
+		ar := f.Area()
+		for y := ar.Min.Y + 1; y < ar.Max.Y; y += 2 {
+			row := image.Rect(ar.Min.X, y, ar.Max.X, y+1)
+			if err := visit(f, row, func(p image.Point) error {
+				return f.SetCellOpts(p, cell.BgColor(color))
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
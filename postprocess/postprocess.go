@@ -0,0 +1,298 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package postprocess transforms the frames composed by the terminal
+// dashboard before they are flushed to the terminal. Useful to apply visual
+// policies across all the widgets, e.g. to dim the containers that aren't
+// focused or to redact sensitive regions.
+//
+// The Terminal wraps the terminal the dashboard runs on and runs the filters
+// on each frame when it is flushed:
+//
+//	pt := postprocess.New(t, postprocess.Scanlines(cell.ColorNumber(235)))
+//	c, err := container.New(pt, ...)
+//	...
+//	pt.Add(postprocess.Dim(cell.ColorNumber(240), c.FocusedArea))
+//	err := termdash.Run(ctx, pt, c)
+//
+// The filters only change the cells written to the terminal, the frame drawn
+// by the dashboard stays intact. A filter that stops applying, e.g. when the
+// focus moves, takes effect on the next redraw.
+package postprocess
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas/buffer"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Filter transforms the frame before it is flushed to the terminal.
+// Filters are called while the terminal is being flushed, they must not call
+// methods of the Terminal.
+type Filter func(f *Frame) error
+
+// Terminal runs filters on the frames drawn on the wrapped terminal.
+//
+// Implements terminalapi.Terminal. This object is thread-safe.
+type Terminal struct {
+	// term is the wrapped terminal.
+	term terminalapi.Terminal
+
+	// filters are the filters in the order they run.
+	filters []Filter
+
+	// back is the frame composed by the dashboard, i.e. the cells set since
+	// the terminal was last cleared.
+	back buffer.Buffer
+
+	// mu protects the Terminal.
+	mu sync.Mutex
+}
+
+// New returns a new Terminal that runs the filters in the provided order on
+// each frame before flushing it to the wrapped terminal.
+func New(t terminalapi.Terminal, filters ...Filter) *Terminal {
+	return &Terminal{
+		term:    t,
+		filters: filters,
+	}
+}
+
+// Add appends the filters, they run after the existing ones starting with
+// the next flush.
+func (t *Terminal) Add(filters ...Filter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.filters = append(t.filters, filters...)
+}
+
+// backBuffer returns the back buffer resized to the current size of the
+// terminal. The composed cells are lost when the terminal resizes, the
+// dashboard redraws the whole terminal in that case.
+// Caller must hold t.mu.
+func (t *Terminal) backBuffer() (buffer.Buffer, error) {
+	size := t.term.Size()
+	if t.back != nil && t.back.Size() == size {
+		return t.back, nil
+	}
+	b, err := buffer.New(size)
+	if err != nil {
+		return nil, err
+	}
+	t.back = b
+	return b, nil
+}
+
+// Size implements terminalapi.Terminal.Size.
+func (t *Terminal) Size() image.Point {
+	return t.term.Size()
+}
+
+// Clear implements terminalapi.Terminal.Clear.
+func (t *Terminal) Clear(opts ...cell.Option) error {
+	if err := t.term.Clear(opts...); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, err := buffer.New(t.term.Size())
+	if err != nil {
+		return err
+	}
+	for _, col := range b {
+		for _, c := range col {
+			c.Apply(opts...)
+		}
+	}
+	t.back = b
+	return nil
+}
+
+// Flush implements terminalapi.Terminal.Flush.
+// Runs the filters on a copy of the composed frame and writes the result to
+// the wrapped terminal before flushing it.
+func (t *Terminal) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, err := t.backBuffer()
+	if err != nil {
+		return err
+	}
+	f := newFrame(b)
+	for i, filter := range t.filters {
+		if err := filter(f); err != nil {
+			return fmt.Errorf("filter #%d => %v", i, err)
+		}
+	}
+	if err := f.apply(t.term); err != nil {
+		return err
+	}
+	return t.term.Flush()
+}
+
+// SetCursor implements terminalapi.Terminal.SetCursor.
+func (t *Terminal) SetCursor(p image.Point) {
+	t.term.SetCursor(p)
+}
+
+// HideCursor implements terminalapi.Terminal.HideCursor.
+func (t *Terminal) HideCursor() {
+	t.term.HideCursor()
+}
+
+// SetCell implements terminalapi.Terminal.SetCell.
+// The cell is written to the wrapped terminal when flushed.
+func (t *Terminal) SetCell(p image.Point, r rune, opts ...cell.Option) error {
+	return t.SetCellCombining(p, r, nil, opts...)
+}
+
+// SetCellCombining implements terminalapi.Combiner.SetCellCombining.
+// The combining characters are dropped when flushed if the wrapped terminal
+// doesn't implement terminalapi.Combiner.
+func (t *Terminal) SetCellCombining(p image.Point, r rune, combining []rune, opts ...cell.Option) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, err := t.backBuffer()
+	if err != nil {
+		return err
+	}
+	if ar := (image.Rectangle{Max: b.Size()}); !p.In(ar) {
+		return fmt.Errorf("cell at point %v falls out of the terminal area %v", p, ar)
+	}
+	c := b[p.X][p.Y]
+	c.Rune = r
+	c.Combining = append([]rune(nil), combining...)
+	c.Opts = cell.NewOptions(opts...)
+	return nil
+}
+
+// Event implements terminalapi.Terminal.Event.
+func (t *Terminal) Event(ctx context.Context) terminalapi.Event {
+	return t.term.Event(ctx)
+}
+
+// Capabilities implements terminalapi.Terminal.Capabilities.
+func (t *Terminal) Capabilities() terminalapi.Capabilities {
+	return t.term.Capabilities()
+}
+
+// Close implements terminalapi.Terminal.Close.
+func (t *Terminal) Close() {
+	t.term.Close()
+}
+
+// Cell is a cell of a frame.
+type Cell struct {
+	// Rune is the rune displayed in the cell.
+	Rune rune
+	// Combining are the combining characters drawn over the rune.
+	Combining []rune
+	// Opts are the options of the cell.
+	Opts cell.Options
+}
+
+// Frame is a frame composed by the dashboard, transformed by the filters.
+type Frame struct {
+	// cells are the cells of the frame.
+	cells buffer.Buffer
+}
+
+// newFrame returns a new frame with a copy of the cells in the buffer.
+func newFrame(b buffer.Buffer) *Frame {
+	cells := make(buffer.Buffer, len(b))
+	for x, col := range b {
+		cells[x] = make([]*buffer.Cell, len(col))
+		for y, c := range col {
+			cells[x][y] = c.Copy()
+		}
+	}
+	return &Frame{cells: cells}
+}
+
+// Area returns the area of the frame, i.e. the area of the terminal.
+func (f *Frame) Area() image.Rectangle {
+	return image.Rectangle{Max: f.cells.Size()}
+}
+
+// Cell returns a copy of the cell at the point.
+func (f *Frame) Cell(p image.Point) (Cell, error) {
+	if !p.In(f.Area()) {
+		return Cell{}, fmt.Errorf("point %v falls out of the frame area %v", p, f.Area())
+	}
+	c := f.cells[p.X][p.Y]
+	return Cell{
+		Rune:      c.Rune,
+		Combining: append([]rune(nil), c.Combining...),
+		Opts:      *c.Opts,
+	}, nil
+}
+
+// SetRune replaces the rune at the point and removes its combining
+// characters, the cell options are kept. The width of the rune isn't checked,
+// replacing a full-width rune with a half-width one leaves the following cell
+// empty.
+func (f *Frame) SetRune(p image.Point, r rune) error {
+	if !p.In(f.Area()) {
+		return fmt.Errorf("point %v falls out of the frame area %v", p, f.Area())
+	}
+	c := f.cells[p.X][p.Y]
+	c.Rune = r
+	c.Combining = nil
+	return nil
+}
+
+// SetCellOpts applies the options on the cell at the point, options not
+// provided keep their values.
+func (f *Frame) SetCellOpts(p image.Point, opts ...cell.Option) error {
+	if !p.In(f.Area()) {
+		return fmt.Errorf("point %v falls out of the frame area %v", p, f.Area())
+	}
+	f.cells[p.X][p.Y].Apply(opts...)
+	return nil
+}
+
+// apply writes the frame to the terminal.
+func (f *Frame) apply(t terminalapi.Terminal) error {
+	combiner, _ := t.(terminalapi.Combiner)
+	for x, col := range f.cells {
+		for y, c := range col {
+			p := image.Point{x, y}
+			partial, err := f.cells.IsPartial(p)
+			if err != nil {
+				return err
+			}
+			if partial {
+				// Written together with the full-width rune before it.
+				continue
+			}
+			if combiner != nil && len(c.Combining) > 0 {
+				err = combiner.SetCellCombining(p, c.Rune, c.Combining, c.Opts)
+			} else {
+				err = t.SetCell(p, c.Rune, c.Opts)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
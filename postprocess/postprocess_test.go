@@ -0,0 +1,222 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postprocess
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+)
+
+// mustDrawText draws the lines of text on the terminal.
+func mustDrawText(t *Terminal, lines ...string) {
+	cvs := testcanvas.MustNew(image.Rectangle{Max: t.Size()})
+	for y, l := range lines {
+		testdraw.MustText(cvs, l, image.Point{0, y})
+	}
+	if err := cvs.Apply(t); err != nil {
+		panic(fmt.Sprintf("Apply => unexpected error: %v", err))
+	}
+}
+
+// mustSetAreaCellOpts sets the cell options in the area of the canvas.
+func mustSetAreaCellOpts(cvs *canvas.Canvas, ar image.Rectangle, opts ...cell.Option) {
+	if err := cvs.SetAreaCellOpts(ar, opts...); err != nil {
+		panic(fmt.Sprintf("SetAreaCellOpts => unexpected error: %v", err))
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	tests := []struct {
+		desc    string
+		filters []Filter
+		want    func(size image.Point) *faketerm.Terminal
+		wantErr bool
+	}{
+		{
+			desc: "writes the frame as drawn without filters",
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "ab 世", image.Point{0, 0})
+				testdraw.MustText(cvs, "cd", image.Point{0, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "dims the cells outside of the area",
+			filters: []Filter{
+				Dim(cell.ColorRed, func() image.Rectangle {
+					return image.Rect(0, 0, 1, 2)
+				}),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "ab 世", image.Point{0, 0})
+				testdraw.MustText(cvs, "cd", image.Point{0, 1})
+				// Skips the partial cell of the full-width rune.
+				mustSetAreaCellOpts(cvs, image.Rect(1, 0, 4, 1), cell.FgColor(cell.ColorRed))
+				mustSetAreaCellOpts(cvs, image.Rect(1, 1, 5, 2), cell.FgColor(cell.ColorRed))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "redacts the areas, keeps spaces and empty cells",
+			filters: []Filter{
+				Redact('*', func() []image.Rectangle {
+					return []image.Rectangle{
+						image.Rect(1, 0, 5, 1),
+						image.Rect(0, 1, 1, 2),
+					}
+				}),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "a* *", image.Point{0, 0})
+				testdraw.MustText(cvs, "*d", image.Point{0, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "runs the filters in order",
+			filters: []Filter{
+				Scanlines(cell.ColorBlue),
+				Dim(cell.ColorRed, func() image.Rectangle {
+					return image.Rect(0, 0, 5, 1)
+				}),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "ab 世", image.Point{0, 0})
+				testdraw.MustText(cvs, "cd", image.Point{0, 1})
+				mustSetAreaCellOpts(cvs, image.Rect(0, 1, 5, 2), cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorBlue))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "fails when a filter fails",
+			filters: []Filter{
+				func(*Frame) error {
+					return errors.New("filter error")
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := faketerm.MustNew(image.Point{5, 2})
+			pt := New(got, tc.filters...)
+			mustDrawText(pt, "ab 世", "cd")
+
+			err := pt.Flush()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Flush => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := faketerm.Diff(tc.want(got.Size()), got); diff != "" {
+				t.Errorf("Flush => %v", diff)
+			}
+		})
+	}
+}
+
+func TestTerminalKeepsFrame(t *testing.T) {
+	got := faketerm.MustNew(image.Point{3, 1})
+	redacted := true
+	pt := New(got, Redact('*', func() []image.Rectangle {
+		if !redacted {
+			return nil
+		}
+		return []image.Rectangle{image.Rect(0, 0, 3, 1)}
+	}))
+	mustDrawText(pt, "abc")
+	if err := pt.Flush(); err != nil {
+		t.Fatalf("Flush => unexpected error: %v", err)
+	}
+	if got, want := got.String(), "***\n"; got != want {
+		t.Errorf("Flush => got %q, want %q", got, want)
+	}
+
+	// The filters don't change the frame drawn by the dashboard.
+	redacted = false
+	pt.Add(Scanlines(cell.ColorBlue))
+	if err := pt.Flush(); err != nil {
+		t.Fatalf("Flush => unexpected error: %v", err)
+	}
+	if got, want := got.String(), "abc\n"; got != want {
+		t.Errorf("Flush => got %q, want %q", got, want)
+	}
+
+	if err := pt.Clear(); err != nil {
+		t.Fatalf("Clear => unexpected error: %v", err)
+	}
+	if err := pt.Flush(); err != nil {
+		t.Fatalf("Flush => unexpected error: %v", err)
+	}
+	if got, want := got.String(), "   \n"; got != want {
+		t.Errorf("Flush after Clear => got %q, want %q", got, want)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	got := faketerm.MustNew(image.Point{2, 1})
+	pt := New(got, func(f *Frame) error {
+		if _, err := f.Cell(image.Point{2, 0}); err == nil {
+			t.Errorf("Cell => got nil error, want one for a point outside of the frame")
+		}
+		if err := f.SetRune(image.Point{-1, 0}, 'x'); err == nil {
+			t.Errorf("SetRune => got nil error, want one for a point outside of the frame")
+		}
+		if err := f.SetCellOpts(image.Point{0, 1}); err == nil {
+			t.Errorf("SetCellOpts => got nil error, want one for a point outside of the frame")
+		}
+
+		c, err := f.Cell(image.Point{0, 0})
+		if err != nil {
+			return err
+		}
+		if c.Rune != 'e' || len(c.Combining) != 1 || c.Opts.FgColor != cell.ColorRed {
+			t.Errorf("Cell => %+v, want 'e' with a combining character and a red foreground", c)
+		}
+		return nil
+	})
+	if err := pt.SetCellCombining(image.Point{0, 0}, 'e', []rune{'\u0301'}, cell.FgColor(cell.ColorRed)); err != nil {
+		t.Fatalf("SetCellCombining => unexpected error: %v", err)
+	}
+	if err := pt.SetCell(image.Point{2, 0}, 'x'); err == nil {
+		t.Errorf("SetCell => got nil error, want one for a point outside of the terminal")
+	}
+	if err := pt.Flush(); err != nil {
+		t.Fatalf("Flush => unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postprocess
+
+// privacy.go contains the privacy mode.
+
+import (
+	"image"
+	"sync"
+)
+
+// DefaultMask is the rune that masks the sensitive content in the privacy
+// mode by default.
+const DefaultMask = '*'
+
+// Privacy is the privacy mode, which masks the sensitive content of the
+// dashboard while enabled, e.g. while the dashboard is screen-shared:
+//
+//	p := postprocess.NewPrivacy(DefaultMask, c.SensitiveAreas)
+//	pt.Add(p.Filter)
+//
+// The mode is disabled when created. Toggle it with the termdash.PrivacyKey
+// option or by calling the methods and redrawing the dashboard.
+//
+// This object is thread-safe.
+type Privacy struct {
+	// mask is the rune that replaces the sensitive content.
+	mask rune
+	// areas returns the areas with the sensitive content.
+	areas func() []image.Rectangle
+
+	// mu protects enabled.
+	mu sync.Mutex
+	// enabled indicates if the sensitive content is masked.
+	enabled bool
+}
+
+// NewPrivacy returns a new privacy mode that masks the areas returned by the
+// function with the mask rune, e.g. pass container.Container.SensitiveAreas.
+// The function is called on each frame while the mode is enabled.
+func NewPrivacy(mask rune, areas func() []image.Rectangle) *Privacy {
+	return &Privacy{
+		mask:  mask,
+		areas: areas,
+	}
+}
+
+// Enable enables or disables the privacy mode.
+func (p *Privacy) Enable(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.enabled = enabled
+}
+
+// Toggle enables the privacy mode if it is disabled and vice versa.
+func (p *Privacy) Toggle() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.enabled = !p.enabled
+}
+
+// Enabled asserts whether the privacy mode is enabled.
+func (p *Privacy) Enabled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.enabled
+}
+
+// Filter masks the sensitive content while the privacy mode is enabled.
+// Implements Filter.
+func (p *Privacy) Filter(f *Frame) error {
+	if !p.Enabled() {
+		return nil
+	}
+	return Redact(p.mask, p.areas)(f)
+}
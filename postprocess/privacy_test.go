@@ -0,0 +1,72 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postprocess
+
+import (
+	"image"
+	"testing"
+
+	"github.com/mum4k/termdash/private/faketerm"
+)
+
+func TestPrivacy(t *testing.T) {
+	got := faketerm.MustNew(image.Point{4, 1})
+	p := NewPrivacy(DefaultMask, func() []image.Rectangle {
+		return []image.Rectangle{image.Rect(1, 0, 3, 1)}
+	})
+	pt := New(got, p.Filter)
+	mustDrawText(pt, "abcd")
+
+	steps := []struct {
+		desc   string
+		change func()
+		want   string
+	}{
+		{
+			desc:   "disabled when created",
+			change: func() {},
+			want:   "abcd\n",
+		},
+		{
+			desc:   "Toggle enables the mode",
+			change: p.Toggle,
+			want:   "a**d\n",
+		},
+		{
+			desc:   "Toggle disables the mode",
+			change: p.Toggle,
+			want:   "abcd\n",
+		},
+		{
+			desc:   "Enable enables the mode",
+			change: func() { p.Enable(true) },
+			want:   "a**d\n",
+		},
+		{
+			desc:   "Enable disables the mode",
+			change: func() { p.Enable(false) },
+			want:   "abcd\n",
+		},
+	}
+	for _, step := range steps {
+		step.change()
+		if err := pt.Flush(); err != nil {
+			t.Fatalf("%s: Flush => unexpected error: %v", step.desc, err)
+		}
+		if got := got.String(); got != step.want {
+			t.Errorf("%s: Flush => got %q, want %q", step.desc, got, step.want)
+		}
+	}
+}
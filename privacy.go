@@ -0,0 +1,62 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termdash
+
+// privacy.go contains code that toggles the privacy mode.
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/postprocess"
+)
+
+// PrivacyKey binds the key sequence to a function that enables or disables
+// the privacy mode, which masks the sensitive content while the dashboard is
+// screen-shared. The filter of the privacy mode must run on the terminal the
+// dashboard runs on, see postprocess.Privacy.
+// The sequence is bound in the registry provided via the KeyBindings option,
+// which is required.
+func PrivacyKey(p *postprocess.Privacy, seq keybinding.Sequence) Option {
+	return option(func(td *termdash) {
+		td.privacy = p
+		td.privacyKey = seq
+	})
+}
+
+// bindPrivacyKey binds the PrivacyKey sequence in the key bindings.
+func (td *termdash) bindPrivacyKey() error {
+	if td.privacyKey == nil {
+		return nil
+	}
+	if td.privacy == nil {
+		return errors.New("the privacy mode of the PrivacyKey cannot be nil")
+	}
+	if err := td.keyBindings.Bind(td.privacyKey, td.togglePrivacy, keybinding.Description("Mask or reveal the sensitive content")); err != nil {
+		return fmt.Errorf("invalid PrivacyKey: %v", err)
+	}
+	return nil
+}
+
+// togglePrivacy enables or disables the privacy mode and redraws the
+// terminal.
+func (td *termdash) togglePrivacy() error {
+	td.privacy.Toggle()
+
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	return td.redraw()
+}
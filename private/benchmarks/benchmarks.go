@@ -0,0 +1,229 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package benchmarks renders representative dashboards frame by frame on a
+// fake terminal, so that the cost of a frame can be benchmarked and the
+// allocations per frame asserted in the regular test runs.
+package benchmarks
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/container/grid"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/widgetapi"
+	"github.com/mum4k/termdash/widgets/barchart"
+	"github.com/mum4k/termdash/widgets/donut"
+	"github.com/mum4k/termdash/widgets/gauge"
+	"github.com/mum4k/termdash/widgets/linechart"
+	"github.com/mum4k/termdash/widgets/sparkline"
+	"github.com/mum4k/termdash/widgets/text"
+)
+
+// columns is the maximum number of widgets in one row of the dashboard.
+const columns = 4
+
+// points is the number of data points in the series of the line charts.
+const points = 200
+
+// wave is a precomputed sine wave the data of the widgets are taken from,
+// so that the dashboard itself doesn't allocate when preparing a frame.
+var wave = func() []float64 {
+	w := make([]float64, 2*points)
+	for i := range w {
+		w[i] = 50 + 50*math.Sin(float64(i)*2*math.Pi/points)
+	}
+	return w
+}()
+
+// lines are the lines of text the text widgets cycle through.
+var lines = []string{
+	"GET /api/v1/metrics 200 12ms\n",
+	"POST /api/v1/series 201 31ms\n",
+	"GET /healthz 200 1ms\n",
+	"DELETE /api/v1/series/42 404 3ms\n",
+}
+
+// updateFunc updates the data of a widget for the frame.
+type updateFunc func(frame int) error
+
+// Dashboard is a representative dashboard on a fake terminal. The widgets
+// cycle through the line chart, the sparkline, the bar chart, the gauge,
+// the donut and the text widget and are laid out in rows of up to four
+// bordered containers.
+//
+// This object is not thread-safe.
+type Dashboard struct {
+	// term is the fake terminal the dashboard is drawn on.
+	term *faketerm.Terminal
+	// cont is the root container.
+	cont *container.Container
+	// updates update the data of each widget.
+	updates []updateFunc
+	// frame is the number of frames rendered so far.
+	frame int
+}
+
+// New returns a new dashboard with the number of widgets on a terminal of
+// the size.
+func New(size image.Point, widgets int) (*Dashboard, error) {
+	if widgets <= 0 {
+		return nil, fmt.Errorf("invalid number of widgets %d, must be a positive integer", widgets)
+	}
+	term, err := faketerm.New(size)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dashboard{term: term}
+	var (
+		rows []grid.Element
+		cols []grid.Element
+	)
+	for i := 0; i < widgets; i++ {
+		w, update, err := newWidget(i)
+		if err != nil {
+			return nil, err
+		}
+		d.updates = append(d.updates, update)
+		cols = append(cols, grid.ColWeighted(1, grid.Widget(w,
+			container.Border(linestyle.Light),
+			container.BorderTitle(fmt.Sprintf("widget %d", i)),
+		)))
+		if len(cols) == columns || i == widgets-1 {
+			rows = append(rows, grid.RowWeighted(1, cols...))
+			cols = nil
+		}
+	}
+
+	b := grid.New()
+	b.Add(rows...)
+	opts, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	cont, err := container.New(term, opts...)
+	if err != nil {
+		return nil, err
+	}
+	d.cont = cont
+	return d, nil
+}
+
+// newWidget returns the i-th widget of the dashboard and the function that
+// updates its data.
+func newWidget(i int) (widgetapi.Widget, updateFunc, error) {
+	switch i % 6 {
+	case 0:
+		lc, err := linechart.New()
+		if err != nil {
+			return nil, nil, err
+		}
+		return lc, func(frame int) error {
+			start := frame % points
+			return lc.Series("wave", wave[start:start+points], linechart.SeriesCellOpts(cell.FgColor(cell.ColorGreen)))
+		}, nil
+
+	case 1:
+		sl, err := sparkline.New(sparkline.Color(cell.ColorBlue))
+		if err != nil {
+			return nil, nil, err
+		}
+		data := make([]int, 1)
+		return sl, func(frame int) error {
+			data[0] = int(wave[frame%len(wave)])
+			return sl.Add(data)
+		}, nil
+
+	case 2:
+		bc, err := barchart.New()
+		if err != nil {
+			return nil, nil, err
+		}
+		values := make([]int, 8)
+		return bc, func(frame int) error {
+			for j := range values {
+				values[j] = int(wave[(frame+j*10)%len(wave)])
+			}
+			return bc.Values(values, 100)
+		}, nil
+
+	case 3:
+		g, err := gauge.New()
+		if err != nil {
+			return nil, nil, err
+		}
+		return g, func(frame int) error {
+			return g.Percent(int(wave[frame%len(wave)]))
+		}, nil
+
+	case 4:
+		dn, err := donut.New()
+		if err != nil {
+			return nil, nil, err
+		}
+		return dn, func(frame int) error {
+			return dn.Percent(1 + int(wave[frame%len(wave)])*99/100)
+		}, nil
+
+	default:
+		t, err := text.New(text.RollContent())
+		if err != nil {
+			return nil, nil, err
+		}
+		return t, func(frame int) error {
+			i := frame % len(lines)
+			if i == 0 {
+				// Keeps the amount of content constant between frames.
+				return t.Write(lines[i], text.WriteReplace())
+			}
+			return t.Write(lines[i])
+		}, nil
+	}
+}
+
+// Frame updates the data of all the widgets and renders one frame, i.e.
+// draws the containers and flushes the terminal.
+func (d *Dashboard) Frame() error {
+	for _, update := range d.updates {
+		if err := update(d.frame); err != nil {
+			return err
+		}
+	}
+	d.frame++
+
+	if err := d.cont.Draw(); err != nil {
+		return err
+	}
+	return d.term.Flush()
+}
+
+// Frames renders the number of frames.
+func (d *Dashboard) Frames(n int) error {
+	if n < 0 {
+		return errors.New("the number of frames cannot be negative")
+	}
+	for i := 0; i < n; i++ {
+		if err := d.Frame(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package benchmarks
+
+import (
+	"image"
+	"testing"
+)
+
+// warmUpFrames are rendered before measuring, so that the widgets reach
+// their steady state, e.g. the sparklines fill their width.
+const warmUpFrames = 100
+
+// dashboards are the representative dashboards. The allocation budgets leave
+// about a quarter of headroom above the measured allocations, update them
+// when an intentional change moves the measurement.
+var dashboards = []struct {
+	desc    string
+	size    image.Point
+	widgets int
+	// maxAllocs is the maximum number of allocations per frame.
+	maxAllocs float64
+}{
+	{
+		desc:      "single widget on a regular terminal",
+		size:      image.Point{80, 24},
+		widgets:   1,
+		maxAllocs: 20000,
+	},
+	{
+		desc:      "six widgets on a regular terminal",
+		size:      image.Point{80, 24},
+		widgets:   6,
+		maxAllocs: 20000,
+	},
+	{
+		desc:      "twelve widgets on a full HD terminal",
+		size:      image.Point{200, 60},
+		widgets:   12,
+		maxAllocs: 125000,
+	},
+	{
+		desc:      "twenty four widgets on an ultrawide terminal",
+		size:      image.Point{300, 100},
+		widgets:   24,
+		maxAllocs: 270000,
+	},
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		desc    string
+		size    image.Point
+		widgets int
+		wantErr bool
+	}{
+		{
+			desc:    "fails on zero widgets",
+			size:    image.Point{80, 24},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on an invalid terminal size",
+			size:    image.Point{0, 24},
+			widgets: 1,
+			wantErr: true,
+		},
+		{
+			desc:    "creates the dashboard",
+			size:    image.Point{80, 24},
+			widgets: 7,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			d, err := New(tc.size, tc.widgets)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := d.Frames(2); err != nil {
+				t.Errorf("Frames => unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAllocsPerFrame(t *testing.T) {
+	for _, tc := range dashboards {
+		t.Run(tc.desc, func(t *testing.T) {
+			d, err := New(tc.size, tc.widgets)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := d.Frames(warmUpFrames); err != nil {
+				t.Fatalf("Frames => unexpected error: %v", err)
+			}
+
+			var frameErr error
+			allocs := testing.AllocsPerRun(10, func() {
+				if err := d.Frame(); err != nil {
+					frameErr = err
+				}
+			})
+			if frameErr != nil {
+				t.Fatalf("Frame => unexpected error: %v", frameErr)
+			}
+			if allocs > tc.maxAllocs {
+				t.Errorf("Frame => got %v allocations per frame, want at most %v", allocs, tc.maxAllocs)
+			}
+		})
+	}
+}
+
+func BenchmarkFrame(b *testing.B) {
+	for _, tc := range dashboards {
+		b.Run(tc.desc, func(b *testing.B) {
+			d, err := New(tc.size, tc.widgets)
+			if err != nil {
+				b.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := d.Frames(warmUpFrames); err != nil {
+				b.Fatalf("Frames => unexpected error: %v", err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := d.Frame(); err != nil {
+					b.Fatalf("Frame => unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -990,3 +990,33 @@ func TestBraille(t *testing.T) {
 		})
 	}
 }
+
+func TestHalfDensity(t *testing.T) {
+	cvs, err := canvas.New(image.Rect(0, 0, 4, 1))
+	if err != nil {
+		t.Fatalf("canvas.New => unexpected error: %v", err)
+	}
+	for i, r := range []rune{'⢀', 'a', '⣿', '⠇'} {
+		if _, err := cvs.SetCell(image.Point{i, 0}, r, cell.FgColor(cell.ColorRed)); err != nil {
+			t.Fatalf("SetCell => unexpected error: %v", err)
+		}
+	}
+
+	if err := HalfDensity(cvs); err != nil {
+		t.Fatalf("HalfDensity => unexpected error: %v", err)
+	}
+	var got []rune
+	for i := 0; i < 4; i++ {
+		c, err := cvs.Cell(image.Point{i, 0})
+		if err != nil {
+			t.Fatalf("Cell => unexpected error: %v", err)
+		}
+		if c.Opts.FgColor != cell.ColorRed {
+			t.Errorf("Cell(%d) => FgColor %v, want %v", i, c.Opts.FgColor, cell.ColorRed)
+		}
+		got = append(got, c.Rune)
+	}
+	if want := []rune{'⡀', 'a', '⡇', '⠇'}; string(got) != string(want) {
+		t.Errorf("HalfDensity => %q, want %q", string(got), string(want))
+	}
+}
@@ -38,6 +38,10 @@ type Cell struct {
 	// Rune is the rune stored in the cell.
 	Rune rune
 
+	// Combining are combining characters drawn over the rune in the same
+	// cell, see runewidth.IsCombining.
+	Combining []rune
+
 	// Opts are the cell options.
 	Opts *cell.Options
 }
@@ -58,8 +62,9 @@ func NewCell(r rune, opts ...cell.Option) *Cell {
 // Copy returns a copy the cell.
 func (c *Cell) Copy() *Cell {
 	return &Cell{
-		Rune: c.Rune,
-		Opts: cell.NewOptions(c.Opts),
+		Rune:      c.Rune,
+		Combining: append([]rune(nil), c.Combining...),
+		Opts:      cell.NewOptions(c.Opts),
 	}
 }
 
@@ -101,7 +106,21 @@ func New(size image.Point) (Buffer, error) {
 // printed on the terminal. See http://www.unicode.org/reports/tr11/.
 // Use the options to specify which attributes to modify, if an attribute
 // option isn't specified, the attribute retains its previous value.
+//
+// Runes that join the grapheme cluster in the cell before the specified
+// point, i.e. the point where the next rune would be set after the cluster,
+// are added to that cell, see runewidth.Tracker. These are the combining
+// characters and the runes that form emoji sequences. They usually occupy
+// zero cells, but can make the cluster wider, e.g. the emoji presentation
+// selector. A rune that would make the cluster extend past the end of the
+// line is dropped. The options don't apply to them.
 func (b Buffer) SetCell(p image.Point, r rune, opts ...cell.Option) (int, error) {
+	if p.X > 0 {
+		if joined, cells, err := b.join(image.Point{p.X - 1, p.Y}, r); joined || err != nil {
+			return cells, err
+		}
+	}
+
 	partial, err := b.IsPartial(p)
 	if err != nil {
 		return -1, err
@@ -126,10 +145,79 @@ func (b Buffer) SetCell(p image.Point, r rune, opts ...cell.Option) (int, error)
 
 	c := b[p.X][p.Y]
 	c.Rune = r
+	c.Combining = nil
 	c.Apply(opts...)
 	return rw, nil
 }
 
+// clusterCell returns the point of the cell that contains the grapheme
+// cluster drawn at the point, i.e. the point itself or the full-width cell
+// that occupies it. Returns false if the point falls outside of the buffer.
+func (b Buffer) clusterCell(p image.Point) (image.Point, bool, error) {
+	ar, err := area.FromSize(b.Size())
+	if err != nil {
+		return image.ZP, false, err
+	}
+	if !p.In(ar) {
+		return image.ZP, false, nil
+	}
+
+	partial, err := b.IsPartial(p)
+	if err != nil {
+		return image.ZP, false, err
+	}
+	if partial {
+		p = image.Point{p.X - 1, p.Y}
+	}
+	return p, true, nil
+}
+
+// Joins determines if the rune set at the point would join the grapheme
+// cluster in the cell before the point instead of occupying cells of its
+// own, see SetCell.
+func (b Buffer) Joins(p image.Point, r rune) bool {
+	if p.X <= 0 {
+		return false
+	}
+	cp, ok, err := b.clusterCell(image.Point{p.X - 1, p.Y})
+	if err != nil || !ok {
+		return false
+	}
+	c := b[cp.X][cp.Y]
+	t := runewidth.CellTracker(c.Rune, c.Combining)
+	return t.Joins(r)
+}
+
+// join adds the rune to the grapheme cluster in the cell at the point or in
+// the full-width cell that occupies it, if the rune joins the cluster.
+// Returns true if the rune joined the cluster and the number of cells the
+// cluster grew by.
+func (b Buffer) join(p image.Point, r rune) (bool, int, error) {
+	p, ok, err := b.clusterCell(p)
+	if err != nil || !ok {
+		// Let the caller report an invalid point.
+		return false, 0, err
+	}
+	c := b[p.X][p.Y]
+	t := runewidth.CellTracker(c.Rune, c.Combining)
+	if !t.Joins(r) {
+		return false, 0, nil
+	}
+	grew := t.Peek(r)
+	end := p.X + t.Width() + grew
+	if end > b.Size().X {
+		// Dropped, the grown cluster wouldn't fit.
+		return true, 0, nil
+	}
+	c.Combining = append(c.Combining, r)
+	// Clear the cells the cluster grew into.
+	for x := p.X + t.Width(); x < end; x++ {
+		b[x][p.Y].Rune = 0
+		b[x][p.Y].Combining = nil
+	}
+	return true, grew, nil
+}
+
 // IsPartial returns true if the cell at the specified point holds a part of a
 // full width rune from a previous cell. See
 // http://www.unicode.org/reports/tr11/.
@@ -153,8 +241,9 @@ func (b Buffer) IsPartial(p image.Point) (bool, error) {
 		prevP = image.Point{size.X - 1, p.Y - 1}
 	}
 
-	prevR := b[prevP.X][prevP.Y].Rune
-	switch rw := runewidth.RuneWidth(prevR); rw {
+	prev := b[prevP.X][prevP.Y]
+	prevR := prev.Rune
+	switch rw := runewidth.CellWidth(prevR, prev.Combining); rw {
 	case 0, 1:
 		return false, nil
 	case 2:
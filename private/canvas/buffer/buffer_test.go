@@ -380,6 +380,143 @@ func TestSetCell(t *testing.T) {
 				return b
 			}(),
 		},
+		{
+			desc: "combining character is added to the previous cell",
+			buffer: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = 'e'
+				return b
+			}(),
+			point:     image.Point{1, 0},
+			r:         '\u0301',
+			wantCells: 0,
+			want: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = 'e'
+				b[0][0].Combining = []rune{'\u0301'}
+				return b
+			}(),
+		},
+		{
+			desc: "combining character after a full-width rune",
+			buffer: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = '世'
+				return b
+			}(),
+			point:     image.Point{2, 0},
+			r:         '\u0301',
+			wantCells: 0,
+			want: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = '世'
+				b[0][0].Combining = []rune{'\u0301'}
+				return b
+			}(),
+		},
+		{
+			desc: "combining character after the last cell on the line",
+			buffer: func() Buffer {
+				b := mustNew(size)
+				b[2][0].Rune = 'e'
+				return b
+			}(),
+			point:     image.Point{3, 0},
+			r:         '\u0301',
+			wantCells: 0,
+			want: func() Buffer {
+				b := mustNew(size)
+				b[2][0].Rune = 'e'
+				b[2][0].Combining = []rune{'\u0301'}
+				return b
+			}(),
+		},
+		{
+			desc: "emoji after a zero width joiner joins the previous cell",
+			buffer: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = '👨'
+				b[0][0].Combining = []rune{'\u200d'}
+				return b
+			}(),
+			point:     image.Point{2, 0},
+			r:         '👩',
+			wantCells: 0,
+			want: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = '👨'
+				b[0][0].Combining = []rune{'\u200d', '👩'}
+				return b
+			}(),
+		},
+		{
+			desc: "variation selector widens the previous cell",
+			buffer: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = '❤'
+				b[1][0].Rune = 'x'
+				return b
+			}(),
+			point:     image.Point{1, 0},
+			r:         '\ufe0f',
+			wantCells: 1,
+			want: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = '❤'
+				b[0][0].Combining = []rune{'\ufe0f'}
+				return b
+			}(),
+		},
+		{
+			desc: "variation selector is dropped when the widened cell doesn't fit",
+			buffer: func() Buffer {
+				b := mustNew(size)
+				b[2][0].Rune = '❤'
+				return b
+			}(),
+			point:     image.Point{3, 0},
+			r:         '\ufe0f',
+			wantCells: 0,
+			want: func() Buffer {
+				b := mustNew(size)
+				b[2][0].Rune = '❤'
+				return b
+			}(),
+		},
+		{
+			desc: "regional indicators form a flag",
+			buffer: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = '🇺'
+				return b
+			}(),
+			point:     image.Point{1, 0},
+			r:         '🇸',
+			wantCells: 1,
+			want: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = '🇺'
+				b[0][0].Combining = []rune{'🇸'}
+				return b
+			}(),
+		},
+		{
+			desc: "setting a rune clears combining characters",
+			buffer: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = 'e'
+				b[0][0].Combining = []rune{'\u0301'}
+				return b
+			}(),
+			point:     image.Point{0, 0},
+			r:         'A',
+			wantCells: 1,
+			want: func() Buffer {
+				b := mustNew(size)
+				b[0][0].Rune = 'A'
+				return b
+			}(),
+		},
 		{
 			desc:    "not enough space for a wide rune on the line",
 			buffer:  mustNew(image.Point{3, 3}),
@@ -83,6 +83,14 @@ func (c *Canvas) SetCell(p image.Point, r rune, opts ...cell.Option) (int, error
 	return c.buffer.SetCell(p, r, opts...)
 }
 
+// Joins determines if the rune set at the point would join the grapheme
+// cluster in the cell before the point instead of occupying cells of its
+// own, e.g. a combining character or the second emoji of a zero width joiner
+// sequence.
+func (c *Canvas) Joins(p image.Point, r rune) bool {
+	return c.buffer.Joins(p, r)
+}
+
 // Cell returns a copy of the specified cell.
 func (c *Canvas) Cell(p image.Point) (*buffer.Cell, error) {
 	ar, err := area.FromSize(c.Size())
@@ -116,6 +124,7 @@ func (c *Canvas) SetCellOpts(p image.Point, opts ...cell.Option) error {
 	if _, err := c.SetCell(p, curCell.Rune, opts...); err != nil {
 		return err
 	}
+	c.buffer[p.X][p.Y].Combining = curCell.Combining
 	return nil
 }
 
@@ -128,6 +137,9 @@ func (c *Canvas) SetAreaCells(cellArea image.Rectangle, r rune, opts ...cell.Opt
 		return fmt.Errorf("unable to set cell runes in area %v, it must fit inside the available cell area is %v", cellArea, haveArea)
 	}
 
+	if runewidth.IsCombining(r) {
+		return fmt.Errorf("unable to set the combining rune %q on cells in area %v, it doesn't occupy any cells", r, cellArea)
+	}
 	rw := runewidth.RuneWidth(r)
 	for row := cellArea.Min.Y; row < cellArea.Max.Y; row++ {
 		for col := cellArea.Min.X; col < cellArea.Max.X; {
@@ -163,7 +175,7 @@ func (c *Canvas) SetAreaCellOpts(cellArea image.Rectangle, opts ...cell.Option)
 }
 
 // setCellFunc is a function that sets cell content on a terminal or a canvas.
-type setCellFunc func(image.Point, rune, ...cell.Option) error
+type setCellFunc func(image.Point, *buffer.Cell) error
 
 // copyTo is the internal implementation of code that copies the content of a
 // canvas. If a non zero offset is provided, all the copied points are offset by
@@ -186,7 +198,7 @@ func (c *Canvas) copyTo(offset image.Point, dstSetCell setCellFunc) error {
 			}
 			cell := c.buffer[col][row]
 			p := image.Point{col, row}.Add(offset)
-			if err := dstSetCell(p, cell.Rune, cell.Opts); err != nil {
+			if err := dstSetCell(p, cell); err != nil {
 				return fmt.Errorf("setCellFunc%v => error: %v", p, err)
 			}
 		}
@@ -215,7 +227,13 @@ func (c *Canvas) Apply(t terminalapi.Terminal) error {
 	// image.Point{0, 0} on the terminal.
 	// Depends on area assigned by the container.
 	offset := c.area.Min
-	return c.copyTo(offset, t.SetCell)
+	combiner, _ := t.(terminalapi.Combiner)
+	return c.copyTo(offset, func(p image.Point, bc *buffer.Cell) error {
+		if combiner != nil && len(bc.Combining) > 0 {
+			return combiner.SetCellCombining(p, bc.Rune, bc.Combining, bc.Opts)
+		}
+		return t.SetCell(p, bc.Rune, bc.Opts)
+	})
 }
 
 // CopyTo copies the content of this canvas onto the destination canvas.
@@ -226,10 +244,11 @@ func (c *Canvas) CopyTo(dst *Canvas) error {
 		return fmt.Errorf("the canvas area %v doesn't fit or lie inside the destination canvas area %v", c.area, dst.Area())
 	}
 
-	fn := setCellFunc(func(p image.Point, r rune, opts ...cell.Option) error {
-		if _, err := dst.SetCell(p, r, opts...); err != nil {
+	fn := setCellFunc(func(p image.Point, bc *buffer.Cell) error {
+		if _, err := dst.SetCell(p, bc.Rune, bc.Opts); err != nil {
 			return fmt.Errorf("dst.SetCell => %v", err)
 		}
+		dst.buffer[p.X][p.Y].Combining = append([]rune(nil), bc.Combining...)
 		return nil
 	})
 
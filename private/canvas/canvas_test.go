@@ -903,6 +903,51 @@ func TestApplyFullWidthRunes(t *testing.T) {
 	}
 }
 
+func TestApplyCombining(t *testing.T) {
+	ar := image.Rect(0, 0, 3, 1)
+	c, err := New(ar)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	var cur image.Point
+	for _, r := range "e\u0301a" {
+		cells, err := c.SetCell(cur, r)
+		if err != nil {
+			t.Fatalf("SetCell => unexpected error: %v", err)
+		}
+		cur = image.Point{cur.X + cells, cur.Y}
+	}
+
+	dst, err := New(ar)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := c.CopyTo(dst); err != nil {
+		t.Fatalf("CopyTo => unexpected error: %v", err)
+	}
+
+	ft, err := faketerm.New(area.Size(ar))
+	if err != nil {
+		t.Fatalf("faketerm.New => unexpected error: %v", err)
+	}
+	if err := dst.Apply(ft); err != nil {
+		t.Fatalf("Apply => unexpected error: %v", err)
+	}
+
+	want, err := buffer.New(area.Size(ar))
+	if err != nil {
+		t.Fatalf("buffer.New => unexpected error: %v", err)
+	}
+	want[0][0].Rune = 'e'
+	want[0][0].Combining = []rune{'\u0301'}
+	want[1][0].Rune = 'a'
+
+	got := ft.BackBuffer()
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("faketerm.BackBuffer => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
 func TestCell(t *testing.T) {
 	tests := []struct {
 		desc    string
@@ -0,0 +1,282 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package choice implements a group of choices the user can select from,
+// shared by the checkbox and the radiogroup widgets.
+package choice
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/alignfor"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/private/wrap"
+	"github.com/mum4k/termdash/selection"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Config configures the group.
+type Config struct {
+	// Labels are the labels of the choices, one per line.
+	Labels []string
+	// Mode determines if one or multiple choices can be selected.
+	Mode selection.Mode
+
+	// Selected and Unselected are the markers drawn in front of the labels.
+	// Both must have the same width in cells.
+	Selected   string
+	Unselected string
+
+	// Align is the horizontal alignment of the choices.
+	Align align.Horizontal
+
+	// TextColor is the color of the markers and the labels.
+	TextColor cell.Color
+	// HighlightedColor is the background color of the choice under the
+	// cursor while the group is focused.
+	HighlightedColor cell.Color
+	// DisabledColor is the color of the markers and the labels while the
+	// group is disabled.
+	DisabledColor cell.Color
+}
+
+// validate validates the configuration.
+func (c *Config) validate() error {
+	if len(c.Labels) == 0 {
+		return errors.New("at least one label must be provided")
+	}
+	for i, l := range c.Labels {
+		if err := wrap.ValidText(l); err != nil {
+			return fmt.Errorf("invalid label #%d: %v", i, err)
+		}
+		if strings.ContainsRune(l, '\n') {
+			return fmt.Errorf("invalid label #%d %q: it cannot contain newline characters", i, l)
+		}
+	}
+	if sw, uw := runewidth.StringWidth(c.Selected), runewidth.StringWidth(c.Unselected); sw != uw {
+		return fmt.Errorf("the markers %q and %q must have the same width, got %d and %d cells", c.Selected, c.Unselected, sw, uw)
+	}
+	return nil
+}
+
+// ToggleKey is the keyboard key that selects the choice under the cursor.
+const ToggleKey = ' '
+
+// Group is a group of choices displayed one per line with a marker that
+// indicates if the choice is selected.
+//
+// The up and down arrow keys move the cursor, the ToggleKey and a left mouse
+// click select the choice. In selection.ModeMultiple the choice is toggled
+// instead.
+//
+// This object is not thread-safe.
+type Group struct {
+	// cfg is the configuration.
+	cfg Config
+
+	// model tracks the cursor and the selected choices.
+	model *selection.Model
+
+	// disabled indicates if the group ignores events.
+	disabled bool
+
+	// rows are the areas occupied by the choices last time Draw() was
+	// called.
+	rows []image.Rectangle
+}
+
+// New returns a new group.
+func New(cfg Config) (*Group, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	model, err := selection.New(len(cfg.Labels), selection.SelectionMode(cfg.Mode))
+	if err != nil {
+		return nil, err
+	}
+	return &Group{
+		cfg:   cfg,
+		model: model,
+	}, nil
+}
+
+// Selected returns the indexes of the selected choices in ascending order.
+func (g *Group) Selected() []int {
+	return g.model.Selected()
+}
+
+// IsSelected determines if the choice at the index is selected.
+func (g *Group) IsSelected(i int) bool {
+	return g.model.IsSelected(i)
+}
+
+// Set selects or deselects the choice at the index. In selection.ModeSingle,
+// selecting a choice deselects all the others.
+// Doesn't move the cursor.
+func (g *Group) Set(i int, selected bool) error {
+	if i < 0 || i >= len(g.cfg.Labels) {
+		return fmt.Errorf("invalid choice index %d, must be in range 0 <= i < %d", i, len(g.cfg.Labels))
+	}
+	if g.model.IsSelected(i) == selected {
+		return nil
+	}
+	cursor := g.model.Cursor()
+	if err := g.model.Toggle(i); err != nil {
+		return err
+	}
+	g.model.SetCursor(cursor)
+	return nil
+}
+
+// Clear deselects all the choices.
+func (g *Group) Clear() {
+	g.model.Clear()
+}
+
+// SetDisabled disables or enables the group.
+func (g *Group) SetDisabled(disabled bool) {
+	g.disabled = disabled
+}
+
+// Disabled determines if the group is disabled.
+func (g *Group) Disabled() bool {
+	return g.disabled
+}
+
+// MinimumSize returns the size needed to display all the choices.
+func (g *Group) MinimumSize() image.Point {
+	var width int
+	for _, l := range g.cfg.Labels {
+		if w := g.rowWidth(l); w > width {
+			width = w
+		}
+	}
+	return image.Point{width, len(g.cfg.Labels)}
+}
+
+// rowWidth returns the width of the row with the label in cells.
+func (g *Group) rowWidth(label string) int {
+	return runewidth.StringWidth(g.cfg.Unselected) + runewidth.StringWidth(label)
+}
+
+// Draw draws the group onto the canvas, the cursor is highlighted if the
+// group is focused.
+func (g *Group) Draw(cvs *canvas.Canvas, focused bool) error {
+	ar := cvs.Area()
+	cursor := g.model.Cursor()
+	g.rows = nil
+	for i, l := range g.cfg.Labels {
+		y := ar.Min.Y + i
+		if y >= ar.Max.Y {
+			break
+		}
+		marker := g.cfg.Unselected
+		if g.model.IsSelected(i) {
+			marker = g.cfg.Selected
+		}
+		text := marker + l
+		lineAr := image.Rect(ar.Min.X, y, ar.Max.X, y+1)
+		start, err := alignfor.Text(lineAr, text, g.cfg.Align, align.VerticalTop)
+		if err != nil {
+			return err
+		}
+		g.rows = append(g.rows, image.Rect(start.X, y, start.X+g.rowWidth(l), y+1))
+
+		opts := []cell.Option{cell.FgColor(g.cfg.TextColor)}
+		if g.disabled {
+			opts = []cell.Option{cell.FgColor(g.cfg.DisabledColor)}
+		} else if focused && i == cursor {
+			opts = append(opts, cell.BgColor(g.cfg.HighlightedColor))
+		}
+		if err := draw.Text(cvs, text, start,
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextMaxX(ar.Max.X),
+			draw.TextCellOpts(opts...),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activate selects or toggles the choice at the index depending on the
+// selection mode.
+func (g *Group) activate(i int) (bool, error) {
+	before := g.model.Selected()
+	if g.cfg.Mode == selection.ModeSingle {
+		if err := g.model.Select(i); err != nil {
+			return false, err
+		}
+	} else {
+		if err := g.model.Toggle(i); err != nil {
+			return false, err
+		}
+	}
+	return !sameInts(before, g.model.Selected()), nil
+}
+
+// sameInts determines if the two slices contain the same values.
+func sameInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Keyboard processes keyboard events.
+// Returns true if the selected choices changed.
+func (g *Group) Keyboard(k *terminalapi.Keyboard) (bool, error) {
+	if g.disabled {
+		return false, nil
+	}
+
+	switch k.Key {
+	case keyboard.KeyArrowUp:
+		g.model.MoveCursor(-1)
+	case keyboard.KeyArrowDown:
+		g.model.MoveCursor(1)
+	case ToggleKey:
+		return g.activate(g.model.Cursor())
+	}
+	return false, nil
+}
+
+// Mouse processes mouse events.
+// Returns true if the selected choices changed.
+func (g *Group) Mouse(m *terminalapi.Mouse) (bool, error) {
+	if g.disabled || m.Button != mouse.ButtonLeft {
+		return false, nil
+	}
+
+	for i, row := range g.rows {
+		if m.Position.In(row) {
+			return g.activate(i)
+		}
+	}
+	return false, nil
+}
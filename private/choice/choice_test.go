@@ -0,0 +1,416 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package choice
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/selection"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// testConfig returns a configuration used in the tests.
+func testConfig(mode selection.Mode) Config {
+	return Config{
+		Labels:           []string{"one", "two", "three"},
+		Mode:             mode,
+		Selected:         "[x] ",
+		Unselected:       "[ ] ",
+		TextColor:        cell.ColorWhite,
+		HighlightedColor: cell.ColorBlue,
+		DisabledColor:    cell.ColorRed,
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		desc    string
+		cfg     func() Config
+		wantErr bool
+	}{
+		{
+			desc: "succeeds on a valid config",
+			cfg: func() Config {
+				return testConfig(selection.ModeSingle)
+			},
+		},
+		{
+			desc: "fails without labels",
+			cfg: func() Config {
+				cfg := testConfig(selection.ModeSingle)
+				cfg.Labels = nil
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on an empty label",
+			cfg: func() Config {
+				cfg := testConfig(selection.ModeSingle)
+				cfg.Labels = []string{"one", ""}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on a label with a newline",
+			cfg: func() Config {
+				cfg := testConfig(selection.ModeSingle)
+				cfg.Labels = []string{"one\ntwo"}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on markers of different widths",
+			cfg: func() Config {
+				cfg := testConfig(selection.ModeSingle)
+				cfg.Selected = "[x]"
+				return cfg
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := New(tc.cfg())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	tests := []struct {
+		desc         string
+		mode         selection.Mode
+		align        align.Horizontal
+		disabled     bool
+		events       []terminalapi.Event
+		wantSelected []int
+		wantChanges  int
+		wantCursor   int
+	}{
+		{
+			desc:         "nothing selected initially",
+			mode:         selection.ModeSingle,
+			wantSelected: []int{},
+		},
+		{
+			desc: "toggle key selects the choice under the cursor",
+			mode: selection.ModeSingle,
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: ToggleKey},
+			},
+			wantSelected: []int{1},
+			wantChanges:  1,
+			wantCursor:   1,
+		},
+		{
+			desc: "single mode selects only one choice and doesn't deselect it",
+			mode: selection.ModeSingle,
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: ToggleKey},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: ToggleKey},
+				&terminalapi.Keyboard{Key: ToggleKey},
+			},
+			wantSelected: []int{1},
+			wantChanges:  2,
+			wantCursor:   1,
+		},
+		{
+			desc: "multiple mode toggles the choices",
+			mode: selection.ModeMultiple,
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: ToggleKey},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: ToggleKey},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowUp},
+				&terminalapi.Keyboard{Key: ToggleKey},
+				&terminalapi.Keyboard{Key: ToggleKey},
+			},
+			wantSelected: []int{0, 2},
+			wantChanges:  4,
+			wantCursor:   1,
+		},
+		{
+			desc: "cursor stops at the last choice",
+			mode: selection.ModeSingle,
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+			},
+			wantSelected: []int{},
+			wantCursor:   2,
+		},
+		{
+			desc: "mouse click selects the clicked choice",
+			mode: selection.ModeSingle,
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{5, 2}, Button: mouse.ButtonLeft},
+			},
+			wantSelected: []int{2},
+			wantChanges:  1,
+			wantCursor:   2,
+		},
+		{
+			desc:  "mouse click on aligned choices",
+			mode:  selection.ModeSingle,
+			align: align.HorizontalRight,
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{6, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{12, 1}, Button: mouse.ButtonLeft},
+			},
+			wantSelected: []int{1},
+			wantChanges:  1,
+			wantCursor:   1,
+		},
+		{
+			desc: "ignores other mouse buttons",
+			mode: selection.ModeSingle,
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 0}, Button: mouse.ButtonRight},
+			},
+			wantSelected: []int{},
+		},
+		{
+			desc:     "disabled group ignores events",
+			mode:     selection.ModeSingle,
+			disabled: true,
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: ToggleKey},
+				&terminalapi.Mouse{Position: image.Point{1, 0}, Button: mouse.ButtonLeft},
+			},
+			wantSelected: []int{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := testConfig(tc.mode)
+			cfg.Align = tc.align
+			g, err := New(cfg)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			g.SetDisabled(tc.disabled)
+
+			cvs, err := canvas.New(image.Rect(0, 0, 15, 3))
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := g.Draw(cvs, true); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			var changes int
+			for _, ev := range tc.events {
+				var (
+					changed bool
+					err     error
+				)
+				switch e := ev.(type) {
+				case *terminalapi.Keyboard:
+					changed, err = g.Keyboard(e)
+				case *terminalapi.Mouse:
+					changed, err = g.Mouse(e)
+				default:
+					t.Fatalf("unsupported event type: %T", ev)
+				}
+				if err != nil {
+					t.Fatalf("event %v => unexpected error: %v", ev, err)
+				}
+				if changed {
+					changes++
+				}
+			}
+
+			if diff := pretty.Compare(tc.wantSelected, g.Selected()); diff != "" {
+				t.Errorf("Selected => unexpected diff (-want, +got):\n%s", diff)
+			}
+			if changes != tc.wantChanges {
+				t.Errorf("reported %d changes, want %d", changes, tc.wantChanges)
+			}
+			if got := g.model.Cursor(); got != tc.wantCursor {
+				t.Errorf("Cursor => %d, want %d", got, tc.wantCursor)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	g, err := New(testConfig(selection.ModeSingle))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	if err := g.Set(3, true); err == nil {
+		t.Errorf("Set(3, true) => got nil error, want an error for an invalid index")
+	}
+	for _, i := range []int{0, 2} {
+		if err := g.Set(i, true); err != nil {
+			t.Fatalf("Set(%d, true) => unexpected error: %v", i, err)
+		}
+	}
+	if diff := pretty.Compare([]int{2}, g.Selected()); diff != "" {
+		t.Errorf("Selected => unexpected diff (-want, +got):\n%s", diff)
+	}
+	if got, want := g.model.Cursor(), 0; got != want {
+		t.Errorf("Cursor => %d, want %d, Set shouldn't move the cursor", got, want)
+	}
+
+	if err := g.Set(2, false); err != nil {
+		t.Fatalf("Set(2, false) => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare([]int{}, g.Selected()); diff != "" {
+		t.Errorf("Selected => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	tests := []struct {
+		desc     string
+		align    align.Horizontal
+		selected []int
+		focused  bool
+		disabled bool
+		want     func(size image.Point) *faketerm.Terminal
+	}{
+		{
+			desc:     "draws the choices and the markers",
+			selected: []int{1},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				opts := draw.TextCellOpts(cell.FgColor(cell.ColorWhite))
+				testdraw.MustText(cvs, "[ ] one", image.Point{0, 0}, opts)
+				testdraw.MustText(cvs, "[x] two", image.Point{0, 1}, opts)
+				testdraw.MustText(cvs, "[ ] three", image.Point{0, 2}, opts)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "highlights the cursor when focused",
+			focused: true,
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				opts := draw.TextCellOpts(cell.FgColor(cell.ColorWhite))
+				testdraw.MustText(cvs, "[ ] one", image.Point{0, 0}, draw.TextCellOpts(
+					cell.FgColor(cell.ColorWhite),
+					cell.BgColor(cell.ColorBlue),
+				))
+				testdraw.MustText(cvs, "[ ] two", image.Point{0, 1}, opts)
+				testdraw.MustText(cvs, "[ ] three", image.Point{0, 2}, opts)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "disabled group uses the disabled color without highlight",
+			focused:  true,
+			disabled: true,
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				opts := draw.TextCellOpts(cell.FgColor(cell.ColorRed))
+				testdraw.MustText(cvs, "[ ] one", image.Point{0, 0}, opts)
+				testdraw.MustText(cvs, "[ ] two", image.Point{0, 1}, opts)
+				testdraw.MustText(cvs, "[ ] three", image.Point{0, 2}, opts)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:  "aligns the choices",
+			align: align.HorizontalCenter,
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				opts := draw.TextCellOpts(cell.FgColor(cell.ColorWhite))
+				testdraw.MustText(cvs, "[ ] one", image.Point{4, 0}, opts)
+				testdraw.MustText(cvs, "[ ] two", image.Point{4, 1}, opts)
+				testdraw.MustText(cvs, "[ ] three", image.Point{3, 2}, opts)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := testConfig(selection.ModeMultiple)
+			cfg.Align = tc.align
+			g, err := New(cfg)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			g.SetDisabled(tc.disabled)
+			for _, i := range tc.selected {
+				if err := g.Set(i, true); err != nil {
+					t.Fatalf("Set => unexpected error: %v", err)
+				}
+			}
+
+			cvs, err := canvas.New(image.Rect(0, 0, 15, 3))
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := g.Draw(cvs, tc.focused); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(cvs.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := cvs.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(cvs.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestMinimumSize(t *testing.T) {
+	g, err := New(testConfig(selection.ModeSingle))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if got, want := g.MinimumSize(), (image.Point{9, 3}); got != want {
+		t.Errorf("MinimumSize => %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package doublebuffer implements a double buffer for terminal
+// implementations.
+//
+// The content is drawn into the back buffer, while the front buffer holds the
+// content that was last flushed to the terminal. Flushing only writes the
+// cells that differ between the two buffers, which reduces the amount of work
+// the terminal has to do when most of the screen doesn't change between
+// frames.
+package doublebuffer
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/runewidth"
+)
+
+// content is the content of a single cell.
+type content struct {
+	r rune
+	// combining are the combining characters drawn over the rune, stored as
+	// a string so that the contents remain comparable.
+	combining string
+	opts      cell.Options
+}
+
+// width returns the number of cells the content occupies on the terminal.
+func (c *content) width() int {
+	if c.combining == "" {
+		return runewidth.RuneWidth(c.r)
+	}
+	t := runewidth.CellTracker(c.r, nil)
+	for _, r := range c.combining {
+		t.Add(r)
+	}
+	return t.Width()
+}
+
+// newContents returns a new two dimensional slice of contents indexed as
+// [x][y].
+func newContents(size image.Point) [][]content {
+	cs := make([][]content, size.X)
+	for x := range cs {
+		cs[x] = make([]content, size.Y)
+	}
+	return cs
+}
+
+// Buffer is a double buffer.
+// This object is not thread-safe.
+type Buffer struct {
+	// back is the buffer the content is drawn into.
+	back [][]content
+	// front is the content that was last flushed.
+	front [][]content
+	// valid indicates whether the front buffer matches the terminal. If it
+	// doesn't, the next flush writes all the cells.
+	valid bool
+	// stale are the rows that must be written entirely, because FlushChunk
+	// didn't reach them since the buffer was invalidated. Nil if there are
+	// no such rows.
+	stale []bool
+	// next is the row FlushChunk starts with.
+	next int
+	// partial indicates that the last FlushChunk stopped before it reached
+	// all the rows, see Pending.
+	partial bool
+	// size is the size of both buffers.
+	size image.Point
+}
+
+// New returns a new double buffer of the specified size.
+func New(size image.Point) (*Buffer, error) {
+	if size.X < 0 || size.Y < 0 {
+		return nil, fmt.Errorf("invalid buffer size %v, the dimensions cannot be negative", size)
+	}
+	return &Buffer{
+		back:  newContents(size),
+		front: newContents(size),
+		size:  size,
+	}, nil
+}
+
+// Size returns the size of the buffer.
+func (b *Buffer) Size() image.Point {
+	return b.size
+}
+
+// Resize resizes the buffer to the specified size. The content of the back
+// buffer that fits the new size is preserved, the next flush writes all the
+// cells.
+func (b *Buffer) Resize(size image.Point) error {
+	if size.X < 0 || size.Y < 0 {
+		return fmt.Errorf("invalid buffer size %v, the dimensions cannot be negative", size)
+	}
+
+	back := newContents(size)
+	for x := 0; x < size.X && x < b.size.X; x++ {
+		copy(back[x], b.back[x])
+	}
+	b.back = back
+	b.front = newContents(size)
+	b.size = size
+	b.valid = false
+	b.stale = nil
+	b.next = 0
+	return nil
+}
+
+// Invalidate makes the next flush write all the cells, e.g. when the terminal
+// was cleared and no longer matches the front buffer.
+func (b *Buffer) Invalidate() {
+	b.valid = false
+}
+
+// Clear resets all the cells in the back buffer to their zero value and sets
+// the provided options on them.
+func (b *Buffer) Clear(opts ...cell.Option) {
+	o := cell.NewOptions(opts...)
+	for x := range b.back {
+		for y := range b.back[x] {
+			b.back[x][y] = content{opts: *o}
+		}
+	}
+}
+
+// SetCell sets the rune and the options of the cell in the back buffer.
+// Unlike the cell options on the canvas, any options that aren't specified
+// are reset to their default values.
+func (b *Buffer) SetCell(p image.Point, r rune, opts ...cell.Option) error {
+	return b.SetCellCombining(p, r, nil, opts...)
+}
+
+// SetCellCombining is like SetCell, but also sets the combining characters
+// drawn over the rune.
+func (b *Buffer) SetCellCombining(p image.Point, r rune, combining []rune, opts ...cell.Option) error {
+	if !p.In(image.Rectangle{Max: b.size}) {
+		return fmt.Errorf("point %v falls outside of the buffer of size %v", p, b.size)
+	}
+	c := &b.back[p.X][p.Y]
+	c.r = r
+	c.combining = string(combining)
+	c.opts = cell.Options{}
+	for _, opt := range opts {
+		opt.Set(&c.opts)
+	}
+	return nil
+}
+
+// SetCellFunc writes a single cell to the terminal. The combining characters
+// are nil unless set with SetCellCombining.
+type SetCellFunc func(p image.Point, r rune, combining []rune, opts *cell.Options) error
+
+// Flush calls the provided function for each cell that differs between the
+// back and the front buffer and updates the front buffer.
+// Cells that are occupied by a full-width rune in the previous cell are
+// skipped. Returns the number of written cells.
+func (b *Buffer) Flush(fn SetCellFunc) (int, error) {
+	var written int
+	for y := 0; y < b.size.Y; y++ {
+		w, err := b.flushRow(y, !b.valid || b.staleRow(y), fn)
+		written += w
+		if err != nil {
+			return written, err
+		}
+	}
+	b.valid = true
+	b.stale = nil
+	b.next = 0
+	b.partial = false
+	return written, nil
+}
+
+// Pending asserts whether the last call to FlushChunk stopped at the limit
+// before it reached all the rows, i.e. whether some changed cells might not
+// be written yet.
+func (b *Buffer) Pending() bool {
+	return b.partial
+}
+
+// FlushChunk is like Flush, but stops after the row in which the number of
+// written cells reached the limit, so that a single flush doesn't take too
+// long on very large terminals. The next call continues with the following
+// row, all the rows are written once enough calls were made.
+// Returns the number of written cells.
+func (b *Buffer) FlushChunk(fn SetCellFunc, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, fmt.Errorf("invalid limit %d, must be a positive integer", limit)
+	}
+	if !b.valid {
+		// Marks the rows individually, the flush might not reach all of them.
+		b.stale = make([]bool, b.size.Y)
+		for y := range b.stale {
+			b.stale[y] = true
+		}
+		b.valid = true
+	}
+
+	var written int
+	for i := 0; i < b.size.Y; i++ {
+		y := (b.next + i) % b.size.Y
+		w, err := b.flushRow(y, b.staleRow(y), fn)
+		written += w
+		if err != nil {
+			return written, err
+		}
+		if b.stale != nil {
+			b.stale[y] = false
+		}
+		if written >= limit && i < b.size.Y-1 {
+			b.next = (y + 1) % b.size.Y
+			b.partial = true
+			return written, nil
+		}
+	}
+	b.partial = false
+	return written, nil
+}
+
+// staleRow asserts whether all the cells in the row must be written, because
+// the row wasn't flushed since the buffer was invalidated.
+func (b *Buffer) staleRow(y int) bool {
+	return b.stale != nil && b.stale[y]
+}
+
+// flushRow calls the provided function for each cell in the row that differs
+// between the back and the front buffer, or for all the cells in the row if
+// all is true.
+func (b *Buffer) flushRow(y int, all bool, fn SetCellFunc) (int, error) {
+	var written int
+	// force is set when the cell must be written, because the previous
+	// cell contained a full-width rune that was overwritten.
+	force := false
+	for x := 0; x < b.size.X; x++ {
+		bc, fc := &b.back[x][y], &b.front[x][y]
+		if x > 0 && b.back[x-1][y].width() == 2 {
+			// A partial cell, occupied by the full-width rune in the
+			// previous cell.
+			*fc = *bc
+			force = false
+			continue
+		}
+		if !all && !force && *bc == *fc {
+			continue
+		}
+
+		force = fc.width() == 2
+		var combining []rune
+		if bc.combining != "" {
+			combining = []rune(bc.combining)
+		}
+		if err := fn(image.Point{x, y}, bc.r, combining, &bc.opts); err != nil {
+			return written, err
+		}
+		*fc = *bc
+		written++
+	}
+	return written, nil
+}
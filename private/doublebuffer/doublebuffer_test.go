@@ -0,0 +1,347 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doublebuffer
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+)
+
+// written is a cell written during a flush.
+type written struct {
+	P         image.Point
+	R         rune
+	Combining string
+	Opts      cell.Options
+}
+
+// flush flushes the buffer and returns the written cells.
+func flush(t *testing.T, b *Buffer) []written {
+	t.Helper()
+
+	var res []written
+	n, err := b.Flush(func(p image.Point, r rune, combining []rune, opts *cell.Options) error {
+		res = append(res, written{p, r, string(combining), *opts})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Flush => unexpected error: %v", err)
+	}
+	if n != len(res) {
+		t.Errorf("Flush => reported %d written cells, but called the function %d times", n, len(res))
+	}
+	return res
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(image.Point{-1, 1}); err == nil {
+		t.Errorf("New => expected an error for a negative size")
+	}
+	b, err := New(image.Point{2, 3})
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if got, want := b.Size(), (image.Point{2, 3}); got != want {
+		t.Errorf("Size => %v, want %v", got, want)
+	}
+}
+
+func TestSetCellFailsOutsideOfBuffer(t *testing.T) {
+	b, err := New(image.Point{2, 2})
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := b.SetCell(image.Point{2, 0}, 'a'); err == nil {
+		t.Errorf("SetCell => expected an error")
+	}
+}
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		desc   string
+		size   image.Point
+		frames []func(*Buffer)
+		want   []written // Cells written when flushing the last frame.
+	}{
+		{
+			desc: "the first flush writes all the cells",
+			size: image.Point{2, 1},
+			frames: []func(*Buffer){
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'a')
+				},
+			},
+			want: []written{
+				{P: image.Point{0, 0}, R: 'a'},
+				{P: image.Point{1, 0}},
+			},
+		},
+		{
+			desc: "writes only changed cells",
+			size: image.Point{3, 2},
+			frames: []func(*Buffer){
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'a')
+					b.SetCell(image.Point{1, 1}, 'b')
+				},
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'a')
+					b.SetCell(image.Point{1, 1}, 'c')
+					b.SetCell(image.Point{2, 0}, ' ', cell.FgColor(cell.ColorRed))
+				},
+			},
+			want: []written{
+				{P: image.Point{2, 0}, R: ' ', Opts: cell.Options{FgColor: cell.ColorRed}},
+				{P: image.Point{1, 1}, R: 'c'},
+			},
+		},
+		{
+			desc: "writes nothing when nothing changed",
+			size: image.Point{2, 2},
+			frames: []func(*Buffer){
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'a')
+				},
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'a')
+				},
+			},
+		},
+		{
+			desc: "writes cells whose combining characters changed",
+			size: image.Point{1, 1},
+			frames: []func(*Buffer){
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'e')
+				},
+				func(b *Buffer) {
+					b.SetCellCombining(image.Point{0, 0}, 'e', []rune{'\u0301'})
+				},
+			},
+			want: []written{
+				{P: image.Point{0, 0}, R: 'e', Combining: "\u0301"},
+			},
+		},
+		{
+			desc: "unspecified options are reset to their defaults",
+			size: image.Point{1, 1},
+			frames: []func(*Buffer){
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'a', cell.FgColor(cell.ColorRed))
+				},
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'a')
+				},
+			},
+			want: []written{
+				{P: image.Point{0, 0}, R: 'a'},
+			},
+		},
+		{
+			desc: "skips partial cells of full-width runes",
+			size: image.Point{3, 1},
+			frames: []func(*Buffer){
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, '世')
+				},
+			},
+			want: []written{
+				{P: image.Point{0, 0}, R: '世'},
+				{P: image.Point{2, 0}},
+			},
+		},
+		{
+			desc: "rewrites the cell after an overwritten full-width rune",
+			size: image.Point{3, 1},
+			frames: []func(*Buffer){
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, '世')
+				},
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'a')
+				},
+			},
+			want: []written{
+				{P: image.Point{0, 0}, R: 'a'},
+				{P: image.Point{1, 0}},
+			},
+		},
+		{
+			desc: "invalidate writes all the cells",
+			size: image.Point{2, 1},
+			frames: []func(*Buffer){
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'a')
+				},
+				func(b *Buffer) {
+					b.Invalidate()
+				},
+			},
+			want: []written{
+				{P: image.Point{0, 0}, R: 'a'},
+				{P: image.Point{1, 0}},
+			},
+		},
+		{
+			desc: "clear resets the cells and sets the options",
+			size: image.Point{2, 1},
+			frames: []func(*Buffer){
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'a')
+				},
+				func(b *Buffer) {
+					b.Clear(cell.BgColor(cell.ColorBlue))
+				},
+			},
+			want: []written{
+				{P: image.Point{0, 0}, Opts: cell.Options{BgColor: cell.ColorBlue}},
+				{P: image.Point{1, 0}, Opts: cell.Options{BgColor: cell.ColorBlue}},
+			},
+		},
+		{
+			desc: "resize preserves the content and writes all the cells",
+			size: image.Point{2, 1},
+			frames: []func(*Buffer){
+				func(b *Buffer) {
+					b.SetCell(image.Point{0, 0}, 'a')
+					b.SetCell(image.Point{1, 0}, 'b')
+				},
+				func(b *Buffer) {
+					if err := b.Resize(image.Point{1, 2}); err != nil {
+						panic(err)
+					}
+				},
+			},
+			want: []written{
+				{P: image.Point{0, 0}, R: 'a'},
+				{P: image.Point{0, 1}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			b, err := New(tc.size)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+
+			var got []written
+			for _, frame := range tc.frames {
+				frame(b)
+				got = flush(t, b)
+			}
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("Flush => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// flushChunk flushes a chunk of the buffer and returns the written cells.
+func flushChunk(t *testing.T, b *Buffer, limit int) []written {
+	t.Helper()
+
+	var res []written
+	n, err := b.FlushChunk(func(p image.Point, r rune, combining []rune, opts *cell.Options) error {
+		res = append(res, written{P: p, R: r, Combining: string(combining), Opts: *opts})
+		return nil
+	}, limit)
+	if err != nil {
+		t.Fatalf("FlushChunk => unexpected error: %v", err)
+	}
+	if n != len(res) {
+		t.Errorf("FlushChunk => reported %d written cells, but called the function %d times", n, len(res))
+	}
+	return res
+}
+
+func TestFlushChunk(t *testing.T) {
+	b, err := New(image.Point{2, 3})
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if _, err := b.FlushChunk(func(image.Point, rune, []rune, *cell.Options) error { return nil }, 0); err == nil {
+		t.Errorf("FlushChunk => got nil error, want one for a zero limit")
+	}
+
+	// The first flushes write all the rows, one row each.
+	b.SetCell(image.Point{0, 2}, 'a')
+	for y, want := range [][]written{
+		{{P: image.Point{0, 0}}, {P: image.Point{1, 0}}},
+		{{P: image.Point{0, 1}}, {P: image.Point{1, 1}}},
+		{{P: image.Point{0, 2}, R: 'a'}, {P: image.Point{1, 2}}},
+		nil,
+	} {
+		got := flushChunk(t, b, 2)
+		if diff := pretty.Compare(want, got); diff != "" {
+			t.Errorf("FlushChunk #%d => unexpected diff (-want, +got):\n%s", y, diff)
+		}
+		// The last flush reaches all the rows without hitting the limit.
+		if got, want := b.Pending(), y < 3; got != want {
+			t.Errorf("Pending after FlushChunk #%d => %v, want %v", y, got, want)
+		}
+	}
+
+	// Continues with the row after the last written one and completes the
+	// row that reached the limit.
+	b.SetCell(image.Point{0, 0}, 'b')
+	b.SetCell(image.Point{1, 0}, 'c')
+	b.SetCell(image.Point{1, 1}, 'd')
+	b.SetCell(image.Point{0, 2}, 'e')
+	want := []written{
+		{P: image.Point{0, 0}, R: 'b'},
+		{P: image.Point{1, 0}, R: 'c'},
+	}
+	if diff := pretty.Compare(want, flushChunk(t, b, 1)); diff != "" {
+		t.Errorf("FlushChunk => unexpected diff (-want, +got):\n%s", diff)
+	}
+	want = []written{
+		{P: image.Point{1, 1}, R: 'd'},
+		{P: image.Point{0, 2}, R: 'e'},
+	}
+	if diff := pretty.Compare(want, flushChunk(t, b, 5)); diff != "" {
+		t.Errorf("FlushChunk => unexpected diff (-want, +got):\n%s", diff)
+	}
+	if b.Pending() {
+		t.Errorf("Pending => true after a FlushChunk below the limit, want false")
+	}
+
+	// Flush writes the rows the chunks didn't reach since the buffer was
+	// invalidated.
+	b.Invalidate()
+	want = []written{
+		{P: image.Point{0, 1}},
+		{P: image.Point{1, 1}, R: 'd'},
+	}
+	if diff := pretty.Compare(want, flushChunk(t, b, 2)); diff != "" {
+		t.Errorf("FlushChunk => unexpected diff (-want, +got):\n%s", diff)
+	}
+	want = []written{
+		{P: image.Point{0, 0}, R: 'b'},
+		{P: image.Point{1, 0}, R: 'c'},
+		{P: image.Point{0, 2}, R: 'e'},
+		{P: image.Point{1, 2}},
+	}
+	if diff := pretty.Compare(want, flush(t, b)); diff != "" {
+		t.Errorf("Flush => unexpected diff (-want, +got):\n%s", diff)
+	}
+	if b.Pending() {
+		t.Errorf("Pending => true after Flush, want false")
+	}
+}
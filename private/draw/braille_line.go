@@ -59,6 +59,7 @@ type BrailleLineOption interface {
 type brailleLineOptions struct {
 	cellOpts    []cell.Option
 	pixelChange braillePixelChange
+	dash, gap   int
 }
 
 // newBrailleLineOptions returns a new brailleLineOptions instance.
@@ -94,6 +95,18 @@ func BrailleLineClearPixels() BrailleLineOption {
 	})
 }
 
+// BrailleLineDashed makes BrailleLine draw a dashed line, where dashes of
+// the specified number of pixels alternate with gaps of the specified number
+// of pixels. The pattern is aligned to the X coordinates of the pixels, so
+// lines that continue each other continue the pattern too.
+// Ignored unless both dash and gap are positive.
+func BrailleLineDashed(dash, gap int) BrailleLineOption {
+	return brailleLineOption(func(opts *brailleLineOptions) {
+		opts.dash = dash
+		opts.gap = gap
+	})
+}
+
 // BrailleLine draws an approximated line segment on the braille canvas between
 // the two provided points.
 // Both start and end must be valid points within the canvas. Start and end can
@@ -115,6 +128,9 @@ func BrailleLine(bc *braille.Canvas, start, end image.Point, opts ...BrailleLine
 
 	points := brailleLinePoints(start, end)
 	for _, p := range points {
+		if opt.dash > 0 && opt.gap > 0 && p.X%(opt.dash+opt.gap) >= opt.dash {
+			continue
+		}
 		switch opt.pixelChange {
 		case braillePixelChangeSet:
 			if err := bc.SetPixel(p, opt.cellOpts...); err != nil {
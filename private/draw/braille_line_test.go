@@ -131,6 +131,42 @@ func TestBrailleLine(t *testing.T) {
 				return ft
 			},
 		},
+		{
+			desc:   "draws a dashed line",
+			canvas: image.Rect(0, 0, 4, 1),
+			start:  image.Point{1, 0},
+			end:    image.Point{7, 0},
+			opts: []BrailleLineOption{
+				BrailleLineDashed(2, 1),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				bc := testbraille.MustNew(ft.Area())
+				for _, x := range []int{1, 3, 4, 6, 7} {
+					testbraille.MustSetPixel(bc, image.Point{x, 0})
+				}
+				testbraille.MustApply(bc, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "draws a solid line when the gap isn't positive",
+			canvas: image.Rect(0, 0, 2, 1),
+			start:  image.Point{0, 0},
+			end:    image.Point{3, 0},
+			opts: []BrailleLineOption{
+				BrailleLineDashed(2, 0),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				bc := testbraille.MustNew(ft.Area())
+				for x := 0; x <= 3; x++ {
+					testbraille.MustSetPixel(bc, image.Point{x, 0})
+				}
+				testbraille.MustApply(bc, ft)
+				return ft
+			},
+		},
 		{
 			desc:   "draws single point with cell options",
 			canvas: image.Rect(0, 0, 1, 1),
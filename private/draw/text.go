@@ -124,17 +124,19 @@ func TrimText(text string, maxCells int, om OverrunMode) (string, error) {
 		return "", fmt.Errorf("unsupported overrun mode %d", om)
 	}
 
+	// The text is trimmed at the boundaries of grapheme clusters, so that
+	// combining characters are never separated from their base runes.
 	var b strings.Builder
 	cur := 0
-	for _, r := range text {
-		rw := runewidth.RuneWidth(r)
-		if cur+rw >= maxCells {
+	for _, c := range runewidth.Clusters(text) {
+		cw := runewidth.StringWidth(c)
+		if cur+cw >= maxCells {
 			switch {
 			case om == OverrunModeTrim:
-				// Only write the rune if it still fits, i.e. don't cut
+				// Only write the cluster if it still fits, i.e. don't cut
 				// full-width runes in half.
-				if cur+rw == maxCells {
-					b.WriteRune(r)
+				if cur+cw == maxCells {
+					b.WriteString(c)
 				}
 			case om == OverrunModeThreeDot:
 				b.WriteRune('…')
@@ -142,8 +144,8 @@ func TrimText(text string, maxCells int, om OverrunMode) (string, error) {
 			break
 		}
 
-		b.WriteRune(r)
-		cur += rw
+		b.WriteString(c)
+		cur += cw
 	}
 	return b.String(), nil
 }
@@ -54,6 +54,41 @@ func TestTrimText(t *testing.T) {
 			om:       OverrunMode(-1),
 			wantErr:  true,
 		},
+		{
+			desc:     "combining characters, OverrunModeTrim, marks stay with their base",
+			text:     "e\u0301e\u0301e\u0301",
+			maxCells: 2,
+			om:       OverrunModeTrim,
+			want:     "e\u0301e\u0301",
+		},
+		{
+			desc:     "combining characters, OverrunModeThreeDot, marks stay with their base",
+			text:     "e\u0301e\u0301e\u0301",
+			maxCells: 2,
+			om:       OverrunModeThreeDot,
+			want:     "e\u0301…",
+		},
+		{
+			desc:     "combining characters, text fits exactly",
+			text:     "e\u0301e\u0301",
+			maxCells: 2,
+			om:       OverrunModeStrict,
+			want:     "e\u0301e\u0301",
+		},
+		{
+			desc:     "emoji sequences, OverrunModeTrim, sequences stay whole",
+			text:     "👨\u200d👩❤\ufe0f🇺🇸",
+			maxCells: 5,
+			om:       OverrunModeTrim,
+			want:     "👨\u200d👩❤\ufe0f",
+		},
+		{
+			desc:     "emoji sequences, OverrunModeThreeDot",
+			text:     "👋🏻👋🏻",
+			maxCells: 3,
+			om:       OverrunModeThreeDot,
+			want:     "👋🏻…",
+		},
 		{
 			desc:     "half-width runes, OverrunModeStrict, text fits exactly",
 			text:     "ab",
@@ -54,6 +54,9 @@ type subscriber struct {
 	// cancel when called terminates the goroutine that forwards events towards
 	// this subscriber.
 	cancel context.CancelFunc
+	// stopOnce ensures the subscriber is only stopped once, both Close and
+	// the StopFunc returned by Subscribe stop it.
+	stopOnce sync.Once
 
 	// processes is the number of events that were fully processed, i.e.
 	// delivered to the callback.
@@ -138,9 +141,12 @@ func (s *subscriber) processedEvents() int {
 }
 
 // stop stops the event subscriber.
+// This method is idempotent.
 func (s *subscriber) stop() {
-	s.cancel()
-	s.queue.Close()
+	s.stopOnce.Do(func() {
+		s.cancel()
+		s.queue.Close()
+	})
 }
 
 // DistributionSystem distributes events to subscribers.
@@ -158,6 +164,10 @@ type DistributionSystem struct {
 	// maps subscriber id to subscriber.
 	subscribers map[int]*subscriber
 
+	// closed are the subscribers stopped by Close, they still count towards
+	// the processed events.
+	closed []*subscriber
+
 	// nextID is id for the next subscriber.
 	nextID int
 
@@ -246,6 +256,19 @@ func (eds *DistributionSystem) Subscribe(filter []terminalapi.Event, cb Callback
 	}
 }
 
+// Close stops all the subscribers and their goroutines, the subscribers
+// don't receive any more events.
+func (eds *DistributionSystem) Close() {
+	eds.mu.Lock()
+	defer eds.mu.Unlock()
+
+	for id, sub := range eds.subscribers {
+		sub.stop()
+		eds.closed = append(eds.closed, sub)
+		delete(eds.subscribers, id)
+	}
+}
+
 // Processed returns the number of events that were fully processed, i.e.
 // delivered to all the subscribers and their callbacks returned.
 func (eds *DistributionSystem) Processed() int {
@@ -256,5 +279,8 @@ func (eds *DistributionSystem) Processed() int {
 	for _, sub := range eds.subscribers {
 		res += sub.processedEvents()
 	}
+	for _, sub := range eds.closed {
+		res += sub.processedEvents()
+	}
 	return res
 }
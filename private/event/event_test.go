@@ -423,3 +423,78 @@ func TestProcessed(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkDistributionSystemMouse(b *testing.B) {
+	eds := NewDistributionSystem()
+	var (
+		mu        sync.Mutex
+		delivered int
+	)
+	stopMouse := eds.Subscribe([]terminalapi.Event{&terminalapi.Mouse{}}, func(terminalapi.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered++
+	})
+	defer stopMouse()
+	// Subscriber that filters out all the mouse events.
+	stopKey := eds.Subscribe([]terminalapi.Event{&terminalapi.Keyboard{}}, func(terminalapi.Event) {})
+	defer stopKey()
+
+	ev := &terminalapi.Mouse{Position: image.Point{1, 1}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		eds.Event(ev)
+	}
+	b.StopTimer()
+
+	if err := testevent.WaitFor(5*time.Second, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		if delivered != b.N {
+			return fmt.Errorf("delivered %d events, want %d", delivered, b.N)
+		}
+		return nil
+	}); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	eds := NewDistributionSystem()
+	rec := newReceiver(receiverModeReceive)
+	eds.Subscribe(nil, rec.receive)
+
+	eds.Event(&terminalapi.Keyboard{Key: keyboard.KeyEnter})
+	if err := testevent.WaitFor(5*time.Second, func() error {
+		if got := eds.Processed(); got != 1 {
+			return fmt.Errorf("processed %d events, want 1", got)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("testevent.WaitFor => %v", err)
+	}
+
+	eds.Close()
+	eds.Event(&terminalapi.Keyboard{Key: keyboard.KeyEsc})
+	time.Sleep(10 * time.Millisecond)
+
+	if got := rec.getEvents(); len(got) != 1 {
+		t.Errorf("getEvents => got %v, want only the event sent before Close", got)
+	}
+	if got := eds.Processed(); got != 1 {
+		t.Errorf("Processed => %v, want the event processed before Close to still count", got)
+	}
+}
+
+func TestStopAfterClose(t *testing.T) {
+	eds := NewDistributionSystem()
+	rec := newReceiver(receiverModeReceive)
+	stop := eds.Subscribe(nil, rec.receive)
+
+	eds.Close()
+	// Must not panic on the subscriber stopped by Close.
+	stop()
+	stop()
+	eds.Close()
+}
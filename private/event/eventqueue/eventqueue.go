@@ -31,13 +31,25 @@ type node struct {
 	event terminalapi.Event
 }
 
+// maxFreeNodes is the maximum number of released nodes the queue keeps for
+// reuse. Limits the memory retained after a burst of events.
+const maxFreeNodes = 1024
+
 // Unbound is an unbound FIFO queue of terminal events.
 // Unbound must not be copied, pass it by reference only.
 // This implementation is thread-safe.
 type Unbound struct {
 	first *node
 	last  *node
-	// mu protects first and last.
+
+	// free is a list of nodes that were popped and can be reused.
+	// Reusing nodes keeps Push and Pop free of allocations when events
+	// arrive at high frequency, e.g. mouse movements.
+	free *node
+	// freeLen is the number of nodes in the free list.
+	freeLen int
+
+	// mu protects first, last and the free list.
 	mu sync.Mutex
 
 	// cond is used to notify any callers waiting on a call to Pull().
@@ -96,12 +108,41 @@ func (u *Unbound) Push(e terminalapi.Event) {
 	u.push(e)
 }
 
+// newNode returns a node holding the event, reusing a released node if one
+// is available.
+// Caller must hold u.mu.
+func (u *Unbound) newNode(e terminalapi.Event) *node {
+	if u.free == nil {
+		return &node{event: e}
+	}
+
+	n := u.free
+	u.free = n.next
+	u.freeLen--
+	n.next = nil
+	n.event = e
+	return n
+}
+
+// releaseNode returns the node to the free list so it can be reused.
+// Caller must hold u.mu.
+func (u *Unbound) releaseNode(n *node) {
+	// Don't retain the event, it might reference large objects.
+	n.event = nil
+	n.prev = nil
+	if u.freeLen >= maxFreeNodes {
+		n.next = nil
+		return
+	}
+	n.next = u.free
+	u.free = n
+	u.freeLen++
+}
+
 // push is the implementation of Push.
 // Caller must hold u.mu.
 func (u *Unbound) push(e terminalapi.Event) {
-	n := &node{
-		event: e,
-	}
+	n := u.newNode(e)
 	if u.empty() {
 		u.first = n
 		u.last = n
@@ -128,8 +169,13 @@ func (u *Unbound) Pop() terminalapi.Event {
 
 	if u.empty() {
 		u.last = nil
+	} else {
+		u.first.prev = nil
 	}
-	return n.event
+
+	e := n.event
+	u.releaseNode(n)
+	return e
 }
 
 // Pull is like Pop(), but blocks until an item is available or the context
@@ -156,8 +202,17 @@ func (u *Unbound) Pull(ctx context.Context) terminalapi.Event {
 }
 
 // Close should be called when the queue isn't needed anymore.
+// Wakes up the goroutines waiting in Pull, which return if their context
+// expired. Cancel the context before calling Close, since the waiting
+// goroutines are no longer woken up periodically afterwards.
 func (u *Unbound) Close() {
 	close(u.done)
+
+	// Pull holds the lock between checking the context and waiting, so the
+	// wake up can't be lost.
+	u.cond.L.Lock()
+	defer u.cond.L.Unlock()
+	u.cond.Broadcast()
 }
 
 // Throttled is an unbound and throttled FIFO queue of terminal events.
@@ -201,7 +256,7 @@ func (t *Throttled) Push(e terminalapi.Event) {
 
 	var same int
 	for n := t.queue.last; n != nil; n = n.prev {
-		if reflect.DeepEqual(e, n.event) {
+		if sameEvent(e, n.event) {
 			same++
 		} else {
 			break
@@ -214,6 +269,30 @@ func (t *Throttled) Push(e terminalapi.Event) {
 	t.queue.push(e)
 }
 
+// sameEvent determines if the two events are equal.
+// Compares the event types that arrive at high frequency directly, since
+// reflect.DeepEqual is comparatively slow.
+func sameEvent(a, b terminalapi.Event) bool {
+	switch av := a.(type) {
+	case *terminalapi.Mouse:
+		bv, ok := b.(*terminalapi.Mouse)
+		if !ok || av == nil || bv == nil {
+			return ok && av == bv
+		}
+		return *av == *bv
+
+	case *terminalapi.Keyboard:
+		bv, ok := b.(*terminalapi.Keyboard)
+		if !ok || av == nil || bv == nil {
+			return ok && av == bv
+		}
+		return *av == *bv
+
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
 // Pop pops an event from the queue. Returns nil if the queue is empty.
 func (t *Throttled) Pop() terminalapi.Event {
 	return t.queue.Pop()
@@ -226,6 +305,7 @@ func (t *Throttled) Pull(ctx context.Context) terminalapi.Event {
 }
 
 // Close should be called when the queue isn't needed anymore.
+// See Unbound.Close.
 func (t *Throttled) Close() {
-	close(t.queue.done)
+	t.queue.Close()
 }
@@ -16,10 +16,13 @@ package eventqueue
 
 import (
 	"context"
+	"image"
 	"testing"
 	"time"
 
 	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
 	"github.com/mum4k/termdash/terminal/terminalapi"
 )
 
@@ -115,6 +118,27 @@ func TestPullBlocksUntilAvailable(t *testing.T) {
 	}
 }
 
+func TestCloseWakesUpPull(t *testing.T) {
+	q := New()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Pull(ctx)
+	}()
+	// Gives the goroutine time to start waiting.
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	q.Close()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Pull => didn't return after the context expired and the queue closed")
+	}
+}
+
 func TestThrottled(t *testing.T) {
 	tests := []struct {
 		desc      string
@@ -247,3 +271,76 @@ func TestThrottledPullEventAvailable(t *testing.T) {
 		t.Errorf("Pull => unexpected diff (-want, +got):\n%s", diff)
 	}
 }
+
+func TestPushPopDoesNotAllocate(t *testing.T) {
+	q := New()
+	defer q.Close()
+
+	ev := &terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonLeft}
+	// Prime the free list.
+	q.Push(ev)
+	q.Pop()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		q.Push(ev)
+		q.Pop()
+	})
+	if allocs != 0 {
+		t.Errorf("Push and Pop => got %v allocations per run, want 0", allocs)
+	}
+}
+
+func TestThrottledPushDoesNotAllocate(t *testing.T) {
+	q := NewThrottled(10)
+	defer q.Close()
+
+	ev := &terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonLeft}
+	for i := 0; i < 20; i++ {
+		q.Push(ev)
+	}
+	for q.Pop() != nil {
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for i := 0; i < 20; i++ {
+			q.Push(ev)
+		}
+		for q.Pop() != nil {
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Push and Pop => got %v allocations per run, want 0", allocs)
+	}
+}
+
+func BenchmarkUnbound(b *testing.B) {
+	q := New()
+	defer q.Close()
+	ev := &terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonLeft}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Push(ev)
+		q.Pop()
+	}
+}
+
+func BenchmarkThrottled(b *testing.B) {
+	q := NewThrottled(10)
+	defer q.Close()
+	evs := []terminalapi.Event{
+		&terminalapi.Mouse{Position: image.Point{1, 1}},
+		&terminalapi.Mouse{Position: image.Point{2, 1}},
+		&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Push(evs[i%len(evs)])
+		if i%4 == 0 {
+			q.Pop()
+		}
+	}
+}
@@ -52,6 +52,22 @@ func WithEventQueue(eq *eventqueue.Unbound) Option {
 	})
 }
 
+// DefaultCapabilities are the capabilities reported by the fake terminal
+// unless the WithCapabilities option is provided.
+var DefaultCapabilities = terminalapi.Capabilities{
+	ColorDepth: 256,
+	Unicode:    terminalapi.UnicodeFull,
+	Mouse:      true,
+}
+
+// WithCapabilities sets the capabilities reported by the fake terminal.
+// Defaults to DefaultCapabilities.
+func WithCapabilities(caps terminalapi.Capabilities) Option {
+	return option(func(t *Terminal) {
+		t.caps = caps
+	})
+}
+
 // Terminal is a fake terminal.
 // This implementation is thread-safe.
 type Terminal struct {
@@ -61,6 +77,9 @@ type Terminal struct {
 	// events is a queue of input events.
 	events *eventqueue.Unbound
 
+	// caps are the reported capabilities.
+	caps terminalapi.Capabilities
+
 	// mu protects the buffer.
 	mu sync.Mutex
 }
@@ -74,6 +93,7 @@ func New(size image.Point, opts ...Option) (*Terminal, error) {
 
 	t := &Terminal{
 		buffer: b,
+		caps:   DefaultCapabilities,
 	}
 	for _, opt := range opts {
 		opt.set(t)
@@ -131,6 +151,9 @@ func (t *Terminal) String() string {
 				r = ' '
 			}
 			b.WriteRune(r)
+			for _, cr := range t.buffer[col][row].Combining {
+				b.WriteRune(cr)
+			}
 		}
 		b.WriteRune('\n')
 	}
@@ -190,6 +213,23 @@ func (t *Terminal) SetCell(p image.Point, r rune, opts ...cell.Option) error {
 	return nil
 }
 
+// SetCellCombining implements terminalapi.Combiner.SetCellCombining.
+func (t *Terminal) SetCellCombining(p image.Point, r rune, combining []rune, opts ...cell.Option) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, err := t.buffer.SetCell(p, r, opts...); err != nil {
+		return err
+	}
+	t.buffer[p.X][p.Y].Combining = append([]rune(nil), combining...)
+	return nil
+}
+
+// Capabilities implements terminalapi.Terminal.Capabilities.
+func (t *Terminal) Capabilities() terminalapi.Capabilities {
+	return t.caps
+}
+
 // Event implements terminalapi.Terminal.Event.
 func (t *Terminal) Event(ctx context.Context) terminalapi.Event {
 	if t.events == nil {
@@ -0,0 +1,157 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fallback substitutes runes that a terminal cannot display with
+// runes that it can.
+package fallback
+
+import (
+	"image"
+
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Ranges of the unicode blocks used by termdash.
+const (
+	boxDrawingFirst = 0x2500
+	boxDrawingLast  = 0x257F
+	blockFirst      = 0x2580
+	blockLast       = 0x259F
+	brailleFirst    = 0x2800
+	brailleLast     = 0x28FF
+)
+
+// boxDrawing maps box drawing runes that aren't corners or junctions to their
+// ASCII replacements. All the other box drawing runes are replaced with '+'.
+var boxDrawing = map[rune]rune{
+	'─': '-', '━': '-', '┄': '-', '┅': '-', '┈': '-', '┉': '-',
+	'╌': '-', '╍': '-', '═': '-', '╴': '-', '╶': '-', '╸': '-',
+	'╺': '-', '╼': '-', '╾': '-',
+	'│': '|', '┃': '|', '┆': '|', '┇': '|', '┊': '|', '┋': '|',
+	'╎': '|', '╏': '|', '║': '|', '╵': '|', '╷': '|', '╹': '|',
+	'╻': '|', '╽': '|', '╿': '|',
+	'╱': '/', '╲': '\\', '╳': 'X',
+}
+
+// blocks maps block element runes that don't fill most of the cell to their
+// ASCII replacements. All the other block elements are replaced with '#'.
+var blocks = map[rune]rune{
+	'▁': '_', '▂': '_', '▃': '_',
+	'▔': '-', '░': '.',
+}
+
+// other maps other runes used by termdash to their ASCII replacements.
+var other = map[rune]rune{
+	'←': '<', '→': '>', '↑': '^', '↓': 'v',
+	'⇦': '<', '⇨': '>', '⇧': '^', '⇩': 'v',
+	'⇄': '#', '•': '*', '…': '.',
+}
+
+// Replacement is used for runes that don't have a better ASCII replacement.
+const Replacement = '?'
+
+// Rune returns a rune that can be displayed on a terminal with the specified
+// unicode level in place of the provided rune. Returns the provided rune if
+// the terminal can display it.
+func Rune(r rune, ul terminalapi.UnicodeLevel) rune {
+	if ul == terminalapi.UnicodeFull || r < 0x80 {
+		return r
+	}
+
+	if r >= brailleFirst && r <= brailleLast {
+		if r == brailleFirst {
+			return ' '
+		}
+		return '*'
+	}
+	if ul == terminalapi.UnicodeBasic {
+		return r
+	}
+
+	switch {
+	case r >= boxDrawingFirst && r <= boxDrawingLast:
+		if a, ok := boxDrawing[r]; ok {
+			return a
+		}
+		return '+'
+
+	case r >= blockFirst && r <= blockLast:
+		if a, ok := blocks[r]; ok {
+			return a
+		}
+		return '#'
+
+	default:
+		if a, ok := other[r]; ok {
+			return a
+		}
+		return Replacement
+	}
+}
+
+// Canvas replaces all the runes on the canvas that cannot be displayed on a
+// terminal with the specified unicode level.
+func Canvas(cvs *canvas.Canvas, ul terminalapi.UnicodeLevel) error {
+	if ul == terminalapi.UnicodeFull {
+		return nil
+	}
+
+	size := cvs.Size()
+	for col := 0; col < size.X; col++ {
+		for row := 0; row < size.Y; row++ {
+			p := image.Point{col, row}
+			c, err := cvs.Cell(p)
+			if err != nil {
+				return err
+			}
+			if r := Rune(c.Rune, ul); r != c.Rune {
+				if _, err := cvs.SetCell(p, r); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Emoji replaces all the emoji sequences on the canvas, e.g. emoji joined
+// with a zero width joiner or flags, with the glyph. Used for terminals that
+// cannot display the sequences as a single glyph. Sequences narrower than the
+// glyph are replaced with the Replacement rune instead.
+func Emoji(cvs *canvas.Canvas, glyph rune) error {
+	size := cvs.Size()
+	for col := 0; col < size.X; col++ {
+		for row := 0; row < size.Y; row++ {
+			p := image.Point{col, row}
+			c, err := cvs.Cell(p)
+			if err != nil {
+				return err
+			}
+			if !runewidth.IsEmojiSequence(c.Rune, c.Combining) {
+				continue
+			}
+
+			r := glyph
+			if runewidth.RuneWidth(r) > runewidth.CellWidth(c.Rune, c.Combining) {
+				r = Replacement
+			}
+			if _, err := cvs.SetCell(p, r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
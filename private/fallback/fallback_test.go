@@ -0,0 +1,201 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fallback
+
+import (
+	"image"
+	"testing"
+
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+func TestRune(t *testing.T) {
+	tests := []struct {
+		desc string
+		r    rune
+		ul   terminalapi.UnicodeLevel
+		want rune
+	}{
+		{
+			desc: "full support keeps braille",
+			r:    '⠁',
+			ul:   terminalapi.UnicodeFull,
+			want: '⠁',
+		},
+		{
+			desc: "basic support replaces braille",
+			r:    '⠁',
+			ul:   terminalapi.UnicodeBasic,
+			want: '*',
+		},
+		{
+			desc: "empty braille pattern becomes a space",
+			r:    '⠀',
+			ul:   terminalapi.UnicodeBasic,
+			want: ' ',
+		},
+		{
+			desc: "basic support keeps box drawing",
+			r:    '┼',
+			ul:   terminalapi.UnicodeBasic,
+			want: '┼',
+		},
+		{
+			desc: "basic support keeps other runes",
+			r:    '世',
+			ul:   terminalapi.UnicodeBasic,
+			want: '世',
+		},
+		{
+			desc: "ASCII is never replaced",
+			r:    'a',
+			ul:   terminalapi.UnicodeASCII,
+			want: 'a',
+		},
+		{
+			desc: "horizontal line",
+			r:    '═',
+			ul:   terminalapi.UnicodeASCII,
+			want: '-',
+		},
+		{
+			desc: "vertical line",
+			r:    '│',
+			ul:   terminalapi.UnicodeASCII,
+			want: '|',
+		},
+		{
+			desc: "corner",
+			r:    '╭',
+			ul:   terminalapi.UnicodeASCII,
+			want: '+',
+		},
+		{
+			desc: "low block",
+			r:    '▂',
+			ul:   terminalapi.UnicodeASCII,
+			want: '_',
+		},
+		{
+			desc: "full block",
+			r:    '█',
+			ul:   terminalapi.UnicodeASCII,
+			want: '#',
+		},
+		{
+			desc: "arrow",
+			r:    '⇧',
+			ul:   terminalapi.UnicodeASCII,
+			want: '^',
+		},
+		{
+			desc: "rune without a better replacement",
+			r:    '世',
+			ul:   terminalapi.UnicodeASCII,
+			want: Replacement,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := Rune(tc.r, tc.ul); got != tc.want {
+				t.Errorf("Rune(%q, %v) => %q, want %q", tc.r, tc.ul, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanvas(t *testing.T) {
+	tests := []struct {
+		desc string
+		ul   terminalapi.UnicodeLevel
+		want string
+	}{
+		{
+			desc: "full support",
+			ul:   terminalapi.UnicodeFull,
+			want: "│⣿世\x00a\n",
+		},
+		{
+			desc: "basic support",
+			ul:   terminalapi.UnicodeBasic,
+			want: "│*世\x00a\n",
+		},
+		{
+			desc: "ASCII only",
+			ul:   terminalapi.UnicodeASCII,
+			want: "|*? a\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			cvs := testcanvas.MustNew(image.Rect(0, 0, 5, 1))
+			testcanvas.MustSetCell(cvs, image.Point{0, 0}, '│')
+			testcanvas.MustSetCell(cvs, image.Point{1, 0}, '⣿')
+			testcanvas.MustSetCell(cvs, image.Point{2, 0}, '世')
+			testcanvas.MustSetCell(cvs, image.Point{4, 0}, 'a')
+
+			if err := Canvas(cvs, tc.ul); err != nil {
+				t.Fatalf("Canvas => unexpected error: %v", err)
+			}
+
+			ft := faketerm.MustNew(cvs.Size())
+			testcanvas.MustApply(cvs, ft)
+			if got := ft.String(); got != tc.want {
+				t.Errorf("Canvas => got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEmoji(t *testing.T) {
+	tests := []struct {
+		desc  string
+		glyph rune
+		want  string
+	}{
+		{
+			desc:  "half-width glyph",
+			glyph: '*',
+			want:  "* e\u0301👋\x00* \n",
+		},
+		{
+			desc:  "full-width glyph",
+			glyph: '〓',
+			want:  "〓\x00e\u0301👋\x00〓\x00\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			cvs := testcanvas.MustNew(image.Rect(0, 0, 7, 1))
+			testdraw.MustText(cvs, "👨\u200d👩e\u0301👋🇺🇸", image.Point{0, 0})
+
+			if err := Emoji(cvs, tc.glyph); err != nil {
+				t.Fatalf("Emoji => unexpected error: %v", err)
+			}
+
+			ft := faketerm.MustNew(cvs.Size())
+			testcanvas.MustApply(cvs, ft)
+			if got := ft.String(); got != tc.want {
+				t.Errorf("Emoji => got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -16,7 +16,11 @@
 // gives different treatment to certain runes with ambiguous width.
 package runewidth
 
-import runewidth "github.com/mattn/go-runewidth"
+import (
+	"unicode"
+
+	runewidth "github.com/mattn/go-runewidth"
+)
 
 // RuneWidth returns the number of cells needed to draw r.
 // Background in http://www.unicode.org/reports/tr11/.
@@ -37,15 +41,214 @@ func RuneWidth(r rune) int {
 }
 
 // StringWidth is like RuneWidth, but returns the number of cells occupied by
-// all the runes in the string.
+// all the runes in the string. Runes that join a grapheme cluster, like the
+// emoji in a zero width joiner sequence, only add the cells the cluster
+// grows by, see Tracker.
 func StringWidth(s string) int {
+	var t Tracker
 	var width int
-	for _, r := range []rune(s) {
-		width += RuneWidth(r)
+	for _, r := range s {
+		width += t.Add(r)
 	}
 	return width
 }
 
+// IsCombining asserts whether the rune is a combining character, i.e. a rune
+// that occupies no cells of its own and modifies the preceding rune, like a
+// combining accent or a zero width joiner.
+func IsCombining(r rune) bool {
+	if unicode.Is(unicode.Variation_Selector, r) {
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) && RuneWidth(r) == 0
+}
+
+// Runes that form emoji sequences.
+const (
+	// zeroWidthJoiner joins the emoji before and after it into one glyph.
+	zeroWidthJoiner = '\u200d'
+	// emojiPresentation requests the emoji presentation of the preceding
+	// rune, which occupies two cells.
+	emojiPresentation = '\ufe0f'
+
+	// The emoji modifiers change the skin tone of the preceding emoji.
+	modifierFirst = 0x1f3fb
+	modifierLast  = 0x1f3ff
+
+	// Pairs of regional indicators form flags.
+	regionalFirst = 0x1f1e6
+	regionalLast  = 0x1f1ff
+
+	// The tags follow a flag and select a subdivision, e.g. a country of the
+	// United Kingdom.
+	tagFirst = 0xe0020
+	tagLast  = 0xe007f
+)
+
+// isModifier determines if the rune is an emoji modifier.
+func isModifier(r rune) bool {
+	return r >= modifierFirst && r <= modifierLast
+}
+
+// isRegional determines if the rune is a regional indicator.
+func isRegional(r rune) bool {
+	return r >= regionalFirst && r <= regionalLast
+}
+
+// isTag determines if the rune is a tag.
+func isTag(r rune) bool {
+	return r >= tagFirst && r <= tagLast
+}
+
+// IsEmojiSequence determines if the grapheme cluster made of the rune and the
+// combining characters is an emoji sequence, i.e. emoji joined with a zero
+// width joiner, an emoji with a presentation selector or a modifier, or a
+// flag.
+func IsEmojiSequence(r rune, combining []rune) bool {
+	for _, c := range combining {
+		switch {
+		case c == zeroWidthJoiner, c == emojiPresentation, isModifier(c), isRegional(c), isTag(c):
+			return true
+		}
+	}
+	return false
+}
+
+// Tracker computes the width of text one rune at a time, while tracking the
+// grapheme cluster at the end of the text. A rune joins the cluster if it is
+// a combining character, follows a zero width joiner, is an emoji modifier
+// following an emoji, a tag, or a regional indicator completing a flag.
+// The zero value is an empty text.
+type Tracker struct {
+	// first and last are the first and the last rune of the cluster.
+	first, last rune
+	// n is the number of runes in the cluster.
+	n int
+	// width is the number of cells the cluster occupies.
+	width int
+}
+
+// Joins determines if the rune joins the cluster at the end of the text.
+func (t *Tracker) Joins(r rune) bool {
+	if t.n == 0 {
+		return false
+	}
+	switch {
+	case IsCombining(r), t.last == zeroWidthJoiner, isTag(r):
+		return true
+	case isModifier(r):
+		return RuneWidth(t.last) == 2 && !isModifier(t.last)
+	case isRegional(r):
+		return t.n == 1 && isRegional(t.first)
+	default:
+		return false
+	}
+}
+
+// Peek returns the number of cells the text would grow by if the rune was
+// added to it.
+func (t *Tracker) Peek(r rune) int {
+	if !t.Joins(r) {
+		return RuneWidth(r)
+	}
+	return t.joinedWidth(r) - t.width
+}
+
+// joinedWidth returns the width of the cluster with the rune joined to it.
+func (t *Tracker) joinedWidth(r rune) int {
+	switch {
+	case r == emojiPresentation && t.width > 0:
+		return 2
+	case isRegional(r):
+		return 2
+	default:
+		return t.width
+	}
+}
+
+// Add adds the rune to the text and returns the number of cells the text
+// grew by. This is the width of the rune if it starts a new cluster and the
+// number of cells the cluster grew by, usually zero, if the rune joins it.
+func (t *Tracker) Add(r rune) int {
+	if t.Joins(r) {
+		w := t.joinedWidth(r)
+		grew := w - t.width
+		t.last = r
+		t.n++
+		t.width = w
+		return grew
+	}
+	t.first, t.last, t.n = r, r, 1
+	t.width = RuneWidth(r)
+	return t.width
+}
+
+// Width returns the number of cells occupied by the cluster at the end of the
+// text.
+func (t *Tracker) Width() int {
+	return t.width
+}
+
+// CellWidth returns the number of cells occupied by a cell that contains the
+// rune and the combining characters, i.e. the rest of its grapheme cluster.
+func CellWidth(r rune, combining []rune) int {
+	if len(combining) == 0 {
+		return RuneWidth(r)
+	}
+	t := CellTracker(r, combining)
+	return t.Width()
+}
+
+// CellTracker returns a Tracker positioned after the cluster in a cell that
+// contains the rune and the combining characters.
+func CellTracker(r rune, combining []rune) Tracker {
+	var t Tracker
+	t.Add(r)
+	for _, c := range combining {
+		t.Add(c)
+	}
+	return t
+}
+
+// Clusters splits the string into grapheme clusters, i.e. the units of text
+// that are displayed, trimmed and edited together. A cluster is a rune
+// followed by any number of runes that join it, see Tracker. Combining
+// characters at the start of the string form a cluster of their own.
+func Clusters(s string) []string {
+	var clusters []string
+	var t Tracker
+	start := 0
+	for i, r := range s {
+		if i > 0 && !t.Joins(r) {
+			clusters = append(clusters, s[start:i])
+			start = i
+		}
+		t.Add(r)
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+	return clusters
+}
+
+// ClusterStart returns the index of the first rune of the grapheme cluster
+// that contains the rune at the index. Returns the index unchanged if it is
+// out of range.
+func ClusterStart(runes []rune, idx int) int {
+	if idx <= 0 || idx >= len(runes) {
+		return idx
+	}
+	var t Tracker
+	start := 0
+	for i, r := range runes[:idx+1] {
+		if !t.Joins(r) {
+			start = i
+		}
+		t.Add(r)
+	}
+	return start
+}
+
 // inTable determines if the rune falls within the table.
 // Copied from github.com/mattn/go-runewidth/blob/master/runewidth.go.
 func inTable(r rune, t table) bool {
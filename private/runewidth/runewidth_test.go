@@ -149,6 +149,41 @@ func TestStringWidth(t *testing.T) {
 			eastAsian: true,
 			want:      4,
 		},
+		{
+			desc: "emoji joined with zero width joiners",
+			str:  "👨\u200d👩\u200d👧",
+			want: 2,
+		},
+		{
+			desc: "variation selector requests the emoji presentation",
+			str:  "❤\ufe0f",
+			want: 2,
+		},
+		{
+			desc: "variation selector on an invisible rune",
+			str:  "\u200d\ufe0f",
+			want: 0,
+		},
+		{
+			desc: "emoji with a skin tone modifier",
+			str:  "👋🏻",
+			want: 2,
+		},
+		{
+			desc: "regional indicators form flags",
+			str:  "🇺🇸🇩",
+			want: 3,
+		},
+		{
+			desc: "flag with tags",
+			str:  "🏴\U000e0067\U000e0062\U000e0073\U000e0063\U000e0074\U000e007f",
+			want: 2,
+		},
+		{
+			desc: "two emoji without a joiner",
+			str:  "👨👩",
+			want: 4,
+		},
 	}
 
 	for _, tc := range tests {
@@ -164,3 +199,198 @@ func TestStringWidth(t *testing.T) {
 		})
 	}
 }
+
+func TestIsCombining(t *testing.T) {
+	tests := []struct {
+		desc  string
+		runes []rune
+		want  bool
+	}{
+		{
+			desc:  "runes that occupy cells",
+			runes: []rune{'a', ' ', '世', '…'},
+			want:  false,
+		},
+		{
+			desc:  "control and separator runes",
+			runes: []rune{'\x00', '\n', '\t', '\u2028'},
+			want:  false,
+		},
+		{
+			desc:  "combining marks",
+			runes: []rune{'\u0301', '\u0308', '\u20dd'},
+			want:  true,
+		},
+		{
+			desc:  "zero width joiner and variation selector",
+			runes: []rune{'\u200d', '\ufe0f'},
+			want:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			for _, r := range tc.runes {
+				if got := IsCombining(r); got != tc.want {
+					t.Errorf("IsCombining(%q) => %v, want %v", r, got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClusters(t *testing.T) {
+	tests := []struct {
+		desc string
+		str  string
+		want []string
+	}{
+		{
+			desc: "empty string",
+		},
+		{
+			desc: "one rune per cluster",
+			str:  "a世b",
+			want: []string{"a", "世", "b"},
+		},
+		{
+			desc: "combining characters join the preceding rune",
+			str:  "e\u0301x\u0323\u0307",
+			want: []string{"e\u0301", "x\u0323\u0307"},
+		},
+		{
+			desc: "leading combining characters form a cluster",
+			str:  "\u0301\u0301a",
+			want: []string{"\u0301\u0301", "a"},
+		},
+		{
+			desc: "emoji sequences form clusters",
+			str:  "a👨\u200d👩❤\ufe0f👋🏻👋🇺🇸🇩",
+			want: []string{"a", "👨\u200d👩", "❤\ufe0f", "👋🏻", "👋", "🇺🇸", "🇩"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := Clusters(tc.str)
+			if len(got) != len(tc.want) {
+				t.Fatalf("Clusters(%q) => %q, want %q", tc.str, got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("Clusters(%q) => %q, want %q", tc.str, got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClusterStart(t *testing.T) {
+	runes := []rune("ae\u0301\u0302b")
+	tests := []struct {
+		idx  int
+		want int
+	}{
+		{-1, -1},
+		{0, 0},
+		{1, 1},
+		{2, 1},
+		{3, 1},
+		{4, 4},
+		{5, 5},
+	}
+
+	for _, tc := range tests {
+		if got := ClusterStart(runes, tc.idx); got != tc.want {
+			t.Errorf("ClusterStart(%q, %d) => %d, want %d", runes, tc.idx, got, tc.want)
+		}
+	}
+}
+
+func TestCellWidth(t *testing.T) {
+	tests := []struct {
+		desc      string
+		r         rune
+		combining []rune
+		want      int
+	}{
+		{
+			desc: "rune without combining characters",
+			r:    '世',
+			want: 2,
+		},
+		{
+			desc:      "combining characters don't add width",
+			r:         'e',
+			combining: []rune{'\u0301'},
+			want:      1,
+		},
+		{
+			desc:      "emoji joined with a zero width joiner",
+			r:         '👨',
+			combining: []rune{'\u200d', '👩'},
+			want:      2,
+		},
+		{
+			desc:      "emoji presentation of a half-width rune",
+			r:         '❤',
+			combining: []rune{'\ufe0f'},
+			want:      2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := CellWidth(tc.r, tc.combining); got != tc.want {
+				t.Errorf("CellWidth(%q, %q) => %v, want %v", tc.r, tc.combining, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsEmojiSequence(t *testing.T) {
+	tests := []struct {
+		desc      string
+		r         rune
+		combining []rune
+		want      bool
+	}{
+		{
+			desc: "single emoji",
+			r:    '👨',
+			want: false,
+		},
+		{
+			desc:      "combining accent",
+			r:         'e',
+			combining: []rune{'\u0301'},
+			want:      false,
+		},
+		{
+			desc:      "emoji joined with a zero width joiner",
+			r:         '👨',
+			combining: []rune{'\u200d', '👩'},
+			want:      true,
+		},
+		{
+			desc:      "emoji with a modifier",
+			r:         '👋',
+			combining: []rune{'🏻'},
+			want:      true,
+		},
+		{
+			desc:      "flag",
+			r:         '🇺',
+			combining: []rune{'🇸'},
+			want:      true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := IsEmojiSequence(tc.r, tc.combining); got != tc.want {
+				t.Errorf("IsEmojiSequence(%q, %q) => %v, want %v", tc.r, tc.combining, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,450 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package soak runs dashboards for simulated hours to catch leaks in the
+// widgets and in the event loop.
+//
+// The harness runs the dashboard on a fake terminal. A virtual clock
+// advances by one step per frame, on each step the harness feeds the widgets
+// with random data, sends random keyboard, mouse and resize events and
+// redraws the dashboard. The harness fails if the heap or the number of
+// goroutines grows after the warm-up, or if goroutines outlive the dashboard.
+package soak
+
+import (
+	"fmt"
+	"image"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/event/eventqueue"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Clock is a virtual clock, it only moves when the harness advances it.
+// This object is thread-safe.
+type Clock struct {
+	// mu protects now.
+	mu sync.Mutex
+	// now is the current time of the clock.
+	now time.Time
+}
+
+// NewClock returns a new clock set to the time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by the duration.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Feed feeds the widgets of the dashboard with data for one step. The
+// random source must be used for all randomness, so that a failing run can
+// be reproduced with the same Seed.
+type Feed func(rnd *rand.Rand) error
+
+// Setup creates the dashboard under test on the terminal. The widgets can
+// read the time from the virtual clock. Returns the root container and the
+// function that feeds the widgets with data.
+type Setup func(t terminalapi.Terminal, clk *Clock) (*container.Container, Feed, error)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	duration           time.Duration
+	step               time.Duration
+	warmUp             time.Duration
+	seed               int64
+	inputInterval      time.Duration
+	maxHeapGrowth      uint64
+	maxGoroutineGrowth int
+	termdashOpts       []termdash.Option
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.step <= 0 {
+		return fmt.Errorf("invalid Step(%v), must be a positive duration", o.step)
+	}
+	if o.duration < o.step {
+		return fmt.Errorf("invalid Duration(%v), must be at least one Step(%v)", o.duration, o.step)
+	}
+	if o.warmUp < 0 || o.warmUp/o.step >= o.duration/o.step {
+		return fmt.Errorf("invalid WarmUp(%v), must be zero or a positive duration shorter than Duration(%v)", o.warmUp, o.duration)
+	}
+	if o.inputInterval < 0 {
+		return fmt.Errorf("invalid InputInterval(%v), must be zero or a positive duration", o.inputInterval)
+	}
+	if o.maxGoroutineGrowth < 0 {
+		return fmt.Errorf("invalid MaxGoroutineGrowth(%d), must be zero or a positive number", o.maxGoroutineGrowth)
+	}
+	return nil
+}
+
+// Default values of the options.
+const (
+	DefaultDuration      = time.Hour
+	DefaultStep          = time.Second
+	DefaultWarmUp        = 5 * time.Minute
+	DefaultInputInterval = 10 * time.Second
+	DefaultMaxHeapGrowth = 1 << 20
+)
+
+// Duration sets the simulated duration of the run.
+// Defaults to DefaultDuration.
+func Duration(d time.Duration) Option {
+	return option(func(opts *options) {
+		opts.duration = d
+	})
+}
+
+// Step sets the simulated time between two frames.
+// Defaults to DefaultStep.
+func Step(d time.Duration) Option {
+	return option(func(opts *options) {
+		opts.step = d
+	})
+}
+
+// WarmUp sets the simulated time after which the harness takes the baseline
+// of the heap and the goroutines, so that caches and buffers of the widgets
+// can fill up first.
+// Defaults to DefaultWarmUp.
+func WarmUp(d time.Duration) Option {
+	return option(func(opts *options) {
+		opts.warmUp = d
+	})
+}
+
+// Seed sets the seed of the random data and input.
+// Defaults to zero.
+func Seed(seed int64) Option {
+	return option(func(opts *options) {
+		opts.seed = seed
+	})
+}
+
+// InputInterval sets the simulated time between two random input events.
+// Termdash waits for the widgets before redrawing on input, so frequent
+// input makes the run take longer in real time. Zero disables the input.
+// Defaults to DefaultInputInterval.
+func InputInterval(d time.Duration) Option {
+	return option(func(opts *options) {
+		opts.inputInterval = d
+	})
+}
+
+// MaxHeapGrowth sets the number of bytes the live heap can grow by between
+// the end of the warm-up and the end of the run.
+// Defaults to DefaultMaxHeapGrowth.
+func MaxHeapGrowth(bytes uint64) Option {
+	return option(func(opts *options) {
+		opts.maxHeapGrowth = bytes
+	})
+}
+
+// MaxGoroutineGrowth sets the number of goroutines that can be started and
+// not exited between the end of the warm-up and the end of the run.
+// Defaults to zero.
+func MaxGoroutineGrowth(n int) Option {
+	return option(func(opts *options) {
+		opts.maxGoroutineGrowth = n
+	})
+}
+
+// TermdashOptions are provided to the termdash controller that runs the
+// dashboard, e.g. its key bindings. The harness sets its own ErrorHandler.
+func TermdashOptions(tdOpts ...termdash.Option) Option {
+	return option(func(opts *options) {
+		opts.termdashOpts = append(opts.termdashOpts, tdOpts...)
+	})
+}
+
+// Report are the results of a run.
+type Report struct {
+	// Steps is the number of simulated steps.
+	Steps int
+	// Events is the number of input events sent.
+	Events int
+	// HeapGrowth is the number of bytes the live heap grew by after the
+	// warm-up, negative if it shrank.
+	HeapGrowth int64
+	// GoroutineGrowth is the number of goroutines started and not exited
+	// after the warm-up.
+	GoroutineGrowth int
+}
+
+// String implements fmt.Stringer()
+func (r *Report) String() string {
+	return fmt.Sprintf("%d steps, %d events, heap growth %d bytes, goroutine growth %d", r.Steps, r.Events, r.HeapGrowth, r.GoroutineGrowth)
+}
+
+// drainTimeout is the time the harness waits for the dashboard to process
+// the input events or for its goroutines to exit.
+const drainTimeout = 5 * time.Second
+
+// harness runs one dashboard.
+type harness struct {
+	// opts are the provided options.
+	opts *options
+	// size is the original size of the terminal.
+	size image.Point
+	// term is the terminal the dashboard runs on.
+	term *faketerm.Terminal
+	// eq is the queue of the input events of the terminal.
+	eq *eventqueue.Unbound
+	// clk is the virtual clock.
+	clk *Clock
+	// feed feeds the widgets with data.
+	feed Feed
+	// ctrl controls the dashboard.
+	ctrl *termdash.Controller
+
+	// mu protects err.
+	mu sync.Mutex
+	// err is the first error reported by the dashboard.
+	err error
+}
+
+// Run runs the dashboard created by setup on a fake terminal of the size.
+// Returns the report of the run and an error if the run failed or the
+// dashboard leaked memory or goroutines.
+func Run(size image.Point, setup Setup, opts ...Option) (*Report, error) {
+	o := &options{
+		duration:      DefaultDuration,
+		step:          DefaultStep,
+		warmUp:        DefaultWarmUp,
+		inputInterval: DefaultInputInterval,
+		maxHeapGrowth: DefaultMaxHeapGrowth,
+	}
+	for _, opt := range opts {
+		opt.set(o)
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	startGoroutines := runtime.NumGoroutine()
+	h := &harness{
+		opts: o,
+		size: size,
+		eq:   eventqueue.New(),
+		clk:  NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	r, err := h.run(setup)
+	h.eq.Close()
+	if err != nil {
+		return r, err
+	}
+
+	if r.HeapGrowth > 0 && uint64(r.HeapGrowth) > o.maxHeapGrowth {
+		return r, fmt.Errorf("the heap grew by %d bytes after the warm-up, want at most %d bytes", r.HeapGrowth, o.maxHeapGrowth)
+	}
+	if r.GoroutineGrowth > o.maxGoroutineGrowth {
+		return r, fmt.Errorf("%d goroutines were started and didn't exit after the warm-up, want at most %d", r.GoroutineGrowth, o.maxGoroutineGrowth)
+	}
+	if !waitFor(func() bool {
+		return runtime.NumGoroutine() <= startGoroutines
+	}) {
+		return r, fmt.Errorf("%d goroutines are still running after the dashboard was closed", runtime.NumGoroutine()-startGoroutines)
+	}
+	return r, nil
+}
+
+// run sets the dashboard up, runs it and closes it.
+func (h *harness) run(setup Setup) (*Report, error) {
+	term, err := faketerm.New(h.size, faketerm.WithEventQueue(h.eq))
+	if err != nil {
+		return nil, err
+	}
+	h.term = term
+	cont, feed, err := setup(term, h.clk)
+	if err != nil {
+		return nil, fmt.Errorf("setup => %v", err)
+	}
+	h.feed = feed
+
+	tdOpts := append(append([]termdash.Option(nil), h.opts.termdashOpts...), termdash.ErrorHandler(h.handleError))
+	ctrl, err := termdash.NewController(term, cont, tdOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("termdash.NewController => %v", err)
+	}
+	h.ctrl = ctrl
+	defer ctrl.Close()
+
+	return h.steps()
+}
+
+// handleError records the first error reported by the dashboard.
+func (h *harness) handleError(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err == nil {
+		h.err = err
+	}
+}
+
+// failed returns the first error reported by the dashboard.
+func (h *harness) failed() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+// steps runs the steps of the simulated duration and measures the heap and
+// the goroutines after the warm-up and at the end.
+func (h *harness) steps() (*Report, error) {
+	r := &Report{}
+	rnd := rand.New(rand.NewSource(h.opts.seed))
+	var (
+		baseHeap       uint64
+		baseGoroutines int
+		sinceInput     time.Duration
+	)
+	steps := int(h.opts.duration / h.opts.step)
+	warmUpSteps := int(h.opts.warmUp / h.opts.step)
+	for i := 0; i < steps; i++ {
+		if i == warmUpSteps {
+			baseHeap = liveHeap()
+			baseGoroutines = runtime.NumGoroutine()
+		}
+
+		h.clk.Advance(h.opts.step)
+		if err := h.feed(rnd); err != nil {
+			return r, fmt.Errorf("step %d: feed => %v", i, err)
+		}
+
+		sinceInput += h.opts.step
+		cur := h.term.Size()
+		for h.opts.inputInterval > 0 && sinceInput >= h.opts.inputInterval {
+			sinceInput -= h.opts.inputInterval
+			h.eq.Push(randomInput(rnd, h.size, cur))
+			r.Events++
+		}
+		if !waitFor(h.eq.Empty) {
+			return r, fmt.Errorf("step %d: the dashboard didn't process the input events in time", i)
+		}
+
+		if err := h.ctrl.Redraw(); err != nil {
+			return r, fmt.Errorf("step %d: Redraw => %v", i, err)
+		}
+		if err := h.failed(); err != nil {
+			return r, fmt.Errorf("step %d: the dashboard failed: %v", i, err)
+		}
+		r.Steps++
+	}
+
+	r.HeapGrowth = int64(liveHeap()) - int64(baseHeap)
+	r.GoroutineGrowth = runtime.NumGoroutine() - baseGoroutines
+	return r, nil
+}
+
+// keys are the keys sent as random keyboard events.
+var keys = []keyboard.Key{
+	keyboard.KeyArrowUp,
+	keyboard.KeyArrowDown,
+	keyboard.KeyArrowLeft,
+	keyboard.KeyArrowRight,
+	keyboard.KeyPgUp,
+	keyboard.KeyPgDn,
+	keyboard.KeyTab,
+	keyboard.KeyEnter,
+	keyboard.KeyBackspace,
+	keyboard.KeySpace,
+	'a',
+	'z',
+	'0',
+}
+
+// buttons are the mouse buttons sent as random mouse events.
+var buttons = []mouse.Button{
+	mouse.ButtonLeft,
+	mouse.ButtonRelease,
+	mouse.ButtonRight,
+	mouse.ButtonWheelUp,
+	mouse.ButtonWheelDown,
+	mouse.ButtonNone,
+}
+
+// randomInput returns a random keyboard, mouse or resize event. The mouse
+// events fall on the terminal of the current size, the resize events resize
+// the terminal to between half and all of its original size.
+func randomInput(rnd *rand.Rand, size, cur image.Point) terminalapi.Event {
+	switch n := rnd.Intn(20); {
+	case n == 0:
+		return &terminalapi.Resize{Size: image.Point{
+			X: size.X/2 + rnd.Intn(size.X/2+1),
+			Y: size.Y/2 + rnd.Intn(size.Y/2+1),
+		}}
+	case n < 10:
+		return &terminalapi.Keyboard{Key: keys[rnd.Intn(len(keys))]}
+	default:
+		return &terminalapi.Mouse{
+			Position: image.Point{rnd.Intn(cur.X), rnd.Intn(cur.Y)},
+			Button:   buttons[rnd.Intn(len(buttons))],
+		}
+	}
+}
+
+// waitFor waits until the condition is true or the drainTimeout passes.
+// Returns false on timeout.
+func waitFor(cond func() bool) bool {
+	deadline := time.Now().Add(drainTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		runtime.Gosched()
+	}
+	return true
+}
+
+// liveHeap returns the number of bytes of the live heap objects.
+func liveHeap() uint64 {
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.HeapAlloc
+}
@@ -0,0 +1,200 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package soak
+
+import (
+	"fmt"
+	"image"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/linechart"
+	"github.com/mum4k/termdash/widgets/sparkline"
+	"github.com/mum4k/termdash/widgets/text"
+	"github.com/mum4k/termdash/widgets/textinput"
+)
+
+// dashboard creates a dashboard with widgets that receive data and input.
+func dashboard(t terminalapi.Terminal, clk *Clock) (*container.Container, Feed, error) {
+	lc, err := linechart.New()
+	if err != nil {
+		return nil, nil, err
+	}
+	sl, err := sparkline.New()
+	if err != nil {
+		return nil, nil, err
+	}
+	log, err := text.New(text.RollContent())
+	if err != nil {
+		return nil, nil, err
+	}
+	ti, err := textinput.New(textinput.Label("cmd: "))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c, err := container.New(
+		t,
+		container.SplitHorizontal(
+			container.Top(
+				container.SplitVertical(
+					container.Left(container.Border(linestyle.Light), container.PlaceWidget(lc)),
+					container.Right(container.Border(linestyle.Light), container.PlaceWidget(log)),
+				),
+			),
+			container.Bottom(
+				container.SplitHorizontal(
+					container.Top(container.Border(linestyle.Light), container.PlaceWidget(sl)),
+					container.Bottom(container.PlaceWidget(ti)),
+				),
+			),
+			container.SplitPercent(70),
+		),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		values []float64
+		lines  int
+	)
+	feed := func(rnd *rand.Rand) error {
+		values = append(values, rnd.Float64()*100)
+		if len(values) > 100 {
+			values = values[1:]
+		}
+		if err := lc.Series("rps", values); err != nil {
+			return err
+		}
+		if err := sl.Add([]int{rnd.Intn(100)}); err != nil {
+			return err
+		}
+
+		var wOpts []text.WriteOption
+		if lines++; lines%50 == 0 {
+			// Keeps the log bounded like a log viewer would.
+			wOpts = append(wOpts, text.WriteReplace())
+		}
+		if err := log.Write(fmt.Sprintf("%s %s\n", clk.Now().Format(time.RFC3339), ti.ReadAndClear()), wOpts...); err != nil {
+			return err
+		}
+		return nil
+	}
+	return c, feed, nil
+}
+
+func TestRun(t *testing.T) {
+	size := image.Point{80, 24}
+	tests := []struct {
+		desc    string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			desc:    "fails on zero Step",
+			opts:    []Option{Step(0)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on Duration shorter than Step",
+			opts:    []Option{Duration(time.Second), Step(time.Minute)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on WarmUp as long as Duration",
+			opts:    []Option{Duration(time.Minute), WarmUp(time.Minute)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on negative InputInterval",
+			opts:    []Option{InputInterval(-time.Second)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on negative MaxGoroutineGrowth",
+			opts:    []Option{MaxGoroutineGrowth(-1)},
+			wantErr: true,
+		},
+		{
+			desc: "runs the dashboard for a simulated hour without leaks",
+			opts: []Option{Step(5 * time.Second), InputInterval(30 * time.Second)},
+		},
+		{
+			desc: "runs the dashboard with heavy input",
+			opts: []Option{Duration(2 * time.Minute), WarmUp(time.Minute), InputInterval(time.Second), Seed(42)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := Run(size, dashboard, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Run => unexpected error: %v, wantErr: %v, report: %v", err, tc.wantErr, r)
+			}
+		})
+	}
+}
+
+func TestRunDetectsLeaks(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	var retained [][]byte
+
+	tests := []struct {
+		desc string
+		// leak is called on each step.
+		leak func()
+	}{
+		{
+			desc: "goroutines",
+			leak: func() {
+				go func() {
+					<-stop
+				}()
+			},
+		},
+		{
+			desc: "memory",
+			leak: func() {
+				retained = append(retained, make([]byte, 16<<10))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			setup := func(term terminalapi.Terminal, clk *Clock) (*container.Container, Feed, error) {
+				c, feed, err := dashboard(term, clk)
+				if err != nil {
+					return nil, nil, err
+				}
+				return c, func(rnd *rand.Rand) error {
+					tc.leak()
+					return feed(rnd)
+				}, nil
+			}
+
+			r, err := Run(image.Point{80, 24}, setup, Duration(10*time.Minute), WarmUp(time.Minute))
+			if err == nil {
+				t.Errorf("Run => got nil error, want one for the leaked %s, report: %v", tc.desc, r)
+			}
+		})
+	}
+}
@@ -150,6 +150,11 @@ type cellScanner struct {
 	// posX tracks the horizontal position of the current cell on the canvas.
 	posX int
 
+	// cluster tracks the grapheme cluster that ends at posX, so that runes
+	// joining it, e.g. the parts of an emoji sequence, are never separated
+	// from it.
+	cluster runewidth.Tracker
+
 	// mode is the wrapping mode.
 	mode Mode
 
@@ -208,11 +213,12 @@ func (cs *cellScanner) wordCells() []*buffer.Cell {
 // wordWidth returns the width of the current word in cells when printed on the
 // terminal.
 func (cs *cellScanner) wordWidth() int {
-	var b strings.Builder
+	t := cs.cluster
+	var width int
 	for _, wc := range cs.wordCells() {
-		b.WriteRune(wc.Rune)
+		width += t.Add(wc.Rune)
 	}
-	return runewidth.StringWidth(b.String())
+	return width
 }
 
 // isWordStart determines if the scanner is at the beginning of a word.
@@ -261,7 +267,7 @@ func scanCellRunes(cs *cellScanner) cellScannerState {
 			return markWordStart
 		}
 
-		if runeWrapNeeded(r, cs.posX, cs.width) {
+		if cs.runeWrapNeeded(r) {
 			return newLineForAtRunes
 		}
 
@@ -273,7 +279,7 @@ func scanCellRunes(cs *cellScanner) cellScannerState {
 func runeToCurrentLine(cs *cellScanner) cellScannerState {
 	cell := cs.peekPrev()
 	// Move horizontally within the line for each scanned cell.
-	cs.posX += runewidth.RuneWidth(cell.Rune)
+	cs.posX += cs.cluster.Add(cell.Rune)
 
 	// Copy the cell into the current line.
 	cs.line = append(cs.line, cell)
@@ -284,6 +290,7 @@ func runeToCurrentLine(cs *cellScanner) cellScannerState {
 func newLineForLineBreak(cs *cellScanner) cellScannerState {
 	cs.lines = append(cs.lines, cs.line)
 	cs.posX = 0
+	cs.cluster = runewidth.Tracker{}
 	cs.line = nil
 	return scanCellRunes
 }
@@ -293,7 +300,8 @@ func newLineForAtRunes(cs *cellScanner) cellScannerState {
 	// The character on which we wrapped will be printed and is the start of
 	// new line.
 	cs.lines = append(cs.lines, cs.line)
-	cs.posX = runewidth.RuneWidth(cs.peekPrev().Rune)
+	cs.cluster = runewidth.Tracker{}
+	cs.posX = cs.cluster.Add(cs.peekPrev().Rune)
 	cs.line = []*buffer.Cell{cs.peekPrev()}
 	return scanCellRunes
 }
@@ -338,6 +346,9 @@ func wordToCurrentLine(cs *cellScanner) cellScannerState {
 	if cs.posX+wordWidth <= cs.width {
 		// Place the word onto the current line.
 		cs.posX += wordWidth
+		for _, wc := range wordCells {
+			cs.cluster.Add(wc.Rune)
+		}
 		cs.line = append(cs.line, wordCells...)
 		return scanCellRunes
 	}
@@ -350,6 +361,7 @@ func wrapWord(cs *cellScanner) cellScannerState {
 	if cs.posX > 0 {
 		cs.lines = append(cs.lines, cs.line)
 		cs.posX = 0
+		cs.cluster = runewidth.Tracker{}
 		cs.line = nil
 	}
 
@@ -359,8 +371,8 @@ func wrapWord(cs *cellScanner) cellScannerState {
 			continue
 		}
 
-		if !runeWrapNeeded(wc.Rune, cs.posX, cs.width) {
-			cs.posX += runewidth.RuneWidth(wc.Rune)
+		if !cs.runeWrapNeeded(wc.Rune) {
+			cs.posX += cs.cluster.Add(wc.Rune)
 			cs.line = append(cs.line, wc)
 			continue
 		}
@@ -401,9 +413,13 @@ func isWordCell(c *buffer.Cell) bool {
 	return false
 }
 
-// runeWrapNeeded returns true if wrapping is needed for the rune at the horizontal
-// position on the canvas that has the specified width.
-func runeWrapNeeded(r rune, posX, width int) bool {
+// runeWrapNeeded returns true if wrapping is needed for the rune at the current
+// horizontal position on the canvas. Runes that join the previous grapheme
+// cluster never wrap on their own.
+func (cs *cellScanner) runeWrapNeeded(r rune) bool {
+	if cs.cluster.Joins(r) {
+		return false
+	}
 	rw := runewidth.RuneWidth(r)
-	return posX > width-rw
+	return cs.posX > cs.width-rw
 }
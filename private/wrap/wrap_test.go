@@ -660,6 +660,26 @@ func TestCells(t *testing.T) {
 				buffer.NewCells("bc", cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorBlue)),
 			},
 		},
+		{
+			desc:  "wraps at runes, doesn't split emoji sequences",
+			cells: buffer.NewCells("a👨\u200d👩b"),
+			width: 3,
+			mode:  AtRunes,
+			want: [][]*buffer.Cell{
+				buffer.NewCells("a👨\u200d👩"),
+				buffer.NewCells("b"),
+			},
+		},
+		{
+			desc:  "wraps at words, measures emoji sequences as one cluster",
+			cells: buffer.NewCells("ab 👋🏻 cd"),
+			width: 5,
+			mode:  AtWords,
+			want: [][]*buffer.Cell{
+				buffer.NewCells("ab 👋🏻"),
+				buffer.NewCells("cd"),
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -683,6 +703,7 @@ func TestCells(t *testing.T) {
 func TestRuneWrapNeeded(t *testing.T) {
 	tests := []struct {
 		desc  string
+		prev  string
 		r     rune
 		posX  int
 		width int
@@ -730,11 +751,32 @@ func TestRuneWrapNeeded(t *testing.T) {
 			width: 3,
 			want:  false,
 		},
+		{
+			desc:  "doesn't wrap a rune that joins an emoji sequence",
+			prev:  "👨\u200d",
+			r:     '👩',
+			posX:  3,
+			width: 3,
+			want:  false,
+		},
+		{
+			desc:  "wraps an emoji that follows an emoji sequence",
+			prev:  "👨\u200d👩",
+			r:     '👧',
+			posX:  2,
+			width: 3,
+			want:  true,
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.desc, func(t *testing.T) {
-			got := runeWrapNeeded(tc.r, tc.posX, tc.width)
+			cs := newCellScanner(nil, tc.width, AtRunes)
+			cs.posX = tc.posX
+			for _, r := range tc.prev {
+				cs.cluster.Add(r)
+			}
+			got := cs.runeWrapNeeded(tc.r)
 			if got != tc.want {
 				t.Errorf("runeWrapNeeded => got %v, want %v", got, tc.want)
 			}
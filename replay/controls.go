@@ -0,0 +1,312 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+// controls.go contains the widget that controls the replay.
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// ControlsOption is used to provide options to Controls().
+type ControlsOption interface {
+	// set sets the provided option.
+	set(*controlsOptions)
+}
+
+// controlsOptions stores the provided options.
+type controlsOptions struct {
+	seekStep       time.Duration
+	liveCellOpts   []cell.Option
+	replayCellOpts []cell.Option
+	markerCellOpts []cell.Option
+}
+
+// validate validates the provided options.
+func (o *controlsOptions) validate() error {
+	if o.seekStep <= 0 {
+		return fmt.Errorf("invalid SeekStep(%v), must be a positive duration", o.seekStep)
+	}
+	return nil
+}
+
+// controlsOption implements ControlsOption.
+type controlsOption func(*controlsOptions)
+
+// set implements ControlsOption.set.
+func (o controlsOption) set(opts *controlsOptions) {
+	o(opts)
+}
+
+// DefaultSeekStep is the default value for the SeekStep option.
+const DefaultSeekStep = 10 * time.Second
+
+// SeekStep sets how far the arrow keys move the replay.
+// Defaults to DefaultSeekStep.
+func SeekStep(d time.Duration) ControlsOption {
+	return controlsOption(func(opts *controlsOptions) {
+		opts.seekStep = d
+	})
+}
+
+// LiveCellOpts sets the cell options of the status while the live updates
+// are displayed.
+// Defaults to green text.
+func LiveCellOpts(opts ...cell.Option) ControlsOption {
+	return controlsOption(func(o *controlsOptions) {
+		o.liveCellOpts = opts
+	})
+}
+
+// ReplayCellOpts sets the cell options of the status while replaying.
+// Defaults to yellow text.
+func ReplayCellOpts(opts ...cell.Option) ControlsOption {
+	return controlsOption(func(o *controlsOptions) {
+		o.replayCellOpts = opts
+	})
+}
+
+// MarkerCellOpts sets the cell options of the marker of the position on the
+// timeline.
+// Defaults to yellow text.
+func MarkerCellOpts(opts ...cell.Option) ControlsOption {
+	return controlsOption(func(o *controlsOptions) {
+		o.markerCellOpts = opts
+	})
+}
+
+// Symbols used by the Controls.
+const (
+	liveSymbol   = '●'
+	playSymbol   = '▶'
+	pauseSymbol  = '⏸'
+	lineSymbol   = '─'
+	markerSymbol = '◆'
+)
+
+// Controls displays the status of the replay and a timeline with the
+// displayed position on the first two rows.
+//
+// The widget plays and pauses the replay when space is pressed, seeks by the
+// SeekStep with the left and right arrow keys, seeks to the clicked position
+// on the timeline and returns to the live updates when 'l' or End is
+// pressed. The playing replay advances each time the widget is ticked, see
+// termdash.TickInterval.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Controls struct {
+	// r is the controlled recorder.
+	r *Recorder
+
+	// timeline is the area of the timeline the last time the widget was
+	// drawn.
+	timeline image.Rectangle
+	// status is the status of the recorder the last time the widget was
+	// drawn.
+	status Status
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the Controls.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *controlsOptions
+}
+
+// Controls returns a new widget that controls the replay of the recorder.
+func (r *Recorder) Controls(opts ...ControlsOption) (*Controls, error) {
+	opt := &controlsOptions{
+		seekStep: DefaultSeekStep,
+		liveCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorGreen),
+		},
+		replayCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorYellow),
+		},
+		markerCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorYellow),
+		},
+	}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	c := &Controls{
+		r:    r,
+		opts: opt,
+	}
+	r.onStatusChange(c.changed)
+	return c, nil
+}
+
+// changed notifies the infrastructure that the status changed.
+func (c *Controls) changed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.notify != nil {
+		c.notify()
+	}
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (c *Controls) SetNotifyFunc(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notify = fn
+}
+
+// Tick implements widgetapi.Ticker.Tick.
+func (c *Controls) Tick(now time.Time) {
+	c.r.Advance()
+}
+
+// statusText returns the text describing the status.
+func statusText(s Status) string {
+	if !s.Replaying {
+		return fmt.Sprintf("%c LIVE", liveSymbol)
+	}
+	sym := pauseSymbol
+	if s.Playing {
+		sym = playSymbol
+	}
+	behind := s.End.Sub(s.Position).Round(time.Second)
+	text := fmt.Sprintf("%c %s (-%v)", sym, s.Position.Format("15:04:05"), behind)
+	if s.Speed != 1 {
+		text += fmt.Sprintf(" x%g", s.Speed)
+	}
+	return text
+}
+
+// markerX returns the column of the marker of the position on the timeline.
+func markerX(s Status, timeline image.Rectangle) int {
+	span := s.End.Sub(s.Start)
+	if span <= 0 || timeline.Dx() <= 1 {
+		return timeline.Max.X - 1
+	}
+	x := int(float64(s.Position.Sub(s.Start)) / float64(span) * float64(timeline.Dx()-1))
+	return timeline.Min.X + x
+}
+
+// Draw draws the Controls widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (c *Controls) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.r.Status()
+	c.status = s
+	ar := cvs.Area()
+	cOpts := c.opts.liveCellOpts
+	if s.Replaying {
+		cOpts = c.opts.replayCellOpts
+	}
+	if err := draw.Text(cvs, statusText(s), ar.Min,
+		draw.TextMaxX(ar.Max.X),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		draw.TextCellOpts(cOpts...),
+	); err != nil {
+		return err
+	}
+
+	c.timeline = image.ZR
+	if ar.Dy() < 2 {
+		return nil
+	}
+	c.timeline = image.Rect(ar.Min.X, ar.Min.Y+1, ar.Max.X, ar.Min.Y+2)
+	for x := c.timeline.Min.X; x < c.timeline.Max.X; x++ {
+		if _, err := cvs.SetCell(image.Point{x, c.timeline.Min.Y}, lineSymbol); err != nil {
+			return err
+		}
+	}
+	mx := markerX(s, c.timeline)
+	if _, err := cvs.SetCell(image.Point{mx, c.timeline.Min.Y}, markerSymbol, c.opts.markerCellOpts...); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Keyboard processes keyboard events, see Controls.
+// Implements widgetapi.Widget.Keyboard.
+func (c *Controls) Keyboard(k *terminalapi.Keyboard) error {
+	switch k.Key {
+	case keyboard.KeySpace:
+		if s := c.r.Status(); s.Replaying && s.Playing {
+			c.r.Pause()
+		} else if s.Replaying {
+			c.r.Play()
+		} else {
+			c.r.Replay()
+		}
+
+	case keyboard.KeyArrowLeft:
+		c.r.SeekBy(-c.opts.seekStep)
+
+	case keyboard.KeyArrowRight:
+		if c.r.Status().Replaying {
+			c.r.SeekBy(c.opts.seekStep)
+		}
+
+	case 'l', keyboard.KeyEnd:
+		c.r.Live()
+	}
+	return nil
+}
+
+// Mouse seeks to the clicked position on the timeline.
+// Implements widgetapi.Widget.Mouse.
+func (c *Controls) Mouse(m *terminalapi.Mouse) error {
+	if m.Button != mouse.ButtonLeft {
+		return nil
+	}
+
+	c.mu.Lock()
+	timeline, s := c.timeline, c.status
+	c.mu.Unlock()
+	if !m.Position.In(timeline) {
+		return nil
+	}
+	span := s.End.Sub(s.Start)
+	if span <= 0 || timeline.Dx() <= 1 {
+		return nil
+	}
+	frac := float64(m.Position.X-timeline.Min.X) / float64(timeline.Dx()-1)
+	c.r.Seek(s.Start.Add(time.Duration(frac * float64(span))))
+	return nil
+}
+
+// Options implements widgetapi.Widget.Options.
+func (c *Controls) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		MaximumSize:  image.Point{0, 2},
+		WantKeyboard: widgetapi.KeyScopeFocused,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+func TestControls(t *testing.T) {
+	tests := []struct {
+		desc       string
+		opts       []ControlsOption
+		events     []terminalapi.Event
+		size       image.Point
+		want       func(size image.Point) *faketerm.Terminal
+		wantStatus func(start time.Time) Status
+		wantNewErr bool
+	}{
+		{
+			desc:       "fails on invalid SeekStep",
+			opts:       []ControlsOption{SeekStep(0)},
+			wantNewErr: true,
+		},
+		{
+			desc: "displays the live status",
+			size: image.Point{10, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "● LIVE", image.Point{0, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorGreen)))
+				testdraw.MustText(cvs, "─────────", image.Point{0, 1})
+				testcanvas.MustSetCell(cvs, image.Point{9, 1}, '◆', cell.FgColor(cell.ColorYellow))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantStatus: func(start time.Time) Status {
+				return Status{
+					Speed:    1,
+					Position: start.Add(time.Minute),
+					Start:    start,
+					End:      start.Add(time.Minute),
+				}
+			},
+		},
+		{
+			desc: "space pauses the replay at the current time",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeySpace},
+			},
+			size: image.Point{20, 1},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "⏸ 12:01:00 (-0s)", image.Point{0, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorYellow)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantStatus: func(start time.Time) Status {
+				return Status{
+					Replaying: true,
+					Speed:     1,
+					Position:  start.Add(time.Minute),
+					Start:     start,
+					End:       start.Add(time.Minute),
+				}
+			},
+		},
+		{
+			desc: "seeks back with the arrow key and plays",
+			opts: []ControlsOption{
+				SeekStep(30 * time.Second),
+				ReplayCellOpts(cell.FgColor(cell.ColorRed)),
+				MarkerCellOpts(cell.FgColor(cell.ColorBlue)),
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+				&terminalapi.Keyboard{Key: keyboard.KeySpace},
+			},
+			size: image.Point{21, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "▶ 12:00:30 (-30s)", image.Point{0, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorRed)))
+				testdraw.MustText(cvs, "─────────────────────", image.Point{0, 1})
+				testcanvas.MustSetCell(cvs, image.Point{10, 1}, '◆', cell.FgColor(cell.ColorBlue))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantStatus: func(start time.Time) Status {
+				return Status{
+					Replaying: true,
+					Playing:   true,
+					Speed:     1,
+					Position:  start.Add(30 * time.Second),
+					Start:     start,
+					End:       start.Add(time.Minute),
+				}
+			},
+		},
+		{
+			desc: "seeks to the clicked position on the timeline",
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{5, 1}, Button: mouse.ButtonLeft},
+			},
+			size: image.Point{11, 2},
+			wantStatus: func(start time.Time) Status {
+				return Status{
+					Replaying: true,
+					Speed:     1,
+					Position:  start.Add(30 * time.Second),
+					Start:     start,
+					End:       start.Add(time.Minute),
+				}
+			},
+		},
+		{
+			desc: "returns to the live updates",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+				&terminalapi.Keyboard{Key: 'l'},
+			},
+			size: image.Point{10, 1},
+			wantStatus: func(start time.Time) Status {
+				return Status{
+					Speed:    1,
+					Position: start.Add(time.Minute),
+					Start:    start,
+					End:      start.Add(time.Minute),
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			fc, restore := newFakeClock()
+			defer restore()
+			start := fc.now
+
+			r, err := New()
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			c, err := r.Controls(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("Controls => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+			r.Update("rps", func() {})
+			fc.now = fc.now.Add(time.Minute)
+
+			cvs, err := canvas.New(image.Rectangle{Max: tc.size})
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			// The first draw determines the position of the timeline.
+			if err := c.Draw(cvs, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			for _, ev := range tc.events {
+				switch e := ev.(type) {
+				case *terminalapi.Keyboard:
+					if err := c.Keyboard(e); err != nil {
+						t.Fatalf("Keyboard => unexpected error: %v", err)
+					}
+				case *terminalapi.Mouse:
+					if err := c.Mouse(e); err != nil {
+						t.Fatalf("Mouse => unexpected error: %v", err)
+					}
+				}
+			}
+			if diff := pretty.Compare(tc.wantStatus(start), r.Status()); diff != "" {
+				t.Errorf("Status => unexpected diff (-want, +got):\n%s", diff)
+			}
+			if tc.want == nil {
+				return
+			}
+
+			cvs, err = canvas.New(image.Rectangle{Max: tc.size})
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := c.Draw(cvs, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			got, err := faketerm.New(cvs.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := cvs.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(cvs.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestControlsTick(t *testing.T) {
+	fc, restore := newFakeClock()
+	defer restore()
+
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	c, err := r.Controls()
+	if err != nil {
+		t.Fatalf("Controls => unexpected error: %v", err)
+	}
+	notified := 0
+	c.SetNotifyFunc(func() {
+		notified++
+	})
+
+	start := fc.now
+	r.Update("rps", func() {})
+	fc.now = fc.now.Add(time.Minute)
+	r.Play()
+	fc.now = fc.now.Add(10 * time.Second)
+	c.Tick(fc.now)
+	if got, want := r.Status().Position, start.Add(10*time.Second); !got.Equal(want) {
+		t.Errorf("Tick => position %v, want %v", got, want)
+	}
+	if want := 2; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
@@ -0,0 +1,452 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replay records the data updates of the widgets and replays them,
+// so incidents can be re-viewed after the fact inside the same dashboard.
+//
+// The application routes its updates through a Recorder, each update
+// identified by a key:
+//
+//	r.Update("rps", func() {
+//		if err := lc.Series("rps", values); err != nil {
+//			...
+//		}
+//	})
+//
+// The recorded updates must replace the content they display, e.g. set all
+// the values of a series rather than append one, because the recorder
+// reconstructs the dashboard at a moment in time by applying the latest
+// update of each key recorded before that moment. The Controls widget plays,
+// pauses and seeks the replay and returns to the live data.
+package replay
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/freeze"
+)
+
+// timeNow returns the current time.
+// Exists to be replaced in tests.
+var timeNow = time.Now
+
+// Option is used to provide options to New().
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// options stores the provided options.
+type options struct {
+	window time.Duration
+	gate   *freeze.Gate
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.window <= 0 {
+		return fmt.Errorf("invalid Window(%v), must be a positive duration", o.window)
+	}
+	return nil
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// DefaultWindow is the default value for the Window option.
+const DefaultWindow = time.Hour
+
+// Window sets how long the recorder keeps the updates, older updates are
+// forgotten, except for the latest update of each key which is needed to
+// reconstruct the start of the window.
+// Defaults to DefaultWindow.
+func Window(d time.Duration) Option {
+	return option(func(opts *options) {
+		opts.window = d
+	})
+}
+
+// Gate applies the live updates through the gate, so they can be frozen,
+// see the freeze package. The updates are provided to the gate with
+// freeze.Gate.ApplyLatest under their keys. The replayed updates bypass the
+// gate.
+func Gate(g *freeze.Gate) Option {
+	return option(func(opts *options) {
+		opts.gate = g
+	})
+}
+
+// record is a recorded update.
+type record struct {
+	at  time.Time
+	key string
+	fn  func()
+}
+
+// Status is the state of the recorder at a point in time.
+type Status struct {
+	// Replaying indicates that the recorder replays the recorded updates
+	// instead of applying the live ones.
+	Replaying bool
+	// Playing indicates that the replay advances with time, see
+	// Recorder.Play.
+	Playing bool
+	// Speed is the speed of the playback, see Recorder.SetSpeed.
+	Speed float64
+
+	// Position is the moment displayed while replaying.
+	Position time.Time
+	// Start and End are the times of the oldest update that can be replayed
+	// and the current time.
+	Start, End time.Time
+}
+
+// Recorder records the updates and applies either the live or the replayed
+// ones.
+// This object is thread-safe.
+type Recorder struct {
+	// mu protects the Recorder.
+	mu sync.Mutex
+
+	// records are the recorded updates, the oldest first.
+	records []*record
+	// base are the latest updates of each key that are older than the
+	// window, keyed by the keys.
+	base map[string]*record
+	// shown are the updates applied last, keyed by the keys.
+	shown map[string]*record
+
+	// replaying indicates that the replayed updates are applied instead of
+	// the live ones.
+	replaying bool
+	// playing indicates that the position advances when Advance is called.
+	playing bool
+	// speed is the speed of the playback.
+	speed float64
+	// pos is the displayed moment while replaying.
+	pos time.Time
+	// lastAdvance is the time Advance was last called while playing.
+	lastAdvance time.Time
+
+	// onChange are called when the status changes.
+	onChange []func()
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new Recorder that applies the live updates.
+func New(opts ...Option) (*Recorder, error) {
+	o := &options{
+		window: DefaultWindow,
+	}
+	for _, opt := range opts {
+		opt.set(o)
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		base:  map[string]*record{},
+		shown: map[string]*record{},
+		speed: 1,
+		opts:  o,
+	}, nil
+}
+
+// Update records the update identified by the key, which must replace the
+// content displayed by the previous updates with the same key. The update
+// runs immediately unless the recorder is replaying.
+func (r *Recorder) Update(key string, fn func()) {
+	r.mu.Lock()
+	now := timeNow()
+	rec := &record{at: now, key: key, fn: fn}
+	r.records = append(r.records, rec)
+	r.trim(now)
+	if r.replaying {
+		r.mu.Unlock()
+		return
+	}
+	r.shown[key] = rec
+	r.mu.Unlock()
+
+	r.applyLive(rec)
+}
+
+// applyLive applies the live update, through the gate if one was provided.
+func (r *Recorder) applyLive(rec *record) {
+	if g := r.opts.gate; g != nil {
+		g.ApplyLatest(rec.key, rec.fn)
+		return
+	}
+	rec.fn()
+}
+
+// trim forgets the updates older than the window.
+// The caller must hold r.mu.
+func (r *Recorder) trim(now time.Time) {
+	cutoff := now.Add(-r.opts.window)
+	i := 0
+	for ; i < len(r.records) && r.records[i].at.Before(cutoff); i++ {
+		r.base[r.records[i].key] = r.records[i]
+	}
+	if i > 0 {
+		r.records = r.records[i:]
+	}
+}
+
+// start returns the time of the oldest update that can be replayed.
+// The caller must hold r.mu.
+func (r *Recorder) start(now time.Time) time.Time {
+	if len(r.base) > 0 {
+		return now.Add(-r.opts.window)
+	}
+	if len(r.records) > 0 {
+		return r.records[0].at
+	}
+	return now
+}
+
+// Status returns the state of the recorder.
+func (r *Recorder) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := timeNow()
+	pos := r.pos
+	if !r.replaying {
+		pos = now
+	}
+	return Status{
+		Replaying: r.replaying,
+		Playing:   r.playing,
+		Speed:     r.speed,
+		Position:  pos,
+		Start:     r.start(now),
+		End:       now,
+	}
+}
+
+// Replay starts replaying, the replay is paused at the current time. Live
+// updates are recorded but not applied until Live is called. Has no effect
+// if the recorder is already replaying.
+func (r *Recorder) Replay() {
+	r.mu.Lock()
+	if r.replaying {
+		r.mu.Unlock()
+		return
+	}
+	r.replaying = true
+	r.playing = false
+	r.pos = timeNow()
+	r.mu.Unlock()
+	r.changed()
+}
+
+// Seek displays the moment in time, clamped to the recorded updates. Starts
+// replaying if the recorder isn't replaying.
+func (r *Recorder) Seek(t time.Time) {
+	r.mu.Lock()
+	if !r.replaying {
+		r.replaying = true
+		r.playing = false
+	}
+	recs := r.seek(t)
+	r.mu.Unlock()
+
+	apply(recs)
+	r.changed()
+}
+
+// SeekBy moves the displayed moment by the duration, backwards if the
+// duration is negative. Starts replaying at the current time if the
+// recorder isn't replaying.
+func (r *Recorder) SeekBy(d time.Duration) {
+	r.mu.Lock()
+	pos := r.pos
+	if !r.replaying {
+		pos = timeNow()
+	}
+	r.mu.Unlock()
+	r.Seek(pos.Add(d))
+}
+
+// seek moves the position and returns the updates that display it in the
+// order they need to be applied.
+// The caller must hold r.mu.
+func (r *Recorder) seek(t time.Time) []*record {
+	now := timeNow()
+	if start := r.start(now); t.Before(start) {
+		t = start
+	}
+	if t.After(now) {
+		t = now
+	}
+	r.pos = t
+
+	// The latest update of each key at or before the position.
+	latest := map[string]*record{}
+	for k, rec := range r.base {
+		latest[k] = rec
+	}
+	for _, rec := range r.records {
+		if rec.at.After(t) {
+			break
+		}
+		latest[rec.key] = rec
+	}
+	return r.show(latest)
+}
+
+// show returns the updates of the provided ones that aren't displayed yet
+// in the order they were recorded and records them as displayed.
+// The caller must hold r.mu.
+func (r *Recorder) show(latest map[string]*record) []*record {
+	var recs []*record
+	for k, rec := range latest {
+		if r.shown[k] != rec {
+			recs = append(recs, rec)
+			r.shown[k] = rec
+		}
+	}
+	sort.SliceStable(recs, func(i, j int) bool {
+		return recs[i].at.Before(recs[j].at)
+	})
+	return recs
+}
+
+// apply applies the replayed updates.
+func apply(recs []*record) {
+	for _, rec := range recs {
+		rec.fn()
+	}
+}
+
+// Play advances the replay with time, see Advance. Starts replaying at the
+// oldest recorded update if the recorder isn't replaying.
+func (r *Recorder) Play() {
+	r.mu.Lock()
+	var recs []*record
+	if !r.replaying {
+		r.replaying = true
+		recs = r.seek(r.start(timeNow()))
+	}
+	r.playing = true
+	r.lastAdvance = timeNow()
+	r.mu.Unlock()
+
+	apply(recs)
+	r.changed()
+}
+
+// Pause stops advancing the replay.
+func (r *Recorder) Pause() {
+	r.mu.Lock()
+	r.playing = false
+	r.mu.Unlock()
+	r.changed()
+}
+
+// SetSpeed sets the speed of the playback, e.g. 2 plays the replay twice as
+// fast as the updates were recorded. Must be a positive number.
+func (r *Recorder) SetSpeed(speed float64) error {
+	if speed <= 0 {
+		return fmt.Errorf("invalid speed %v, must be a positive number", speed)
+	}
+	r.mu.Lock()
+	r.speed = speed
+	r.mu.Unlock()
+	r.changed()
+	return nil
+}
+
+// Advance moves the position of the playing replay by the time elapsed
+// since the last call multiplied by the speed. The replay switches to the
+// live updates once it reaches the current time. Has no effect unless the
+// replay is playing.
+// The Controls widget calls Advance each time it is ticked.
+func (r *Recorder) Advance() {
+	r.mu.Lock()
+	if !r.replaying || !r.playing {
+		r.mu.Unlock()
+		return
+	}
+	now := timeNow()
+	elapsed := now.Sub(r.lastAdvance)
+	r.lastAdvance = now
+	pos := r.pos.Add(time.Duration(float64(elapsed) * r.speed))
+	if !pos.Before(now) {
+		r.mu.Unlock()
+		r.Live()
+		return
+	}
+	recs := r.seek(pos)
+	r.mu.Unlock()
+
+	apply(recs)
+	r.changed()
+}
+
+// Live stops replaying and applies the latest update of each key, including
+// the updates recorded while replaying. Has no effect if the recorder isn't
+// replaying.
+func (r *Recorder) Live() {
+	r.mu.Lock()
+	if !r.replaying {
+		r.mu.Unlock()
+		return
+	}
+	r.replaying = false
+	r.playing = false
+
+	latest := map[string]*record{}
+	for k, rec := range r.base {
+		latest[k] = rec
+	}
+	for _, rec := range r.records {
+		latest[rec.key] = rec
+	}
+	recs := r.show(latest)
+	r.mu.Unlock()
+
+	for _, rec := range recs {
+		r.applyLive(rec)
+	}
+	r.changed()
+}
+
+// onStatusChange registers a function that is called when the status
+// changes.
+func (r *Recorder) onStatusChange(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChange = append(r.onChange, fn)
+}
+
+// changed calls the functions registered via onStatusChange.
+func (r *Recorder) changed() {
+	r.mu.Lock()
+	fns := append([]func(){}, r.onChange...)
+	r.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/freeze"
+)
+
+// fakeClock replaces timeNow for the duration of a test.
+type fakeClock struct {
+	now time.Time
+}
+
+// newFakeClock returns a clock set to a fixed time and a function that
+// restores timeNow.
+func newFakeClock() (*fakeClock, func()) {
+	fc := &fakeClock{now: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)}
+	timeNow = func() time.Time { return fc.now }
+	return fc, func() { timeNow = time.Now }
+}
+
+// display records the updates applied to a simulated widget.
+type display struct {
+	values map[string]string
+}
+
+// update returns an update that displays the value under the key.
+func (d *display) update(r *Recorder, key, value string) {
+	r.Update(key, func() {
+		d.values[key] = value
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	fc, restore := newFakeClock()
+	defer restore()
+
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	d := &display{values: map[string]string{}}
+	start := fc.now
+
+	d.update(r, "rps", "10")
+	fc.now = fc.now.Add(10 * time.Second)
+	d.update(r, "errors", "0")
+	fc.now = fc.now.Add(10 * time.Second)
+	d.update(r, "rps", "20")
+	fc.now = fc.now.Add(10 * time.Second)
+
+	check := func(desc string, want map[string]string) {
+		t.Helper()
+		if diff := pretty.Compare(want, d.values); diff != "" {
+			t.Errorf("%s => unexpected diff (-want, +got):\n%s", desc, diff)
+		}
+	}
+	check("live updates", map[string]string{"rps": "20", "errors": "0"})
+
+	r.Seek(start.Add(5 * time.Second))
+	check("Seek", map[string]string{"rps": "10", "errors": "0"})
+	if s := r.Status(); !s.Replaying || s.Playing || !s.Position.Equal(start.Add(5*time.Second)) {
+		t.Errorf("Status => %+v, want paused replay at +5s", s)
+	}
+
+	d.update(r, "errors", "5")
+	check("live update while replaying", map[string]string{"rps": "10", "errors": "0"})
+
+	r.SeekBy(20 * time.Second)
+	check("SeekBy", map[string]string{"rps": "20", "errors": "0"})
+
+	r.Seek(start.Add(-time.Hour))
+	if s := r.Status(); !s.Position.Equal(start) {
+		t.Errorf("Seek before the start => position %v, want %v", s.Position, start)
+	}
+
+	r.Live()
+	check("Live", map[string]string{"rps": "20", "errors": "5"})
+	if s := r.Status(); s.Replaying {
+		t.Errorf("Status => %+v, want live", s)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	fc, restore := newFakeClock()
+	defer restore()
+
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	d := &display{values: map[string]string{}}
+	d.update(r, "rps", "1")
+	fc.now = fc.now.Add(10 * time.Second)
+	d.update(r, "rps", "2")
+	fc.now = fc.now.Add(10 * time.Second)
+	d.update(r, "rps", "3")
+
+	r.Play()
+	if got, want := d.values["rps"], "1"; got != want {
+		t.Errorf("Play => displays %q, want %q from the start", got, want)
+	}
+	if err := r.SetSpeed(2); err != nil {
+		t.Fatalf("SetSpeed => unexpected error: %v", err)
+	}
+	if err := r.SetSpeed(0); err == nil {
+		t.Errorf("SetSpeed(0) => got nil error, want an error")
+	}
+
+	fc.now = fc.now.Add(5 * time.Second)
+	r.Advance()
+	if got, want := d.values["rps"], "2"; got != want {
+		t.Errorf("Advance => displays %q, want %q", got, want)
+	}
+
+	r.Pause()
+	fc.now = fc.now.Add(5 * time.Second)
+	r.Advance()
+	if got, want := d.values["rps"], "2"; got != want {
+		t.Errorf("Advance while paused => displays %q, want %q", got, want)
+	}
+
+	r.Play()
+	fc.now = fc.now.Add(time.Minute)
+	r.Advance()
+	if s := r.Status(); s.Replaying {
+		t.Errorf("Advance past the current time => %+v, want live", s)
+	}
+	if got, want := d.values["rps"], "3"; got != want {
+		t.Errorf("Advance past the current time => displays %q, want %q", got, want)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	fc, restore := newFakeClock()
+	defer restore()
+
+	if _, err := New(Window(0)); err == nil {
+		t.Errorf("New(Window(0)) => got nil error, want an error")
+	}
+
+	r, err := New(Window(time.Minute))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	d := &display{values: map[string]string{}}
+	d.update(r, "old", "kept")
+	d.update(r, "rps", "1")
+	fc.now = fc.now.Add(2 * time.Minute)
+	d.update(r, "rps", "2")
+	if got, want := len(r.records), 1; got != want {
+		t.Errorf("records => %d, want %d within the window", got, want)
+	}
+
+	r.Seek(time.Time{})
+	want := map[string]string{"old": "kept", "rps": "1"}
+	if diff := pretty.Compare(want, d.values); diff != "" {
+		t.Errorf("Seek to the start of the window => unexpected diff (-want, +got):\n%s", diff)
+	}
+	if s := r.Status(); !s.Start.Equal(fc.now.Add(-time.Minute)) {
+		t.Errorf("Status => start %v, want %v", s.Start, fc.now.Add(-time.Minute))
+	}
+}
+
+func TestGate(t *testing.T) {
+	_, restore := newFakeClock()
+	defer restore()
+
+	g, err := freeze.New()
+	if err != nil {
+		t.Fatalf("freeze.New => unexpected error: %v", err)
+	}
+	r, err := New(Gate(g))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	d := &display{values: map[string]string{}}
+
+	g.Freeze()
+	d.update(r, "rps", "1")
+	d.update(r, "rps", "2")
+	if got := d.values["rps"]; got != "" {
+		t.Errorf("update while frozen => displays %q, want nothing", got)
+	}
+	if pending, _ := g.Pending(); pending != 1 {
+		t.Errorf("Pending => %d, want 1", pending)
+	}
+	g.Resume()
+	if got, want := d.values["rps"], "2"; got != want {
+		t.Errorf("Resume => displays %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rules implements conditional styling of widgets. A rule binds a
+// style to a predicate over the value the widget displays, e.g. a gauge
+// above 90% turns red and blinks:
+//
+//	r, err := rules.New(
+//		rules.When(rules.Above(90), rules.Style{Color: cell.ColorRed, Blink: true}),
+//		rules.When(rules.Above(75), rules.Style{Color: cell.ColorYellow}),
+//	)
+//	...
+//	g, err := gauge.New(gauge.Rules(r))
+//
+// The widgets evaluate the rules each time their values are updated, so the
+// threshold styling is defined in one place instead of by the code that
+// feeds the data.
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+)
+
+// Predicate reports whether a rule applies to the value.
+type Predicate func(value float64) bool
+
+// Above returns a predicate that matches values larger than the threshold.
+func Above(threshold float64) Predicate {
+	return func(v float64) bool { return v > threshold }
+}
+
+// AtLeast returns a predicate that matches values larger than or equal to
+// the threshold.
+func AtLeast(threshold float64) Predicate {
+	return func(v float64) bool { return v >= threshold }
+}
+
+// Below returns a predicate that matches values smaller than the threshold.
+func Below(threshold float64) Predicate {
+	return func(v float64) bool { return v < threshold }
+}
+
+// AtMost returns a predicate that matches values smaller than or equal to
+// the threshold.
+func AtMost(threshold float64) Predicate {
+	return func(v float64) bool { return v <= threshold }
+}
+
+// Between returns a predicate that matches values in the range
+// min <= value < max.
+func Between(min, max float64) Predicate {
+	return func(v float64) bool { return v >= min && v < max }
+}
+
+// Style are the visual properties applied by a rule. The properties left at
+// the zero value keep the style configured on the widget, which means a rule
+// cannot set a color to cell.ColorDefault. What each of the colors applies to
+// is documented by the widgets that accept rules.
+type Style struct {
+	// Color is the color of the data, e.g. the bar of a gauge.
+	Color cell.Color
+	// TextColor is the color of the text that displays the value.
+	TextColor cell.Color
+	// BorderColor is the color of the border of the widget.
+	BorderColor cell.Color
+	// Blink alternates between this style and the style configured on the
+	// widget every BlinkInterval. Blinking requires the dashboard to be
+	// redrawn periodically, see termdash.RedrawInterval.
+	Blink bool
+}
+
+// BlinkInterval is how long blinking styles stay applied and how long they
+// stay off.
+const BlinkInterval = 500 * time.Millisecond
+
+// Applied asserts whether the style is applied at the time, i.e. false
+// during the off phases of blinking styles.
+func (s Style) Applied(now time.Time) bool {
+	if !s.Blink {
+		return true
+	}
+	return now.UnixNano()/int64(BlinkInterval)%2 == 0
+}
+
+// Rule applies a style when the predicate matches, see When.
+type Rule struct {
+	when  Predicate
+	style Style
+}
+
+// When returns a rule that applies the style when the predicate matches.
+func When(p Predicate, s Style) Rule {
+	return Rule{
+		when:  p,
+		style: s,
+	}
+}
+
+// Rules is an ordered set of rules, the first rule that matches a value
+// determines its style.
+// This object is immutable and thread-safe.
+type Rules struct {
+	rules []Rule
+}
+
+// New returns a new set of rules, evaluated in the provided order.
+func New(rules ...Rule) (*Rules, error) {
+	for i, r := range rules {
+		if r.when == nil {
+			return nil, fmt.Errorf("rule[%d] has a nil predicate", i)
+		}
+	}
+	return &Rules{
+		rules: append([]Rule(nil), rules...),
+	}, nil
+}
+
+// Eval returns the style of the first rule that matches the value. Returns
+// false if none of the rules matches.
+func (r *Rules) Eval(value float64) (Style, bool) {
+	if r == nil {
+		return Style{}, false
+	}
+	for _, rule := range r.rules {
+		if rule.when(value) {
+			return rule.style, true
+		}
+	}
+	return Style{}, false
+}
+
+// pick returns the color if set and if the style is applied at the time,
+// otherwise returns the fallback color.
+func (s Style) pick(c, fallback cell.Color, now time.Time) cell.Color {
+	if c == cell.ColorDefault || !s.Applied(now) {
+		return fallback
+	}
+	return c
+}
+
+// ColorOr returns the Color of the style at the time or the fallback color
+// configured on the widget.
+func (s Style) ColorOr(fallback cell.Color, now time.Time) cell.Color {
+	return s.pick(s.Color, fallback, now)
+}
+
+// TextColorOr returns the TextColor of the style at the time or the fallback
+// color configured on the widget.
+func (s Style) TextColorOr(fallback cell.Color, now time.Time) cell.Color {
+	return s.pick(s.TextColor, fallback, now)
+}
+
+// BorderColorOr returns the BorderColor of the style at the time or the
+// fallback color configured on the widget.
+func (s Style) BorderColorOr(fallback cell.Color, now time.Time) cell.Color {
+	return s.pick(s.BorderColor, fallback, now)
+}
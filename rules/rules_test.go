@@ -0,0 +1,200 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+)
+
+func TestPredicates(t *testing.T) {
+	tests := []struct {
+		desc string
+		p    Predicate
+		in   float64
+		want bool
+	}{
+		{desc: "Above matches larger", p: Above(5), in: 6, want: true},
+		{desc: "Above doesn't match equal", p: Above(5), in: 5, want: false},
+		{desc: "AtLeast matches equal", p: AtLeast(5), in: 5, want: true},
+		{desc: "AtLeast doesn't match smaller", p: AtLeast(5), in: 4, want: false},
+		{desc: "Below matches smaller", p: Below(5), in: 4, want: true},
+		{desc: "Below doesn't match equal", p: Below(5), in: 5, want: false},
+		{desc: "AtMost matches equal", p: AtMost(5), in: 5, want: true},
+		{desc: "AtMost doesn't match larger", p: AtMost(5), in: 6, want: false},
+		{desc: "Between matches min", p: Between(1, 5), in: 1, want: true},
+		{desc: "Between doesn't match max", p: Between(1, 5), in: 5, want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := tc.p(tc.in); got != tc.want {
+				t.Errorf("predicate(%v) => %v, want %v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	red := Style{Color: cell.ColorRed, Blink: true}
+	yellow := Style{Color: cell.ColorYellow}
+
+	tests := []struct {
+		desc      string
+		rules     []Rule
+		value     float64
+		want      Style
+		wantMatch bool
+		wantErr   bool
+	}{
+		{
+			desc:    "fails on a nil predicate",
+			rules:   []Rule{When(nil, red)},
+			wantErr: true,
+		},
+		{
+			desc:  "no rules",
+			value: 10,
+		},
+		{
+			desc: "no rule matches",
+			rules: []Rule{
+				When(Above(90), red),
+				When(Above(75), yellow),
+			},
+			value: 10,
+		},
+		{
+			desc: "first matching rule wins",
+			rules: []Rule{
+				When(Above(90), red),
+				When(Above(75), yellow),
+			},
+			value:     95,
+			want:      red,
+			wantMatch: true,
+		},
+		{
+			desc: "later rule matches",
+			rules: []Rule{
+				When(Above(90), red),
+				When(Above(75), yellow),
+			},
+			value:     80,
+			want:      yellow,
+			wantMatch: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := New(tc.rules...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got, gotMatch := r.Eval(tc.value)
+			if gotMatch != tc.wantMatch {
+				t.Errorf("Eval => match %v, want %v", gotMatch, tc.wantMatch)
+			}
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("Eval => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEvalNilRules(t *testing.T) {
+	var r *Rules
+	if _, match := r.Eval(1); match {
+		t.Errorf("Eval on nil Rules => true, want false")
+	}
+}
+
+func TestColorOr(t *testing.T) {
+	on := time.Unix(0, 0)
+	off := on.Add(BlinkInterval)
+
+	tests := []struct {
+		desc       string
+		style      Style
+		now        time.Time
+		wantColor  cell.Color
+		wantText   cell.Color
+		wantBorder cell.Color
+	}{
+		{
+			desc:       "unset colors fall back",
+			now:        on,
+			wantColor:  cell.ColorGreen,
+			wantText:   cell.ColorGreen,
+			wantBorder: cell.ColorGreen,
+		},
+		{
+			desc: "set colors apply",
+			style: Style{
+				Color:       cell.ColorRed,
+				TextColor:   cell.ColorBlue,
+				BorderColor: cell.ColorYellow,
+			},
+			now:        off,
+			wantColor:  cell.ColorRed,
+			wantText:   cell.ColorBlue,
+			wantBorder: cell.ColorYellow,
+		},
+		{
+			desc: "blinking style applies during the on phase",
+			style: Style{
+				Color: cell.ColorRed,
+				Blink: true,
+			},
+			now:        on,
+			wantColor:  cell.ColorRed,
+			wantText:   cell.ColorGreen,
+			wantBorder: cell.ColorGreen,
+		},
+		{
+			desc: "blinking style falls back during the off phase",
+			style: Style{
+				Color: cell.ColorRed,
+				Blink: true,
+			},
+			now:        off,
+			wantColor:  cell.ColorGreen,
+			wantText:   cell.ColorGreen,
+			wantBorder: cell.ColorGreen,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := tc.style.ColorOr(cell.ColorGreen, tc.now); got != tc.wantColor {
+				t.Errorf("ColorOr => %v, want %v", got, tc.wantColor)
+			}
+			if got := tc.style.TextColorOr(cell.ColorGreen, tc.now); got != tc.wantText {
+				t.Errorf("TextColorOr => %v, want %v", got, tc.wantText)
+			}
+			if got := tc.style.BorderColorOr(cell.ColorGreen, tc.now); got != tc.wantBorder {
+				t.Errorf("BorderColorOr => %v, want %v", got, tc.wantBorder)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termdash
+
+// screens.go contains code that switches between multiple root containers.
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// ScreenOption is used to provide options to Screen().
+type ScreenOption interface {
+	// set sets the provided option.
+	set(*screen)
+}
+
+// screenOption implements ScreenOption.
+type screenOption func(*screen)
+
+// set implements ScreenOption.set.
+func (o screenOption) set(s *screen) {
+	o(s)
+}
+
+// ScreenKey binds the key sequence to a function that displays the screen.
+// The sequence is bound in the registry provided via the KeyBindings option,
+// which is required.
+func ScreenKey(seq keybinding.Sequence) ScreenOption {
+	return screenOption(func(s *screen) {
+		s.key = seq
+	})
+}
+
+// OnEnter sets a function that is called each time the screen is displayed,
+// including when the dashboard starts with it. The function is called
+// without holding any termdash locks, it must be thread-safe.
+func OnEnter(fn func()) ScreenOption {
+	return screenOption(func(s *screen) {
+		s.onEnter = fn
+	})
+}
+
+// OnLeave sets a function that is called each time a different screen
+// replaces the screen. The function is called without holding any termdash
+// locks, it must be thread-safe.
+func OnLeave(fn func()) ScreenOption {
+	return screenOption(func(s *screen) {
+		s.onLeave = fn
+	})
+}
+
+// screen is a root container that can be displayed instead of the others.
+type screen struct {
+	name    string
+	cont    *container.Container
+	key     keybinding.Sequence
+	onEnter func()
+	onLeave func()
+}
+
+// Screen registers a named screen, i.e. a root container with its own
+// layout that is displayed instead of the other screens, e.g. an overview
+// and a detail of a service. The container provided to Run or NewController
+// is displayed first and must be registered as one of the screens.
+//
+// Only the displayed screen is drawn and receives the input events, the
+// widgets on the other screens keep their state and can still be updated.
+// Switch the screens with the ScreenKey option or Controller.SwitchScreen.
+func Screen(name string, c *container.Container, opts ...ScreenOption) Option {
+	return option(func(td *termdash) {
+		s := &screen{
+			name: name,
+			cont: c,
+		}
+		for _, o := range opts {
+			o.set(s)
+		}
+		td.screens = append(td.screens, s)
+	})
+}
+
+// validateScreens validates the registered screens and sets the current one.
+func (td *termdash) validateScreens() error {
+	if len(td.screens) == 0 {
+		return nil
+	}
+
+	td.screen = -1
+	names := map[string]bool{}
+	conts := map[*container.Container]bool{}
+	for i, s := range td.screens {
+		if s.name == "" {
+			return errors.New("the name of a Screen cannot be an empty string")
+		}
+		if names[s.name] {
+			return fmt.Errorf("duplicate Screen name %q", s.name)
+		}
+		names[s.name] = true
+		if s.cont == nil {
+			return fmt.Errorf("the Screen %q must have a container", s.name)
+		}
+		if conts[s.cont] {
+			return fmt.Errorf("the container of Screen %q is already used by another screen", s.name)
+		}
+		conts[s.cont] = true
+		if s.key != nil && td.keyBindings == nil {
+			return fmt.Errorf("the ScreenKey of Screen %q requires the KeyBindings option", s.name)
+		}
+		if s.cont == td.container {
+			td.screen = i
+		}
+	}
+	if td.screen < 0 {
+		return errors.New("the container provided to Run or NewController must be registered as a Screen when using screens")
+	}
+	return nil
+}
+
+// bindScreenKeys binds the ScreenKey sequences in the key bindings.
+func (td *termdash) bindScreenKeys() error {
+	for _, s := range td.screens {
+		if s.key == nil {
+			continue
+		}
+		name := s.name
+		if err := td.keyBindings.Bind(s.key, func() error {
+			return td.switchScreen(name)
+		}, keybinding.Description(fmt.Sprintf("Show the %s screen", name))); err != nil {
+			return fmt.Errorf("invalid ScreenKey of Screen %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// containers returns the root containers of all the screens.
+func (td *termdash) containers() []*container.Container {
+	if len(td.screens) == 0 {
+		return []*container.Container{td.container}
+	}
+	var conts []*container.Container
+	for _, s := range td.screens {
+		conts = append(conts, s.cont)
+	}
+	return conts
+}
+
+// activeContainer returns the root container of the displayed screen.
+func (td *termdash) activeContainer() *container.Container {
+	td.screenMu.Lock()
+	defer td.screenMu.Unlock()
+	return td.container
+}
+
+// dispatch delivers the input event to the displayed screen.
+func (td *termdash) dispatch(ev terminalapi.Event) {
+	if err := td.activeContainer().Inject(ev); err != nil {
+		td.eds.Event(terminalapi.NewErrorf("failed to process event %v: %v", ev, err))
+	}
+}
+
+// switchScreen displays the screen with the name and redraws the terminal.
+// Does nothing if the screen is already displayed.
+func (td *termdash) switchScreen(name string) error {
+	next := -1
+	for i, s := range td.screens {
+		if s.name == name {
+			next = i
+		}
+	}
+	if next < 0 {
+		return fmt.Errorf("no screen named %q", name)
+	}
+
+	td.mu.Lock()
+	td.screenMu.Lock()
+	prev := td.screen
+	if prev == next {
+		td.screenMu.Unlock()
+		td.mu.Unlock()
+		return nil
+	}
+	td.screen = next
+	td.container = td.screens[next].cont
+	td.screenMu.Unlock()
+
+	// The previous screen might have covered parts of the terminal the new
+	// one doesn't draw on.
+	td.clearNeeded = true
+	err := td.redraw()
+	td.mu.Unlock()
+
+	if fn := td.screens[prev].onLeave; fn != nil {
+		fn()
+	}
+	if fn := td.screens[next].onEnter; fn != nil {
+		fn()
+	}
+	return err
+}
+
+// SwitchScreen displays the screen with the name, see the Screen option, and
+// redraws the terminal.
+func (c *Controller) SwitchScreen(name string) error {
+	if c.td == nil {
+		return errors.New("the termdash instance is no longer running, this controller is now invalid")
+	}
+	if len(c.td.screens) == 0 {
+		return errors.New("no screens were registered, see the Screen option")
+	}
+	return c.td.switchScreen(name)
+}
+
+// Screen returns the name of the displayed screen or an empty string if no
+// screens were registered.
+func (c *Controller) Screen() string {
+	if c.td == nil || len(c.td.screens) == 0 {
+		return ""
+	}
+	c.td.screenMu.Lock()
+	defer c.td.screenMu.Unlock()
+	return c.td.screens[c.td.screen].name
+}
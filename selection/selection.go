@@ -0,0 +1,364 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package selection implements a selection model for widgets that display a
+// list of items, e.g. rows of a list or a table.
+//
+// The model tracks the cursor, i.e. the item the user is currently on, and
+// the set of selected items. Widgets translate keyboard and mouse events into
+// calls to the model, which gives all the widgets that use it identical
+// selection semantics.
+package selection
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Mode determines how many items can be selected.
+type Mode int
+
+// String implements fmt.Stringer()
+func (m Mode) String() string {
+	if n, ok := modeNames[m]; ok {
+		return n
+	}
+	return "ModeUnknown"
+}
+
+// modeNames maps Mode values to human readable names.
+var modeNames = map[Mode]string{
+	ModeSingle:   "ModeSingle",
+	ModeMultiple: "ModeMultiple",
+}
+
+const (
+	// ModeSingle allows at most one selected item.
+	ModeSingle Mode = iota
+	// ModeMultiple allows any number of selected items, selected one by one
+	// or as ranges.
+	ModeMultiple
+)
+
+// Option is used to provide options to New().
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// options stores the provided options.
+type options struct {
+	mode     Mode
+	onChange func(selected []int)
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if _, ok := modeNames[o.mode]; !ok {
+		return fmt.Errorf("invalid selection mode %v", o.mode)
+	}
+	return nil
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// SelectionMode sets the selection mode. Defaults to ModeSingle.
+func SelectionMode(m Mode) Option {
+	return option(func(opts *options) {
+		opts.mode = m
+	})
+}
+
+// OnChange sets a function that is called with the indexes of the selected
+// items in ascending order every time the selection changes.
+// The function is called synchronously and must not call methods of the
+// Model.
+func OnChange(fn func(selected []int)) Option {
+	return option(func(opts *options) {
+		opts.onChange = fn
+	})
+}
+
+// Model tracks the cursor and the selected items among a number of items
+// identified by their indexes.
+//
+// The anchor is the item where a range selection starts. It is set by the
+// methods that select or toggle a single item, ExtendTo then selects all the
+// items between the anchor and the specified item.
+//
+// This object is thread-safe.
+type Model struct {
+	// size is the number of items.
+	size int
+	// cursor is the index of the item the cursor is on.
+	cursor int
+	// anchor is the index of the item where range selection starts.
+	anchor int
+	// selected are the indexes of the selected items.
+	selected map[int]bool
+
+	// mu protects the Model.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new selection model for the specified number of items.
+func New(size int, opts ...Option) (*Model, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid size %d, must be zero or a positive integer", size)
+	}
+	opt := &options{}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Model{
+		size:     size,
+		selected: map[int]bool{},
+		opts:     opt,
+	}, nil
+}
+
+// Size returns the number of items.
+func (m *Model) Size() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.size
+}
+
+// SetSize changes the number of items, e.g. when items were added or removed.
+// Items beyond the new size are deselected and the cursor and the anchor are
+// moved to the last item if they were beyond it.
+func (m *Model) SetSize(size int) error {
+	if size < 0 {
+		return fmt.Errorf("invalid size %d, must be zero or a positive integer", size)
+	}
+	m.mu.Lock()
+	m.size = size
+	m.cursor = m.clamp(m.cursor)
+	m.anchor = m.clamp(m.anchor)
+	changed := false
+	for i := range m.selected {
+		if i >= size {
+			delete(m.selected, i)
+			changed = true
+		}
+	}
+	m.unlockAndNotify(changed)
+	return nil
+}
+
+// Cursor returns the index of the item the cursor is on.
+// Returns zero if there are no items.
+func (m *Model) Cursor() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cursor
+}
+
+// SetCursor moves the cursor to the item, limited to the available items.
+// Doesn't change the selection.
+func (m *Model) SetCursor(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cursor = m.clamp(i)
+}
+
+// MoveCursor moves the cursor by the specified number of items, negative
+// values move it towards the first item. The cursor stops at the first and
+// the last item. Doesn't change the selection.
+func (m *Model) MoveCursor(delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cursor = m.clamp(m.cursor + delta)
+}
+
+// Select selects only the item at the index and moves the cursor and the
+// anchor to it.
+func (m *Model) Select(i int) error {
+	m.mu.Lock()
+	if err := m.check(i); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.cursor, m.anchor = i, i
+	changed := len(m.selected) != 1 || !m.selected[i]
+	m.selected = map[int]bool{i: true}
+	m.unlockAndNotify(changed)
+	return nil
+}
+
+// Toggle flips the selection of the item at the index and moves the cursor
+// and the anchor to it. In ModeSingle, selecting an item deselects all the
+// other items.
+func (m *Model) Toggle(i int) error {
+	m.mu.Lock()
+	if err := m.check(i); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.cursor, m.anchor = i, i
+	switch {
+	case m.selected[i]:
+		delete(m.selected, i)
+	case m.opts.mode == ModeSingle:
+		m.selected = map[int]bool{i: true}
+	default:
+		m.selected[i] = true
+	}
+	m.unlockAndNotify(true)
+	return nil
+}
+
+// ExtendTo selects all the items between the anchor and the item at the index
+// inclusive and deselects all the other items. Moves the cursor to the item,
+// the anchor stays in place. In ModeSingle this is the same as Select.
+func (m *Model) ExtendTo(i int) error {
+	if m.mode() == ModeSingle {
+		return m.Select(i)
+	}
+
+	m.mu.Lock()
+	if err := m.check(i); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.cursor = i
+	from, to := m.anchor, i
+	if from > to {
+		from, to = to, from
+	}
+	sel := map[int]bool{}
+	for j := from; j <= to; j++ {
+		sel[j] = true
+	}
+	changed := !sameSelection(m.selected, sel)
+	m.selected = sel
+	m.unlockAndNotify(changed)
+	return nil
+}
+
+// SelectAll selects all the items. In ModeSingle this does nothing.
+func (m *Model) SelectAll() {
+	m.mu.Lock()
+	if m.opts.mode == ModeSingle {
+		m.mu.Unlock()
+		return
+	}
+	changed := len(m.selected) != m.size
+	for i := 0; i < m.size; i++ {
+		m.selected[i] = true
+	}
+	m.unlockAndNotify(changed)
+}
+
+// Clear deselects all the items.
+func (m *Model) Clear() {
+	m.mu.Lock()
+	changed := len(m.selected) > 0
+	m.selected = map[int]bool{}
+	m.unlockAndNotify(changed)
+}
+
+// IsSelected determines if the item at the index is selected.
+func (m *Model) IsSelected(i int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.selected[i]
+}
+
+// Selected returns the indexes of the selected items in ascending order.
+func (m *Model) Selected() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.selectedSorted()
+}
+
+// mode returns the selection mode.
+func (m *Model) mode() Mode {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.opts.mode
+}
+
+// check checks that the index refers to an existing item.
+// Caller must hold m.mu.
+func (m *Model) check(i int) error {
+	if i < 0 || i >= m.size {
+		return fmt.Errorf("invalid item index %d, must be in range 0 <= i < %d", i, m.size)
+	}
+	return nil
+}
+
+// clamp limits the index to the existing items.
+// Caller must hold m.mu.
+func (m *Model) clamp(i int) int {
+	if i >= m.size {
+		i = m.size - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+// selectedSorted returns the indexes of the selected items in ascending
+// order.
+// Caller must hold m.mu.
+func (m *Model) selectedSorted() []int {
+	var sel []int
+	for i := range m.selected {
+		sel = append(sel, i)
+	}
+	sort.Ints(sel)
+	return sel
+}
+
+// unlockAndNotify releases m.mu and calls the OnChange function if the
+// selection changed.
+// Caller must hold m.mu.
+func (m *Model) unlockAndNotify(changed bool) {
+	fn := m.opts.onChange
+	if !changed || fn == nil {
+		m.mu.Unlock()
+		return
+	}
+	sel := m.selectedSorted()
+	m.mu.Unlock()
+	fn(sel)
+}
+
+// sameSelection determines if the two sets of selected items are equal.
+func sameSelection(a, b map[int]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,276 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selection
+
+import (
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		desc    string
+		size    int
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			desc:    "fails on negative size",
+			size:    -1,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unknown mode",
+			size:    1,
+			opts:    []Option{SelectionMode(Mode(-1))},
+			wantErr: true,
+		},
+		{
+			desc: "succeeds with no items",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := New(tc.size, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestModel(t *testing.T) {
+	tests := []struct {
+		desc string
+		size int
+		opts []Option
+		// update is applied to the model.
+		update       func(*Model) error
+		wantSelected []int
+		wantCursor   int
+		// wantChanges are the selections reported via OnChange.
+		wantChanges [][]int
+		wantErr     bool
+	}{
+		{
+			desc:   "nothing is selected initially",
+			size:   3,
+			update: func(*Model) error { return nil },
+		},
+		{
+			desc: "select replaces the selection in single mode",
+			size: 3,
+			update: func(m *Model) error {
+				if err := m.Select(0); err != nil {
+					return err
+				}
+				return m.Select(2)
+			},
+			wantSelected: []int{2},
+			wantCursor:   2,
+			wantChanges:  [][]int{{0}, {2}},
+		},
+		{
+			desc: "select of the selected item reports no change",
+			size: 3,
+			update: func(m *Model) error {
+				if err := m.Select(1); err != nil {
+					return err
+				}
+				return m.Select(1)
+			},
+			wantSelected: []int{1},
+			wantCursor:   1,
+			wantChanges:  [][]int{{1}},
+		},
+		{
+			desc: "select fails on index out of range",
+			size: 3,
+			update: func(m *Model) error {
+				return m.Select(3)
+			},
+			wantErr: true,
+		},
+		{
+			desc: "toggle in single mode",
+			size: 3,
+			update: func(m *Model) error {
+				if err := m.Toggle(0); err != nil {
+					return err
+				}
+				if err := m.Toggle(1); err != nil {
+					return err
+				}
+				return m.Toggle(1)
+			},
+			wantCursor:  1,
+			wantChanges: [][]int{{0}, {1}, nil},
+		},
+		{
+			desc: "toggle in multiple mode",
+			size: 3,
+			opts: []Option{SelectionMode(ModeMultiple)},
+			update: func(m *Model) error {
+				if err := m.Toggle(2); err != nil {
+					return err
+				}
+				if err := m.Toggle(0); err != nil {
+					return err
+				}
+				return m.Toggle(1)
+			},
+			wantSelected: []int{0, 1, 2},
+			wantCursor:   1,
+			wantChanges:  [][]int{{2}, {0, 2}, {0, 1, 2}},
+		},
+		{
+			desc: "extend selects the range from the anchor",
+			size: 6,
+			opts: []Option{SelectionMode(ModeMultiple)},
+			update: func(m *Model) error {
+				if err := m.Select(3); err != nil {
+					return err
+				}
+				if err := m.ExtendTo(5); err != nil {
+					return err
+				}
+				return m.ExtendTo(1)
+			},
+			wantSelected: []int{1, 2, 3},
+			wantCursor:   1,
+			wantChanges:  [][]int{{3}, {3, 4, 5}, {1, 2, 3}},
+		},
+		{
+			desc: "extend acts as select in single mode",
+			size: 6,
+			update: func(m *Model) error {
+				if err := m.Select(3); err != nil {
+					return err
+				}
+				return m.ExtendTo(5)
+			},
+			wantSelected: []int{5},
+			wantCursor:   5,
+			wantChanges:  [][]int{{3}, {5}},
+		},
+		{
+			desc: "cursor moves without changing the selection",
+			size: 4,
+			update: func(m *Model) error {
+				if err := m.Select(1); err != nil {
+					return err
+				}
+				m.MoveCursor(10)
+				m.MoveCursor(-1)
+				return nil
+			},
+			wantSelected: []int{1},
+			wantCursor:   2,
+			wantChanges:  [][]int{{1}},
+		},
+		{
+			desc: "cursor stops at the first item",
+			size: 4,
+			update: func(m *Model) error {
+				m.SetCursor(-3)
+				return nil
+			},
+		},
+		{
+			desc: "select all and clear in multiple mode",
+			size: 3,
+			opts: []Option{SelectionMode(ModeMultiple)},
+			update: func(m *Model) error {
+				m.SelectAll()
+				m.SelectAll()
+				m.Clear()
+				m.Clear()
+				return nil
+			},
+			wantChanges: [][]int{{0, 1, 2}, nil},
+		},
+		{
+			desc: "select all does nothing in single mode",
+			size: 3,
+			update: func(m *Model) error {
+				m.SelectAll()
+				return nil
+			},
+		},
+		{
+			desc: "shrinking deselects items beyond the size",
+			size: 5,
+			opts: []Option{SelectionMode(ModeMultiple)},
+			update: func(m *Model) error {
+				if err := m.Select(1); err != nil {
+					return err
+				}
+				if err := m.ExtendTo(4); err != nil {
+					return err
+				}
+				return m.SetSize(3)
+			},
+			wantSelected: []int{1, 2},
+			wantCursor:   2,
+			wantChanges:  [][]int{{1}, {1, 2, 3, 4}, {1, 2}},
+		},
+		{
+			desc: "set size fails on negative size",
+			size: 5,
+			update: func(m *Model) error {
+				return m.SetSize(-1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			var gotChanges [][]int
+			opts := append(tc.opts, OnChange(func(selected []int) {
+				gotChanges = append(gotChanges, selected)
+			}))
+			m, err := New(tc.size, opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+
+			err = tc.update(m)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("update => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := pretty.Compare(tc.wantSelected, m.Selected()); diff != "" {
+				t.Errorf("Selected => unexpected diff (-want, +got):\n%s", diff)
+			}
+			for _, i := range tc.wantSelected {
+				if !m.IsSelected(i) {
+					t.Errorf("IsSelected(%d) => false, want true", i)
+				}
+			}
+			if got := m.Cursor(); got != tc.wantCursor {
+				t.Errorf("Cursor => %d, want %d", got, tc.wantCursor)
+			}
+			if diff := pretty.Compare(tc.wantChanges, gotChanges); diff != "" {
+				t.Errorf("OnChange => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
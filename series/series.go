@@ -0,0 +1,207 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package series implements a bounded series of timestamped values for
+// streaming data into the LineChart and the SparkLine widgets.
+//
+// A Series keeps the most recent values in a ring buffer, so long running
+// dashboards use constant memory regardless of how many values are appended.
+// The widgets downsample the series to the width of the canvas when drawing
+// using the Largest-Triangle-Three-Buckets (LTTB) algorithm, which preserves
+// the visual shape of the data, including spikes, much better than taking
+// every n-th value.
+package series
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Point is a value in the series.
+type Point struct {
+	// Time is when the value was observed.
+	Time time.Time
+	// Value is the observed value. Missing values can be represented as
+	// math.NaN.
+	Value float64
+}
+
+// Series is a bounded series of timestamped values.
+//
+// This object is thread-safe.
+type Series struct {
+	// points is the ring buffer of the values.
+	points []Point
+	// start is the index of the oldest value in points and count is the
+	// number of values stored.
+	start, count int
+
+	// revision is incremented each time a value is appended.
+	revision uint64
+
+	// mu protects the Series.
+	mu sync.Mutex
+}
+
+// New returns a new Series that retains up to capacity most recent values.
+func New(capacity int) (*Series, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("invalid capacity %d, must be a positive number", capacity)
+	}
+	return &Series{
+		points: make([]Point, capacity),
+	}, nil
+}
+
+// Append appends the value observed at the provided time. Values are
+// expected to be appended in the chronological order. The oldest value is
+// discarded once the series is full.
+func (s *Series) Append(t time.Time, v float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revision++
+	end := (s.start + s.count) % len(s.points)
+	s.points[end] = Point{Time: t, Value: v}
+	if s.count < len(s.points) {
+		s.count++
+		return
+	}
+	s.start = (s.start + 1) % len(s.points)
+}
+
+// Len returns the number of values in the series.
+func (s *Series) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Capacity returns the maximum number of values the series retains.
+func (s *Series) Capacity() int {
+	return len(s.points)
+}
+
+// Revision returns a number that changes each time a value is appended.
+// Useful to determine if the series changed since it was last read.
+func (s *Series) Revision() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revision
+}
+
+// Points returns all the values in the series from the oldest to the newest.
+func (s *Series) Points() []Point {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ordered()
+}
+
+// ordered returns a copy of the values from the oldest to the newest.
+// Caller must hold s.mu.
+func (s *Series) ordered() []Point {
+	res := make([]Point, s.count)
+	for i := range res {
+		res[i] = s.points[(s.start+i)%len(s.points)]
+	}
+	return res
+}
+
+// Downsample returns at most threshold values that represent the shape of
+// the series, from the oldest to the newest. The first and the last value
+// are always included. Returns all the values if the series doesn't have
+// more than threshold values or if the threshold is less than three.
+func (s *Series) Downsample(threshold int) []Point {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return LTTB(s.ordered(), threshold)
+}
+
+// LTTB downsamples the points to at most threshold points using the
+// Largest-Triangle-Three-Buckets algorithm. The points must be ordered by
+// time. Returns the points unchanged if there aren't more than threshold of
+// them or if the threshold is less than three.
+//
+// The points except the first and the last are split into threshold-2
+// buckets. From each bucket the algorithm selects the point that forms the
+// largest triangle with the point selected from the previous bucket and the
+// average of the next bucket. Missing (NaN) values are only selected if the
+// whole bucket is missing.
+func LTTB(points []Point, threshold int) []Point {
+	n := len(points)
+	if threshold < 3 || n <= threshold {
+		return points
+	}
+
+	origin := points[0].Time
+	x := func(i int) float64 {
+		return float64(points[i].Time.Sub(origin))
+	}
+
+	res := make([]Point, 0, threshold)
+	res = append(res, points[0])
+	every := float64(n-2) / float64(threshold-2)
+	selected := 0
+	for b := 0; b < threshold-2; b++ {
+		// The average of the next bucket, the last point for the last one.
+		avgStart := int(float64(b+1)*every) + 1
+		avgEnd := int(float64(b+2)*every) + 1
+		if avgEnd > n {
+			avgEnd = n
+		}
+		if avgStart >= avgEnd {
+			avgStart = n - 1
+			avgEnd = n
+		}
+		var avgX, avgY float64
+		var valid int
+		for i := avgStart; i < avgEnd; i++ {
+			avgX += x(i)
+			if v := points[i].Value; !math.IsNaN(v) {
+				avgY += v
+				valid++
+			}
+		}
+		avgX /= float64(avgEnd - avgStart)
+		if valid > 0 {
+			avgY /= float64(valid)
+		}
+
+		// The point in this bucket that forms the largest triangle.
+		start := int(float64(b)*every) + 1
+		end := int(float64(b+1)*every) + 1
+		aX, aY := x(selected), points[selected].Value
+		best, bestArea := start, -1.0
+		for i := start; i < end; i++ {
+			area := math.Abs((aX-avgX)*(points[i].Value-aY) - (aX-x(i))*(avgY-aY))
+			if area > bestArea {
+				best, bestArea = i, area
+			}
+		}
+		res = append(res, points[best])
+		selected = best
+	}
+	return append(res, points[n-1])
+}
+
+// Values returns the values of the points.
+func Values(points []Point) []float64 {
+	res := make([]float64, len(points))
+	for i, p := range points {
+		res[i] = p.Value
+	}
+	return res
+}
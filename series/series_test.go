@@ -0,0 +1,211 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package series
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// start is the time of the first value in the tests.
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// at returns the time of the i-th value appended each second.
+func at(i int) time.Time {
+	return start.Add(time.Duration(i) * time.Second)
+}
+
+// points returns points with the values appended each second.
+func points(values ...float64) []Point {
+	var res []Point
+	for i, v := range values {
+		res = append(res, Point{Time: at(i), Value: v})
+	}
+	return res
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		desc     string
+		capacity int
+		wantErr  bool
+	}{
+		{
+			desc:     "fails on zero capacity",
+			capacity: 0,
+			wantErr:  true,
+		},
+		{
+			desc:     "fails on negative capacity",
+			capacity: -1,
+			wantErr:  true,
+		},
+		{
+			desc:     "succeeds on positive capacity",
+			capacity: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := New(tc.capacity)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	tests := []struct {
+		desc         string
+		capacity     int
+		values       []float64
+		want         []Point
+		wantRevision uint64
+	}{
+		{
+			desc:     "empty series",
+			capacity: 3,
+		},
+		{
+			desc:         "series that isn't full",
+			capacity:     3,
+			values:       []float64{1, 2},
+			want:         points(1, 2),
+			wantRevision: 2,
+		},
+		{
+			desc:         "discards the oldest values when full",
+			capacity:     3,
+			values:       []float64{1, 2, 3, 4, 5},
+			want:         points(1, 2, 3, 4, 5)[2:],
+			wantRevision: 5,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := New(tc.capacity)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			for i, v := range tc.values {
+				s.Append(at(i), v)
+			}
+
+			got := s.Points()
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("Points => unexpected diff (-want, +got):\n%s", diff)
+			}
+			if got, want := s.Len(), len(tc.want); got != want {
+				t.Errorf("Len => %d, want %d", got, want)
+			}
+			if got := s.Revision(); got != tc.wantRevision {
+				t.Errorf("Revision => %d, want %d", got, tc.wantRevision)
+			}
+		})
+	}
+}
+
+func TestLTTB(t *testing.T) {
+	tests := []struct {
+		desc      string
+		points    []Point
+		threshold int
+		want      []Point
+	}{
+		{
+			desc:      "no points",
+			threshold: 3,
+		},
+		{
+			desc:      "returns all points when they fit",
+			points:    points(1, 2, 3),
+			threshold: 3,
+			want:      points(1, 2, 3),
+		},
+		{
+			desc:      "returns all points when the threshold is too small",
+			points:    points(1, 2, 3, 4),
+			threshold: 2,
+			want:      points(1, 2, 3, 4),
+		},
+		{
+			desc:      "keeps the spike",
+			points:    points(0, 0, 0, 10, 0, 0, 0),
+			threshold: 3,
+			want: []Point{
+				{Time: at(0), Value: 0},
+				{Time: at(3), Value: 10},
+				{Time: at(6), Value: 0},
+			},
+		},
+		{
+			desc:      "selects one point from each bucket",
+			points:    points(0, 5, 1, 1, -5, 0),
+			threshold: 4,
+			want: []Point{
+				{Time: at(0), Value: 0},
+				{Time: at(1), Value: 5},
+				{Time: at(4), Value: -5},
+				{Time: at(5), Value: 0},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := LTTB(tc.points, tc.threshold)
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("LTTB => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLTTBMissingValues(t *testing.T) {
+	got := LTTB(points(0, math.NaN(), 3, math.NaN(), 0), 3)
+	if len(got) != 3 {
+		t.Fatalf("LTTB => got %d points, want 3", len(got))
+	}
+	if v := got[1].Value; v != 3 {
+		t.Errorf("LTTB => selected value %v, want 3", v)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	s, err := New(100)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		s.Append(at(i), float64(i%10))
+	}
+
+	got := s.Downsample(20)
+	if len(got) != 20 {
+		t.Fatalf("Downsample => got %d points, want 20", len(got))
+	}
+	if first, want := got[0].Time, at(900); !first.Equal(want) {
+		t.Errorf("Downsample => first point at %v, want %v", first, want)
+	}
+	if last, want := got[len(got)-1].Time, at(999); !last.Equal(want) {
+		t.Errorf("Downsample => last point at %v, want %v", last, want)
+	}
+}
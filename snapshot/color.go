@@ -0,0 +1,70 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+// color.go converts the terminal colors to RGB.
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/mum4k/termdash/cell"
+)
+
+// systemColors are the RGB values of the first 16 xterm colors.
+var systemColors = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xff},
+	{0xcd, 0x00, 0x00, 0xff},
+	{0x00, 0xcd, 0x00, 0xff},
+	{0xcd, 0xcd, 0x00, 0xff},
+	{0x00, 0x00, 0xee, 0xff},
+	{0xcd, 0x00, 0xcd, 0xff},
+	{0x00, 0xcd, 0xcd, 0xff},
+	{0xe5, 0xe5, 0xe5, 0xff},
+	{0x7f, 0x7f, 0x7f, 0xff},
+	{0xff, 0x00, 0x00, 0xff},
+	{0x00, 0xff, 0x00, 0xff},
+	{0xff, 0xff, 0x00, 0xff},
+	{0x5c, 0x5c, 0xff, 0xff},
+	{0xff, 0x00, 0xff, 0xff},
+	{0x00, 0xff, 0xff, 0xff},
+	{0xff, 0xff, 0xff, 0xff},
+}
+
+// cubeLevels are the intensities of the 6x6x6 color cube.
+var cubeLevels = [6]uint8{0x00, 0x5f, 0x87, 0xaf, 0xd7, 0xff}
+
+// toRGBA converts the terminal color to RGB using the xterm palette.
+// Returns def for cell.ColorDefault.
+func toRGBA(c cell.Color, def color.RGBA) color.RGBA {
+	n := int(c) - 1 // Colors are off-by-one due to ColorDefault being zero.
+	switch {
+	case n < 0 || n > 255:
+		return def
+	case n < 16:
+		return systemColors[n]
+	case n < 232:
+		n -= 16
+		return color.RGBA{cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6], 0xff}
+	default:
+		g := uint8(8 + 10*(n-232))
+		return color.RGBA{g, g, g, 0xff}
+	}
+}
+
+// hexColor formats the color for HTML.
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
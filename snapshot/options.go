@@ -0,0 +1,99 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+// options.go contains configurable options for exporting the frames.
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	fg    color.RGBA
+	bg    color.RGBA
+	scale int
+}
+
+// newOptions returns options with the default values set and the provided
+// options applied.
+func newOptions(opts ...Option) *options {
+	opt := &options{
+		fg:    DefaultForeground,
+		bg:    DefaultBackground,
+		scale: DefaultScale,
+	}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	return opt
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.scale <= 0 {
+		return fmt.Errorf("invalid Scale %d, must be a positive number", o.scale)
+	}
+	return nil
+}
+
+// The default colors used for cells with cell.ColorDefault.
+var (
+	DefaultForeground = color.RGBA{0xe5, 0xe5, 0xe5, 0xff}
+	DefaultBackground = color.RGBA{0x00, 0x00, 0x00, 0xff}
+)
+
+// Foreground sets the color used for cells with the default foreground
+// color. Defaults to DefaultForeground.
+func Foreground(c color.RGBA) Option {
+	return option(func(opts *options) {
+		opts.fg = c
+	})
+}
+
+// Background sets the color used for cells with the default background
+// color. Defaults to DefaultBackground.
+func Background(c color.RGBA) Option {
+	return option(func(opts *options) {
+		opts.bg = c
+	})
+}
+
+// DefaultScale is the default value for the Scale option.
+const DefaultScale = 2
+
+// Scale sets how many times the cells in the PNG image are enlarged. Each
+// cell is cellWidth x cellHeight pixels at scale one.
+// Defaults to DefaultScale.
+func Scale(s int) Option {
+	return option(func(opts *options) {
+		opts.scale = s
+	})
+}
@@ -0,0 +1,242 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+// png.go draws the frames as images.
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/mum4k/termdash/cell"
+)
+
+// The size of a cell in the image in pixels at scale one.
+const (
+	cellWidth  = 6
+	cellHeight = 10
+)
+
+// boxLines describes which lines from the center of the cell to its edges
+// the box drawing characters have and whether the lines are heavy. Double
+// lines are drawn as heavy lines.
+var boxLines = map[rune]struct {
+	up, down, left, right, heavy bool
+}{
+	'─': {left: true, right: true},
+	'━': {left: true, right: true, heavy: true},
+	'═': {left: true, right: true, heavy: true},
+	'│': {up: true, down: true},
+	'┃': {up: true, down: true, heavy: true},
+	'║': {up: true, down: true, heavy: true},
+	'┌': {down: true, right: true},
+	'╭': {down: true, right: true},
+	'┏': {down: true, right: true, heavy: true},
+	'╔': {down: true, right: true, heavy: true},
+	'┐': {down: true, left: true},
+	'╮': {down: true, left: true},
+	'┓': {down: true, left: true, heavy: true},
+	'╗': {down: true, left: true, heavy: true},
+	'└': {up: true, right: true},
+	'╰': {up: true, right: true},
+	'┗': {up: true, right: true, heavy: true},
+	'╚': {up: true, right: true, heavy: true},
+	'┘': {up: true, left: true},
+	'╯': {up: true, left: true},
+	'┛': {up: true, left: true, heavy: true},
+	'╝': {up: true, left: true, heavy: true},
+	'├': {up: true, down: true, right: true},
+	'┣': {up: true, down: true, right: true, heavy: true},
+	'╠': {up: true, down: true, right: true, heavy: true},
+	'┤': {up: true, down: true, left: true},
+	'┫': {up: true, down: true, left: true, heavy: true},
+	'╣': {up: true, down: true, left: true, heavy: true},
+	'┬': {down: true, left: true, right: true},
+	'┳': {down: true, left: true, right: true, heavy: true},
+	'╦': {down: true, left: true, right: true, heavy: true},
+	'┴': {up: true, left: true, right: true},
+	'┻': {up: true, left: true, right: true, heavy: true},
+	'╩': {up: true, left: true, right: true, heavy: true},
+	'┼': {up: true, down: true, left: true, right: true},
+	'╋': {up: true, down: true, left: true, right: true, heavy: true},
+	'╬': {up: true, down: true, left: true, right: true, heavy: true},
+}
+
+// brailleDots are the positions of the dots of the braille patterns in the
+// order of the bits of the rune, as columns and rows of the 2x4 grid.
+var brailleDots = [8]image.Point{
+	{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {0, 3}, {1, 3},
+}
+
+// Image draws the frame as an image, see Scale for the size of the cells.
+// Text is drawn with an embedded font that supports the printable ASCII
+// characters. The block elements, braille patterns and the box drawing
+// characters used by the widgets are drawn as shapes, any other characters
+// are drawn as empty boxes.
+func (f *Frame) Image(opts ...Option) (*image.RGBA, error) {
+	opt := newOptions(opts...)
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+
+	size := f.Size()
+	img := image.NewRGBA(image.Rect(0, 0, size.X*cellWidth*opt.scale, size.Y*cellHeight*opt.scale))
+	for y := 0; y < size.Y; y++ {
+		f.visit(y, func(x int, r rune, opts *cell.Options) {
+			cd := &cellDrawer{
+				img:   img,
+				min:   image.Point{x * cellWidth, y * cellHeight},
+				scale: opt.scale,
+				fg:    toRGBA(opts.FgColor, opt.fg),
+				bg:    toRGBA(opts.BgColor, opt.bg),
+			}
+			cd.draw(r, f.runeWidth(image.Point{x, y}))
+		})
+	}
+	return img, nil
+}
+
+// PNG writes the frame to the writer as a PNG image, see Image.
+func (f *Frame) PNG(w io.Writer, opts ...Option) error {
+	img, err := f.Image(opts...)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// runeWidth returns the number of cells the rune at the point occupies.
+func (f *Frame) runeWidth(p image.Point) int {
+	rem, err := f.cells.RemWidth(p)
+	if err != nil || rem < 2 {
+		return 1
+	}
+	if partial, err := f.cells.IsPartial(image.Point{p.X + 1, p.Y}); err == nil && partial {
+		return 2
+	}
+	return 1
+}
+
+// cellDrawer draws a single cell of the frame.
+type cellDrawer struct {
+	img *image.RGBA
+	// min is the top left pixel of the cell at scale one.
+	min   image.Point
+	scale int
+	fg    color.RGBA
+	bg    color.RGBA
+}
+
+// fill fills the rectangle specified in pixels relative to the cell at scale
+// one.
+func (cd *cellDrawer) fill(r image.Rectangle, c color.Color) {
+	r = r.Add(cd.min)
+	r = image.Rect(r.Min.X*cd.scale, r.Min.Y*cd.scale, r.Max.X*cd.scale, r.Max.Y*cd.scale)
+	draw.Draw(cd.img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// blend returns the foreground color blended into the background color in
+// the provided ratio out of four.
+func (cd *cellDrawer) blend(quarters int) color.RGBA {
+	mix := func(fg, bg uint8) uint8 {
+		return uint8((int(fg)*quarters + int(bg)*(4-quarters)) / 4)
+	}
+	return color.RGBA{mix(cd.fg.R, cd.bg.R), mix(cd.fg.G, cd.bg.G), mix(cd.fg.B, cd.bg.B), 0xff}
+}
+
+// draw draws the rune that occupies width cells.
+func (cd *cellDrawer) draw(r rune, width int) {
+	cd.fill(image.Rect(0, 0, cellWidth*width, cellHeight), cd.bg)
+
+	switch {
+	case r == ' ':
+
+	case r >= '▁' && r <= '█':
+		// Lower eighths of the cell.
+		h := cellHeight * int(r-'▀') / 8
+		cd.fill(image.Rect(0, cellHeight-h, cellWidth, cellHeight), cd.fg)
+
+	case r >= '▉' && r <= '▏':
+		// Left eighths of the cell.
+		w := cellWidth * int('█'-r+8) / 8
+		cd.fill(image.Rect(0, 0, w, cellHeight), cd.fg)
+
+	case r == '▀':
+		cd.fill(image.Rect(0, 0, cellWidth, cellHeight/2), cd.fg)
+
+	case r == '▐':
+		cd.fill(image.Rect(cellWidth/2, 0, cellWidth, cellHeight), cd.fg)
+
+	case r >= '░' && r <= '▓':
+		cd.fill(image.Rect(0, 0, cellWidth, cellHeight), cd.blend(int(r-'░')+1))
+
+	case r >= '⠀' && r <= '⣿':
+		for bit, dot := range brailleDots {
+			if (r-'⠀')&(1<<uint(bit)) == 0 {
+				continue
+			}
+			x := 1 + dot.X*3
+			y := dot.Y * cellHeight / 4
+			cd.fill(image.Rect(x, y, x+2, y+2), cd.fg)
+		}
+
+	default:
+		if bl, ok := boxLines[r]; ok {
+			cd.drawBox(bl.up, bl.down, bl.left, bl.right, bl.heavy)
+			return
+		}
+		g, ok := glyph(r)
+		if !ok {
+			// Draw an empty box for unsupported characters.
+			box := image.Rect(1, 1, cellWidth*width-1, cellHeight-1)
+			cd.fill(image.Rect(box.Min.X, box.Min.Y, box.Max.X, box.Min.Y+1), cd.fg)
+			cd.fill(image.Rect(box.Min.X, box.Max.Y-1, box.Max.X, box.Max.Y), cd.fg)
+			cd.fill(image.Rect(box.Min.X, box.Min.Y, box.Min.X+1, box.Max.Y), cd.fg)
+			cd.fill(image.Rect(box.Max.X-1, box.Min.Y, box.Max.X, box.Max.Y), cd.fg)
+			return
+		}
+		for x, col := range g {
+			for y := 0; y < glyphHeight; y++ {
+				if col&(1<<uint(y)) != 0 {
+					cd.fill(image.Rect(x, y+1, x+1, y+2), cd.fg)
+				}
+			}
+		}
+	}
+}
+
+// drawBox draws the lines of a box drawing character.
+func (cd *cellDrawer) drawBox(up, down, left, right, heavy bool) {
+	const cx, cy = cellWidth / 2, cellHeight / 2
+	thick := 1
+	if heavy {
+		thick = 2
+	}
+	if up {
+		cd.fill(image.Rect(cx, 0, cx+thick, cy+thick), cd.fg)
+	}
+	if down {
+		cd.fill(image.Rect(cx, cy, cx+thick, cellHeight), cd.fg)
+	}
+	if left {
+		cd.fill(image.Rect(0, cy, cx+thick, cy+thick), cd.fg)
+	}
+	if right {
+		cd.fill(image.Rect(cx, cy, cellWidth, cy+thick), cd.fg)
+	}
+}
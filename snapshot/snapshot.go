@@ -0,0 +1,317 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snapshot captures the frames displayed by the terminal dashboard
+// and exports them as plain text, text with ANSI escape sequences, HTML or a
+// PNG image. Useful to share the state of a dashboard in reports and bug
+// filings.
+//
+// The Recorder wraps the terminal the dashboard runs on and records the
+// cells as they are drawn:
+//
+//	rec := snapshot.NewRecorder(t)
+//	c, err := container.New(rec, ...)
+//	...
+//	err := termdash.Run(ctx, rec, c)
+//
+// Calling rec.Frame() at any time returns the frame last flushed to the
+// terminal.
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"image"
+	"strings"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas/buffer"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Recorder records the cells drawn on the terminal.
+//
+// Implements terminalapi.Terminal. This object is thread-safe.
+type Recorder struct {
+	// term is the wrapped terminal.
+	term terminalapi.Terminal
+
+	// back is the back buffer, i.e. the cells set since the last flush.
+	back buffer.Buffer
+	// front is the last flushed frame, nil if the terminal wasn't flushed.
+	front *Frame
+
+	// mu protects the Recorder.
+	mu sync.Mutex
+}
+
+// NewRecorder returns a new Recorder that forwards all calls to the provided
+// terminal.
+func NewRecorder(t terminalapi.Terminal) *Recorder {
+	return &Recorder{
+		term: t,
+	}
+}
+
+// Frame returns the frame last flushed to the terminal. Returns an empty
+// frame of the size of the terminal if it wasn't flushed yet.
+func (r *Recorder) Frame() (*Frame, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.front != nil {
+		return r.front, nil
+	}
+	b, err := buffer.New(r.term.Size())
+	if err != nil {
+		return nil, err
+	}
+	return newFrame(b), nil
+}
+
+// backBuffer returns the back buffer resized to the current size of the
+// terminal. The recorded cells are lost when the terminal resizes, the
+// dashboard redraws the whole terminal in that case.
+// Caller must hold r.mu.
+func (r *Recorder) backBuffer() (buffer.Buffer, error) {
+	size := r.term.Size()
+	if r.back != nil && r.back.Size() == size {
+		return r.back, nil
+	}
+	b, err := buffer.New(size)
+	if err != nil {
+		return nil, err
+	}
+	r.back = b
+	return b, nil
+}
+
+// Size implements terminalapi.Terminal.Size.
+func (r *Recorder) Size() image.Point {
+	return r.term.Size()
+}
+
+// Clear implements terminalapi.Terminal.Clear.
+func (r *Recorder) Clear(opts ...cell.Option) error {
+	if err := r.term.Clear(opts...); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := buffer.New(r.term.Size())
+	if err != nil {
+		return err
+	}
+	for _, col := range b {
+		for _, c := range col {
+			c.Apply(opts...)
+		}
+	}
+	r.back = b
+	return nil
+}
+
+// Flush implements terminalapi.Terminal.Flush.
+func (r *Recorder) Flush() error {
+	if err := r.term.Flush(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := r.backBuffer()
+	if err != nil {
+		return err
+	}
+	r.front = newFrame(b)
+	return nil
+}
+
+// SetCursor implements terminalapi.Terminal.SetCursor.
+func (r *Recorder) SetCursor(p image.Point) {
+	r.term.SetCursor(p)
+}
+
+// HideCursor implements terminalapi.Terminal.HideCursor.
+func (r *Recorder) HideCursor() {
+	r.term.HideCursor()
+}
+
+// SetCell implements terminalapi.Terminal.SetCell.
+func (r *Recorder) SetCell(p image.Point, rn rune, opts ...cell.Option) error {
+	if err := r.term.SetCell(p, rn, opts...); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := r.backBuffer()
+	if err != nil {
+		return err
+	}
+	// The terminal accepted the cell, so failing to record it would only
+	// make the recorded frame less accurate.
+	b.SetCell(p, rn, opts...)
+	return nil
+}
+
+// Event implements terminalapi.Terminal.Event.
+func (r *Recorder) Event(ctx context.Context) terminalapi.Event {
+	return r.term.Event(ctx)
+}
+
+// Capabilities implements terminalapi.Terminal.Capabilities.
+func (r *Recorder) Capabilities() terminalapi.Capabilities {
+	return r.term.Capabilities()
+}
+
+// Close implements terminalapi.Terminal.Close.
+func (r *Recorder) Close() {
+	r.term.Close()
+}
+
+// Frame is a captured frame of the terminal.
+type Frame struct {
+	// cells are the captured cells.
+	cells buffer.Buffer
+}
+
+// newFrame returns a new frame with a copy of the cells in the buffer.
+func newFrame(b buffer.Buffer) *Frame {
+	cells := make(buffer.Buffer, len(b))
+	for x, col := range b {
+		cells[x] = make([]*buffer.Cell, len(col))
+		for y, c := range col {
+			cells[x][y] = c.Copy()
+		}
+	}
+	return &Frame{
+		cells: cells,
+	}
+}
+
+// Size returns the size of the frame in cells.
+func (f *Frame) Size() image.Point {
+	return f.cells.Size()
+}
+
+// visit calls the function for each cell on the row that isn't a part of a
+// wide rune in the previous cell. Cells without a rune have the space rune.
+func (f *Frame) visit(row int, fn func(x int, r rune, opts *cell.Options)) {
+	for x := 0; x < len(f.cells); x++ {
+		p := image.Point{x, row}
+		if partial, err := f.cells.IsPartial(p); err == nil && partial {
+			continue
+		}
+		c := f.cells[x][row]
+		r := c.Rune
+		if r == 0 {
+			r = ' '
+		}
+		fn(x, r, c.Opts)
+	}
+}
+
+// Text returns the runes in the frame as plain text with a line per row.
+// The trailing spaces of each line are removed.
+func (f *Frame) Text() string {
+	var b strings.Builder
+	for y := 0; y < f.Size().Y; y++ {
+		var line strings.Builder
+		f.visit(y, func(_ int, r rune, _ *cell.Options) {
+			line.WriteRune(r)
+		})
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteRune('\n')
+	}
+	return b.String()
+}
+
+// sgrColor returns the parameters of the SGR escape sequence that sets the
+// color. The base is 30 for the foreground and 40 for the background color.
+func sgrColor(c cell.Color, base int) string {
+	if c == cell.ColorDefault {
+		return fmt.Sprintf("%d", base+9)
+	}
+	return fmt.Sprintf("%d;5;%d", base+8, int(c)-1)
+}
+
+// ANSI returns the frame as text with ANSI escape sequences that set the
+// colors of the cells, suitable for printing on a terminal that supports 256
+// colors. Each line ends by resetting the colors.
+func (f *Frame) ANSI() string {
+	var b strings.Builder
+	for y := 0; y < f.Size().Y; y++ {
+		var cur *cell.Options
+		f.visit(y, func(_ int, r rune, opts *cell.Options) {
+			if cur == nil || *cur != *opts {
+				fmt.Fprintf(&b, "\x1b[%s;%sm", sgrColor(opts.FgColor, 30), sgrColor(opts.BgColor, 40))
+				cur = opts
+			}
+			b.WriteRune(r)
+		})
+		b.WriteString("\x1b[0m\n")
+	}
+	return b.String()
+}
+
+// HTML returns the frame as a HTML pre element with the colors of the cells
+// set via inline styles. Cells with the default colors use the colors
+// provided via the Foreground and Background options.
+func (f *Frame) HTML(opts ...Option) string {
+	opt := newOptions(opts...)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<pre style="color:%s;background-color:%s;font-family:monospace">`,
+		hexColor(opt.fg), hexColor(opt.bg))
+	b.WriteRune('\n')
+	for y := 0; y < f.Size().Y; y++ {
+		var (
+			cur  *cell.Options
+			text strings.Builder
+		)
+		flush := func() {
+			if text.Len() == 0 {
+				return
+			}
+			var style []string
+			if cur.FgColor != cell.ColorDefault {
+				style = append(style, "color:"+hexColor(toRGBA(cur.FgColor, opt.fg)))
+			}
+			if cur.BgColor != cell.ColorDefault {
+				style = append(style, "background-color:"+hexColor(toRGBA(cur.BgColor, opt.bg)))
+			}
+			if len(style) == 0 {
+				b.WriteString(html.EscapeString(text.String()))
+			} else {
+				fmt.Fprintf(&b, `<span style="%s">%s</span>`, strings.Join(style, ";"), html.EscapeString(text.String()))
+			}
+			text.Reset()
+		}
+		f.visit(y, func(_ int, r rune, opts *cell.Options) {
+			if cur != nil && *cur != *opts {
+				flush()
+			}
+			cur = opts
+			text.WriteRune(r)
+		})
+		flush()
+		b.WriteRune('\n')
+	}
+	b.WriteString("</pre>\n")
+	return b.String()
+}
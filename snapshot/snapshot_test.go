@@ -0,0 +1,222 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/faketerm"
+)
+
+// mustFrame returns the frame recorded after the cells were set on a
+// terminal of the provided size and flushed.
+func mustFrame(t *testing.T, size image.Point, set func(*Recorder) error) *Frame {
+	t.Helper()
+	rec := NewRecorder(faketerm.MustNew(size))
+	if err := set(rec); err != nil {
+		t.Fatalf("set => unexpected error: %v", err)
+	}
+	if err := rec.Flush(); err != nil {
+		t.Fatalf("Flush => unexpected error: %v", err)
+	}
+	f, err := rec.Frame()
+	if err != nil {
+		t.Fatalf("Frame => unexpected error: %v", err)
+	}
+	return f
+}
+
+// setText sets the text starting at the point.
+func setText(rec *Recorder, text string, start image.Point, opts ...cell.Option) error {
+	p := start
+	for _, r := range text {
+		if err := rec.SetCell(p, r, opts...); err != nil {
+			return err
+		}
+		p.X++
+	}
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{3, 2})
+	rec := NewRecorder(ft)
+
+	f, err := rec.Frame()
+	if err != nil {
+		t.Fatalf("Frame => unexpected error: %v", err)
+	}
+	if got, want := f.Text(), "\n\n"; got != want {
+		t.Errorf("Frame before Flush => %q, want %q", got, want)
+	}
+
+	if err := setText(rec, "abc", image.Point{0, 0}); err != nil {
+		t.Fatalf("SetCell => unexpected error: %v", err)
+	}
+	if err := rec.Flush(); err != nil {
+		t.Fatalf("Flush => unexpected error: %v", err)
+	}
+	if err := setText(rec, "de", image.Point{0, 1}); err != nil {
+		t.Fatalf("SetCell => unexpected error: %v", err)
+	}
+
+	f, err = rec.Frame()
+	if err != nil {
+		t.Fatalf("Frame => unexpected error: %v", err)
+	}
+	// Cells set after the last flush aren't part of the frame.
+	if got, want := f.Text(), "abc\n\n"; got != want {
+		t.Errorf("Frame => %q, want %q", got, want)
+	}
+	// The cells are forwarded to the terminal.
+	if got, want := ft.String(), "abc\nde \n"; got != want {
+		t.Errorf("terminal => %q, want %q", got, want)
+	}
+
+	if err := rec.Clear(); err != nil {
+		t.Fatalf("Clear => unexpected error: %v", err)
+	}
+	if err := rec.Flush(); err != nil {
+		t.Fatalf("Flush => unexpected error: %v", err)
+	}
+	f, err = rec.Frame()
+	if err != nil {
+		t.Fatalf("Frame => unexpected error: %v", err)
+	}
+	if got, want := f.Text(), "\n\n"; got != want {
+		t.Errorf("Frame after Clear => %q, want %q", got, want)
+	}
+}
+
+func TestText(t *testing.T) {
+	f := mustFrame(t, image.Point{6, 2}, func(rec *Recorder) error {
+		if err := setText(rec, "ab", image.Point{1, 0}); err != nil {
+			return err
+		}
+		if err := rec.SetCell(image.Point{0, 1}, '世'); err != nil {
+			return err
+		}
+		return rec.SetCell(image.Point{2, 1}, 'x')
+	})
+	if got, want := f.Text(), " ab\n世x\n"; got != want {
+		t.Errorf("Text => %q, want %q", got, want)
+	}
+}
+
+func TestANSI(t *testing.T) {
+	f := mustFrame(t, image.Point{3, 1}, func(rec *Recorder) error {
+		return setText(rec, "ab", image.Point{0, 0}, cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorNumber(100)))
+	})
+	want := "\x1b[38;5;1;48;5;100mab\x1b[39;49m \x1b[0m\n"
+	if got := f.ANSI(); got != want {
+		t.Errorf("ANSI => %q, want %q", got, want)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	f := mustFrame(t, image.Point{4, 1}, func(rec *Recorder) error {
+		if err := setText(rec, "<", image.Point{0, 0}); err != nil {
+			return err
+		}
+		return setText(rec, "ok", image.Point{1, 0}, cell.FgColor(cell.ColorGreen))
+	})
+	want := `<pre style="color:#ffffff;background-color:#000000;font-family:monospace">
+&lt;<span style="color:#00cd00">ok</span> 
+</pre>
+`
+	if got := f.HTML(Foreground(color.RGBA{0xff, 0xff, 0xff, 0xff})); got != want {
+		t.Errorf("HTML => %q, want %q", got, want)
+	}
+}
+
+func TestToRGBA(t *testing.T) {
+	def := color.RGBA{1, 2, 3, 0xff}
+	tests := []struct {
+		c    cell.Color
+		want color.RGBA
+	}{
+		{cell.ColorDefault, def},
+		{cell.ColorRed, color.RGBA{0xcd, 0x00, 0x00, 0xff}},
+		{cell.ColorNumber(15), color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{cell.ColorRGB6(5, 0, 1), color.RGBA{0xff, 0x00, 0x5f, 0xff}},
+		{cell.ColorNumber(232), color.RGBA{0x08, 0x08, 0x08, 0xff}},
+		{cell.ColorNumber(255), color.RGBA{0xee, 0xee, 0xee, 0xff}},
+	}
+	for _, tc := range tests {
+		if got := toRGBA(tc.c, def); got != tc.want {
+			t.Errorf("toRGBA(%v) => %v, want %v", tc.c, got, tc.want)
+		}
+	}
+}
+
+func TestImage(t *testing.T) {
+	fg := color.RGBA{0xcd, 0x00, 0x00, 0xff}
+	bg := DefaultBackground
+	f := mustFrame(t, image.Point{4, 1}, func(rec *Recorder) error {
+		return setText(rec, "█I⠁?", image.Point{0, 0}, cell.FgColor(cell.ColorRed))
+	})
+
+	if _, err := f.Image(Scale(0)); err == nil {
+		t.Errorf("Image => got nil error, want one for an invalid scale")
+	}
+
+	img, err := f.Image(Scale(1))
+	if err != nil {
+		t.Fatalf("Image => unexpected error: %v", err)
+	}
+	if got, want := img.Bounds().Size(), (image.Point{4 * cellWidth, cellHeight}); got != want {
+		t.Fatalf("Image => size %v, want %v", got, want)
+	}
+
+	pixels := []struct {
+		desc string
+		p    image.Point
+		want color.RGBA
+	}{
+		{"full block is filled", image.Point{0, 0}, fg},
+		{"full block is filled", image.Point{5, 9}, fg},
+		{"middle of the letter I", image.Point{cellWidth + 2, 4}, fg},
+		{"beside the letter I", image.Point{cellWidth, 4}, bg},
+		{"first braille dot", image.Point{2*cellWidth + 1, 0}, fg},
+		{"missing braille dot", image.Point{2*cellWidth + 4, 0}, bg},
+	}
+	var got []color.RGBA
+	var want []color.RGBA
+	for _, px := range pixels {
+		got = append(got, img.RGBAAt(px.p.X, px.p.Y))
+		want = append(want, px.want)
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("Image => unexpected pixels (-want, +got):\n%s", diff)
+	}
+
+	var buf bytes.Buffer
+	if err := f.PNG(&buf); err != nil {
+		t.Fatalf("PNG => unexpected error: %v", err)
+	}
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode => unexpected error: %v", err)
+	}
+	if got, want := decoded.Bounds().Size(), (image.Point{4 * cellWidth * DefaultScale, cellHeight * DefaultScale}); got != want {
+		t.Errorf("PNG => size %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tasks
+
+// panel.go contains the widget that displays the tasks.
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// PanelOption is used to provide options to Panel().
+type PanelOption interface {
+	// set sets the provided option.
+	set(*panelOptions)
+}
+
+// panelOptions stores the provided options.
+type panelOptions struct {
+	barWidth      int
+	spinner       []rune
+	barCellOpts   []cell.Option
+	emptyCellOpts []cell.Option
+	doneCellOpts  []cell.Option
+	errCellOpts   []cell.Option
+}
+
+// validate validates the provided options.
+func (o *panelOptions) validate() error {
+	if min := 1; o.barWidth < min {
+		return fmt.Errorf("invalid BarWidth(%d), must be at least %d", o.barWidth, min)
+	}
+	if len(o.spinner) == 0 {
+		return errors.New("the Spinner must have at least one frame")
+	}
+	for _, r := range o.spinner {
+		if w := runewidth.RuneWidth(r); w != 1 {
+			return fmt.Errorf("invalid Spinner frame %q, must have width of one cell, got %d", r, w)
+		}
+	}
+	return nil
+}
+
+// panelOption implements PanelOption.
+type panelOption func(*panelOptions)
+
+// set implements PanelOption.set.
+func (o panelOption) set(opts *panelOptions) {
+	o(opts)
+}
+
+// DefaultBarWidth is the default value for the BarWidth option.
+const DefaultBarWidth = 20
+
+// BarWidth sets the width of the progress bars in cells.
+// Defaults to DefaultBarWidth.
+func BarWidth(cells int) PanelOption {
+	return panelOption(func(opts *panelOptions) {
+		opts.barWidth = cells
+	})
+}
+
+// DefaultSpinner are the default frames of the spinner.
+var DefaultSpinner = []rune{'|', '/', '-', '\\'}
+
+// Spinner sets the frames of the spinner displayed instead of the progress
+// bar of tasks whose total amount of work isn't known. The spinner advances
+// to the next frame each time the widget is ticked, see
+// termdash.TickInterval.
+// Defaults to DefaultSpinner.
+func Spinner(frames ...rune) PanelOption {
+	return panelOption(func(opts *panelOptions) {
+		opts.spinner = frames
+	})
+}
+
+// BarCellOpts sets the cell options of the part of the progress bars that
+// represents the work done.
+// Defaults to green background.
+func BarCellOpts(opts ...cell.Option) PanelOption {
+	return panelOption(func(o *panelOptions) {
+		o.barCellOpts = opts
+	})
+}
+
+// EmptyCellOpts sets the cell options of the part of the progress bars that
+// represents the remaining work.
+// Defaults to gray background.
+func EmptyCellOpts(opts ...cell.Option) PanelOption {
+	return panelOption(func(o *panelOptions) {
+		o.emptyCellOpts = opts
+	})
+}
+
+// DoneCellOpts sets the cell options of the status of the tasks that
+// finished successfully.
+// Defaults to green text.
+func DoneCellOpts(opts ...cell.Option) PanelOption {
+	return panelOption(func(o *panelOptions) {
+		o.doneCellOpts = opts
+	})
+}
+
+// ErrorCellOpts sets the cell options of the errors of the failed tasks.
+// Defaults to red text.
+func ErrorCellOpts(opts ...cell.Option) PanelOption {
+	return panelOption(func(o *panelOptions) {
+		o.errCellOpts = opts
+	})
+}
+
+// Panel lists the tasks of a manager, one per row. Each row displays the
+// name of the task, its progress bar and percentage and its status. A
+// spinner replaces the progress bar of tasks whose total amount of work
+// isn't known and the error replaces the progress bar of the failed tasks.
+// If the tasks don't fit, the last row displays how many tasks are hidden.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Panel struct {
+	// m is the manager whose tasks are displayed.
+	m *Manager
+
+	// frame is the index of the displayed frame of the spinner.
+	frame int
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the Panel.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *panelOptions
+}
+
+// Panel returns a new widget that displays the tasks of the manager.
+func (m *Manager) Panel(opts ...PanelOption) (*Panel, error) {
+	opt := &panelOptions{
+		barWidth: DefaultBarWidth,
+		spinner:  DefaultSpinner,
+		barCellOpts: []cell.Option{
+			cell.BgColor(cell.ColorGreen),
+		},
+		emptyCellOpts: []cell.Option{
+			cell.BgColor(cell.ColorNumber(240)),
+		},
+		doneCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorGreen),
+		},
+		errCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorRed),
+		},
+	}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	p := &Panel{
+		m:    m,
+		opts: opt,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.panels = append(m.panels, p)
+	return p, nil
+}
+
+// changed notifies the infrastructure that the tasks changed.
+func (p *Panel) changed() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.notify != nil {
+		p.notify()
+	}
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (p *Panel) SetNotifyFunc(fn func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notify = fn
+}
+
+// Tick advances the spinners if any task displays one.
+// Implements widgetapi.Ticker.Tick.
+func (p *Panel) Tick(now time.Time) {
+	spinning := false
+	for _, i := range p.m.Tasks() {
+		if i.Indeterminate() {
+			spinning = true
+		}
+	}
+	if !spinning {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.frame = (p.frame + 1) % len(p.opts.spinner)
+	if p.notify != nil {
+		p.notify()
+	}
+}
+
+// Draw draws the Panel widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (p *Panel) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	infos := p.m.Tasks()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ar := cvs.Area()
+	shown := infos
+	if len(infos) > ar.Dy() {
+		// The last row displays the number of hidden tasks.
+		shown = infos[:ar.Dy()-1]
+	}
+
+	// The names are aligned into a column that takes at most a third of the
+	// width.
+	nameWidth := 0
+	for _, i := range shown {
+		if w := runewidth.StringWidth(i.Name); w > nameWidth {
+			nameWidth = w
+		}
+	}
+	if max := ar.Dx() / 3; nameWidth > max {
+		nameWidth = max
+	}
+
+	for y, i := range shown {
+		if err := p.drawTask(cvs, i, y, nameWidth); err != nil {
+			return err
+		}
+	}
+	if hidden := len(infos) - len(shown); hidden > 0 {
+		text := fmt.Sprintf("... and %d more", hidden)
+		if err := draw.Text(cvs, text, image.Point{0, ar.Dy() - 1},
+			draw.TextMaxX(ar.Dx()),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drawTask draws the task on the row.
+// The caller must hold p.mu.
+func (p *Panel) drawTask(cvs *canvas.Canvas, i Info, y, nameWidth int) error {
+	width := cvs.Area().Dx()
+	text := func(s string, x int, opts ...cell.Option) error {
+		if x >= width || s == "" {
+			return nil
+		}
+		return draw.Text(cvs, s, image.Point{x, y},
+			draw.TextMaxX(width),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(opts...),
+		)
+	}
+
+	if nameWidth > 0 {
+		if err := draw.Text(cvs, i.Name, image.Point{0, y},
+			draw.TextMaxX(nameWidth),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		); err != nil {
+			return err
+		}
+	}
+	// One cell gap between the columns.
+	x := nameWidth + 1
+
+	switch {
+	case i.State == StateFailed:
+		return text(i.Err.Error(), x, p.opts.errCellOpts...)
+
+	case i.Indeterminate():
+		if err := text(string(p.opts.spinner[p.frame]), x); err != nil {
+			return err
+		}
+		return text(i.Status, x+2)
+	}
+
+	filled := i.Percent() * p.opts.barWidth / 100
+	for bx := 0; bx < p.opts.barWidth && x+bx < width; bx++ {
+		opts := p.opts.emptyCellOpts
+		if bx < filled {
+			opts = p.opts.barCellOpts
+		}
+		if _, err := cvs.SetCell(image.Point{x + bx, y}, ' ', opts...); err != nil {
+			return err
+		}
+	}
+	x += p.opts.barWidth + 1
+	if err := text(fmt.Sprintf("%3d%%", i.Percent()), x); err != nil {
+		return err
+	}
+	x += 5
+
+	status := i.Status
+	var opts []cell.Option
+	if i.State == StateDone {
+		opts = p.opts.doneCellOpts
+		if status == "" {
+			status = "done"
+		}
+	}
+	return text(status, x, opts...)
+}
+
+// Keyboard input isn't supported on the Panel widget.
+func (*Panel) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the Panel widget doesn't support keyboard events")
+}
+
+// Mouse input isn't supported on the Panel widget.
+func (*Panel) Mouse(m *terminalapi.Mouse) error {
+	return errors.New("the Panel widget doesn't support mouse events")
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*Panel) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeNone,
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tasks
+
+import (
+	"errors"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// The default cell options of the panel.
+var (
+	barOpts   = []cell.Option{cell.BgColor(cell.ColorGreen)}
+	emptyOpts = []cell.Option{cell.BgColor(cell.ColorNumber(240))}
+	doneOpts  = []cell.Option{cell.FgColor(cell.ColorGreen)}
+	errOpts   = []cell.Option{cell.FgColor(cell.ColorRed)}
+)
+
+// mustText draws the text on the row starting at the column.
+func mustText(cvs *canvas.Canvas, text string, x, y int, opts ...cell.Option) {
+	testdraw.MustText(cvs, text, image.Point{x, y},
+		draw.TextMaxX(cvs.Area().Dx()),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		draw.TextCellOpts(opts...),
+	)
+}
+
+// mustBar draws a progress bar of the width with the filled cells starting
+// at the column.
+func mustBar(cvs *canvas.Canvas, x, y, width, filled int) {
+	for bx := 0; bx < width; bx++ {
+		opts := emptyOpts
+		if bx < filled {
+			opts = barOpts
+		}
+		testcanvas.MustSetCell(cvs, image.Point{x + bx, y}, ' ', opts...)
+	}
+}
+
+func TestPanel(t *testing.T) {
+	tests := []struct {
+		desc       string
+		opts       []PanelOption
+		update     func(*Manager) error
+		ticks      int
+		size       image.Point
+		want       func(size image.Point) *faketerm.Terminal
+		wantNewErr bool
+	}{
+		{
+			desc:       "fails on zero BarWidth",
+			opts:       []PanelOption{BarWidth(0)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on empty Spinner",
+			opts:       []PanelOption{Spinner()},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on a wide Spinner frame",
+			opts:       []PanelOption{Spinner('世')},
+			wantNewErr: true,
+		},
+		{
+			desc: "no tasks",
+			size: image.Point{30, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+		{
+			desc: "tasks in all the states",
+			opts: []PanelOption{BarWidth(4)},
+			update: func(m *Manager) error {
+				a, err := m.Add("copy")
+				if err != nil {
+					return err
+				}
+				a.Progress(1, 2)
+				a.Status("a.txt")
+
+				b, err := m.Add("scan")
+				if err != nil {
+					return err
+				}
+				b.Status("dirs")
+
+				c, err := m.Add("gc")
+				if err != nil {
+					return err
+				}
+				c.Done()
+
+				d, err := m.Add("push")
+				if err != nil {
+					return err
+				}
+				d.Fail(errors.New("denied"))
+				return nil
+			},
+			size: image.Point{30, 4},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustText(cvs, "copy", 0, 0)
+				mustBar(cvs, 5, 0, 4, 2)
+				mustText(cvs, " 50%", 10, 0)
+				mustText(cvs, "a.txt", 15, 0)
+
+				mustText(cvs, "scan", 0, 1)
+				mustText(cvs, "|", 5, 1)
+				mustText(cvs, "dirs", 7, 1)
+
+				mustText(cvs, "gc", 0, 2)
+				mustBar(cvs, 5, 2, 4, 4)
+				mustText(cvs, "100%", 10, 2)
+				mustText(cvs, "done", 15, 2, doneOpts...)
+
+				mustText(cvs, "push", 0, 3)
+				mustText(cvs, "denied", 5, 3, errOpts...)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "ticks advance the spinner",
+			opts: []PanelOption{Spinner('a', 'b', 'c')},
+			update: func(m *Manager) error {
+				_, err := m.Add("scan")
+				return err
+			},
+			ticks: 4,
+			size:  image.Point{12, 1},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustText(cvs, "scan", 0, 0)
+				mustText(cvs, "b", 5, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "long names are trimmed to a third of the width",
+			opts: []PanelOption{BarWidth(2)},
+			update: func(m *Manager) error {
+				_, err := m.Add("downloading")
+				return err
+			},
+			size: image.Point{15, 1},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "downloading", image.Point{0, 0},
+					draw.TextMaxX(5),
+					draw.TextOverrunMode(draw.OverrunModeThreeDot),
+				)
+				mustText(cvs, "|", 6, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "displays the number of tasks that don't fit",
+			opts: []PanelOption{BarWidth(2)},
+			update: func(m *Manager) error {
+				for _, n := range []string{"a", "b", "c", "d"} {
+					t, err := m.Add(n)
+					if err != nil {
+						return err
+					}
+					t.Fail(errors.New("x"))
+				}
+				return nil
+			},
+			size: image.Point{20, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustText(cvs, "a", 0, 0)
+				mustText(cvs, "x", 2, 0, errOpts...)
+				mustText(cvs, "... and 3 more", 0, 1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			m, err := New()
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			p, err := m.Panel(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("Panel => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if tc.update != nil {
+				if err := tc.update(m); err != nil {
+					t.Fatalf("update => unexpected error: %v", err)
+				}
+			}
+			for i := 0; i < tc.ticks; i++ {
+				p.Tick(time.Now())
+			}
+
+			c, err := canvas.New(image.Rectangle{Max: tc.size})
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := p.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestPanelNotifies(t *testing.T) {
+	m, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	p, err := m.Panel()
+	if err != nil {
+		t.Fatalf("Panel => unexpected error: %v", err)
+	}
+	notified := 0
+	p.SetNotifyFunc(func() {
+		notified++
+	})
+
+	a, err := m.Add("a")
+	if err != nil {
+		t.Fatalf("Add => unexpected error: %v", err)
+	}
+	a.Progress(1, 2)
+	// Ticks only notify while a spinner is displayed.
+	p.Tick(time.Now())
+	a.Done()
+	a.Status("ignored")
+	a.Remove()
+
+	if want := 4; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
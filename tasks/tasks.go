@@ -0,0 +1,336 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tasks tracks the progress of long running tasks and displays it.
+//
+// Goroutines register named tasks with a Manager and report their progress,
+// status and outcome through the returned Task. The Panel widget lists the
+// tasks of a Manager with progress bars, spinners for tasks whose total
+// amount of work isn't known and the errors of the failed tasks.
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// State is the state of a task.
+type State int
+
+// String implements fmt.Stringer()
+func (s State) String() string {
+	if n, ok := stateNames[s]; ok {
+		return n
+	}
+	return "StateUnknown"
+}
+
+// stateNames maps State values to human readable names.
+var stateNames = map[State]string{
+	StateRunning: "StateRunning",
+	StateDone:    "StateDone",
+	StateFailed:  "StateFailed",
+}
+
+const (
+	// StateRunning is a task that didn't finish yet.
+	StateRunning State = iota
+
+	// StateDone is a task that finished successfully.
+	StateDone
+
+	// StateFailed is a task that finished with an error.
+	StateFailed
+)
+
+// Info is the state of a task at a point in time.
+type Info struct {
+	// Name is the name the task was added with.
+	Name string
+	// State is the state of the task.
+	State State
+	// Done and Total are the amounts of work done so far and in total. The
+	// Total is zero if the total amount of work isn't known.
+	Done, Total int
+	// Status is the last status reported by the task.
+	Status string
+	// Err is the error the task failed with.
+	Err error
+}
+
+// Indeterminate asserts whether the task is running and the total amount of
+// work isn't known.
+func (i Info) Indeterminate() bool {
+	return i.State == StateRunning && i.Total <= 0
+}
+
+// Percent returns the percentage of the work done, or zero if the total
+// amount of work isn't known.
+func (i Info) Percent() int {
+	if i.State == StateDone {
+		return 100
+	}
+	if i.Total <= 0 {
+		return 0
+	}
+	return i.Done * 100 / i.Total
+}
+
+// Option is used to provide options to New().
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// options stores the provided options.
+type options struct {
+	maxFinished   int
+	limitFinished bool
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.limitFinished && o.maxFinished < 0 {
+		return fmt.Errorf("invalid MaxFinished(%d), must be zero or a positive integer", o.maxFinished)
+	}
+	return nil
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// MaxFinished sets the maximum number of finished tasks the manager keeps,
+// the tasks that finished first are removed first.
+// Defaults to keeping all the finished tasks until they are removed.
+func MaxFinished(n int) Option {
+	return option(func(opts *options) {
+		opts.maxFinished = n
+		opts.limitFinished = true
+	})
+}
+
+// Manager keeps the tasks and notifies the panels that display them about
+// changes.
+//
+// This object is thread-safe.
+type Manager struct {
+	// tasks are the tasks in the order they were added.
+	tasks []*Task
+	// finished are the finished tasks in the order they finished.
+	finished []*Task
+
+	// panels are the created Panel widgets.
+	panels []*Panel
+
+	// mu protects the Manager and the state of its tasks.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new Manager without any tasks.
+func New(opts ...Option) (*Manager, error) {
+	opt := &options{}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		opts: opt,
+	}, nil
+}
+
+// Add adds a running task with the name. The name must be unique among the
+// tasks of the manager, a removed task's name can be used again.
+func (m *Manager) Add(name string) (*Task, error) {
+	if name == "" {
+		return nil, errors.New("the task name cannot be an empty string")
+	}
+
+	m.mu.Lock()
+	for _, t := range m.tasks {
+		if t.info.Name == name {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("a task named %q already exists", name)
+		}
+	}
+	t := &Task{
+		m:    m,
+		info: Info{Name: name},
+	}
+	m.tasks = append(m.tasks, t)
+	m.mu.Unlock()
+
+	m.changed()
+	return t, nil
+}
+
+// Tasks returns the state of the tasks in the order they were added.
+func (m *Manager) Tasks() []Info {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var infos []Info
+	for _, t := range m.tasks {
+		infos = append(infos, t.info)
+	}
+	return infos
+}
+
+// update updates the task with the function unless it already finished and
+// notifies the panels.
+func (m *Manager) update(t *Task, fn func(*Info)) {
+	m.mu.Lock()
+	if t.removed || t.info.State != StateRunning {
+		m.mu.Unlock()
+		return
+	}
+	fn(&t.info)
+	if t.info.State != StateRunning {
+		m.finish(t)
+	}
+	m.mu.Unlock()
+
+	m.changed()
+}
+
+// finish records that the task finished and removes the finished tasks over
+// the MaxFinished limit.
+// The caller must hold m.mu.
+func (m *Manager) finish(t *Task) {
+	m.finished = append(m.finished, t)
+	if !m.opts.limitFinished {
+		return
+	}
+	for len(m.finished) > m.opts.maxFinished {
+		m.remove(m.finished[0])
+	}
+}
+
+// remove removes the task.
+// The caller must hold m.mu.
+func (m *Manager) remove(t *Task) {
+	t.removed = true
+	for i, other := range m.tasks {
+		if other == t {
+			m.tasks = append(m.tasks[:i], m.tasks[i+1:]...)
+			break
+		}
+	}
+	for i, other := range m.finished {
+		if other == t {
+			m.finished = append(m.finished[:i], m.finished[i+1:]...)
+			break
+		}
+	}
+}
+
+// changed notifies the panels that the tasks changed.
+func (m *Manager) changed() {
+	m.mu.Lock()
+	panels := append([]*Panel(nil), m.panels...)
+	m.mu.Unlock()
+	for _, p := range panels {
+		p.changed()
+	}
+}
+
+// Task reports the progress of a task to its manager. Updates of a task
+// that finished or was removed are ignored.
+//
+// This object is thread-safe.
+type Task struct {
+	// m is the manager the task belongs to.
+	m *Manager
+
+	// info is the state of the task.
+	info Info
+	// removed indicates that the task was removed from the manager.
+	removed bool
+}
+
+// Info returns the state of the task.
+func (t *Task) Info() Info {
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+	return t.info
+}
+
+// Progress sets the amounts of work done so far and in total. A total of
+// zero indicates that the total amount of work isn't known. The done amount
+// is clamped to the range from zero to the total.
+func (t *Task) Progress(done, total int) {
+	if total < 0 {
+		total = 0
+	}
+	if done < 0 {
+		done = 0
+	} else if total > 0 && done > total {
+		done = total
+	}
+	t.m.update(t, func(i *Info) {
+		i.Done = done
+		i.Total = total
+	})
+}
+
+// Status sets a short description of what the task is doing, e.g. the name
+// of the file being downloaded.
+func (t *Task) Status(text string) {
+	t.m.update(t, func(i *Info) {
+		i.Status = text
+	})
+}
+
+// Done marks the task as finished successfully.
+func (t *Task) Done() {
+	t.m.update(t, func(i *Info) {
+		i.State = StateDone
+		if i.Total > 0 {
+			i.Done = i.Total
+		}
+	})
+}
+
+// Fail marks the task as finished with the error.
+func (t *Task) Fail(err error) {
+	if err == nil {
+		err = errors.New("unknown error")
+	}
+	t.m.update(t, func(i *Info) {
+		i.State = StateFailed
+		i.Err = err
+	})
+}
+
+// Remove removes the task from the manager.
+func (t *Task) Remove() {
+	t.m.mu.Lock()
+	if t.removed {
+		t.m.mu.Unlock()
+		return
+	}
+	t.m.remove(t)
+	t.m.mu.Unlock()
+
+	t.m.changed()
+}
@@ -0,0 +1,219 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tasks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestManager(t *testing.T) {
+	tests := []struct {
+		desc       string
+		opts       []Option
+		update     func(*Manager) error
+		want       []Info
+		wantNewErr bool
+		wantErr    bool
+	}{
+		{
+			desc:       "fails on negative MaxFinished",
+			opts:       []Option{MaxFinished(-1)},
+			wantNewErr: true,
+		},
+		{
+			desc: "fails on empty name",
+			update: func(m *Manager) error {
+				_, err := m.Add("")
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on duplicate name",
+			update: func(m *Manager) error {
+				if _, err := m.Add("a"); err != nil {
+					return err
+				}
+				_, err := m.Add("a")
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			desc: "the name of a removed task can be used again",
+			update: func(m *Manager) error {
+				a, err := m.Add("a")
+				if err != nil {
+					return err
+				}
+				a.Progress(1, 2)
+				a.Remove()
+				_, err = m.Add("a")
+				return err
+			},
+			want: []Info{
+				{Name: "a"},
+			},
+		},
+		{
+			desc: "reports progress and status",
+			update: func(m *Manager) error {
+				a, err := m.Add("a")
+				if err != nil {
+					return err
+				}
+				a.Progress(3, 10)
+				a.Status("copying")
+				return nil
+			},
+			want: []Info{
+				{Name: "a", Done: 3, Total: 10, Status: "copying"},
+			},
+		},
+		{
+			desc: "clamps the progress",
+			update: func(m *Manager) error {
+				a, err := m.Add("a")
+				if err != nil {
+					return err
+				}
+				b, err := m.Add("b")
+				if err != nil {
+					return err
+				}
+				a.Progress(20, 10)
+				b.Progress(-1, -1)
+				return nil
+			},
+			want: []Info{
+				{Name: "a", Done: 10, Total: 10},
+				{Name: "b"},
+			},
+		},
+		{
+			desc: "ignores updates after the task finished",
+			update: func(m *Manager) error {
+				a, err := m.Add("a")
+				if err != nil {
+					return err
+				}
+				b, err := m.Add("b")
+				if err != nil {
+					return err
+				}
+				a.Progress(1, 10)
+				a.Done()
+				a.Progress(2, 10)
+				a.Fail(errors.New("late"))
+				b.Fail(nil)
+				b.Status("late")
+				return nil
+			},
+			want: []Info{
+				{Name: "a", State: StateDone, Done: 10, Total: 10},
+				{Name: "b", State: StateFailed, Err: errors.New("unknown error")},
+			},
+		},
+		{
+			desc: "removes the finished tasks over MaxFinished",
+			opts: []Option{MaxFinished(1)},
+			update: func(m *Manager) error {
+				var tasks []*Task
+				for _, n := range []string{"a", "b", "c"} {
+					t, err := m.Add(n)
+					if err != nil {
+						return err
+					}
+					tasks = append(tasks, t)
+				}
+				tasks[1].Done()
+				tasks[0].Done()
+				return nil
+			},
+			want: []Info{
+				{Name: "a", State: StateDone},
+				{Name: "c"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			m, err := New(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if tc.update != nil {
+				err := tc.update(m)
+				if (err != nil) != tc.wantErr {
+					t.Errorf("update => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+				}
+				if err != nil {
+					return
+				}
+			}
+			if diff := pretty.Compare(tc.want, m.Tasks()); diff != "" {
+				t.Errorf("Tasks => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInfo(t *testing.T) {
+	tests := []struct {
+		desc              string
+		info              Info
+		wantPercent       int
+		wantIndeterminate bool
+	}{
+		{
+			desc:        "running with a known total",
+			info:        Info{Done: 1, Total: 3},
+			wantPercent: 33,
+		},
+		{
+			desc:              "running with an unknown total",
+			info:              Info{Done: 5},
+			wantIndeterminate: true,
+		},
+		{
+			desc:        "done with an unknown total",
+			info:        Info{State: StateDone},
+			wantPercent: 100,
+		},
+		{
+			desc: "failed with an unknown total",
+			info: Info{State: StateFailed},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := tc.info.Percent(); got != tc.wantPercent {
+				t.Errorf("Percent => %d, want %d", got, tc.wantPercent)
+			}
+			if got := tc.info.Indeterminate(); got != tc.wantIndeterminate {
+				t.Errorf("Indeterminate => %v, want %v", got, tc.wantIndeterminate)
+			}
+		})
+	}
+}
@@ -27,17 +27,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
+	"github.com/mum4k/termdash/bus"
 	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/freeze"
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/postprocess"
 	"github.com/mum4k/termdash/private/event"
 	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/workspace"
 )
 
 // DefaultRedrawInterval is the default for the RedrawInterval option.
 const DefaultRedrawInterval = 250 * time.Millisecond
 
+// DefaultTickInterval is the default for the TickInterval option.
+const DefaultTickInterval = time.Second
+
 // Option is used to provide options.
 type Option interface {
 	// set sets the provided option.
@@ -61,6 +70,22 @@ func RedrawInterval(t time.Duration) Option {
 	})
 }
 
+// RedrawOnChange disables the periodic redraw. Instead the screen is redrawn
+// when a widget that implements widgetapi.Notifier reports a change of its
+// content, when a keyboard or mouse event arrives and when the terminal is
+// resized. When idle, termdash doesn't redraw at all.
+// Widgets that don't implement widgetapi.Notifier are only redrawn together
+// with the rest of the screen, use Controller.Redraw to redraw them
+// explicitly. Terminals that write the changes over multiple flushes, see
+// terminalapi.PartialFlusher, are redrawn until all the changes are written.
+// This option only applies to Run, the RedrawInterval option is ignored when
+// it is provided.
+func RedrawOnChange() Option {
+	return option(func(td *termdash) {
+		td.redrawOnChange = true
+	})
+}
+
 // ErrorHandler is used to provide a function that will be called with all
 // errors that occur while the dashboard is running. If not provided, any
 // errors panic the application.
@@ -89,6 +114,97 @@ func MouseSubscriber(f func(*terminalapi.Mouse)) Option {
 	})
 }
 
+// KeyBindings registers global key bindings, i.e. shortcuts that work
+// regardless of which container is focused, e.g. the key that quits the
+// application. Keys that start, continue or complete a bound sequence aren't
+// forwarded to the widgets. Bindings of the focused container and its
+// ancestors provided via container.KeyBindings take precedence.
+//
+// Termdash returns an error at startup if any of the bindings conflict with
+// keys the containers process, i.e. their scroll keys, keys that resize
+// splits or their own key bindings. Keys processed by widgets aren't known to
+// termdash and aren't checked.
+func KeyBindings(r *keybinding.Registry) Option {
+	return option(func(td *termdash) {
+		td.keyBindings = r
+	})
+}
+
+// HelpKey binds the key sequence to a function that shows or hides an
+// overlay listing all the global key bindings along with their descriptions,
+// see keybinding.Description. The sequence is bound in the registry provided
+// via the KeyBindings option, which is required.
+func HelpKey(seq keybinding.Sequence) Option {
+	return option(func(td *termdash) {
+		td.helpKey = seq
+	})
+}
+
+// IdleTimeout enables the detection of inactivity. The dashboard becomes
+// idle when no keyboard, mouse or paste input arrives for the duration.
+// Use together with the OnIdle or the LockOnIdle options.
+func IdleTimeout(d time.Duration) Option {
+	return option(func(td *termdash) {
+		td.idleTimeout = d
+	})
+}
+
+// OnIdle registers a function that is called each time the dashboard becomes
+// idle, see IdleTimeout. The function is called again only after new input
+// arrives and the dashboard becomes idle again.
+// The provided function must be thread-safe.
+func OnIdle(f func()) Option {
+	return option(func(td *termdash) {
+		td.onIdle = f
+	})
+}
+
+// LockOnIdle locks the dashboard when it becomes idle, see IdleTimeout.
+// The locked dashboard hides the containers behind an overlay that asks for
+// a passphrase and doesn't forward any input to the containers or the
+// subscribers. The entered passphrase is verified by the provided function,
+// the dashboard unlocks when it returns true. Applications that only require
+// a key press can accept any passphrase.
+// The provided function must be thread-safe.
+func LockOnIdle(verify func(passphrase string) bool) Option {
+	return option(func(td *termdash) {
+		td.lockVerify = verify
+	})
+}
+
+// AuditLog records every user action to the writer, e.g. pressed keys, mouse
+// clicks, changes of the focused container and executed key bindings. Each
+// action is written as a JSON encoded AuditRecord on a separate line. Keys
+// typed while the dashboard is locked aren't recorded, see LockOnIdle.
+// Errors writing to the writer are reported to the ErrorHandler.
+func AuditLog(w io.Writer) Option {
+	return option(func(td *termdash) {
+		td.auditWriter = w
+	})
+}
+
+// TickInterval sets how often termdash calls the Tick method of widgets that
+// implement widgetapi.Ticker. The widgets are ticked both when using Run and
+// the Controller.
+// Defaults to DefaultTickInterval.
+func TickInterval(d time.Duration) Option {
+	return option(func(td *termdash) {
+		td.tickInterval = d
+	})
+}
+
+// MessageBus sets the message bus of this termdash instance, which lets
+// widgets and application code exchange messages, see the bus package.
+// Termdash provides the bus to all the widgets that implement
+// widgetapi.BusUser and closes it when it stops, so the bus must not be
+// shared with other termdash instances.
+// Defaults to a new bus, available via Controller.Bus.
+func MessageBus(b *bus.Bus) Option {
+	return option(func(td *termdash) {
+		td.bus = b
+	})
+}
+
 // withEDS indicates that termdash should run with the provided event
 // distribution system instead of creating one.
 // Useful for tests.
@@ -103,9 +219,12 @@ func withEDS(eds *event.DistributionSystem) Option {
 // Controller instead.
 // Blocks until the context expires.
 func Run(ctx context.Context, t terminalapi.Terminal, c *container.Container, opts ...Option) error {
-	td := newTermdash(t, c, opts...)
+	td, err := newTermdash(t, c, opts...)
+	if err != nil {
+		return err
+	}
 
-	err := td.start(ctx)
+	err = td.start(ctx)
 	// Only return the status (error or nil) after the termdash event
 	// processing goroutine actually exits.
 	td.stop()
@@ -118,6 +237,7 @@ func Run(ctx context.Context, t terminalapi.Terminal, c *container.Container, op
 type Controller struct {
 	td     *termdash
 	cancel context.CancelFunc
+	bus    *bus.Bus
 }
 
 // NewController initializes termdash and returns an instance of the controller.
@@ -125,14 +245,21 @@ type Controller struct {
 // option is ignored.
 // Close the controller when it isn't needed anymore.
 func NewController(t terminalapi.Terminal, c *container.Container, opts ...Option) (*Controller, error) {
+	td, err := newTermdash(t, c, opts...)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	ctrl := &Controller{
-		td:     newTermdash(t, c, opts...),
+		td:     td,
 		cancel: cancel,
+		bus:    td.bus,
 	}
 
 	// stops when Close() is called.
 	go ctrl.td.processEvents(ctx)
+	go ctrl.td.tick(ctx)
 	if err := ctrl.td.periodicRedraw(); err != nil {
 		return nil, err
 	}
@@ -150,6 +277,26 @@ func (c *Controller) Redraw() error {
 	return c.td.redraw()
 }
 
+// RedrawSubtree triggers redraw of the container with the specified ID and
+// all of its sub containers, leaving the rest of the terminal unchanged.
+// Useful on dashboards where a single widget updates often while the others
+// remain static. See container.DrawSubtree for details.
+func (c *Controller) RedrawSubtree(containerID string) error {
+	if c.td == nil {
+		return errors.New("the termdash instance is no longer running, this controller is now invalid")
+	}
+
+	c.td.mu.Lock()
+	defer c.td.mu.Unlock()
+	return c.td.redrawSubtree(containerID)
+}
+
+// Bus returns the message bus of the termdash instance, see the MessageBus
+// option. The bus is closed together with the controller.
+func (c *Controller) Bus() *bus.Bus {
+	return c.bus
+}
+
 // Close closes the Controller and its termdash instance.
 func (c *Controller) Close() {
 	c.cancel()
@@ -179,37 +326,223 @@ type termdash struct {
 	// we're drawing it. Terminal needs to be cleared if its sized changed.
 	clearNeeded bool
 
+	// changeCh receives a value when a widget reports a change of its
+	// content. Used with the RedrawOnChange option.
+	changeCh chan struct{}
+
+	// helpVisible indicates if the overlay listing the key bindings is
+	// displayed.
+	helpVisible bool
+
+	// idleTimer fires when no input arrived for the IdleTimeout, nil if
+	// the idle detection isn't enabled. Only accessed from the event
+	// collecting goroutine.
+	idleTimer *time.Timer
+
+	// lock is the state of the locked dashboard or nil if it isn't locked.
+	lock *lockState
+
+	// dialogs are the requested dialogs, the first one is displayed.
+	dialogs []*dialog
+
+	// auditLog records the user actions, nil if the AuditLog option wasn't
+	// provided.
+	auditLog *auditLog
+
+	// screens are the registered screens and screen is the index of the
+	// displayed one. The container is the root container of the displayed
+	// screen.
+	screens []*screen
+	screen  int
+	// screenMu protects container and screen, it is held together with mu
+	// when they change, so holding either one is enough to read them.
+	screenMu sync.Mutex
+
+	// bus delivers the messages between widgets and the application.
+	bus *bus.Bus
+
+	// freeze is the gate of the FreezeKey option, nil if it wasn't provided.
+	freeze *freeze.Gate
+
+	// privacy is the privacy mode of the PrivacyKey option, nil if it wasn't
+	// provided.
+	privacy *postprocess.Privacy
+
+	// workspace are the slots of the WorkspaceKeys option, nil if it wasn't
+	// provided.
+	workspace *workspace.Slots
+
 	// mu protects termdash.
 	mu sync.Mutex
 
 	// Options.
-	redrawInterval     time.Duration
-	errorHandler       func(error)
-	mouseSubscriber    func(*terminalapi.Mouse)
-	keyboardSubscriber func(*terminalapi.Keyboard)
+	redrawInterval      time.Duration
+	redrawOnChange      bool
+	errorHandler        func(error)
+	mouseSubscriber     func(*terminalapi.Mouse)
+	keyboardSubscriber  func(*terminalapi.Keyboard)
+	keyBindings         *keybinding.Registry
+	helpKey             keybinding.Sequence
+	idleTimeout         time.Duration
+	onIdle              func()
+	lockVerify          func(string) bool
+	auditWriter         io.Writer
+	tickInterval        time.Duration
+	freezeKey           keybinding.Sequence
+	privacyKey          keybinding.Sequence
+	maximizeKey         keybinding.Sequence
+	workspaceSaveKey    keybinding.Sequence
+	workspaceRestoreKey keybinding.Sequence
 }
 
 // newTermdash creates a new termdash.
-func newTermdash(t terminalapi.Terminal, c *container.Container, opts ...Option) *termdash {
+func newTermdash(t terminalapi.Terminal, c *container.Container, opts ...Option) (*termdash, error) {
 	td := &termdash{
 		term:           t,
 		container:      c,
 		eds:            event.NewDistributionSystem(),
 		closeCh:        make(chan struct{}),
 		exitCh:         make(chan struct{}),
+		changeCh:       make(chan struct{}, 1),
 		redrawInterval: DefaultRedrawInterval,
+		tickInterval:   DefaultTickInterval,
 	}
 
 	for _, opt := range opts {
 		opt.set(td)
 	}
+	if err := td.validate(); err != nil {
+		return nil, err
+	}
+	if err := td.setKeyBindings(); err != nil {
+		return nil, err
+	}
+	if td.auditWriter != nil {
+		td.auditLog = newAuditLog(td.auditWriter)
+	}
+	if td.bus == nil {
+		td.bus = bus.New()
+	}
+	for _, sc := range td.containers() {
+		sc.SetBus(td.bus)
+		if td.auditLog != nil {
+			sc.SetFocusFunc(td.auditFocus)
+			sc.SetCommandFunc(td.auditCommand)
+		}
+		if td.redrawOnChange {
+			sc.SetNotifyFunc(td.notifyChange)
+		}
+	}
 	td.subscribers()
-	c.Subscribe(td.eds)
-	return td
+	if len(td.screens) > 0 {
+		if fn := td.screens[td.screen].onEnter; fn != nil {
+			fn()
+		}
+	}
+	return td, nil
+}
+
+// validate validates the provided options.
+func (td *termdash) validate() error {
+	if td.idleTimeout < 0 {
+		return fmt.Errorf("invalid IdleTimeout(%v), must be a positive duration", td.idleTimeout)
+	}
+	if td.idleTimeout == 0 && (td.onIdle != nil || td.lockVerify != nil) {
+		return errors.New("the OnIdle and LockOnIdle options require the IdleTimeout option")
+	}
+	if td.tickInterval <= 0 {
+		return fmt.Errorf("invalid TickInterval(%v), must be a positive duration", td.tickInterval)
+	}
+	return td.validateScreens()
+}
+
+// setKeyBindings binds the help key and provides the global key bindings to
+// the container after verifying they don't conflict with the keys of the
+// containers.
+func (td *termdash) setKeyBindings() error {
+	if td.keyBindings == nil {
+		if td.helpKey != nil {
+			return fmt.Errorf("HelpKey(%v) requires the KeyBindings option", td.helpKey)
+		}
+		if td.freezeKey != nil {
+			return fmt.Errorf("FreezeKey(%v) requires the KeyBindings option", td.freezeKey)
+		}
+		if td.privacyKey != nil {
+			return fmt.Errorf("PrivacyKey(%v) requires the KeyBindings option", td.privacyKey)
+		}
+		if td.maximizeKey != nil {
+			return fmt.Errorf("MaximizeKey(%v) requires the KeyBindings option", td.maximizeKey)
+		}
+		if td.workspaceSaveKey != nil || td.workspaceRestoreKey != nil {
+			return errors.New("WorkspaceKeys requires the KeyBindings option")
+		}
+		return nil
+	}
+
+	if td.helpKey != nil {
+		if err := td.keyBindings.Bind(td.helpKey, td.toggleHelp, keybinding.Description("Show or hide this help")); err != nil {
+			return fmt.Errorf("invalid HelpKey: %v", err)
+		}
+	}
+	if err := td.bindScreenKeys(); err != nil {
+		return err
+	}
+	if err := td.bindFreezeKey(); err != nil {
+		return err
+	}
+	if err := td.bindPrivacyKey(); err != nil {
+		return err
+	}
+	if err := td.bindMaximizeKey(); err != nil {
+		return err
+	}
+	if err := td.bindWorkspaceKeys(); err != nil {
+		return err
+	}
+	for _, c := range td.containers() {
+		if err := c.KeyConflicts(td.keyBindings); err != nil {
+			return fmt.Errorf("invalid KeyBindings: %v", err)
+		}
+		c.SetGlobalKeyBindings(td.keyBindings)
+	}
+	return nil
+}
+
+// toggleHelp shows or hides the overlay listing the key bindings and redraws
+// the terminal.
+func (td *termdash) toggleHelp() error {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+
+	td.helpVisible = !td.helpVisible
+	if !td.helpVisible {
+		// Remove the overlay from the terminal.
+		td.clearNeeded = true
+	}
+	return td.redraw()
+}
+
+// notifyChange records that the content of a widget changed.
+// Doesn't block, multiple changes before the next redraw are coalesced.
+func (td *termdash) notifyChange() {
+	select {
+	case td.changeCh <- struct{}{}:
+	default:
+	}
 }
 
 // subscribers subscribes event receivers that live in this package to EDS.
 func (td *termdash) subscribers() {
+	// Delivers the input events to the displayed screen. Repetitive events
+	// towards the widgets are throttled.
+	td.eds.Subscribe([]terminalapi.Event{
+		&terminalapi.Keyboard{},
+		&terminalapi.Mouse{},
+		&terminalapi.Paste{},
+		&terminalapi.Composition{},
+		&terminalapi.Commit{},
+	}, td.dispatch, event.MaxRepetitive(10))
+
 	// Handler for all errors that occur during input event processing.
 	td.eds.Subscribe([]terminalapi.Event{terminalapi.NewError("")}, func(ev terminalapi.Event) {
 		td.handleError(ev.(*terminalapi.Error).Error())
@@ -218,14 +551,20 @@ func (td *termdash) subscribers() {
 	// Handles terminal resize events.
 	td.eds.Subscribe([]terminalapi.Event{&terminalapi.Resize{}}, func(terminalapi.Event) {
 		td.setClearNeeded()
+		if td.redrawOnChange {
+			td.notifyChange()
+		}
 	})
 
-	// Redraws the screen on Keyboard and Mouse events.
+	// Redraws the screen on the input events.
 	// These events very likely change the content of the widgets (e.g. zooming
 	// a LineChart) so a redraw is needed to make that visible.
 	td.eds.Subscribe([]terminalapi.Event{
 		&terminalapi.Keyboard{},
 		&terminalapi.Mouse{},
+		&terminalapi.Paste{},
+		&terminalapi.Composition{},
+		&terminalapi.Commit{},
 	}, func(terminalapi.Event) {
 		td.evRedraw()
 	}, event.MaxRepetitive(0)) // No repetitive events that cause terminal redraw.
@@ -264,20 +603,77 @@ func (td *termdash) setClearNeeded() {
 // redraw redraws the container and its widgets.
 // The caller must hold td.mu.
 func (td *termdash) redraw() error {
-	if td.clearNeeded {
+	if td.clearNeeded || td.lock != nil {
 		if err := td.term.Clear(); err != nil {
 			return fmt.Errorf("term.Clear => error: %v", err)
 		}
 		td.clearNeeded = false
 	}
 
+	if td.lock != nil {
+		// The containers stay hidden until the dashboard is unlocked.
+		if err := drawOverlay(td.term, lockTitle, td.lock.lines()); err != nil {
+			return fmt.Errorf("drawOverlay => error: %v", err)
+		}
+		if err := td.flush(); err != nil {
+			return err
+		}
+		return nil
+	}
+
 	if err := td.container.Draw(); err != nil {
 		return fmt.Errorf("container.Draw => error: %v", err)
 	}
+	if err := drawFreeze(td.term, td.freeze); err != nil {
+		return fmt.Errorf("drawFreeze => error: %v", err)
+	}
+	if td.helpVisible {
+		if err := drawHelp(td.term, td.keyBindings.Bindings()); err != nil {
+			return fmt.Errorf("drawHelp => error: %v", err)
+		}
+	}
+	if len(td.dialogs) > 0 {
+		d := td.dialogs[0]
+		if err := drawOverlay(td.term, d.title(), d.lines()); err != nil {
+			return fmt.Errorf("drawOverlay => error: %v", err)
+		}
+	}
 
+	if err := td.flush(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// flush flushes the terminal. Terminals that implement
+// terminalapi.PartialFlusher might not write all the changed cells at once,
+// with the RedrawOnChange option another redraw is then requested, so that
+// the screen doesn't stay partially updated until the next change.
+// The caller must hold td.mu.
+func (td *termdash) flush() error {
 	if err := td.term.Flush(); err != nil {
 		return fmt.Errorf("term.Flush => error: %v", err)
 	}
+	if pf, ok := td.term.(terminalapi.PartialFlusher); ok && td.redrawOnChange && pf.FlushPending() {
+		td.notifyChange()
+	}
+	return nil
+}
+
+// redrawSubtree redraws the container with the specified ID and its sub
+// containers. The caller must hold td.mu.
+func (td *termdash) redrawSubtree(id string) error {
+	if td.clearNeeded || td.helpVisible || td.lock != nil || len(td.dialogs) > 0 || (td.freeze != nil && td.freeze.Frozen()) {
+		return td.redraw()
+	}
+
+	if err := td.container.DrawSubtree(id); err != nil {
+		return fmt.Errorf("container.DrawSubtree => error: %v", err)
+	}
+
+	if err := td.flush(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -306,9 +702,27 @@ func (td *termdash) periodicRedraw() error {
 func (td *termdash) processEvents(ctx context.Context) {
 	defer close(td.exitCh)
 
+	if td.idleTimeout > 0 {
+		td.idleTimer = time.AfterFunc(td.idleTimeout, td.idle)
+		defer td.idleTimer.Stop()
+	}
+
 	for {
 		ev := td.term.Event(ctx)
+		if ev != nil && isInput(ev) && td.idleTimer != nil {
+			if td.lockInput(ev) {
+				ev = nil
+			} else {
+				td.restartIdle()
+			}
+		}
+		if ev != nil && isInput(ev) && td.dialogInput(ev) {
+			ev = nil
+		}
 		if ev != nil {
+			if isInput(ev) {
+				td.auditInput(ev)
+			}
 			td.eds.Event(ev)
 		}
 
@@ -320,6 +734,24 @@ func (td *termdash) processEvents(ctx context.Context) {
 	}
 }
 
+// tick ticks the widgets once each TickInterval until the context expires.
+func (td *termdash) tick(ctx context.Context) {
+	ticker := time.NewTicker(td.tickInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			// The widgets on screens that aren't displayed keep running.
+			for _, c := range td.containers() {
+				c.Tick(now)
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // start starts the terminal dashboard. Blocks until the context expires or
 // until stop() is called.
 func (td *termdash) start(ctx context.Context) error {
@@ -329,18 +761,28 @@ func (td *termdash) start(ctx context.Context) error {
 		return err
 	}
 
-	redrawTimer := time.NewTicker(td.redrawInterval)
-	defer redrawTimer.Stop()
+	var redrawC <-chan time.Time
+	if !td.redrawOnChange {
+		redrawTimer := time.NewTicker(td.redrawInterval)
+		defer redrawTimer.Stop()
+		redrawC = redrawTimer.C
+	}
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// stops when stop() is called or the context expires.
 	go td.processEvents(ctx)
+	go td.tick(ctx)
 
 	for {
 		select {
-		case <-redrawTimer.C:
+		case <-redrawC:
+			if err := td.periodicRedraw(); err != nil {
+				return err
+			}
+
+		case <-td.changeCh:
 			if err := td.periodicRedraw(); err != nil {
 				return err
 			}
@@ -355,8 +797,10 @@ func (td *termdash) start(ctx context.Context) error {
 }
 
 // stop tells the event collecting goroutine to stop.
-// Blocks until it exits.
+// Blocks until it exits, then stops the event subscribers.
 func (td *termdash) stop() {
 	close(td.closeCh)
 	<-td.exitCh
+	td.eds.Close()
+	td.bus.Close()
 }
@@ -15,18 +15,28 @@
 package termdash
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"image"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/bus"
 	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/freeze"
+	"github.com/mum4k/termdash/keybinding"
 	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/linestyle"
 	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/postprocess"
 	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
 	"github.com/mum4k/termdash/private/event"
 	"github.com/mum4k/termdash/private/event/eventqueue"
 	"github.com/mum4k/termdash/private/event/testevent"
@@ -37,6 +47,8 @@ import (
 	"github.com/mum4k/termdash/widgetapi"
 	"github.com/mum4k/termdash/widgets/barchart"
 	"github.com/mum4k/termdash/widgets/gauge"
+	"github.com/mum4k/termdash/widgets/textinput"
+	"github.com/mum4k/termdash/workspace"
 )
 
 // Example shows how to setup and run termdash with periodic redraw.
@@ -520,6 +532,29 @@ func TestController(t *testing.T) {
 				return ft
 			},
 		},
+		{
+			desc: "controller triggers redraw of a subtree",
+			size: image.Point{60, 10},
+			apiEvents: func(mi *fakewidget.Mirror) {
+				mi.Text("hello")
+			},
+			controls: func(ctrl *Controller) error {
+				return ctrl.RedrawSubtree("root")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				mirror := fakewidget.New(widgetapi.Options{})
+				mirror.Text("hello")
+				fakewidget.MustDrawWithMirror(
+					mirror,
+					ft,
+					testcanvas.MustNew(ft.Area()),
+					&widgetapi.Meta{Focused: true},
+				)
+				return ft
+			},
+		},
 		{
 			desc: "ignores periodic redraw via the controller",
 			size: image.Point{60, 10},
@@ -595,6 +630,97 @@ func TestController(t *testing.T) {
 				return ft
 			},
 		},
+		{
+			desc: "help key shows the overlay with the key bindings",
+			size: image.Point{60, 10},
+			opts: []Option{
+				KeyBindings(mustKeyBindings(keybinding.Binding{
+					Sequence:    keybinding.Sequence{'q'},
+					Description: "Quit",
+				})),
+				HelpKey(keybinding.Sequence{keyboard.KeyF1}),
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyF1},
+			},
+			wantProcessed: 2,
+			controls: func(ctrl *Controller) error {
+				return ctrl.Redraw()
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				// The key is consumed by the binding, the widget doesn't
+				// receive it.
+				fakewidget.MustDraw(
+					ft,
+					testcanvas.MustNew(ft.Area()),
+					&widgetapi.Meta{Focused: true},
+					widgetapi.Options{},
+				)
+
+				cvs := testcanvas.MustNew(image.Rect(13, 3, 46, 7))
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ')
+				testdraw.MustBorder(cvs, cvs.Area(), draw.BorderTitle("Key bindings", draw.OverrunModeThreeDot))
+				testdraw.MustText(cvs, "q      Quit", image.Point{2, 1})
+				testdraw.MustText(cvs, "KeyF1  Show or hide this help", image.Point{2, 2})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "help key hides the overlay when pressed again",
+			size: image.Point{60, 10},
+			opts: []Option{
+				KeyBindings(mustKeyBindings()),
+				HelpKey(keybinding.Sequence{keyboard.KeyF1}),
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyF1},
+				&terminalapi.Keyboard{Key: keyboard.KeyF1},
+			},
+			// The repeated event doesn't trigger another redraw.
+			wantProcessed: 3,
+			controls: func(ctrl *Controller) error {
+				return ctrl.Redraw()
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+
+				fakewidget.MustDraw(
+					ft,
+					testcanvas.MustNew(ft.Area()),
+					&widgetapi.Meta{Focused: true},
+					widgetapi.Options{},
+				)
+				return ft
+			},
+		},
+		{
+			desc: "fails on HelpKey without KeyBindings",
+			size: image.Point{60, 10},
+			opts: []Option{
+				HelpKey(keybinding.Sequence{keyboard.KeyF1}),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails when the HelpKey conflicts with a key binding",
+			size: image.Point{60, 10},
+			opts: []Option{
+				KeyBindings(mustKeyBindings(keybinding.Binding{
+					Sequence: keybinding.Sequence{keyboard.KeyF1},
+				})),
+				HelpKey(keybinding.Sequence{keyboard.KeyF1}),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -618,6 +744,7 @@ func TestController(t *testing.T) {
 			})
 			cont, err := container.New(
 				got,
+				container.ID("root"),
 				container.PlaceWidget(mi),
 			)
 			if err != nil {
@@ -661,3 +788,1347 @@ func TestController(t *testing.T) {
 		})
 	}
 }
+
+// mustKeyBindings returns a registry with the sequences bound to functions
+// that do nothing. Panics on errors.
+func mustKeyBindings(bindings ...keybinding.Binding) *keybinding.Registry {
+	r, err := keybinding.New()
+	if err != nil {
+		panic(err)
+	}
+	for _, b := range bindings {
+		if err := r.Bind(b.Sequence, func() error { return nil }, keybinding.Description(b.Description)); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// notifyingMirror is a fake widget that implements widgetapi.Notifier.
+type notifyingMirror struct {
+	*fakewidget.Mirror
+
+	mu     sync.Mutex
+	notify func()
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (nm *notifyingMirror) SetNotifyFunc(fn func()) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.notify = fn
+}
+
+// Text sets the text on the mirror and notifies about the change.
+func (nm *notifyingMirror) Text(txt string) {
+	nm.Mirror.Text(txt)
+
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if nm.notify != nil {
+		nm.notify()
+	}
+}
+
+func TestRedrawOnChange(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New()))
+	nm := &notifyingMirror{
+		Mirror: fakewidget.New(widgetapi.Options{}),
+	}
+	cont, err := container.New(ft, container.PlaceWidget(nm))
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- Run(ctx, ft, cont, RedrawOnChange())
+	}()
+
+	want := func(text string) *faketerm.Terminal {
+		ft := faketerm.MustNew(image.Point{60, 10})
+		mirror := fakewidget.New(widgetapi.Options{})
+		mirror.Text(text)
+		fakewidget.MustDrawWithMirror(
+			mirror,
+			ft,
+			testcanvas.MustNew(ft.Area()),
+			&widgetapi.Meta{Focused: true},
+		)
+		return ft
+	}
+
+	for _, text := range []string{"hello", "world"} {
+		nm.Text(text)
+		if err := testevent.WaitFor(5*time.Second, func() error {
+			if diff := faketerm.Diff(want(text), ft); diff != "" {
+				return fmt.Errorf("the screen wasn't redrawn after a change: %v", diff)
+			}
+			return nil
+		}); err != nil {
+			t.Errorf("testevent.WaitFor => %v", err)
+		}
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Errorf("Run => unexpected error: %v", err)
+	}
+}
+
+// partialTerm is a terminal whose first flushes report that they didn't write
+// all the changed cells.
+// Implements terminalapi.PartialFlusher.
+type partialTerm struct {
+	*faketerm.Terminal
+
+	// partial is the number of the first flushes that are partial.
+	partial int
+
+	mu      sync.Mutex
+	flushes int
+}
+
+// Flush implements terminalapi.Terminal.Flush.
+func (pt *partialTerm) Flush() error {
+	pt.mu.Lock()
+	pt.flushes++
+	pt.mu.Unlock()
+	return pt.Terminal.Flush()
+}
+
+// FlushPending implements terminalapi.PartialFlusher.FlushPending.
+func (pt *partialTerm) FlushPending() bool {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.flushes <= pt.partial
+}
+
+// flushCount returns the number of flushes.
+func (pt *partialTerm) flushCount() int {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.flushes
+}
+
+func TestRedrawOnChangeCompletesPartialFlushes(t *testing.T) {
+	pt := &partialTerm{
+		Terminal: faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New())),
+		partial:  3,
+	}
+	cont, err := container.New(pt, container.PlaceWidget(fakewidget.New(widgetapi.Options{})))
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- Run(ctx, pt, cont, RedrawOnChange())
+	}()
+
+	// The initial flush and one more for each partial flush.
+	const want = 4
+	if err := testevent.WaitFor(5*time.Second, func() error {
+		if got := pt.flushCount(); got != want {
+			return fmt.Errorf("got %d flushes, want %d", got, want)
+		}
+		return nil
+	}); err != nil {
+		t.Errorf("testevent.WaitFor => %v", err)
+	}
+	// Once all the cells are written, the terminal isn't flushed anymore.
+	time.Sleep(50 * time.Millisecond)
+	if got := pt.flushCount(); got != want {
+		t.Errorf("got %d flushes after the complete flush, want %d", got, want)
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Errorf("Run => unexpected error: %v", err)
+	}
+}
+
+func TestIdle(t *testing.T) {
+	tests := []struct {
+		desc    string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			desc:    "fails on negative IdleTimeout",
+			opts:    []Option{IdleTimeout(-1)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on OnIdle without IdleTimeout",
+			opts:    []Option{OnIdle(func() {})},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on LockOnIdle without IdleTimeout",
+			opts:    []Option{LockOnIdle(func(string) bool { return true })},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New()))
+			cont, err := container.New(ft)
+			if err != nil {
+				t.Fatalf("container.New => unexpected error: %v", err)
+			}
+			_, err = NewController(ft, cont, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("NewController => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestOnIdle(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New()))
+	cont, err := container.New(ft)
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+
+	idleCh := make(chan struct{}, 1)
+	ctrl, err := NewController(ft, cont,
+		IdleTimeout(10*time.Millisecond),
+		OnIdle(func() {
+			select {
+			case idleCh <- struct{}{}:
+			default:
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewController => unexpected error: %v", err)
+	}
+	defer ctrl.Close()
+
+	select {
+	case <-idleCh:
+	case <-time.After(5 * time.Second):
+		t.Errorf("the OnIdle function wasn't called")
+	}
+}
+
+func TestLockOnIdle(t *testing.T) {
+	eq := eventqueue.New()
+	ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eq))
+	mi := fakewidget.New(widgetapi.Options{
+		WantKeyboard: widgetapi.KeyScopeFocused,
+	})
+	cont, err := container.New(ft, container.PlaceWidget(mi))
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+
+	var audit syncBuffer
+	ctrl, err := NewController(ft, cont,
+		// The test locks the dashboard explicitly.
+		IdleTimeout(time.Hour),
+		LockOnIdle(func(pass string) bool {
+			return pass == "ab"
+		}),
+		AuditLog(&audit),
+	)
+	if err != nil {
+		t.Fatalf("NewController => unexpected error: %v", err)
+	}
+	defer ctrl.Close()
+
+	locked := func(lines ...string) *faketerm.Terminal {
+		ft := faketerm.MustNew(image.Point{60, 10})
+		// The box is in the middle of the terminal.
+		top := (10 - len(lines) - 2) / 2
+		cvs := testcanvas.MustNew(image.Rect(12, top, 47, top+len(lines)+2))
+		testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ')
+		testdraw.MustBorder(cvs, cvs.Area(), draw.BorderTitle("Locked", draw.OverrunModeThreeDot))
+		for i, l := range lines {
+			testdraw.MustText(cvs, l, image.Point{2, 1 + i})
+		}
+		testcanvas.MustApply(cvs, ft)
+		return ft
+	}
+	waitForScreen := func(want *faketerm.Terminal) {
+		t.Helper()
+		if err := testevent.WaitFor(5*time.Second, func() error {
+			ctrl.td.mu.Lock()
+			defer ctrl.td.mu.Unlock()
+			if diff := faketerm.Diff(want, ft); diff != "" {
+				return fmt.Errorf("unexpected screen: %v", diff)
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("testevent.WaitFor => %v", err)
+		}
+	}
+
+	ctrl.td.idle()
+	waitForScreen(locked("Enter the passphrase to unlock.", "", "> "))
+
+	for _, k := range []keyboard.Key{'x', keyboard.KeyEnter} {
+		eq.Push(&terminalapi.Keyboard{Key: k})
+	}
+	waitForScreen(locked("Enter the passphrase to unlock.", "", "> ", "", "Wrong passphrase, try again."))
+
+	for _, k := range []keyboard.Key{'a', 'c', keyboard.KeyBackspace2, 'b'} {
+		eq.Push(&terminalapi.Keyboard{Key: k})
+	}
+	waitForScreen(locked("Enter the passphrase to unlock.", "", "> **", "", "Wrong passphrase, try again."))
+
+	eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyEnter})
+	// The keys pressed while locked don't reach the widget.
+	unlocked := faketerm.MustNew(image.Point{60, 10})
+	fakewidget.MustDraw(
+		unlocked,
+		testcanvas.MustNew(unlocked.Area()),
+		&widgetapi.Meta{Focused: true},
+		widgetapi.Options{},
+	)
+	waitForScreen(unlocked)
+
+	// The keys of the passphrase aren't recorded.
+	var got []AuditAction
+	for _, l := range audit.lines() {
+		r := &AuditRecord{}
+		if err := json.Unmarshal([]byte(l), r); err != nil {
+			t.Fatalf("json.Unmarshal(%q) => unexpected error: %v", l, err)
+		}
+		got = append(got, r.Action)
+	}
+	want := []AuditAction{AuditLock, AuditUnlockFailed, AuditUnlock}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("AuditLog => unexpected actions (-want, +got):\n%s", diff)
+	}
+}
+
+// syncBuffer is a thread-safe bytes.Buffer.
+type syncBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) lines() []string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return strings.Split(strings.TrimSuffix(sb.buf.String(), "\n"), "\n")
+}
+
+func TestAuditLog(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	oldTimeNow := timeNow
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = oldTimeNow }()
+
+	eq := eventqueue.New()
+	ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eq))
+	cont, err := container.New(
+		ft,
+		container.SplitVertical(
+			container.Left(
+				container.ID("left"),
+				container.PlaceWidget(fakewidget.New(widgetapi.Options{})),
+			),
+			container.Right(
+				container.ID("right"),
+				container.PlaceWidget(fakewidget.New(widgetapi.Options{})),
+			),
+		),
+	)
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+
+	var buf syncBuffer
+	ctrl, err := NewController(ft, cont,
+		KeyBindings(mustKeyBindings(keybinding.Binding{
+			Sequence:    keybinding.Sequence{keyboard.KeyCtrlS},
+			Description: "Save",
+		})),
+		AuditLog(&buf),
+	)
+	if err != nil {
+		t.Fatalf("NewController => unexpected error: %v", err)
+	}
+	defer ctrl.Close()
+
+	// The records of the container are written by a different goroutine, so
+	// the events are pushed in steps to make the order deterministic.
+	steps := []struct {
+		events      []terminalapi.Event
+		wantRecords int
+	}{
+		{
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{40, 5}, Button: mouse.ButtonLeft},
+				// Dragging is recorded only once.
+				&terminalapi.Mouse{Position: image.Point{41, 5}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{41, 5}, Button: mouse.ButtonRelease},
+			},
+			wantRecords: 2,
+		},
+		{
+			events:      []terminalapi.Event{&terminalapi.Keyboard{Key: 'a'}},
+			wantRecords: 3,
+		},
+		{
+			events:      []terminalapi.Event{&terminalapi.Paste{Text: "héllo"}},
+			wantRecords: 4,
+		},
+		{
+			events:      []terminalapi.Event{&terminalapi.Keyboard{Key: keyboard.KeyCtrlS}},
+			wantRecords: 6,
+		},
+		{
+			events:      []terminalapi.Event{&terminalapi.Mouse{Position: image.Point{1, 2}, Button: mouse.ButtonWheelUp}},
+			wantRecords: 7,
+		},
+		{
+			events: []terminalapi.Event{
+				// The composition isn't recorded.
+				&terminalapi.Composition{Text: "ni", Cursor: 2},
+				&terminalapi.Commit{Text: "你"},
+			},
+			wantRecords: 8,
+		},
+	}
+	for _, s := range steps {
+		for _, ev := range s.events {
+			eq.Push(ev)
+		}
+		if err := testevent.WaitFor(5*time.Second, func() error {
+			if got := len(buf.lines()); got != s.wantRecords {
+				return fmt.Errorf("got %d audit records, want %d", got, s.wantRecords)
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("testevent.WaitFor => %v", err)
+		}
+	}
+
+	var got []*AuditRecord
+	for _, l := range buf.lines() {
+		r := &AuditRecord{}
+		if err := json.Unmarshal([]byte(l), r); err != nil {
+			t.Fatalf("json.Unmarshal(%q) => unexpected error: %v", l, err)
+		}
+		got = append(got, r)
+	}
+	want := []*AuditRecord{
+		{Time: now, Action: AuditClick, Button: "ButtonLeft", Position: &image.Point{40, 5}},
+		{Time: now, Action: AuditFocus, Container: "right"},
+		{Time: now, Action: AuditText, Length: 1},
+		{Time: now, Action: AuditPaste, Length: 5},
+		{Time: now, Action: AuditKey, Key: "KeyCtrlS"},
+		{Time: now, Action: AuditCommand, Keys: "KeyCtrlS", Description: "Save"},
+		{Time: now, Action: AuditWheel, Button: "ButtonWheelUp", Position: &image.Point{1, 2}},
+		{Time: now, Action: AuditCommit, Length: 1},
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("AuditLog => unexpected records (-want, +got):\n%s", diff)
+	}
+}
+
+func TestAuditLogRedactsText(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	oldTimeNow := timeNow
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = oldTimeNow }()
+
+	eq := eventqueue.New()
+	ft := faketerm.MustNew(image.Point{30, 5}, faketerm.WithEventQueue(eq))
+	ti, err := textinput.New(textinput.HideTextWith('*'))
+	if err != nil {
+		t.Fatalf("textinput.New => unexpected error: %v", err)
+	}
+	cont, err := container.New(ft, container.PlaceWidget(ti))
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+
+	var buf syncBuffer
+	ctrl, err := NewController(ft, cont, AuditLog(&buf))
+	if err != nil {
+		t.Fatalf("NewController => unexpected error: %v", err)
+	}
+	defer ctrl.Close()
+
+	const password = "pa55wörd"
+	for _, r := range password {
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.Key(r)})
+	}
+	eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyEnter})
+	wantRecords := len([]rune(password)) + 1
+	if err := testevent.WaitFor(5*time.Second, func() error {
+		if got := len(buf.lines()); got != wantRecords {
+			return fmt.Errorf("got %d audit records, want %d", got, wantRecords)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("testevent.WaitFor => %v", err)
+	}
+	if got := ti.ReadAndClear(); got != password {
+		t.Errorf("ReadAndClear => %q, want %q", got, password)
+	}
+
+	// The raw records are compared, so that no field can carry the text.
+	var want []string
+	for range password {
+		want = append(want, `{"time":"2020-01-02T03:04:05Z","action":"text","length":1}`)
+	}
+	want = append(want, `{"time":"2020-01-02T03:04:05Z","action":"key","key":"KeyEnter"}`)
+	if diff := pretty.Compare(want, buf.lines()); diff != "" {
+		t.Errorf("AuditLog => unexpected records (-want, +got):\n%s", diff)
+	}
+}
+
+// tickWidget is a widget that reports its ticks on a channel.
+type tickWidget struct {
+	*fakewidget.Mirror
+	ticks chan time.Time
+}
+
+// Tick implements widgetapi.Ticker.Tick.
+func (tw *tickWidget) Tick(now time.Time) {
+	select {
+	case tw.ticks <- now:
+	default:
+	}
+}
+
+func TestTickInterval(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New()))
+	tw := &tickWidget{
+		Mirror: fakewidget.New(widgetapi.Options{}),
+		ticks:  make(chan time.Time, 1),
+	}
+	cont, err := container.New(ft, container.PlaceWidget(tw))
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+
+	if _, err := NewController(ft, cont, TickInterval(0)); err == nil {
+		t.Fatalf("NewController => got nil error on zero TickInterval, want an error")
+	}
+
+	ctrl, err := NewController(ft, cont, TickInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewController => unexpected error: %v", err)
+	}
+	defer ctrl.Close()
+
+	select {
+	case <-tw.ticks:
+	case <-time.After(5 * time.Second):
+		t.Errorf("the widget wasn't ticked")
+	}
+}
+
+// busWidget is a widget that publishes a message when it receives the bus.
+type busWidget struct {
+	*fakewidget.Mirror
+}
+
+// SetBus implements widgetapi.BusUser.SetBus.
+func (bw *busWidget) SetBus(b *bus.Bus) {
+	b.Publish("mounted")
+}
+
+func TestMessageBus(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New()))
+	cont, err := container.New(ft, container.PlaceWidget(&busWidget{
+		Mirror: fakewidget.New(widgetapi.Options{}),
+	}))
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+
+	b := bus.New()
+	var got []interface{}
+	b.Subscribe("", func(msg interface{}) {
+		got = append(got, msg)
+	})
+	ctrl, err := NewController(ft, cont, MessageBus(b))
+	if err != nil {
+		t.Fatalf("NewController => unexpected error: %v", err)
+	}
+	if ctrl.Bus() != b {
+		t.Errorf("Bus => %v, want the bus provided via MessageBus", ctrl.Bus())
+	}
+	ctrl.Bus().Publish("published")
+	ctrl.Close()
+	// The bus is closed together with the controller.
+	b.Publish("after close")
+
+	if diff := pretty.Compare([]interface{}{"mounted", "published"}, got); diff != "" {
+		t.Errorf("messages => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	ctrl, err = NewController(ft, cont)
+	if err != nil {
+		t.Fatalf("NewController => unexpected error: %v", err)
+	}
+	defer ctrl.Close()
+	if ctrl.Bus() == nil {
+		t.Errorf("Bus => nil, want a new bus by default")
+	}
+}
+
+// keyCounter is a widget that counts the received keyboard events.
+type keyCounter struct {
+	*fakewidget.Mirror
+	mu   sync.Mutex
+	keys int
+}
+
+// Keyboard implements widgetapi.Widget.Keyboard.
+func (kc *keyCounter) Keyboard(k *terminalapi.Keyboard) error {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	kc.keys++
+	return nil
+}
+
+// count returns the number of received keyboard events.
+func (kc *keyCounter) count() int {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	return kc.keys
+}
+
+func TestScreens(t *testing.T) {
+	newCounter := func() *keyCounter {
+		return &keyCounter{
+			Mirror: fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeGlobal}),
+		}
+	}
+	mustCont := func(ft *faketerm.Terminal, title string, w widgetapi.Widget) *container.Container {
+		c, err := container.New(ft, container.Border(linestyle.Light), container.BorderTitle(title), container.PlaceWidget(w))
+		if err != nil {
+			t.Fatalf("container.New => unexpected error: %v", err)
+		}
+		return c
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New()))
+		first := mustCont(ft, "first", newCounter())
+		second := mustCont(ft, "second", newCounter())
+		r, err := keybinding.New()
+		if err != nil {
+			t.Fatalf("keybinding.New => unexpected error: %v", err)
+		}
+
+		tests := []struct {
+			desc string
+			opts []Option
+		}{
+			{
+				desc: "fails on empty name",
+				opts: []Option{Screen("", first)},
+			},
+			{
+				desc: "fails on duplicate name",
+				opts: []Option{Screen("a", first), Screen("a", second)},
+			},
+			{
+				desc: "fails on nil container",
+				opts: []Option{Screen("a", first), Screen("b", nil)},
+			},
+			{
+				desc: "fails on container used twice",
+				opts: []Option{Screen("a", first), Screen("b", first)},
+			},
+			{
+				desc: "fails when the container of the dashboard isn't a screen",
+				opts: []Option{Screen("b", second)},
+			},
+			{
+				desc: "fails on ScreenKey without KeyBindings",
+				opts: []Option{Screen("a", first, ScreenKey(keybinding.Sequence{keyboard.KeyF1}))},
+			},
+			{
+				desc: "fails on conflicting ScreenKey",
+				opts: []Option{
+					KeyBindings(r),
+					Screen("a", first, ScreenKey(keybinding.Sequence{keyboard.KeyF1})),
+					Screen("b", second, ScreenKey(keybinding.Sequence{keyboard.KeyF1})),
+				},
+			},
+		}
+		for _, tc := range tests {
+			t.Run(tc.desc, func(t *testing.T) {
+				if _, err := NewController(ft, first, tc.opts...); err == nil {
+					t.Errorf("NewController => got nil error, want an error")
+				}
+			})
+		}
+	})
+
+	t.Run("switching", func(t *testing.T) {
+		eq := eventqueue.New()
+		ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eq))
+		overviewW := newCounter()
+		logsW := newCounter()
+		overview := mustCont(ft, "overview", overviewW)
+		logs := mustCont(ft, "logs", logsW)
+
+		var mu sync.Mutex
+		var calls []string
+		record := func(s string) func() {
+			return func() {
+				mu.Lock()
+				defer mu.Unlock()
+				calls = append(calls, s)
+			}
+		}
+		r, err := keybinding.New()
+		if err != nil {
+			t.Fatalf("keybinding.New => unexpected error: %v", err)
+		}
+		ctrl, err := NewController(ft, overview,
+			KeyBindings(r),
+			Screen("overview", overview,
+				ScreenKey(keybinding.Sequence{keyboard.KeyF1}),
+				OnEnter(record("enter overview")),
+				OnLeave(record("leave overview")),
+			),
+			Screen("logs", logs,
+				ScreenKey(keybinding.Sequence{keyboard.KeyF2}),
+				OnEnter(record("enter logs")),
+				OnLeave(record("leave logs")),
+			),
+		)
+		if err != nil {
+			t.Fatalf("NewController => unexpected error: %v", err)
+		}
+		defer ctrl.Close()
+
+		waitForTitle := func(title string) {
+			t.Helper()
+			if err := testevent.WaitFor(5*time.Second, func() error {
+				ctrl.td.mu.Lock()
+				defer ctrl.td.mu.Unlock()
+				if got := ft.String(); !strings.Contains(got, title) {
+					return fmt.Errorf("the screen doesn't contain %q:\n%s", title, got)
+				}
+				return nil
+			}); err != nil {
+				t.Fatalf("testevent.WaitFor => %v", err)
+			}
+		}
+
+		if got, want := ctrl.Screen(), "overview"; got != want {
+			t.Errorf("Screen => %q, want %q", got, want)
+		}
+		if err := ctrl.Redraw(); err != nil {
+			t.Fatalf("Redraw => unexpected error: %v", err)
+		}
+		waitForTitle("overview")
+
+		eq.Push(&terminalapi.Keyboard{Key: 'x'})
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyF2})
+		waitForTitle("logs")
+		if got, want := ctrl.Screen(), "logs"; got != want {
+			t.Errorf("Screen => %q, want %q", got, want)
+		}
+
+		eq.Push(&terminalapi.Keyboard{Key: 'y'})
+		if err := testevent.WaitFor(5*time.Second, func() error {
+			if got := logsW.count(); got != 1 {
+				return fmt.Errorf("the logs widget received %d keys, want 1", got)
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("testevent.WaitFor => %v", err)
+		}
+		if got := overviewW.count(); got != 1 {
+			t.Errorf("the overview widget received %d keys, want 1", got)
+		}
+
+		if err := ctrl.SwitchScreen("overview"); err != nil {
+			t.Fatalf("SwitchScreen => unexpected error: %v", err)
+		}
+		waitForTitle("overview")
+		if err := ctrl.SwitchScreen("unknown"); err == nil {
+			t.Errorf("SwitchScreen => got nil error for an unknown screen, want an error")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		want := []string{"enter overview", "leave overview", "enter logs", "leave logs", "enter overview"}
+		if diff := pretty.Compare(want, calls); diff != "" {
+			t.Errorf("OnEnter and OnLeave => unexpected calls (-want, +got):\n%s", diff)
+		}
+	})
+}
+
+func TestFreezeKey(t *testing.T) {
+	t.Run("validation", func(t *testing.T) {
+		ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New()))
+		cont, err := container.New(ft)
+		if err != nil {
+			t.Fatalf("container.New => unexpected error: %v", err)
+		}
+		g, err := freeze.New()
+		if err != nil {
+			t.Fatalf("freeze.New => unexpected error: %v", err)
+		}
+		newRegistry := func() *keybinding.Registry {
+			r, err := keybinding.New()
+			if err != nil {
+				t.Fatalf("keybinding.New => unexpected error: %v", err)
+			}
+			return r
+		}
+
+		tests := []struct {
+			desc string
+			opts []Option
+		}{
+			{
+				desc: "fails on FreezeKey without KeyBindings",
+				opts: []Option{FreezeKey(g, keybinding.Sequence{keyboard.KeyF1})},
+			},
+			{
+				desc: "fails on nil gate",
+				opts: []Option{
+					KeyBindings(newRegistry()),
+					FreezeKey(nil, keybinding.Sequence{keyboard.KeyF1}),
+				},
+			},
+			{
+				desc: "fails when the FreezeKey conflicts with the HelpKey",
+				opts: []Option{
+					KeyBindings(newRegistry()),
+					HelpKey(keybinding.Sequence{keyboard.KeyF1}),
+					FreezeKey(g, keybinding.Sequence{keyboard.KeyF1}),
+				},
+			},
+		}
+		for _, tc := range tests {
+			t.Run(tc.desc, func(t *testing.T) {
+				if _, err := NewController(ft, cont, tc.opts...); err == nil {
+					t.Errorf("NewController => got nil error, want an error")
+				}
+			})
+		}
+	})
+
+	t.Run("freezes and resumes", func(t *testing.T) {
+		eq := eventqueue.New()
+		ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eq))
+		cont, err := container.New(ft, container.Border(linestyle.Light))
+		if err != nil {
+			t.Fatalf("container.New => unexpected error: %v", err)
+		}
+		g, err := freeze.New()
+		if err != nil {
+			t.Fatalf("freeze.New => unexpected error: %v", err)
+		}
+		r, err := keybinding.New()
+		if err != nil {
+			t.Fatalf("keybinding.New => unexpected error: %v", err)
+		}
+		ctrl, err := NewController(ft, cont, KeyBindings(r), FreezeKey(g, keybinding.Sequence{keyboard.KeyF1}))
+		if err != nil {
+			t.Fatalf("NewController => unexpected error: %v", err)
+		}
+		defer ctrl.Close()
+
+		waitFor := func(desc string, fn func(screen string) bool) {
+			t.Helper()
+			if err := testevent.WaitFor(5*time.Second, func() error {
+				ctrl.td.mu.Lock()
+				defer ctrl.td.mu.Unlock()
+				if got := ft.String(); !fn(got) {
+					return fmt.Errorf("the screen doesn't %s:\n%s", desc, got)
+				}
+				return nil
+			}); err != nil {
+				t.Fatalf("testevent.WaitFor => %v", err)
+			}
+		}
+
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyF1})
+		waitFor("display the indicator", func(screen string) bool {
+			return strings.Contains(screen, freezeIndicator(0, 0))
+		})
+
+		var mu sync.Mutex
+		applied := 0
+		g.Apply(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			applied++
+		})
+		if err := ctrl.Redraw(); err != nil {
+			t.Fatalf("Redraw => unexpected error: %v", err)
+		}
+		waitFor("count the buffered update", func(screen string) bool {
+			return strings.Contains(screen, freezeIndicator(1, 0))
+		})
+		mu.Lock()
+		if applied != 0 {
+			t.Errorf("applied %d updates while frozen, want 0", applied)
+		}
+		mu.Unlock()
+
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyF1})
+		waitFor("remove the indicator", func(screen string) bool {
+			return !strings.Contains(screen, "FROZEN")
+		})
+		mu.Lock()
+		defer mu.Unlock()
+		if applied != 1 {
+			t.Errorf("applied %d updates after resuming, want 1", applied)
+		}
+	})
+}
+
+func TestPrivacyKey(t *testing.T) {
+	t.Run("validation", func(t *testing.T) {
+		ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New()))
+		cont, err := container.New(ft)
+		if err != nil {
+			t.Fatalf("container.New => unexpected error: %v", err)
+		}
+		p := postprocess.NewPrivacy(postprocess.DefaultMask, cont.SensitiveAreas)
+		newRegistry := func() *keybinding.Registry {
+			r, err := keybinding.New()
+			if err != nil {
+				t.Fatalf("keybinding.New => unexpected error: %v", err)
+			}
+			return r
+		}
+
+		tests := []struct {
+			desc string
+			opts []Option
+		}{
+			{
+				desc: "fails on PrivacyKey without KeyBindings",
+				opts: []Option{PrivacyKey(p, keybinding.Sequence{keyboard.KeyF2})},
+			},
+			{
+				desc: "fails on nil privacy mode",
+				opts: []Option{
+					KeyBindings(newRegistry()),
+					PrivacyKey(nil, keybinding.Sequence{keyboard.KeyF2}),
+				},
+			},
+			{
+				desc: "fails when the PrivacyKey conflicts with the HelpKey",
+				opts: []Option{
+					KeyBindings(newRegistry()),
+					HelpKey(keybinding.Sequence{keyboard.KeyF2}),
+					PrivacyKey(p, keybinding.Sequence{keyboard.KeyF2}),
+				},
+			},
+		}
+		for _, tc := range tests {
+			t.Run(tc.desc, func(t *testing.T) {
+				if _, err := NewController(ft, cont, tc.opts...); err == nil {
+					t.Errorf("NewController => got nil error, want an error")
+				}
+			})
+		}
+	})
+
+	t.Run("masks and reveals the sensitive content", func(t *testing.T) {
+		eq := eventqueue.New()
+		ft := faketerm.MustNew(image.Point{20, 5}, faketerm.WithEventQueue(eq))
+		pt := postprocess.New(ft)
+		cont, err := container.New(
+			pt,
+			container.SplitVertical(
+				container.Left(
+					container.Sensitive(),
+					container.PlaceWidget(fakewidget.New(widgetapi.Options{})),
+				),
+				container.Right(
+					container.PlaceWidget(fakewidget.New(widgetapi.Options{})),
+				),
+			),
+		)
+		if err != nil {
+			t.Fatalf("container.New => unexpected error: %v", err)
+		}
+		p := postprocess.NewPrivacy(postprocess.DefaultMask, cont.SensitiveAreas)
+		pt.Add(p.Filter)
+		r, err := keybinding.New()
+		if err != nil {
+			t.Fatalf("keybinding.New => unexpected error: %v", err)
+		}
+		ctrl, err := NewController(pt, cont, KeyBindings(r), PrivacyKey(p, keybinding.Sequence{keyboard.KeyF2}))
+		if err != nil {
+			t.Fatalf("NewController => unexpected error: %v", err)
+		}
+		defer ctrl.Close()
+
+		waitFor := func(desc string, fn func(screen string) bool) {
+			t.Helper()
+			if err := testevent.WaitFor(5*time.Second, func() error {
+				ctrl.td.mu.Lock()
+				defer ctrl.td.mu.Unlock()
+				if got := ft.String(); !fn(got) {
+					return fmt.Errorf("the screen doesn't %s:\n%s", desc, got)
+				}
+				return nil
+			}); err != nil {
+				t.Fatalf("testevent.WaitFor => %v", err)
+			}
+		}
+
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyF2})
+		waitFor("mask the sensitive container", func(screen string) bool {
+			lines := strings.Split(screen, "\n")
+			return p.Enabled() && lines[1] == "*******  *│(10,5)  │"
+		})
+
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyF2})
+		waitFor("reveal the sensitive container", func(screen string) bool {
+			return !p.Enabled() && !strings.Contains(screen, "*")
+		})
+	})
+}
+
+func TestDialogs(t *testing.T) {
+	eq := eventqueue.New()
+	ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eq))
+	kc := &keyCounter{
+		Mirror: fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeGlobal}),
+	}
+	cont, err := container.New(ft, container.PlaceWidget(kc))
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+	ctrl, err := NewController(ft, cont)
+	if err != nil {
+		t.Fatalf("NewController => unexpected error: %v", err)
+	}
+	defer ctrl.Close()
+
+	// waitForText waits until the terminal contains or doesn't contain the
+	// text.
+	waitForText := func(text string, want bool) {
+		t.Helper()
+		if err := testevent.WaitFor(5*time.Second, func() error {
+			ctrl.td.mu.Lock()
+			defer ctrl.td.mu.Unlock()
+			if got := strings.Contains(ft.String(), text); got != want {
+				return fmt.Errorf("the terminal contains %q: %v, want %v:\n%s", text, got, want, ft.String())
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("testevent.WaitFor => %v", err)
+		}
+	}
+	// receive waits for a value or for closing of the channel.
+	receive := func(ch <-chan string) (string, bool) {
+		t.Helper()
+		select {
+		case v, ok := <-ch:
+			return v, ok
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout while waiting for the answer")
+		}
+		return "", false
+	}
+
+	t.Run("confirm", func(t *testing.T) {
+		yes := ctrl.Confirm(context.Background(), "Delete the file?")
+		no := ctrl.Confirm(context.Background(), "Delete the backup?")
+		waitForText("Delete the file?", true)
+		waitForText("Delete the backup?", false)
+
+		eq.Push(&terminalapi.Keyboard{Key: 'x'}) // Ignored.
+		eq.Push(&terminalapi.Keyboard{Key: 'y'})
+		waitForText("Delete the backup?", true)
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyEsc})
+		waitForText("[y]es / [n]o", false)
+
+		if got, ok := <-yes; !got || !ok {
+			t.Errorf("Confirm => got %v, %v, want true, true", got, ok)
+		}
+		if got, ok := <-no; got || !ok {
+			t.Errorf("Confirm => got %v, %v, want false, true", got, ok)
+		}
+		if _, ok := <-yes; ok {
+			t.Errorf("Confirm => the channel wasn't closed after the answer")
+		}
+	})
+
+	t.Run("prompt", func(t *testing.T) {
+		ch := ctrl.Prompt(context.Background(), "Name:")
+		waitForText("Name:", true)
+		for _, k := range []keyboard.Key{'a', 'b', keyboard.KeyBackspace2, 'c'} {
+			eq.Push(&terminalapi.Keyboard{Key: k})
+		}
+		eq.Push(&terminalapi.Paste{Text: "de\n"})
+		waitForText("> acde", true)
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyEnter})
+
+		if got, ok := receive(ch); got != "acde" || !ok {
+			t.Errorf("Prompt => got %q, %v, want %q, true", got, ok, "acde")
+		}
+	})
+
+	t.Run("prompt canceled with escape", func(t *testing.T) {
+		ch := ctrl.Prompt(context.Background(), "Name:")
+		waitForText("Name:", true)
+		eq.Push(&terminalapi.Keyboard{Key: 'a'})
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyEsc})
+
+		if got, ok := receive(ch); ok {
+			t.Errorf("Prompt => got %q, want the channel closed", got)
+		}
+		waitForText("Name:", false)
+	})
+
+	t.Run("dismissed when the context expires", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := ctrl.Prompt(ctx, "Name:")
+		waitForText("Name:", true)
+		cancel()
+
+		if got, ok := receive(ch); ok {
+			t.Errorf("Prompt => got %q, want the channel closed", got)
+		}
+		waitForText("Name:", false)
+	})
+
+	if got := kc.count(); got != 0 {
+		t.Errorf("the widget received %d keys while dialogs were displayed, want 0", got)
+	}
+}
+
+func TestDialogsClosedWithController(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New()))
+	cont, err := container.New(ft)
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+	ctrl, err := NewController(ft, cont)
+	if err != nil {
+		t.Fatalf("NewController => unexpected error: %v", err)
+	}
+	ch := ctrl.Confirm(context.Background(), "Quit?")
+	ctrl.Close()
+
+	select {
+	case got, ok := <-ch:
+		if ok {
+			t.Errorf("Confirm => got %v, want the channel closed", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout while waiting for the channel to close")
+	}
+	if _, ok := <-ctrl.Confirm(context.Background(), "Quit?"); ok {
+		t.Errorf("Confirm on a closed controller => got an answer, want the channel closed")
+	}
+}
+
+func TestMaximizeKey(t *testing.T) {
+	t.Run("fails on MaximizeKey without KeyBindings", func(t *testing.T) {
+		ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New()))
+		cont, err := container.New(ft)
+		if err != nil {
+			t.Fatalf("container.New => unexpected error: %v", err)
+		}
+		if _, err := NewController(ft, cont, MaximizeKey(keybinding.Sequence{keyboard.KeyF3})); err == nil {
+			t.Errorf("NewController => got nil error, want an error")
+		}
+	})
+
+	t.Run("maximizes and restores the focused container", func(t *testing.T) {
+		eq := eventqueue.New()
+		ft := faketerm.MustNew(image.Point{20, 5}, faketerm.WithEventQueue(eq))
+		cont, err := container.New(
+			ft,
+			container.SplitVertical(
+				container.Left(
+					container.PlaceWidget(fakewidget.New(widgetapi.Options{})),
+				),
+				container.Right(
+					container.PlaceWidget(fakewidget.New(widgetapi.Options{})),
+				),
+			),
+		)
+		if err != nil {
+			t.Fatalf("container.New => unexpected error: %v", err)
+		}
+		r, err := keybinding.New()
+		if err != nil {
+			t.Fatalf("keybinding.New => unexpected error: %v", err)
+		}
+		ctrl, err := NewController(ft, cont, KeyBindings(r), MaximizeKey(keybinding.Sequence{keyboard.KeyF3}))
+		if err != nil {
+			t.Fatalf("NewController => unexpected error: %v", err)
+		}
+		defer ctrl.Close()
+
+		waitFor := func(desc string, fn func(screen string) bool) {
+			t.Helper()
+			if err := testevent.WaitFor(5*time.Second, func() error {
+				ctrl.td.mu.Lock()
+				defer ctrl.td.mu.Unlock()
+				if got := ft.String(); !fn(got) {
+					return fmt.Errorf("the screen doesn't %s:\n%s", desc, got)
+				}
+				return nil
+			}); err != nil {
+				t.Fatalf("testevent.WaitFor => %v", err)
+			}
+		}
+
+		// Focus the right container.
+		eq.Push(&terminalapi.Mouse{Position: image.Point{11, 1}, Button: mouse.ButtonLeft})
+		eq.Push(&terminalapi.Mouse{Position: image.Point{11, 1}, Button: mouse.ButtonRelease})
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyF3})
+		waitFor("display the maximized container", func(screen string) bool {
+			_, ok := cont.Maximized()
+			return ok && strings.Contains(screen, "(20,5)")
+		})
+
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyF3})
+		waitFor("display both containers", func(screen string) bool {
+			_, ok := cont.Maximized()
+			return !ok && strings.Count(screen, "(10,5)") == 2
+		})
+	})
+}
+
+func TestWorkspaceKeys(t *testing.T) {
+	newSlots := func() *workspace.Slots {
+		s, err := workspace.New(nil)
+		if err != nil {
+			t.Fatalf("workspace.New => unexpected error: %v", err)
+		}
+		return s
+	}
+	newRegistry := func() *keybinding.Registry {
+		r, err := keybinding.New()
+		if err != nil {
+			t.Fatalf("keybinding.New => unexpected error: %v", err)
+		}
+		return r
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		ft := faketerm.MustNew(image.Point{60, 10}, faketerm.WithEventQueue(eventqueue.New()))
+		cont, err := container.New(ft)
+		if err != nil {
+			t.Fatalf("container.New => unexpected error: %v", err)
+		}
+
+		tests := []struct {
+			desc string
+			opts []Option
+		}{
+			{
+				desc: "fails on WorkspaceKeys without KeyBindings",
+				opts: []Option{WorkspaceKeys(newSlots(), keybinding.Sequence{keyboard.KeyF4}, keybinding.Sequence{keyboard.KeyF5})},
+			},
+			{
+				desc: "fails on nil slots",
+				opts: []Option{
+					KeyBindings(newRegistry()),
+					WorkspaceKeys(nil, keybinding.Sequence{keyboard.KeyF4}, keybinding.Sequence{keyboard.KeyF5}),
+				},
+			},
+			{
+				desc: "fails without the restore sequence",
+				opts: []Option{
+					KeyBindings(newRegistry()),
+					WorkspaceKeys(newSlots(), keybinding.Sequence{keyboard.KeyF4}, nil),
+				},
+			},
+			{
+				desc: "fails when the save and restore sequences are the same",
+				opts: []Option{
+					KeyBindings(newRegistry()),
+					WorkspaceKeys(newSlots(), keybinding.Sequence{keyboard.KeyF4}, keybinding.Sequence{keyboard.KeyF4}),
+				},
+			},
+		}
+		for _, tc := range tests {
+			t.Run(tc.desc, func(t *testing.T) {
+				if _, err := NewController(ft, cont, tc.opts...); err == nil {
+					t.Errorf("NewController => got nil error, want an error")
+				}
+			})
+		}
+	})
+
+	t.Run("saves and restores the arrangement", func(t *testing.T) {
+		eq := eventqueue.New()
+		ft := faketerm.MustNew(image.Point{20, 5}, faketerm.WithEventQueue(eq))
+		cont, err := container.New(
+			ft,
+			container.SplitVertical(
+				container.Left(
+					container.PlaceWidget(fakewidget.New(widgetapi.Options{})),
+				),
+				container.Right(
+					container.ID("right"),
+					container.PlaceWidget(fakewidget.New(widgetapi.Options{})),
+				),
+			),
+		)
+		if err != nil {
+			t.Fatalf("container.New => unexpected error: %v", err)
+		}
+		s := newSlots()
+		ctrl, err := NewController(ft, cont,
+			KeyBindings(newRegistry()),
+			WorkspaceKeys(s, keybinding.Sequence{keyboard.KeyF4}, keybinding.Sequence{keyboard.KeyF5}),
+		)
+		if err != nil {
+			t.Fatalf("NewController => unexpected error: %v", err)
+		}
+		defer ctrl.Close()
+
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyF4})
+		eq.Push(&terminalapi.Keyboard{Key: '2'})
+		if err := testevent.WaitFor(5*time.Second, func() error {
+			if got := s.Saved(); len(got) != 1 || got[0] != 2 {
+				return fmt.Errorf("Saved => %v, want [2]", got)
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("testevent.WaitFor => %v", err)
+		}
+
+		if err := cont.Maximize("right"); err != nil {
+			t.Fatalf("Maximize => unexpected error: %v", err)
+		}
+		eq.Push(&terminalapi.Keyboard{Key: keyboard.KeyF5})
+		eq.Push(&terminalapi.Keyboard{Key: '2'})
+		if err := testevent.WaitFor(5*time.Second, func() error {
+			ctrl.td.mu.Lock()
+			defer ctrl.td.mu.Unlock()
+			if _, ok := cont.Maximized(); ok {
+				return fmt.Errorf("the container is still maximized")
+			}
+			if got := ft.String(); strings.Count(got, "(10,5)") != 2 {
+				return fmt.Errorf("the screen doesn't display both containers:\n%s", got)
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("testevent.WaitFor => %v", err)
+		}
+	})
+}
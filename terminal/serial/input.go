@@ -0,0 +1,325 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+// input.go parses the input bytes into termdash events.
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// controlKeys maps the control characters to keys.
+var controlKeys = map[byte]keyboard.Key{
+	0x00: keyboard.KeyCtrlSpace,
+	0x01: keyboard.KeyCtrlA,
+	0x02: keyboard.KeyCtrlB,
+	0x03: keyboard.KeyCtrlC,
+	0x04: keyboard.KeyCtrlD,
+	0x05: keyboard.KeyCtrlE,
+	0x06: keyboard.KeyCtrlF,
+	0x07: keyboard.KeyCtrlG,
+	0x08: keyboard.KeyBackspace,
+	0x09: keyboard.KeyTab,
+	0x0a: keyboard.KeyCtrlJ,
+	0x0b: keyboard.KeyCtrlK,
+	0x0c: keyboard.KeyCtrlL,
+	0x0d: keyboard.KeyEnter,
+	0x0e: keyboard.KeyCtrlN,
+	0x0f: keyboard.KeyCtrlO,
+	0x10: keyboard.KeyCtrlP,
+	0x11: keyboard.KeyCtrlQ,
+	0x12: keyboard.KeyCtrlR,
+	0x13: keyboard.KeyCtrlS,
+	0x14: keyboard.KeyCtrlT,
+	0x15: keyboard.KeyCtrlU,
+	0x16: keyboard.KeyCtrlV,
+	0x17: keyboard.KeyCtrlW,
+	0x18: keyboard.KeyCtrlX,
+	0x19: keyboard.KeyCtrlY,
+	0x1a: keyboard.KeyCtrlZ,
+	0x1c: keyboard.KeyCtrlBackslash,
+	0x1d: keyboard.KeyCtrlRsqBracket,
+	0x1e: keyboard.KeyCtrl6,
+	0x1f: keyboard.KeyCtrlUnderscore,
+	0x7f: keyboard.KeyBackspace2,
+}
+
+// finalKeys maps the final bytes of the CSI and SS3 sequences to keys, these
+// are sent by the cursor and the keypad keys.
+var finalKeys = map[byte]keyboard.Key{
+	'A': keyboard.KeyArrowUp,
+	'B': keyboard.KeyArrowDown,
+	'C': keyboard.KeyArrowRight,
+	'D': keyboard.KeyArrowLeft,
+	'H': keyboard.KeyHome,
+	'F': keyboard.KeyEnd,
+	'M': keyboard.KeyEnter,
+	'P': keyboard.KeyF1,
+	'Q': keyboard.KeyF2,
+	'R': keyboard.KeyF3,
+	'S': keyboard.KeyF4,
+}
+
+// tildeKeys maps the parameters of the "CSI n ~" sequences to keys. These
+// aren't sent by the VT100 itself, but by most of its successors.
+var tildeKeys = map[int]keyboard.Key{
+	1:  keyboard.KeyHome,
+	2:  keyboard.KeyInsert,
+	3:  keyboard.KeyDelete,
+	4:  keyboard.KeyEnd,
+	5:  keyboard.KeyPgUp,
+	6:  keyboard.KeyPgDn,
+	7:  keyboard.KeyHome,
+	8:  keyboard.KeyEnd,
+	11: keyboard.KeyF1,
+	12: keyboard.KeyF2,
+	13: keyboard.KeyF3,
+	14: keyboard.KeyF4,
+	15: keyboard.KeyF5,
+	17: keyboard.KeyF6,
+	18: keyboard.KeyF7,
+	19: keyboard.KeyF8,
+	20: keyboard.KeyF9,
+	21: keyboard.KeyF10,
+	23: keyboard.KeyF11,
+	24: keyboard.KeyF12,
+}
+
+// maxSeqLen is the maximum length of the parameters of an escape sequence.
+// Longer sequences are considered corrupted and discarded.
+const maxSeqLen = 16
+
+// pasteStartParams are the parameters of the "CSI n ~" sequence that starts
+// pasted text in the bracketed paste mode, see BracketedPaste.
+const pasteStartParams = "200"
+
+// pasteEndSeq is the sequence that ends pasted text.
+var pasteEndSeq = []byte("\x1b[201~")
+
+// maxPasteLen is the maximum number of bytes of pasted text reported in a
+// single Paste event. Longer text is split into multiple events.
+const maxPasteLen = 64 * 1024
+
+// parserState is the state of the parser.
+type parserState int
+
+// The states of the parser.
+const (
+	// stateGround expects a new key.
+	stateGround parserState = iota
+	// stateEsc follows the escape byte.
+	stateEsc
+	// stateCSI collects the control sequence introduced by "ESC [".
+	stateCSI
+	// stateSS3 expects the key following "ESC O".
+	stateSS3
+	// stateUTF8 collects the bytes of a multi-byte UTF-8 character.
+	stateUTF8
+	// stateDiscard skips the rest of an overlong control sequence.
+	stateDiscard
+	// statePaste collects pasted text until the end of the bracketed paste.
+	statePaste
+)
+
+// parser parses the input bytes into events.
+// This object is not thread-safe.
+type parser struct {
+	state parserState
+	// seq are the bytes of the escape sequence, the character or the pasted
+	// text being collected.
+	seq []byte
+}
+
+// pending determines if the parser waits for more bytes to complete an escape
+// sequence or a character.
+func (p *parser) pending() bool {
+	return p.state != stateGround
+}
+
+// expire is called when no more bytes arrived within the escape timeout.
+// Reports a lone escape byte as the Esc key and the pasted text collected so
+// far as a Paste event, since the end of the paste might have been lost.
+// Discards anything else that is incomplete.
+func (p *parser) expire() []terminalapi.Event {
+	var evs []terminalapi.Event
+	switch p.state {
+	case stateEsc:
+		evs = append(evs, &terminalapi.Keyboard{Key: keyboard.KeyEsc})
+	case statePaste:
+		if len(p.seq) > 0 {
+			evs = append(evs, &terminalapi.Paste{Text: string(p.seq)})
+		}
+	}
+	p.reset()
+	return evs
+}
+
+// reset returns the parser to the ground state.
+func (p *parser) reset() {
+	p.state = stateGround
+	p.seq = p.seq[:0]
+}
+
+// feed parses the bytes and returns the complete events.
+func (p *parser) feed(bs []byte) []terminalapi.Event {
+	var evs []terminalapi.Event
+	for _, b := range bs {
+		evs = append(evs, p.feedByte(b)...)
+	}
+	return evs
+}
+
+// feedByte parses a single byte.
+func (p *parser) feedByte(b byte) []terminalapi.Event {
+	switch p.state {
+	case stateEsc:
+		switch b {
+		case '[':
+			p.state = stateCSI
+			return nil
+		case 'O':
+			p.state = stateSS3
+			return nil
+		}
+		// Not a sequence, the escape key was pressed on its own.
+		p.reset()
+		return append([]terminalapi.Event{&terminalapi.Keyboard{Key: keyboard.KeyEsc}}, p.feedByte(b)...)
+
+	case stateCSI, stateDiscard:
+		switch {
+		case b >= 0x20 && b <= 0x3f:
+			if len(p.seq) == maxSeqLen {
+				p.state = stateDiscard
+			}
+			if p.state == stateCSI {
+				p.seq = append(p.seq, b)
+			}
+			return nil
+		case b >= 0x40 && b <= 0x7e:
+			if p.state == stateCSI && b == '~' && string(p.seq) == pasteStartParams {
+				p.reset()
+				p.state = statePaste
+				return nil
+			}
+			k, ok := csiKey(string(p.seq), b)
+			if p.state == stateDiscard {
+				ok = false
+			}
+			p.reset()
+			if !ok {
+				return nil
+			}
+			return []terminalapi.Event{&terminalapi.Keyboard{Key: k}}
+		}
+		// The sequence is corrupted, probably some bytes were lost. Drop it
+		// and start over from this byte.
+		p.reset()
+		if b == 0x1b || b < 0x20 || b == 0x7f {
+			return p.feedByte(b)
+		}
+		return nil
+
+	case stateSS3:
+		k, ok := finalKeys[b]
+		p.reset()
+		if ok {
+			return []terminalapi.Event{&terminalapi.Keyboard{Key: k}}
+		}
+		if b == 0x1b || b < 0x20 {
+			return p.feedByte(b)
+		}
+		return nil
+
+	case statePaste:
+		p.seq = append(p.seq, b)
+		if bytes.HasSuffix(p.seq, pasteEndSeq) {
+			text := string(p.seq[:len(p.seq)-len(pasteEndSeq)])
+			p.reset()
+			if text == "" {
+				return nil
+			}
+			return []terminalapi.Event{&terminalapi.Paste{Text: text}}
+		}
+		if len(p.seq) < maxPasteLen {
+			return nil
+		}
+		// Keep the bytes that might be the start of the end sequence.
+		keep := len(pasteEndSeq) - 1
+		text := string(p.seq[:len(p.seq)-keep])
+		p.seq = append(p.seq[:0], p.seq[len(p.seq)-keep:]...)
+		return []terminalapi.Event{&terminalapi.Paste{Text: text}}
+
+	case stateUTF8:
+		if !utf8.RuneStart(b) {
+			p.seq = append(p.seq, b)
+			if !utf8.FullRune(p.seq) {
+				return nil
+			}
+			r, _ := utf8.DecodeRune(p.seq)
+			p.reset()
+			if r == utf8.RuneError {
+				return nil
+			}
+			return []terminalapi.Event{&terminalapi.Keyboard{Key: keyboard.Key(r)}}
+		}
+		// The character is incomplete, drop it.
+		p.reset()
+		return p.feedByte(b)
+	}
+
+	switch {
+	case b == 0x1b:
+		p.state = stateEsc
+		return nil
+	case b < 0x20 || b == 0x7f:
+		if k, ok := controlKeys[b]; ok {
+			return []terminalapi.Event{&terminalapi.Keyboard{Key: k}}
+		}
+		return nil
+	case b < utf8.RuneSelf:
+		return []terminalapi.Event{&terminalapi.Keyboard{Key: keyboard.Key(b)}}
+	case utf8.RuneStart(b):
+		p.state = stateUTF8
+		p.seq = append(p.seq, b)
+		return nil
+	}
+	// A continuation byte of a character whose first byte was lost.
+	return nil
+}
+
+// csiKey returns the key sent as the control sequence with the parameters
+// and the final byte.
+func csiKey(params string, final byte) (keyboard.Key, bool) {
+	if final == '~' {
+		// Any modifiers follow the key number after a semicolon.
+		n, err := strconv.Atoi(strings.SplitN(params, ";", 2)[0])
+		if err != nil {
+			return 0, false
+		}
+		k, ok := tildeKeys[n]
+		return k, ok
+	}
+	if final == 'Z' {
+		return keyboard.KeyTab, true // Shift+Tab.
+	}
+	k, ok := finalKeys[final]
+	return k, ok
+}
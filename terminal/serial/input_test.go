@@ -0,0 +1,193 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+func TestParser(t *testing.T) {
+	tests := []struct {
+		desc  string
+		input []string
+		// expire indicates that the escape timeout expires after the input.
+		expire bool
+		want   []terminalapi.Event
+	}{
+		{
+			desc:  "printable characters",
+			input: []string{"aZ "},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: 'Z'},
+				&terminalapi.Keyboard{Key: keyboard.KeySpace},
+			},
+		},
+		{
+			desc:  "control characters",
+			input: []string{"\r\t\x03\x7f\x08"},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				&terminalapi.Keyboard{Key: keyboard.KeyTab},
+				&terminalapi.Keyboard{Key: keyboard.KeyCtrlC},
+				&terminalapi.Keyboard{Key: keyboard.KeyBackspace2},
+				&terminalapi.Keyboard{Key: keyboard.KeyBackspace},
+			},
+		},
+		{
+			desc:  "UTF-8 character split across reads",
+			input: []string{"\xc3", "\xa9"},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'é'},
+			},
+		},
+		{
+			desc:  "cursor keys in both modes",
+			input: []string{"\x1b[A\x1bOB\x1b[C\x1bOD"},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowUp},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+			},
+		},
+		{
+			desc:  "function and editing keys",
+			input: []string{"\x1bOP\x1b[15~\x1b[3~\x1b[6;5~"},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyF1},
+				&terminalapi.Keyboard{Key: keyboard.KeyF5},
+				&terminalapi.Keyboard{Key: keyboard.KeyDelete},
+				&terminalapi.Keyboard{Key: keyboard.KeyPgDn},
+			},
+		},
+		{
+			desc:  "sequence split across reads",
+			input: []string{"\x1b", "[", "2~"},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyInsert},
+			},
+		},
+		{
+			desc:   "lone escape reported after the timeout",
+			input:  []string{"\x1b"},
+			expire: true,
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEsc},
+			},
+		},
+		{
+			desc:  "escape followed by a character",
+			input: []string{"\x1bx"},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEsc},
+				&terminalapi.Keyboard{Key: 'x'},
+			},
+		},
+		{
+			desc:   "incomplete sequence discarded after the timeout",
+			input:  []string{"\x1b[1"},
+			expire: true,
+		},
+		{
+			desc:  "sequence interrupted by a lost byte",
+			input: []string{"\x1b[1\r"},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+			},
+		},
+		{
+			desc:  "overlong sequence discarded",
+			input: []string{"\x1b[11111111111111111111~a"},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+			},
+		},
+		{
+			desc:  "unknown sequence ignored",
+			input: []string{"\x1b[99~\x1b[Xb"},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'b'},
+			},
+		},
+		{
+			desc:  "bracketed paste",
+			input: []string{"\x1b[200~hé\x1b[A\r\x1b[201~x"},
+			want: []terminalapi.Event{
+				&terminalapi.Paste{Text: "hé\x1b[A\r"},
+				&terminalapi.Keyboard{Key: 'x'},
+			},
+		},
+		{
+			desc:  "bracketed paste split across reads",
+			input: []string{"\x1b[20", "0~ab\x1b[2", "01~"},
+			want: []terminalapi.Event{
+				&terminalapi.Paste{Text: "ab"},
+			},
+		},
+		{
+			desc:  "empty bracketed paste ignored",
+			input: []string{"\x1b[200~\x1b[201~"},
+		},
+		{
+			desc:   "bracketed paste without the end reported after the timeout",
+			input:  []string{"\x1b[200~ab"},
+			expire: true,
+			want: []terminalapi.Event{
+				&terminalapi.Paste{Text: "ab"},
+			},
+		},
+		{
+			desc:  "long bracketed paste split into multiple events",
+			input: []string{"\x1b[200~" + strings.Repeat("a", maxPasteLen) + "b\x1b[201~"},
+			want: []terminalapi.Event{
+				&terminalapi.Paste{Text: strings.Repeat("a", maxPasteLen-len(pasteEndSeq)+1)},
+				&terminalapi.Paste{Text: strings.Repeat("a", len(pasteEndSeq)-1) + "b"},
+			},
+		},
+		{
+			desc:  "invalid UTF-8 bytes dropped",
+			input: []string{"\xa9a\xc3b"},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: 'b'},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := &parser{}
+			var got []terminalapi.Event
+			for _, in := range tc.input {
+				got = append(got, p.feed([]byte(in))...)
+			}
+			if tc.expire {
+				got = append(got, p.expire()...)
+			}
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("feed => unexpected diff (-want, +got):\n%s", diff)
+			}
+			if p.pending() {
+				t.Errorf("pending => got true, want false")
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+// output.go encodes the output into the VT100 escape sequences.
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/mum4k/termdash/cell"
+)
+
+const (
+	// resetSeq resets all the attributes.
+	resetSeq = "\x1b[0m"
+	// clearSeq clears the entire screen.
+	clearSeq = "\x1b[2J"
+	// pasteOnSeq and pasteOffSeq enable and disable the bracketed paste
+	// mode.
+	pasteOnSeq  = "\x1b[?2004h"
+	pasteOffSeq = "\x1b[?2004l"
+)
+
+// cursorSeq returns the sequence that moves the cursor to the point.
+func cursorSeq(p image.Point) string {
+	// The VT100 coordinates are one based.
+	return fmt.Sprintf("\x1b[%d;%dH", p.Y+1, p.X+1)
+}
+
+// attrSeq returns the sequence that sets all the attributes of a cell to the
+// options. The sequence always resets the attributes first, so it doesn't
+// depend on any previous state of the console.
+func attrSeq(o *cell.Options, colors bool) string {
+	params := []string{"0"}
+	switch {
+	case colors:
+		if n, ok := ansiColor(o.FgColor); ok {
+			params = append(params, fmt.Sprintf("%d", 30+n))
+		}
+		if n, ok := ansiColor(o.BgColor); ok {
+			params = append(params, fmt.Sprintf("%d", 40+n))
+		}
+	case o.BgColor != cell.ColorDefault:
+		// Reverse video keeps highlighted cells, e.g. selected items,
+		// distinguishable on a monochrome console.
+		params = append(params, "7")
+	}
+	return fmt.Sprintf("\x1b[%sm", strings.Join(params, ";"))
+}
+
+// ansiColor returns the number of the ANSI color, i.e. 0-7, for the color.
+// Returns false if the color is the default color or has no ANSI equivalent.
+func ansiColor(c cell.Color) (int, bool) {
+	// The colors are off-by-one due to ColorDefault being zero, the bright
+	// colors 8-15 map to their normal counterparts.
+	if c <= cell.ColorDefault || c > cell.ColorNumber(15) {
+		return 0, false
+	}
+	return int(c-cell.ColorBlack) % 8, true
+}
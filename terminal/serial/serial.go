@@ -0,0 +1,385 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package serial implements a terminal for serial consoles and other dumb
+// terminals that only understand the VT100 escape sequences.
+//
+// The terminal has a fixed size provided by the caller and doesn't report
+// mouse events. The output positions the cursor absolutely at the start of
+// every run of written cells and sets all the attributes again, so that a
+// byte dropped on the line corrupts at most one run. The input is parsed
+// defensively, incomplete and unknown escape sequences are discarded.
+package serial
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/doublebuffer"
+	"github.com/mum4k/termdash/private/event/eventqueue"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*Terminal)
+}
+
+// option implements Option.
+type option func(*Terminal)
+
+// set implements Option.set.
+func (o option) set(t *Terminal) {
+	o(t)
+}
+
+// Colors enables the eight ANSI colors, which most VT100 compatible terminals
+// support although the VT100 itself doesn't. The bright colors are displayed
+// as their normal counterparts, other colors as the default color.
+// Without this option the terminal is monochrome and displays cells with a
+// background color in reverse video.
+func Colors() Option {
+	return option(func(t *Terminal) {
+		t.colors = true
+	})
+}
+
+// Unicode sets the unicode characters the terminal can display. Characters
+// outside of ASCII are sent encoded in UTF-8.
+// Defaults to terminalapi.UnicodeASCII.
+func Unicode(ul terminalapi.UnicodeLevel) Option {
+	return option(func(t *Terminal) {
+		t.unicode = ul
+	})
+}
+
+// BracketedPaste enables the bracketed paste mode of terminals that support
+// it, e.g. terminal emulators connected to the serial line. Text pasted into
+// such a terminal is reported as a single Paste event instead of one
+// Keyboard event per character. Terminals that don't support the mode ignore
+// it and the pasted text arrives as Keyboard events.
+func BracketedPaste() Option {
+	return option(func(t *Terminal) {
+		t.paste = true
+	})
+}
+
+// DefaultEscapeTimeout is the default value for the EscapeTimeout option.
+const DefaultEscapeTimeout = 100 * time.Millisecond
+
+// EscapeTimeout is the time the terminal waits for the rest of an escape
+// sequence. A lone escape byte is reported as the Esc key after the timeout,
+// an incomplete escape sequence is discarded.
+// Defaults to DefaultEscapeTimeout.
+func EscapeTimeout(d time.Duration) Option {
+	return option(func(t *Terminal) {
+		t.escTimeout = d
+	})
+}
+
+// RepaintEvery makes every n-th call to Flush write all the cells, which
+// repairs the screen if any bytes were lost on the line. Defaults to zero,
+// which only writes the changed cells.
+func RepaintEvery(n int) Option {
+	return option(func(t *Terminal) {
+		t.repaintEvery = n
+	})
+}
+
+// Terminal provides input and output to a serial console. This object is not
+// thread-safe.
+// Implements terminalapi.Terminal.
+type Terminal struct {
+	// rw is the connection to the console.
+	rw io.ReadWriter
+
+	// size is the fixed size of the terminal.
+	size image.Point
+
+	// events is a queue of input events.
+	events *eventqueue.Unbound
+
+	// done gets closed when Close() is called.
+	done chan struct{}
+
+	// buf is the double buffer the cells are drawn into, only the changed
+	// cells are written to the console on Flush.
+	buf *doublebuffer.Buffer
+
+	// cleared indicates that the console must be cleared on the next Flush.
+	cleared bool
+	// flushes counts the calls to Flush.
+	flushes int
+
+	// cursor is the position of the cursor or nil if it is hidden.
+	cursor *image.Point
+
+	// Options.
+	colors       bool
+	unicode      terminalapi.UnicodeLevel
+	escTimeout   time.Duration
+	repaintEvery int
+	paste        bool
+}
+
+// newTerminal creates the terminal and applies the options.
+func newTerminal(rw io.ReadWriter, size image.Point, opts ...Option) (*Terminal, error) {
+	if size.X <= 0 || size.Y <= 0 {
+		return nil, fmt.Errorf("invalid terminal size %v, both dimensions must be positive", size)
+	}
+	t := &Terminal{
+		rw:         rw,
+		size:       size,
+		events:     eventqueue.New(),
+		done:       make(chan struct{}),
+		cleared:    true,
+		unicode:    terminalapi.UnicodeASCII,
+		escTimeout: DefaultEscapeTimeout,
+	}
+	for _, opt := range opts {
+		opt.set(t)
+	}
+	if t.escTimeout <= 0 {
+		return nil, fmt.Errorf("invalid EscapeTimeout(%v), must be positive", t.escTimeout)
+	}
+	if t.repaintEvery < 0 {
+		return nil, fmt.Errorf("invalid RepaintEvery(%d), must be zero or positive", t.repaintEvery)
+	}
+
+	buf, err := doublebuffer.New(size)
+	if err != nil {
+		return nil, err
+	}
+	t.buf = buf
+	return t, nil
+}
+
+// New returns a new Terminal of the specified size that reads input from and
+// writes output to the provided connection, e.g. an opened serial port.
+// Call Close() when the terminal isn't required anymore. The caller remains
+// responsible for closing the connection afterwards.
+func New(rw io.ReadWriter, size image.Point, opts ...Option) (*Terminal, error) {
+	t, err := newTerminal(rw, size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	go t.pollEvents() // Stops when Close() is called.
+	return t, nil
+}
+
+// Size implements terminalapi.Terminal.Size.
+func (t *Terminal) Size() image.Point {
+	return t.size
+}
+
+// Clear implements terminalapi.Terminal.Clear.
+func (t *Terminal) Clear(opts ...cell.Option) error {
+	t.buf.Clear(opts...)
+	t.buf.Invalidate()
+	t.cleared = true
+	return nil
+}
+
+// Flush implements terminalapi.Terminal.Flush.
+func (t *Terminal) Flush() error {
+	t.flushes++
+	if t.repaintEvery > 0 && t.flushes%t.repaintEvery == 0 {
+		t.buf.Invalidate()
+	}
+
+	var out bytes.Buffer
+	cleared := t.cleared
+	if cleared {
+		out.WriteString(resetSeq + clearSeq)
+		if t.paste {
+			// Enabled with every clear, so that a terminal that was reset
+			// meanwhile reports pastes again.
+			out.WriteString(pasteOnSeq)
+		}
+		t.cleared = false
+	}
+	next := image.Point{-1, -1} // The position the cursor moves to.
+	if _, err := t.buf.Flush(func(p image.Point, r rune, combining []rune, o *cell.Options) error {
+		if cleared && (r == 0 || r == ' ') && *o == (cell.Options{}) {
+			return nil // The cell is already blank after the clear.
+		}
+		if p != next {
+			out.WriteString(cursorSeq(p))
+			out.WriteString(attrSeq(o, t.colors))
+		}
+		if r == 0 || (r > 0x7e && t.unicode == terminalapi.UnicodeASCII) || r < ' ' {
+			r = ' '
+		}
+		out.WriteRune(r)
+		rw := runewidth.RuneWidth(r)
+		if r != ' ' && t.unicode == terminalapi.UnicodeFull {
+			// The combining characters follow the rune on the line.
+			for _, cr := range combining {
+				out.WriteRune(cr)
+			}
+			rw = runewidth.CellWidth(r, combining)
+		}
+		next = image.Point{p.X + rw, p.Y}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if t.cursor != nil {
+		out.WriteString(cursorSeq(*t.cursor))
+	} else {
+		// The VT100 cannot hide the cursor, park it in the bottom right
+		// corner.
+		out.WriteString(cursorSeq(image.Point{t.size.X - 1, t.size.Y - 1}))
+	}
+	if _, err := t.rw.Write(out.Bytes()); err != nil {
+		return fmt.Errorf("writing to the console failed: %v", err)
+	}
+	return nil
+}
+
+// SetCursor implements terminalapi.Terminal.SetCursor.
+func (t *Terminal) SetCursor(p image.Point) {
+	t.cursor = &p
+}
+
+// HideCursor implements terminalapi.Terminal.HideCursor.
+func (t *Terminal) HideCursor() {
+	t.cursor = nil
+}
+
+// SetCell implements terminalapi.Terminal.SetCell.
+func (t *Terminal) SetCell(p image.Point, r rune, opts ...cell.Option) error {
+	if !p.In(image.Rectangle{Max: t.size}) {
+		return nil // Like the other terminals, ignore cells outside of the terminal.
+	}
+	return t.buf.SetCell(p, r, opts...)
+}
+
+// SetCellCombining implements terminalapi.Combiner.SetCellCombining.
+// The combining characters are only displayed with the UnicodeFull level.
+func (t *Terminal) SetCellCombining(p image.Point, r rune, combining []rune, opts ...cell.Option) error {
+	if !p.In(image.Rectangle{Max: t.size}) {
+		return nil // Like the other terminals, ignore cells outside of the terminal.
+	}
+	return t.buf.SetCellCombining(p, r, combining, opts...)
+}
+
+// pollEvents reads the input and enqueues the parsed events.
+func (t *Terminal) pollEvents() {
+	input := make(chan []byte)
+	go t.readInput(input) // Stops when reading fails or Close() is called.
+
+	p := &parser{}
+	timeout := time.NewTimer(t.escTimeout)
+	timeout.Stop()
+	for {
+		select {
+		case <-t.done:
+			timeout.Stop()
+			return
+
+		case b, ok := <-input:
+			if !ok {
+				return
+			}
+			if !timeout.Stop() {
+				// Drain the channel if the timer expired meanwhile.
+				select {
+				case <-timeout.C:
+				default:
+				}
+			}
+			for _, ev := range p.feed(b) {
+				t.events.Push(ev)
+			}
+			if p.pending() {
+				timeout.Reset(t.escTimeout)
+			}
+
+		case <-timeout.C:
+			for _, ev := range p.expire() {
+				t.events.Push(ev)
+			}
+		}
+	}
+}
+
+// readInput reads from the connection and sends the read bytes to the
+// channel. Closes the channel when reading fails.
+func (t *Terminal) readInput(input chan<- []byte) {
+	defer close(input)
+	buf := make([]byte, 256)
+	for {
+		n, err := t.rw.Read(buf)
+		if n > 0 {
+			b := make([]byte, n)
+			copy(b, buf[:n])
+			select {
+			case input <- b:
+			case <-t.done:
+				return
+			}
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.events.Push(terminalapi.NewErrorf("reading from the console failed: %v", err))
+			}
+			return
+		}
+	}
+}
+
+// Event implements terminalapi.Terminal.Event.
+func (t *Terminal) Event(ctx context.Context) terminalapi.Event {
+	ev := t.events.Pull(ctx)
+	if ev == nil {
+		return nil
+	}
+	return ev
+}
+
+// Capabilities implements terminalapi.Terminal.Capabilities.
+func (t *Terminal) Capabilities() terminalapi.Capabilities {
+	depth := 2
+	if t.colors {
+		depth = 8
+	}
+	return terminalapi.Capabilities{
+		ColorDepth: depth,
+		Unicode:    t.unicode,
+		Paste:      t.paste,
+	}
+}
+
+// Close closes the terminal, should be called when the terminal isn't required
+// anymore to return the screen to a sane state.
+// Implements terminalapi.Terminal.Close.
+func (t *Terminal) Close() {
+	close(t.done)
+	seq := resetSeq + clearSeq + cursorSeq(image.Point{})
+	if t.paste {
+		seq += pasteOffSeq
+	}
+	// There is nobody to report a failure to at this point.
+	t.rw.Write([]byte(seq))
+}
@@ -0,0 +1,283 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// conn is a fake connection to a console.
+type conn struct {
+	io.Reader
+	bytes.Buffer
+}
+
+// Read implements io.Reader.Read.
+func (c *conn) Read(p []byte) (int, error) {
+	return c.Reader.Read(p)
+}
+
+func TestNewTerminal(t *testing.T) {
+	tests := []struct {
+		desc    string
+		size    image.Point
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			desc: "default options",
+			size: image.Point{80, 24},
+		},
+		{
+			desc:    "fails on zero size",
+			size:    image.Point{80, 0},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on zero escape timeout",
+			size:    image.Point{80, 24},
+			opts:    []Option{EscapeTimeout(0)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on negative RepaintEvery",
+			size:    image.Point{80, 24},
+			opts:    []Option{RepaintEvery(-1)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := newTerminal(&conn{}, tc.size, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("newTerminal => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		// prepare if not nil is called before the flush whose output is
+		// checked.
+		prepare func(*Terminal) error
+		draw    func(*Terminal) error
+		want    string
+	}{
+		{
+			desc: "clears the console on the first flush",
+			draw: func(t *Terminal) error {
+				return t.SetCell(image.Point{1, 0}, 'a')
+			},
+			want: "\x1b[0m\x1b[2J" +
+				"\x1b[1;2H\x1b[0ma" +
+				"\x1b[2;4H",
+		},
+		{
+			desc: "writes runs of changed cells",
+			prepare: func(t *Terminal) error {
+				return t.Flush()
+			},
+			draw: func(t *Terminal) error {
+				for _, p := range []image.Point{{0, 0}, {1, 0}, {3, 0}, {0, 1}} {
+					if err := t.SetCell(p, 'x'); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			want: "\x1b[1;1H\x1b[0mxx" +
+				"\x1b[1;4H\x1b[0mx" +
+				"\x1b[2;1H\x1b[0mx" +
+				"\x1b[2;4H",
+		},
+		{
+			desc: "enables the bracketed paste mode when clearing",
+			opts: []Option{BracketedPaste()},
+			draw: func(t *Terminal) error {
+				return t.SetCell(image.Point{1, 0}, 'a')
+			},
+			want: "\x1b[0m\x1b[2J\x1b[?2004h" +
+				"\x1b[1;2H\x1b[0ma" +
+				"\x1b[2;4H",
+		},
+		{
+			desc: "displays background colors in reverse video",
+			draw: func(t *Terminal) error {
+				return t.SetCell(image.Point{0, 0}, 'a', cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorBlue))
+			},
+			want: "\x1b[0m\x1b[2J" +
+				"\x1b[1;1H\x1b[0;7ma" +
+				"\x1b[2;4H",
+		},
+		{
+			desc: "writes the ANSI colors",
+			opts: []Option{Colors()},
+			draw: func(t *Terminal) error {
+				if err := t.SetCell(image.Point{0, 0}, 'a', cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorNumber(12))); err != nil {
+					return err
+				}
+				return t.SetCell(image.Point{2, 0}, 'b', cell.FgColor(cell.ColorNumber(100)))
+			},
+			want: "\x1b[0m\x1b[2J" +
+				"\x1b[1;1H\x1b[0;31;44ma" +
+				"\x1b[1;3H\x1b[0mb" +
+				"\x1b[2;4H",
+		},
+		{
+			desc: "replaces characters outside of ASCII",
+			draw: func(t *Terminal) error {
+				return t.SetCell(image.Point{0, 0}, '⣿')
+			},
+			want: "\x1b[0m\x1b[2J" +
+				"\x1b[1;1H\x1b[0m " +
+				"\x1b[2;4H",
+		},
+		{
+			desc: "writes unicode characters when enabled",
+			opts: []Option{Unicode(terminalapi.UnicodeFull)},
+			draw: func(t *Terminal) error {
+				return t.SetCell(image.Point{0, 0}, '⣿')
+			},
+			want: "\x1b[0m\x1b[2J" +
+				"\x1b[1;1H\x1b[0m⣿" +
+				"\x1b[2;4H",
+		},
+		{
+			desc: "positions the visible cursor",
+			prepare: func(t *Terminal) error {
+				return t.Flush()
+			},
+			draw: func(t *Terminal) error {
+				t.SetCursor(image.Point{1, 1})
+				return nil
+			},
+			want: "\x1b[2;2H",
+		},
+		{
+			desc: "repaints all the cells",
+			opts: []Option{RepaintEvery(2)},
+			prepare: func(t *Terminal) error {
+				return t.Flush()
+			},
+			want: "\x1b[1;1H\x1b[0m    " +
+				"\x1b[2;1H\x1b[0m    " +
+				"\x1b[2;4H",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			c := &conn{}
+			term, err := newTerminal(c, image.Point{4, 2}, tc.opts...)
+			if err != nil {
+				t.Fatalf("newTerminal => unexpected error: %v", err)
+			}
+			if tc.prepare != nil {
+				if err := tc.prepare(term); err != nil {
+					t.Fatalf("prepare => unexpected error: %v", err)
+				}
+			}
+			c.Reset()
+
+			if tc.draw != nil {
+				if err := tc.draw(term); err != nil {
+					t.Fatalf("draw => unexpected error: %v", err)
+				}
+			}
+			if err := term.Flush(); err != nil {
+				t.Fatalf("Flush => unexpected error: %v", err)
+			}
+			if got := c.String(); got != tc.want {
+				t.Errorf("Flush => got output %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEvent(t *testing.T) {
+	r, w := io.Pipe()
+	term, err := New(&conn{Reader: r}, image.Point{80, 24}, EscapeTimeout(time.Millisecond))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	defer term.Close()
+
+	go func() {
+		w.Write([]byte("a\x1b[B\x1b"))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []terminalapi.Event
+	for i := 0; i < 3; i++ {
+		got = append(got, term.Event(ctx))
+	}
+	want := []terminalapi.Event{
+		&terminalapi.Keyboard{Key: 'a'},
+		&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+		&terminalapi.Keyboard{Key: keyboard.KeyEsc},
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("Event => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	if got, want := term.Capabilities(), (terminalapi.Capabilities{ColorDepth: 2, Unicode: terminalapi.UnicodeASCII}); got != want {
+		t.Errorf("Capabilities => got %+v, want %+v", got, want)
+	}
+}
+
+func TestBracketedPaste(t *testing.T) {
+	r, w := io.Pipe()
+	c := &conn{Reader: r}
+	term, err := New(c, image.Point{80, 24}, BracketedPaste())
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	go func() {
+		w.Write([]byte("\x1b[200~ab\rc\x1b[201~"))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := term.Event(ctx)
+	want := &terminalapi.Paste{Text: "ab\rc"}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("Event => unexpected diff (-want, +got):\n%s", diff)
+	}
+	if !term.Capabilities().Paste {
+		t.Errorf("Capabilities => got Paste false, want true")
+	}
+
+	term.Close()
+	if got := c.String(); !strings.HasSuffix(got, pasteOffSeq) {
+		t.Errorf("Close => got output %q, want it to disable the bracketed paste mode", got)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+// capabilities.go determines the capabilities of the terminal.
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Runes used to probe the character set of the terminal.
+const (
+	brailleProbe = '⣿'
+	boxProbe     = '┼'
+	blockProbe   = '█'
+)
+
+// capabilities determines the capabilities of the initialized screen.
+func capabilities(s tcell.Screen) terminalapi.Capabilities {
+	ul := terminalapi.UnicodeASCII
+	switch {
+	case s.CanDisplay(brailleProbe, false):
+		ul = terminalapi.UnicodeFull
+	case s.CanDisplay(boxProbe, false) && s.CanDisplay(blockProbe, false):
+		ul = terminalapi.UnicodeBasic
+	}
+	return terminalapi.Capabilities{
+		ColorDepth: s.Colors(),
+		Unicode:    ul,
+		Mouse:      s.HasMouse(),
+		// tcell enables the reporting of all the mouse motion.
+		Hover: s.HasMouse(),
+	}
+}
@@ -169,6 +169,36 @@ func convResize(event *tcell.EventResize) terminalapi.Event {
 	}
 }
 
+// buttonTracker tracks whether any mouse buttons are pressed. This is needed,
+// because tcell reports both the release of the buttons and the motion of the
+// mouse pointer without any pressed buttons with tcell.ButtonNone.
+type buttonTracker struct {
+	pressed bool
+}
+
+// event reports the mouse event with mouse.ButtonRelease as hovering, i.e.
+// mouse.ButtonNone, if no buttons were pressed. Other events are returned
+// unchanged.
+func (bt *buttonTracker) event(ev terminalapi.Event) terminalapi.Event {
+	m, ok := ev.(*terminalapi.Mouse)
+	if !ok {
+		return ev
+	}
+	switch m.Button {
+	case mouse.ButtonLeft, mouse.ButtonRight, mouse.ButtonMiddle:
+		bt.pressed = true
+	case mouse.ButtonRelease:
+		if !bt.pressed {
+			return &terminalapi.Mouse{
+				Position: m.Position,
+				Button:   mouse.ButtonNone,
+			}
+		}
+		bt.pressed = false
+	}
+	return ev
+}
+
 // toTermdashEvents converts a tcell event to the termdash event format.
 // This function returns nil if the event is unsupported by termdash.
 func toTermdashEvents(event tcell.Event) []terminalapi.Event {
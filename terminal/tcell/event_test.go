@@ -167,6 +167,40 @@ func TestMouseButtons(t *testing.T) {
 	}
 }
 
+func TestButtonTracker(t *testing.T) {
+	masks := []tcell.ButtonMask{
+		tcell.ButtonNone,
+		tcell.Button1,
+		tcell.Button1,
+		tcell.ButtonNone,
+		tcell.ButtonNone,
+		tcell.WheelUp,
+		tcell.ButtonNone,
+	}
+	want := []mouse.Button{
+		mouse.ButtonNone,
+		mouse.ButtonLeft,
+		mouse.ButtonLeft,
+		mouse.ButtonRelease,
+		mouse.ButtonNone,
+		mouse.ButtonWheelUp,
+		mouse.ButtonNone,
+	}
+
+	var (
+		bt  buttonTracker
+		got []mouse.Button
+	)
+	for _, m := range masks {
+		for _, ev := range toTermdashEvents(tcell.NewEventMouse(0, 0, m, tcell.ModNone)) {
+			got = append(got, bt.event(ev).(*terminalapi.Mouse).Button)
+		}
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("buttonTracker.event => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
 func TestKeyboardKeys(t *testing.T) {
 	tests := []struct {
 		key     tcell.Key
@@ -22,8 +22,10 @@ import (
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/encoding"
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/doublebuffer"
 	"github.com/mum4k/termdash/private/event/eventqueue"
 	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/terminal/termstate"
 )
 
 // Option is used to provide options.
@@ -62,6 +64,31 @@ func ClearStyle(fg, bg cell.Color) Option {
 	})
 }
 
+// StateFile writes the terminal modes changed by the terminal into a state
+// file at the path when the terminal is created and removes the file when it
+// is closed. The file allows restoring the terminal if the process is killed
+// before Close is called, see the termstate package.
+// Use termstate.DefaultPath() for the path the termrecover command looks for.
+func StateFile(path string) Option {
+	return option(func(t *Terminal) {
+		t.stateFile = path
+	})
+}
+
+// FlushCells limits the number of cells written to the terminal by a single
+// Flush, which keeps flushing fast on very large terminals where a redraw of
+// the whole screen would visibly lag. The changed rows that don't fit are
+// written by the next calls to Flush. The terminal implements
+// terminalapi.PartialFlusher, so termdash keeps flushing until all the
+// changes are written even with termdash.RedrawOnChange. A row is always
+// written entirely, so a flush can exceed the limit by up to one row.
+// Defaults to zero, which writes all the changed cells on each Flush.
+func FlushCells(cells int) Option {
+	return option(func(t *Terminal) {
+		t.flushCells = cells
+	})
+}
+
 // Terminal provides input and output to a real terminal. Wraps the
 // gdamore/tcell terminal implementation. This object is not thread-safe.
 // Implements terminalapi.Terminal.
@@ -75,9 +102,21 @@ type Terminal struct {
 	// the tcell terminal window
 	screen tcell.Screen
 
+	// caps are the capabilities of the terminal.
+	caps terminalapi.Capabilities
+
+	// buttons tracks the pressed mouse buttons.
+	buttons buttonTracker
+
+	// buf is the double buffer the cells are drawn into, only the changed
+	// cells are written to the terminal on Flush.
+	buf *doublebuffer.Buffer
+
 	// Options.
 	colorMode  terminalapi.ColorMode
 	clearStyle *cell.Options
+	stateFile  string
+	flushCells int
 }
 
 // tcellNewScreen can be overridden from tests.
@@ -117,18 +156,51 @@ func New(opts ...Option) (*Terminal, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := t.saveState(); err != nil {
+		return nil, err
+	}
 	if err = t.screen.Init(); err != nil {
+		t.removeState()
 		return nil, err
 	}
 
 	clearStyle := cellOptsToStyle(t.clearStyle, t.colorMode)
 	t.screen.EnableMouse()
 	t.screen.SetStyle(clearStyle)
+	t.caps = capabilities(t.screen)
+	buf, err := doublebuffer.New(t.Size())
+	if err != nil {
+		return nil, err
+	}
+	t.buf = buf
 
 	go t.pollEvents() // Stops when Close() is called.
 	return t, nil
 }
 
+// saveState writes the state file if the StateFile option was provided.
+// Must be called before the terminal modes change.
+func (t *Terminal) saveState() error {
+	if t.stateFile == "" {
+		return nil
+	}
+	s := termstate.Capture("tcell")
+	s.AltScreen = true
+	s.HiddenCursor = true
+	s.Mouse = true
+	if err := termstate.Save(t.stateFile, s); err != nil {
+		return fmt.Errorf("termstate.Save => %v", err)
+	}
+	return nil
+}
+
+// removeState removes the state file if the StateFile option was provided.
+func (t *Terminal) removeState() {
+	if t.stateFile != "" {
+		termstate.Remove(t.stateFile)
+	}
+}
+
 // Size implements terminalapi.Terminal.Size.
 func (t *Terminal) Size() image.Point {
 	w, h := t.screen.Size()
@@ -140,18 +212,58 @@ func (t *Terminal) Size() image.Point {
 
 // Clear implements terminalapi.Terminal.Clear.
 func (t *Terminal) Clear(opts ...cell.Option) error {
+	if err := t.resizeBuf(); err != nil {
+		return err
+	}
+	t.buf.Clear(opts...)
+	t.buf.Invalidate()
+
 	o := cell.NewOptions(opts...)
 	st := cellOptsToStyle(o, t.colorMode)
 	t.screen.Fill(' ', st)
 	return nil
 }
 
+// resizeBuf resizes the double buffer if the size of the terminal changed.
+func (t *Terminal) resizeBuf() error {
+	if size := t.Size(); size != t.buf.Size() {
+		return t.buf.Resize(size)
+	}
+	return nil
+}
+
 // Flush implements terminalapi.Terminal.Flush.
 func (t *Terminal) Flush() error {
+	if err := t.resizeBuf(); err != nil {
+		return err
+	}
+	setCell := func(p image.Point, r rune, combining []rune, o *cell.Options) error {
+		t.screen.SetContent(p.X, p.Y, r, combining, cellOptsToStyle(o, t.colorMode))
+		return nil
+	}
+	if err := t.flushBuf(setCell); err != nil {
+		return err
+	}
 	t.screen.Show()
 	return nil
 }
 
+// flushBuf writes the changed cells of the double buffer to the terminal,
+// at most the number set by the FlushCells option.
+func (t *Terminal) flushBuf(fn doublebuffer.SetCellFunc) error {
+	if t.flushCells > 0 {
+		_, err := t.buf.FlushChunk(fn, t.flushCells)
+		return err
+	}
+	_, err := t.buf.Flush(fn)
+	return err
+}
+
+// FlushPending implements terminalapi.PartialFlusher.FlushPending.
+func (t *Terminal) FlushPending() bool {
+	return t.buf.Pending()
+}
+
 // SetCursor implements terminalapi.Terminal.SetCursor.
 func (t *Terminal) SetCursor(p image.Point) {
 	t.screen.ShowCursor(p.X, p.Y)
@@ -164,10 +276,28 @@ func (t *Terminal) HideCursor() {
 
 // SetCell implements terminalapi.Terminal.SetCell.
 func (t *Terminal) SetCell(p image.Point, r rune, opts ...cell.Option) error {
-	o := cell.NewOptions(opts...)
-	st := cellOptsToStyle(o, t.colorMode)
-	t.screen.SetContent(p.X, p.Y, r, nil, st)
-	return nil
+	if !p.In(image.Rectangle{Max: t.buf.Size()}) {
+		if err := t.resizeBuf(); err != nil {
+			return err
+		}
+		if !p.In(image.Rectangle{Max: t.buf.Size()}) {
+			return nil // Like tcell, ignore cells outside of the terminal.
+		}
+	}
+	return t.buf.SetCell(p, r, opts...)
+}
+
+// SetCellCombining implements terminalapi.Combiner.SetCellCombining.
+func (t *Terminal) SetCellCombining(p image.Point, r rune, combining []rune, opts ...cell.Option) error {
+	if !p.In(image.Rectangle{Max: t.buf.Size()}) {
+		if err := t.resizeBuf(); err != nil {
+			return err
+		}
+		if !p.In(image.Rectangle{Max: t.buf.Size()}) {
+			return nil // Like tcell, ignore cells outside of the terminal.
+		}
+	}
+	return t.buf.SetCellCombining(p, r, combining, opts...)
 }
 
 // pollEvents polls and enqueues the input events.
@@ -181,7 +311,7 @@ func (t *Terminal) pollEvents() {
 
 		events := toTermdashEvents(t.screen.PollEvent())
 		for _, ev := range events {
-			t.events.Push(ev)
+			t.events.Push(t.buttons.event(ev))
 		}
 	}
 }
@@ -195,10 +325,16 @@ func (t *Terminal) Event(ctx context.Context) terminalapi.Event {
 	return ev
 }
 
+// Capabilities implements terminalapi.Terminal.Capabilities.
+func (t *Terminal) Capabilities() terminalapi.Capabilities {
+	return t.caps
+}
+
 // Close closes the terminal, should be called when the terminal isn't required
 // anymore to return the screen to a sane state.
 // Implements terminalapi.Terminal.Close.
 func (t *Terminal) Close() {
 	close(t.done)
 	t.screen.Fini()
+	t.removeState()
 }
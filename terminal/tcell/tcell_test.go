@@ -44,6 +44,16 @@ func TestNewTerminalColorMode(t *testing.T) {
 				colorMode: terminalapi.ColorModeNormal,
 			},
 		},
+		{
+			desc: "sets the flush limit",
+			opts: []Option{
+				FlushCells(1000),
+			},
+			want: &Terminal{
+				colorMode:  terminalapi.ColorMode256,
+				flushCells: 1000,
+			},
+		},
 	}
 
 	tcellNewScreen = func() (tcell.Screen, error) { return nil, nil }
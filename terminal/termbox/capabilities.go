@@ -0,0 +1,58 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termbox
+
+// capabilities.go determines the capabilities of the terminal.
+
+import (
+	"strings"
+
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// colorDepth returns the number of colors available in the color mode.
+func colorDepth(cm terminalapi.ColorMode) int {
+	switch cm {
+	case terminalapi.ColorModeNormal:
+		return 8
+	case terminalapi.ColorMode216:
+		return 216
+	case terminalapi.ColorModeGrayscale:
+		return 24
+	default:
+		return 256
+	}
+}
+
+// unicodeLevel determines the unicode level of the terminal from the locale
+// and the terminal type set in the environment, since termbox doesn't detect
+// the character set. Variables are looked up using the provided function.
+func unicodeLevel(getenv func(string) string) terminalapi.UnicodeLevel {
+	var locale string
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale = getenv(name); locale != "" {
+			break
+		}
+	}
+
+	l := strings.ToLower(locale)
+	if locale != "" && !strings.Contains(l, "utf-8") && !strings.Contains(l, "utf8") {
+		return terminalapi.UnicodeASCII
+	}
+	if getenv("TERM") == "linux" {
+		return terminalapi.UnicodeBasic
+	}
+	return terminalapi.UnicodeFull
+}
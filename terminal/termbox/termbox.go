@@ -17,11 +17,15 @@ package termbox
 
 import (
 	"context"
+	"fmt"
 	"image"
+	"os"
 
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/doublebuffer"
 	"github.com/mum4k/termdash/private/event/eventqueue"
 	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/terminal/termstate"
 	tbx "github.com/nsf/termbox-go"
 )
 
@@ -50,6 +54,31 @@ func ColorMode(cm terminalapi.ColorMode) Option {
 	})
 }
 
+// StateFile writes the terminal modes changed by the terminal into a state
+// file at the path when the terminal is created and removes the file when it
+// is closed. The file allows restoring the terminal if the process is killed
+// before Close is called, see the termstate package.
+// Use termstate.DefaultPath() for the path the termrecover command looks for.
+func StateFile(path string) Option {
+	return option(func(t *Terminal) {
+		t.stateFile = path
+	})
+}
+
+// FlushCells limits the number of cells written to the terminal by a single
+// Flush, which keeps flushing fast on very large terminals where a redraw of
+// the whole screen would visibly lag. The changed rows that don't fit are
+// written by the next calls to Flush. The terminal implements
+// terminalapi.PartialFlusher, so termdash keeps flushing until all the
+// changes are written even with termdash.RedrawOnChange. A row is always
+// written entirely, so a flush can exceed the limit by up to one row.
+// Defaults to zero, which writes all the changed cells on each Flush.
+func FlushCells(cells int) Option {
+	return option(func(t *Terminal) {
+		t.flushCells = cells
+	})
+}
+
 // Terminal provides input and output to a real terminal. Wraps the
 // nsf/termbox-go terminal implementation. This object is not thread-safe.
 // Implements terminalapi.Terminal.
@@ -60,8 +89,17 @@ type Terminal struct {
 	// done gets closed when Close() is called.
 	done chan struct{}
 
+	// caps are the capabilities of the terminal.
+	caps terminalapi.Capabilities
+
+	// buf is the double buffer the cells are drawn into, only the changed
+	// cells are written to the terminal on Flush.
+	buf *doublebuffer.Buffer
+
 	// Options.
-	colorMode terminalapi.ColorMode
+	colorMode  terminalapi.ColorMode
+	stateFile  string
+	flushCells int
 }
 
 // newTerminal creates the terminal and applies the options.
@@ -80,22 +118,59 @@ func newTerminal(opts ...Option) *Terminal {
 // New returns a new termbox based Terminal.
 // Call Close() when the terminal isn't required anymore.
 func New(opts ...Option) (*Terminal, error) {
+	t := newTerminal(opts...)
+	if err := t.saveState(); err != nil {
+		return nil, err
+	}
 	if err := tbx.Init(); err != nil {
+		t.removeState()
 		return nil, err
 	}
 	tbx.SetInputMode(tbx.InputEsc | tbx.InputMouse)
 
-	t := newTerminal(opts...)
 	om, err := colorMode(t.colorMode)
 	if err != nil {
 		return nil, err
 	}
 	tbx.SetOutputMode(om)
+	t.caps = terminalapi.Capabilities{
+		ColorDepth: colorDepth(t.colorMode),
+		Unicode:    unicodeLevel(os.Getenv),
+		Mouse:      true,
+	}
+	buf, err := doublebuffer.New(t.Size())
+	if err != nil {
+		return nil, err
+	}
+	t.buf = buf
 
 	go t.pollEvents() // Stops when Close() is called.
 	return t, nil
 }
 
+// saveState writes the state file if the StateFile option was provided.
+// Must be called before the terminal modes change.
+func (t *Terminal) saveState() error {
+	if t.stateFile == "" {
+		return nil
+	}
+	s := termstate.Capture("termbox")
+	s.AltScreen = true
+	s.HiddenCursor = true
+	s.Mouse = true
+	if err := termstate.Save(t.stateFile, s); err != nil {
+		return fmt.Errorf("termstate.Save => %v", err)
+	}
+	return nil
+}
+
+// removeState removes the state file if the StateFile option was provided.
+func (t *Terminal) removeState() {
+	if t.stateFile != "" {
+		termstate.Remove(t.stateFile)
+	}
+}
+
 // Size implements terminalapi.Terminal.Size.
 func (t *Terminal) Size() image.Point {
 	w, h := tbx.Size()
@@ -104,15 +179,57 @@ func (t *Terminal) Size() image.Point {
 
 // Clear implements terminalapi.Terminal.Clear.
 func (t *Terminal) Clear(opts ...cell.Option) error {
+	if err := t.resizeBuf(); err != nil {
+		return err
+	}
+	t.buf.Clear(opts...)
+	t.buf.Invalidate()
+
 	o := cell.NewOptions(opts...)
 	return tbx.Clear(cellOptsToFg(o), cellOptsToBg(o))
 }
 
+// resizeBuf resizes the double buffer if the size of the terminal changed.
+func (t *Terminal) resizeBuf() error {
+	if size := t.Size(); size != t.buf.Size() {
+		return t.buf.Resize(size)
+	}
+	return nil
+}
+
 // Flush implements terminalapi.Terminal.Flush.
 func (t *Terminal) Flush() error {
+	if err := t.resizeBuf(); err != nil {
+		return err
+	}
+	// Termbox cannot display combining characters, only the base runes are
+	// written.
+	setCell := func(p image.Point, r rune, _ []rune, o *cell.Options) error {
+		tbx.SetCell(p.X, p.Y, r, cellOptsToFg(o), cellOptsToBg(o))
+		return nil
+	}
+	if err := t.flushBuf(setCell); err != nil {
+		return err
+	}
 	return tbx.Flush()
 }
 
+// flushBuf writes the changed cells of the double buffer to the terminal,
+// at most the number set by the FlushCells option.
+func (t *Terminal) flushBuf(fn doublebuffer.SetCellFunc) error {
+	if t.flushCells > 0 {
+		_, err := t.buf.FlushChunk(fn, t.flushCells)
+		return err
+	}
+	_, err := t.buf.Flush(fn)
+	return err
+}
+
+// FlushPending implements terminalapi.PartialFlusher.FlushPending.
+func (t *Terminal) FlushPending() bool {
+	return t.buf.Pending()
+}
+
 // SetCursor implements terminalapi.Terminal.SetCursor.
 func (t *Terminal) SetCursor(p image.Point) {
 	tbx.SetCursor(p.X, p.Y)
@@ -125,9 +242,15 @@ func (t *Terminal) HideCursor() {
 
 // SetCell implements terminalapi.Terminal.SetCell.
 func (t *Terminal) SetCell(p image.Point, r rune, opts ...cell.Option) error {
-	o := cell.NewOptions(opts...)
-	tbx.SetCell(p.X, p.Y, r, cellOptsToFg(o), cellOptsToBg(o))
-	return nil
+	if !p.In(image.Rectangle{Max: t.buf.Size()}) {
+		if err := t.resizeBuf(); err != nil {
+			return err
+		}
+		if !p.In(image.Rectangle{Max: t.buf.Size()}) {
+			return nil // Like termbox, ignore cells outside of the terminal.
+		}
+	}
+	return t.buf.SetCell(p, r, opts...)
 }
 
 // pollEvents polls and enqueues the input events.
@@ -155,10 +278,16 @@ func (t *Terminal) Event(ctx context.Context) terminalapi.Event {
 	return ev
 }
 
+// Capabilities implements terminalapi.Terminal.Capabilities.
+func (t *Terminal) Capabilities() terminalapi.Capabilities {
+	return t.caps
+}
+
 // Close closes the terminal, should be called when the terminal isn't required
 // anymore to return the screen to a sane state.
 // Implements terminalapi.Terminal.Close.
 func (t *Terminal) Close() {
 	close(t.done)
 	tbx.Close()
+	t.removeState()
 }
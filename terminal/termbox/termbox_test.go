@@ -42,6 +42,26 @@ func TestNewTerminal(t *testing.T) {
 				colorMode: terminalapi.ColorModeNormal,
 			},
 		},
+		{
+			desc: "sets the state file",
+			opts: []Option{
+				StateFile("/tmp/termdash.state"),
+			},
+			want: &Terminal{
+				colorMode: terminalapi.ColorMode256,
+				stateFile: "/tmp/termdash.state",
+			},
+		},
+		{
+			desc: "sets the flush limit",
+			opts: []Option{
+				FlushCells(1000),
+			},
+			want: &Terminal{
+				colorMode:  terminalapi.ColorMode256,
+				flushCells: 1000,
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -58,3 +78,51 @@ func TestNewTerminal(t *testing.T) {
 		})
 	}
 }
+
+func TestUnicodeLevel(t *testing.T) {
+	tests := []struct {
+		desc string
+		env  map[string]string
+		want terminalapi.UnicodeLevel
+	}{
+		{
+			desc: "no locale set",
+			want: terminalapi.UnicodeFull,
+		},
+		{
+			desc: "UTF-8 locale",
+			env:  map[string]string{"LANG": "en_US.UTF-8"},
+			want: terminalapi.UnicodeFull,
+		},
+		{
+			desc: "LC_ALL takes precedence over LANG",
+			env: map[string]string{
+				"LC_ALL": "C",
+				"LANG":   "en_US.UTF-8",
+			},
+			want: terminalapi.UnicodeASCII,
+		},
+		{
+			desc: "non UTF-8 locale",
+			env:  map[string]string{"LC_CTYPE": "en_US.ISO-8859-1"},
+			want: terminalapi.UnicodeASCII,
+		},
+		{
+			desc: "the Linux console",
+			env: map[string]string{
+				"LANG": "en_US.utf8",
+				"TERM": "linux",
+			},
+			want: terminalapi.UnicodeBasic,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			getenv := func(name string) string { return tc.env[name] }
+			if got := unicodeLevel(getenv); got != tc.want {
+				t.Errorf("unicodeLevel => %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
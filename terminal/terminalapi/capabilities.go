@@ -0,0 +1,82 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminalapi
+
+// capabilities.go defines the capabilities a terminal can report.
+
+// UnicodeLevel indicates which unicode characters a terminal can display.
+// The levels are ordered from the most to the least capable, the zero value
+// indicates full support.
+type UnicodeLevel int
+
+// String implements fmt.Stringer()
+func (ul UnicodeLevel) String() string {
+	if n, ok := unicodeLevelNames[ul]; ok {
+		return n
+	}
+	return "UnicodeLevelUnknown"
+}
+
+// unicodeLevelNames maps UnicodeLevel values to human readable names.
+var unicodeLevelNames = map[UnicodeLevel]string{
+	UnicodeFull:  "UnicodeFull",
+	UnicodeBasic: "UnicodeBasic",
+	UnicodeASCII: "UnicodeASCII",
+}
+
+// Supported unicode levels.
+const (
+	// UnicodeFull indicates that the terminal can display all the characters
+	// used by termdash, including the braille patterns used by the line chart
+	// and the donut.
+	UnicodeFull UnicodeLevel = iota
+
+	// UnicodeBasic indicates that the terminal can display the box drawing
+	// and the block element characters, but not the braille patterns.
+	// This is typical for the Linux console.
+	UnicodeBasic
+
+	// UnicodeASCII indicates that the terminal can only display ASCII
+	// characters.
+	UnicodeASCII
+)
+
+// Capabilities describe the features supported by a terminal.
+type Capabilities struct {
+	// ColorDepth is the number of colors the terminal can display, e.g. 8,
+	// 256 or 16777216. Zero if unknown.
+	ColorDepth int
+
+	// Unicode indicates which unicode characters the terminal can display.
+	Unicode UnicodeLevel
+
+	// Mouse asserts whether the terminal reports mouse events.
+	Mouse bool
+
+	// Hover asserts whether the terminal reports the motion of the mouse
+	// pointer without any pressed buttons, as mouse events with
+	// mouse.ButtonNone.
+	Hover bool
+
+	// Paste asserts whether the terminal reports pasted text as Paste events.
+	// Otherwise the pasted text arrives as individual Keyboard events.
+	Paste bool
+
+	// Composition asserts whether the terminal reports the text composed with
+	// an input method editor as Composition and Commit events. Otherwise the
+	// terminal displays the composition itself and the composed text arrives
+	// as individual Keyboard events.
+	Composition bool
+}
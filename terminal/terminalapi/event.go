@@ -44,6 +44,58 @@ func (k Keyboard) String() string {
 	return fmt.Sprintf("Keyboard{Key: %v}", k.Key)
 }
 
+// Paste is the event used when the user pastes text into a terminal that
+// supports bracketed paste. The pasted text is delivered as one event instead
+// of one Keyboard event per character, see Capabilities.Paste.
+// Implements terminalapi.Event.
+type Paste struct {
+	// Text is the pasted text.
+	Text string
+}
+
+func (*Paste) isEvent() {}
+
+// String implements fmt.Stringer.
+func (p Paste) String() string {
+	return fmt.Sprintf("Paste{Text: %q}", p.Text)
+}
+
+// Composition is the event used while the user composes text with an input
+// method editor (IME), e.g. when typing CJK characters that take several
+// keystrokes. Carries the preedit text, i.e. the text composed so far that
+// isn't part of the input yet. An event with empty Text ends the composition
+// without input, e.g. when the user cancels it. The composed text is
+// delivered in a Commit event. See Capabilities.Composition.
+// Implements terminalapi.Event.
+type Composition struct {
+	// Text is the preedit text.
+	Text string
+	// Cursor is the position of the cursor within the preedit text in runes.
+	Cursor int
+}
+
+func (*Composition) isEvent() {}
+
+// String implements fmt.Stringer.
+func (c Composition) String() string {
+	return fmt.Sprintf("Composition{Text: %q, Cursor: %d}", c.Text, c.Cursor)
+}
+
+// Commit is the event used when the input method editor commits the composed
+// text, which ends the composition. See Capabilities.Composition.
+// Implements terminalapi.Event.
+type Commit struct {
+	// Text is the committed text.
+	Text string
+}
+
+func (*Commit) isEvent() {}
+
+// String implements fmt.Stringer.
+func (c Commit) String() string {
+	return fmt.Sprintf("Commit{Text: %q}", c.Text)
+}
+
 // Resize is the event used when the terminal was resized.
 // Implements terminalapi.Event.
 type Resize struct {
@@ -50,7 +50,30 @@ type Terminal interface {
 	// Returns nil when the context gets canceled.
 	Event(ctx context.Context) Event
 
+	// Capabilities returns the features supported by the terminal.
+	Capabilities() Capabilities
+
 	// Close closes the underlying terminal implementation and should be called when
 	// the terminal isn't required anymore to return the screen to a sane state.
 	Close()
 }
+
+// PartialFlusher is an optional interface that terminals can implement if
+// they can limit the number of cells written by a single Flush, leaving the
+// remaining changes to the following calls.
+type PartialFlusher interface {
+	// FlushPending asserts whether the last Flush stopped before it wrote
+	// all the changed cells, so Flush must be called again even if nothing
+	// else changes.
+	FlushPending() bool
+}
+
+// Combiner is an optional interface that terminals can implement if they can
+// display combining characters, i.e. zero-width runes like combining accents
+// that modify the rune in the same cell. Terminals that don't implement it
+// only display the base runes.
+type Combiner interface {
+	// SetCellCombining is like SetCell, but also sets the combining
+	// characters drawn over the rune.
+	SetCellCombining(p image.Point, r rune, combining []rune, opts ...cell.Option) error
+}
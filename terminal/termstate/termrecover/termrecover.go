@@ -0,0 +1,72 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary termrecover restores the terminal left in a broken state by a
+// termdash application that was killed, e.g. with SIGKILL. Run it from the
+// affected terminal, typing blind if needed.
+//
+// By default it restores the terminal using the state files left behind by
+// the processes that exited in the temporary directory, see the termstate
+// package. Use -file to recover from a specific state file or -force to
+// restore the terminal even without a state file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/mum4k/termdash/terminal/termstate"
+)
+
+func main() {
+	file := flag.String("file", "", "The state file to recover from. Defaults to the stale state files in -dir.")
+	dir := flag.String("dir", os.TempDir(), "The directory with the state files.")
+	force := flag.Bool("force", false, "Restore the terminal to sane defaults if no state file is found.")
+	flag.Parse()
+
+	paths := []string{*file}
+	if *file == "" {
+		stale, err := termstate.Stale(*dir)
+		if err != nil {
+			log.Fatalf("termstate.Stale => %v", err)
+		}
+		paths = stale
+	}
+
+	if len(paths) == 0 {
+		if !*force {
+			fmt.Println("No state files of terminated sessions found, use -force to restore the terminal anyway.")
+			return
+		}
+		tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+		if err != nil {
+			log.Fatalf("os.OpenFile => %v", err)
+		}
+		defer tty.Close()
+		if err := termstate.Defaults().Restore(tty); err != nil {
+			log.Fatalf("Restore => %v", err)
+		}
+		fmt.Println("Terminal restored to sane defaults.")
+		return
+	}
+
+	for _, p := range paths {
+		if err := termstate.Recover(p); err != nil {
+			log.Fatalf("termstate.Recover(%q) => %v", p, err)
+		}
+		fmt.Printf("Terminal restored from %s.\n", p)
+	}
+}
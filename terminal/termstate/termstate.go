@@ -0,0 +1,267 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package termstate records the terminal modes a termdash application changes
+// at startup in a small state file, so the terminal can be restored if the
+// process is killed before it gets a chance to restore it itself, e.g. on
+// SIGKILL.
+//
+// The terminals write the state file when created with their StateFile
+// option and remove it when closed. A state file left behind by a process
+// that exited belongs to a session that didn't end cleanly and can be used to
+// restore the terminal by calling Recover or running the termrecover command
+// from the same terminal.
+package termstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ttyPath is the path to the controlling terminal of the process.
+const ttyPath = "/dev/tty"
+
+// filePrefix and fileSuffix surround the process ID in the names of the state
+// files created at DefaultPath.
+const (
+	filePrefix = "termdash-"
+	fileSuffix = ".state"
+)
+
+// stty runs the stty command with the arguments on the controlling terminal
+// and returns its output.
+// Exists to be replaced in tests.
+var stty = func(args ...string) (string, error) {
+	tty, err := os.OpenFile(ttyPath, os.O_RDWR, 0)
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("stty %s => %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// processRunning determines if the process with the PID is running.
+// Exists to be replaced in tests.
+var processRunning = func(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// Signal zero only checks that the process exists. The process exists
+	// also when we aren't permitted to signal it.
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
+
+// State describes the terminal modes changed by a termdash application.
+type State struct {
+	// PID is the ID of the process that changed the terminal.
+	PID int `json:"pid"`
+	// Backend is the name of the terminal implementation, e.g. "termbox".
+	Backend string `json:"backend"`
+	// Started is the time the terminal was changed.
+	Started time.Time `json:"started"`
+
+	// Stty are the settings of the terminal line before they were changed
+	// as reported by "stty -g". Empty if they couldn't be determined, the
+	// terminal is then restored with "stty sane".
+	Stty string `json:"stty,omitempty"`
+
+	// AltScreen indicates that the alternate screen was entered.
+	AltScreen bool `json:"alt_screen"`
+	// HiddenCursor indicates that the cursor was hidden.
+	HiddenCursor bool `json:"hidden_cursor"`
+	// Mouse indicates that mouse reporting was enabled.
+	Mouse bool `json:"mouse"`
+}
+
+// Capture returns the state of the terminal before the backend changes it.
+// Must be called before the terminal modes change, the caller sets the modes
+// it is going to change on the returned state. The settings of the terminal
+// line are captured on a best-effort basis, e.g. they are left empty if the
+// stty command isn't available.
+func Capture(backend string) *State {
+	s := &State{
+		PID:     os.Getpid(),
+		Backend: backend,
+		Started: time.Now(),
+	}
+	if out, err := stty("-g"); err == nil {
+		s.Stty = out
+	}
+	return s
+}
+
+// DefaultPath returns the path of the state file of the current process in
+// the temporary directory. The termrecover command looks for the state files
+// there by default.
+func DefaultPath() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("%s%d%s", filePrefix, os.Getpid(), fileSuffix))
+}
+
+// Save writes the state into the file at the path. The file is replaced
+// atomically, so a reader never sees a partially written state.
+func Save(path string, s *State) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Load reads the state from the file at the path.
+func Load(path string) (*State, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &State{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("invalid state file %q: %v", path, err)
+	}
+	return s, nil
+}
+
+// Remove removes the state file at the path. It isn't an error if the file
+// doesn't exist.
+func Remove(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Stale returns the paths of the state files in the directory whose
+// processes aren't running anymore, i.e. the sessions that didn't end
+// cleanly. Only the files named like the ones created at DefaultPath are
+// considered.
+func Stale(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, m := range matches {
+		name := filepath.Base(m)
+		pid, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix))
+		if err != nil {
+			continue
+		}
+		if !processRunning(pid) {
+			stale = append(stale, m)
+		}
+	}
+	return stale, nil
+}
+
+// Escape sequences that undo the terminal modes.
+const (
+	resetAttrs   = "\x1b[0m"
+	showCursor   = "\x1b[?25h"
+	leaveAltScr  = "\x1b[?1049l"
+	disableMouse = "\x1b[?1000l\x1b[?1002l\x1b[?1003l\x1b[?1006l\x1b[?1015l"
+	disablePaste = "\x1b[?2004l"
+)
+
+// sttyFallback are the stty arguments used when the original settings of the
+// terminal line are unknown.
+const sttyFallback = "sane"
+
+// Sequence returns the escape sequences that undo the terminal modes in the
+// state.
+func (s *State) Sequence() string {
+	var b strings.Builder
+	if s.Mouse {
+		b.WriteString(disableMouse)
+	}
+	b.WriteString(disablePaste)
+	b.WriteString(resetAttrs)
+	if s.HiddenCursor {
+		b.WriteString(showCursor)
+	}
+	if s.AltScreen {
+		b.WriteString(leaveAltScr)
+	}
+	return b.String()
+}
+
+// Restore writes the escape sequences that undo the terminal modes to the
+// terminal and restores the settings of the terminal line.
+func (s *State) Restore(w io.Writer) error {
+	if _, err := io.WriteString(w, s.Sequence()); err != nil {
+		return err
+	}
+	arg := s.Stty
+	if arg == "" {
+		arg = sttyFallback
+	}
+	if _, err := stty(arg); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Defaults returns a state that undoes all the modes a termdash application
+// changes, for when the state file is missing. The settings of the terminal
+// line are restored with "stty sane".
+func Defaults() *State {
+	return &State{
+		AltScreen:    true,
+		HiddenCursor: true,
+		Mouse:        true,
+	}
+}
+
+// Recover restores the controlling terminal using the state file at the path
+// and removes the file.
+func Recover(path string) error {
+	s, err := Load(path)
+	if err != nil {
+		return err
+	}
+	tty, err := os.OpenFile(ttyPath, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+	if err := s.Restore(tty); err != nil {
+		return err
+	}
+	return Remove(path)
+}
@@ -0,0 +1,227 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termstate
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// fakeStty replaces stty for the duration of a test and records the
+// arguments it was called with.
+type fakeStty struct {
+	calls [][]string
+	out   string
+	err   error
+}
+
+// newFakeStty returns a fake stty and a function that restores the original.
+func newFakeStty(out string, err error) (*fakeStty, func()) {
+	fs := &fakeStty{out: out, err: err}
+	orig := stty
+	stty = func(args ...string) (string, error) {
+		fs.calls = append(fs.calls, args)
+		return fs.out, fs.err
+	}
+	return fs, func() { stty = orig }
+}
+
+func TestCapture(t *testing.T) {
+	tests := []struct {
+		desc     string
+		out      string
+		err      error
+		wantStty string
+	}{
+		{
+			desc:     "captures the settings of the terminal line",
+			out:      "500:5:bf:8a3b:3:1c",
+			wantStty: "500:5:bf:8a3b:3:1c",
+		},
+		{
+			desc: "leaves the settings empty when stty fails",
+			err:  errors.New("no terminal"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			fs, restore := newFakeStty(tc.out, tc.err)
+			defer restore()
+
+			got := Capture("termbox")
+			if got.PID != os.Getpid() || got.Backend != "termbox" || got.Started.IsZero() {
+				t.Errorf("Capture => %+v, want the PID, backend and start time set", got)
+			}
+			if got.Stty != tc.wantStty {
+				t.Errorf("Capture => Stty %q, want %q", got.Stty, tc.wantStty)
+			}
+			if diff := pretty.Compare([][]string{{"-g"}}, fs.calls); diff != "" {
+				t.Errorf("Capture => unexpected stty calls (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSaveLoadRemove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "termstate")
+	if err != nil {
+		t.Fatalf("TempDir => unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state")
+
+	want := &State{
+		PID:          42,
+		Backend:      "tcell",
+		Started:      time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+		Stty:         "500:5:bf:8a3b",
+		AltScreen:    true,
+		HiddenCursor: true,
+		Mouse:        true,
+	}
+	if err := Save(path, want); err != nil {
+		t.Fatalf("Save => unexpected error: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("Load => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	if err := Remove(path); err != nil {
+		t.Fatalf("Remove => unexpected error: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Errorf("Load after Remove => got nil error, want an error")
+	}
+	if err := Remove(path); err != nil {
+		t.Errorf("Remove of a missing file => unexpected error: %v", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("garbage"), 0600); err != nil {
+		t.Fatalf("WriteFile => unexpected error: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Errorf("Load of an invalid file => got nil error, want an error")
+	}
+}
+
+func TestStale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "termstate")
+	if err != nil {
+		t.Fatalf("TempDir => unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{
+		"termdash-1.state",
+		"termdash-2.state",
+		"termdash-x.state",
+		"other-3.state",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("{}"), 0600); err != nil {
+			t.Fatalf("WriteFile => unexpected error: %v", err)
+		}
+	}
+	orig := processRunning
+	defer func() { processRunning = orig }()
+	processRunning = func(pid int) bool {
+		return pid == 1
+	}
+
+	got, err := Stale(dir)
+	if err != nil {
+		t.Fatalf("Stale => unexpected error: %v", err)
+	}
+	want := []string{filepath.Join(dir, "termdash-2.state")}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("Stale => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	name := filepath.Base(DefaultPath())
+	if !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+		t.Errorf("DefaultPath => %q, want a file named %s<pid>%s", name, filePrefix, fileSuffix)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	tests := []struct {
+		desc      string
+		state     *State
+		sttyErr   error
+		wantSeq   string
+		wantStty  [][]string
+		wantError bool
+	}{
+		{
+			desc:     "restores only the reset attributes when no modes changed",
+			state:    &State{Stty: "500:5"},
+			wantSeq:  disablePaste + resetAttrs,
+			wantStty: [][]string{{"500:5"}},
+		},
+		{
+			desc:     "undoes all the modes",
+			state:    &State{Stty: "500:5", AltScreen: true, HiddenCursor: true, Mouse: true},
+			wantSeq:  disableMouse + disablePaste + resetAttrs + showCursor + leaveAltScr,
+			wantStty: [][]string{{"500:5"}},
+		},
+		{
+			desc:     "falls back to stty sane",
+			state:    Defaults(),
+			wantSeq:  disableMouse + disablePaste + resetAttrs + showCursor + leaveAltScr,
+			wantStty: [][]string{{"sane"}},
+		},
+		{
+			desc:      "fails when stty fails",
+			state:     &State{},
+			sttyErr:   errors.New("no terminal"),
+			wantSeq:   disablePaste + resetAttrs,
+			wantStty:  [][]string{{"sane"}},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			fs, restore := newFakeStty("", tc.sttyErr)
+			defer restore()
+
+			var buf bytes.Buffer
+			err := tc.state.Restore(&buf)
+			if (err != nil) != tc.wantError {
+				t.Errorf("Restore => unexpected error: %v, wantError: %v", err, tc.wantError)
+			}
+			if got := buf.String(); got != tc.wantSeq {
+				t.Errorf("Restore => wrote %q, want %q", got, tc.wantSeq)
+			}
+			if diff := pretty.Compare(tc.wantStty, fs.calls); diff != "" {
+				t.Errorf("Restore => unexpected stty calls (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
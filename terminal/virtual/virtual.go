@@ -0,0 +1,441 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package virtual implements a terminal of a fixed size that is displayed on
+// another terminal.
+//
+// The virtual terminal can be larger or smaller than the terminal it is
+// displayed on, which is useful when developing layouts for a particular
+// terminal size or when capturing screenshots of a fixed size. A virtual
+// terminal that doesn't fit is either scrolled or scaled down, see Overflow.
+package virtual
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/private/canvas/buffer"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*Terminal)
+}
+
+// option implements Option.
+type option func(*Terminal)
+
+// set implements Option.set.
+func (o option) set(t *Terminal) {
+	o(t)
+}
+
+// Policy determines how a virtual terminal that doesn't fit onto the real
+// terminal is displayed.
+type Policy int
+
+// String implements fmt.Stringer()
+func (p Policy) String() string {
+	if n, ok := policyNames[p]; ok {
+		return n
+	}
+	return "PolicyUnknown"
+}
+
+// policyNames maps Policy values to human readable names.
+var policyNames = map[Policy]string{
+	PolicyScroll: "PolicyScroll",
+	PolicyScale:  "PolicyScale",
+}
+
+const (
+	// PolicyScroll displays the part of the virtual terminal that fits onto
+	// the real terminal. The displayed part can be moved with the keys set by
+	// ScrollKeys or by calling ScrollTo.
+	PolicyScroll Policy = iota
+
+	// PolicyScale scales the virtual terminal down to the size of the real
+	// terminal by displaying only a sample of its rows and columns. The
+	// dimensions are scaled independently, so the aspect ratio isn't
+	// preserved.
+	PolicyScale
+)
+
+// Overflow sets how a virtual terminal larger than the real terminal is
+// displayed.
+// Defaults to PolicyScroll.
+func Overflow(p Policy) Option {
+	return option(func(t *Terminal) {
+		t.policy = p
+	})
+}
+
+// ScrollKeys sets the keys that scroll the displayed part of the virtual
+// terminal by one cell when using PolicyScroll. These keys are consumed by
+// the virtual terminal and not reported as keyboard events.
+// Defaults to no keys.
+func ScrollKeys(up, down, left, right keyboard.Key) Option {
+	return option(func(t *Terminal) {
+		t.scrollKeys = map[keyboard.Key]image.Point{
+			up:    {0, -1},
+			down:  {0, 1},
+			left:  {-1, 0},
+			right: {1, 0},
+		}
+	})
+}
+
+// Terminal is a terminal of a fixed size displayed on another terminal.
+// This object is thread-safe.
+// Implements terminalapi.Terminal.
+type Terminal struct {
+	// real is the terminal the virtual terminal is displayed on.
+	real terminalapi.Terminal
+
+	// size is the fixed size of the virtual terminal.
+	size image.Point
+
+	// buf holds the cells of the virtual terminal.
+	buf buffer.Buffer
+
+	// offset is the cell of the virtual terminal displayed in the top left
+	// corner of the real terminal when using PolicyScroll.
+	offset image.Point
+
+	// cursorSet indicates that the cursor was either set or hidden.
+	cursorSet bool
+	// cursor is the position of the cursor or nil if it is hidden.
+	cursor *image.Point
+
+	// mu protects the Terminal.
+	mu sync.Mutex
+
+	// Options.
+	policy     Policy
+	scrollKeys map[keyboard.Key]image.Point
+}
+
+// New returns a new virtual terminal of the specified size that is displayed
+// on the provided terminal. Call Close() when the terminal isn't required
+// anymore, this also closes the real terminal.
+func New(real terminalapi.Terminal, size image.Point, opts ...Option) (*Terminal, error) {
+	if size.X <= 0 || size.Y <= 0 {
+		return nil, fmt.Errorf("invalid terminal size %v, both dimensions must be positive", size)
+	}
+	t := &Terminal{
+		real: real,
+		size: size,
+	}
+	for _, opt := range opts {
+		opt.set(t)
+	}
+	if _, ok := policyNames[t.policy]; !ok {
+		return nil, fmt.Errorf("unsupported Overflow policy %v", t.policy)
+	}
+
+	buf, err := buffer.New(size)
+	if err != nil {
+		return nil, err
+	}
+	t.buf = buf
+	return t, nil
+}
+
+// Size implements terminalapi.Terminal.Size.
+// Returns the size of the virtual terminal regardless of the size of the real
+// terminal.
+func (t *Terminal) Size() image.Point {
+	return t.size
+}
+
+// Clear implements terminalapi.Terminal.Clear.
+func (t *Terminal) Clear(opts ...cell.Option) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for col := range t.buf {
+		for row := range t.buf[col] {
+			t.buf[col][row] = buffer.NewCell(0, opts...)
+		}
+	}
+	return nil
+}
+
+// Flush implements terminalapi.Terminal.Flush.
+func (t *Terminal) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.repaint()
+}
+
+// SetCursor implements terminalapi.Terminal.SetCursor.
+func (t *Terminal) SetCursor(p image.Point) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cursorSet = true
+	t.cursor = &p
+}
+
+// HideCursor implements terminalapi.Terminal.HideCursor.
+func (t *Terminal) HideCursor() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cursorSet = true
+	t.cursor = nil
+}
+
+// SetCell implements terminalapi.Terminal.SetCell.
+func (t *Terminal) SetCell(p image.Point, r rune, opts ...cell.Option) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !p.In(image.Rectangle{Max: t.size}) {
+		return nil // Like the other terminals, ignore cells outside of the terminal.
+	}
+	_, err := t.buf.SetCell(p, r, opts...)
+	return err
+}
+
+// SetCellCombining implements terminalapi.Combiner.SetCellCombining.
+// The combining characters are displayed if the real terminal implements
+// terminalapi.Combiner.
+func (t *Terminal) SetCellCombining(p image.Point, r rune, combining []rune, opts ...cell.Option) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !p.In(image.Rectangle{Max: t.size}) {
+		return nil // Like the other terminals, ignore cells outside of the terminal.
+	}
+	if _, err := t.buf.SetCell(p, r, opts...); err != nil {
+		return err
+	}
+	t.buf[p.X][p.Y].Combining = append([]rune(nil), combining...)
+	return nil
+}
+
+// ScrollTo scrolls the virtual terminal so that the specified cell is
+// displayed in the top left corner of the real terminal. Only applies to
+// PolicyScroll, the position is limited so that the real terminal remains
+// covered.
+func (t *Terminal) ScrollTo(p image.Point) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.offset = p
+	return t.repaint()
+}
+
+// Offset returns the cell of the virtual terminal displayed in the top left
+// corner of the real terminal.
+func (t *Terminal) Offset() image.Point {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limitOffset(t.displaySize())
+	return t.offset
+}
+
+// displaySize returns the size of the area of the real terminal that displays
+// the virtual terminal.
+// Caller must hold t.mu.
+func (t *Terminal) displaySize() image.Point {
+	real := t.real.Size()
+	return image.Point{minInt(real.X, t.size.X), minInt(real.Y, t.size.Y)}
+}
+
+// limitOffset limits the offset so that the displayed part of the virtual
+// terminal remains within it.
+// Caller must hold t.mu.
+func (t *Terminal) limitOffset(display image.Point) {
+	if t.policy != PolicyScroll {
+		t.offset = image.ZP
+		return
+	}
+	t.offset = image.Point{
+		maxInt(0, minInt(t.offset.X, t.size.X-display.X)),
+		maxInt(0, minInt(t.offset.Y, t.size.Y-display.Y)),
+	}
+}
+
+// toVirtual converts a point on the display area of the real terminal to a
+// point on the virtual terminal.
+// Caller must hold t.mu.
+func (t *Terminal) toVirtual(p image.Point, display image.Point) image.Point {
+	if t.policy == PolicyScale {
+		return image.Point{p.X * t.size.X / display.X, p.Y * t.size.Y / display.Y}
+	}
+	return p.Add(t.offset)
+}
+
+// toReal converts a point on the virtual terminal to a point on the real
+// terminal. Returns false if the point isn't displayed.
+// Caller must hold t.mu.
+func (t *Terminal) toReal(p image.Point, display image.Point) (image.Point, bool) {
+	var rp image.Point
+	if t.policy == PolicyScale {
+		// The first display cell that shows the virtual cell, rounding up.
+		rp = image.Point{
+			(p.X*display.X + t.size.X - 1) / t.size.X,
+			(p.Y*display.Y + t.size.Y - 1) / t.size.Y,
+		}
+		if t.toVirtual(rp, display) != p {
+			return image.ZP, false // The cell was left out when scaling.
+		}
+	} else {
+		rp = p.Sub(t.offset)
+	}
+	return rp, rp.In(image.Rectangle{Max: display})
+}
+
+// repaint displays the virtual terminal on the real terminal.
+// Caller must hold t.mu.
+func (t *Terminal) repaint() error {
+	display := t.displaySize()
+	if display.X <= 0 || display.Y <= 0 {
+		return nil // Nothing can be displayed.
+	}
+	t.limitOffset(display)
+
+	if err := t.real.Clear(); err != nil {
+		return err
+	}
+	combiner, _ := t.real.(terminalapi.Combiner)
+	for y := 0; y < display.Y; y++ {
+		for x := 0; x < display.X; x++ {
+			vp := t.toVirtual(image.Point{x, y}, display)
+			c := t.buf[vp.X][vp.Y]
+			r := c.Rune
+			rw := runewidth.CellWidth(r, c.Combining)
+			partial, err := t.buf.IsPartial(vp)
+			if err != nil {
+				return err
+			}
+			if partial || x+rw > display.X {
+				// The first cell is outside of the display or the wide rune
+				// doesn't fit.
+				r = ' '
+				rw = 1
+			}
+			if combiner != nil && r == c.Rune && len(c.Combining) > 0 {
+				if err := combiner.SetCellCombining(image.Point{x, y}, r, c.Combining, c.Opts); err != nil {
+					return err
+				}
+			} else if err := t.real.SetCell(image.Point{x, y}, r, c.Opts); err != nil {
+				return err
+			}
+			if rw == 2 {
+				x++
+			}
+		}
+	}
+
+	if t.cursorSet {
+		if t.cursor == nil {
+			t.real.HideCursor()
+		} else if rp, ok := t.toReal(*t.cursor, display); ok {
+			t.real.SetCursor(rp)
+		} else {
+			t.real.HideCursor()
+		}
+	}
+	return t.real.Flush()
+}
+
+// Event implements terminalapi.Terminal.Event.
+// Mouse events are converted to the coordinates of the virtual terminal,
+// events outside of the displayed area are dropped. Resize events are
+// consumed since the size of the virtual terminal doesn't change.
+func (t *Terminal) Event(ctx context.Context) terminalapi.Event {
+	for {
+		ev := t.real.Event(ctx)
+		if ev == nil {
+			return nil
+		}
+
+		out, err := t.processEvent(ev)
+		if err != nil {
+			return terminalapi.NewErrorf("failed to display the virtual terminal: %v", err)
+		}
+		if out != nil {
+			return out
+		}
+	}
+}
+
+// processEvent processes the event on the real terminal and returns the event
+// on the virtual terminal or nil if the event was consumed.
+func (t *Terminal) processEvent(ev terminalapi.Event) (terminalapi.Event, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch e := ev.(type) {
+	case *terminalapi.Resize:
+		return nil, t.repaint()
+
+	case *terminalapi.Keyboard:
+		if d, ok := t.scrollKeys[e.Key]; ok && t.policy == PolicyScroll {
+			t.limitOffset(t.displaySize())
+			t.offset = t.offset.Add(d)
+			return nil, t.repaint()
+		}
+		return ev, nil
+
+	case *terminalapi.Mouse:
+		display := t.displaySize()
+		if !e.Position.In(image.Rectangle{Max: display}) {
+			return nil, nil
+		}
+		t.limitOffset(display)
+		m := *e
+		m.Position = t.toVirtual(e.Position, display)
+		return &m, nil
+
+	default:
+		return ev, nil
+	}
+}
+
+// Capabilities implements terminalapi.Terminal.Capabilities.
+// Returns the capabilities of the real terminal.
+func (t *Terminal) Capabilities() terminalapi.Capabilities {
+	return t.real.Capabilities()
+}
+
+// Close closes the real terminal.
+// Implements terminalapi.Terminal.Close.
+func (t *Terminal) Close() {
+	t.real.Close()
+}
+
+// minInt returns the smaller of the two integers.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the larger of the two integers.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,423 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtual
+
+import (
+	"context"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/event/eventqueue"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// mustSetCells sets the text starting at the point on the terminal.
+func mustSetCells(t terminalapi.Terminal, start image.Point, text string, opts ...cell.Option) {
+	p := start
+	for _, r := range text {
+		if err := t.SetCell(p, r, opts...); err != nil {
+			panic(err)
+		}
+		p.X++
+	}
+}
+
+// rows returns the content of the terminal as rows of text, the zero runes
+// are replaced by spaces.
+func rows(ft *faketerm.Terminal) []string {
+	b := ft.BackBuffer()
+	size := b.Size()
+	var res []string
+	for y := 0; y < size.Y; y++ {
+		var row []rune
+		for x := 0; x < size.X; x++ {
+			r := b[x][y].Rune
+			if r == 0 {
+				r = ' '
+			}
+			row = append(row, r)
+		}
+		res = append(res, string(row))
+	}
+	return res
+}
+
+// grid fills the terminal with rows of consecutive letters, starting with the
+// specified letter on each row.
+func grid(t terminalapi.Terminal, starts ...rune) {
+	size := t.Size()
+	for y, s := range starts {
+		var row []rune
+		for x := 0; x < size.X; x++ {
+			row = append(row, s+rune(x))
+		}
+		mustSetCells(t, image.Point{0, y}, string(row))
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		desc    string
+		size    image.Point
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			desc:    "fails on zero width",
+			size:    image.Point{0, 1},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on negative height",
+			size:    image.Point{1, -1},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on unsupported policy",
+			size:    image.Point{1, 1},
+			opts:    []Option{Overflow(Policy(-1))},
+			wantErr: true,
+		},
+		{
+			desc: "succeeds with options",
+			size: image.Point{1, 1},
+			opts: []Option{
+				Overflow(PolicyScale),
+				ScrollKeys(keyboard.KeyArrowUp, keyboard.KeyArrowDown, keyboard.KeyArrowLeft, keyboard.KeyArrowRight),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			vt, err := New(faketerm.MustNew(image.Point{3, 3}), tc.size, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := vt.Size(); got != tc.size {
+				t.Errorf("Size => %v, want %v", got, tc.size)
+			}
+		})
+	}
+}
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		desc     string
+		realSize image.Point
+		size     image.Point
+		opts     []Option
+		draw     func(*Terminal) error
+		want     []string
+	}{
+		{
+			desc:     "smaller virtual terminal is displayed in the top left corner",
+			realSize: image.Point{4, 3},
+			size:     image.Point{2, 2},
+			draw: func(vt *Terminal) error {
+				grid(vt, 'a', 'c')
+				return nil
+			},
+			want: []string{
+				"ab  ",
+				"cd  ",
+				"    ",
+			},
+		},
+		{
+			desc:     "ignores cells outside of the virtual terminal",
+			realSize: image.Point{3, 1},
+			size:     image.Point{2, 1},
+			draw: func(vt *Terminal) error {
+				return vt.SetCell(image.Point{2, 0}, 'x')
+			},
+			want: []string{
+				"   ",
+			},
+		},
+		{
+			desc:     "scroll policy displays the top left part",
+			realSize: image.Point{2, 2},
+			size:     image.Point{4, 3},
+			draw: func(vt *Terminal) error {
+				grid(vt, 'a', 'k', 'u')
+				return nil
+			},
+			want: []string{
+				"ab",
+				"kl",
+			},
+		},
+		{
+			desc:     "scroll policy displays the part scrolled to",
+			realSize: image.Point{2, 2},
+			size:     image.Point{4, 3},
+			draw: func(vt *Terminal) error {
+				grid(vt, 'a', 'k', 'u')
+				return vt.ScrollTo(image.Point{1, 1})
+			},
+			want: []string{
+				"lm",
+				"vw",
+			},
+		},
+		{
+			desc:     "scrolling is limited to the virtual terminal",
+			realSize: image.Point{2, 2},
+			size:     image.Point{4, 3},
+			draw: func(vt *Terminal) error {
+				grid(vt, 'a', 'k', 'u')
+				return vt.ScrollTo(image.Point{10, 10})
+			},
+			want: []string{
+				"mn",
+				"wx",
+			},
+		},
+		{
+			desc:     "hides partial wide rune on the left edge",
+			realSize: image.Point{2, 1},
+			size:     image.Point{4, 1},
+			draw: func(vt *Terminal) error {
+				if err := vt.SetCell(image.Point{0, 0}, '世'); err != nil {
+					return err
+				}
+				if err := vt.SetCell(image.Point{2, 0}, 'x'); err != nil {
+					return err
+				}
+				return vt.ScrollTo(image.Point{1, 0})
+			},
+			want: []string{
+				" x",
+			},
+		},
+		{
+			desc:     "hides wide rune that doesn't fit on the right edge",
+			realSize: image.Point{2, 1},
+			size:     image.Point{4, 1},
+			draw: func(vt *Terminal) error {
+				mustSetCells(vt, image.Point{0, 0}, "a")
+				return vt.SetCell(image.Point{1, 0}, '世')
+			},
+			want: []string{
+				"a ",
+			},
+		},
+		{
+			desc:     "scale policy samples rows and columns",
+			realSize: image.Point{2, 2},
+			size:     image.Point{4, 4},
+			opts:     []Option{Overflow(PolicyScale)},
+			draw: func(vt *Terminal) error {
+				grid(vt, 'a', 'k', 'u', 'A')
+				return nil
+			},
+			want: []string{
+				"ac",
+				"uw",
+			},
+		},
+		{
+			desc:     "scale policy ignores scrolling",
+			realSize: image.Point{2, 2},
+			size:     image.Point{4, 4},
+			opts:     []Option{Overflow(PolicyScale)},
+			draw: func(vt *Terminal) error {
+				grid(vt, 'a', 'k', 'u', 'A')
+				return vt.ScrollTo(image.Point{1, 1})
+			},
+			want: []string{
+				"ac",
+				"uw",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ft := faketerm.MustNew(tc.realSize)
+			vt, err := New(ft, tc.size, tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := tc.draw(vt); err != nil {
+				t.Fatalf("draw => unexpected error: %v", err)
+			}
+			if err := vt.Flush(); err != nil {
+				t.Fatalf("Flush => unexpected error: %v", err)
+			}
+			if diff := pretty.Compare(tc.want, rows(ft)); diff != "" {
+				t.Errorf("Flush => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFlushKeepsCellOptions(t *testing.T) {
+	ft := faketerm.MustNew(image.Point{1, 1})
+	vt, err := New(ft, image.Point{2, 2})
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := vt.SetCell(image.ZP, 'a', cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorBlue)); err != nil {
+		t.Fatalf("SetCell => unexpected error: %v", err)
+	}
+	if err := vt.Flush(); err != nil {
+		t.Fatalf("Flush => unexpected error: %v", err)
+	}
+
+	want := cell.NewOptions(cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorBlue))
+	if diff := pretty.Compare(want, ft.BackBuffer()[0][0].Opts); diff != "" {
+		t.Errorf("Flush => unexpected cell options diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestEvent(t *testing.T) {
+	scrollKeys := ScrollKeys(keyboard.KeyArrowUp, keyboard.KeyArrowDown, keyboard.KeyArrowLeft, keyboard.KeyArrowRight)
+
+	tests := []struct {
+		desc       string
+		size       image.Point
+		opts       []Option
+		events     []terminalapi.Event
+		want       []terminalapi.Event
+		wantOffset image.Point
+	}{
+		{
+			desc: "passes keyboard events through",
+			size: image.Point{4, 4},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+			},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+			},
+		},
+		{
+			desc: "consumes resize events",
+			size: image.Point{4, 4},
+			events: []terminalapi.Event{
+				&terminalapi.Resize{Size: image.Point{3, 3}},
+				&terminalapi.Keyboard{Key: 'a'},
+			},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+			},
+		},
+		{
+			desc: "scroll keys scroll and are consumed",
+			size: image.Point{4, 4},
+			opts: []Option{scrollKeys},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+				&terminalapi.Keyboard{Key: 'a'},
+			},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+			},
+			wantOffset: image.Point{1, 1},
+		},
+		{
+			desc: "scroll keys are passed through with the scale policy",
+			size: image.Point{4, 4},
+			opts: []Option{scrollKeys, Overflow(PolicyScale)},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+			},
+			want: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+			},
+		},
+		{
+			desc: "converts mouse events when scrolled",
+			size: image.Point{4, 4},
+			opts: []Option{scrollKeys},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Mouse{Position: image.Point{1, 0}, Button: mouse.ButtonLeft},
+			},
+			want: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonLeft},
+			},
+			wantOffset: image.Point{0, 1},
+		},
+		{
+			desc: "converts mouse events when scaled",
+			size: image.Point{4, 4},
+			opts: []Option{Overflow(PolicyScale)},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonLeft},
+			},
+			want: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{2, 2}, Button: mouse.ButtonLeft},
+			},
+		},
+		{
+			desc: "drops mouse events outside of the virtual terminal",
+			size: image.Point{1, 1},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 1}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft},
+			},
+			want: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			eq := eventqueue.New()
+			for _, ev := range tc.events {
+				eq.Push(ev)
+			}
+			ft := faketerm.MustNew(image.Point{2, 2}, faketerm.WithEventQueue(eq))
+			vt, err := New(ft, tc.size, tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+
+			var got []terminalapi.Event
+			for range tc.want {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				ev := vt.Event(ctx)
+				cancel()
+				if ev == nil {
+					break
+				}
+				got = append(got, ev)
+			}
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("Event => unexpected diff (-want, +got):\n%s", diff)
+			}
+			if got := vt.Offset(); got != tc.wantOffset {
+				t.Errorf("Offset => %v, want %v", got, tc.wantOffset)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package undo implements an undo stack for widgets that allow the user to
+// edit their content.
+//
+// Widgets record every edit as a Change on a Stack, which then undoes and
+// redoes the changes in order. This gives all the editable widgets identical
+// undo semantics.
+package undo
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Change is a reversible change of the content of a widget.
+type Change interface {
+	// Undo reverts the change.
+	Undo()
+	// Redo applies the change again after it was reverted.
+	Redo()
+}
+
+// Merger can optionally be implemented by a Change that can absorb the change
+// recorded immediately after it, so that both are undone in one step. E.g.
+// consecutive characters typed by the user.
+type Merger interface {
+	// Merge attempts to absorb the next change into this one.
+	// Returns true if the next change was merged.
+	Merge(next Change) bool
+}
+
+// Reporter can optionally be implemented by a Change that reports to the user
+// when it is undone or redone, e.g. by calling a callback provided by the user.
+// Unlike the Change methods, Report is called after the Stack is unlocked, so
+// it may call methods of the Stack.
+type Reporter interface {
+	// Report is called after the change was undone or redone.
+	Report() error
+}
+
+// Option is used to provide options to New().
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// options stores the provided options.
+type options struct {
+	depth int
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.depth < 1 {
+		return fmt.Errorf("invalid Depth(%d), must be a positive integer", o.depth)
+	}
+	return nil
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// DefaultDepth is the default value for the Depth option.
+const DefaultDepth = 100
+
+// Depth sets the maximum number of changes that can be undone. The oldest
+// changes are forgotten when more changes are recorded.
+// Defaults to DefaultDepth.
+func Depth(changes int) Option {
+	return option(func(opts *options) {
+		opts.depth = changes
+	})
+}
+
+// Stack records changes so they can be undone and redone.
+//
+// This object is thread-safe, but the Change methods are called while the
+// Stack is locked and must not call methods of the Stack.
+type Stack struct {
+	// done are the changes that can be undone, the most recent last.
+	done []Change
+	// undone are the changes that can be redone, the most recently undone
+	// last.
+	undone []Change
+
+	// sealed indicates that the most recent change must not be merged with
+	// the next one, because changes were undone or redone since it was
+	// recorded.
+	sealed bool
+
+	// mu protects the Stack.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new empty Stack.
+func New(opts ...Option) (*Stack, error) {
+	opt := &options{
+		depth: DefaultDepth,
+	}
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Stack{
+		opts: opt,
+	}, nil
+}
+
+// Record records a change that was just applied by the widget. Forgets all
+// the changes that could be redone.
+// If the most recent change implements Merger and agrees to merge, the change
+// is merged into it instead.
+func (s *Stack) Record(c Change) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.undone = nil
+	if n := len(s.done); n > 0 && !s.sealed {
+		if m, ok := s.done[n-1].(Merger); ok && m.Merge(c) {
+			return
+		}
+	}
+	s.sealed = false
+	s.done = append(s.done, c)
+	if over := len(s.done) - s.opts.depth; over > 0 {
+		s.done = s.done[over:]
+	}
+}
+
+// Seal prevents the next recorded change from being merged into the most
+// recent one, e.g. when the user moves the cursor between two edits.
+func (s *Stack) Seal() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sealed = true
+}
+
+// Undo reverts the most recent change.
+// Returns false if there was nothing to undo and the error returned by the
+// change if it implements Reporter.
+func (s *Stack) Undo() (bool, error) {
+	return report(s.undo())
+}
+
+// undo is the implementation of Undo.
+// Returns the reverted change or nil if there was nothing to undo.
+func (s *Stack) undo() Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.done)
+	if n == 0 {
+		return nil
+	}
+	c := s.done[n-1]
+	s.done = s.done[:n-1]
+	c.Undo()
+	s.undone = append(s.undone, c)
+	s.sealed = true
+	return c
+}
+
+// Redo applies the most recently undone change again.
+// Returns false if there was nothing to redo and the error returned by the
+// change if it implements Reporter.
+func (s *Stack) Redo() (bool, error) {
+	return report(s.redo())
+}
+
+// redo is the implementation of Redo.
+// Returns the applied change or nil if there was nothing to redo.
+func (s *Stack) redo() Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.undone)
+	if n == 0 {
+		return nil
+	}
+	c := s.undone[n-1]
+	s.undone = s.undone[:n-1]
+	c.Redo()
+	s.done = append(s.done, c)
+	s.sealed = true
+	return c
+}
+
+// report calls Report on the change if it implements Reporter.
+// Returns false if the change is nil.
+func report(c Change) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+	if r, ok := c.(Reporter); ok {
+		return true, r.Report()
+	}
+	return true, nil
+}
+
+// CanUndo determines if there are changes that can be undone.
+func (s *Stack) CanUndo() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.done) > 0
+}
+
+// CanRedo determines if there are changes that can be redone.
+func (s *Stack) CanRedo() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.undone) > 0
+}
+
+// Clear forgets all the recorded changes.
+func (s *Stack) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.done = nil
+	s.undone = nil
+	s.sealed = false
+}
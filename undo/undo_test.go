@@ -0,0 +1,267 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package undo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// value is the content edited in the tests.
+type value struct {
+	v int
+}
+
+// setChange changes the value.
+type setChange struct {
+	target    *value
+	from, to  int
+	mergeable bool
+}
+
+// Undo implements Change.Undo.
+func (sc *setChange) Undo() {
+	sc.target.v = sc.from
+}
+
+// Redo implements Change.Redo.
+func (sc *setChange) Redo() {
+	sc.target.v = sc.to
+}
+
+// Merge implements Merger.Merge.
+func (sc *setChange) Merge(next Change) bool {
+	n, ok := next.(*setChange)
+	if !ok || !sc.mergeable || !n.mergeable {
+		return false
+	}
+	sc.to = n.to
+	return true
+}
+
+// op is an operation performed on the stack in the tests.
+type op int
+
+const (
+	opSet op = iota
+	opSetMergeable
+	opUndo
+	opRedo
+	opSeal
+	opClear
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		desc    string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			desc: "succeeds with default options",
+		},
+		{
+			desc: "succeeds with a valid depth",
+			opts: []Option{
+				Depth(1),
+			},
+		},
+		{
+			desc: "fails on zero depth",
+			opts: []Option{
+				Depth(0),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := New(tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestStack(t *testing.T) {
+	tests := []struct {
+		desc        string
+		opts        []Option
+		ops         []op
+		wantValue   int
+		wantCanUndo bool
+		wantCanRedo bool
+	}{
+		{
+			desc:      "nothing to undo or redo initially",
+			ops:       []op{opUndo, opRedo},
+			wantValue: 0,
+		},
+		{
+			desc:        "undoes changes in reverse order",
+			ops:         []op{opSet, opSet, opSet, opUndo, opUndo},
+			wantValue:   1,
+			wantCanUndo: true,
+			wantCanRedo: true,
+		},
+		{
+			desc:        "redoes undone changes",
+			ops:         []op{opSet, opSet, opUndo, opUndo, opRedo},
+			wantValue:   1,
+			wantCanUndo: true,
+			wantCanRedo: true,
+		},
+		{
+			desc:        "recording a change forgets the undone changes",
+			ops:         []op{opSet, opSet, opUndo, opSet, opRedo},
+			wantValue:   2,
+			wantCanUndo: true,
+		},
+		{
+			desc:        "forgets the oldest changes beyond the depth",
+			opts:        []Option{Depth(2)},
+			ops:         []op{opSet, opSet, opSet, opUndo, opUndo, opUndo},
+			wantValue:   1,
+			wantCanRedo: true,
+		},
+		{
+			desc:        "merges mergeable changes",
+			ops:         []op{opSet, opSetMergeable, opSetMergeable, opUndo},
+			wantValue:   1,
+			wantCanUndo: true,
+			wantCanRedo: true,
+		},
+		{
+			desc:        "doesn't merge after seal",
+			ops:         []op{opSetMergeable, opSeal, opSetMergeable, opUndo},
+			wantValue:   1,
+			wantCanUndo: true,
+			wantCanRedo: true,
+		},
+		{
+			desc:        "doesn't merge into a change that was undone and redone",
+			ops:         []op{opSetMergeable, opUndo, opRedo, opSetMergeable, opUndo},
+			wantValue:   1,
+			wantCanUndo: true,
+			wantCanRedo: true,
+		},
+		{
+			desc:      "clear forgets all the changes",
+			ops:       []op{opSet, opSet, opUndo, opClear, opUndo},
+			wantValue: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+
+			val := &value{}
+			for _, o := range tc.ops {
+				switch o {
+				case opSet, opSetMergeable:
+					c := &setChange{
+						target:    val,
+						from:      val.v,
+						to:        val.v + 1,
+						mergeable: o == opSetMergeable,
+					}
+					c.Redo()
+					s.Record(c)
+				case opUndo:
+					s.Undo()
+				case opRedo:
+					s.Redo()
+				case opSeal:
+					s.Seal()
+				case opClear:
+					s.Clear()
+				}
+			}
+
+			if val.v != tc.wantValue {
+				t.Errorf("value => %d, want %d", val.v, tc.wantValue)
+			}
+			if got := s.CanUndo(); got != tc.wantCanUndo {
+				t.Errorf("CanUndo => %v, want %v", got, tc.wantCanUndo)
+			}
+			if got := s.CanRedo(); got != tc.wantCanRedo {
+				t.Errorf("CanRedo => %v, want %v", got, tc.wantCanRedo)
+			}
+		})
+	}
+}
+
+// reportChange is a change that implements Reporter.
+type reportChange struct {
+	setChange
+
+	// stack is the stack the change is recorded on.
+	stack *Stack
+	// reported are the values of CanUndo observed by Report.
+	reported []bool
+	// err is returned by Report.
+	err error
+}
+
+// Report implements Reporter.Report.
+func (rc *reportChange) Report() error {
+	// Must not deadlock, the Stack is unlocked when Report is called.
+	rc.reported = append(rc.reported, rc.stack.CanUndo())
+	return rc.err
+}
+
+func TestReporter(t *testing.T) {
+	s, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	val := &value{}
+	c := &reportChange{
+		setChange: setChange{target: val, to: 1},
+		stack:     s,
+	}
+	c.Redo()
+	s.Record(c)
+
+	if ok, err := s.Undo(); !ok || err != nil {
+		t.Fatalf("Undo => %v, %v, want true, nil", ok, err)
+	}
+	if want := 0; val.v != want {
+		t.Errorf("after Undo value => %d, want %d", val.v, want)
+	}
+
+	c.err = errors.New("report failed")
+	if ok, err := s.Redo(); !ok || err == nil {
+		t.Fatalf("Redo => %v, %v, want true and an error", ok, err)
+	}
+	if want := 1; val.v != want {
+		t.Errorf("after Redo value => %d, want %d", val.v, want)
+	}
+
+	if ok, err := s.Redo(); ok || err != nil {
+		t.Errorf("Redo => %v, %v, want false, nil when there is nothing to redo", ok, err)
+	}
+	if diff := pretty.Compare([]bool{false, true}, c.reported); diff != "" {
+		t.Errorf("Report => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgetapi
+
+// base.go contains a helper that widgets outside of termdash can embed.
+
+import (
+	"errors"
+	"image"
+	"sync"
+
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Base implements the parts of Widget and of the optional lifecycle
+// interfaces that most widgets share. Widgets embed it and implement Draw,
+// overriding Keyboard and Mouse if they want input events:
+//
+//	type Clock struct {
+//	  widgetapi.Base
+//	}
+//
+//	func New() *Clock {
+//	  c := &Clock{}
+//	  c.SetOptions(widgetapi.Options{MinimumSize: image.Point{8, 1}})
+//	  return c
+//	}
+//
+// The zero value is ready to use and reports the zero Options.
+// This object is thread-safe.
+type Base struct {
+	// mu protects the Base.
+	mu sync.Mutex
+
+	// opts are the options returned by Options.
+	opts Options
+
+	// notify is the function provided via SetNotifyFunc.
+	notify func()
+
+	// mounted and focused record the lifecycle of the widget.
+	mounted bool
+	focused bool
+
+	// theme is the last provided theme or nil.
+	theme *Theme
+}
+
+// Options returns the options last set via SetOptions.
+// Implements Widget.Options.
+func (b *Base) Options() Options {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.opts
+}
+
+// SetOptions sets the options returned by Options.
+func (b *Base) SetOptions(opts Options) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.opts = opts
+}
+
+// SetMinimumSize sets the minimum size of the canvas in the options, see
+// Options.MinimumSize. Useful for widgets whose minimum size depends on
+// their content.
+func (b *Base) SetMinimumSize(size image.Point) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.opts.MinimumSize = size
+}
+
+// Fits asserts whether the size of a canvas is at least the minimum size set
+// in the options. The infrastructure doesn't draw widgets on canvases that
+// are too small, but the minimum size might have changed since the canvas
+// was allocated.
+func (b *Base) Fits(size image.Point) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	min := b.opts.MinimumSize
+	return size.X >= min.X && size.Y >= min.Y
+}
+
+// Keyboard input isn't supported unless the embedding widget overrides this
+// method.
+// Implements Widget.Keyboard.
+func (*Base) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the widget doesn't support keyboard events")
+}
+
+// Mouse input isn't supported unless the embedding widget overrides this
+// method.
+// Implements Widget.Mouse.
+func (*Base) Mouse(m *terminalapi.Mouse) error {
+	return errors.New("the widget doesn't support mouse events")
+}
+
+// SetNotifyFunc implements Notifier.SetNotifyFunc.
+func (b *Base) SetNotifyFunc(fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.notify = fn
+}
+
+// Notify notifies the infrastructure that the content of the widget changed.
+// Does nothing if the infrastructure didn't provide a function, see
+// Notifier.
+func (b *Base) Notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.notify != nil {
+		b.notify()
+	}
+}
+
+// Mount implements Mounter.Mount.
+func (b *Base) Mount() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mounted = true
+}
+
+// Unmount implements Mounter.Unmount.
+func (b *Base) Unmount() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mounted = false
+	b.focused = false
+}
+
+// Mounted asserts whether the widget is placed in the containers.
+func (b *Base) Mounted() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.mounted
+}
+
+// FocusChanged implements FocusObserver.FocusChanged.
+func (b *Base) FocusChanged(focused bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.focused = focused
+}
+
+// Focused asserts whether the container of the widget is focused.
+func (b *Base) Focused() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.focused
+}
+
+// SetTheme implements Themer.SetTheme.
+func (b *Base) SetTheme(t Theme) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.theme = &t
+}
+
+// Theme returns the last provided theme and true, or false if no theme was
+// provided.
+func (b *Base) Theme() (Theme, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.theme == nil {
+		return Theme{}, false
+	}
+	return *b.theme, true
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgetapi
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+)
+
+func TestBase(t *testing.T) {
+	var b Base
+	if diff := pretty.Compare(Options{}, b.Options()); diff != "" {
+		t.Errorf("Options => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	b.SetOptions(Options{WantMouse: MouseScopeWidget})
+	b.SetMinimumSize(image.Point{3, 2})
+	want := Options{
+		MinimumSize: image.Point{3, 2},
+		WantMouse:   MouseScopeWidget,
+	}
+	if diff := pretty.Compare(want, b.Options()); diff != "" {
+		t.Errorf("Options => unexpected diff (-want, +got):\n%s", diff)
+	}
+	for _, tc := range []struct {
+		size image.Point
+		want bool
+	}{
+		{image.Point{3, 2}, true},
+		{image.Point{2, 5}, false},
+		{image.Point{5, 1}, false},
+	} {
+		if got := b.Fits(tc.size); got != tc.want {
+			t.Errorf("Fits(%v) => %v, want %v", tc.size, got, tc.want)
+		}
+	}
+
+	if err := b.Keyboard(nil); err == nil {
+		t.Errorf("Keyboard => got nil error, want an error")
+	}
+	if err := b.Mouse(nil); err == nil {
+		t.Errorf("Mouse => got nil error, want an error")
+	}
+
+	b.Notify() // Doesn't panic without a function.
+	notified := 0
+	b.SetNotifyFunc(func() { notified++ })
+	b.Notify()
+	if notified != 1 {
+		t.Errorf("Notify => called the function %d times, want 1", notified)
+	}
+
+	if _, ok := b.Theme(); ok {
+		t.Errorf("Theme => got ok before SetTheme, want !ok")
+	}
+	th := Theme{Name: "dark", Accent: cell.ColorRed}
+	b.SetTheme(th)
+	if got, ok := b.Theme(); !ok || got != th {
+		t.Errorf("Theme => %v, %v, want %v, true", got, ok, th)
+	}
+
+	b.Mount()
+	b.FocusChanged(true)
+	if !b.Mounted() || !b.Focused() {
+		t.Errorf("Mounted, Focused => %v, %v, want true, true", b.Mounted(), b.Focused())
+	}
+	b.Unmount()
+	if b.Mounted() || b.Focused() {
+		t.Errorf("after Unmount: Mounted, Focused => %v, %v, want false, false", b.Mounted(), b.Focused())
+	}
+}
+
+// baseWidget is a widget built on Base.
+type baseWidget struct {
+	Base
+}
+
+// Draw implements Widget.Draw.
+func (*baseWidget) Draw(*canvas.Canvas, *Meta) error {
+	return nil
+}
+
+// The widgets built on Base implement the lifecycle interfaces.
+var (
+	_ Widget        = &baseWidget{}
+	_ Notifier      = &baseWidget{}
+	_ Mounter       = &baseWidget{}
+	_ FocusObserver = &baseWidget{}
+	_ Themer        = &baseWidget{}
+)
@@ -0,0 +1,84 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgetapi
+
+// registry.go contains the registry of widget kinds.
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Factory creates a new widget of a registered kind.
+type Factory func() (Widget, error)
+
+var (
+	// factoriesMu protects factories.
+	factoriesMu sync.Mutex
+	// factories are the registered factories keyed by the kind.
+	factories = map[string]Factory{}
+)
+
+// Register makes a kind of widgets available by its name, e.g. to layouts
+// built by the layoutspec package. Packages that provide widgets usually
+// call this from their init function. The kind should be qualified by the
+// name of the package, e.g. "clock.Analog", to avoid conflicts.
+// Panics if the kind is empty, the factory is nil or the kind is already
+// registered.
+func Register(kind string, f Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if kind == "" {
+		panic("widgetapi: the kind of a registered widget cannot be empty")
+	}
+	if f == nil {
+		panic(fmt.Sprintf("widgetapi: the factory of widget kind %q is nil", kind))
+	}
+	if _, ok := factories[kind]; ok {
+		panic(fmt.Sprintf("widgetapi: widget kind %q is already registered", kind))
+	}
+	factories[kind] = f
+}
+
+// NewOf returns a new widget of the registered kind.
+func NewOf(kind string) (Widget, error) {
+	factoriesMu.Lock()
+	f, ok := factories[kind]
+	factoriesMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no widget kind %q is registered", kind)
+	}
+	w, err := f()
+	if err != nil {
+		return nil, fmt.Errorf("the factory of widget kind %q failed: %v", kind, err)
+	}
+	return w, nil
+}
+
+// Kinds returns the registered kinds of widgets in alphabetical order.
+func Kinds() []string {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	var kinds []string
+	for k := range factories {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgetapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestRegistry(t *testing.T) {
+	// Start with an empty registry and restore the global one afterwards.
+	factoriesMu.Lock()
+	saved := factories
+	factories = map[string]Factory{}
+	factoriesMu.Unlock()
+	defer func() {
+		factoriesMu.Lock()
+		defer factoriesMu.Unlock()
+		factories = saved
+	}()
+
+	w := &baseWidget{}
+	Register("widgetapi.test", func() (Widget, error) {
+		return w, nil
+	})
+	Register("widgetapi.failing", func() (Widget, error) {
+		return nil, errors.New("failed")
+	})
+
+	got, err := NewOf("widgetapi.test")
+	if err != nil {
+		t.Fatalf("NewOf => unexpected error: %v", err)
+	}
+	if got != w {
+		t.Errorf("NewOf => %v, want %v", got, w)
+	}
+	if _, err := NewOf("widgetapi.failing"); err == nil {
+		t.Errorf("NewOf(failing) => got nil error, want an error")
+	}
+	if _, err := NewOf("widgetapi.unknown"); err == nil {
+		t.Errorf("NewOf(unknown) => got nil error, want an error")
+	}
+
+	want := []string{"widgetapi.failing", "widgetapi.test"}
+	if diff := pretty.Compare(want, Kinds()); diff != "" {
+		t.Errorf("Kinds => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	for _, tc := range []struct {
+		desc string
+		kind string
+		f    Factory
+	}{
+		{"empty kind", "", func() (Widget, error) { return w, nil }},
+		{"nil factory", "widgetapi.nil", nil},
+		{"duplicate kind", "widgetapi.test", func() (Widget, error) { return w, nil }},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Register => didn't panic")
+				}
+			}()
+			Register(tc.kind, tc.f)
+		})
+	}
+}
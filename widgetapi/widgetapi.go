@@ -17,7 +17,11 @@ package widgetapi
 
 import (
 	"image"
+	"time"
 
+	"github.com/mum4k/termdash/bus"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/gesture"
 	"github.com/mum4k/termdash/private/canvas"
 	"github.com/mum4k/termdash/terminal/terminalapi"
 )
@@ -137,12 +141,24 @@ type Options struct {
 	// if it falls onto its canvas. See the documentation next to individual
 	// MouseScope values for details.
 	WantMouse MouseScope
+
+	// WantHover indicates that the widget also receives the mouse events with
+	// mouse.ButtonNone that report the mouse pointer hovering within the scope
+	// requested by WantMouse. These events are only reported by some
+	// terminals, see terminalapi.Capabilities.Hover.
+	WantHover bool
 }
 
 // Meta provide additional metadata to widgets.
 type Meta struct {
 	// Focused asserts whether the widget's container is focused.
 	Focused bool
+
+	// Capabilities are the features supported by the terminal the widget is
+	// drawn on. Widgets can use these to degrade gracefully, although the
+	// infrastructure already substitutes any runes the terminal cannot
+	// display.
+	Capabilities terminalapi.Capabilities
 }
 
 // Widget is a single widget on the dashboard.
@@ -183,3 +199,168 @@ type Widget interface {
 	// Draw.
 	Options() Options
 }
+
+// ChangeTracker is an optional interface that widgets can implement to report
+// whether their content changed since they were last drawn.
+//
+// The infrastructure doesn't call Draw on widgets that report no changes,
+// instead it reuses the content they drew the last time. This saves the work
+// of drawing widgets that rarely change on dashboards that are redrawn often.
+// The content is only reused if the canvas and the metadata passed to Draw
+// would be the same as the last time.
+type ChangeTracker interface {
+	// Changed returns true if the content of the widget changed since the
+	// last call to Draw, i.e. if drawing the widget again would result in a
+	// different content on the same canvas. Implementations must return true
+	// before the first call to Draw.
+	Changed() bool
+}
+
+// Notifier is an optional interface that widgets can implement to notify the
+// infrastructure as soon as their content changes. This allows termdash to
+// redraw the screen only when needed, see the termdash.RedrawOnChange option.
+type Notifier interface {
+	// SetNotifyFunc provides the function that the widget must call each time
+	// its content changes. The function is thread-safe and doesn't block, so
+	// the widget can call it while holding its own locks.
+	SetNotifyFunc(fn func())
+}
+
+// Paster is an optional interface that widgets can implement to receive
+// pasted text in one piece. Widgets that want keyboard events, but don't
+// implement this interface, receive the pasted text as a sequence of Keyboard
+// events, one per character.
+type Paster interface {
+	// Paste is called with the text the user pasted while the widget was a
+	// target of keyboard events, see Options.WantKeyboard.
+	Paste(text string)
+}
+
+// Composer is an optional interface that widgets can implement to display the
+// text the user composes with an input method editor and to receive the
+// composed text in one piece, see terminalapi.Composition. Widgets that want
+// keyboard events, but don't implement this interface, don't display the
+// composition and receive the committed text as a sequence of Keyboard
+// events, one per character.
+type Composer interface {
+	// Compose is called with the preedit text and the position of the cursor
+	// within it in runes each time the composition changes while the widget
+	// is a target of keyboard events, see Options.WantKeyboard. Empty
+	// preedit text ends the composition.
+	Compose(preedit string, cursor int)
+	// Commit is called with the composed text, which ends the composition.
+	Commit(text string)
+}
+
+// Sensitive is an optional interface that widgets can implement to mark parts
+// of their content as sensitive, e.g. credentials or personal data. The
+// sensitive content is masked while the privacy mode is enabled, see
+// container.Container.SensitiveAreas and postprocess.Privacy.
+type Sensitive interface {
+	// SensitiveAreas returns the areas of the canvas provided to the last
+	// call to Draw that display sensitive content.
+	SensitiveAreas() []image.Rectangle
+}
+
+// Tooltip is a text displayed when the mouse pointer hovers over an area of
+// the widget's canvas.
+type Tooltip struct {
+	// Area is the area of the canvas, relative to the canvas.
+	Area image.Rectangle
+	// Text is the displayed text, it can contain newline characters.
+	Text string
+}
+
+// Tooltipper is an optional interface that widgets can implement to display
+// tooltips over parts of their content, e.g. the value of a bar under the
+// mouse pointer. The infrastructure displays the text in a floating box next
+// to the mouse pointer once it hovered over the area for the tooltip delay of
+// the container, see container.TooltipDelay. The tooltip of the widget takes
+// precedence over the tooltip of its container.
+type Tooltipper interface {
+	// Tooltips returns the tooltips of the content drawn on the last call to
+	// Draw. If the areas overlap, the first matching tooltip is displayed.
+	Tooltips() []Tooltip
+}
+
+// GestureHandler is an optional interface that widgets can implement to
+// receive touch gestures recognized in the mouse events, see the gesture
+// package. A gesture is delivered to the widget whose canvas contains the
+// position where the gesture started, the position is relative to the canvas.
+// Widgets receive the gestures regardless of Options.WantMouse, the mouse
+// events that make up the gesture are delivered as usual.
+type GestureHandler interface {
+	// Gesture is called with the recognized gesture.
+	// The widget can assume that this method is only called from a single
+	// goroutine at a time.
+	Gesture(g *gesture.Gesture) error
+}
+
+// Mounter is an optional interface that widgets can implement to learn when
+// they are placed into a tree of containers and when they are removed from it,
+// e.g. to start and stop goroutines that feed them data.
+//
+// A widget is mounted when a container holding it is created or updated to
+// hold it, see container.PlaceWidget. It is unmounted when an update of the
+// containers removes it from the tree. The methods are called without holding
+// the container lock, so they are allowed to update the containers.
+type Mounter interface {
+	// Mount is called when the widget was placed into the containers.
+	Mount()
+	// Unmount is called when the widget was removed from the containers.
+	Unmount()
+}
+
+// FocusObserver is an optional interface that widgets can implement to learn
+// when the container holding them gains or loses the keyboard focus.
+type FocusObserver interface {
+	// FocusChanged is called with true when the container of the widget
+	// became focused and with false when it lost the focus. Called without
+	// holding the container lock.
+	FocusChanged(focused bool)
+}
+
+// Theme is a set of colors provided to widgets by the application, see
+// container.Container.SetTheme. Widgets that implement Themer use these
+// instead of their own defaults.
+type Theme struct {
+	// Name identifies the theme, e.g. "dark".
+	Name string
+	// Foreground is the color of text.
+	Foreground cell.Color
+	// Background is the color of the background.
+	Background cell.Color
+	// Accent is the color of highlighted content, e.g. the selected item.
+	Accent cell.Color
+	// Muted is the color of less important content, e.g. axis labels.
+	Muted cell.Color
+}
+
+// Themer is an optional interface that widgets can implement to receive the
+// theme of the application.
+type Themer interface {
+	// SetTheme is called with the current theme when the widget is mounted
+	// and each time the theme changes. Called without holding the container
+	// lock.
+	SetTheme(t Theme)
+}
+
+// Ticker is an optional interface that widgets can implement to be called
+// periodically, e.g. to advance an animation or poll a data source, see the
+// termdash.TickInterval option.
+type Ticker interface {
+	// Tick is called once each tick interval while the widget is mounted.
+	// The widget can assume that this method is only called from a single
+	// goroutine at a time. Widgets on dashboards that only redraw on changes
+	// must notify the infrastructure if their content changed, see Notifier.
+	Tick(now time.Time)
+}
+
+// BusUser is an optional interface that widgets can implement to exchange
+// messages with other widgets and the application through the message bus of
+// the termdash instance, see the bus package.
+type BusUser interface {
+	// SetBus is called with the message bus when the widget is mounted.
+	// Called without holding the container lock.
+	SetBus(b *bus.Bus)
+}
@@ -49,6 +49,14 @@ type BarChart struct {
 
 	// lastWidth is the width of the canvas as of the last time when Draw was called.
 	lastWidth int
+	// tooltips are the hover regions of the bars as of the last time when
+	// Draw was called.
+	tooltips []widgetapi.Tooltip
+
+	// drawn indicates that the current content was drawn, see Changed.
+	drawn bool
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
 
 	// mu protects the BarChart.
 	mu sync.Mutex
@@ -77,7 +85,19 @@ func (bc *BarChart) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
 
+	if err := bc.draw(cvs); err != nil {
+		return err
+	}
+	// Only set after a successful draw, so that a failed one is retried.
+	bc.drawn = true
+	return nil
+}
+
+// draw draws the BarChart onto the canvas.
+// Caller must hold bc.mu.
+func (bc *BarChart) draw(cvs *canvas.Canvas) error {
 	bc.lastWidth = cvs.Area().Dx()
+	bc.tooltips = nil
 	needAr, err := area.FromSize(bc.minSize())
 	if err != nil {
 		return err
@@ -93,8 +113,9 @@ func (bc *BarChart) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 		}
 
 		if r.Dy() > 0 { // Value might be so small so that the rectangle is zero.
+			cellOpts := append([]cell.Option{cell.BgColor(bc.barColor(i))}, bc.opts.points[i].CellOpts...)
 			if err := draw.Rectangle(cvs, r,
-				draw.RectCellOpts(cell.BgColor(bc.barColor(i))),
+				draw.RectCellOpts(cellOpts...),
 				draw.RectChar(bc.opts.barChar),
 			); err != nil {
 				return err
@@ -102,7 +123,7 @@ func (bc *BarChart) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 		}
 
 		if bc.opts.showValues {
-			if err := bc.drawText(cvs, i, fmt.Sprint(bc.values[i]), bc.valColor(i), insideBar); err != nil {
+			if err := bc.drawText(cvs, i, bc.valueText(v), bc.valColor(i), insideBar); err != nil {
 				return err
 			}
 		}
@@ -113,10 +134,35 @@ func (bc *BarChart) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 				return err
 			}
 		}
+
+		full, err := bc.barRect(cvs, i, bc.max)
+		if err != nil {
+			return err
+		}
+		text := bc.valueText(v)
+		if l != "" {
+			text = fmt.Sprintf("%s: %s", l, text)
+		}
+		if pl := bc.opts.points[i].Label; pl != "" {
+			text = fmt.Sprintf("%s\n%s", text, pl)
+		}
+		bc.tooltips = append(bc.tooltips, widgetapi.Tooltip{
+			Area: full,
+			Text: text,
+		})
 	}
 	return nil
 }
 
+// Tooltips returns the value of each bar, displayed when the mouse pointer
+// hovers over the bar.
+// Implements widgetapi.Tooltipper.
+func (bc *BarChart) Tooltips() []widgetapi.Tooltip {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return append([]widgetapi.Tooltip(nil), bc.tooltips...)
+}
+
 // textLoc represents the location of the drawn text.
 type textLoc int
 
@@ -224,6 +270,14 @@ func (bc *BarChart) valColor(i int) cell.Color {
 	return DefaultValueColor
 }
 
+// valueText returns the text that displays the value.
+func (bc *BarChart) valueText(v int) string {
+	if f := bc.opts.formatter; f != nil {
+		return f(float64(v))
+	}
+	return fmt.Sprint(v)
+}
+
 // label safely determines the label and its color for the i-th bar.
 // Labels are optional and don't have to be specified for all the bars.
 func (bc *BarChart) label(i int) (string, cell.Color) {
@@ -263,6 +317,7 @@ func (bc *BarChart) ValueCapacity() int {
 func (bc *BarChart) Values(values []int, max int, opts ...Option) error {
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
+	bc.markChanged()
 
 	// Copy to avoid external modifications. See #174.
 	v := make([]int, len(values))
@@ -279,6 +334,29 @@ func (bc *BarChart) Values(values []int, max int, opts ...Option) error {
 	return nil
 }
 
+// Changed implements widgetapi.ChangeTracker.Changed.
+func (bc *BarChart) Changed() bool {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return !bc.drawn
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (bc *BarChart) SetNotifyFunc(fn func()) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.notify = fn
+}
+
+// markChanged records that the content changed and notifies the
+// infrastructure. The caller must hold bc.mu.
+func (bc *BarChart) markChanged() {
+	bc.drawn = false
+	if bc.notify != nil {
+		bc.notify()
+	}
+}
+
 // Keyboard input isn't supported on the BarChart widget.
 func (*BarChart) Keyboard(k *terminalapi.Keyboard) error {
 	return errors.New("the BarChart widget doesn't support keyboard events")
@@ -20,6 +20,7 @@ import (
 
 	"github.com/kylelemons/godebug/pretty"
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
 	"github.com/mum4k/termdash/private/canvas"
 	"github.com/mum4k/termdash/private/canvas/testcanvas"
 	"github.com/mum4k/termdash/private/draw"
@@ -188,6 +189,40 @@ func TestBarChart(t *testing.T) {
 			},
 			wantCapacity: 4,
 		},
+		{
+			desc: "displays bars with styled points",
+			opts: []Option{
+				Char('o'),
+				Points(map[int]Point{
+					2: {CellOpts: []cell.Option{cell.BgColor(cell.ColorBlue), cell.FgColor(cell.ColorRed)}},
+					3: {Label: "only a label"},
+				}),
+			},
+			update: func(bc *BarChart) error {
+				return bc.Values([]int{0, 2, 5, 10}, 10)
+			},
+			canvas: image.Rect(0, 0, 7, 10),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustRectangle(c, image.Rect(2, 8, 3, 10),
+					draw.RectChar('o'),
+					draw.RectCellOpts(cell.BgColor(DefaultBarColor)),
+				)
+				testdraw.MustRectangle(c, image.Rect(4, 5, 5, 10),
+					draw.RectChar('o'),
+					draw.RectCellOpts(cell.BgColor(cell.ColorBlue), cell.FgColor(cell.ColorRed)),
+				)
+				testdraw.MustRectangle(c, image.Rect(6, 0, 7, 10),
+					draw.RectChar('o'),
+					draw.RectCellOpts(cell.BgColor(DefaultBarColor)),
+				)
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+			wantCapacity: 4,
+		},
 		{
 			desc: "displays bars with labels",
 			opts: []Option{
@@ -852,3 +887,67 @@ func TestValueCapacity(t *testing.T) {
 		})
 	}
 }
+
+func TestTooltips(t *testing.T) {
+	bc, err := New(
+		BarWidth(1),
+		BarGap(1),
+		Labels([]string{"a"}),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if got := bc.Tooltips(); len(got) != 0 {
+		t.Errorf("Tooltips before Draw => %v, want none", got)
+	}
+
+	if err := bc.Values([]int{1, 2}, 4); err != nil {
+		t.Fatalf("Values => unexpected error: %v", err)
+	}
+	c, err := canvas.New(image.Rect(0, 0, 3, 5))
+	if err != nil {
+		t.Fatalf("canvas.New => unexpected error: %v", err)
+	}
+	if err := bc.Draw(c, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	want := []widgetapi.Tooltip{
+		{Area: image.Rect(0, 0, 1, 4), Text: "a: 1"},
+		{Area: image.Rect(2, 0, 3, 4), Text: "2"},
+	}
+	if diff := pretty.Compare(want, bc.Tooltips()); diff != "" {
+		t.Errorf("Tooltips => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestTooltipsWithValueFormatterAndPoints(t *testing.T) {
+	bc, err := New(
+		BarWidth(1),
+		BarGap(1),
+		Labels([]string{"a"}),
+		ValueFormatter(format.IEC("B")),
+		Points(map[int]Point{0: {Label: "anomaly"}}),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := bc.Values([]int{1536, 2048}, 4096); err != nil {
+		t.Fatalf("Values => unexpected error: %v", err)
+	}
+	c, err := canvas.New(image.Rect(0, 0, 3, 5))
+	if err != nil {
+		t.Fatalf("canvas.New => unexpected error: %v", err)
+	}
+	if err := bc.Draw(c, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	want := []widgetapi.Tooltip{
+		{Area: image.Rect(0, 0, 1, 4), Text: "a: 1.5KiB\nanomaly"},
+		{Area: image.Rect(2, 0, 3, 4), Text: "2KiB"},
+	}
+	if diff := pretty.Compare(want, bc.Tooltips()); diff != "" {
+		t.Errorf("Tooltips => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
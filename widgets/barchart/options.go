@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
 	"github.com/mum4k/termdash/private/draw"
 )
 
@@ -47,6 +48,8 @@ type options struct {
 	labelColors []cell.Color
 	valueColors []cell.Color
 	labels      []string
+	formatter   format.Formatter
+	points      map[int]Point
 }
 
 // validate validates the provided options.
@@ -100,6 +103,15 @@ func BarGap(width int) Option {
 	})
 }
 
+// ValueFormatter sets the function that formats the values displayed inside
+// the bars, see ShowValues, and in the tooltips, e.g. format.SI.
+// Defaults to the values displayed as integers.
+func ValueFormatter(f format.Formatter) Option {
+	return option(func(opts *options) {
+		opts.formatter = f
+	})
+}
+
 // ShowValues tells the bar chart to display the actual values inside each of the bars.
 func ShowValues() Option {
 	return option(func(opts *options) {
@@ -149,6 +161,28 @@ func Labels(labels []string) Option {
 	})
 }
 
+// Point is the metadata of an individual bar.
+type Point struct {
+	// CellOpts are applied to the cells of the bar on top of the color set
+	// by BarColors, e.g. to highlight an anomaly.
+	CellOpts []cell.Option
+	// Label describes the value. The label is displayed in the tooltip of
+	// the bar.
+	Label string
+}
+
+// Points attaches metadata to individual bars. The argument maps the indexes
+// of the values provided to Values() to the metadata of their bars.
+func Points(points map[int]Point) Option {
+	return option(func(opts *options) {
+		// Copy to avoid external modifications. See #174.
+		opts.points = make(map[int]Point, len(points))
+		for i, p := range points {
+			opts.points[i] = p
+		}
+	})
+}
+
 // DefaultValueColor is the default color of a bar value, unless specified
 // otherwise via the ValueColors option.
 const DefaultValueColor = cell.ColorYellow
@@ -73,6 +73,9 @@ type Button struct {
 
 	// opts are the provided options.
 	opts *options
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
 }
 
 // New returns a new Button that will display the provided text.
@@ -108,6 +111,9 @@ var (
 
 	// timeSince is a function that calculates duration since some time.
 	timeSince = time.Since
+
+	// afterFunc schedules the release of a button pressed by a keyboard key.
+	afterFunc = func(d time.Duration, f func()) { time.AfterFunc(d, f) }
 )
 
 // Draw draws the Button widget onto the canvas.
@@ -163,11 +169,21 @@ func (b *Button) keyActivated(k *terminalapi.Keyboard) bool {
 		b.state = button.Down
 		now := time.Now().UTC()
 		b.keyTriggerTime = &now
+		b.markChanged()
+		afterFunc(b.opts.keyUpDelay, b.keyReleased)
 		return true
 	}
 	return false
 }
 
+// keyReleased notifies the infrastructure that a button pressed by a keyboard
+// key should now be drawn as released.
+func (b *Button) keyReleased() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.markChanged()
+}
+
 // Keyboard processes keyboard events, acts as a button press on the configured
 // Key.
 //
@@ -188,6 +204,9 @@ func (b *Button) mouseActivated(m *terminalapi.Mouse) bool {
 	defer b.mu.Unlock()
 
 	clicked, state := b.mouseFSM.Event(m)
+	if state != b.state {
+		b.markChanged()
+	}
 	b.state = state
 	b.keyTriggerTime = nil
 
@@ -208,6 +227,21 @@ func (b *Button) Mouse(m *terminalapi.Mouse) error {
 	return nil
 }
 
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (b *Button) SetNotifyFunc(fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.notify = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// b.mu must be held when calling this method.
+func (b *Button) markChanged() {
+	if b.notify != nil {
+		b.notify()
+	}
+}
+
 // shadowWidth is the width of the shadow under the button in cell.
 const shadowWidth = 1
 
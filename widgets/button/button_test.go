@@ -845,3 +845,48 @@ func TestOptions(t *testing.T) {
 	}
 
 }
+
+func TestNotifies(t *testing.T) {
+	var release func()
+	afterFunc = func(_ time.Duration, f func()) { release = f }
+	defer func() {
+		afterFunc = func(d time.Duration, f func()) { time.AfterFunc(d, f) }
+	}()
+
+	ct := &callbackTracker{}
+	b, err := New("hello", ct.callback, Key(keyboard.KeyEnter))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	b.SetNotifyFunc(func() {
+		notified++
+	})
+
+	if err := b.Draw(testcanvas.MustNew(image.Rect(0, 0, 8, 4)), &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if err := b.Mouse(&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft}); err != nil {
+		t.Fatalf("Mouse => unexpected error: %v", err)
+	}
+	if err := b.Mouse(&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonRelease}); err != nil {
+		t.Fatalf("Mouse => unexpected error: %v", err)
+	}
+	if want := 2; notified != want {
+		t.Errorf("after mouse click notified %d times, want %d", notified, want)
+	}
+
+	if err := b.Keyboard(&terminalapi.Keyboard{Key: keyboard.KeyEnter}); err != nil {
+		t.Fatalf("Keyboard => unexpected error: %v", err)
+	}
+	if want := 3; notified != want {
+		t.Errorf("after key press notified %d times, want %d", notified, want)
+	}
+	if release == nil {
+		t.Fatalf("Keyboard => didn't schedule the key release")
+	}
+	release()
+	if want := 4; notified != want {
+		t.Errorf("after key release notified %d times, want %d", notified, want)
+	}
+}
@@ -0,0 +1,504 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package candlestick implements a widget that displays financial data as a
+// candlestick (OHLC) chart.
+package candlestick
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/braille"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// Candle is the open, high, low and close price of one time bucket.
+type Candle struct {
+	Open  float64
+	High  float64
+	Low   float64
+	Close float64
+}
+
+// validate validates the candle.
+func (c Candle) validate() error {
+	for _, v := range []float64{c.Open, c.High, c.Low, c.Close} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("invalid candle %+v, the prices must be finite numbers", c)
+		}
+	}
+	if c.High < math.Max(c.Open, c.Close) || c.Low > math.Min(c.Open, c.Close) {
+		return fmt.Errorf("invalid candle %+v, must be Low <= Open, Close <= High", c)
+	}
+	return nil
+}
+
+// up determines if the candle closed at or above its open price.
+func (c Candle) up() bool {
+	return c.Close >= c.Open
+}
+
+// cellsPerCandle is the width of one candle in cells. Each candle is three
+// braille pixels wide with one pixel of space before the next candle.
+const cellsPerCandle = 2
+
+// yLabelGap is the number of rows between the labels on the price axis.
+const yLabelGap = 2
+
+// Candlestick displays financial data as a candlestick chart.
+//
+// Each candle occupies two cells and is drawn using braille characters, the
+// body spans the open and close prices and the wick spans the high and low
+// prices. The price axis scales to the visible candles. The newest candles
+// are displayed on the right, older candles are reached by scrolling with
+// the keyboard or the mouse wheel. Moving the mouse over the chart displays a
+// crosshair and the prices of the candle under it.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Candlestick struct {
+	// candles are the displayed candles, the oldest first.
+	candles []Candle
+
+	// offset is the number of newest candles scrolled out of view on the
+	// right.
+	offset int
+
+	// capacity is the number of candles that fit onto the canvas as of the
+	// last call to Draw.
+	capacity int
+
+	// crosshair is the position of the mouse pointer on the canvas or nil if
+	// the pointer isn't over the chart.
+	crosshair *image.Point
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the Candlestick.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new Candlestick.
+func New(opts ...Option) (*Candlestick, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Candlestick{
+		opts: opt,
+	}, nil
+}
+
+// Series replaces the displayed candles and scrolls to the newest candle.
+// The candles must be ordered from the oldest to the newest.
+func (cs *Candlestick) Series(candles []Candle) error {
+	for i, c := range candles {
+		if err := c.validate(); err != nil {
+			return fmt.Errorf("candle[%d]: %v", i, err)
+		}
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.candles = append([]Candle(nil), candles...)
+	cs.offset = 0
+	cs.markChanged()
+	return nil
+}
+
+// Add appends the candles as the newest ones. If the chart was scrolled back
+// in history, it remains on the same candles.
+func (cs *Candlestick) Add(candles ...Candle) error {
+	for i, c := range candles {
+		if err := c.validate(); err != nil {
+			return fmt.Errorf("candle[%d]: %v", i, err)
+		}
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.candles = append(cs.candles, candles...)
+	if cs.offset > 0 {
+		cs.offset += len(candles)
+	}
+	cs.markChanged()
+	return nil
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (cs *Candlestick) SetNotifyFunc(fn func()) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.notify = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// The caller must hold cs.mu.
+func (cs *Candlestick) markChanged() {
+	if cs.notify != nil {
+		cs.notify()
+	}
+}
+
+// maxOffset returns the largest offset that still fills the canvas.
+// The caller must hold cs.mu.
+func (cs *Candlestick) maxOffset() int {
+	if max := len(cs.candles) - cs.capacity; max > 0 {
+		return max
+	}
+	return 0
+}
+
+// scrollBy scrolls the chart by the number of candles, positive values
+// scroll back in history.
+// The caller must hold cs.mu.
+func (cs *Candlestick) scrollBy(candles int) {
+	cs.offset += candles
+	if max := cs.maxOffset(); cs.offset > max {
+		cs.offset = max
+	}
+	if cs.offset < 0 {
+		cs.offset = 0
+	}
+}
+
+// layout are the areas of the canvas used by the chart.
+type layout struct {
+	// readout is the row with the prices of the selected candle.
+	readout image.Rectangle
+	// labels is the area with the labels of the price axis.
+	labels image.Rectangle
+	// axis is the column with the price axis.
+	axis image.Rectangle
+	// plot is the area with the candles.
+	plot image.Rectangle
+}
+
+// newLayout determines the areas of the canvas given the width of the
+// labels. Returns false if the canvas is too small to fit at least one
+// candle.
+func newLayout(cvsAr image.Rectangle, labelWidth int) (*layout, bool) {
+	plotX := cvsAr.Min.X + labelWidth + 1
+	if cvsAr.Dy() < 2 || cvsAr.Max.X-plotX < cellsPerCandle {
+		return nil, false
+	}
+	top := cvsAr.Min.Y + 1
+	return &layout{
+		readout: image.Rect(cvsAr.Min.X, cvsAr.Min.Y, cvsAr.Max.X, top),
+		labels:  image.Rect(cvsAr.Min.X, top, plotX-1, cvsAr.Max.Y),
+		axis:    image.Rect(plotX-1, top, plotX, cvsAr.Max.Y),
+		plot:    image.Rect(plotX, top, cvsAr.Max.X, cvsAr.Max.Y),
+	}, true
+}
+
+// priceRange returns the lowest and the highest price of the candles. The
+// range is widened if all the prices are equal.
+func priceRange(candles []Candle) (float64, float64) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, c := range candles {
+		min = math.Min(min, c.Low)
+		max = math.Max(max, c.High)
+	}
+	if min == max {
+		return min - 1, max + 1
+	}
+	return min, max
+}
+
+// scale converts prices to pixel rows on the plot and back.
+type scale struct {
+	min, max float64
+	// pixels is the height of the plot in braille pixels.
+	pixels int
+}
+
+// pixel returns the pixel row of the price.
+func (s *scale) pixel(v float64) int {
+	return int(math.Round((s.max - v) / (s.max - s.min) * float64(s.pixels-1)))
+}
+
+// value returns the price at the pixel row.
+func (s *scale) value(pixel int) float64 {
+	return s.max - float64(pixel)/float64(s.pixels-1)*(s.max-s.min)
+}
+
+// rowValue returns the price displayed as the label of the row of cells on
+// the plot. Rows are labeled with the price of their top pixel, the bottom
+// row with the price of its bottom pixel so that the lowest price is labeled.
+func (s *scale) rowValue(row int) float64 {
+	if p := (row + 1) * braille.RowMult; p >= s.pixels {
+		return s.value(s.pixels - 1)
+	}
+	return s.value(row * braille.RowMult)
+}
+
+// Draw draws the Candlestick widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (cs *Candlestick) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	// The width of the labels is determined from all the candles so that it
+	// doesn't change while scrolling.
+	var labelWidth int
+	if len(cs.candles) > 0 {
+		min, max := priceRange(cs.candles)
+		for _, v := range []float64{min, max} {
+			if w := runewidth.StringWidth(cs.opts.valueFormatter(v)); w > labelWidth {
+				labelWidth = w
+			}
+		}
+	}
+	lay, ok := newLayout(cvs.Area(), labelWidth)
+	if !ok {
+		return draw.ResizeNeeded(cvs)
+	}
+
+	cs.capacity = lay.plot.Dx() / cellsPerCandle
+	cs.scrollBy(0) // Clamps the offset to the new capacity.
+	end := len(cs.candles) - cs.offset
+	start := end - cs.capacity
+	if start < 0 {
+		start = 0
+	}
+	visible := cs.candles[start:end]
+	// The newest candles are displayed on the right.
+	firstSlot := cs.capacity - len(visible)
+
+	if err := cs.drawAxis(cvs, lay); err != nil {
+		return err
+	}
+	if len(visible) == 0 {
+		return nil
+	}
+
+	min, max := priceRange(visible)
+	sc := &scale{
+		min:    min,
+		max:    max,
+		pixels: lay.plot.Dy() * braille.RowMult,
+	}
+	if err := cs.drawCandles(cvs, lay, sc, visible, firstSlot); err != nil {
+		return err
+	}
+	if err := cs.drawLabels(cvs, lay, sc); err != nil {
+		return err
+	}
+
+	selected := visible[len(visible)-1]
+	if ch := cs.crosshair; ch != nil && ch.In(lay.plot) {
+		slot := (ch.X - lay.plot.Min.X) / cellsPerCandle
+		if i := slot - firstSlot; i >= 0 && i < len(visible) {
+			selected = visible[i]
+		}
+		if err := cs.drawCrosshair(cvs, lay, sc, *ch, slot); err != nil {
+			return err
+		}
+	}
+	return cs.drawReadout(cvs, lay, selected)
+}
+
+// drawAxis draws the vertical line of the price axis.
+func (cs *Candlestick) drawAxis(cvs *canvas.Canvas, lay *layout) error {
+	return draw.HVLines(cvs, []draw.HVLine{{
+		Start: lay.axis.Min,
+		End:   image.Point{lay.axis.Min.X, lay.axis.Max.Y - 1},
+	}}, draw.HVLineCellOpts(cs.opts.axisCellOpts...))
+}
+
+// drawCandles draws the candles, the first of them into the specified slot.
+func (cs *Candlestick) drawCandles(cvs *canvas.Canvas, lay *layout, sc *scale, candles []Candle, firstSlot int) error {
+	bc, err := braille.New(lay.plot)
+	if err != nil {
+		return err
+	}
+
+	for i, c := range candles {
+		color := cs.opts.upColor
+		if !c.up() {
+			color = cs.opts.downColor
+		}
+		x := (firstSlot + i) * cellsPerCandle * braille.ColMult
+
+		// The wick in the middle of the candle.
+		for y := sc.pixel(c.High); y <= sc.pixel(c.Low); y++ {
+			if err := bc.SetPixel(image.Point{x + 1, y}, cell.FgColor(color)); err != nil {
+				return err
+			}
+		}
+		// The body, at least one pixel high.
+		top, bottom := sc.pixel(math.Max(c.Open, c.Close)), sc.pixel(math.Min(c.Open, c.Close))
+		for y := top; y <= bottom; y++ {
+			for dx := 0; dx < 3; dx++ {
+				if err := bc.SetPixel(image.Point{x + dx, y}, cell.FgColor(color)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return bc.CopyTo(cvs)
+}
+
+// drawLabel draws the label of the price right-aligned on the row of the
+// labels area.
+func (cs *Candlestick) drawLabel(cvs *canvas.Canvas, lay *layout, row int, v float64, cOpts ...cell.Option) error {
+	text := cs.opts.valueFormatter(v)
+	x := lay.labels.Max.X - runewidth.StringWidth(text)
+	if x < lay.labels.Min.X {
+		x = lay.labels.Min.X
+	}
+	return draw.Text(cvs, text, image.Point{x, row},
+		draw.TextCellOpts(cOpts...),
+		draw.TextMaxX(lay.labels.Max.X),
+		draw.TextOverrunMode(draw.OverrunModeTrim),
+	)
+}
+
+// drawLabels draws the labels of the price axis on every yLabelGap row and
+// on the bottom row.
+func (cs *Candlestick) drawLabels(cvs *canvas.Canvas, lay *layout, sc *scale) error {
+	rows := lay.labels.Dy()
+	for r := 0; r < rows; r++ {
+		if r%yLabelGap != 0 && r != rows-1 {
+			continue
+		}
+		if r == rows-2 && r%yLabelGap == 0 {
+			// Would be immediately above the label of the bottom row.
+			continue
+		}
+		if err := cs.drawLabel(cvs, lay, lay.labels.Min.Y+r, sc.rowValue(r), cs.opts.axisCellOpts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drawCrosshair highlights the row and the candle slot under the mouse
+// pointer and displays the price of the row on the axis.
+func (cs *Candlestick) drawCrosshair(cvs *canvas.Canvas, lay *layout, sc *scale, ch image.Point, slot int) error {
+	bg := cell.BgColor(cs.opts.crosshairColor)
+	for x := lay.plot.Min.X; x < lay.plot.Max.X; x++ {
+		if err := cvs.SetCellOpts(image.Point{x, ch.Y}, bg); err != nil {
+			return err
+		}
+	}
+	slotX := lay.plot.Min.X + slot*cellsPerCandle
+	for y := lay.plot.Min.Y; y < lay.plot.Max.Y; y++ {
+		if y == ch.Y {
+			continue
+		}
+		for x := slotX; x < slotX+cellsPerCandle; x++ {
+			if err := cvs.SetCellOpts(image.Point{x, y}, bg); err != nil {
+				return err
+			}
+		}
+	}
+
+	labelRow := image.Rect(lay.labels.Min.X, ch.Y, lay.labels.Max.X, ch.Y+1)
+	if err := cvs.SetAreaCells(labelRow, ' ', bg); err != nil {
+		return err
+	}
+	cOpts := append([]cell.Option{}, cs.opts.axisCellOpts...)
+	cOpts = append(cOpts, bg)
+	return cs.drawLabel(cvs, lay, ch.Y, sc.rowValue(ch.Y-lay.plot.Min.Y), cOpts...)
+}
+
+// drawReadout draws the prices of the candle.
+func (cs *Candlestick) drawReadout(cvs *canvas.Canvas, lay *layout, c Candle) error {
+	color := cs.opts.upColor
+	if !c.up() {
+		color = cs.opts.downColor
+	}
+	vf := cs.opts.valueFormatter
+	text := fmt.Sprintf("O %s  H %s  L %s  C %s", vf(c.Open), vf(c.High), vf(c.Low), vf(c.Close))
+	return draw.Text(cvs, text, lay.readout.Min,
+		draw.TextCellOpts(cell.FgColor(color)),
+		draw.TextMaxX(lay.readout.Max.X),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+	)
+}
+
+// Keyboard scrolls through the history of candles.
+// Implements widgetapi.Widget.Keyboard.
+func (cs *Candlestick) Keyboard(k *terminalapi.Keyboard) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	switch k.Key {
+	case cs.opts.keyBack:
+		cs.scrollBy(1)
+	case cs.opts.keyForward:
+		cs.scrollBy(-1)
+	case keyboard.KeyHome:
+		cs.offset = cs.maxOffset()
+	case keyboard.KeyEnd:
+		cs.offset = 0
+	}
+	return nil
+}
+
+// Mouse positions the crosshair and scrolls through the history of candles
+// using the mouse wheel.
+// Implements widgetapi.Widget.Mouse.
+func (cs *Candlestick) Mouse(m *terminalapi.Mouse) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if m.Position.X < 0 || m.Position.Y < 0 {
+		// The pointer left the widget.
+		cs.crosshair = nil
+		return nil
+	}
+
+	switch m.Button {
+	case mouse.ButtonWheelUp:
+		cs.scrollBy(1)
+	case mouse.ButtonWheelDown:
+		cs.scrollBy(-1)
+	default:
+		p := m.Position
+		cs.crosshair = &p
+	}
+	return nil
+}
+
+// Options implements widgetapi.Widget.Options.
+func (cs *Candlestick) Options() widgetapi.Options {
+	return widgetapi.Options{
+		// The readout and one row of candles, a one cell label, the axis and
+		// one candle.
+		MinimumSize:  image.Point{2 + cellsPerCandle, 2},
+		WantKeyboard: widgetapi.KeyScopeFocused,
+		// Global, so that the widget learns when the pointer leaves it.
+		WantMouse: widgetapi.MouseScopeGlobal,
+		// The crosshair follows the pointer on terminals that report hover.
+		WantHover: true,
+	}
+}
@@ -0,0 +1,459 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package candlestick
+
+import (
+	"image"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/braille"
+	"github.com/mum4k/termdash/private/canvas/braille/testbraille"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// intFormatter formats the prices as integers to keep the labels short.
+// It is declared as a format.Formatter, which Formatter accepts as is.
+var intFormatter format.Formatter = func(v float64) string {
+	return strconv.Itoa(int(math.Round(v)))
+}
+
+// mustDrawCandle draws a candle whose leftmost pixel column is at x, the
+// wick spans the pixel rows high to low and the body the rows top to bottom.
+func mustDrawCandle(bc *braille.Canvas, x, high, top, bottom, low int, color cell.Color) {
+	for y := high; y <= low; y++ {
+		testbraille.MustSetPixel(bc, image.Point{x + 1, y}, cell.FgColor(color))
+	}
+	for y := top; y <= bottom; y++ {
+		for dx := 0; dx < 3; dx++ {
+			testbraille.MustSetPixel(bc, image.Point{x + dx, y}, cell.FgColor(color))
+		}
+	}
+}
+
+// mustDrawAxis draws the price axis in the column from row one to the bottom
+// of the canvas.
+func mustDrawAxis(cvs *canvas.Canvas, x int) {
+	testdraw.MustHVLines(cvs, []draw.HVLine{{
+		Start: image.Point{x, 1},
+		End:   image.Point{x, cvs.Area().Max.Y - 1},
+	}})
+}
+
+// mustDrawReadout draws the readout of the candle on the first row.
+func mustDrawReadout(cvs *canvas.Canvas, text string, color cell.Color) {
+	testdraw.MustText(cvs, text, image.Point{0, 0},
+		draw.TextCellOpts(cell.FgColor(color)),
+		draw.TextMaxX(cvs.Area().Max.X),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+	)
+}
+
+// Candles used in the tests, the prices map to pixel rows as 11 - price on
+// a plot three cells high.
+var (
+	up   = Candle{Open: 3, High: 11, Low: 0, Close: 8}
+	down = Candle{Open: 8, High: 9, Low: 4, Close: 5}
+	// up2 has the same range as up and closes lower.
+	up2 = Candle{Open: 4, High: 11, Low: 0, Close: 7}
+	// down2 has the same range as up.
+	down2 = Candle{Open: 8, High: 11, Low: 0, Close: 3}
+)
+
+func TestCandlestick(t *testing.T) {
+	tests := []struct {
+		desc          string
+		opts          []Option
+		candles       []Candle
+		added         []Candle
+		canvas        image.Rectangle
+		events        []terminalapi.Event
+		want          func(size image.Point) *faketerm.Terminal
+		wantNewErr    bool
+		wantSeriesErr bool
+	}{
+		{
+			desc:       "fails on nil Formatter",
+			opts:       []Option{Formatter(nil)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on ScrollKeys that aren't unique",
+			opts:       []Option{ScrollKeys('a', 'a')},
+			wantNewErr: true,
+		},
+		{
+			desc:          "fails on candle with High below Close",
+			candles:       []Candle{{Open: 1, High: 2, Low: 0, Close: 3}},
+			wantSeriesErr: true,
+		},
+		{
+			desc:          "fails on candle with Low above Open",
+			candles:       []Candle{{Open: 1, High: 2, Low: 1.5, Close: 2}},
+			wantSeriesErr: true,
+		},
+		{
+			desc:          "fails on NaN price",
+			candles:       []Candle{{Open: math.NaN(), High: 2, Low: 0, Close: 1}},
+			wantSeriesErr: true,
+		},
+		{
+			desc:   "requests resize when the canvas is too small",
+			canvas: image.Rect(0, 0, 3, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustResizeNeeded(cvs)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "draws only the axis without candles",
+			canvas: image.Rect(0, 0, 5, 3),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawAxis(cvs, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "draws the candles on the right with labels and readout",
+			opts:    []Option{Formatter(intFormatter)},
+			candles: []Candle{up, down},
+			canvas:  image.Rect(0, 0, 12, 4),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawAxis(cvs, 2)
+
+				// Four candles fit, the two are in the last two slots.
+				bc := testbraille.MustNew(image.Rect(3, 1, 12, 4))
+				mustDrawCandle(bc, 8, 0, 3, 8, 11, DefaultUpColor)
+				mustDrawCandle(bc, 12, 2, 3, 6, 7, DefaultDownColor)
+				testbraille.MustCopyTo(bc, cvs)
+
+				testdraw.MustText(cvs, "11", image.Point{0, 1})
+				testdraw.MustText(cvs, "0", image.Point{1, 3})
+				mustDrawReadout(cvs, "O 8  H 9  L 4  C 5", DefaultDownColor)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "draws the candles in custom colors",
+			opts: []Option{
+				Formatter(intFormatter),
+				UpColor(cell.ColorBlue),
+				DownColor(cell.ColorYellow),
+			},
+			candles: []Candle{up, down},
+			canvas:  image.Rect(0, 0, 12, 4),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawAxis(cvs, 2)
+
+				bc := testbraille.MustNew(image.Rect(3, 1, 12, 4))
+				mustDrawCandle(bc, 8, 0, 3, 8, 11, cell.ColorBlue)
+				mustDrawCandle(bc, 12, 2, 3, 6, 7, cell.ColorYellow)
+				testbraille.MustCopyTo(bc, cvs)
+
+				testdraw.MustText(cvs, "11", image.Point{0, 1})
+				testdraw.MustText(cvs, "0", image.Point{1, 3})
+				mustDrawReadout(cvs, "O 8  H 9  L 4  C 5", cell.ColorYellow)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "draws the crosshair and the readout of the candle under the mouse pointer",
+			opts:    []Option{Formatter(intFormatter)},
+			candles: []Candle{up, down},
+			canvas:  image.Rect(0, 0, 12, 4),
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{7, 2}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawAxis(cvs, 2)
+
+				bg := cell.BgColor(cell.ColorNumber(DefaultCrosshairColorNumber))
+				bc := testbraille.MustNew(image.Rect(3, 1, 12, 4))
+				mustDrawCandle(bc, 8, 0, 3, 8, 11, DefaultUpColor)
+				mustDrawCandle(bc, 12, 2, 3, 6, 7, DefaultDownColor)
+				testbraille.MustSetAreaCellOpts(bc, image.Rect(0, 1, 9, 2), bg)
+				testbraille.MustSetAreaCellOpts(bc, image.Rect(4, 0, 6, 3), bg)
+				testbraille.MustCopyTo(bc, cvs)
+
+				testdraw.MustText(cvs, "11", image.Point{0, 1})
+				testdraw.MustText(cvs, "0", image.Point{1, 3})
+				testcanvas.MustSetAreaCells(cvs, image.Rect(0, 2, 2, 3), ' ', bg)
+				testdraw.MustText(cvs, "7", image.Point{1, 2}, draw.TextCellOpts(bg))
+				mustDrawReadout(cvs, "O 3  H 11  L 0  C 8", DefaultUpColor)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "removes the crosshair when the mouse pointer leaves the widget",
+			opts:    []Option{Formatter(intFormatter)},
+			candles: []Candle{up, down},
+			canvas:  image.Rect(0, 0, 12, 4),
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{7, 2}, Button: mouse.ButtonRelease},
+				&terminalapi.Mouse{Position: image.Point{-1, -1}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawAxis(cvs, 2)
+
+				bc := testbraille.MustNew(image.Rect(3, 1, 12, 4))
+				mustDrawCandle(bc, 8, 0, 3, 8, 11, DefaultUpColor)
+				mustDrawCandle(bc, 12, 2, 3, 6, 7, DefaultDownColor)
+				testbraille.MustCopyTo(bc, cvs)
+
+				testdraw.MustText(cvs, "11", image.Point{0, 1})
+				testdraw.MustText(cvs, "0", image.Point{1, 3})
+				mustDrawReadout(cvs, "O 8  H 9  L 4  C 5", DefaultDownColor)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "displays the newest candles that fit",
+			opts:    []Option{Formatter(intFormatter)},
+			candles: []Candle{up, down2, up2},
+			canvas:  image.Rect(0, 0, 7, 4),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawAxis(cvs, 2)
+
+				bc := testbraille.MustNew(image.Rect(3, 1, 7, 4))
+				mustDrawCandle(bc, 0, 0, 3, 8, 11, DefaultDownColor)
+				mustDrawCandle(bc, 4, 0, 4, 7, 11, DefaultUpColor)
+				testbraille.MustCopyTo(bc, cvs)
+
+				testdraw.MustText(cvs, "11", image.Point{0, 1})
+				testdraw.MustText(cvs, "0", image.Point{1, 3})
+				mustDrawReadout(cvs, "O 4  H 11  L 0  C 7", DefaultUpColor)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "scrolls back with the keyboard, stops at the oldest candle",
+			opts:    []Option{Formatter(intFormatter)},
+			candles: []Candle{up, down2, up2},
+			canvas:  image.Rect(0, 0, 7, 4),
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawAxis(cvs, 2)
+
+				bc := testbraille.MustNew(image.Rect(3, 1, 7, 4))
+				mustDrawCandle(bc, 0, 0, 3, 8, 11, DefaultUpColor)
+				mustDrawCandle(bc, 4, 0, 3, 8, 11, DefaultDownColor)
+				testbraille.MustCopyTo(bc, cvs)
+
+				testdraw.MustText(cvs, "11", image.Point{0, 1})
+				testdraw.MustText(cvs, "0", image.Point{1, 3})
+				mustDrawReadout(cvs, "O 8  H 11  L 0  C 3", DefaultDownColor)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "scrolls back with the mouse wheel",
+			opts:    []Option{Formatter(intFormatter)},
+			candles: []Candle{up, down2, up2},
+			canvas:  image.Rect(0, 0, 7, 4),
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{4, 2}, Button: mouse.ButtonWheelUp},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawAxis(cvs, 2)
+
+				bc := testbraille.MustNew(image.Rect(3, 1, 7, 4))
+				mustDrawCandle(bc, 0, 0, 3, 8, 11, DefaultUpColor)
+				mustDrawCandle(bc, 4, 0, 3, 8, 11, DefaultDownColor)
+				testbraille.MustCopyTo(bc, cvs)
+
+				testdraw.MustText(cvs, "11", image.Point{0, 1})
+				testdraw.MustText(cvs, "0", image.Point{1, 3})
+				mustDrawReadout(cvs, "O 8  H 11  L 0  C 3", DefaultDownColor)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "stays on the same candles when new ones are added while scrolled back",
+			opts:    []Option{Formatter(intFormatter)},
+			candles: []Candle{up, down2, up2},
+			added:   []Candle{down},
+			canvas:  image.Rect(0, 0, 7, 4),
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyHome},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawAxis(cvs, 2)
+
+				bc := testbraille.MustNew(image.Rect(3, 1, 7, 4))
+				mustDrawCandle(bc, 0, 0, 3, 8, 11, DefaultUpColor)
+				mustDrawCandle(bc, 4, 0, 3, 8, 11, DefaultDownColor)
+				testbraille.MustCopyTo(bc, cvs)
+
+				testdraw.MustText(cvs, "11", image.Point{0, 1})
+				testdraw.MustText(cvs, "0", image.Point{1, 3})
+				mustDrawReadout(cvs, "O 8  H 11  L 0  C 3", DefaultDownColor)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "Home and End jump to the oldest and the newest candles",
+			opts:    []Option{Formatter(intFormatter)},
+			candles: []Candle{up, down2, up2},
+			canvas:  image.Rect(0, 0, 7, 4),
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyHome},
+				&terminalapi.Keyboard{Key: keyboard.KeyEnd},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawAxis(cvs, 2)
+
+				bc := testbraille.MustNew(image.Rect(3, 1, 7, 4))
+				mustDrawCandle(bc, 0, 0, 3, 8, 11, DefaultDownColor)
+				mustDrawCandle(bc, 4, 0, 4, 7, 11, DefaultUpColor)
+				testbraille.MustCopyTo(bc, cvs)
+
+				testdraw.MustText(cvs, "11", image.Point{0, 1})
+				testdraw.MustText(cvs, "0", image.Point{1, 3})
+				mustDrawReadout(cvs, "O 4  H 11  L 0  C 7", DefaultUpColor)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			cs, err := New(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			err = cs.Series(tc.candles)
+			if (err != nil) != tc.wantSeriesErr {
+				t.Errorf("Series => unexpected error: %v, wantSeriesErr: %v", err, tc.wantSeriesErr)
+			}
+			if err != nil {
+				return
+			}
+
+			c, err := canvas.New(tc.canvas)
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			// Draw once, so that the widget knows its capacity.
+			if err := cs.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			for _, ev := range tc.events {
+				switch e := ev.(type) {
+				case *terminalapi.Keyboard:
+					if err := cs.Keyboard(e); err != nil {
+						t.Fatalf("Keyboard => unexpected error: %v", err)
+					}
+				case *terminalapi.Mouse:
+					if err := cs.Mouse(e); err != nil {
+						t.Fatalf("Mouse => unexpected error: %v", err)
+					}
+				}
+			}
+			if len(tc.added) > 0 {
+				if err := cs.Add(tc.added...); err != nil {
+					t.Fatalf("Add => unexpected error: %v", err)
+				}
+			}
+
+			c, err = canvas.New(tc.canvas)
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := cs.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	cs, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	got := cs.Options()
+	want := widgetapi.Options{
+		MinimumSize:  image.Point{4, 2},
+		WantKeyboard: widgetapi.KeyScopeFocused,
+		WantMouse:    widgetapi.MouseScopeGlobal,
+		WantHover:    true,
+	}
+	if got != want {
+		t.Errorf("Options => %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary candlestickdemo displays a Candlestick widget with randomly
+// generated prices.
+// Exits when 'q' is pressed.
+package main
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/candlestick"
+)
+
+// randomCandle returns a candle that opens at the price with random
+// movements.
+func randomCandle(open float64) candlestick.Candle {
+	close := open + rand.NormFloat64()*2
+	return candlestick.Candle{
+		Open:  open,
+		High:  math.Max(open, close) + rand.Float64()*1.5,
+		Low:   math.Min(open, close) - rand.Float64()*1.5,
+		Close: close,
+	}
+}
+
+// playCandlestick adds a new candle once every delay.
+// Exits when the context expires.
+func playCandlestick(ctx context.Context, cs *candlestick.Candlestick, price float64, delay time.Duration) {
+	ticker := time.NewTicker(delay)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c := randomCandle(price)
+			if err := cs.Add(c); err != nil {
+				panic(err)
+			}
+			price = c.Close
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	cs, err := candlestick.New()
+	if err != nil {
+		panic(err)
+	}
+
+	// Generate some history to scroll through.
+	price := 100.0
+	var history []candlestick.Candle
+	for i := 0; i < 200; i++ {
+		c := randomCandle(price)
+		history = append(history, c)
+		price = c.Close
+	}
+	if err := cs.Series(history); err != nil {
+		panic(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go playCandlestick(ctx, cs, price, 1*time.Second)
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS Q TO QUIT, ARROWS, HOME, END OR MOUSE WHEEL TO SCROLL"),
+		container.PlaceWidget(cs),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == 'q' || k.Key == 'Q' {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter)); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package candlestick
+
+// options.go contains configurable options for Candlestick.
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
+	"github.com/mum4k/termdash/keyboard"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	upColor        cell.Color
+	downColor      cell.Color
+	crosshairColor cell.Color
+	axisCellOpts   []cell.Option
+	valueFormatter ValueFormatter
+	keyBack        keyboard.Key
+	keyForward     keyboard.Key
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		upColor:        DefaultUpColor,
+		downColor:      DefaultDownColor,
+		crosshairColor: cell.ColorNumber(DefaultCrosshairColorNumber),
+		valueFormatter: DefaultValueFormatter,
+		keyBack:        DefaultScrollKeyBack,
+		keyForward:     DefaultScrollKeyForward,
+	}
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.valueFormatter == nil {
+		return fmt.Errorf("invalid ValueFormatter, the function cannot be nil")
+	}
+	if o.keyBack == o.keyForward {
+		return fmt.Errorf("invalid ScrollKeys(back:%v, forward:%v), the keys must be unique", o.keyBack, o.keyForward)
+	}
+	return nil
+}
+
+// The default colors of the candles.
+const (
+	DefaultUpColor   = cell.ColorGreen
+	DefaultDownColor = cell.ColorRed
+)
+
+// UpColor sets the color of candles that closed at or above their open
+// price.
+// Defaults to DefaultUpColor.
+func UpColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.upColor = c
+	})
+}
+
+// DownColor sets the color of candles that closed below their open price.
+// Defaults to DefaultDownColor.
+func DownColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.downColor = c
+	})
+}
+
+// DefaultCrosshairColorNumber is the default color number for the
+// CrosshairColor option.
+const DefaultCrosshairColorNumber = 238
+
+// CrosshairColor sets the background color of the crosshair displayed under
+// the mouse pointer.
+// Defaults to DefaultCrosshairColorNumber.
+func CrosshairColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.crosshairColor = c
+	})
+}
+
+// AxisCellOpts sets the cell options of the price axis and its labels.
+func AxisCellOpts(cOpts ...cell.Option) Option {
+	return option(func(opts *options) {
+		opts.axisCellOpts = cOpts
+	})
+}
+
+// ValueFormatter formats prices for the labels of the axis and the readout
+// of the candle under the crosshair.
+// This is the same type as format.Formatter, so the formatters from the
+// format package can be used directly.
+type ValueFormatter = format.Formatter
+
+// DefaultValueFormatter formats the prices with two decimal places.
+func DefaultValueFormatter(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// Formatter sets the function that formats the prices.
+// Defaults to DefaultValueFormatter.
+func Formatter(vf ValueFormatter) Option {
+	return option(func(opts *options) {
+		opts.valueFormatter = vf
+	})
+}
+
+// The default keys that scroll through the history.
+const (
+	DefaultScrollKeyBack    = keyboard.KeyArrowLeft
+	DefaultScrollKeyForward = keyboard.KeyArrowRight
+)
+
+// ScrollKeys configures the keyboard keys that scroll back to older candles
+// and forward to newer candles. The provided keys must be unique.
+// The Home and End keys always jump to the oldest and the newest candles.
+func ScrollKeys(back, forward keyboard.Key) Option {
+	return option(func(opts *options) {
+		opts.keyBack = back
+		opts.keyForward = forward
+	})
+}
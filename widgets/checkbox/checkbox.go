@@ -0,0 +1,229 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package checkbox implements a widget that displays a group of checkboxes.
+package checkbox
+
+import (
+	"sync"
+
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/choice"
+	"github.com/mum4k/termdash/selection"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// The markers drawn in front of the labels.
+const (
+	checkedMarker   = "[x] "
+	uncheckedMarker = "[ ] "
+)
+
+// Checkbox displays a group of checkboxes with labels, one per line.
+//
+// While the widget is focused, the up and down arrow keys move the cursor and
+// the space key checks or unchecks the checkbox under the cursor. Clicking the
+// left mouse button on a checkbox or its label also checks or unchecks it.
+// The changes can be undone, see the History option.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Checkbox struct {
+	// mu protects the widget.
+	mu sync.Mutex
+
+	// group tracks the state of the checkboxes.
+	group *choice.Group
+
+	// opts are the provided options.
+	opts *options
+
+	// redraw is called when the content changes, see SetNotifyFunc.
+	redraw func()
+}
+
+// New returns a new Checkbox with one checkbox for each of the labels.
+func New(labels []string, opts ...Option) (*Checkbox, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+
+	group, err := choice.New(choice.Config{
+		Labels:           labels,
+		Mode:             selection.ModeMultiple,
+		Selected:         checkedMarker,
+		Unselected:       uncheckedMarker,
+		Align:            opt.labelAlign,
+		TextColor:        opt.textColor,
+		HighlightedColor: opt.highlightedColor,
+		DisabledColor:    opt.disabledColor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range opt.checked {
+		if err := group.Set(i, true); err != nil {
+			return nil, err
+		}
+	}
+	group.SetDisabled(opt.disabled)
+	return &Checkbox{
+		group: group,
+		opts:  opt,
+	}, nil
+}
+
+// Checked returns the indexes of the checked checkboxes in ascending order.
+func (cb *Checkbox) Checked() []int {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.group.Selected()
+}
+
+// IsChecked determines if the checkbox at the index is checked.
+func (cb *Checkbox) IsChecked(i int) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.group.IsSelected(i)
+}
+
+// SetChecked checks or unchecks the checkbox at the index.
+func (cb *Checkbox) SetChecked(i int, checked bool) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err := cb.group.Set(i, checked); err != nil {
+		return err
+	}
+	cb.markChanged()
+	return nil
+}
+
+// SetDisabled disables or enables the widget. See the Disabled option.
+func (cb *Checkbox) SetDisabled(disabled bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.group.SetDisabled(disabled)
+	cb.markChanged()
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (cb *Checkbox) SetNotifyFunc(fn func()) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.redraw = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// cb.mu must be held when calling this method.
+func (cb *Checkbox) markChanged() {
+	if cb.redraw != nil {
+		cb.redraw()
+	}
+}
+
+// Draw draws the Checkbox widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (cb *Checkbox) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.group.Draw(cvs, meta.Focused)
+}
+
+// keyboard processes keyboard events.
+// Returns the change of the checked checkboxes or nil if they didn't change.
+func (cb *Checkbox) keyboard(k *terminalapi.Keyboard) (*checkChange, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	from := cb.group.Selected()
+	changed, err := cb.group.Keyboard(k)
+	if err != nil || !changed {
+		return nil, err
+	}
+	cb.markChanged()
+	return &checkChange{checkbox: cb, from: from, to: cb.group.Selected()}, nil
+}
+
+// Keyboard processes keyboard events.
+// Implements widgetapi.Widget.Keyboard.
+func (cb *Checkbox) Keyboard(k *terminalapi.Keyboard) error {
+	if handled, err := cb.undoKeyboard(k); handled {
+		return err
+	}
+
+	c, err := cb.keyboard(k)
+	if err != nil || c == nil {
+		return err
+	}
+	cb.record(c)
+	return cb.notify(c.to)
+}
+
+// mouse processes mouse events.
+// Returns the change of the checked checkboxes or nil if they didn't change.
+func (cb *Checkbox) mouse(m *terminalapi.Mouse) (*checkChange, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	from := cb.group.Selected()
+	changed, err := cb.group.Mouse(m)
+	if err != nil || !changed {
+		return nil, err
+	}
+	cb.markChanged()
+	return &checkChange{checkbox: cb, from: from, to: cb.group.Selected()}, nil
+}
+
+// Mouse processes mouse events.
+// Implements widgetapi.Widget.Mouse.
+func (cb *Checkbox) Mouse(m *terminalapi.Mouse) error {
+	c, err := cb.mouse(m)
+	if err != nil || c == nil {
+		return err
+	}
+	cb.record(c)
+	return cb.notify(c.to)
+}
+
+// notify calls the OnChange callback if one was provided.
+func (cb *Checkbox) notify(checked []int) error {
+	if cb.opts.onChange == nil {
+		return nil
+	}
+	// Mutex must be released when calling the callback.
+	// Users might call container methods from the callback like the
+	// Container.Update, see #205.
+	return cb.opts.onChange(checked)
+}
+
+// Options implements widgetapi.Widget.Options.
+func (cb *Checkbox) Options() widgetapi.Options {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return widgetapi.Options{
+		MinimumSize:  cb.group.MinimumSize(),
+		WantKeyboard: widgetapi.KeyScopeFocused,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkbox
+
+// history.go contains code that records changes of the checked checkboxes so
+// they can be undone.
+
+import (
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// checkChange is a change of the checked checkboxes made by the user.
+// Implements undo.Change and undo.Reporter.
+type checkChange struct {
+	// checkbox is the changed checkbox.
+	checkbox *Checkbox
+	// from and to are the indexes of the checked checkboxes before and after
+	// the change.
+	from, to []int
+}
+
+// Undo implements undo.Change.Undo.
+func (cc *checkChange) Undo() {
+	cc.checkbox.restore(cc.from)
+}
+
+// Redo implements undo.Change.Redo.
+func (cc *checkChange) Redo() {
+	cc.checkbox.restore(cc.to)
+}
+
+// Report implements undo.Reporter.Report.
+func (cc *checkChange) Report() error {
+	return cc.checkbox.notify(cc.checkbox.Checked())
+}
+
+// restore checks the checkboxes when a change is undone or redone.
+func (cb *Checkbox) restore(checked []int) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.group.Clear()
+	for _, i := range checked {
+		// The indexes were valid when the change was recorded and the
+		// labels cannot change.
+		_ = cb.group.Set(i, true)
+	}
+	cb.markChanged()
+}
+
+// record records the change on the stack provided with the History option.
+// Caller must not hold cb.mu, the stack calls back into the checkbox when the
+// change is undone.
+func (cb *Checkbox) record(c *checkChange) {
+	if cb.opts.history != nil {
+		cb.opts.history.Record(c)
+	}
+}
+
+// undoKeyboard undoes or redoes the changes recorded on the stack provided
+// with the History option if the key is one of the UndoKeys.
+// Returns true if the key was handled.
+// Caller must not hold cb.mu.
+func (cb *Checkbox) undoKeyboard(k *terminalapi.Keyboard) (bool, error) {
+	h := cb.opts.history
+	if h == nil || (k.Key != cb.opts.undoKey && k.Key != cb.opts.redoKey) {
+		return false, nil
+	}
+
+	cb.mu.Lock()
+	disabled := cb.group.Disabled()
+	cb.mu.Unlock()
+	if disabled {
+		// A disabled widget ignores all keyboard events.
+		return true, nil
+	}
+
+	var err error
+	if k.Key == cb.opts.undoKey {
+		_, err = h.Undo()
+	} else {
+		_, err = h.Redo()
+	}
+	return true, err
+}
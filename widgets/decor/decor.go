@@ -0,0 +1,497 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package decor implements a widget that wraps another widget and decorates
+// it with a title, a footer, a badge and a border.
+//
+// The decorations are drawn by the wrapper itself, so they don't require
+// nesting the widget in additional containers:
+//
+//	d, err := decor.New(lc,
+//		decor.Title("latency"),
+//		decor.Badge("3"),
+//		decor.Border(linestyle.Light),
+//	)
+//	...
+//	c, err := container.New(t, container.PlaceWidget(d))
+package decor
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/gesture"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/private/alignfor"
+	"github.com/mum4k/termdash/private/area"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// Decor wraps a widget and draws decorations around it. All the calls are
+// delegated to the wrapped widget, the positions of mouse events, gestures
+// and tooltips are translated between the canvas of the Decor and the area
+// of the wrapped widget. The optional interfaces of the widgetapi package
+// are delegated if the wrapped widget implements them.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Decor struct {
+	// widget is the wrapped widget.
+	widget widgetapi.Widget
+
+	// title, footer and badge are the current decoration texts.
+	title  string
+	footer string
+	badge  string
+
+	// inner is the area of the wrapped widget on the canvas during the last
+	// call to Draw. Zero if the wrapped widget wasn't drawn.
+	inner image.Rectangle
+
+	// changed indicates that the decorations changed since the last draw.
+	changed bool
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the Decor.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new Decor that wraps the provided widget.
+func New(w widgetapi.Widget, opts ...Option) (*Decor, error) {
+	if w == nil {
+		return nil, errors.New("the wrapped widget cannot be nil")
+	}
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Decor{
+		widget:  w,
+		title:   opt.title,
+		footer:  opt.footer,
+		badge:   opt.badge,
+		changed: true,
+		opts:    opt,
+	}, nil
+}
+
+// Widget returns the wrapped widget.
+func (d *Decor) Widget() widgetapi.Widget {
+	return d.widget
+}
+
+// SetTitle changes the title, an empty text removes it.
+func (d *Decor) SetTitle(text string) {
+	d.set(&d.title, text)
+}
+
+// SetFooter changes the footer, an empty text removes it.
+func (d *Decor) SetFooter(text string) {
+	d.set(&d.footer, text)
+}
+
+// SetBadge changes the badge, an empty text removes it.
+func (d *Decor) SetBadge(text string) {
+	d.set(&d.badge, text)
+}
+
+// set changes one of the decoration texts.
+func (d *Decor) set(field *string, text string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if *field == text {
+		return
+	}
+	*field = text
+	d.changed = true
+	if d.notify != nil {
+		d.notify()
+	}
+}
+
+// hasBorder asserts whether the border is drawn.
+func (d *Decor) hasBorder() bool {
+	return d.opts.border != linestyle.None
+}
+
+// extra returns the number of cells the decorations take horizontally and
+// vertically.
+// The caller must hold d.mu.
+func (d *Decor) extra() image.Point {
+	if d.hasBorder() {
+		return image.Point{2, 2}
+	}
+	var e image.Point
+	if d.title != "" || d.badge != "" {
+		e.Y++
+	}
+	if d.footer != "" {
+		e.Y++
+	}
+	return e
+}
+
+// layout returns the rows of the canvas that hold the title and the footer
+// and the area left for the wrapped widget. The rows are zero if they aren't
+// displayed and the area is zero if nothing is left for the widget.
+// The caller must hold d.mu.
+func (d *Decor) layout(ar image.Rectangle) (top, bottom, inner image.Rectangle) {
+	if d.hasBorder() {
+		if ar.Dx() < 3 || ar.Dy() < 2 {
+			return image.ZR, image.ZR, image.ZR
+		}
+		top = image.Rect(ar.Min.X+1, ar.Min.Y, ar.Max.X-1, ar.Min.Y+1)
+		bottom = image.Rect(ar.Min.X+1, ar.Max.Y-1, ar.Max.X-1, ar.Max.Y)
+		return top, bottom, area.ExcludeBorder(ar)
+	}
+
+	inner = ar
+	if d.title != "" || d.badge != "" {
+		top = image.Rect(ar.Min.X, ar.Min.Y, ar.Max.X, ar.Min.Y+1)
+		inner.Min.Y++
+	}
+	if d.footer != "" && inner.Dy() > 0 {
+		bottom = image.Rect(ar.Min.X, ar.Max.Y-1, ar.Max.X, ar.Max.Y)
+		inner.Max.Y--
+	}
+	if inner.Dx() <= 0 || inner.Dy() <= 0 {
+		inner = image.ZR
+	}
+	return top, bottom, inner
+}
+
+// drawText draws the aligned text onto the row, trimming it if needed.
+func drawText(cvs *canvas.Canvas, row image.Rectangle, text string, h align.Horizontal, cOpts []cell.Option) error {
+	if text == "" || row.Dx() <= 0 {
+		return nil
+	}
+	start, err := alignfor.Text(row, text, h, align.VerticalTop)
+	if err != nil {
+		return err
+	}
+	return draw.Text(cvs, text, start,
+		draw.TextMaxX(row.Max.X),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		draw.TextCellOpts(cOpts...),
+	)
+}
+
+// Draw draws the decorations and the wrapped widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (d *Decor) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.changed = false
+	d.inner = image.ZR
+	ar := cvs.Area()
+	top, bottom, inner := d.layout(ar)
+	if d.hasBorder() && top != image.ZR {
+		if err := draw.Border(cvs, ar,
+			draw.BorderLineStyle(d.opts.border),
+			draw.BorderCellOpts(d.opts.borderCellOpts...),
+		); err != nil {
+			return err
+		}
+	}
+
+	if d.badge != "" && top.Dx() > 0 {
+		bw := runewidth.StringWidth(d.badge)
+		if err := drawText(cvs, top, d.badge, align.HorizontalRight, d.opts.badgeCellOpts); err != nil {
+			return err
+		}
+		// One cell gap between the title and the badge.
+		top.Max.X -= bw + 1
+	}
+	if err := drawText(cvs, top, d.title, d.opts.titleAlign, d.opts.titleCellOpts); err != nil {
+		return err
+	}
+	if err := drawText(cvs, bottom, d.footer, d.opts.footerAlign, d.opts.footerCellOpts); err != nil {
+		return err
+	}
+
+	if inner == image.ZR {
+		return nil
+	}
+	wOpts := d.widget.Options()
+	if maxX := wOpts.MaximumSize.X; maxX > 0 && inner.Dx() > maxX {
+		inner.Max.X -= inner.Dx() - maxX
+	}
+	if maxY := wOpts.MaximumSize.Y; maxY > 0 && inner.Dy() > maxY {
+		inner.Max.Y -= inner.Dy() - maxY
+	}
+	if wOpts.Ratio.X > 0 && wOpts.Ratio.Y > 0 {
+		inner = area.WithRatio(inner, wOpts.Ratio)
+	}
+	if inner.Dx() < wOpts.MinimumSize.X || inner.Dy() < wOpts.MinimumSize.Y || inner == image.ZR {
+		// Not enough space for the wrapped widget.
+		return nil
+	}
+
+	wCvs, err := canvas.New(inner)
+	if err != nil {
+		return err
+	}
+	if err := d.widget.Draw(wCvs, meta); err != nil {
+		return err
+	}
+	if err := wCvs.CopyTo(cvs); err != nil {
+		return err
+	}
+	d.inner = inner
+	return nil
+}
+
+// Keyboard delegates the keyboard event to the wrapped widget.
+// Implements widgetapi.Widget.Keyboard.
+func (d *Decor) Keyboard(k *terminalapi.Keyboard) error {
+	return d.widget.Keyboard(k)
+}
+
+// Mouse delegates the mouse event to the wrapped widget, translating its
+// position into the area of the wrapped widget. Events on the decorations
+// are dropped, unless the wrapped widget wants mouse events outside of its
+// canvas.
+// Implements widgetapi.Widget.Mouse.
+func (d *Decor) Mouse(m *terminalapi.Mouse) error {
+	d.mu.Lock()
+	inner := d.inner
+	d.mu.Unlock()
+
+	outside := image.Point{-1, -1}
+	adjusted := *m
+	switch {
+	case m.Position == outside:
+	case m.Position.In(inner):
+		adjusted.Position = m.Position.Sub(inner.Min)
+	case d.widget.Options().WantMouse == widgetapi.MouseScopeWidget:
+		return nil
+	default:
+		adjusted.Position = outside
+	}
+	return d.widget.Mouse(&adjusted)
+}
+
+// Options returns the options of the wrapped widget with the size of the
+// decorations added to its minimum and maximum size.
+// Implements widgetapi.Widget.Options.
+func (d *Decor) Options() widgetapi.Options {
+	d.mu.Lock()
+	extra := d.extra()
+	d.mu.Unlock()
+
+	wOpts := d.widget.Options()
+	opts := widgetapi.Options{
+		MinimumSize:  wOpts.MinimumSize.Add(extra),
+		WantKeyboard: wOpts.WantKeyboard,
+		WantMouse:    wOpts.WantMouse,
+		WantHover:    wOpts.WantHover,
+	}
+	// The ratio of the wrapped widget is applied within the decorations.
+	if max := wOpts.MaximumSize; max.X > 0 {
+		opts.MaximumSize.X = max.X + extra.X
+	}
+	if max := wOpts.MaximumSize; max.Y > 0 {
+		opts.MaximumSize.Y = max.Y + extra.Y
+	}
+	return opts
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (d *Decor) SetNotifyFunc(fn func()) {
+	d.mu.Lock()
+	d.notify = fn
+	d.mu.Unlock()
+	if n, ok := d.widget.(widgetapi.Notifier); ok {
+		n.SetNotifyFunc(fn)
+	}
+}
+
+// Changed implements widgetapi.ChangeTracker.Changed. Wrapped widgets that
+// don't track their changes are always reported as changed.
+func (d *Decor) Changed() bool {
+	d.mu.Lock()
+	changed := d.changed
+	d.mu.Unlock()
+	if changed {
+		return true
+	}
+	if ct, ok := d.widget.(widgetapi.ChangeTracker); ok {
+		return ct.Changed()
+	}
+	return true
+}
+
+// pasteReplacer normalizes the line endings in pasted text.
+var pasteReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// Paste implements widgetapi.Paster.Paste. Wrapped widgets that don't
+// implement widgetapi.Paster receive a keyboard event for each character,
+// the same as they would without the Decor. The delivery stops on the first
+// error returned by the wrapped widget.
+func (d *Decor) Paste(text string) {
+	if p, ok := d.widget.(widgetapi.Paster); ok {
+		p.Paste(text)
+		return
+	}
+	for _, r := range pasteReplacer.Replace(text) {
+		k := keyboard.Key(r)
+		if r == '\n' {
+			k = keyboard.KeyEnter
+		}
+		if err := d.widget.Keyboard(&terminalapi.Keyboard{Key: k}); err != nil {
+			return
+		}
+	}
+}
+
+// Compose implements widgetapi.Composer.Compose. The composition is ignored
+// if the wrapped widget doesn't implement widgetapi.Composer.
+func (d *Decor) Compose(preedit string, cursor int) {
+	if cp, ok := d.widget.(widgetapi.Composer); ok {
+		cp.Compose(preedit, cursor)
+	}
+}
+
+// Commit implements widgetapi.Composer.Commit. Wrapped widgets that don't
+// implement widgetapi.Composer receive a keyboard event for each character,
+// the same as they would without the Decor. The delivery stops on the first
+// error returned by the wrapped widget.
+func (d *Decor) Commit(text string) {
+	if cp, ok := d.widget.(widgetapi.Composer); ok {
+		cp.Commit(text)
+		return
+	}
+	for _, r := range text {
+		if err := d.widget.Keyboard(&terminalapi.Keyboard{Key: keyboard.Key(r)}); err != nil {
+			return
+		}
+	}
+}
+
+// Tooltips implements widgetapi.Tooltipper.Tooltips.
+func (d *Decor) Tooltips() []widgetapi.Tooltip {
+	tt, ok := d.widget.(widgetapi.Tooltipper)
+	if !ok {
+		return nil
+	}
+	d.mu.Lock()
+	inner := d.inner
+	d.mu.Unlock()
+	if inner == image.ZR {
+		return nil
+	}
+
+	var res []widgetapi.Tooltip
+	for _, t := range tt.Tooltips() {
+		res = append(res, widgetapi.Tooltip{
+			Area: t.Area.Add(inner.Min).Intersect(inner),
+			Text: t.Text,
+		})
+	}
+	return res
+}
+
+// SensitiveAreas implements widgetapi.Sensitive.SensitiveAreas.
+func (d *Decor) SensitiveAreas() []image.Rectangle {
+	s, ok := d.widget.(widgetapi.Sensitive)
+	if !ok {
+		return nil
+	}
+	d.mu.Lock()
+	inner := d.inner
+	d.mu.Unlock()
+	if inner == image.ZR {
+		return nil
+	}
+
+	var res []image.Rectangle
+	for _, ar := range s.SensitiveAreas() {
+		if ar = ar.Add(inner.Min).Intersect(inner); !ar.Empty() {
+			res = append(res, ar)
+		}
+	}
+	return res
+}
+
+// Gesture implements widgetapi.GestureHandler.Gesture. Gestures that start
+// on the decorations are dropped.
+func (d *Decor) Gesture(g *gesture.Gesture) error {
+	gh, ok := d.widget.(widgetapi.GestureHandler)
+	if !ok {
+		return nil
+	}
+	d.mu.Lock()
+	inner := d.inner
+	d.mu.Unlock()
+	if !g.Position.In(inner) {
+		return nil
+	}
+
+	adjusted := *g
+	adjusted.Position = g.Position.Sub(inner.Min)
+	return gh.Gesture(&adjusted)
+}
+
+// Mount implements widgetapi.Mounter.Mount.
+func (d *Decor) Mount() {
+	if m, ok := d.widget.(widgetapi.Mounter); ok {
+		m.Mount()
+	}
+}
+
+// Unmount implements widgetapi.Mounter.Unmount.
+func (d *Decor) Unmount() {
+	if m, ok := d.widget.(widgetapi.Mounter); ok {
+		m.Unmount()
+	}
+}
+
+// FocusChanged implements widgetapi.FocusObserver.FocusChanged.
+func (d *Decor) FocusChanged(focused bool) {
+	if fo, ok := d.widget.(widgetapi.FocusObserver); ok {
+		fo.FocusChanged(focused)
+	}
+}
+
+// SetTheme implements widgetapi.Themer.SetTheme.
+func (d *Decor) SetTheme(t widgetapi.Theme) {
+	if th, ok := d.widget.(widgetapi.Themer); ok {
+		th.SetTheme(t)
+	}
+}
+
+// Tick implements widgetapi.Ticker.Tick.
+func (d *Decor) Tick(now time.Time) {
+	if t, ok := d.widget.(widgetapi.Ticker); ok {
+		t.Tick(now)
+	}
+}
@@ -0,0 +1,494 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decor
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/private/fakewidget"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// badgeOpts are the default cell options of the badge.
+var badgeOpts = draw.TextCellOpts(
+	cell.FgColor(cell.ColorBlack),
+	cell.BgColor(cell.ColorYellow),
+)
+
+func TestDecor(t *testing.T) {
+	tests := []struct {
+		desc    string
+		wOpts   widgetapi.Options
+		nilW    bool
+		opts    []Option
+		events  []terminalapi.Event
+		size    image.Point
+		want    func(size image.Point) *faketerm.Terminal
+		wantErr bool
+	}{
+		{
+			desc:    "fails on nil widget",
+			nilW:    true,
+			wantErr: true,
+		},
+		{
+			desc:    "fails on invalid title alignment",
+			opts:    []Option{TitleAlign(align.Horizontal(-1))},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on invalid border line style",
+			opts:    []Option{Border(linestyle.LineStyle(-1))},
+			wantErr: true,
+		},
+		{
+			desc: "draws only the widget without decorations",
+			size: image.Point{30, 6},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(ft.Area()), &widgetapi.Meta{}, widgetapi.Options{})
+				return ft
+			},
+		},
+		{
+			desc: "draws the title and the badge above the widget",
+			opts: []Option{
+				Title("stats", cell.FgColor(cell.ColorBlue)),
+				Badge("12"),
+			},
+			size: image.Point{30, 6},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "stats", image.Point{0, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorBlue)))
+				testdraw.MustText(cvs, "12", image.Point{28, 0}, badgeOpts)
+				testcanvas.MustApply(cvs, ft)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(0, 1, 30, 6)), &widgetapi.Meta{}, widgetapi.Options{})
+				return ft
+			},
+		},
+		{
+			desc: "trims a long title before the badge",
+			opts: []Option{
+				Title("a very long title"),
+				Badge("1", cell.FgColor(cell.ColorRed)),
+			},
+			size: image.Point{10, 4},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "a very …", image.Point{0, 0})
+				testdraw.MustText(cvs, "1", image.Point{9, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorRed)))
+				testcanvas.MustApply(cvs, ft)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(0, 1, 10, 4)), &widgetapi.Meta{}, widgetapi.Options{})
+				return ft
+			},
+		},
+		{
+			desc: "draws the aligned footer below the widget",
+			opts: []Option{
+				Footer("ok"),
+				FooterAlign(align.HorizontalCenter),
+			},
+			size: image.Point{30, 6},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "ok", image.Point{14, 5})
+				testcanvas.MustApply(cvs, ft)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(0, 0, 30, 5)), &widgetapi.Meta{}, widgetapi.Options{})
+				return ft
+			},
+		},
+		{
+			desc: "draws the decorations onto the border",
+			opts: []Option{
+				Title("stats"),
+				TitleAlign(align.HorizontalCenter),
+				Badge("3"),
+				Footer("ok"),
+				Border(linestyle.Round, cell.FgColor(cell.ColorGreen)),
+			},
+			size: image.Point{30, 8},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustBorder(cvs, cvs.Area(),
+					draw.BorderLineStyle(linestyle.Round),
+					draw.BorderCellOpts(cell.FgColor(cell.ColorGreen)),
+				)
+				testdraw.MustText(cvs, "stats", image.Point{11, 0})
+				testdraw.MustText(cvs, "3", image.Point{28, 0}, badgeOpts)
+				testdraw.MustText(cvs, "ok", image.Point{1, 7})
+				testcanvas.MustApply(cvs, ft)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(1, 1, 29, 7)), &widgetapi.Meta{}, widgetapi.Options{})
+				return ft
+			},
+		},
+		{
+			desc: "skips the widget if the decorations leave too little space",
+			wOpts: widgetapi.Options{
+				MinimumSize: image.Point{10, 4},
+			},
+			opts: []Option{Title("stats")},
+			size: image.Point{10, 4},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "stats", image.Point{0, 0})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "applies the ratio of the widget within the decorations",
+			wOpts: widgetapi.Options{
+				Ratio: image.Point{2, 1},
+			},
+			opts: []Option{Title("stats")},
+			size: image.Point{30, 6},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "stats", image.Point{0, 0})
+				testcanvas.MustApply(cvs, ft)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(0, 1, 10, 6)), &widgetapi.Meta{}, widgetapi.Options{})
+				return ft
+			},
+		},
+		{
+			desc: "translates mouse events into the area of the widget",
+			wOpts: widgetapi.Options{
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+			},
+			opts: []Option{Title("stats")},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Mouse{Position: image.Point{5, 3}, Button: mouse.ButtonLeft},
+				// Dropped, falls onto the title.
+				&terminalapi.Mouse{Position: image.Point{5, 0}, Button: mouse.ButtonMiddle},
+			},
+			size: image.Point{30, 6},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "stats", image.Point{0, 0})
+				testcanvas.MustApply(cvs, ft)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(0, 1, 30, 6)), &widgetapi.Meta{},
+					widgetapi.Options{
+						WantKeyboard: widgetapi.KeyScopeFocused,
+						WantMouse:    widgetapi.MouseScopeWidget,
+					},
+					&terminalapi.Keyboard{Key: 'a'},
+					&terminalapi.Mouse{Position: image.Point{5, 2}, Button: mouse.ButtonLeft},
+				)
+				return ft
+			},
+		},
+		{
+			desc: "delivers mouse events on the decorations outside of the widget",
+			wOpts: widgetapi.Options{
+				WantMouse: widgetapi.MouseScopeGlobal,
+			},
+			opts: []Option{Title("stats")},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{5, 0}, Button: mouse.ButtonLeft},
+			},
+			size: image.Point{30, 6},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "stats", image.Point{0, 0})
+				testcanvas.MustApply(cvs, ft)
+				fakewidget.MustDraw(ft, testcanvas.MustNew(image.Rect(0, 1, 30, 6)), &widgetapi.Meta{},
+					widgetapi.Options{
+						WantMouse: widgetapi.MouseScopeGlobal,
+					},
+					&terminalapi.Mouse{Position: image.Point{-1, -1}, Button: mouse.ButtonLeft},
+				)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			var w widgetapi.Widget
+			if !tc.nilW {
+				w = fakewidget.New(tc.wOpts)
+			}
+			d, err := New(w, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			c, err := canvas.New(image.Rectangle{Max: tc.size})
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if len(tc.events) > 0 {
+				// The first draw establishes the area of the widget.
+				if err := d.Draw(c, &widgetapi.Meta{}); err != nil {
+					t.Fatalf("Draw => unexpected error: %v", err)
+				}
+			}
+			for _, ev := range tc.events {
+				switch e := ev.(type) {
+				case *terminalapi.Keyboard:
+					err = d.Keyboard(e)
+				case *terminalapi.Mouse:
+					err = d.Mouse(e)
+				}
+				if err != nil {
+					t.Fatalf("event %v => unexpected error: %v", ev, err)
+				}
+			}
+			if err := d.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	wOpts := widgetapi.Options{
+		Ratio:        image.Point{2, 1},
+		MinimumSize:  image.Point{4, 2},
+		MaximumSize:  image.Point{0, 10},
+		WantKeyboard: widgetapi.KeyScopeFocused,
+		WantMouse:    widgetapi.MouseScopeWidget,
+		WantHover:    true,
+	}
+	tests := []struct {
+		desc string
+		opts []Option
+		want widgetapi.Options
+	}{
+		{
+			desc: "no decorations",
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{4, 2},
+				MaximumSize:  image.Point{0, 10},
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+				WantHover:    true,
+			},
+		},
+		{
+			desc: "title and footer",
+			opts: []Option{Title("t"), Footer("f")},
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{4, 4},
+				MaximumSize:  image.Point{0, 12},
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+				WantHover:    true,
+			},
+		},
+		{
+			desc: "border",
+			opts: []Option{Title("t"), Border(linestyle.Light)},
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{6, 4},
+				MaximumSize:  image.Point{0, 12},
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+				WantHover:    true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			d, err := New(fakewidget.New(wOpts), tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if diff := pretty.Compare(tc.want, d.Options()); diff != "" {
+				t.Errorf("Options => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetters(t *testing.T) {
+	d, err := New(fakewidget.New(widgetapi.Options{}))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	d.SetNotifyFunc(func() {
+		notified++
+	})
+
+	c, err := canvas.New(image.Rect(0, 0, 30, 6))
+	if err != nil {
+		t.Fatalf("canvas.New => unexpected error: %v", err)
+	}
+	if err := d.Draw(c, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	d.SetTitle("stats")
+	d.SetBadge("1")
+	d.SetBadge("1")
+	d.SetFooter("ok")
+	if want := 3; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+	if got, want := d.Options().MinimumSize, (image.Point{0, 2}); got != want {
+		t.Errorf("Options => MinimumSize %v, want %v", got, want)
+	}
+}
+
+func TestPaste(t *testing.T) {
+	w := fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})
+	d, err := New(w)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	d.Paste("ab\r\n")
+
+	c, err := canvas.New(image.Rect(0, 0, 30, 6))
+	if err != nil {
+		t.Fatalf("canvas.New => unexpected error: %v", err)
+	}
+	if err := d.Draw(c, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	got, err := faketerm.New(c.Size())
+	if err != nil {
+		t.Fatalf("faketerm.New => unexpected error: %v", err)
+	}
+	if err := c.Apply(got); err != nil {
+		t.Fatalf("Apply => unexpected error: %v", err)
+	}
+
+	want := faketerm.MustNew(c.Size())
+	fakewidget.MustDraw(want, testcanvas.MustNew(want.Area()), &widgetapi.Meta{},
+		widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused},
+		&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+	)
+	if diff := faketerm.Diff(want, got); diff != "" {
+		t.Errorf("Paste => %v", diff)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	w := fakewidget.New(widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused})
+	d, err := New(w)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	d.Compose("ni", 2)
+	d.Commit("你")
+
+	c, err := canvas.New(image.Rect(0, 0, 30, 6))
+	if err != nil {
+		t.Fatalf("canvas.New => unexpected error: %v", err)
+	}
+	if err := d.Draw(c, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	got, err := faketerm.New(c.Size())
+	if err != nil {
+		t.Fatalf("faketerm.New => unexpected error: %v", err)
+	}
+	if err := c.Apply(got); err != nil {
+		t.Fatalf("Apply => unexpected error: %v", err)
+	}
+
+	want := faketerm.MustNew(c.Size())
+	fakewidget.MustDraw(want, testcanvas.MustNew(want.Area()), &widgetapi.Meta{},
+		widgetapi.Options{WantKeyboard: widgetapi.KeyScopeFocused},
+		&terminalapi.Keyboard{Key: '你'},
+	)
+	if diff := faketerm.Diff(want, got); diff != "" {
+		t.Errorf("Commit => %v", diff)
+	}
+}
+
+// sensitiveWidget is a fake widget that reports sensitive areas.
+type sensitiveWidget struct {
+	*fakewidget.Mirror
+
+	areas []image.Rectangle
+}
+
+// SensitiveAreas implements widgetapi.Sensitive.SensitiveAreas.
+func (sw *sensitiveWidget) SensitiveAreas() []image.Rectangle {
+	return sw.areas
+}
+
+func TestSensitiveAreas(t *testing.T) {
+	w := &sensitiveWidget{
+		Mirror: fakewidget.New(widgetapi.Options{}),
+		areas: []image.Rectangle{
+			image.Rect(0, 0, 2, 1),
+			image.Rect(5, 3, 10, 10),
+			image.Rect(30, 30, 31, 31),
+		},
+	}
+	d, err := New(w, Title("title"))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if got := d.SensitiveAreas(); got != nil {
+		t.Errorf("SensitiveAreas before Draw => %v, want nil", got)
+	}
+
+	c, err := canvas.New(image.Rect(0, 0, 30, 6))
+	if err != nil {
+		t.Fatalf("canvas.New => unexpected error: %v", err)
+	}
+	if err := d.Draw(c, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	want := []image.Rectangle{
+		image.Rect(0, 1, 2, 2),
+		image.Rect(5, 4, 10, 6),
+	}
+	if diff := pretty.Compare(want, d.SensitiveAreas()); diff != "" {
+		t.Errorf("SensitiveAreas => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary decordemo displays widgets decorated with titles, badges, footers
+// and borders without nesting them in additional containers.
+// Exits when 'q' is pressed.
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/decor"
+	"github.com/mum4k/termdash/widgets/sparkline"
+	"github.com/mum4k/termdash/widgets/text"
+)
+
+// feed periodically adds values to the sparkline and messages to the log,
+// the badge of the log counts the messages. Exits when the context expires.
+func feed(ctx context.Context, sl *sparkline.SparkLine, log *text.Text, logDecor *decor.Decor) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	messages := 0
+	for {
+		select {
+		case <-ticker.C:
+			v := rand.Intn(100)
+			if err := sl.Add([]int{v}); err != nil {
+				panic(err)
+			}
+			if v > 80 {
+				messages++
+				if err := log.Write(fmt.Sprintf("value %d is above 80\n", v)); err != nil {
+					panic(err)
+				}
+				logDecor.SetBadge(fmt.Sprintf(" %d ", messages))
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	sl, err := sparkline.New(sparkline.Color(cell.ColorGreen))
+	if err != nil {
+		panic(err)
+	}
+	slDecor, err := decor.New(sl,
+		decor.Title("load", cell.FgColor(cell.ColorGreen)),
+		decor.Footer("sampled every 500ms"),
+		decor.FooterAlign(align.HorizontalRight),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	log, err := text.New(text.RollContent())
+	if err != nil {
+		panic(err)
+	}
+	logDecor, err := decor.New(log,
+		decor.Title("alerts"),
+		decor.TitleAlign(align.HorizontalCenter),
+		decor.Border(linestyle.Round, cell.FgColor(cell.ColorYellow)),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go feed(ctx, sl, log, logDecor)
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS Q TO QUIT"),
+		container.SplitHorizontal(
+			container.Top(
+				container.PlaceWidget(slDecor),
+			),
+			container.Bottom(
+				container.PlaceWidget(logDecor),
+			),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == 'q' || k.Key == 'Q' {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter), termdash.RedrawInterval(250*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decor
+
+// options.go contains configurable options for Decor.
+
+import (
+	"fmt"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/linestyle"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	title          string
+	titleCellOpts  []cell.Option
+	titleAlign     align.Horizontal
+	footer         string
+	footerCellOpts []cell.Option
+	footerAlign    align.Horizontal
+	badge          string
+	badgeCellOpts  []cell.Option
+	border         linestyle.LineStyle
+	borderCellOpts []cell.Option
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		titleAlign:  align.HorizontalLeft,
+		footerAlign: align.HorizontalLeft,
+		badgeCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorBlack),
+			cell.BgColor(cell.ColorYellow),
+		},
+		border: linestyle.None,
+	}
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	for _, a := range []align.Horizontal{o.titleAlign, o.footerAlign} {
+		if a < align.HorizontalLeft || a > align.HorizontalRight {
+			return fmt.Errorf("invalid alignment %v", a)
+		}
+	}
+	if o.border < linestyle.None || o.border > linestyle.Round {
+		return fmt.Errorf("invalid Border line style %v", o.border)
+	}
+	return nil
+}
+
+// Title sets the text displayed above the wrapped widget, or on the top line
+// of the border if the Border option is set. Can be changed later with
+// Decor.SetTitle.
+func Title(text string, cOpts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.title = text
+		o.titleCellOpts = cOpts
+	})
+}
+
+// TitleAlign sets the horizontal alignment of the title.
+// Defaults to align.HorizontalLeft.
+func TitleAlign(h align.Horizontal) Option {
+	return option(func(o *options) {
+		o.titleAlign = h
+	})
+}
+
+// Footer sets the text displayed below the wrapped widget, or on the bottom
+// line of the border if the Border option is set. Can be changed later with
+// Decor.SetFooter.
+func Footer(text string, cOpts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.footer = text
+		o.footerCellOpts = cOpts
+	})
+}
+
+// FooterAlign sets the horizontal alignment of the footer.
+// Defaults to align.HorizontalLeft.
+func FooterAlign(h align.Horizontal) Option {
+	return option(func(o *options) {
+		o.footerAlign = h
+	})
+}
+
+// Badge sets a short text, e.g. a counter of unread items, displayed in the
+// top right corner on the same line as the title. Can be changed later with
+// Decor.SetBadge.
+// The cell options default to black text on yellow background.
+func Badge(text string, cOpts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.badge = text
+		if len(cOpts) > 0 {
+			o.badgeCellOpts = cOpts
+		}
+	})
+}
+
+// Border draws a border around the wrapped widget, the title, the badge and
+// the footer are drawn onto the border instead of taking rows of their own.
+// Defaults to no border.
+func Border(ls linestyle.LineStyle, cOpts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.border = ls
+		o.borderCellOpts = cOpts
+	})
+}
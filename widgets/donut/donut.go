@@ -70,6 +70,11 @@ type Donut struct {
 	// For progressTypePercent, this is 100, for progressTypeAbsolute this is
 	// the total provided by the caller.
 	total int
+	// drawn indicates that the current content was drawn, see Changed.
+	drawn bool
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
 	// mu protects the Donut.
 	mu sync.Mutex
 
@@ -98,6 +103,7 @@ func New(opts ...Option) (*Donut, error) {
 func (d *Donut) Absolute(done, total int, opts ...Option) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
+	d.markChanged()
 
 	if done < 0 || total < 1 || done > total {
 		return fmt.Errorf("invalid progress, done(%d) must be <= total(%d), done must be zero or positive "+
@@ -123,6 +129,7 @@ func (d *Donut) Absolute(done, total int, opts ...Option) error {
 func (d *Donut) Percent(p int, opts ...Option) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
+	d.markChanged()
 
 	if p < 0 || p > 100 {
 		return fmt.Errorf("invalid percentage, p(%d) must be 0 <= p <= 100", p)
@@ -206,6 +213,17 @@ func (d *Donut) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	if err := d.draw(cvs); err != nil {
+		return err
+	}
+	// Only set after a successful draw, so that a failed one is retried.
+	d.drawn = true
+	return nil
+}
+
+// draw draws the Donut onto the canvas.
+// Caller must hold d.mu.
+func (d *Donut) draw(cvs *canvas.Canvas) error {
 	startA, endA := startEndAngles(d.current, d.total, d.opts.startAngle, d.opts.direction)
 	if startA == endA {
 		// No progress recorded, so nothing to do.
@@ -272,6 +290,29 @@ func (d *Donut) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	return nil
 }
 
+// Changed implements widgetapi.ChangeTracker.Changed.
+func (d *Donut) Changed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return !d.drawn
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (d *Donut) SetNotifyFunc(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.notify = fn
+}
+
+// markChanged records that the content changed and notifies the
+// infrastructure. The caller must hold d.mu.
+func (d *Donut) markChanged() {
+	d.drawn = false
+	if d.notify != nil {
+		d.notify()
+	}
+}
+
 // Keyboard input isn't supported on the Donut widget.
 func (*Donut) Keyboard(k *terminalapi.Keyboard) error {
 	return errors.New("the Donut widget doesn't support keyboard events")
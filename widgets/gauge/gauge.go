@@ -21,6 +21,7 @@ import (
 	"image"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/mum4k/termdash/cell"
 	"github.com/mum4k/termdash/linestyle"
@@ -29,6 +30,7 @@ import (
 	"github.com/mum4k/termdash/private/canvas"
 	"github.com/mum4k/termdash/private/draw"
 	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/rules"
 	"github.com/mum4k/termdash/terminal/terminalapi"
 	"github.com/mum4k/termdash/widgetapi"
 )
@@ -70,6 +72,15 @@ type Gauge struct {
 	// For progressTypePercent, this is 100, for progressTypeAbsolute this is
 	// the total provided by the caller.
 	total int
+	// drawn indicates that the current content was drawn, see Changed.
+	drawn bool
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+	// style is the style of the rule that matched the current progress.
+	style rules.Style
+	// now returns the current time, used to blink the style.
+	now func() time.Time
+
 	// mu protects the Gauge.
 	mu sync.Mutex
 
@@ -89,6 +100,7 @@ func New(opts ...Option) (*Gauge, error) {
 
 	return &Gauge{
 		opts: opt,
+		now:  time.Now,
 	}, nil
 }
 
@@ -99,6 +111,7 @@ func New(opts ...Option) (*Gauge, error) {
 func (g *Gauge) Absolute(done, total int, opts ...Option) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	g.markChanged()
 
 	if done < 0 || total < 1 || done > total {
 		return fmt.Errorf("invalid progress, done(%d) must be <= total(%d), done must be zero or positive "+
@@ -112,6 +125,7 @@ func (g *Gauge) Absolute(done, total int, opts ...Option) error {
 	g.pt = progressTypeAbsolute
 	g.current = done
 	g.total = total
+	g.evalRules()
 	return nil
 }
 
@@ -121,6 +135,7 @@ func (g *Gauge) Absolute(done, total int, opts ...Option) error {
 func (g *Gauge) Percent(p int, opts ...Option) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	g.markChanged()
 
 	if p < 0 || p > 100 {
 		return fmt.Errorf("invalid percentage, p(%d) must be 0 <= p <= 100", p)
@@ -133,9 +148,17 @@ func (g *Gauge) Percent(p int, opts ...Option) error {
 	g.pt = progressTypePercent
 	g.current = p
 	g.total = 100
+	g.evalRules()
 	return nil
 }
 
+// evalRules evaluates the styling rules against the current progress in
+// percent. The caller must hold g.mu.
+func (g *Gauge) evalRules() {
+	st, _ := g.opts.rules.Eval(float64(g.current) / float64(g.total) * 100)
+	g.style = st
+}
+
 // width determines the required width of the gauge drawn on the provided area
 // in order to represent the current progress.
 func (g *Gauge) width(ar image.Rectangle) int {
@@ -163,6 +186,12 @@ func (g *Gauge) progressText() string {
 		return ""
 	}
 
+	if f := g.opts.valueFormatter; f != nil {
+		if g.pt == progressTypePercent {
+			return f(float64(g.current))
+		}
+		return fmt.Sprintf("%s/%s", f(float64(g.current)), f(float64(g.total)))
+	}
 	if g.pt == progressTypePercent {
 		return fmt.Sprintf("%d%%", g.current)
 	}
@@ -184,7 +213,7 @@ func (g *Gauge) gaugeText() string {
 }
 
 // drawText draws the text enumerating the progress and the text label.
-func (g *Gauge) drawText(cvs *canvas.Canvas, progress image.Rectangle) error {
+func (g *Gauge) drawText(cvs *canvas.Canvas, progress image.Rectangle, now time.Time) error {
 	text := g.gaugeText()
 	if text == "" {
 		return nil
@@ -220,7 +249,7 @@ func (g *Gauge) drawText(cvs *canvas.Canvas, progress image.Rectangle) error {
 			)
 			if err := draw.Rectangle(cvs, fixup,
 				draw.RectChar(g.opts.gaugeChar),
-				draw.RectCellOpts(cell.BgColor(g.opts.color)),
+				draw.RectCellOpts(cell.BgColor(g.style.ColorOr(g.opts.color, now))),
 			); err != nil {
 				return err
 			}
@@ -229,9 +258,9 @@ func (g *Gauge) drawText(cvs *canvas.Canvas, progress image.Rectangle) error {
 
 		var cellOpts []cell.Option
 		if cur.In(progress) {
-			cellOpts = append(cellOpts, cell.FgColor(g.opts.filledTextColor))
+			cellOpts = append(cellOpts, cell.FgColor(g.style.TextColorOr(g.opts.filledTextColor, now)))
 		} else {
-			cellOpts = append(cellOpts, cell.FgColor(g.opts.emptyTextColor))
+			cellOpts = append(cellOpts, cell.FgColor(g.style.TextColorOr(g.opts.emptyTextColor, now)))
 		}
 
 		cells, err := cvs.SetCell(cur, r, cellOpts...)
@@ -250,6 +279,17 @@ func (g *Gauge) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if err := g.draw(cvs); err != nil {
+		return err
+	}
+	// Only set after a successful draw, so that a failed one is retried.
+	g.drawn = true
+	return nil
+}
+
+// draw draws the Gauge onto the canvas.
+// Caller must hold g.mu.
+func (g *Gauge) draw(cvs *canvas.Canvas) error {
 	needAr, err := area.FromSize(g.minSize())
 	if err != nil {
 		return err
@@ -258,12 +298,17 @@ func (g *Gauge) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 		return draw.ResizeNeeded(cvs)
 	}
 
+	now := g.now()
 	if g.hasBorder() {
+		borderCellOpts := g.opts.borderCellOpts
+		if c := g.style.BorderColorOr(cell.ColorDefault, now); c != cell.ColorDefault {
+			borderCellOpts = append(append([]cell.Option(nil), borderCellOpts...), cell.FgColor(c))
+		}
 		if err := draw.Border(cvs, cvs.Area(),
 			draw.BorderLineStyle(g.opts.border),
-			draw.BorderTitle(g.opts.borderTitle, draw.OverrunModeThreeDot, g.opts.borderCellOpts...),
+			draw.BorderTitle(g.opts.borderTitle, draw.OverrunModeThreeDot, borderCellOpts...),
 			draw.BorderTitleAlign(g.opts.borderTitleHAlign),
-			draw.BorderCellOpts(g.opts.borderCellOpts...),
+			draw.BorderCellOpts(borderCellOpts...),
 		); err != nil {
 			return err
 		}
@@ -279,12 +324,36 @@ func (g *Gauge) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	if progress.Dx() > 0 {
 		if err := draw.Rectangle(cvs, progress,
 			draw.RectChar(g.opts.gaugeChar),
-			draw.RectCellOpts(cell.BgColor(g.opts.color)),
+			draw.RectCellOpts(cell.BgColor(g.style.ColorOr(g.opts.color, now))),
 		); err != nil {
 			return err
 		}
 	}
-	return g.drawText(cvs, progress)
+	return g.drawText(cvs, progress, now)
+}
+
+// Changed implements widgetapi.ChangeTracker.Changed.
+// A blinking style changes the content on every redraw.
+func (g *Gauge) Changed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return !g.drawn || g.style.Blink
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (g *Gauge) SetNotifyFunc(fn func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.notify = fn
+}
+
+// markChanged records that the content changed and notifies the
+// infrastructure. The caller must hold g.mu.
+func (g *Gauge) markChanged() {
+	g.drawn = false
+	if g.notify != nil {
+		g.notify()
+	}
 }
 
 // Keyboard input isn't supported on the Gauge widget.
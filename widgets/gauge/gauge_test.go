@@ -18,16 +18,19 @@ import (
 	"fmt"
 	"image"
 	"testing"
+	"time"
 
 	"github.com/kylelemons/godebug/pretty"
 	"github.com/mum4k/termdash/align"
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
 	"github.com/mum4k/termdash/linestyle"
 	"github.com/mum4k/termdash/private/canvas"
 	"github.com/mum4k/termdash/private/canvas/testcanvas"
 	"github.com/mum4k/termdash/private/draw"
 	"github.com/mum4k/termdash/private/draw/testdraw"
 	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/rules"
 	"github.com/mum4k/termdash/terminal/terminalapi"
 	"github.com/mum4k/termdash/widgetapi"
 )
@@ -387,6 +390,48 @@ func TestGauge(t *testing.T) {
 				return ft
 			},
 		},
+		{
+			desc: "gauge showing absolute progress with a value formatter",
+			opts: []Option{
+				Char('o'),
+				ValueFormatter(format.SI("")),
+			},
+			absolute: &absoluteCall{done: 2000, total: 10000},
+			canvas:   image.Rect(0, 0, 10, 3),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustRectangle(c, image.Rect(0, 0, 2, 3),
+					draw.RectChar('o'),
+					draw.RectCellOpts(cell.BgColor(cell.ColorGreen)),
+				)
+				testdraw.MustText(c, "2k/10k", image.Point{2, 1})
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc: "gauge showing percentage with a value formatter",
+			opts: []Option{
+				Char('o'),
+				ValueFormatter(format.Percent(format.Decimals(1))),
+			},
+			percent: &percentCall{p: 30},
+			canvas:  image.Rect(0, 0, 11, 3),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustRectangle(c, image.Rect(0, 0, 3, 3),
+					draw.RectChar('o'),
+					draw.RectCellOpts(cell.BgColor(cell.ColorGreen)),
+				)
+				testdraw.MustText(c, "30.0%", image.Point{3, 1})
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
 		{
 			desc: "fails when Absolute done is negative",
 			opts: []Option{
@@ -923,6 +968,151 @@ func TestMouse(t *testing.T) {
 	}
 }
 
+func TestChanged(t *testing.T) {
+	g, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if !g.Changed() {
+		t.Errorf("Changed => false before the first Draw, want true")
+	}
+
+	cvs := testcanvas.MustNew(image.Rect(0, 0, 10, 3))
+	if err := g.Draw(cvs, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if g.Changed() {
+		t.Errorf("Changed => true after Draw, want false")
+	}
+
+	var notified int
+	g.SetNotifyFunc(func() { notified++ })
+	if err := g.Percent(50); err != nil {
+		t.Fatalf("Percent => unexpected error: %v", err)
+	}
+	if !g.Changed() {
+		t.Errorf("Changed => false after Percent, want true")
+	}
+	if notified != 1 {
+		t.Errorf("Percent => notified %d times, want 1", notified)
+	}
+}
+
+func TestRules(t *testing.T) {
+	r, err := rules.New(
+		rules.When(rules.Above(90), rules.Style{Color: cell.ColorRed, TextColor: cell.ColorWhite, BorderColor: cell.ColorYellow}),
+		rules.When(rules.Above(70), rules.Style{Color: cell.ColorBlue, Blink: true}),
+	)
+	if err != nil {
+		t.Fatalf("rules.New => unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		desc       string
+		update     func(*Gauge) error
+		now        time.Time
+		wantColor  cell.Color
+		wantText   cell.Color
+		wantBorder cell.Color
+	}{
+		{
+			desc:       "no rule matches",
+			update:     func(g *Gauge) error { return g.Percent(50) },
+			wantColor:  DefaultColor,
+			wantText:   DefaultFilledTextColor,
+			wantBorder: cell.ColorDefault,
+		},
+		{
+			desc:       "first matching rule applies",
+			update:     func(g *Gauge) error { return g.Percent(95) },
+			wantColor:  cell.ColorRed,
+			wantText:   cell.ColorWhite,
+			wantBorder: cell.ColorYellow,
+		},
+		{
+			desc:       "evaluated on the percentage of absolute progress",
+			update:     func(g *Gauge) error { return g.Absolute(19, 20) },
+			wantColor:  cell.ColorRed,
+			wantText:   cell.ColorWhite,
+			wantBorder: cell.ColorYellow,
+		},
+		{
+			desc:       "blinking style during the on phase",
+			update:     func(g *Gauge) error { return g.Percent(80) },
+			now:        time.Unix(0, 0),
+			wantColor:  cell.ColorBlue,
+			wantText:   DefaultFilledTextColor,
+			wantBorder: cell.ColorDefault,
+		},
+		{
+			desc:       "blinking style during the off phase",
+			update:     func(g *Gauge) error { return g.Percent(80) },
+			now:        time.Unix(0, 0).Add(rules.BlinkInterval),
+			wantColor:  DefaultColor,
+			wantText:   DefaultFilledTextColor,
+			wantBorder: cell.ColorDefault,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g, err := New(
+				Rules(r),
+				Border(linestyle.Light),
+				HideTextProgress(),
+				TextLabel("x"),
+				HorizontalTextAlign(align.HorizontalLeft),
+			)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			g.now = func() time.Time { return tc.now }
+			if err := tc.update(g); err != nil {
+				t.Fatalf("update => unexpected error: %v", err)
+			}
+
+			cvs := testcanvas.MustNew(image.Rect(0, 0, 10, 3))
+			if err := g.Draw(cvs, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			border := testcanvas.MustCell(cvs, image.Point{0, 0})
+			if got := border.Opts.FgColor; got != tc.wantBorder {
+				t.Errorf("border FgColor => %v, want %v", got, tc.wantBorder)
+			}
+			text := testcanvas.MustCell(cvs, image.Point{1, 1})
+			if got := text.Opts.BgColor; got != tc.wantColor {
+				t.Errorf("gauge BgColor => %v, want %v", got, tc.wantColor)
+			}
+			if got := text.Opts.FgColor; got != tc.wantText {
+				t.Errorf("text FgColor => %v, want %v", got, tc.wantText)
+			}
+		})
+	}
+}
+
+func TestChangedWhileBlinking(t *testing.T) {
+	r, err := rules.New(
+		rules.When(rules.AtLeast(0), rules.Style{Color: cell.ColorRed, Blink: true}),
+	)
+	if err != nil {
+		t.Fatalf("rules.New => unexpected error: %v", err)
+	}
+	g, err := New(Rules(r))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := g.Percent(10); err != nil {
+		t.Fatalf("Percent => unexpected error: %v", err)
+	}
+	if err := g.Draw(testcanvas.MustNew(image.Rect(0, 0, 10, 3)), &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if !g.Changed() {
+		t.Errorf("Changed => false after Draw with a blinking style, want true")
+	}
+}
+
 func TestProgressTypeString(t *testing.T) {
 	tests := []struct {
 		pt   progressType
@@ -21,8 +21,10 @@ import (
 
 	"github.com/mum4k/termdash/align"
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
 	"github.com/mum4k/termdash/linestyle"
 	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/rules"
 )
 
 // Option is used to provide options.
@@ -47,6 +49,8 @@ type options struct {
 	borderCellOpts    []cell.Option
 	borderTitle       string
 	borderTitleHAlign align.Horizontal
+	rules             *rules.Rules
+	valueFormatter    format.Formatter
 }
 
 // newOptions returns options with the default values set.
@@ -99,6 +103,17 @@ func ShowTextProgress() Option {
 	})
 }
 
+// ValueFormatter sets the function that formats the numbers in the text
+// enumerating the progress, e.g. format.Percent. If the progress is set by a
+// call to Percent(), the function formats the percentage, if it is set by a
+// call to Absolute(), the function formats both of the absolute numbers.
+// Defaults to the numbers displayed as integers.
+func ValueFormatter(f format.Formatter) Option {
+	return option(func(opts *options) {
+		opts.valueFormatter = f
+	})
+}
+
 // HideTextProgress disables the display of a text enumerating the progress.
 func HideTextProgress() Option {
 	return option(func(opts *options) {
@@ -201,3 +216,14 @@ func BorderTitleAlign(h align.Horizontal) Option {
 		opts.borderTitleHAlign = h
 	})
 }
+
+// Rules sets styling rules evaluated against the progress in percent each time
+// the progress is updated. The Color of the matching rule overrides the Color
+// option, the TextColor overrides both the FilledTextColor and the
+// EmptyTextColor options and the BorderColor overrides the color of the
+// border.
+func Rules(r *rules.Rules) Option {
+	return option(func(opts *options) {
+		opts.rules = r
+	})
+}
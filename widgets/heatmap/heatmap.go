@@ -0,0 +1,359 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package heatmap implements a widget that displays the distribution of
+// latencies over time.
+package heatmap
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// percentileRune is the rune used to draw the percentile lines.
+const percentileRune = '─'
+
+// Heatmap displays a histogram of latencies over time. Every column is one
+// interval, the most recent on the right, and every row is one or more of
+// the latency buckets, the lowest at the bottom. The color of a cell
+// indicates how many observations fell into the bucket during the interval.
+//
+// The bucket bounds are displayed on the left, the last row displays the
+// legend of the colors and the values of the percentiles.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Heatmap struct {
+	// intervals are the bucket counts of the intervals, the oldest first.
+	intervals [][]int
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the Heatmap.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new Heatmap.
+func New(opts ...Option) (*Heatmap, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Heatmap{
+		opts: opt,
+	}, nil
+}
+
+// Add adds a new interval, the counts of observations in each of the buckets
+// during the interval. The number of counts must match the number of buckets
+// set with the Buckets option.
+func (h *Heatmap) Add(counts []int) error {
+	if got, want := len(counts), len(h.opts.bounds); got != want {
+		return fmt.Errorf("got %d counts, want one count for each of the %d buckets", got, want)
+	}
+	for i, c := range counts {
+		if c < 0 {
+			return fmt.Errorf("invalid count[%d] %d, must be a non-negative number", i, c)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.intervals = append(h.intervals, append([]int(nil), counts...))
+	if over := len(h.intervals) - h.opts.history; over > 0 {
+		h.intervals = h.intervals[over:]
+	}
+	if h.notify != nil {
+		h.notify()
+	}
+	return nil
+}
+
+// Percentile estimates the percentile p, e.g. 99, of the most recent
+// interval by linear interpolation within the bucket it falls into.
+// Returns false if there are no observations in the most recent interval.
+func (h *Heatmap) Percentile(p float64) (float64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.intervals) == 0 {
+		return 0, false
+	}
+	v, _, ok := percentile(h.intervals[len(h.intervals)-1], h.opts.bounds, p)
+	return v, ok
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (h *Heatmap) SetNotifyFunc(fn func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.notify = fn
+}
+
+// percentile estimates the percentile p of the counts. Returns the value and
+// the index of the bucket it falls into or false if there are no
+// observations.
+func percentile(counts []int, bounds []float64, p float64) (float64, int, bool) {
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return 0, 0, false
+	}
+
+	rank := p / 100 * float64(total)
+	cum := 0
+	for i, c := range counts {
+		if c == 0 {
+			continue
+		}
+		if float64(cum+c) < rank && i < len(counts)-1 {
+			cum += c
+			continue
+		}
+
+		lower := 0.0
+		if i > 0 {
+			lower = bounds[i-1]
+		} else if bounds[0] < 0 {
+			lower = bounds[0]
+		}
+		frac := (rank - float64(cum)) / float64(c)
+		frac = math.Max(0, math.Min(1, frac))
+		return lower + (bounds[i]-lower)*frac, i, true
+	}
+	return 0, 0, false
+}
+
+// rowBuckets returns the range of buckets [lo, hi) displayed on the row,
+// where row zero is the bottom one of the rows.
+func rowBuckets(row, rows, buckets int) (int, int) {
+	lo := row * buckets / rows
+	hi := (row + 1) * buckets / rows
+	if hi <= lo {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+// bucketRow returns the lowest row that displays the bucket.
+func bucketRow(bucket, rows, buckets int) int {
+	for row := 0; row < rows; row++ {
+		if lo, hi := rowBuckets(row, rows, buckets); bucket >= lo && bucket < hi {
+			return row
+		}
+	}
+	return rows - 1
+}
+
+// color returns the color of a cell with the count.
+func (h *Heatmap) color(count, max int) cell.Color {
+	palette := h.opts.palette
+	i := int(math.Ceil(float64(count)/float64(max)*float64(len(palette)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(palette) {
+		i = len(palette) - 1
+	}
+	return palette[i]
+}
+
+// Draw draws the Heatmap widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (h *Heatmap) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ar := cvs.Area()
+	rows := ar.Dy()
+	if rows > 1 {
+		// The last row is reserved for the legend.
+		rows--
+	}
+
+	buckets := len(h.opts.bounds)
+	labels := make([]string, rows)
+	labelW := 0
+	for row := range labels {
+		_, hi := rowBuckets(row, rows, buckets)
+		labels[row] = h.opts.valueFormatter(h.opts.bounds[hi-1])
+		if w := runewidth.StringWidth(labels[row]); w > labelW {
+			labelW = w
+		}
+	}
+	x0 := labelW + 1
+	if x0 >= ar.Dx() {
+		// No space for the labels.
+		x0 = 0
+	} else {
+		last := ""
+		for row, l := range labels {
+			if l == last {
+				continue
+			}
+			last = l
+			y := rows - 1 - row
+			if err := draw.Text(cvs, l, image.Point{labelW - runewidth.StringWidth(l), y},
+				draw.TextCellOpts(h.opts.labelCellOpts...),
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	intervals := h.intervals
+	if cols := ar.Dx() - x0; len(intervals) > cols {
+		intervals = intervals[len(intervals)-cols:]
+	}
+	// sums are the counts of the rows in each of the intervals.
+	sums := make([][]int, len(intervals))
+	max := 0
+	for i, counts := range intervals {
+		sums[i] = make([]int, rows)
+		for row := range sums[i] {
+			lo, hi := rowBuckets(row, rows, buckets)
+			for _, c := range counts[lo:hi] {
+				sums[i][row] += c
+			}
+			if sums[i][row] > max {
+				max = sums[i][row]
+			}
+		}
+	}
+
+	if rows < ar.Dy() {
+		if err := h.drawLegend(cvs, ar.Dy()-1, ar.Dx(), max); err != nil {
+			return err
+		}
+	}
+
+	x := ar.Max.X - len(intervals)
+	for i, counts := range intervals {
+		for row, sum := range sums[i] {
+			if sum == 0 {
+				continue
+			}
+			p := image.Point{x, rows - 1 - row}
+			if _, err := cvs.SetCell(p, ' ', cell.BgColor(h.color(sum, max))); err != nil {
+				return err
+			}
+		}
+		for _, pct := range h.opts.percentiles {
+			_, bucket, ok := percentile(counts, h.opts.bounds, pct)
+			if !ok {
+				continue
+			}
+			p := image.Point{x, rows - 1 - bucketRow(bucket, rows, buckets)}
+			if _, err := cvs.SetCell(p, percentileRune, h.opts.percentileCellOpts...); err != nil {
+				return err
+			}
+		}
+		x++
+	}
+	return nil
+}
+
+// drawLegend draws the legend of the colors and the values of the
+// percentiles in the most recent interval onto the row. The colors go from
+// one observation to max, the largest count of a cell on the heatmap.
+// The caller must hold h.mu.
+func (h *Heatmap) drawLegend(cvs *canvas.Canvas, y, width, max int) error {
+	if max == 0 {
+		return nil
+	}
+
+	x := 0
+	text := func(s string) error {
+		if x >= width {
+			return nil
+		}
+		if err := draw.Text(cvs, s, image.Point{x, y},
+			draw.TextMaxX(width),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(h.opts.labelCellOpts...),
+		); err != nil {
+			return err
+		}
+		x += runewidth.StringWidth(s)
+		return nil
+	}
+
+	if err := text("1 "); err != nil {
+		return err
+	}
+	for _, c := range h.opts.palette {
+		if x >= width {
+			return nil
+		}
+		if _, err := cvs.SetCell(image.Point{x, y}, ' ', cell.BgColor(c)); err != nil {
+			return err
+		}
+		x++
+	}
+	if err := text(fmt.Sprintf(" %d", max)); err != nil {
+		return err
+	}
+
+	if len(h.intervals) == 0 {
+		return nil
+	}
+	last := h.intervals[len(h.intervals)-1]
+	for _, pct := range h.opts.percentiles {
+		v, _, ok := percentile(last, h.opts.bounds, pct)
+		if !ok {
+			continue
+		}
+		if err := text(fmt.Sprintf("  p%g %s", pct, h.opts.valueFormatter(v))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Keyboard input isn't supported on the Heatmap widget.
+func (*Heatmap) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the Heatmap widget doesn't support keyboard events")
+}
+
+// Mouse input isn't supported on the Heatmap widget.
+func (*Heatmap) Mouse(m *terminalapi.Mouse) error {
+	return errors.New("the Heatmap widget doesn't support mouse events")
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*Heatmap) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeNone,
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heatmap
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"testing"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+func TestHeatmap(t *testing.T) {
+	tests := []struct {
+		desc       string
+		opts       []Option
+		intervals  [][]int
+		size       image.Point
+		want       func(size image.Point) *faketerm.Terminal
+		wantNewErr bool
+		wantErr    bool
+	}{
+		{
+			desc:       "fails without Buckets",
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on bounds that aren't ascending",
+			opts:       []Option{Buckets(10, 10)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on an infinite bound",
+			opts:       []Option{Buckets(10, math.Inf(1))},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on an empty Palette",
+			opts:       []Option{Buckets(10), Palette()},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on a percentile out of range",
+			opts:       []Option{Buckets(10), Percentiles(101)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on nil ValueFormatter",
+			opts:       []Option{Buckets(10), ValueFormatter(nil)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on zero History",
+			opts:       []Option{Buckets(10), History(0)},
+			wantNewErr: true,
+		},
+		{
+			desc:      "fails when the counts don't match the buckets",
+			opts:      []Option{Buckets(10, 20)},
+			intervals: [][]int{{1}},
+			wantErr:   true,
+		},
+		{
+			desc:      "fails on a negative count",
+			opts:      []Option{Buckets(10, 20)},
+			intervals: [][]int{{1, -1}},
+			wantErr:   true,
+		},
+		{
+			desc: "draws only the labels without intervals",
+			opts: []Option{Buckets(10, 20)},
+			size: image.Point{5, 3},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "20", image.Point{0, 0})
+				testdraw.MustText(cvs, "10", image.Point{0, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "colors the cells by the counts",
+			opts: []Option{
+				Buckets(10, 20, 30),
+				Palette(cell.ColorRed, cell.ColorBlue),
+			},
+			intervals: [][]int{
+				{1, 0, 2},
+				{0, 4, 0},
+			},
+			size: image.Point{6, 4},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "30", image.Point{0, 0})
+				testdraw.MustText(cvs, "20", image.Point{0, 1})
+				testdraw.MustText(cvs, "10", image.Point{0, 2})
+				red := cell.BgColor(cell.ColorRed)
+				blue := cell.BgColor(cell.ColorBlue)
+				testcanvas.MustSetCell(cvs, image.Point{4, 0}, ' ', red)
+				testcanvas.MustSetCell(cvs, image.Point{4, 2}, ' ', red)
+				testcanvas.MustSetCell(cvs, image.Point{5, 1}, ' ', blue)
+
+				testdraw.MustText(cvs, "1 ", image.Point{0, 3})
+				testcanvas.MustSetCell(cvs, image.Point{2, 3}, ' ', red)
+				testcanvas.MustSetCell(cvs, image.Point{3, 3}, ' ', blue)
+				testdraw.MustText(cvs, " 4", image.Point{4, 3})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "groups buckets when there are more buckets than rows",
+			opts: []Option{
+				Buckets(10, 20, 30, 40),
+				Palette(cell.ColorRed, cell.ColorBlue),
+			},
+			intervals: [][]int{
+				{1, 1, 0, 1},
+			},
+			size: image.Point{4, 3},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "40", image.Point{0, 0})
+				testdraw.MustText(cvs, "20", image.Point{0, 1})
+				testcanvas.MustSetCell(cvs, image.Point{3, 0}, ' ', cell.BgColor(cell.ColorRed))
+				testcanvas.MustSetCell(cvs, image.Point{3, 1}, ' ', cell.BgColor(cell.ColorBlue))
+
+				testdraw.MustText(cvs, "1 ", image.Point{0, 2})
+				testcanvas.MustSetCell(cvs, image.Point{2, 2}, ' ', cell.BgColor(cell.ColorRed))
+				testcanvas.MustSetCell(cvs, image.Point{3, 2}, ' ', cell.BgColor(cell.ColorBlue))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "draws the percentile lines and values",
+			opts: []Option{
+				Buckets(10, 20, 30, 40),
+				Palette(cell.ColorRed),
+				Percentiles(50),
+				ValueFormatter(func(v float64) string {
+					return fmt.Sprintf("%gms", v)
+				}),
+			},
+			intervals: [][]int{
+				{0, 2, 2, 0},
+			},
+			size: image.Point{20, 5},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "40ms", image.Point{0, 0})
+				testdraw.MustText(cvs, "30ms", image.Point{0, 1})
+				testdraw.MustText(cvs, "20ms", image.Point{0, 2})
+				testdraw.MustText(cvs, "10ms", image.Point{0, 3})
+				red := cell.BgColor(cell.ColorRed)
+				testcanvas.MustSetCell(cvs, image.Point{19, 1}, ' ', red)
+				testcanvas.MustSetCell(cvs, image.Point{19, 2}, '─', red, cell.FgColor(cell.ColorWhite))
+
+				testdraw.MustText(cvs, "1 ", image.Point{0, 4})
+				testcanvas.MustSetCell(cvs, image.Point{2, 4}, ' ', red)
+				testdraw.MustText(cvs, " 2  p50 20ms", image.Point{3, 4})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "drops the oldest intervals over the History",
+			opts: []Option{
+				Buckets(10),
+				Palette(cell.ColorRed),
+				History(1),
+			},
+			intervals: [][]int{
+				{5},
+				{1},
+			},
+			size: image.Point{6, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "10", image.Point{0, 0})
+				testcanvas.MustSetCell(cvs, image.Point{5, 0}, ' ', cell.BgColor(cell.ColorRed))
+
+				testdraw.MustText(cvs, "1 ", image.Point{0, 1})
+				testcanvas.MustSetCell(cvs, image.Point{2, 1}, ' ', cell.BgColor(cell.ColorRed))
+				testdraw.MustText(cvs, " 1", image.Point{3, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			h, err := New(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, counts := range tc.intervals {
+				err := h.Add(counts)
+				if (err != nil) != tc.wantErr {
+					t.Errorf("Add => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+				}
+				if err != nil {
+					return
+				}
+			}
+
+			c, err := canvas.New(image.Rectangle{Max: tc.size})
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := h.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		desc   string
+		counts []int
+		p      float64
+		want   float64
+		wantOK bool
+	}{
+		{
+			desc:   "no observations",
+			counts: []int{0, 0, 0, 0},
+			p:      50,
+		},
+		{
+			desc:   "zero percentile is the lower bound of the first observed bucket",
+			counts: []int{0, 2, 2, 0},
+			p:      0,
+			want:   10,
+			wantOK: true,
+		},
+		{
+			desc:   "median",
+			counts: []int{0, 2, 2, 0},
+			p:      50,
+			want:   20,
+			wantOK: true,
+		},
+		{
+			desc:   "interpolates within the bucket",
+			counts: []int{0, 2, 2, 0},
+			p:      75,
+			want:   25,
+			wantOK: true,
+		},
+		{
+			desc:   "maximum",
+			counts: []int{0, 2, 2, 0},
+			p:      100,
+			want:   30,
+			wantOK: true,
+		},
+		{
+			desc:   "first bucket starts at zero",
+			counts: []int{4, 0, 0, 0},
+			p:      50,
+			want:   5,
+			wantOK: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			h, err := New(Buckets(10, 20, 30, 40))
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := h.Add(tc.counts); err != nil {
+				t.Fatalf("Add => unexpected error: %v", err)
+			}
+			got, gotOK := h.Percentile(tc.p)
+			if got != tc.want || gotOK != tc.wantOK {
+				t.Errorf("Percentile(%v) => %v, %v, want %v, %v", tc.p, got, gotOK, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestNotifies(t *testing.T) {
+	h, err := New(Buckets(10))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	h.SetNotifyFunc(func() {
+		notified++
+	})
+	for i := 0; i < 3; i++ {
+		if err := h.Add([]int{i}); err != nil {
+			t.Fatalf("Add => unexpected error: %v", err)
+		}
+	}
+	if want := 3; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
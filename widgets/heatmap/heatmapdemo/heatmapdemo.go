@@ -0,0 +1,109 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary heatmapdemo displays the distribution of simulated request
+// latencies over time.
+// Exits when 'q' is pressed.
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/heatmap"
+)
+
+// bounds are the upper bounds of the latency buckets in milliseconds.
+var bounds = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000}
+
+// observe simulates a second worth of requests every 500ms and provides the
+// bucket counts of their latencies to the widget. The latencies slowly
+// drift up and down. Exits when the context expires.
+func observe(ctx context.Context, h *heatmap.Heatmap) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for step := 0; ; step++ {
+		median := 20 + 15*math.Sin(float64(step)/10)
+		counts := make([]int, len(bounds))
+		for i := 0; i < 200; i++ {
+			latency := median * math.Exp(rand.NormFloat64()*0.8)
+			b := len(bounds) - 1
+			for j, bound := range bounds {
+				if latency <= bound {
+					b = j
+					break
+				}
+			}
+			counts[b]++
+		}
+		if err := h.Add(counts); err != nil {
+			panic(err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h, err := heatmap.New(
+		heatmap.Buckets(bounds...),
+		heatmap.Percentiles(50, 99),
+		heatmap.ValueFormatter(func(v float64) string {
+			return fmt.Sprintf("%.0fms", v)
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+	go observe(ctx, h)
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS Q TO QUIT"),
+		container.PlaceWidget(h),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == 'q' || k.Key == 'Q' {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter), termdash.RedrawInterval(250*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heatmap
+
+// options.go contains configurable options for Heatmap.
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	bounds             []float64
+	palette            []cell.Color
+	percentiles        []float64
+	percentileCellOpts []cell.Option
+	labelCellOpts      []cell.Option
+	valueFormatter     format.Formatter
+	history            int
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		palette: DefaultPalette,
+		percentileCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorWhite),
+		},
+		valueFormatter: func(v float64) string {
+			return fmt.Sprintf("%g", v)
+		},
+		history: DefaultHistory,
+	}
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if len(o.bounds) == 0 {
+		return errors.New("the Buckets option is required")
+	}
+	for i, b := range o.bounds {
+		if math.IsNaN(b) || math.IsInf(b, 0) {
+			return fmt.Errorf("invalid Buckets, bound[%d] %v must be a finite number", i, b)
+		}
+		if i > 0 && b <= o.bounds[i-1] {
+			return fmt.Errorf("invalid Buckets, bound[%d] %v must be larger than bound[%d] %v", i, b, i-1, o.bounds[i-1])
+		}
+	}
+	if len(o.palette) == 0 {
+		return errors.New("invalid Palette, must contain at least one color")
+	}
+	for _, p := range o.percentiles {
+		if min, max := 0.0, 100.0; math.IsNaN(p) || p < min || p > max {
+			return fmt.Errorf("invalid Percentiles, %v must be in range %v <= p <= %v", p, min, max)
+		}
+	}
+	if o.valueFormatter == nil {
+		return errors.New("the ValueFormatter cannot be nil")
+	}
+	if min := 1; o.history < min {
+		return fmt.Errorf("invalid History(%d), must be at least %d", o.history, min)
+	}
+	return nil
+}
+
+// Buckets sets the upper bounds of the latency buckets, in ascending order.
+// Every interval provided to Heatmap.Add carries one count per bucket, the
+// count of observations that were larger than the previous bound and at most
+// equal to the bound of the bucket. The lower bound of the first bucket is
+// zero.
+// This option is required.
+func Buckets(bounds ...float64) Option {
+	return option(func(o *options) {
+		o.bounds = bounds
+	})
+}
+
+// DefaultPalette is the default value for the Palette option, it goes from
+// dark blue through green and yellow to red.
+var DefaultPalette = []cell.Color{
+	cell.ColorNumber(17),
+	cell.ColorNumber(19),
+	cell.ColorNumber(25),
+	cell.ColorNumber(31),
+	cell.ColorNumber(35),
+	cell.ColorNumber(76),
+	cell.ColorNumber(148),
+	cell.ColorNumber(220),
+	cell.ColorNumber(208),
+	cell.ColorNumber(196),
+}
+
+// Palette sets the colors used for the cells of the heatmap, from the least
+// to the most observations. Cells without any observations aren't colored.
+// Defaults to DefaultPalette.
+func Palette(colors ...cell.Color) Option {
+	return option(func(o *options) {
+		o.palette = colors
+	})
+}
+
+// Percentiles sets the percentiles, e.g. 50 and 99, that are drawn as lines
+// over the heatmap. The values of the percentiles in the most recent
+// interval are displayed in the legend.
+// Defaults to no percentiles.
+func Percentiles(ps ...float64) Option {
+	return option(func(o *options) {
+		o.percentiles = ps
+	})
+}
+
+// PercentileCellOpts sets the cell options of the percentile lines.
+// Defaults to white foreground color.
+func PercentileCellOpts(cOpts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.percentileCellOpts = cOpts
+	})
+}
+
+// LabelCellOpts sets the cell options of the bucket labels and the legend.
+func LabelCellOpts(cOpts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.labelCellOpts = cOpts
+	})
+}
+
+// ValueFormatter sets the function that formats the bucket bounds and the
+// percentile values, e.g. to append a unit or format.SI.
+// Defaults to formatting with the %g verb.
+func ValueFormatter(fn format.Formatter) Option {
+	return option(func(o *options) {
+		o.valueFormatter = fn
+	})
+}
+
+// DefaultHistory is the default value for the History option.
+const DefaultHistory = 300
+
+// History sets the maximum number of intervals kept, the oldest intervals
+// are forgotten first.
+// Defaults to DefaultHistory.
+func History(intervals int) Option {
+	return option(func(o *options) {
+		o.history = intervals
+	})
+}
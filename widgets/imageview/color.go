@@ -0,0 +1,155 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageview
+
+// color.go approximates image colors by the colors available on the terminal.
+
+import (
+	"image/color"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// cubeLevels are the intensities of the individual components in the 6x6x6
+// color cube of the 256 color terminals.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// systemColors are the RGB values of the eight system colors as displayed by
+// xterm.
+var systemColors = []struct {
+	c       cell.Color
+	r, g, b int
+}{
+	{cell.ColorBlack, 0, 0, 0},
+	{cell.ColorRed, 205, 0, 0},
+	{cell.ColorGreen, 0, 205, 0},
+	{cell.ColorYellow, 205, 205, 0},
+	{cell.ColorBlue, 0, 0, 238},
+	{cell.ColorMagenta, 205, 0, 205},
+	{cell.ColorCyan, 0, 205, 205},
+	{cell.ColorWhite, 229, 229, 229},
+}
+
+// cubeIndex returns the index of the cube level closest to the intensity v in
+// range 0-255.
+func cubeIndex(v int) int {
+	switch {
+	case v < 48:
+		return 0
+	case v < 115:
+		return 1
+	default:
+		return (v - 35) / 40
+	}
+}
+
+// grayIndex returns the index of the shade of gray (0-23) closest to the
+// intensity v in range 0-255.
+func grayIndex(v int) int {
+	switch {
+	case v < 8:
+		return 0
+	case v > 238:
+		return 23
+	default:
+		return (v - 3) / 10
+	}
+}
+
+// grayLevel returns the intensity of the shade of gray at the index.
+func grayLevel(i int) int {
+	return 8 + 10*i
+}
+
+// luminance returns the perceived brightness of the color in range 0-255.
+func luminance(r, g, b int) int {
+	return (299*r + 587*g + 114*b) / 1000
+}
+
+// distance returns the squared distance between two colors.
+func distance(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
+
+// colorMode returns the color mode matching the color depth the terminal
+// reports.
+func colorMode(caps terminalapi.Capabilities) terminalapi.ColorMode {
+	switch d := caps.ColorDepth; {
+	case d == 0:
+		return DefaultColorMode
+	case d <= 16:
+		return terminalapi.ColorModeNormal
+	case d == 24:
+		return terminalapi.ColorModeGrayscale
+	case d == 216:
+		return terminalapi.ColorMode216
+	default:
+		return terminalapi.ColorMode256
+	}
+}
+
+// toCell converts the color to the closest cell color available in the color
+// mode. Returns false if the color is mostly transparent and shouldn't be
+// drawn at all.
+func toCell(c color.Color, cm terminalapi.ColorMode) (cell.Color, bool) {
+	r16, g16, b16, a16 := c.RGBA()
+	if a16 < 0x8000 {
+		return cell.ColorDefault, false
+	}
+	r, g, b := int(r16>>8), int(g16>>8), int(b16>>8)
+	if a16 < 0xffff {
+		// Undo the alpha premultiplication.
+		r, g, b = int(r16*0xff/a16), int(g16*0xff/a16), int(b16*0xff/a16)
+	}
+	return toCellRGB(r, g, b, cm), true
+}
+
+// toCellRGB converts the RGB values in range 0-255 to the closest cell color
+// available in the color mode.
+func toCellRGB(r, g, b int, cm terminalapi.ColorMode) cell.Color {
+	switch cm {
+	case terminalapi.ColorModeNormal:
+		best := systemColors[0]
+		bestDist := -1
+		for _, sc := range systemColors {
+			if d := distance(r, g, b, sc.r, sc.g, sc.b); bestDist < 0 || d < bestDist {
+				best, bestDist = sc, d
+			}
+		}
+		return best.c
+
+	case terminalapi.ColorMode216:
+		ri, gi, bi := cubeIndex(r), cubeIndex(g), cubeIndex(b)
+		// Colors in this mode are zero based.
+		return cell.ColorNumber(36*ri + 6*gi + bi)
+
+	case terminalapi.ColorModeGrayscale:
+		// Colors in this mode are zero based.
+		return cell.ColorNumber(grayIndex(luminance(r, g, b)))
+
+	default:
+		ri, gi, bi := cubeIndex(r), cubeIndex(g), cubeIndex(b)
+		cubeDist := distance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+		gi2 := grayIndex((r + g + b) / 3)
+		gl := grayLevel(gi2)
+		if grayDist := distance(r, g, b, gl, gl, gl); grayDist < cubeDist {
+			return cell.ColorNumber(232 + gi2)
+		}
+		return cell.ColorNumber(16 + 36*ri + 6*gi + bi)
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package imageview is a widget that displays an image.
+package imageview
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/alignfor"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// Runes used to draw the pixels. Each cell displays two pixels on top of each
+// other, the upper pixel uses the foreground and the lower pixel uses the
+// background color of the cell.
+const (
+	upperHalf = '▀'
+	lowerHalf = '▄'
+)
+
+// ImageView displays an image.
+//
+// The image is scaled to fit the canvas and its colors are approximated by
+// the colors available in the color mode of the terminal. Each cell displays
+// two pixels using the unicode half-block characters.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type ImageView struct {
+	// img is the displayed image.
+	img image.Image
+
+	// mu protects the ImageView.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+}
+
+// New returns a new ImageView.
+func New(opts ...Option) (*ImageView, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &ImageView{
+		opts: opt,
+	}, nil
+}
+
+// Set sets the image to display. The image isn't copied, the caller must not
+// modify it after this call.
+// Provided options override values set when New() was called.
+func (iv *ImageView) Set(img image.Image, opts ...Option) error {
+	iv.mu.Lock()
+	defer iv.mu.Unlock()
+
+	if img == nil {
+		return errors.New("the image cannot be nil")
+	}
+	if img.Bounds().Empty() {
+		return errors.New("the image cannot be empty")
+	}
+
+	// The options are only applied if they are valid.
+	newOpts := *iv.opts
+	for _, opt := range opts {
+		opt.set(&newOpts)
+	}
+	if err := newOpts.validate(); err != nil {
+		return err
+	}
+	iv.opts = &newOpts
+	iv.img = img
+	iv.markChanged()
+	return nil
+}
+
+// Reset removes the displayed image.
+func (iv *ImageView) Reset() {
+	iv.mu.Lock()
+	defer iv.mu.Unlock()
+
+	iv.img = nil
+	iv.markChanged()
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (iv *ImageView) SetNotifyFunc(fn func()) {
+	iv.mu.Lock()
+	defer iv.mu.Unlock()
+	iv.notify = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// iv.mu must be held when calling this method.
+func (iv *ImageView) markChanged() {
+	if iv.notify != nil {
+		iv.notify()
+	}
+}
+
+// pixelArea returns the area in pixels the image should be scaled to.
+// The canvas of the provided size in cells has twice as many rows of pixels.
+func (iv *ImageView) pixelArea(cvsSize image.Point) (image.Rectangle, error) {
+	pixAr := image.Rect(0, 0, cvsSize.X, cvsSize.Y*2)
+	if iv.opts.stretch {
+		return pixAr, nil
+	}
+
+	imgSize := iv.img.Bounds().Size()
+	// Scale by the dimension that is more constrained.
+	w, h := pixAr.Dx(), imgSize.Y*pixAr.Dx()/imgSize.X
+	if h > pixAr.Dy() {
+		w, h = imgSize.X*pixAr.Dy()/imgSize.Y, pixAr.Dy()
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return alignfor.Rectangle(pixAr, image.Rect(0, 0, w, h), iv.opts.hAlign, iv.opts.vAlign)
+}
+
+// sample returns the average color of the pixels of the image that get
+// scaled into the target pixel at the point p of an area of the provided
+// size.
+func (iv *ImageView) sample(p, size image.Point) color.Color {
+	b := iv.img.Bounds()
+	x0 := b.Min.X + p.X*b.Dx()/size.X
+	x1 := b.Min.X + (p.X+1)*b.Dx()/size.X
+	y0 := b.Min.Y + p.Y*b.Dy()/size.Y
+	y1 := b.Min.Y + (p.Y+1)*b.Dy()/size.Y
+	if x1 <= x0 {
+		x1 = x0 + 1
+	}
+	if y1 <= y0 {
+		y1 = y0 + 1
+	}
+
+	var r, g, bl, a, n uint64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			pr, pg, pb, pa := iv.img.At(x, y).RGBA()
+			r += uint64(pr)
+			g += uint64(pg)
+			bl += uint64(pb)
+			a += uint64(pa)
+			n++
+		}
+	}
+	return color.RGBA64{
+		R: uint16(r / n),
+		G: uint16(g / n),
+		B: uint16(bl / n),
+		A: uint16(a / n),
+	}
+}
+
+// pixel is a single scaled pixel of the image.
+type pixel struct {
+	// color is the color of the pixel.
+	color cell.Color
+	// visible is false for transparent pixels.
+	visible bool
+}
+
+// Draw draws the ImageView widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (iv *ImageView) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	iv.mu.Lock()
+	defer iv.mu.Unlock()
+
+	if iv.img == nil {
+		return nil
+	}
+
+	cvsAr := cvs.Area()
+	pixAr, err := iv.pixelArea(cvsAr.Size())
+	if err != nil {
+		return err
+	}
+
+	// pixels are indexed by column then row like the canvas buffer.
+	pixels := make([][]pixel, cvsAr.Dx())
+	for x := range pixels {
+		pixels[x] = make([]pixel, cvsAr.Dy()*2)
+	}
+	cm := colorMode(meta.Capabilities)
+	if iv.opts.colorMode != nil {
+		cm = *iv.opts.colorMode
+	}
+	size := pixAr.Size()
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			c, visible := toCell(iv.sample(image.Point{x, y}, size), cm)
+			pixels[pixAr.Min.X+x][pixAr.Min.Y+y] = pixel{color: c, visible: visible}
+		}
+	}
+
+	for x := 0; x < cvsAr.Dx(); x++ {
+		for y := 0; y < cvsAr.Dy(); y++ {
+			top, bottom := pixels[x][2*y], pixels[x][2*y+1]
+			p := image.Point{x, y}
+
+			var err error
+			switch {
+			case top.visible && bottom.visible:
+				_, err = cvs.SetCell(p, upperHalf, cell.FgColor(top.color), cell.BgColor(bottom.color))
+			case top.visible:
+				_, err = cvs.SetCell(p, upperHalf, cell.FgColor(top.color))
+			case bottom.visible:
+				_, err = cvs.SetCell(p, lowerHalf, cell.FgColor(bottom.color))
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Keyboard input isn't supported on the ImageView widget.
+func (*ImageView) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the ImageView widget doesn't support keyboard events")
+}
+
+// Mouse input isn't supported on the ImageView widget.
+func (*ImageView) Mouse(m *terminalapi.Mouse) error {
+	return errors.New("the ImageView widget doesn't support mouse events")
+}
+
+// Options implements widgetapi.Widget.Options.
+func (iv *ImageView) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeNone,
+	}
+}
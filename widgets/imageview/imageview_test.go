@@ -0,0 +1,490 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageview
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// solid returns an image of the provided size filled with the color.
+func solid(size image.Point, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+	for x := 0; x < size.X; x++ {
+		for y := 0; y < size.Y; y++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// halves returns an image whose upper half is filled with the color top and
+// the lower half with the color bottom.
+func halves(size image.Point, top, bottom color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+	for x := 0; x < size.X; x++ {
+		for y := 0; y < size.Y; y++ {
+			if y < size.Y/2 {
+				img.Set(x, y, top)
+			} else {
+				img.Set(x, y, bottom)
+			}
+		}
+	}
+	return img
+}
+
+var (
+	red   = color.RGBA{255, 0, 0, 255}
+	blue  = color.RGBA{0, 0, 255, 255}
+	clear = color.RGBA{0, 0, 0, 0}
+)
+
+func TestImageView(t *testing.T) {
+	tests := []struct {
+		desc          string
+		opts          []Option
+		update        func(*ImageView) error // update gets called before drawing of the widget.
+		caps          terminalapi.Capabilities
+		canvas        image.Rectangle
+		want          func(size image.Point) *faketerm.Terminal
+		wantErr       bool
+		wantUpdateErr bool
+	}{
+		{
+			desc: "fails on unsupported color mode",
+			opts: []Option{
+				ColorMode(terminalapi.ColorMode(-1)),
+			},
+			canvas:  image.Rect(0, 0, 1, 1),
+			wantErr: true,
+		},
+		{
+			desc: "fails on nil image",
+			update: func(iv *ImageView) error {
+				return iv.Set(nil)
+			},
+			canvas:        image.Rect(0, 0, 1, 1),
+			wantUpdateErr: true,
+		},
+		{
+			desc: "fails on empty image",
+			update: func(iv *ImageView) error {
+				return iv.Set(image.NewRGBA(image.ZR))
+			},
+			canvas:        image.Rect(0, 0, 1, 1),
+			wantUpdateErr: true,
+		},
+		{
+			desc: "draws nothing without an image",
+			update: func(iv *ImageView) error {
+				return nil
+			},
+			canvas: image.Rect(0, 0, 3, 3),
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+		{
+			desc: "draws nothing after reset",
+			update: func(iv *ImageView) error {
+				if err := iv.Set(solid(image.Point{2, 2}, red)); err != nil {
+					return err
+				}
+				iv.Reset()
+				return nil
+			},
+			canvas: image.Rect(0, 0, 3, 3),
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+		{
+			desc: "scales up an image keeping its aspect ratio",
+			update: func(iv *ImageView) error {
+				return iv.Set(halves(image.Point{2, 2}, red, blue))
+			},
+			canvas: image.Rect(0, 0, 6, 2),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				// 4x4 pixels centered on the 6x4 pixel grid.
+				testcanvas.MustSetAreaCells(c, image.Rect(1, 0, 5, 1), upperHalf,
+					cell.FgColor(cell.ColorNumber(196)), cell.BgColor(cell.ColorNumber(196)))
+				testcanvas.MustSetAreaCells(c, image.Rect(1, 1, 5, 2), upperHalf,
+					cell.FgColor(cell.ColorNumber(21)), cell.BgColor(cell.ColorNumber(21)))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc: "aligns the image",
+			opts: []Option{
+				AlignHorizontal(align.HorizontalLeft),
+			},
+			update: func(iv *ImageView) error {
+				return iv.Set(solid(image.Point{2, 2}, red))
+			},
+			canvas: image.Rect(0, 0, 4, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetAreaCells(c, image.Rect(0, 0, 2, 1), upperHalf,
+					cell.FgColor(cell.ColorNumber(196)), cell.BgColor(cell.ColorNumber(196)))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc: "stretches the image",
+			opts: []Option{
+				Stretch(),
+			},
+			update: func(iv *ImageView) error {
+				return iv.Set(solid(image.Point{2, 2}, red))
+			},
+			canvas: image.Rect(0, 0, 4, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetAreaCells(c, c.Area(), upperHalf,
+					cell.FgColor(cell.ColorNumber(196)), cell.BgColor(cell.ColorNumber(196)))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc: "vertically aligned image starts on the lower half of a cell",
+			opts: []Option{
+				AlignVertical(align.VerticalMiddle),
+			},
+			update: func(iv *ImageView) error {
+				return iv.Set(solid(image.Point{4, 2}, red))
+			},
+			canvas: image.Rect(0, 0, 4, 2),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				// 4x2 pixels in the middle of a 4x4 pixel grid.
+				testcanvas.MustSetAreaCells(c, image.Rect(0, 0, 4, 1), lowerHalf,
+					cell.FgColor(cell.ColorNumber(196)))
+				testcanvas.MustSetAreaCells(c, image.Rect(0, 1, 4, 2), upperHalf,
+					cell.FgColor(cell.ColorNumber(196)))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc: "skips transparent pixels",
+			opts: []Option{
+				Stretch(),
+			},
+			update: func(iv *ImageView) error {
+				return iv.Set(halves(image.Point{2, 2}, clear, red))
+			},
+			canvas: image.Rect(0, 0, 2, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetAreaCells(c, c.Area(), lowerHalf,
+					cell.FgColor(cell.ColorNumber(196)))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc: "approximates colors in the configured color mode",
+			opts: []Option{
+				ColorMode(terminalapi.ColorModeNormal),
+				Stretch(),
+			},
+			update: func(iv *ImageView) error {
+				return iv.Set(halves(image.Point{2, 2}, red, blue))
+			},
+			canvas: image.Rect(0, 0, 1, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetCell(c, image.Point{0, 0}, upperHalf,
+					cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorBlue))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc: "approximates colors in the color mode of the terminal",
+			opts: []Option{
+				Stretch(),
+			},
+			update: func(iv *ImageView) error {
+				return iv.Set(halves(image.Point{2, 2}, red, blue))
+			},
+			caps:   terminalapi.Capabilities{ColorDepth: 8},
+			canvas: image.Rect(0, 0, 1, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetCell(c, image.Point{0, 0}, upperHalf,
+					cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorBlue))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc: "the ColorMode option overrides the color mode of the terminal",
+			opts: []Option{
+				ColorMode(terminalapi.ColorMode256),
+				Stretch(),
+			},
+			update: func(iv *ImageView) error {
+				return iv.Set(solid(image.Point{2, 2}, red))
+			},
+			caps:   terminalapi.Capabilities{ColorDepth: 8},
+			canvas: image.Rect(0, 0, 1, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetCell(c, image.Point{0, 0}, upperHalf,
+					cell.FgColor(cell.ColorNumber(196)), cell.BgColor(cell.ColorNumber(196)))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc: "invalid options provided to Set aren't applied",
+			update: func(iv *ImageView) error {
+				if err := iv.Set(solid(image.Point{2, 2}, red)); err != nil {
+					return err
+				}
+				if err := iv.Set(solid(image.Point{2, 2}, blue), Stretch(), ColorMode(terminalapi.ColorMode(-1))); err == nil {
+					return errors.New("Set => expected an error for an unsupported color mode")
+				}
+				return nil
+			},
+			canvas: image.Rect(0, 0, 2, 2),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				// The image keeps its aspect ratio and color.
+				testcanvas.MustSetAreaCells(c, image.Rect(0, 0, 2, 1), lowerHalf,
+					cell.FgColor(cell.ColorNumber(196)))
+				testcanvas.MustSetAreaCells(c, image.Rect(0, 1, 2, 2), upperHalf,
+					cell.FgColor(cell.ColorNumber(196)))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			iv, err := New(tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			err = tc.update(iv)
+			if (err != nil) != tc.wantUpdateErr {
+				t.Errorf("update => unexpected error: %v, wantUpdateErr: %v", err, tc.wantUpdateErr)
+			}
+			if err != nil {
+				return
+			}
+
+			c, err := canvas.New(tc.canvas)
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := iv.Draw(c, &widgetapi.Meta{Capabilities: tc.caps}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestToCellRGB(t *testing.T) {
+	tests := []struct {
+		desc    string
+		r, g, b int
+		cm      terminalapi.ColorMode
+		want    cell.Color
+	}{
+		{
+			desc: "256 colors, color cube",
+			r:    255, g: 135, b: 0,
+			cm:   terminalapi.ColorMode256,
+			want: cell.ColorNumber(16 + 36*5 + 6*2),
+		},
+		{
+			desc: "256 colors, prefers the closer shade of gray",
+			r:    100, g: 100, b: 100,
+			cm:   terminalapi.ColorMode256,
+			want: cell.ColorNumber(232 + 9),
+		},
+		{
+			desc: "216 colors are zero based",
+			r:    0, g: 0, b: 255,
+			cm:   terminalapi.ColorMode216,
+			want: cell.ColorNumber(5),
+		},
+		{
+			desc: "grayscale colors are zero based",
+			r:    255, g: 255, b: 255,
+			cm:   terminalapi.ColorModeGrayscale,
+			want: cell.ColorNumber(23),
+		},
+		{
+			desc: "normal mode uses the system colors",
+			r:    10, g: 200, b: 10,
+			cm:   terminalapi.ColorModeNormal,
+			want: cell.ColorGreen,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := toCellRGB(tc.r, tc.g, tc.b, tc.cm)
+			if got != tc.want {
+				t.Errorf("toCellRGB => %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestColorMode(t *testing.T) {
+	tests := []struct {
+		desc  string
+		depth int
+		want  terminalapi.ColorMode
+	}{
+		{
+			desc: "unknown color depth",
+			want: DefaultColorMode,
+		},
+		{
+			desc:  "monochrome",
+			depth: 2,
+			want:  terminalapi.ColorModeNormal,
+		},
+		{
+			desc:  "system colors",
+			depth: 8,
+			want:  terminalapi.ColorModeNormal,
+		},
+		{
+			desc:  "grayscale",
+			depth: 24,
+			want:  terminalapi.ColorModeGrayscale,
+		},
+		{
+			desc:  "216 colors",
+			depth: 216,
+			want:  terminalapi.ColorMode216,
+		},
+		{
+			desc:  "256 colors",
+			depth: 256,
+			want:  terminalapi.ColorMode256,
+		},
+		{
+			desc:  "true color",
+			depth: 1 << 24,
+			want:  terminalapi.ColorMode256,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := colorMode(terminalapi.Capabilities{ColorDepth: tc.depth})
+			if got != tc.want {
+				t.Errorf("colorMode => %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	iv, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	got := iv.Options()
+	want := widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeNone,
+	}
+	if diff := pretty.Compare(want, got); diff != "" {
+		t.Errorf("Options => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestNotifies(t *testing.T) {
+	iv, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	iv.SetNotifyFunc(func() {
+		notified++
+	})
+
+	if err := iv.Set(image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatalf("Set => unexpected error: %v", err)
+	}
+	if err := iv.Set(nil); err == nil {
+		t.Fatalf("Set => expected an error for a nil image")
+	}
+	iv.Reset()
+	if want := 2; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
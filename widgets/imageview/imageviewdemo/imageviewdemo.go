@@ -0,0 +1,121 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary imageviewdemo displays an image using the imageview widget.
+package main
+
+import (
+	"context"
+	"flag"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/imageview"
+)
+
+// load loads the image from the file.
+func load(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// rings generates an image with colorful concentric rings.
+func rings(size int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	center := float64(size) / 2
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			dist := math.Hypot(float64(x)-center, float64(y)-center)
+			if dist > center {
+				continue // Leave the corners transparent.
+			}
+			img.Set(x, y, color.RGBA{
+				R: uint8(255 * float64(x) / float64(size)),
+				G: uint8(255 * float64(y) / float64(size)),
+				B: uint8(127 + 127*math.Sin(dist/3)),
+				A: 255,
+			})
+		}
+	}
+	return img
+}
+
+func main() {
+	file := flag.String("file", "", "path to a PNG, JPEG or GIF image to display, displays a generated image if empty")
+	flag.Parse()
+
+	var (
+		img image.Image
+		err error
+	)
+	if *file != "" {
+		img, err = load(*file)
+		if err != nil {
+			panic(err)
+		}
+	} else {
+		img = rings(200)
+	}
+
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	iv, err := imageview.New()
+	if err != nil {
+		panic(err)
+	}
+	if err := iv.Set(img); err != nil {
+		panic(err)
+	}
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS Q TO QUIT"),
+		container.PlaceWidget(iv),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == 'q' || k.Key == 'Q' {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter)); err != nil {
+		panic(err)
+	}
+}
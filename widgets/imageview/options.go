@@ -0,0 +1,106 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageview
+
+// options.go contains configurable options for ImageView.
+
+import (
+	"fmt"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	colorMode *terminalapi.ColorMode
+	stretch   bool
+	hAlign    align.Horizontal
+	vAlign    align.Vertical
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.colorMode == nil {
+		return nil
+	}
+	switch *o.colorMode {
+	case terminalapi.ColorModeNormal, terminalapi.ColorMode256, terminalapi.ColorMode216, terminalapi.ColorModeGrayscale:
+	default:
+		return fmt.Errorf("unsupported ColorMode %v", *o.colorMode)
+	}
+	return nil
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		hAlign: align.HorizontalCenter,
+		vAlign: align.VerticalMiddle,
+	}
+}
+
+// DefaultColorMode is the color mode used when the terminal doesn't report its
+// color depth and the ColorMode option wasn't provided.
+const DefaultColorMode = terminalapi.ColorMode256
+
+// ColorMode overrides the color mode the colors of the image are approximated
+// in. By default the color mode is derived from the color depth the terminal
+// reports, see terminalapi.Capabilities.ColorDepth, or is DefaultColorMode if
+// the terminal doesn't report it.
+func ColorMode(cm terminalapi.ColorMode) Option {
+	return option(func(opts *options) {
+		opts.colorMode = &cm
+	})
+}
+
+// Stretch makes the image fill the entire canvas, disregarding its aspect
+// ratio. By default the image is scaled so that it fits the canvas while
+// keeping its aspect ratio.
+func Stretch() Option {
+	return option(func(opts *options) {
+		opts.stretch = true
+	})
+}
+
+// AlignHorizontal sets the horizontal alignment of the image when it doesn't
+// fill the entire width of the canvas. Defaults to alignment in the center.
+func AlignHorizontal(h align.Horizontal) Option {
+	return option(func(opts *options) {
+		opts.hAlign = h
+	})
+}
+
+// AlignVertical sets the vertical alignment of the image when it doesn't
+// fill the entire height of the canvas. Defaults to alignment in the middle.
+func AlignVertical(v align.Vertical) Option {
+	return option(func(opts *options) {
+		opts.vAlign = v
+	})
+}
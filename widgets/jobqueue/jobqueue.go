@@ -0,0 +1,435 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobqueue implements a widget that visualizes a queue of jobs
+// processed by workers.
+package jobqueue
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// timeNow can be replaced in tests.
+var timeNow = time.Now
+
+// sparks are the characters used to draw the charts.
+var sparks = []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// job is a job that didn't finish yet.
+type job struct {
+	// started indicates that a worker started the job.
+	started bool
+	// startedAt is when the job was started.
+	startedAt time.Time
+}
+
+// item is a finished job.
+type item struct {
+	id string
+	// elapsed is how long the job ran.
+	elapsed time.Duration
+	// err is the error the job failed with.
+	err error
+}
+
+// sample are the counts of jobs during one SampleInterval.
+type sample struct {
+	// pending and running are the numbers of the pending and running jobs at
+	// the end of the interval.
+	pending, running int
+	// finished is the number of jobs that finished during the interval.
+	finished int
+}
+
+// Counts are the numbers of jobs in each state.
+type Counts struct {
+	// Pending are the enqueued jobs that weren't started yet.
+	Pending int
+	// Running are the started jobs that didn't finish yet.
+	Running int
+	// Done and Failed are the jobs that finished successfully and with an
+	// error.
+	Done, Failed int
+}
+
+// JobQueue displays the numbers of pending, running and finished jobs of a
+// queue. The top row displays the current counts, followed by charts of the
+// pending, running and finished jobs over time and by the list of the
+// recently finished jobs.
+//
+// The widget is fed by the Enqueue, Start and Finish methods, which are
+// typically called by the code that manages the queue and its workers.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type JobQueue struct {
+	// jobs are the jobs that didn't finish yet by their ids.
+	jobs map[string]*job
+	// counts are the current counts.
+	counts Counts
+
+	// samples are the samples of the counts, the oldest first. The last
+	// sample is for the interval that started at sampleStart.
+	samples     []sample
+	sampleStart time.Time
+
+	// recent are the recently finished jobs, the most recent first.
+	recent []*item
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the JobQueue.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new JobQueue.
+func New(opts ...Option) (*JobQueue, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &JobQueue{
+		jobs:        map[string]*job{},
+		samples:     []sample{{}},
+		sampleStart: timeNow(),
+		opts:        opt,
+	}, nil
+}
+
+// roll starts new samples for the intervals that passed since the last
+// sample started. Returns true if a new sample was started.
+// The caller must hold jq.mu.
+func (jq *JobQueue) roll(now time.Time) bool {
+	passed := int(now.Sub(jq.sampleStart) / jq.opts.interval)
+	if passed <= 0 {
+		return false
+	}
+	if passed > jq.opts.history {
+		// All the samples would be forgotten anyway.
+		passed = jq.opts.history
+		jq.sampleStart = now.Add(-jq.opts.interval * time.Duration(passed))
+	}
+	for i := 0; i < passed; i++ {
+		jq.samples = append(jq.samples, sample{
+			pending: jq.counts.Pending,
+			running: jq.counts.Running,
+		})
+		jq.sampleStart = jq.sampleStart.Add(jq.opts.interval)
+	}
+	if over := len(jq.samples) - jq.opts.history; over > 0 {
+		jq.samples = jq.samples[over:]
+	}
+	return true
+}
+
+// update rolls the samples, applies the change and records the counts in
+// the last sample, then notifies the infrastructure.
+func (jq *JobQueue) update(change func() error) error {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	jq.roll(timeNow())
+	if err := change(); err != nil {
+		return err
+	}
+	last := &jq.samples[len(jq.samples)-1]
+	last.pending = jq.counts.Pending
+	last.running = jq.counts.Running
+	if jq.notify != nil {
+		jq.notify()
+	}
+	return nil
+}
+
+// Enqueue records that the job with the id was added to the queue. The id
+// must be unique among the jobs that didn't finish yet.
+func (jq *JobQueue) Enqueue(id string) error {
+	if id == "" {
+		return errors.New("the job id cannot be an empty string")
+	}
+	return jq.update(func() error {
+		if _, ok := jq.jobs[id]; ok {
+			return fmt.Errorf("the job %q is already in the queue", id)
+		}
+		jq.jobs[id] = &job{}
+		jq.counts.Pending++
+		return nil
+	})
+}
+
+// Start records that a worker started the pending job with the id.
+func (jq *JobQueue) Start(id string) error {
+	return jq.update(func() error {
+		j, ok := jq.jobs[id]
+		if !ok {
+			return fmt.Errorf("the job %q isn't in the queue", id)
+		}
+		if j.started {
+			return fmt.Errorf("the job %q was already started", id)
+		}
+		j.started = true
+		j.startedAt = timeNow()
+		jq.counts.Pending--
+		jq.counts.Running++
+		return nil
+	})
+}
+
+// Finish records that the job with the id finished, successfully if the
+// error is nil. A pending job can finish without being started, e.g. when
+// it is canceled.
+func (jq *JobQueue) Finish(id string, err error) error {
+	return jq.update(func() error {
+		j, ok := jq.jobs[id]
+		if !ok {
+			return fmt.Errorf("the job %q isn't in the queue", id)
+		}
+		delete(jq.jobs, id)
+
+		it := &item{id: id, err: err}
+		if j.started {
+			it.elapsed = timeNow().Sub(j.startedAt)
+			jq.counts.Running--
+		} else {
+			jq.counts.Pending--
+		}
+		if err != nil {
+			jq.counts.Failed++
+		} else {
+			jq.counts.Done++
+		}
+		jq.samples[len(jq.samples)-1].finished++
+
+		if jq.opts.recent > 0 {
+			jq.recent = append([]*item{it}, jq.recent...)
+			if len(jq.recent) > jq.opts.recent {
+				jq.recent = jq.recent[:jq.opts.recent]
+			}
+		}
+		return nil
+	})
+}
+
+// Counts returns the current numbers of jobs in each state.
+func (jq *JobQueue) Counts() Counts {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+	return jq.counts
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (jq *JobQueue) SetNotifyFunc(fn func()) {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+	jq.notify = fn
+}
+
+// Tick starts a new sample once the SampleInterval passes, so the charts
+// move even if no jobs change their state.
+// Implements widgetapi.Ticker.Tick.
+func (jq *JobQueue) Tick(now time.Time) {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+	if jq.roll(now) && jq.notify != nil {
+		jq.notify()
+	}
+}
+
+// chartLabelWidth is the width of the labels of the charts.
+const chartLabelWidth = 9
+
+// Draw draws the JobQueue widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (jq *JobQueue) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	jq.roll(timeNow())
+	if err := jq.drawCounts(cvs); err != nil {
+		return err
+	}
+
+	charts := []struct {
+		label string
+		value func(sample) int
+		opts  []cell.Option
+	}{
+		{"pending", func(s sample) int { return s.pending }, jq.opts.pendingCellOpts},
+		{"running", func(s sample) int { return s.running }, jq.opts.runningCellOpts},
+		{"finished", func(s sample) int { return s.finished }, jq.opts.doneCellOpts},
+	}
+	y := 1
+	for _, c := range charts {
+		if y >= cvs.Area().Dy() {
+			return nil
+		}
+		if err := jq.drawChart(cvs, y, c.label, c.value, c.opts); err != nil {
+			return err
+		}
+		y++
+	}
+
+	for _, it := range jq.recent {
+		if y >= cvs.Area().Dy() {
+			break
+		}
+		if err := jq.drawItem(cvs, y, it); err != nil {
+			return err
+		}
+		y++
+	}
+	return nil
+}
+
+// drawText draws the text on the row starting at the column and returns
+// the column after it.
+func drawText(cvs *canvas.Canvas, text string, x, y int, opts []cell.Option) (int, error) {
+	width := cvs.Area().Dx()
+	if x >= width {
+		return x, nil
+	}
+	if err := draw.Text(cvs, text, image.Point{x, y},
+		draw.TextMaxX(width),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		draw.TextCellOpts(opts...),
+	); err != nil {
+		return 0, err
+	}
+	return x + runewidth.StringWidth(text), nil
+}
+
+// drawCounts draws the current counts on the first row.
+// The caller must hold jq.mu.
+func (jq *JobQueue) drawCounts(cvs *canvas.Canvas) error {
+	counts := []struct {
+		text string
+		opts []cell.Option
+	}{
+		{fmt.Sprintf("pending %d", jq.counts.Pending), jq.opts.pendingCellOpts},
+		{fmt.Sprintf("running %d", jq.counts.Running), jq.opts.runningCellOpts},
+		{fmt.Sprintf("done %d", jq.counts.Done), jq.opts.doneCellOpts},
+		{fmt.Sprintf("failed %d", jq.counts.Failed), jq.opts.failedCellOpts},
+	}
+	x := 0
+	for i, c := range counts {
+		if i > 0 {
+			// Two cells gap between the counts.
+			x += 2
+		}
+		var err error
+		if x, err = drawText(cvs, c.text, x, 0, c.opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drawChart draws the labeled chart of the values of the samples on the
+// row. The most recent sample is on the right. Each chart is scaled to its
+// own maximum.
+// The caller must hold jq.mu.
+func (jq *JobQueue) drawChart(cvs *canvas.Canvas, y int, label string, value func(sample) int, opts []cell.Option) error {
+	if _, err := drawText(cvs, label, 0, y, nil); err != nil {
+		return err
+	}
+	width := cvs.Area().Dx() - chartLabelWidth
+	if width <= 0 {
+		return nil
+	}
+	samples := jq.samples
+	if len(samples) > width {
+		samples = samples[len(samples)-width:]
+	}
+	max := 0
+	for _, s := range samples {
+		if v := value(s); v > max {
+			max = v
+		}
+	}
+	if max == 0 {
+		return nil
+	}
+	for i, s := range samples {
+		v := value(s)
+		if v == 0 {
+			continue
+		}
+		// Any non-zero value is visible.
+		idx := (v*len(sparks) - 1) / max
+		p := image.Point{chartLabelWidth + i, y}
+		if _, err := cvs.SetCell(p, sparks[idx], opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drawItem draws the finished job on the row.
+// The caller must hold jq.mu.
+func (jq *JobQueue) drawItem(cvs *canvas.Canvas, y int, it *item) error {
+	state, opts := "done", jq.opts.doneCellOpts
+	if it.err != nil {
+		state, opts = "failed", jq.opts.failedCellOpts
+	}
+	x, err := drawText(cvs, state, 0, y, opts)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("%s %v", it.id, it.elapsed.Round(time.Millisecond))
+	if it.err != nil {
+		text = fmt.Sprintf("%s: %v", text, it.err)
+	}
+	// The states are aligned into a column.
+	if x < chartLabelWidth {
+		x = chartLabelWidth
+	}
+	_, err = drawText(cvs, text, x, y, nil)
+	return err
+}
+
+// Keyboard input isn't supported on the JobQueue widget.
+func (*JobQueue) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the JobQueue widget doesn't support keyboard events")
+}
+
+// Mouse input isn't supported on the JobQueue widget.
+func (*JobQueue) Mouse(m *terminalapi.Mouse) error {
+	return errors.New("the JobQueue widget doesn't support mouse events")
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*JobQueue) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{chartLabelWidth + 1, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeNone,
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobqueue
+
+import (
+	"errors"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// The default cell options of the states.
+var (
+	pendingOpts = []cell.Option{cell.FgColor(cell.ColorYellow)}
+	runningOpts = []cell.Option{cell.FgColor(cell.ColorBlue)}
+	doneOpts    = []cell.Option{cell.FgColor(cell.ColorGreen)}
+	failedOpts  = []cell.Option{cell.FgColor(cell.ColorRed)}
+)
+
+// mustText draws the text on the row starting at the column.
+func mustText(cvs *canvas.Canvas, text string, x, y int, opts ...cell.Option) {
+	testdraw.MustText(cvs, text, image.Point{x, y},
+		draw.TextMaxX(cvs.Area().Dx()),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		draw.TextCellOpts(opts...),
+	)
+}
+
+// step is a call to the JobQueue after some time elapsed.
+type step struct {
+	elapsed time.Duration
+	call    func(*JobQueue) error
+}
+
+func TestJobQueue(t *testing.T) {
+	tests := []struct {
+		desc       string
+		opts       []Option
+		steps      []step
+		size       image.Point
+		want       func(size image.Point) *faketerm.Terminal
+		wantCounts Counts
+		wantNewErr bool
+		wantErr    bool
+	}{
+		{
+			desc:       "fails on zero SampleInterval",
+			opts:       []Option{SampleInterval(0)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on zero History",
+			opts:       []Option{History(0)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on negative RecentItems",
+			opts:       []Option{RecentItems(-1)},
+			wantNewErr: true,
+		},
+		{
+			desc: "fails on empty id",
+			steps: []step{
+				{call: func(jq *JobQueue) error { return jq.Enqueue("") }},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on duplicate id",
+			steps: []step{
+				{call: func(jq *JobQueue) error { return jq.Enqueue("a") }},
+				{call: func(jq *JobQueue) error { return jq.Enqueue("a") }},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails to start a job that isn't in the queue",
+			steps: []step{
+				{call: func(jq *JobQueue) error { return jq.Start("a") }},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails to start a job twice",
+			steps: []step{
+				{call: func(jq *JobQueue) error { return jq.Enqueue("a") }},
+				{call: func(jq *JobQueue) error { return jq.Start("a") }},
+				{call: func(jq *JobQueue) error { return jq.Start("a") }},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails to finish a job that isn't in the queue",
+			steps: []step{
+				{call: func(jq *JobQueue) error { return jq.Finish("a", nil) }},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "empty queue",
+			size: image.Point{40, 3},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustText(cvs, "pending 0", 0, 0, pendingOpts...)
+				mustText(cvs, "running 0", 11, 0, runningOpts...)
+				mustText(cvs, "done 0", 22, 0, doneOpts...)
+				mustText(cvs, "failed 0", 30, 0, failedOpts...)
+				mustText(cvs, "pending", 0, 1)
+				mustText(cvs, "running", 0, 2)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "displays the counts over time and the recent jobs",
+			steps: []step{
+				{call: func(jq *JobQueue) error { return jq.Enqueue("a") }},
+				{call: func(jq *JobQueue) error { return jq.Enqueue("b") }},
+				{call: func(jq *JobQueue) error { return jq.Enqueue("c") }},
+				{call: func(jq *JobQueue) error { return jq.Start("a") }},
+				{elapsed: time.Second, call: func(jq *JobQueue) error { return jq.Finish("a", nil) }},
+				{call: func(jq *JobQueue) error { return jq.Start("b") }},
+				{elapsed: time.Second, call: func(jq *JobQueue) error { return jq.Finish("b", errors.New("boom")) }},
+			},
+			size: image.Point{40, 6},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustText(cvs, "pending 1", 0, 0, pendingOpts...)
+				mustText(cvs, "running 0", 11, 0, runningOpts...)
+				mustText(cvs, "done 1", 22, 0, doneOpts...)
+				mustText(cvs, "failed 1", 30, 0, failedOpts...)
+
+				mustText(cvs, "pending", 0, 1)
+				mustText(cvs, "█▄▄", 9, 1, pendingOpts...)
+				mustText(cvs, "running", 0, 2)
+				mustText(cvs, "██", 9, 2, runningOpts...)
+				mustText(cvs, "finished", 0, 3)
+				mustText(cvs, "██", 10, 3, doneOpts...)
+
+				mustText(cvs, "failed", 0, 4, failedOpts...)
+				mustText(cvs, "b 1s: boom", 9, 4)
+				mustText(cvs, "done", 0, 5, doneOpts...)
+				mustText(cvs, "a 1s", 9, 5)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCounts: Counts{Pending: 1, Done: 1, Failed: 1},
+		},
+		{
+			desc: "pending jobs can finish without being started",
+			opts: []Option{RecentItems(1)},
+			steps: []step{
+				{call: func(jq *JobQueue) error { return jq.Enqueue("a") }},
+				{call: func(jq *JobQueue) error { return jq.Enqueue("b") }},
+				{call: func(jq *JobQueue) error { return jq.Finish("a", nil) }},
+				{call: func(jq *JobQueue) error { return jq.Finish("b", nil) }},
+			},
+			size: image.Point{40, 6},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustText(cvs, "pending 0", 0, 0, pendingOpts...)
+				mustText(cvs, "running 0", 11, 0, runningOpts...)
+				mustText(cvs, "done 2", 22, 0, doneOpts...)
+				mustText(cvs, "failed 0", 30, 0, failedOpts...)
+				mustText(cvs, "pending", 0, 1)
+				mustText(cvs, "running", 0, 2)
+				mustText(cvs, "finished", 0, 3)
+				mustText(cvs, "█", 9, 3, doneOpts...)
+				mustText(cvs, "done", 0, 4, doneOpts...)
+				mustText(cvs, "b 0s", 9, 4)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCounts: Counts{Done: 2},
+		},
+		{
+			desc: "displays the most recent samples that fit",
+			opts: []Option{History(3)},
+			steps: []step{
+				{call: func(jq *JobQueue) error { return jq.Enqueue("a") }},
+				{elapsed: time.Second, call: func(jq *JobQueue) error { return jq.Enqueue("b") }},
+				{elapsed: time.Second, call: func(jq *JobQueue) error { return jq.Enqueue("c") }},
+				{elapsed: time.Second, call: func(jq *JobQueue) error { return jq.Enqueue("d") }},
+				{elapsed: time.Second, call: func(jq *JobQueue) error { return jq.Enqueue("e") }},
+			},
+			size: image.Point{40, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustText(cvs, "pending 5", 0, 0, pendingOpts...)
+				mustText(cvs, "running 0", 11, 0, runningOpts...)
+				mustText(cvs, "done 0", 22, 0, doneOpts...)
+				mustText(cvs, "failed 0", 30, 0, failedOpts...)
+				mustText(cvs, "pending", 0, 1)
+				mustText(cvs, "▅▇█", 9, 1, pendingOpts...)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCounts: Counts{Pending: 5},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			timeNow = func() time.Time { return now }
+			defer func() { timeNow = time.Now }()
+
+			jq, err := New(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			for i, s := range tc.steps {
+				now = now.Add(s.elapsed)
+				// Only the last call is expected to fail.
+				wantErr := tc.wantErr && i == len(tc.steps)-1
+				err := s.call(jq)
+				if (err != nil) != wantErr {
+					t.Errorf("call => unexpected error: %v, wantErr: %v", err, wantErr)
+				}
+				if err != nil {
+					return
+				}
+			}
+			if diff := pretty.Compare(tc.wantCounts, jq.Counts()); diff != "" {
+				t.Errorf("Counts => unexpected diff (-want, +got):\n%s", diff)
+			}
+
+			c, err := canvas.New(image.Rectangle{Max: tc.size})
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := jq.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestTick(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	jq, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	jq.SetNotifyFunc(func() {
+		notified++
+	})
+
+	jq.Tick(now.Add(500 * time.Millisecond))
+	if notified != 0 {
+		t.Errorf("Tick within the SampleInterval notified %d times, want 0", notified)
+	}
+	jq.Tick(now.Add(time.Second))
+	if notified != 1 {
+		t.Errorf("Tick after the SampleInterval notified %d times, want 1", notified)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary jobqueuedemo displays a queue of jobs processed by simulated
+// workers.
+// Exits when 'q' is pressed.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/jobqueue"
+)
+
+// workers is the number of simulated workers.
+const workers = 3
+
+// produce enqueues jobs at random intervals and sends their ids to the
+// workers. Exits when the context expires.
+func produce(ctx context.Context, jq *jobqueue.JobQueue, ids chan<- string) {
+	for i := 0; ; i++ {
+		id := fmt.Sprintf("job-%d", i)
+		if err := jq.Enqueue(id); err != nil {
+			panic(err)
+		}
+		select {
+		case ids <- id:
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case <-time.After(time.Duration(rand.Intn(600)) * time.Millisecond):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// work processes the jobs for a random duration, some of them fail. Exits
+// when the context expires.
+func work(ctx context.Context, jq *jobqueue.JobQueue, ids <-chan string) {
+	for {
+		var id string
+		select {
+		case id = <-ids:
+		case <-ctx.Done():
+			return
+		}
+		if err := jq.Start(id); err != nil {
+			panic(err)
+		}
+
+		select {
+		case <-time.After(time.Duration(500+rand.Intn(1500)) * time.Millisecond):
+		case <-ctx.Done():
+			return
+		}
+		var jobErr error
+		if rand.Intn(10) == 0 {
+			jobErr = errors.New("connection reset")
+		}
+		if err := jq.Finish(id, jobErr); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	jq, err := jobqueue.New(jobqueue.SampleInterval(500 * time.Millisecond))
+	if err != nil {
+		panic(err)
+	}
+	// The channel buffers the jobs that are pending.
+	ids := make(chan string, 100)
+	go produce(ctx, jq, ids)
+	for i := 0; i < workers; i++ {
+		go work(ctx, jq, ids)
+	}
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS Q TO QUIT"),
+		container.PlaceWidget(jq),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == 'q' || k.Key == 'Q' {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter), termdash.RedrawInterval(100*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
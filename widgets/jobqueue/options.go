@@ -0,0 +1,152 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobqueue
+
+// options.go contains configurable options for JobQueue.
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	interval        time.Duration
+	history         int
+	recent          int
+	pendingCellOpts []cell.Option
+	runningCellOpts []cell.Option
+	doneCellOpts    []cell.Option
+	failedCellOpts  []cell.Option
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		interval: DefaultSampleInterval,
+		history:  DefaultHistory,
+		recent:   DefaultRecentItems,
+		pendingCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorYellow),
+		},
+		runningCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorBlue),
+		},
+		doneCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorGreen),
+		},
+		failedCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorRed),
+		},
+	}
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.interval <= 0 {
+		return fmt.Errorf("invalid SampleInterval(%v), must be a positive duration", o.interval)
+	}
+	if min := 1; o.history < min {
+		return fmt.Errorf("invalid History(%d), must be at least %d", o.history, min)
+	}
+	if min := 0; o.recent < min {
+		return fmt.Errorf("invalid RecentItems(%d), must be %d <= value", o.recent, min)
+	}
+	return nil
+}
+
+// DefaultSampleInterval is the default value for the SampleInterval option.
+const DefaultSampleInterval = time.Second
+
+// SampleInterval sets the interval between the samples of the counts
+// displayed over time. Each column of the charts displays one sample.
+// Defaults to DefaultSampleInterval.
+func SampleInterval(d time.Duration) Option {
+	return option(func(o *options) {
+		o.interval = d
+	})
+}
+
+// DefaultHistory is the default value for the History option.
+const DefaultHistory = 300
+
+// History sets the maximum number of samples kept, the oldest samples are
+// forgotten first.
+// Defaults to DefaultHistory.
+func History(samples int) Option {
+	return option(func(o *options) {
+		o.history = samples
+	})
+}
+
+// DefaultRecentItems is the default value for the RecentItems option.
+const DefaultRecentItems = 10
+
+// RecentItems sets the maximum number of finished jobs listed below the
+// charts. Zero hides the list.
+// Defaults to DefaultRecentItems.
+func RecentItems(n int) Option {
+	return option(func(o *options) {
+		o.recent = n
+	})
+}
+
+// PendingCellOpts sets the cell options of the pending jobs.
+// Defaults to yellow text.
+func PendingCellOpts(opts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.pendingCellOpts = opts
+	})
+}
+
+// RunningCellOpts sets the cell options of the running jobs.
+// Defaults to blue text.
+func RunningCellOpts(opts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.runningCellOpts = opts
+	})
+}
+
+// DoneCellOpts sets the cell options of the jobs that finished successfully.
+// Defaults to green text.
+func DoneCellOpts(opts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.doneCellOpts = opts
+	})
+}
+
+// FailedCellOpts sets the cell options of the jobs that failed.
+// Defaults to red text.
+func FailedCellOpts(opts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.failedCellOpts = opts
+	})
+}
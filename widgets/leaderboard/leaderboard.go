@@ -0,0 +1,325 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package leaderboard implements a widget that displays the entries with the
+// highest values.
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// timeNow can be replaced in tests.
+var timeNow = time.Now
+
+// entry is a named value on the leaderboard.
+type entry struct {
+	name  string
+	value float64
+
+	// rank is the zero based rank of the entry, it is displayed if it is
+	// lower than TopN.
+	rank int
+
+	// from is the row the entry moves from and movedAt is when it started
+	// moving to the row of its rank.
+	from    float64
+	movedAt time.Time
+}
+
+// row returns the row the entry is displayed on at the time.
+func (e *entry) row(now time.Time, d time.Duration) float64 {
+	to := float64(e.rank)
+	if d <= 0 {
+		return to
+	}
+	progress := float64(now.Sub(e.movedAt)) / float64(d)
+	if progress >= 1 {
+		return to
+	}
+	return e.from + (to-e.from)*progress
+}
+
+// Leaderboard displays the TopN entries ranked by their values, one per row
+// with the rank, the name, a bar proportional to the value and the value.
+// The entries are ranked again each time a value changes.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Leaderboard struct {
+	// entries are the entries ordered by their rank.
+	entries []*entry
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the Leaderboard.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new Leaderboard.
+func New(opts ...Option) (*Leaderboard, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Leaderboard{
+		opts: opt,
+	}, nil
+}
+
+// Set sets the value of the entry with the name, adding the entry if it
+// doesn't exist yet.
+func (lb *Leaderboard) Set(name string, value float64) error {
+	if name == "" {
+		return errors.New("the entry name cannot be an empty string")
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("invalid value %v of entry %q, must be a finite number", value, name)
+	}
+
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	var e *entry
+	for _, other := range lb.entries {
+		if other.name == name {
+			e = other
+		}
+	}
+	if e == nil {
+		// New entries slide in from below the displayed rows.
+		e = &entry{
+			name: name,
+			rank: len(lb.entries) + lb.opts.topN,
+		}
+		lb.entries = append(lb.entries, e)
+	}
+	e.value = value
+	lb.rank()
+	return nil
+}
+
+// Remove removes the entry with the name. Does nothing if there is no such
+// entry.
+func (lb *Leaderboard) Remove(name string) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	for i, e := range lb.entries {
+		if e.name == name {
+			lb.entries = append(lb.entries[:i], lb.entries[i+1:]...)
+			lb.rank()
+			return
+		}
+	}
+}
+
+// Names returns the names of the entries ordered by their rank.
+func (lb *Leaderboard) Names() []string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	var names []string
+	for _, e := range lb.entries {
+		names = append(names, e.name)
+	}
+	return names
+}
+
+// rank sorts the entries and starts moving the entries whose rank changed.
+// The caller must hold lb.mu.
+func (lb *Leaderboard) rank() {
+	sort.SliceStable(lb.entries, func(i, j int) bool {
+		a, b := lb.entries[i], lb.entries[j]
+		if a.value != b.value {
+			if lb.opts.ascending {
+				return a.value < b.value
+			}
+			return a.value > b.value
+		}
+		return a.name < b.name
+	})
+
+	now := timeNow()
+	for i, e := range lb.entries {
+		if e.rank == i {
+			continue
+		}
+		from := e.row(now, lb.opts.animation)
+		if max := float64(lb.opts.topN); from > max {
+			// Entries that weren't displayed start just below the last row.
+			from = max
+		}
+		e.from = from
+		e.movedAt = now
+		e.rank = i
+	}
+	if lb.notify != nil {
+		lb.notify()
+	}
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (lb *Leaderboard) SetNotifyFunc(fn func()) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	lb.notify = fn
+}
+
+// Draw draws the Leaderboard widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (lb *Leaderboard) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	shown := lb.entries
+	if len(shown) > lb.opts.topN {
+		shown = shown[:lb.opts.topN]
+	}
+	if len(shown) == 0 {
+		return nil
+	}
+
+	// The columns are sized to the widest rank, name and value.
+	width := cvs.Area().Dx()
+	cols := &columns{
+		rank: len(fmt.Sprintf("%d.", len(shown))),
+	}
+	valueWidth := 0
+	var values []string
+	for _, e := range shown {
+		if w := runewidth.StringWidth(e.name); w > cols.name {
+			cols.name = w
+		}
+		v := lb.opts.valueFormatter(e.value)
+		values = append(values, v)
+		if w := runewidth.StringWidth(v); w > valueWidth {
+			valueWidth = w
+		}
+		if a := math.Abs(e.value); a > cols.max {
+			cols.max = a
+		}
+	}
+	if limit := width / 3; cols.name > limit {
+		cols.name = limit
+	}
+	// One cell gap between the columns.
+	cols.barStart = cols.rank + 1 + cols.name + 1
+	cols.bar = width - cols.barStart - 1 - valueWidth
+
+	now := timeNow()
+	for i, e := range shown {
+		y := int(math.Round(e.row(now, lb.opts.animation)))
+		if y < 0 || y >= cvs.Area().Dy() {
+			continue
+		}
+		if err := lb.drawRow(cvs, y, i, e, values[i], cols); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columns are the widths of the columns of the rows.
+type columns struct {
+	// rank and name are the widths of the rank and name columns.
+	rank, name int
+	// barStart is the column where the bars start and bar is their maximum
+	// width.
+	barStart, bar int
+	// max is the largest absolute value, it is displayed as the full bar.
+	max float64
+}
+
+// drawRow draws the entry with the index on the row.
+// The caller must hold lb.mu.
+func (lb *Leaderboard) drawRow(cvs *canvas.Canvas, y, i int, e *entry, value string, cols *columns) error {
+	width := cvs.Area().Dx()
+	text := func(s string, x, maxX int) error {
+		if x >= maxX {
+			return nil
+		}
+		return draw.Text(cvs, s, image.Point{x, y},
+			draw.TextMaxX(maxX),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(lb.opts.labelCellOpts...),
+		)
+	}
+	// Clear the row, another entry might have been drawn there while moving.
+	if err := cvs.SetAreaCells(image.Rect(0, y, width, y+1), ' '); err != nil {
+		return err
+	}
+	rank := fmt.Sprintf("%d.", i+1)
+	if err := text(rank, cols.rank-len(rank), width); err != nil {
+		return err
+	}
+	if cols.name > 0 {
+		if err := text(e.name, cols.rank+1, cols.rank+1+cols.name); err != nil {
+			return err
+		}
+	}
+
+	if cols.bar > 0 && cols.max > 0 {
+		cells := int(math.Round(math.Abs(e.value) / cols.max * float64(cols.bar)))
+		for x := cols.barStart; x < cols.barStart+cells; x++ {
+			if _, err := cvs.SetCell(image.Point{x, y}, ' ', lb.opts.barCellOpts...); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The values are aligned to the right edge.
+	vx := width - runewidth.StringWidth(value)
+	if vx < 0 {
+		return nil
+	}
+	return draw.Text(cvs, value, image.Point{vx, y},
+		draw.TextCellOpts(lb.opts.valueCellOpts...),
+	)
+}
+
+// Keyboard input isn't supported on the Leaderboard widget.
+func (*Leaderboard) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the Leaderboard widget doesn't support keyboard events")
+}
+
+// Mouse input isn't supported on the Leaderboard widget.
+func (*Leaderboard) Mouse(m *terminalapi.Mouse) error {
+	return errors.New("the Leaderboard widget doesn't support mouse events")
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*Leaderboard) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeNone,
+	}
+}
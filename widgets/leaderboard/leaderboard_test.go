@@ -0,0 +1,275 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderboard
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// barOpts are the default cell options of the bars.
+var barOpts = []cell.Option{cell.BgColor(cell.ColorGreen)}
+
+// mustRow draws a row with the rank, name, bar of the width starting at the
+// column and the value aligned to the right.
+func mustRow(cvs *canvas.Canvas, y int, rank, name string, barStart, bar int, value string) {
+	width := cvs.Area().Dx()
+	testcanvas.MustSetAreaCells(cvs, image.Rect(0, y, width, y+1), ' ')
+	testdraw.MustText(cvs, rank, image.Point{0, y})
+	testdraw.MustText(cvs, name, image.Point{len(rank) + 1, y})
+	for x := barStart; x < barStart+bar; x++ {
+		testcanvas.MustSetCell(cvs, image.Point{x, y}, ' ', barOpts...)
+	}
+	testdraw.MustText(cvs, value, image.Point{width - len(value), y})
+}
+
+// set is a call to Set after some time elapsed.
+type set struct {
+	elapsed time.Duration
+	name    string
+	value   float64
+}
+
+func TestLeaderboard(t *testing.T) {
+	tests := []struct {
+		desc       string
+		opts       []Option
+		sets       []set
+		remove     []string
+		size       image.Point
+		want       func(size image.Point) *faketerm.Terminal
+		wantNames  []string
+		wantNewErr bool
+		wantErr    bool
+	}{
+		{
+			desc:       "fails on zero TopN",
+			opts:       []Option{TopN(0)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on negative animation",
+			opts:       []Option{Animate(-1)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on nil ValueFormatter",
+			opts:       []Option{ValueFormatter(nil)},
+			wantNewErr: true,
+		},
+		{
+			desc: "fails on empty name",
+			sets: []set{
+				{name: "", value: 1},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on infinite value",
+			sets: []set{
+				{name: "a", value: math.Inf(1)},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "empty leaderboard",
+			size: image.Point{20, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
+		{
+			desc: "displays the TopN entries with the highest values",
+			opts: []Option{TopN(2)},
+			sets: []set{
+				{name: "a", value: 10},
+				{name: "b", value: 30},
+				{name: "c", value: 20},
+			},
+			size: image.Point{20, 3},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustRow(cvs, 0, "1.", "b", 5, 10, "30.0")
+				mustRow(cvs, 1, "2.", "c", 5, 7, "20.0")
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantNames: []string{"b", "c", "a"},
+		},
+		{
+			desc: "ranks again when a value changes",
+			sets: []set{
+				{name: "a", value: 10},
+				{name: "b", value: 20},
+				{name: "a", value: 40},
+			},
+			size: image.Point{20, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustRow(cvs, 0, "1.", "a", 5, 10, "40.0")
+				mustRow(cvs, 1, "2.", "b", 5, 5, "20.0")
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantNames: []string{"a", "b"},
+		},
+		{
+			desc: "ascending order and custom formatter",
+			opts: []Option{
+				Ascending(),
+				ValueFormatter(func(v float64) string {
+					return fmt.Sprintf("%.0fms", v)
+				}),
+			},
+			sets: []set{
+				{name: "a", value: 10},
+				{name: "b", value: 20},
+			},
+			remove: []string{"c"},
+			size:   image.Point{20, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustRow(cvs, 0, "1.", "a", 5, 5, "10ms")
+				mustRow(cvs, 1, "2.", "b", 5, 10, "20ms")
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantNames: []string{"a", "b"},
+		},
+		{
+			desc: "ties are ranked by name",
+			sets: []set{
+				{name: "b", value: 1},
+				{name: "a", value: 1},
+			},
+			wantNames: []string{"a", "b"},
+			size:      image.Point{20, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustRow(cvs, 0, "1.", "a", 5, 11, "1.0")
+				mustRow(cvs, 1, "2.", "b", 5, 11, "1.0")
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "removed entries are no longer displayed",
+			sets: []set{
+				{name: "a", value: 10},
+				{name: "b", value: 20},
+			},
+			remove: []string{"b"},
+			size:   image.Point{20, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustRow(cvs, 0, "1.", "a", 5, 10, "10.0")
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantNames: []string{"a"},
+		},
+		{
+			desc: "animates the rows that change rank",
+			opts: []Option{Animate(time.Second)},
+			sets: []set{
+				{name: "a", value: 20},
+				{name: "b", value: 10},
+				{elapsed: 2 * time.Second, name: "b", value: 40},
+				// The rows are a quarter of the way to their new ranks.
+				{elapsed: 250 * time.Millisecond, name: "c", value: 0},
+			},
+			size: image.Point{20, 3},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustRow(cvs, 0, "2.", "a", 5, 5, "20.0")
+				mustRow(cvs, 1, "1.", "b", 5, 10, "40.0")
+				// The new entry slides in from below the TopN rows, it isn't
+				// visible yet.
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantNames: []string{"b", "a", "c"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			timeNow = func() time.Time { return now }
+			defer func() { timeNow = time.Now }()
+
+			lb, err := New(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, s := range tc.sets {
+				now = now.Add(s.elapsed)
+				err := lb.Set(s.name, s.value)
+				if (err != nil) != tc.wantErr {
+					t.Errorf("Set => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+				}
+				if err != nil {
+					return
+				}
+			}
+			for _, n := range tc.remove {
+				lb.Remove(n)
+			}
+			if diff := pretty.Compare(tc.wantNames, lb.Names()); diff != "" {
+				t.Errorf("Names => unexpected diff (-want, +got):\n%s", diff)
+			}
+
+			c, err := canvas.New(image.Rectangle{Max: tc.size})
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := lb.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
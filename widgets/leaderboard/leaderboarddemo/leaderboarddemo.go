@@ -0,0 +1,104 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary leaderboarddemo displays the hosts with the highest simulated CPU
+// usage.
+// Exits when 'q' is pressed.
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/leaderboard"
+)
+
+// hosts is the number of simulated hosts.
+const hosts = 20
+
+// play changes the CPU usage of random hosts every 500ms. Exits when the
+// context expires.
+func play(ctx context.Context, lb *leaderboard.Leaderboard) {
+	usage := make([]float64, hosts)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		for i := 0; i < hosts/4; i++ {
+			h := rand.Intn(hosts)
+			usage[h] += float64(rand.Intn(41) - 20)
+			if usage[h] < 0 {
+				usage[h] = 0
+			} else if usage[h] > 100 {
+				usage[h] = 100
+			}
+			if err := lb.Set(fmt.Sprintf("host-%02d", h), usage[h]); err != nil {
+				panic(err)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lb, err := leaderboard.New(
+		leaderboard.TopN(8),
+		leaderboard.Animate(300*time.Millisecond),
+		leaderboard.ValueFormatter(func(v float64) string {
+			return fmt.Sprintf("%.0f%%", v)
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+	go play(ctx, lb)
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS Q TO QUIT"),
+		container.PlaceWidget(lb),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == 'q' || k.Key == 'Q' {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter), termdash.RedrawInterval(50*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderboard
+
+// options.go contains configurable options for Leaderboard.
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	topN           int
+	ascending      bool
+	animation      time.Duration
+	barCellOpts    []cell.Option
+	labelCellOpts  []cell.Option
+	valueCellOpts  []cell.Option
+	valueFormatter format.Formatter
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		topN: DefaultTopN,
+		barCellOpts: []cell.Option{
+			cell.BgColor(cell.ColorGreen),
+		},
+		valueFormatter: func(v float64) string {
+			return fmt.Sprintf("%.1f", v)
+		},
+	}
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if min := 1; o.topN < min {
+		return fmt.Errorf("invalid TopN(%d), must be at least %d", o.topN, min)
+	}
+	if o.animation < 0 {
+		return fmt.Errorf("invalid Animate(%v), must be zero or a positive duration", o.animation)
+	}
+	if o.valueFormatter == nil {
+		return fmt.Errorf("the ValueFormatter cannot be nil")
+	}
+	return nil
+}
+
+// DefaultTopN is the default value for the TopN option.
+const DefaultTopN = 10
+
+// TopN sets the number of entries with the highest values that are
+// displayed.
+// Defaults to DefaultTopN.
+func TopN(n int) Option {
+	return option(func(o *options) {
+		o.topN = n
+	})
+}
+
+// Ascending ranks the entries with the lowest values first, e.g. for a
+// leaderboard of the fastest endpoints.
+// Defaults to ranking the highest values first.
+func Ascending() Option {
+	return option(func(o *options) {
+		o.ascending = true
+	})
+}
+
+// Animate animates the rows of the entries that change their rank, each row
+// slides from its old position to the new one for the duration. The
+// animation is only visible if the dashboard is redrawn often enough, see
+// the termdash.RedrawInterval option.
+// Defaults to moving the rows immediately.
+func Animate(d time.Duration) Option {
+	return option(func(o *options) {
+		o.animation = d
+	})
+}
+
+// BarCellOpts sets the cell options of the bars.
+// Defaults to green background.
+func BarCellOpts(opts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.barCellOpts = opts
+	})
+}
+
+// LabelCellOpts sets the cell options of the ranks and names of the
+// entries.
+func LabelCellOpts(opts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.labelCellOpts = opts
+	})
+}
+
+// ValueCellOpts sets the cell options of the values of the entries.
+func ValueCellOpts(opts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.valueCellOpts = opts
+	})
+}
+
+// ValueFormatter sets the function that formats the values of the entries,
+// e.g. to append a unit or format.SI.
+// Defaults to formatting the values with one decimal place.
+func ValueFormatter(fn format.Formatter) Option {
+	return option(func(o *options) {
+		o.valueFormatter = fn
+	})
+}
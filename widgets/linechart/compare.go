@@ -0,0 +1,203 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linechart
+
+// compare.go contains the compare mode that overlays the series with their
+// baselines.
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/braille"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/widgets/linechart/internal/axes"
+)
+
+// DeltaStats compares the values of a series with its baseline.
+// Only the positions where both the series and the baseline have a value are
+// compared.
+type DeltaStats struct {
+	// Mean is the mean of the values of the series.
+	Mean float64
+	// BaselineMean is the mean of the values of the baseline.
+	BaselineMean float64
+	// Delta is the difference of the means, i.e. Mean - BaselineMean.
+	Delta float64
+	// Percent is the delta as a percentage of the mean of the baseline, NaN
+	// if the mean of the baseline is zero.
+	Percent float64
+}
+
+// String implements fmt.Stringer.
+func (ds DeltaStats) String() string {
+	if math.IsNaN(ds.Percent) {
+		return fmt.Sprintf("Δ%+.2f", ds.Delta)
+	}
+	return fmt.Sprintf("Δ%+.2f (%+.1f%%)", ds.Delta, ds.Percent)
+}
+
+// Baseline sets the baseline values of the series with the provided label,
+// e.g. the values of the same metric a day earlier. The baselines are
+// displayed behind the series with muted colors while the compare mode is
+// enabled, see Compare and CompareKey. The series and its baseline are
+// compared position by position, so the baseline should have the values at
+// the same positions on the X axis as the series.
+// The values that should not be displayed should be represented as math.NaN
+// values. Providing no values removes the baseline.
+func (lc *LineChart) Baseline(label string, values []float64) error {
+	if label == "" {
+		return errors.New("the label cannot be empty")
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if len(values) == 0 {
+		delete(lc.baselines, label)
+	} else {
+		lc.baselines[label] = newSeriesValues(values)
+	}
+	lc.yMin, lc.yMax = lc.yMinMax()
+	lc.markChanged()
+	return nil
+}
+
+// Compare enables or disables the compare mode. While enabled, the line
+// chart displays the baselines of the series and the statistics comparing
+// each series with its baseline, see Baseline.
+func (lc *LineChart) Compare(enabled bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.compare = enabled
+	lc.yMin, lc.yMax = lc.yMinMax()
+	lc.markChanged()
+}
+
+// Comparing asserts whether the compare mode is enabled.
+func (lc *LineChart) Comparing() bool {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	return lc.compare
+}
+
+// CompareStats returns the statistics comparing the series with the provided
+// label with its baseline. Returns false if the series or its baseline don't
+// exist or if they have no values at the same positions.
+func (lc *LineChart) CompareStats(label string) (DeltaStats, bool) {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	return lc.compareStats(label)
+}
+
+// compareStats implements CompareStats.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) compareStats(label string) (DeltaStats, bool) {
+	sv, ok := lc.series[label]
+	if !ok {
+		return DeltaStats{}, false
+	}
+	base, ok := lc.baselines[label]
+	if !ok {
+		return DeltaStats{}, false
+	}
+
+	var (
+		sum, baseSum float64
+		count        int
+	)
+	for i, v := range sv.values {
+		if i >= len(base.values) {
+			break
+		}
+		b := base.values[i]
+		if math.IsNaN(v) || math.IsNaN(b) {
+			continue
+		}
+		sum += v
+		baseSum += b
+		count++
+	}
+	if count == 0 {
+		return DeltaStats{}, false
+	}
+
+	ds := DeltaStats{
+		Mean:         sum / float64(count),
+		BaselineMean: baseSum / float64(count),
+	}
+	ds.Delta = ds.Mean - ds.BaselineMean
+	if ds.BaselineMean == 0 {
+		ds.Percent = math.NaN()
+	} else {
+		ds.Percent = ds.Delta / math.Abs(ds.BaselineMean) * 100
+	}
+	return ds, true
+}
+
+// baselineNames returns the sorted labels of the baselines to display.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) baselineNames() []string {
+	if !lc.compare {
+		return nil
+	}
+	var names []string
+	for name := range lc.baselines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// drawBaselines draws the baselines behind the series.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) drawBaselines(bc *braille.Canvas, xd *axes.XDetails, yd *axes.YDetails) error {
+	for _, name := range lc.baselineNames() {
+		if err := lc.drawValues(bc, xd, yd, name, lc.baselines[name].values, 0, draw.BrailleLineCellOpts(lc.opts.baselineCellOpts...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drawCompareStats draws the statistics comparing the series with their
+// baselines, one line per series, at the top of the graph.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) drawCompareStats(cvs *canvas.Canvas, graphAr image.Rectangle) error {
+	y := graphAr.Min.Y
+	for _, name := range lc.baselineNames() {
+		if y >= graphAr.Max.Y {
+			break
+		}
+		ds, ok := lc.compareStats(name)
+		if !ok {
+			continue
+		}
+		if err := draw.Text(cvs, fmt.Sprintf("%s %v", name, ds), image.Point{graphAr.Min.X, y},
+			draw.TextMaxX(graphAr.Max.X),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(lc.opts.compareStatsCellOpts...),
+		); err != nil {
+			return fmt.Errorf("failed to draw the compare statistics: %v", err)
+		}
+		y++
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linechart
+
+import (
+	"image"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+func TestCompareStats(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		desc     string
+		values   []float64
+		baseline []float64
+		want     DeltaStats
+		wantOK   bool
+	}{
+		{
+			desc:   "no baseline",
+			values: []float64{1, 2},
+		},
+		{
+			desc:     "no values at the same positions",
+			values:   []float64{1, nan},
+			baseline: []float64{nan, 2},
+		},
+		{
+			desc:     "compares the means",
+			values:   []float64{4, 8, nan, 100},
+			baseline: []float64{2, 6, 1},
+			want: DeltaStats{
+				Mean:         6,
+				BaselineMean: 4,
+				Delta:        2,
+				Percent:      50,
+			},
+			wantOK: true,
+		},
+		{
+			desc:     "negative baseline",
+			values:   []float64{-1},
+			baseline: []float64{-2},
+			want: DeltaStats{
+				Mean:         -1,
+				BaselineMean: -2,
+				Delta:        1,
+				Percent:      50,
+			},
+			wantOK: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			lc, err := New()
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := lc.Series("series", tc.values); err != nil {
+				t.Fatalf("Series => unexpected error: %v", err)
+			}
+			if err := lc.Baseline("series", tc.baseline); err != nil {
+				t.Fatalf("Baseline => unexpected error: %v", err)
+			}
+
+			got, ok := lc.CompareStats("series")
+			if ok != tc.wantOK {
+				t.Errorf("CompareStats => got ok %v, want %v", ok, tc.wantOK)
+			}
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("CompareStats => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDeltaStatsString(t *testing.T) {
+	tests := []struct {
+		desc string
+		ds   DeltaStats
+		want string
+	}{
+		{
+			desc: "increase",
+			ds:   DeltaStats{Delta: 2, Percent: 50},
+			want: "Δ+2.00 (+50.0%)",
+		},
+		{
+			desc: "decrease",
+			ds:   DeltaStats{Delta: -1.5, Percent: -12.25},
+			want: "Δ-1.50 (-12.2%)",
+		},
+		{
+			desc: "without percentage",
+			ds:   DeltaStats{Delta: 1, Percent: math.NaN()},
+			want: "Δ+1.00",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := tc.ds.String(); got != tc.want {
+				t.Errorf("String => %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// rowText returns the text on the row of the canvas.
+func rowText(cvs *canvas.Canvas, y int) string {
+	var b strings.Builder
+	for x := 0; x < cvs.Area().Max.X; x++ {
+		b.WriteRune(testcanvas.MustCell(cvs, image.Point{x, y}).Rune)
+	}
+	return b.String()
+}
+
+// countFgColor returns the number of cells of the graph with the foreground
+// color.
+func countFgColor(lc *LineChart, cvs *canvas.Canvas, color cell.Color) int {
+	var res int
+	for x := lc.lastGraphAr.Min.X; x < lc.lastGraphAr.Max.X; x++ {
+		for y := lc.lastGraphAr.Min.Y; y < lc.lastGraphAr.Max.Y; y++ {
+			if testcanvas.MustCell(cvs, image.Point{x, y}).Opts.FgColor == color {
+				res++
+			}
+		}
+	}
+	return res
+}
+
+func TestCompare(t *testing.T) {
+	t.Run("fails on an empty label", func(t *testing.T) {
+		lc, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := lc.Baseline("", []float64{1}); err == nil {
+			t.Errorf("Baseline => got nil error, want one for an empty label")
+		}
+	})
+
+	t.Run("displays the baselines in the compare mode", func(t *testing.T) {
+		lc, err := New(
+			CompareKey('c'),
+			CompareStatsCellOpts(cell.FgColor(cell.ColorYellow)),
+		)
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := lc.Series("series", []float64{4, 8}, SeriesCellOpts(cell.FgColor(cell.ColorBlue))); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		if err := lc.Baseline("series", []float64{2, 6, 20, 1}); err != nil {
+			t.Fatalf("Baseline => unexpected error: %v", err)
+		}
+
+		ar := image.Rect(0, 0, 40, 10)
+		baseline := cell.ColorNumber(DefaultBaselineColorNumber)
+		cvs := testcanvas.MustNew(ar)
+		if err := lc.Draw(cvs, &widgetapi.Meta{}); err != nil {
+			t.Fatalf("Draw => unexpected error: %v", err)
+		}
+		if got := countFgColor(lc, cvs, baseline); got != 0 {
+			t.Errorf("Draw => %d cells of the baseline, want none outside of the compare mode", got)
+		}
+		if got, want := lc.yMax, 8.0; got != want {
+			t.Errorf("yMax => %v, want %v", got, want)
+		}
+
+		if err := lc.Keyboard(&terminalapi.Keyboard{Key: 'c'}); err != nil {
+			t.Fatalf("Keyboard => unexpected error: %v", err)
+		}
+		if !lc.Comparing() {
+			t.Fatalf("Comparing => false, want true after the compare key")
+		}
+		if got, want := lc.yMax, 20.0; got != want {
+			t.Errorf("yMax => %v, want %v", got, want)
+		}
+		if got, want := lc.maxXValue(), 3; got != want {
+			t.Errorf("maxXValue => %v, want %v", got, want)
+		}
+
+		cvs = testcanvas.MustNew(ar)
+		if err := lc.Draw(cvs, &widgetapi.Meta{}); err != nil {
+			t.Fatalf("Draw => unexpected error: %v", err)
+		}
+		if got := countFgColor(lc, cvs, baseline); got == 0 {
+			t.Errorf("Draw => no cells of the baseline, want some in the compare mode")
+		}
+		if got := countFgColor(lc, cvs, cell.ColorBlue); got == 0 {
+			t.Errorf("Draw => no cells of the series, want some in the compare mode")
+		}
+		if got, want := rowText(cvs, lc.lastGraphAr.Min.Y), "series Δ+2.00 (+50.0%)"; !strings.Contains(got, want) {
+			t.Errorf("Draw => top row %q, want it to contain %q", got, want)
+		}
+
+		// Other keys are ignored.
+		if err := lc.Keyboard(&terminalapi.Keyboard{Key: keyboard.KeyEnter}); err != nil {
+			t.Fatalf("Keyboard => unexpected error: %v", err)
+		}
+		if !lc.Comparing() {
+			t.Errorf("Comparing => false, want true after an unrelated key")
+		}
+
+		lc.Compare(false)
+		if got, want := lc.yMax, 8.0; got != want {
+			t.Errorf("yMax => %v, want %v", got, want)
+		}
+	})
+
+	t.Run("removes the baseline", func(t *testing.T) {
+		lc, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		lc.Compare(true)
+		if err := lc.Baseline("series", []float64{10, 20}); err != nil {
+			t.Fatalf("Baseline => unexpected error: %v", err)
+		}
+		if got, want := lc.yMax, 20.0; got != want {
+			t.Errorf("yMax => %v, want %v", got, want)
+		}
+		if err := lc.Baseline("series", nil); err != nil {
+			t.Fatalf("Baseline => unexpected error: %v", err)
+		}
+		if got, want := lc.yMax, 0.0; got != want {
+			t.Errorf("yMax => %v, want %v", got, want)
+		}
+	})
+}
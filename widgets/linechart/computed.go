@@ -0,0 +1,254 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linechart
+
+// computed.go contains the support for series computed from other series.
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ComputeFn computes the values of a computed series from the values of its
+// source series. The sources are provided in the order they were specified
+// in the call to Computed, a source that doesn't exist has no values.
+// The function is called while the line chart is locked, so it must not call
+// the methods of the line chart. It must not modify the source values.
+type ComputeFn func(sources ...[]float64) []float64
+
+// computed is a series computed from other series.
+type computed struct {
+	// fn computes the values.
+	fn ComputeFn
+	// sources are the labels of the source series.
+	sources []string
+}
+
+// Computed displays a series whose values are computed from the values of
+// the source series with the provided label, e.g. the difference of two
+// series, see Difference, Ratio, Sum and MovingAverage. The values are
+// recomputed whenever any of the series change, so the computed series
+// follows its sources including streamed ones.
+// The sources can be other computed series, but only those registered
+// earlier. The SeriesXLabels option isn't supported.
+// Subsequent calls with the same label replace any previously provided
+// values, calling Series or Stream with the same label stops the computing.
+func (lc *LineChart) Computed(label string, fn ComputeFn, sources []string, opts ...SeriesOption) error {
+	if label == "" {
+		return errors.New("the label cannot be empty")
+	}
+	if fn == nil {
+		return errors.New("the compute function cannot be nil")
+	}
+	if len(sources) == 0 {
+		return errors.New("at least one source series must be specified")
+	}
+	for _, src := range sources {
+		if src == label {
+			return fmt.Errorf("the computed series %q cannot be its own source", label)
+		}
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	// Replacing a computed series keeps its place in the order of
+	// computing.
+	pos := len(lc.computedOrder)
+	for i, name := range lc.computedOrder {
+		if name == label {
+			pos = i
+		}
+	}
+	for _, name := range lc.computedOrder[:pos] {
+		for _, src := range lc.series[name].computed.sources {
+			if src == label {
+				return fmt.Errorf("the series %q is a source of the computed series %q registered earlier", label, name)
+			}
+		}
+	}
+	for _, name := range lc.computedOrder[pos:] {
+		for _, src := range sources {
+			if src == name && name != label {
+				return fmt.Errorf("the source %q is a computed series registered after %q", src, label)
+			}
+		}
+	}
+
+	sv := newSeriesValues(nil)
+	for _, opt := range opts {
+		opt.set(sv)
+	}
+	if sv.xLabelsSet {
+		return errors.New("the SeriesXLabels option isn't supported with a computed series")
+	}
+	sv.computed = &computed{
+		fn:      fn,
+		sources: append([]string(nil), sources...),
+	}
+	lc.setSeries(label, sv)
+	if pos < len(lc.computedOrder)-1 {
+		// Move the replaced series back to its place.
+		lc.computedOrder = append(lc.computedOrder[:pos], append([]string{label}, lc.computedOrder[pos:len(lc.computedOrder)-1]...)...)
+	}
+	lc.refreshComputed()
+	lc.yMin, lc.yMax = lc.yMinMax()
+	lc.markChanged()
+	return nil
+}
+
+// setSeries stores the series under the label and keeps track of the order
+// in which the computed series were registered.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) setSeries(label string, sv *seriesValues) {
+	if prev, ok := lc.series[label]; ok && prev.computed != nil {
+		for i, name := range lc.computedOrder {
+			if name == label {
+				lc.computedOrder = append(lc.computedOrder[:i], lc.computedOrder[i+1:]...)
+				break
+			}
+		}
+	}
+	if sv.computed != nil {
+		lc.computedOrder = append(lc.computedOrder, label)
+	}
+	lc.series[label] = sv
+}
+
+// refreshComputed recomputes the values of the computed series in the order
+// they were registered. Doesn't update the range of the Y axis.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) refreshComputed() {
+	for _, name := range lc.computedOrder {
+		sv := lc.series[name]
+		var sources [][]float64
+		for _, src := range sv.computed.sources {
+			var values []float64
+			if s, ok := lc.series[src]; ok {
+				values = s.values
+			}
+			sources = append(sources, values)
+		}
+		sv.setValues(sv.computed.fn(sources...))
+	}
+}
+
+// longest returns the length of the longest of the provided series.
+func longest(sources [][]float64) int {
+	var l int
+	for _, s := range sources {
+		if len(s) > l {
+			l = len(s)
+		}
+	}
+	return l
+}
+
+// valueAt returns the value at the index or NaN if the series doesn't have
+// it.
+func valueAt(values []float64, i int) float64 {
+	if i >= len(values) {
+		return math.NaN()
+	}
+	return values[i]
+}
+
+// Difference returns a ComputeFn that subtracts the values of the second and
+// any subsequent sources from the values of the first source, i.e. A - B.
+// The result is NaN where any of the sources is missing a value.
+func Difference() ComputeFn {
+	return func(sources ...[]float64) []float64 {
+		res := make([]float64, longest(sources))
+		for i := range res {
+			res[i] = valueAt(sources[0], i)
+			for _, s := range sources[1:] {
+				res[i] -= valueAt(s, i)
+			}
+		}
+		return res
+	}
+}
+
+// Sum returns a ComputeFn that adds up the values of all the sources.
+// The result is NaN where any of the sources is missing a value.
+func Sum() ComputeFn {
+	return func(sources ...[]float64) []float64 {
+		res := make([]float64, longest(sources))
+		for i := range res {
+			for _, s := range sources {
+				res[i] += valueAt(s, i)
+			}
+		}
+		return res
+	}
+}
+
+// Ratio returns a ComputeFn that divides the values of the first source by
+// the values of the second source, i.e. A / B.
+// The result is NaN where any of the sources is missing a value or where the
+// divisor is zero.
+func Ratio() ComputeFn {
+	return func(sources ...[]float64) []float64 {
+		res := make([]float64, longest(sources))
+		for i := range res {
+			var div float64
+			if len(sources) > 1 {
+				div = valueAt(sources[1], i)
+			}
+			if div == 0 {
+				res[i] = math.NaN()
+				continue
+			}
+			res[i] = valueAt(sources[0], i) / div
+		}
+		return res
+	}
+}
+
+// MovingAverage returns a ComputeFn that computes the moving average of the
+// values of the first source over the window of the specified number of
+// values ending at each of the values. Missing values aren't included in
+// the average, the result is NaN where the window has no values.
+// A window smaller than one is treated as one.
+func MovingAverage(window int) ComputeFn {
+	if window < 1 {
+		window = 1
+	}
+	return func(sources ...[]float64) []float64 {
+		values := sources[0]
+		res := make([]float64, len(values))
+		var (
+			sum   float64
+			count int
+		)
+		for i, v := range values {
+			if !math.IsNaN(v) {
+				sum += v
+				count++
+			}
+			if j := i - window; j >= 0 && !math.IsNaN(values[j]) {
+				sum -= values[j]
+				count--
+			}
+			if count == 0 {
+				res[i] = math.NaN()
+				continue
+			}
+			res[i] = sum / float64(count)
+		}
+		return res
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linechart
+
+import (
+	"image"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/series"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+func TestComputeFns(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		desc    string
+		fn      ComputeFn
+		sources [][]float64
+		want    []float64
+	}{
+		{
+			desc:    "difference of two series",
+			fn:      Difference(),
+			sources: [][]float64{{5, 4, 3}, {1, 1, 1}},
+			want:    []float64{4, 3, 2},
+		},
+		{
+			desc:    "difference of three series",
+			fn:      Difference(),
+			sources: [][]float64{{5, 4}, {1, 1}, {2, 2}},
+			want:    []float64{2, 1},
+		},
+		{
+			desc:    "difference with missing values",
+			fn:      Difference(),
+			sources: [][]float64{{5, nan, 3}, {1, 1}},
+			want:    []float64{4, nan, nan},
+		},
+		{
+			desc:    "difference of no values",
+			fn:      Difference(),
+			sources: [][]float64{nil, nil},
+			want:    []float64{},
+		},
+		{
+			desc:    "sum",
+			fn:      Sum(),
+			sources: [][]float64{{1, 2, 3}, {10, 20}},
+			want:    []float64{11, 22, nan},
+		},
+		{
+			desc:    "ratio",
+			fn:      Ratio(),
+			sources: [][]float64{{1, 2, 3, 4}, {2, 0, nan}},
+			want:    []float64{0.5, nan, nan, nan},
+		},
+		{
+			desc:    "ratio without divisor",
+			fn:      Ratio(),
+			sources: [][]float64{{1, 2}},
+			want:    []float64{nan, nan},
+		},
+		{
+			desc:    "moving average",
+			fn:      MovingAverage(2),
+			sources: [][]float64{{2, 4, 6, 8}},
+			want:    []float64{2, 3, 5, 7},
+		},
+		{
+			desc:    "moving average skips missing values",
+			fn:      MovingAverage(2),
+			sources: [][]float64{{2, nan, nan, 8, 10}},
+			want:    []float64{2, 2, nan, 8, 9},
+		},
+		{
+			desc:    "moving average with a window smaller than one",
+			fn:      MovingAverage(0),
+			sources: [][]float64{{2, 4}},
+			want:    []float64{2, 4},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := tc.fn(tc.sources...)
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("ComputeFn => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestComputed(t *testing.T) {
+	t.Run("fails on invalid arguments", func(t *testing.T) {
+		lc, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := lc.Computed("", Difference(), []string{"a", "b"}); err == nil {
+			t.Errorf("Computed => got nil error, want one for an empty label")
+		}
+		if err := lc.Computed("diff", nil, []string{"a", "b"}); err == nil {
+			t.Errorf("Computed => got nil error, want one for a nil function")
+		}
+		if err := lc.Computed("diff", Difference(), nil); err == nil {
+			t.Errorf("Computed => got nil error, want one without sources")
+		}
+		if err := lc.Computed("diff", Difference(), []string{"a", "diff"}); err == nil {
+			t.Errorf("Computed => got nil error, want one for a series that is its own source")
+		}
+		if err := lc.Computed("diff", Difference(), []string{"a", "b"}, SeriesXLabels(map[int]string{0: "a"})); err == nil {
+			t.Errorf("Computed => got nil error, want one for SeriesXLabels")
+		}
+	})
+
+	t.Run("fails on sources registered later", func(t *testing.T) {
+		lc, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := lc.Computed("first", Sum(), []string{"second"}); err != nil {
+			t.Fatalf("Computed => unexpected error: %v", err)
+		}
+		if err := lc.Computed("second", Sum(), []string{"a"}); err == nil {
+			t.Errorf("Computed => got nil error, want one for a source of an earlier computed series")
+		}
+		if err := lc.Computed("third", Sum(), []string{"a"}); err != nil {
+			t.Fatalf("Computed => unexpected error: %v", err)
+		}
+		if err := lc.Computed("first", Sum(), []string{"third"}); err == nil {
+			t.Errorf("Computed => got nil error, want one for a source registered later")
+		}
+	})
+
+	t.Run("follows the changes of the sources", func(t *testing.T) {
+		lc, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := lc.Series("a", []float64{5, 6, 7}); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		if err := lc.Computed("diff", Difference(), []string{"a", "b"}); err != nil {
+			t.Fatalf("Computed => unexpected error: %v", err)
+		}
+		if err := lc.Computed("avg", MovingAverage(2), []string{"diff"}); err != nil {
+			t.Fatalf("Computed => unexpected error: %v", err)
+		}
+		if got := lc.series["diff"].values; len(got) != 3 || !math.IsNaN(got[0]) {
+			t.Errorf("diff => %v, want NaN values without the second source", got)
+		}
+
+		if err := lc.Series("b", []float64{1, 2, 3}); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		want := map[string][]float64{
+			"diff": {4, 4, 4},
+			"avg":  {4, 4, 4},
+		}
+		for name, w := range want {
+			if diff := pretty.Compare(w, lc.series[name].values); diff != "" {
+				t.Errorf("%s => unexpected diff (-want, +got):\n%s", name, diff)
+			}
+		}
+		if got, want := lc.yMax, 7.0; got != want {
+			t.Errorf("yMax => %v, want %v", got, want)
+		}
+
+		// Replacing the first computed series keeps it computed first.
+		if err := lc.Computed("diff", Sum(), []string{"a", "b"}); err != nil {
+			t.Fatalf("Computed => unexpected error: %v", err)
+		}
+		if diff := pretty.Compare([]float64{6, 7, 9}, lc.series["avg"].values); diff != "" {
+			t.Errorf("avg => unexpected diff (-want, +got):\n%s", diff)
+		}
+
+		// Series stops the computing.
+		if err := lc.Series("diff", []float64{0, 1}); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		if err := lc.Series("a", []float64{100}); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		if diff := pretty.Compare([]float64{0, 1}, lc.series["diff"].values); diff != "" {
+			t.Errorf("diff => unexpected diff (-want, +got):\n%s", diff)
+		}
+		if diff := pretty.Compare([]float64{0, 0.5}, lc.series["avg"].values); diff != "" {
+			t.Errorf("avg => unexpected diff (-want, +got):\n%s", diff)
+		}
+	})
+
+	t.Run("follows streamed sources", func(t *testing.T) {
+		s, err := series.New(100)
+		if err != nil {
+			t.Fatalf("series.New => unexpected error: %v", err)
+		}
+		lc, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := lc.Stream("stream", s); err != nil {
+			t.Fatalf("Stream => unexpected error: %v", err)
+		}
+		if err := lc.Computed("double", Sum(), []string{"stream", "stream"}); err != nil {
+			t.Fatalf("Computed => unexpected error: %v", err)
+		}
+		start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 3; i++ {
+			s.Append(start.Add(time.Duration(i)*time.Second), float64(i))
+		}
+		if err := lc.Draw(testcanvas.MustNew(image.Rect(0, 0, 40, 10)), &widgetapi.Meta{}); err != nil {
+			t.Fatalf("Draw => unexpected error: %v", err)
+		}
+		if diff := pretty.Compare([]float64{0, 2, 4}, lc.series["double"].values); diff != "" {
+			t.Errorf("double => unexpected diff (-want, +got):\n%s", diff)
+		}
+	})
+
+	t.Run("draws the computed series", func(t *testing.T) {
+		lc, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := lc.Series("a", []float64{0, 10, 20, 30}); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		if err := lc.Series("b", []float64{0, 5, 5, 5}); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		if err := lc.Computed("diff", Difference(), []string{"a", "b"}, SeriesCellOpts(cell.FgColor(cell.ColorBlue))); err != nil {
+			t.Fatalf("Computed => unexpected error: %v", err)
+		}
+		ar := image.Rect(0, 0, 30, 10)
+		got := faketerm.MustNew(ar.Size())
+		cvs := testcanvas.MustNew(ar)
+		if err := lc.Draw(cvs, &widgetapi.Meta{}); err != nil {
+			t.Fatalf("Draw => unexpected error: %v", err)
+		}
+		testcanvas.MustApply(cvs, got)
+
+		mirror, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := mirror.Series("a", []float64{0, 10, 20, 30}); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		if err := mirror.Series("b", []float64{0, 5, 5, 5}); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		if err := mirror.Series("diff", []float64{0, 5, 15, 25}, SeriesCellOpts(cell.FgColor(cell.ColorBlue))); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		want := faketerm.MustNew(ar.Size())
+		cvs = testcanvas.MustNew(ar)
+		if err := mirror.Draw(cvs, &widgetapi.Meta{}); err != nil {
+			t.Fatalf("Draw => unexpected error: %v", err)
+		}
+		testcanvas.MustApply(cvs, want)
+
+		if diff := faketerm.Diff(want, got); diff != "" {
+			t.Errorf("Draw => %v", diff)
+		}
+	})
+}
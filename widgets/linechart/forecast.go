@@ -0,0 +1,174 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linechart
+
+// forecast.go contains the support for projecting the series beyond their
+// last value.
+
+import (
+	"math"
+
+	"github.com/mum4k/termdash/cell"
+)
+
+// ForecastFn projects the continuation of a series. Receives the values of
+// the series and returns the specified number of values that follow the last
+// value. Returning fewer values shortens the projection.
+// The function is called while the line chart is locked, so it must not call
+// the methods of the line chart. It must not modify the values.
+type ForecastFn func(values []float64, n int) []float64
+
+// forecast is the projection of a series.
+type forecast struct {
+	// fn computes the projection.
+	fn ForecastFn
+	// points is the number of projected values.
+	points int
+	// cellOpts are the cell options of the projection, defaults to the cell
+	// options of the series.
+	cellOpts []cell.Option
+	// values are the projected values.
+	values []float64
+}
+
+// SeriesForecast displays the projected continuation of the series beyond its
+// last value as a dashed line. The projection has the specified number of
+// values computed by the provided function, e.g. LinearForecast or
+// EWMAForecast. The values are projected again whenever the series change.
+// The X and Y axes are sized so that they accommodate the projected values.
+func SeriesForecast(fn ForecastFn, points int) SeriesOption {
+	return seriesOption(func(opts *seriesValues) {
+		if opts.forecast == nil {
+			opts.forecast = &forecast{}
+		}
+		opts.forecast.fn = fn
+		opts.forecast.points = points
+	})
+}
+
+// SeriesForecastCellOpts sets the cell options for the projection of the
+// series, see SeriesForecast.
+// Defaults to the cell options of the series.
+func SeriesForecastCellOpts(co ...cell.Option) SeriesOption {
+	return seriesOption(func(opts *seriesValues) {
+		if opts.forecast == nil {
+			opts.forecast = &forecast{}
+		}
+		opts.forecast.cellOpts = co
+	})
+}
+
+// updateForecast projects the values of the series and extends the min and
+// max of the series by the projected values.
+func (sv *seriesValues) updateForecast() {
+	f := sv.forecast
+	if f == nil || f.fn == nil || f.points <= 0 || len(sv.values) == 0 {
+		if f != nil {
+			f.values = nil
+		}
+		return
+	}
+
+	f.values = f.fn(sv.values, f.points)
+	if len(f.values) > f.points {
+		f.values = f.values[:f.points]
+	}
+	all := make([]float64, 0, len(sv.values)+len(f.values))
+	all = append(all, sv.values...)
+	sv.min, sv.max = minMax(append(all, f.values...))
+}
+
+// LinearForecast returns a ForecastFn that extrapolates the linear trend of
+// the specified number of last values, determined by the least squares
+// method. Missing values are skipped. The window must be at least two,
+// smaller windows are treated as two.
+func LinearForecast(window int) ForecastFn {
+	if window < 2 {
+		window = 2
+	}
+	return func(values []float64, n int) []float64 {
+		// Fit the line over the positions of the values.
+		var xs, ys []float64
+		for i := len(values) - 1; i >= 0 && len(xs) < window; i-- {
+			if v := values[i]; !math.IsNaN(v) {
+				xs = append(xs, float64(i))
+				ys = append(ys, v)
+			}
+		}
+		if len(xs) == 0 {
+			return nil
+		}
+
+		var sumX, sumY float64
+		for i := range xs {
+			sumX += xs[i]
+			sumY += ys[i]
+		}
+		meanX, meanY := sumX/float64(len(xs)), sumY/float64(len(ys))
+		var cov, variance float64
+		for i := range xs {
+			cov += (xs[i] - meanX) * (ys[i] - meanY)
+			variance += (xs[i] - meanX) * (xs[i] - meanX)
+		}
+		var slope float64
+		if variance != 0 {
+			slope = cov / variance
+		}
+
+		res := make([]float64, n)
+		for i := range res {
+			x := float64(len(values) + i)
+			res[i] = meanY + slope*(x-meanX)
+		}
+		return res
+	}
+}
+
+// EWMAForecast returns a ForecastFn that projects the exponentially weighted
+// moving average of the values, i.e. the projection is flat at the level of
+// the average. The alpha is the weight of each new value, values closer to
+// one make the average follow the latest values more closely. Missing values
+// are skipped. Alpha outside of the range 0 < alpha <= 1 is treated as one.
+func EWMAForecast(alpha float64) ForecastFn {
+	if alpha <= 0 || alpha > 1 {
+		alpha = 1
+	}
+	return func(values []float64, n int) []float64 {
+		var (
+			avg  float64
+			seen bool
+		)
+		for _, v := range values {
+			if math.IsNaN(v) {
+				continue
+			}
+			if !seen {
+				avg = v
+				seen = true
+				continue
+			}
+			avg = alpha*v + (1-alpha)*avg
+		}
+		if !seen {
+			return nil
+		}
+
+		res := make([]float64, n)
+		for i := range res {
+			res[i] = avg
+		}
+		return res
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linechart
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+func TestForecastFns(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		desc   string
+		fn     ForecastFn
+		values []float64
+		n      int
+		want   []float64
+	}{
+		{
+			desc:   "linear without values",
+			fn:     LinearForecast(3),
+			values: []float64{nan, nan},
+			n:      2,
+		},
+		{
+			desc:   "linear with a single value",
+			fn:     LinearForecast(3),
+			values: []float64{5},
+			n:      2,
+			want:   []float64{5, 5},
+		},
+		{
+			desc:   "linear continues the trend",
+			fn:     LinearForecast(3),
+			values: []float64{100, 0, 2, 4},
+			n:      3,
+			want:   []float64{6, 8, 10},
+		},
+		{
+			desc:   "linear skips missing values",
+			fn:     LinearForecast(2),
+			values: []float64{0, 2, nan},
+			n:      2,
+			want:   []float64{6, 8},
+		},
+		{
+			desc:   "linear window smaller than two",
+			fn:     LinearForecast(0),
+			values: []float64{1, 2},
+			n:      1,
+			want:   []float64{3},
+		},
+		{
+			desc:   "EWMA without values",
+			fn:     EWMAForecast(0.5),
+			values: []float64{nan},
+			n:      2,
+		},
+		{
+			desc:   "EWMA projects the average",
+			fn:     EWMAForecast(0.5),
+			values: []float64{4, nan, 8, 2},
+			n:      2,
+			want:   []float64{4, 4},
+		},
+		{
+			desc:   "EWMA with invalid alpha projects the last value",
+			fn:     EWMAForecast(2),
+			values: []float64{4, 8},
+			n:      1,
+			want:   []float64{8},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := tc.fn(tc.values, tc.n)
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("ForecastFn => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeriesForecast(t *testing.T) {
+	lc, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := lc.Series("series", []float64{0, 1, 2, 3},
+		SeriesCellOpts(cell.FgColor(cell.ColorBlue)),
+		SeriesForecast(LinearForecast(4), 4),
+		SeriesForecastCellOpts(cell.FgColor(cell.ColorRed)),
+	); err != nil {
+		t.Fatalf("Series => unexpected error: %v", err)
+	}
+
+	if got, want := lc.maxXValue(), 7; got != want {
+		t.Errorf("maxXValue => %d, want %d", got, want)
+	}
+	if got, want := lc.yMax, 7.0; got != want {
+		t.Errorf("yMax => %v, want %v", got, want)
+	}
+
+	ar := image.Rect(0, 0, 30, 10)
+	cvs := testcanvas.MustNew(ar)
+	if err := lc.Draw(cvs, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	// The series is drawn up to its last value and the projection beyond it.
+	last := mustColumn(t, lc, 3)
+	var blue, red, empty int
+	for x := lc.lastGraphAr.Min.X; x < lc.lastGraphAr.Max.X; x++ {
+		var drawn bool
+		for y := lc.lastGraphAr.Min.Y; y < lc.lastGraphAr.Max.Y; y++ {
+			c := testcanvas.MustCell(cvs, image.Point{x, y})
+			if c.Rune == 0 || c.Rune == ' ' {
+				continue
+			}
+			drawn = true
+			switch c.Opts.FgColor {
+			case cell.ColorBlue:
+				blue++
+				if x > last {
+					t.Errorf("cell %v => series drawn after its last value in column %d", image.Point{x, y}, last)
+				}
+			case cell.ColorRed:
+				red++
+				if x < last {
+					t.Errorf("cell %v => projection drawn before the last value in column %d", image.Point{x, y}, last)
+				}
+			}
+		}
+		if !drawn && x > last {
+			empty++
+		}
+	}
+	if blue == 0 || red == 0 {
+		t.Errorf("Draw => %d cells of the series and %d of the projection, want both drawn", blue, red)
+	}
+	if empty == 0 {
+		t.Errorf("Draw => the projection isn't dashed, want empty columns between the dashes")
+	}
+
+	// The projection follows the changes of the series.
+	if err := lc.Series("series", []float64{3, 2, 1, 0}, SeriesForecast(LinearForecast(4), 1)); err != nil {
+		t.Fatalf("Series => unexpected error: %v", err)
+	}
+	if got, want := lc.yMin, -1.0; got != want {
+		t.Errorf("yMin => %v, want %v", got, want)
+	}
+}
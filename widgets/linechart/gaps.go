@@ -0,0 +1,156 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linechart
+
+// gaps.go contains the handling of missing values.
+
+import (
+	"math"
+	"time"
+
+	"github.com/mum4k/termdash/series"
+)
+
+// GapMode determines how the missing values of a series are displayed.
+type GapMode int
+
+// String implements fmt.Stringer()
+func (gm GapMode) String() string {
+	if n, ok := gapModeNames[gm]; ok {
+		return n
+	}
+	return "GapModeUnknown"
+}
+
+// gapModeNames maps GapMode values to human readable names.
+var gapModeNames = map[GapMode]string{
+	GapModeBreak:       "GapModeBreak",
+	GapModeInterpolate: "GapModeInterpolate",
+	GapModeZero:        "GapModeZero",
+}
+
+const (
+	// GapModeBreak leaves the missing values out, the line is interrupted
+	// where values are missing.
+	GapModeBreak GapMode = iota
+
+	// GapModeInterpolate replaces the missing values with values linearly
+	// interpolated between the surrounding values. Missing values at the
+	// start and at the end of the series stay missing.
+	GapModeInterpolate
+
+	// GapModeZero replaces the missing values with zeroes.
+	GapModeZero
+)
+
+// SeriesGapMode determines how the missing values of the series, i.e. the
+// math.NaN values, are displayed.
+// Defaults to GapModeBreak.
+func SeriesGapMode(gm GapMode) SeriesOption {
+	return seriesOption(func(opts *seriesValues) {
+		opts.gapMode = gm
+	})
+}
+
+// SeriesMaxInterval marks outages of a streamed series, see Stream. Values
+// that were appended more than the specified duration after the previous
+// value are treated as if a missing value separated them, so the outage is
+// displayed according to SeriesGapMode instead of the line connecting the
+// values across it.
+// Has no effect on series that aren't streamed. Zero disables the detection
+// of outages, which is the default.
+func SeriesMaxInterval(d time.Duration) SeriesOption {
+	return seriesOption(func(opts *seriesValues) {
+		opts.maxInterval = d
+	})
+}
+
+// setValues sets the values of the series with the gaps filled according to
+// the gap mode and updates the min, max and the forecast of the series.
+func (sv *seriesValues) setValues(values []float64) {
+	sv.values = fillGaps(values, sv.gapMode)
+	sv.min, sv.max = minMax(sv.values)
+	sv.updateForecast()
+}
+
+// fillGaps returns the values with the missing values replaced according to
+// the gap mode. Doesn't modify the provided values.
+func fillGaps(values []float64, gm GapMode) []float64 {
+	switch gm {
+	case GapModeInterpolate:
+		res := make([]float64, len(values))
+		copy(res, values)
+		prev := -1 // Position of the last value that isn't missing.
+		for i, v := range res {
+			if math.IsNaN(v) {
+				continue
+			}
+			if prev >= 0 && i-prev > 1 {
+				step := (v - res[prev]) / float64(i-prev)
+				for j := prev + 1; j < i; j++ {
+					res[j] = res[prev] + step*float64(j-prev)
+				}
+			}
+			prev = i
+		}
+		return res
+
+	case GapModeZero:
+		res := make([]float64, len(values))
+		for i, v := range values {
+			if !math.IsNaN(v) {
+				res[i] = v
+			}
+		}
+		return res
+
+	default:
+		return values
+	}
+}
+
+// downsample downsamples the streamed series to the threshold. If the series
+// has a maximum interval, a missing value is inserted between the downsampled
+// values that have an outage between them.
+func (sv *seriesValues) downsample(threshold int) []float64 {
+	if sv.maxInterval <= 0 {
+		return series.Values(sv.stream.Downsample(threshold))
+	}
+
+	points := sv.stream.Points()
+	// outages are the times of the values that follow an outage.
+	var outages []time.Time
+	for i := 1; i < len(points); i++ {
+		if points[i].Time.Sub(points[i-1].Time) > sv.maxInterval {
+			outages = append(outages, points[i].Time)
+		}
+	}
+
+	var res []float64
+	for _, p := range series.LTTB(points, threshold) {
+		// The downsampled values are a subset of the points, so each outage
+		// ends at or before the first downsampled value after it.
+		var outage bool
+		for len(outages) > 0 && !outages[0].After(p.Time) {
+			outages = outages[1:]
+			outage = true
+		}
+		if outage && len(res) > 0 {
+			res = append(res, math.NaN())
+		}
+		res = append(res, p.Value)
+	}
+	return res
+}
@@ -0,0 +1,178 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linechart
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/series"
+)
+
+func TestFillGaps(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		desc   string
+		values []float64
+		gm     GapMode
+		want   []float64
+	}{
+		{
+			desc:   "break leaves the missing values",
+			values: []float64{1, nan, 3},
+			gm:     GapModeBreak,
+			want:   []float64{1, nan, 3},
+		},
+		{
+			desc:   "interpolates between the surrounding values",
+			values: []float64{nan, 1, nan, nan, 4, 5, nan},
+			gm:     GapModeInterpolate,
+			want:   []float64{nan, 1, 2, 3, 4, 5, nan},
+		},
+		{
+			desc:   "interpolation without values",
+			values: []float64{nan, nan},
+			gm:     GapModeInterpolate,
+			want:   []float64{nan, nan},
+		},
+		{
+			desc:   "replaces the missing values with zeroes",
+			values: []float64{nan, 2, nan},
+			gm:     GapModeZero,
+			want:   []float64{0, 2, 0},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := fillGaps(tc.values, tc.gm)
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("fillGaps => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeriesGapMode(t *testing.T) {
+	nan := math.NaN()
+	lc, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	values := []float64{5, nan, 10}
+	if err := lc.Series("series", values, SeriesGapMode(GapModeZero)); err != nil {
+		t.Fatalf("Series => unexpected error: %v", err)
+	}
+	if got, want := lc.yMin, 0.0; got != want {
+		t.Errorf("yMin => %v, want %v", got, want)
+	}
+	if !math.IsNaN(values[1]) {
+		t.Errorf("Series => modified the provided values %v", values)
+	}
+
+	if err := lc.Series("series", values, SeriesGapMode(GapModeInterpolate)); err != nil {
+		t.Fatalf("Series => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare([]float64{5, 7.5, 10}, lc.series["series"].values); diff != "" {
+		t.Errorf("Series => unexpected diff (-want, +got):\n%s", diff)
+	}
+	if got, want := lc.yMin, 5.0; got != want {
+		t.Errorf("yMin => %v, want %v", got, want)
+	}
+}
+
+func TestSeriesMaxInterval(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newStream := func(t *testing.T) *series.Series {
+		t.Helper()
+		s, err := series.New(100)
+		if err != nil {
+			t.Fatalf("series.New => unexpected error: %v", err)
+		}
+		// An outage between the second and the third value.
+		for i, sec := range []int{0, 1, 10, 11, 12} {
+			s.Append(start.Add(time.Duration(sec)*time.Second), float64(i))
+		}
+		return s
+	}
+
+	tests := []struct {
+		desc string
+		opts []SeriesOption
+		want []float64
+	}{
+		{
+			desc: "connects the values across outages by default",
+			want: []float64{0, 1, 2, 3, 4},
+		},
+		{
+			desc: "inserts a missing value at the outage",
+			opts: []SeriesOption{SeriesMaxInterval(5 * time.Second)},
+			want: []float64{0, 1, math.NaN(), 2, 3, 4},
+		},
+		{
+			desc: "fills the outage according to the gap mode",
+			opts: []SeriesOption{
+				SeriesMaxInterval(5 * time.Second),
+				SeriesGapMode(GapModeZero),
+			},
+			want: []float64{0, 1, 0, 2, 3, 4},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			lc, err := New()
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := lc.Stream("stream", newStream(t), tc.opts...); err != nil {
+				t.Fatalf("Stream => unexpected error: %v", err)
+			}
+			if diff := pretty.Compare(tc.want, lc.series["stream"].values); diff != "" {
+				t.Errorf("Stream => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("keeps the outage when downsampling", func(t *testing.T) {
+		s, err := series.New(100)
+		if err != nil {
+			t.Fatalf("series.New => unexpected error: %v", err)
+		}
+		for i := 0; i < 50; i++ {
+			sec := i
+			if i >= 25 {
+				sec += 100
+			}
+			s.Append(start.Add(time.Duration(sec)*time.Second), 1)
+		}
+		sv := newSeriesValues(nil)
+		sv.stream = s
+		sv.maxInterval = time.Minute
+
+		var gaps int
+		for _, v := range sv.downsample(10) {
+			if math.IsNaN(v) {
+				gaps++
+			}
+		}
+		if gaps != 1 {
+			t.Errorf("downsample => got %d missing values, want 1", gaps)
+		}
+	})
+}
@@ -176,6 +176,15 @@ type XProperties struct {
 	CustomLabels map[int]string
 	// LO is the desired orientation of labels under the X axis.
 	LO LabelOrientation
+	// ValueFormatter is the formatter used to format the positions on the
+	// axis to their labels. The CustomLabels are preferred if provided.
+	ValueFormatter func(float64) string
+	// LabelEvery when larger than one, only the positions that are multiples
+	// of it are labeled.
+	LabelEvery int
+	// MaxLabelWidth when positive, is the maximum width of the labels in
+	// cells, longer labels are trimmed.
+	MaxLabelWidth int
 }
 
 // NewXDetails retrieves details about the X axis required to draw it on a canvas
@@ -187,7 +196,7 @@ type XProperties struct {
 func NewXDetails(cvsAr image.Rectangle, xp *XProperties) (*XDetails, error) {
 	cvsHeight := cvsAr.Dy()
 	maxHeight := cvsHeight - 1 // Reserve one row for the line chart itself.
-	reqHeight := RequiredHeight(xp.Max, xp.CustomLabels, xp.LO)
+	reqHeight := RequiredHeight(xp)
 	if maxHeight < reqHeight {
 		return nil, fmt.Errorf("the available maxHeight %d is smaller than the reported required height %d", maxHeight, reqHeight)
 	}
@@ -205,7 +214,7 @@ func NewXDetails(cvsAr image.Rectangle, xp *XProperties) (*XDetails, error) {
 		xp.ReqYWidth + 1,
 		cvsAr.Dy() - reqHeight - 1,
 	}
-	labels, err := xLabels(scale, graphZero, xp.CustomLabels, xp.LO)
+	labels, err := xLabels(scale, graphZero, xp)
 	if err != nil {
 		return nil, err
 	}
@@ -220,21 +229,76 @@ func NewXDetails(cvsAr image.Rectangle, xp *XProperties) (*XDetails, error) {
 }
 
 // RequiredHeight calculates the minimum height required in order to draw the X
-// axis and its labels.
-func RequiredHeight(max int, customLabels map[int]string, lo LabelOrientation) int {
-	if lo == LabelOrientationHorizontal {
+// axis and its labels. Only the Max, CustomLabels, LO, ValueFormatter and
+// MaxLabelWidth properties are used.
+func RequiredHeight(xp *XProperties) int {
+	if xp.LO != LabelOrientationVertical {
 		// One row for the X axis and one row for its labels flowing
 		// horizontally.
 		return axisWidth + 1
 	}
 
-	labels := []*Label{
-		{Value: NewValue(float64(max), nonZeroDecimals)},
+	var values []*Value
+	if xp.ValueFormatter == nil {
+		values = append(values, NewValue(float64(xp.Max), nonZeroDecimals))
+	} else {
+		// Formatted labels don't necessarily grow with the position.
+		for i := 0; i <= xp.Max; i++ {
+			values = append(values, NewValue(float64(i), nonZeroDecimals))
+		}
 	}
-	for _, cl := range customLabels {
-		labels = append(labels, &Label{
-			Value: NewTextValue(cl),
-		})
+	for pos := range xp.CustomLabels {
+		values = append(values, NewValue(float64(pos), nonZeroDecimals))
+	}
+
+	var labels []*Label
+	for _, v := range values {
+		// Errors are only returned when trimming to a non-positive width,
+		// which isn't attempted.
+		lv, _ := xp.labelValue(v)
+		labels = append(labels, &Label{Value: lv})
 	}
 	return longestLabel(labels) + axisWidth
 }
+
+// AutoOrientation resolves LabelOrientationAuto to the orientation of the
+// labels on the X axis when drawn on a canvas of the provided area. The
+// labels flow vertically if the horizontal labels would collide, i.e. fewer
+// positions would be labeled than with vertical labels, and if the canvas is
+// tall enough for the vertical labels.
+// Returns the orientation of the properties if it isn't LabelOrientationAuto.
+func AutoOrientation(cvsAr image.Rectangle, xp *XProperties) (LabelOrientation, error) {
+	if xp.LO != LabelOrientationAuto {
+		return xp.LO, nil
+	}
+
+	vp := *xp // Shallow copy.
+	vp.LO = LabelOrientationVertical
+	// One row is reserved for the line chart itself.
+	if RequiredHeight(&vp) >= cvsAr.Dy()-1 {
+		return LabelOrientationHorizontal, nil
+	}
+	graphWidth := cvsAr.Dx() - xp.ReqYWidth - 1
+	if graphWidth < 1 {
+		return LabelOrientationHorizontal, nil
+	}
+	scale, err := NewXScale(xp.Min, xp.Max, graphWidth, nonZeroDecimals)
+	if err != nil {
+		return 0, err
+	}
+
+	vertical, err := xLabels(scale, image.Point{}, &vp)
+	if err != nil {
+		return 0, err
+	}
+	hp := *xp
+	hp.LO = LabelOrientationHorizontal
+	horizontal, err := xLabels(scale, image.Point{}, &hp)
+	if err != nil {
+		return 0, err
+	}
+	if len(horizontal) < len(vertical) {
+		return LabelOrientationVertical, nil
+	}
+	return LabelOrientationHorizontal, nil
+}
@@ -483,6 +483,8 @@ func TestRequiredHeight(t *testing.T) {
 		max              int
 		customLabels     map[int]string
 		labelOrientation LabelOrientation
+		valueFormatter   func(float64) string
+		maxLabelWidth    int
 		want             int
 	}{
 		{
@@ -515,14 +517,113 @@ func TestRequiredHeight(t *testing.T) {
 			labelOrientation: LabelOrientationVertical,
 			want:             6,
 		},
+		{
+			desc:             "vertical orientation, formatted labels longer than max label",
+			max:              3,
+			labelOrientation: LabelOrientationVertical,
+			valueFormatter: func(v float64) string {
+				if v == 1 {
+					return "long label"
+				}
+				return "x"
+			},
+			want: 11,
+		},
+		{
+			desc:             "vertical orientation, custom labels preferred over formatted labels",
+			max:              3,
+			customLabels:     map[int]string{0: "ccc"},
+			labelOrientation: LabelOrientationVertical,
+			valueFormatter:   func(float64) string { return "x" },
+			want:             4,
+		},
+		{
+			desc:             "vertical orientation, long labels trimmed",
+			max:              3,
+			customLabels:     map[int]string{0: "a very long label"},
+			labelOrientation: LabelOrientationVertical,
+			maxLabelWidth:    5,
+			want:             6,
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.desc, func(t *testing.T) {
-			got := RequiredHeight(tc.max, tc.customLabels, tc.labelOrientation)
+			got := RequiredHeight(&XProperties{
+				Max:            tc.max,
+				CustomLabels:   tc.customLabels,
+				LO:             tc.labelOrientation,
+				ValueFormatter: tc.valueFormatter,
+				MaxLabelWidth:  tc.maxLabelWidth,
+			})
 			if got != tc.want {
 				t.Errorf("RequiredHeight => %d, want %d", got, tc.want)
 			}
 		})
 	}
 }
+
+func TestAutoOrientation(t *testing.T) {
+	tests := []struct {
+		desc    string
+		cvsAr   image.Rectangle
+		xp      *XProperties
+		want    LabelOrientation
+		wantErr bool
+	}{
+		{
+			desc:  "returns orientations other than auto",
+			cvsAr: image.Rect(0, 0, 20, 20),
+			xp:    &XProperties{Max: 10, LO: LabelOrientationVertical},
+			want:  LabelOrientationVertical,
+		},
+		{
+			desc:  "horizontal when the labels don't collide",
+			cvsAr: image.Rect(0, 0, 20, 20),
+			xp: &XProperties{
+				Max:          1,
+				ReqYWidth:    2,
+				CustomLabels: map[int]string{0: "a", 1: "b"},
+				LO:           LabelOrientationAuto,
+			},
+			want: LabelOrientationHorizontal,
+		},
+		{
+			desc:  "vertical when the labels collide",
+			cvsAr: image.Rect(0, 0, 20, 20),
+			xp: &XProperties{
+				Max:          1,
+				ReqYWidth:    2,
+				CustomLabels: map[int]string{0: "fifteen letters", 1: "b"},
+				LO:           LabelOrientationAuto,
+			},
+			want: LabelOrientationVertical,
+		},
+		{
+			desc:  "horizontal when the canvas is too short for vertical labels",
+			cvsAr: image.Rect(0, 0, 20, 10),
+			xp: &XProperties{
+				Max:          1,
+				ReqYWidth:    2,
+				CustomLabels: map[int]string{0: "a long label", 1: "b"},
+				LO:           LabelOrientationAuto,
+			},
+			want: LabelOrientationHorizontal,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := AutoOrientation(tc.cvsAr, tc.xp)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("AutoOrientation => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got != tc.want {
+				t.Errorf("AutoOrientation => %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
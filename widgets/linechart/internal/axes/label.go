@@ -22,6 +22,8 @@ import (
 
 	"github.com/mum4k/termdash/align"
 	"github.com/mum4k/termdash/private/alignfor"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
 )
 
 // LabelOrientation represents the orientation of text labels.
@@ -39,6 +41,7 @@ func (lo LabelOrientation) String() string {
 var labelOrientationNames = map[LabelOrientation]string{
 	LabelOrientationHorizontal: "LabelOrientationHorizontal",
 	LabelOrientationVertical:   "LabelOrientationVertical",
+	LabelOrientationAuto:       "LabelOrientationAuto",
 }
 
 const (
@@ -48,6 +51,11 @@ const (
 
 	// LabelOrientationVertical is an orientation where text flows vertically.
 	LabelOrientationVertical
+
+	// LabelOrientationAuto is an orientation where text flows horizontally,
+	// unless the labels would collide, then it flows vertically. Must be
+	// resolved with AutoOrientation before computing the layout.
+	LabelOrientationAuto
 )
 
 // Label is one value label on an axis.
@@ -187,16 +195,21 @@ func (xs *xSpace) Sub(size int) error {
 // Labels are returned in an increasing value order.
 // Returned labels shouldn't be trimmed, their count is adjusted so that they
 // fit under the width of the axis.
-// The customLabels map value positions in the series to the desired custom
-// label. These are preferred if present.
-func xLabels(scale *XScale, graphZero image.Point, customLabels map[int]string, lo LabelOrientation) ([]*Label, error) {
+// The custom labels of the properties map value positions in the series to
+// the desired custom label. These are preferred if present. The value
+// formatter formats the other positions, nil if they are displayed as
+// numbers.
+func xLabels(scale *XScale, graphZero image.Point, xp *XProperties) ([]*Label, error) {
+	if xp.LabelEvery > 1 {
+		return xLabelsEvery(scale, graphZero, xp)
+	}
+
 	space := newXSpace(graphZero, scale.GraphWidth)
-	const minSpacing = 3
 	var res []*Label
 
 	next := int(scale.Min.Value)
 	for haveLabels := 0; haveLabels <= int(scale.Max.Value); haveLabels = len(res) {
-		label, err := colLabel(scale, space, customLabels, lo)
+		label, err := colLabel(scale, space, xp, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -215,8 +228,8 @@ func xLabels(scale *XScale, graphZero image.Point, customLabels map[int]string,
 		}
 
 		skip := nextCell - space.Relative().X
-		if skip < minSpacing {
-			skip = minSpacing
+		if skip < minLabelSpacing {
+			skip = minLabelSpacing
 		}
 
 		if space.Remaining() <= skip {
@@ -229,26 +242,74 @@ func xLabels(scale *XScale, graphZero image.Point, customLabels map[int]string,
 	return res, nil
 }
 
-// colLabel returns a label placed at the beginning of the space.
-// The space is adjusted according to how much space was taken by the label.
-// Returns nil, nil if the label doesn't fit in the space.
-func colLabel(scale *XScale, space *xSpace, customLabels map[int]string, lo LabelOrientation) (*Label, error) {
-	pos := space.Relative()
-	label, err := scale.CellLabel(pos.X)
-	if err != nil {
-		return nil, fmt.Errorf("unable to determine label value for column %d: %v", pos.X, err)
+// minLabelSpacing is the minimum number of cells between two labels on the
+// X axis.
+const minLabelSpacing = 3
+
+// xLabelsEvery returns labels that should be placed under the X axis at the
+// positions that are multiples of the LabelEvery property. Positions whose
+// labels would collide with the previous label are skipped.
+func xLabelsEvery(scale *XScale, graphZero image.Point, xp *XProperties) ([]*Label, error) {
+	space := newXSpace(graphZero, scale.GraphWidth)
+	var res []*Label
+
+	every := xp.LabelEvery
+	first := (int(scale.Min.Value) + every - 1) / every * every
+	for pos := first; pos <= int(scale.Max.Value); pos += every {
+		cell, err := scale.ValueToCell(pos)
+		if err != nil {
+			return nil, err
+		}
+
+		skip := cell - space.Relative().X
+		if len(res) > 0 && skip < minLabelSpacing {
+			continue
+		}
+		if skip < 0 || space.Remaining() <= skip {
+			continue
+		}
+		if err := space.Sub(skip); err != nil {
+			return nil, err
+		}
+
+		label, err := colLabel(scale, space, xp, NewValue(float64(pos), scale.Min.NonZeroDecimals))
+		if err != nil {
+			return nil, err
+		}
+		if label == nil {
+			break
+		}
+		res = append(res, label)
 	}
+	return res, nil
+}
 
-	if custom, ok := customLabels[int(label.Value)]; ok {
-		label = NewTextValue(custom)
+// colLabel returns a label placed at the beginning of the space.
+// The label displays the provided value or the value of the column if nil.
+// Returns nil if the label doesn't fit into the remaining space.
+func colLabel(scale *XScale, space *xSpace, xp *XProperties, value *Value) (*Label, error) {
+	label := value
+	if label == nil {
+		pos := space.Relative()
+		v, err := scale.CellLabel(pos.X)
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine label value for column %d: %v", pos.X, err)
+		}
+		label = v
+	}
+	label, err := xp.labelValue(label)
+	if err != nil {
+		return nil, err
 	}
 
 	var labelLen int
-	switch lo {
+	switch xp.LO {
 	case LabelOrientationHorizontal:
-		labelLen = len(label.Text())
+		labelLen = runewidth.StringWidth(label.Text())
 	case LabelOrientationVertical:
 		labelLen = 1
+	default:
+		return nil, fmt.Errorf("unsupported label orientation %v", xp.LO)
 	}
 	if labelLen > space.Remaining() {
 		return nil, nil
@@ -264,3 +325,23 @@ func colLabel(scale *XScale, space *xSpace, customLabels map[int]string, lo Labe
 		Pos:   abs,
 	}, nil
 }
+
+// labelValue returns the value displayed as the label of the position on the
+// X axis represented by the value. Prefers the custom labels over the
+// formatted value and trims the text to the maximum width of labels.
+func (xp *XProperties) labelValue(v *Value) (*Value, error) {
+	if custom, ok := xp.CustomLabels[int(v.Value)]; ok {
+		v = NewTextValue(custom)
+	} else if xp.ValueFormatter != nil {
+		v = NewValue(v.Value, v.NonZeroDecimals, ValueFormatter(xp.ValueFormatter))
+	}
+
+	if max := xp.MaxLabelWidth; max > 0 && runewidth.StringWidth(v.Text()) > max {
+		trimmed, err := draw.TrimText(v.Text(), max, draw.OverrunModeThreeDot)
+		if err != nil {
+			return nil, err
+		}
+		v = NewTextValue(trimmed)
+	}
+	return v, nil
+}
@@ -15,6 +15,7 @@
 package axes
 
 import (
+	"fmt"
 	"image"
 	"testing"
 
@@ -458,7 +459,10 @@ func TestXLabels(t *testing.T) {
 				t.Fatalf("NewXScale => unexpected error: %v", err)
 			}
 			t.Logf("scale step: %v, label orientation: %v", scale.Step.Rounded, tc.labelOrientation)
-			got, err := xLabels(scale, tc.graphZero, tc.customLabels, tc.labelOrientation)
+			got, err := xLabels(scale, tc.graphZero, &XProperties{
+				CustomLabels: tc.customLabels,
+				LO:           tc.labelOrientation,
+			})
 			if (err != nil) != tc.wantErr {
 				t.Errorf("xLabels => unexpected error: %v, wantErr: %v", err, tc.wantErr)
 			}
@@ -472,6 +476,87 @@ func TestXLabels(t *testing.T) {
 	}
 }
 
+func TestXLabelsFormatted(t *testing.T) {
+	scale, err := NewXScale(0, 3, 40, 2)
+	if err != nil {
+		t.Fatalf("NewXScale => unexpected error: %v", err)
+	}
+	got, err := xLabels(scale, image.Point{0, 1}, &XProperties{
+		CustomLabels: map[int]string{2: "custom"},
+		ValueFormatter: func(v float64) string {
+			return fmt.Sprintf("#%v", v)
+		},
+	})
+	if err != nil {
+		t.Fatalf("xLabels => unexpected error: %v", err)
+	}
+
+	var texts []string
+	for _, l := range got {
+		texts = append(texts, l.Value.Text())
+	}
+	want := []string{"#0", "#1", "custom"}
+	if diff := pretty.Compare(want, texts); diff != "" {
+		t.Errorf("xLabels => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestXLabelsDensityAndWidth(t *testing.T) {
+	tests := []struct {
+		desc       string
+		max        int
+		graphWidth int
+		xp         *XProperties
+		want       []string
+	}{
+		{
+			desc:       "labels every nth position",
+			max:        20,
+			graphWidth: 40,
+			xp:         &XProperties{LabelEvery: 5},
+			want:       []string{"0", "5", "10", "15", "20"},
+		},
+		{
+			desc:       "skips positions whose labels would collide",
+			max:        20,
+			graphWidth: 10,
+			xp:         &XProperties{LabelEvery: 2},
+			want:       []string{"0", "8", "18"},
+		},
+		{
+			desc:       "trims long labels",
+			max:        2,
+			graphWidth: 20,
+			xp: &XProperties{
+				CustomLabels:  map[int]string{0: "a very long label", 1: "short"},
+				MaxLabelWidth: 5,
+			},
+			want: []string{"a ve…", "short", "2"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			scale, err := NewXScale(0, tc.max, tc.graphWidth, 2)
+			if err != nil {
+				t.Fatalf("NewXScale => unexpected error: %v", err)
+			}
+			got, err := xLabels(scale, image.Point{0, 1}, tc.xp)
+			if err != nil {
+				t.Fatalf("xLabels => unexpected error: %v", err)
+			}
+
+			var texts []string
+			for _, l := range got {
+				texts = append(texts, l.Value.Text())
+			}
+			if diff := pretty.Compare(tc.want, texts); diff != "" {
+				t.Errorf("xLabels => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestXSpace(t *testing.T) {
 	tests := []struct {
 		desc          string
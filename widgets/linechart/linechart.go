@@ -22,13 +22,16 @@ import (
 	"math"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/mouse"
 	"github.com/mum4k/termdash/private/area"
 	"github.com/mum4k/termdash/private/canvas"
 	"github.com/mum4k/termdash/private/canvas/braille"
 	"github.com/mum4k/termdash/private/draw"
 	"github.com/mum4k/termdash/private/numbers"
+	"github.com/mum4k/termdash/series"
 	"github.com/mum4k/termdash/terminal/terminalapi"
 	"github.com/mum4k/termdash/widgetapi"
 	"github.com/mum4k/termdash/widgets/linechart/internal/axes"
@@ -44,6 +47,22 @@ type seriesValues struct {
 	// max is the largest value, zero if values is empty.
 	max float64
 
+	// stream is the streamed series the values are downsampled from on each
+	// call to Draw, nil if the values were provided via Series.
+	stream *series.Series
+	// computed computes the values from other series on each change of the
+	// series, nil if the values aren't computed.
+	computed *computed
+	// forecast is the projection of the series, nil if it isn't projected.
+	forecast *forecast
+	// points are the metadata of individual values keyed by their positions.
+	points map[int]Point
+	// gapMode determines how the missing values are displayed.
+	gapMode GapMode
+	// maxInterval is the longest interval between the values of a streamed
+	// series that isn't considered an outage, zero if outages aren't detected.
+	maxInterval time.Duration
+
 	seriesCellOpts []cell.Option
 	// The custom labels provided on a call to Series and a bool indicating if
 	// the labels were provided. This allows resetting them to nil.
@@ -80,6 +99,16 @@ func newSeriesValues(values []float64) *seriesValues {
 // highlighting an area on the graph (left mouse clicking and dragging) or by
 // using the mouse scroll button.
 //
+// Series can be computed from other series, see Computed.
+//
+// In the compare mode, LineChart overlays the series with their baselines,
+// see Baseline and Compare.
+//
+// Individual values can be marked and labeled, see SeriesPoints.
+//
+// LineChart optionally displays a crosshair under the mouse pointer, which
+// can be linked with the crosshairs of other charts, see CrosshairLink.
+//
 // Implements widgetapi.Widget. This object is thread-safe.
 type LineChart struct {
 	// mu protects the LineChart widget.
@@ -88,6 +117,15 @@ type LineChart struct {
 	// series are the series that will be plotted.
 	// Keyed by the name of the series and updated by calling Series.
 	series map[string]*seriesValues
+	// computedOrder are the labels of the computed series in the order they
+	// were registered, which is the order they are computed in.
+	computedOrder []string
+
+	// baselines are the baselines of the series keyed by the labels of the
+	// series, updated by calling Baseline.
+	baselines map[string]*seriesValues
+	// compare indicates that the compare mode is enabled.
+	compare bool
 
 	// yMin are the min and max values for the Y axis.
 	yMin, yMax float64
@@ -104,6 +142,20 @@ type LineChart struct {
 
 	// zoom tracks the zooming of the X axis.
 	zoom *zoom.Tracker
+
+	// crosshair is the position of the crosshair on the X axis or nil if the
+	// crosshair isn't displayed. Only used if the crosshair isn't linked.
+	crosshair *int
+	// lastXD and lastGraphAr are the details of the X axis and the area of
+	// the graph as observed on the last call to Draw. Used to translate mouse
+	// positions into positions on the X axis.
+	lastXD      *axes.XDetails
+	lastGraphAr image.Rectangle
+	// tooltips are the labels of the values as of the last call to Draw.
+	tooltips []widgetapi.Tooltip
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
 }
 
 // New returns a new line chart widget.
@@ -113,8 +165,9 @@ func New(opts ...Option) (*LineChart, error) {
 		return nil, err
 	}
 	return &LineChart{
-		series: map[string]*seriesValues{},
-		opts:   opt,
+		series:    map[string]*seriesValues{},
+		baselines: map[string]*seriesValues{},
+		opts:      opt,
 	}, nil
 }
 
@@ -162,6 +215,10 @@ func SeriesXLabels(labels map[int]string) SeriesOption {
 
 // yMinMax determines the min and max values for the Y axis.
 func (lc *LineChart) yMinMax() (float64, float64) {
+	if p := lc.opts.yAxisPinned; p != nil {
+		return p.min, p.max
+	}
+
 	var (
 		minimums []float64
 		maximums []float64
@@ -171,14 +228,28 @@ func (lc *LineChart) yMinMax() (float64, float64) {
 		maximums = append(maximums, sv.max)
 	}
 
+	for _, name := range lc.baselineNames() {
+		minimums = append(minimums, lc.baselines[name].min)
+		maximums = append(maximums, lc.baselines[name].max)
+	}
+
 	if lc.opts.yAxisCustomScale != nil {
 		minimums = append(minimums, lc.opts.yAxisCustomScale.min)
 		maximums = append(maximums, lc.opts.yAxisCustomScale.max)
 	}
 
+	if lc.opts.yAxisIncludeZero {
+		minimums = append(minimums, 0)
+		maximums = append(maximums, 0)
+	}
+
 	min, _ := minMax(minimums)
 	_, max := minMax(maximums)
 
+	if lc.opts.yAxisSymmetric {
+		max = math.Max(math.Abs(min), math.Abs(max))
+		min = -max
+	}
 	return min, max
 }
 
@@ -199,7 +270,7 @@ func (lc *LineChart) ValueCapacity() int {
 // Series sets the values that should be displayed as the line chart with the
 // provided label.
 // The values that should not be displayed on the line chart should be represented
-// as math.NaN values on the values slice.
+// as math.NaN values on the values slice, see SeriesGapMode.
 // Subsequent calls with the same label replace any previously provided values.
 func (lc *LineChart) Series(label string, values []float64, opts ...SeriesOption) error {
 	if label == "" {
@@ -224,25 +295,90 @@ func (lc *LineChart) Series(label string, values []float64, opts ...SeriesOption
 		}
 		lc.xLabels = series.xLabels
 	}
+	series.setValues(series.values)
 
-	lc.series[label] = series
+	lc.setSeries(label, series)
+	lc.refreshComputed()
 	yMin, yMax := lc.yMinMax()
 	lc.yMin = yMin
 	lc.yMax = yMax
+	lc.markChanged()
+	return nil
+}
+
+// Stream displays the streamed series as the line chart with the provided
+// label. Values appended to the series are displayed on the next redraw. The
+// series is downsampled to the width of the graph in pixels, so each pixel
+// displays one value regardless of how many values the series holds. The
+// time of the values isn't displayed, the values are distributed evenly on
+// the X axis.
+// The SeriesXLabels option isn't supported, since the positions of the
+// values change as they are downsampled.
+// Subsequent calls with the same label replace any previously provided values
+// or series, calling Series with the same label stops the streaming.
+func (lc *LineChart) Stream(label string, s *series.Series, opts ...SeriesOption) error {
+	if label == "" {
+		return errors.New("the label cannot be empty")
+	}
+	if s == nil {
+		return errors.New("the series cannot be nil")
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	sv := newSeriesValues(nil)
+	for _, opt := range opts {
+		opt.set(sv)
+	}
+	if sv.xLabelsSet {
+		return errors.New("the SeriesXLabels option isn't supported with a streamed series")
+	}
+	sv.stream = s
+	lc.setSeries(label, sv)
+	lc.refreshStreams()
+	lc.markChanged()
 	return nil
 }
 
+// refreshStreams downsamples the streamed series to the capacity of the
+// graph, recomputes the computed series and updates the range of the Y axis. All the values are used until
+// the capacity is known.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) refreshStreams() {
+	var refreshed bool
+	for _, sv := range lc.series {
+		if sv.stream == nil {
+			continue
+		}
+		sv.setValues(sv.downsample(lc.capacity))
+		refreshed = true
+	}
+	if refreshed {
+		lc.refreshComputed()
+		lc.yMin, lc.yMax = lc.yMinMax()
+	}
+}
+
+// xProperties returns the properties of the X axis given the specified
+// minimum and maximum value to display and the orientation of the labels.
+func (lc *LineChart) xProperties(reqYWidth, min, max int, lo axes.LabelOrientation) *axes.XProperties {
+	return &axes.XProperties{
+		Min:            min,
+		Max:            max,
+		ReqYWidth:      reqYWidth,
+		CustomLabels:   lc.xLabels,
+		LO:             lo,
+		ValueFormatter: lc.opts.xAxisValueFormatter,
+		LabelEvery:     lc.opts.xLabelEvery,
+		MaxLabelWidth:  lc.opts.xLabelMaxWidth,
+	}
+}
+
 // xDetails returns the details for the X axis given the specified minimum and
-// maximum value to display.
-func (lc *LineChart) xDetails(cvs *canvas.Canvas, reqYWidth, min, max int) (*axes.XDetails, error) {
-	xp := &axes.XProperties{
-		Min:          min,
-		Max:          max,
-		ReqYWidth:    reqYWidth,
-		CustomLabels: lc.xLabels,
-		LO:           lc.opts.xLabelOrientation,
-	}
-	xd, err := axes.NewXDetails(cvs.Area(), xp)
+// maximum value to display and the resolved orientation of the labels.
+func (lc *LineChart) xDetails(cvs *canvas.Canvas, reqYWidth, min, max int, lo axes.LabelOrientation) (*axes.XDetails, error) {
+	xd, err := axes.NewXDetails(cvs.Area(), lc.xProperties(reqYWidth, min, max, lo))
 	if err != nil {
 		return nil, fmt.Errorf("NewXDetails => %v", err)
 	}
@@ -264,7 +400,7 @@ func (lc *LineChart) xDetailsForCap(cvs *canvas.Canvas, bc *braille.Canvas, xd *
 	diff := values - lc.capacity
 	xMin := int(xd.Scale.Min.Value) + diff
 	xMax := int(xd.Scale.Max.Value)
-	unscaledXD, err := lc.xDetails(cvs, yd.Start.X, xMin, xMax)
+	unscaledXD, err := lc.xDetails(cvs, yd.Start.X, xMin, xMax, xd.Properties.LO)
 	if err != nil {
 		return nil, err
 	}
@@ -273,7 +409,13 @@ func (lc *LineChart) xDetailsForCap(cvs *canvas.Canvas, bc *braille.Canvas, xd *
 
 // axesDetails determines the details about the X and Y axes.
 func (lc *LineChart) axesDetails(cvs *canvas.Canvas) (*axes.XDetails, *axes.YDetails, error) {
-	reqXHeight := axes.RequiredHeight(lc.maxXValue(), lc.xLabels, lc.opts.xLabelOrientation)
+	// The width of the Y axis isn't known before its details are determined,
+	// the orientation of the labels is resolved with the required width.
+	lo, err := axes.AutoOrientation(cvs.Area(), lc.xProperties(axes.RequiredWidth(lc.yMin, lc.yMax), 0, lc.maxXValue(), lc.opts.xLabelOrientation))
+	if err != nil {
+		return nil, nil, fmt.Errorf("AutoOrientation => %v", err)
+	}
+	reqXHeight := axes.RequiredHeight(lc.xProperties(0, 0, lc.maxXValue(), lo))
 	yp := &axes.YProperties{
 		Min:            lc.yMin,
 		Max:            lc.yMax,
@@ -288,7 +430,7 @@ func (lc *LineChart) axesDetails(cvs *canvas.Canvas) (*axes.XDetails, *axes.YDet
 
 	const xMin = 0
 	xMax := lc.maxXValue()
-	xd, err := lc.xDetails(cvs, yd.Start.X, xMin, xMax)
+	xd, err := lc.xDetails(cvs, yd.Start.X, xMin, xMax, lo)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -309,6 +451,7 @@ func (lc *LineChart) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 		return draw.ResizeNeeded(cvs)
 	}
 
+	lc.refreshStreams()
 	xd, yd, err := lc.axesDetails(cvs)
 	if err != nil {
 		return err
@@ -318,6 +461,12 @@ func (lc *LineChart) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	if err != nil {
 		return err
 	}
+	if err := lc.drawCompareStats(cvs, lc.lastGraphAr); err != nil {
+		return err
+	}
+	if err := lc.drawCrosshairLabels(cvs); err != nil {
+		return err
+	}
 	return lc.drawAxes(cvs, adjXD, yd)
 }
 
@@ -342,7 +491,7 @@ func (lc *LineChart) drawAxes(cvs *canvas.Canvas, xd *axes.XDetails, yd *axes.YD
 	}
 
 	for _, l := range xd.Labels {
-		switch lc.opts.xLabelOrientation {
+		switch xd.Properties.LO {
 		case axes.LabelOrientationHorizontal:
 			if err := draw.Text(cvs, l.Value.Text(), l.Pos, draw.TextCellOpts(lc.opts.xLabelCellOpts...)); err != nil {
 				return fmt.Errorf("failed to draw the X horizontal labels: %v", err)
@@ -366,6 +515,13 @@ func (lc *LineChart) graphAr(cvs *canvas.Canvas, xd *axes.XDetails, yd *axes.YDe
 	return image.Rect(yd.Start.X+1, yd.Start.Y, cvs.Area().Max.X, xd.End.Y)
 }
 
+// forecastDash and forecastGap are the lengths of the dashes and the gaps
+// in pixels of the lines that display the projections of the series.
+const (
+	forecastDash = 2
+	forecastGap  = 2
+)
+
 // drawSeries draws the graph representing the stored series.
 // Returns XDetails that might be adjusted to not start at zero value if some
 // of the series didn't fit the graphs and XAxisUnscaled was provided.
@@ -395,6 +551,10 @@ func (lc *LineChart) drawSeries(cvs *canvas.Canvas, xd *axes.XDetails, yd *axes.
 	}
 
 	xdZoomed := lc.zoom.Zoom()
+	if err := lc.drawBaselines(bc, xdZoomed, yd); err != nil {
+		return nil, err
+	}
+
 	var names []string
 	for name := range lc.series {
 		names = append(names, name)
@@ -403,58 +563,36 @@ func (lc *LineChart) drawSeries(cvs *canvas.Canvas, xd *axes.XDetails, yd *axes.
 
 	for _, name := range names {
 		sv := lc.series[name]
-		// Skip over series that don't have at least two points since we can't
-		// draw a line for just one point.
-		// Skip over series that fall under the minimum value on the X axis.
-		if got := len(sv.values); got <= 1 {
-			continue
+		if err := lc.drawValues(bc, xdZoomed, yd, name, sv.values, 0, draw.BrailleLineCellOpts(sv.seriesCellOpts...)); err != nil {
+			return nil, err
 		}
 
-		var prev float64
-		for i := 1; i < len(sv.values); i++ {
-			v := sv.values[i]
-			prev = sv.values[i-1]
-
-			// Skip the values that are missing.
-			if math.IsNaN(v) || math.IsNaN(prev) {
-				continue
+		if f := sv.forecast; f != nil && len(f.values) > 0 {
+			// The projection starts at the last value of the series.
+			last := len(sv.values) - 1
+			values := append([]float64{sv.values[last]}, f.values...)
+			cellOpts := sv.seriesCellOpts
+			if f.cellOpts != nil {
+				cellOpts = f.cellOpts
 			}
-
-			if i < int(xdZoomed.Scale.Min.Value)+1 || i > int(xdZoomed.Scale.Max.Value) {
-				// Don't draw lines for values that aren't supposed to be visible.
-				// These are either values outside of the current zoom or
-				// values at the beginning of a series that falls before athe
-				// start of an unscaled X axis when the XAxisUnscaled option is
-				// provided.
-				continue
-			}
-
-			startX, err := xdZoomed.Scale.ValueToPixel(i - 1)
-			if err != nil {
-				return nil, fmt.Errorf("failure for series %v[%d] on scale %v, xdZoomed.Scale.ValueToPixel(%v) => %v", name, i-1, xdZoomed.Scale, i-1, err)
-			}
-			endX, err := xdZoomed.Scale.ValueToPixel(i)
-			if err != nil {
-				return nil, fmt.Errorf("failure for series %v[%d] on scale %v, xdZoomed.Scale.ValueToPixel(%v) => %v", name, i, xdZoomed.Scale, i, err)
-			}
-
-			startY, err := yd.Scale.ValueToPixel(prev)
-			if err != nil {
-				return nil, fmt.Errorf("failure for series %v[%d] on scale %v, yd.Scale.ValueToPixel(%v) => %v", name, i-1, yd.Scale, prev, err)
+			if err := lc.drawValues(bc, xdZoomed, yd, name, values, last,
+				draw.BrailleLineCellOpts(cellOpts...),
+				draw.BrailleLineDashed(forecastDash, forecastGap),
+			); err != nil {
+				return nil, err
 			}
+		}
+	}
 
-			endY, err := yd.Scale.ValueToPixel(v)
-			if err != nil {
-				return nil, fmt.Errorf("failure for series %v[%d] on scale %v, yd.Scale.ValueToPixel(%v) => %v", name, i, yd.Scale, v, err)
-			}
+	if err := lc.drawPoints(bc, graphAr, xdZoomed, yd, names); err != nil {
+		return nil, err
+	}
 
-			if err := draw.BrailleLine(bc,
-				image.Point{startX, startY},
-				image.Point{endX, endY},
-				draw.BrailleLineCellOpts(sv.seriesCellOpts...),
-			); err != nil {
-				return nil, fmt.Errorf("draw.BrailleLine => %v", err)
-			}
+	lc.lastXD = xdZoomed
+	lc.lastGraphAr = graphAr
+	if x, ok := lc.crosshairX(); ok {
+		if err := lc.drawCrosshair(bc, xdZoomed, x); err != nil {
+			return nil, err
 		}
 	}
 
@@ -470,6 +608,68 @@ func (lc *LineChart) drawSeries(cvs *canvas.Canvas, xd *axes.XDetails, yd *axes.
 	return xdZoomed, nil
 }
 
+// drawValues draws lines between the values, the first of the values is at
+// the specified position on the X axis.
+// If the values have NaN values they will be ignored and not draw on the
+// graph.
+func (lc *LineChart) drawValues(bc *braille.Canvas, xdZoomed *axes.XDetails, yd *axes.YDetails, name string, values []float64, start int, opts ...draw.BrailleLineOption) error {
+	// Skip over series that don't have at least two points since we can't
+	// draw a line for just one point.
+	// Skip over series that fall under the minimum value on the X axis.
+	if got := len(values); got <= 1 {
+		return nil
+	}
+
+	var prev float64
+	for j := 1; j < len(values); j++ {
+		v := clampY(yd, values[j])
+		prev = clampY(yd, values[j-1])
+		i := start + j
+
+		// Skip the values that are missing.
+		if math.IsNaN(v) || math.IsNaN(prev) {
+			continue
+		}
+
+		if i < int(xdZoomed.Scale.Min.Value)+1 || i > int(xdZoomed.Scale.Max.Value) {
+			// Don't draw lines for values that aren't supposed to be visible.
+			// These are either values outside of the current zoom or
+			// values at the beginning of a series that falls before athe
+			// start of an unscaled X axis when the XAxisUnscaled option is
+			// provided.
+			continue
+		}
+
+		startX, err := xdZoomed.Scale.ValueToPixel(i - 1)
+		if err != nil {
+			return fmt.Errorf("failure for series %v[%d] on scale %v, xdZoomed.Scale.ValueToPixel(%v) => %v", name, i-1, xdZoomed.Scale, i-1, err)
+		}
+		endX, err := xdZoomed.Scale.ValueToPixel(i)
+		if err != nil {
+			return fmt.Errorf("failure for series %v[%d] on scale %v, xdZoomed.Scale.ValueToPixel(%v) => %v", name, i, xdZoomed.Scale, i, err)
+		}
+
+		startY, err := yd.Scale.ValueToPixel(prev)
+		if err != nil {
+			return fmt.Errorf("failure for series %v[%d] on scale %v, yd.Scale.ValueToPixel(%v) => %v", name, i-1, yd.Scale, prev, err)
+		}
+
+		endY, err := yd.Scale.ValueToPixel(v)
+		if err != nil {
+			return fmt.Errorf("failure for series %v[%d] on scale %v, yd.Scale.ValueToPixel(%v) => %v", name, i, yd.Scale, v, err)
+		}
+
+		if err := draw.BrailleLine(bc,
+			image.Point{startX, startY},
+			image.Point{endX, endY},
+			opts...,
+		); err != nil {
+			return fmt.Errorf("draw.BrailleLine => %v", err)
+		}
+	}
+	return nil
+}
+
 // highlightRange highlights the range of X columns on the braille canvas.
 func (lc *LineChart) highlightRange(bc *braille.Canvas, hRange *zoom.Range) error {
 	cellAr := bc.CellArea()
@@ -477,20 +677,161 @@ func (lc *LineChart) highlightRange(bc *braille.Canvas, hRange *zoom.Range) erro
 	return bc.SetAreaCellOpts(ar, cell.BgColor(lc.opts.zoomHightlightColor))
 }
 
+// crosshairKey returns the key of the position on the X axis published
+// through the crosshair link.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) crosshairKey(x int) float64 {
+	if lc.opts.crosshairKeys == nil {
+		return float64(x)
+	}
+	return lc.opts.crosshairKeys(x)
+}
+
+// crosshairX returns the position of the crosshair on the X axis. Returns
+// false if the crosshair isn't displayed.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) crosshairX() (int, bool) {
+	if !lc.opts.crosshair {
+		return 0, false
+	}
+	if lc.opts.crosshairLink == nil {
+		if lc.crosshair == nil {
+			return 0, false
+		}
+		return *lc.crosshair, true
+	}
+
+	key, ok := lc.opts.crosshairLink.Position()
+	if !ok {
+		return 0, false
+	}
+	// Find the position whose key is the closest.
+	max := lc.maxXValue()
+	x := sort.Search(max+1, func(i int) bool {
+		return lc.crosshairKey(i) >= key
+	})
+	if x > max {
+		x = max
+	}
+	if x > 0 && key-lc.crosshairKey(x-1) < lc.crosshairKey(x)-key {
+		x--
+	}
+	return x, true
+}
+
+// drawCrosshair highlights the column of the graph at the position on the X
+// axis.
+func (lc *LineChart) drawCrosshair(bc *braille.Canvas, xd *axes.XDetails, x int) error {
+	if fx := float64(x); fx < xd.Scale.Min.Value || fx > xd.Scale.Max.Value {
+		// The position isn't visible, e.g. outside of the current zoom.
+		return nil
+	}
+	cellX, err := xd.Scale.ValueToCell(x)
+	if err != nil {
+		return err
+	}
+	cellAr := bc.CellArea()
+	ar := image.Rect(cellX, cellAr.Min.Y, cellX+1, cellAr.Max.Y)
+	return bc.SetAreaCellOpts(ar, cell.BgColor(lc.opts.crosshairColor))
+}
+
+// moveCrosshair moves the crosshair to the mouse pointer or removes it if
+// the pointer left the graph.
+// Returns a function that publishes the change through the crosshair link,
+// which must be called after releasing lc.mu.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) moveCrosshair(m *terminalapi.Mouse) (func(), error) {
+	if m.Button != mouse.ButtonNone && m.Button != mouse.ButtonLeft {
+		return func() {}, nil
+	}
+	l := lc.opts.crosshairLink
+	if lc.lastXD == nil || !m.Position.In(lc.lastGraphAr) {
+		lc.crosshair = nil
+		if l == nil {
+			return func() {}, nil
+		}
+		return func() { l.Clear(lc) }, nil
+	}
+
+	v, err := lc.lastXD.Scale.PixelToValue((m.Position.X - lc.lastGraphAr.Min.X) * braille.ColMult)
+	if err != nil {
+		return nil, err
+	}
+	x := int(math.Round(v))
+	if max := lc.maxXValue(); x > max {
+		x = max
+	}
+	lc.crosshair = &x
+	if l == nil {
+		return func() {}, nil
+	}
+	key := lc.crosshairKey(x)
+	return func() { l.Set(lc, key) }, nil
+}
+
+// mouse processes mouse events.
+// Returns a function that publishes the changes of the crosshair, which must
+// be called after releasing lc.mu.
+func (lc *LineChart) mouse(m *terminalapi.Mouse) (func(), error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	publish := func() {}
+	if lc.opts.crosshair {
+		p, err := lc.moveCrosshair(m)
+		if err != nil {
+			return nil, err
+		}
+		publish = p
+	}
+	if lc.zoom == nil {
+		return publish, nil
+	}
+	return publish, lc.zoom.Mouse(m)
+}
+
 // Keyboard implements widgetapi.Widget.Keyboard.
+// The keyboard is only used to toggle the compare mode, see CompareKey.
 func (lc *LineChart) Keyboard(k *terminalapi.Keyboard) error {
-	return errors.New("the LineChart widget doesn't support keyboard events")
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if lc.opts.compareKey == 0 {
+		return errors.New("the LineChart widget doesn't support keyboard events without the CompareKey option")
+	}
+	if k.Key == lc.opts.compareKey {
+		lc.compare = !lc.compare
+		lc.yMin, lc.yMax = lc.yMinMax()
+		lc.markChanged()
+	}
+	return nil
 }
 
-// Mouse implements widgetapi.Widget.Mouse.
-func (lc *LineChart) Mouse(m *terminalapi.Mouse) error {
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (lc *LineChart) SetNotifyFunc(fn func()) {
 	lc.mu.Lock()
 	defer lc.mu.Unlock()
+	lc.notify = fn
+}
 
-	if lc.zoom == nil {
-		return nil
+// markChanged notifies the infrastructure that the content changed.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) markChanged() {
+	if lc.notify != nil {
+		lc.notify()
 	}
-	return lc.zoom.Mouse(m)
+}
+
+// Mouse implements widgetapi.Widget.Mouse.
+func (lc *LineChart) Mouse(m *terminalapi.Mouse) error {
+	publish, err := lc.mouse(m)
+	if err != nil {
+		return err
+	}
+	// Mutex must be released when publishing the crosshair, the subscribers
+	// of the link might call methods of this line chart.
+	publish()
+	return nil
 }
 
 // minSize determines the minimum required size to draw the line chart.
@@ -503,7 +844,13 @@ func (lc *LineChart) minSize() image.Point {
 	// And for the height:
 	// - n cells width for the X axis and its labels as reported by it.
 	// - at least 2 cell height for the graph.
-	reqHeight := axes.RequiredHeight(lc.maxXValue(), lc.xLabels, lc.opts.xLabelOrientation) + 2
+	// Labels with the automatic orientation flow vertically only if there is
+	// enough space.
+	lo := lc.opts.xLabelOrientation
+	if lo == axes.LabelOrientationAuto {
+		lo = axes.LabelOrientationHorizontal
+	}
+	reqHeight := axes.RequiredHeight(lc.xProperties(0, 0, lc.maxXValue(), lo)) + 2
 	return image.Point{reqWidth, reqHeight}
 }
 
@@ -512,9 +859,16 @@ func (lc *LineChart) Options() widgetapi.Options {
 	lc.mu.RLock()
 	defer lc.mu.RUnlock()
 
+	ks := widgetapi.KeyScopeNone
+	if lc.opts.compareKey != 0 {
+		ks = widgetapi.KeyScopeFocused
+	}
 	return widgetapi.Options{
-		MinimumSize: lc.minSize(),
-		WantMouse:   widgetapi.MouseScopeGlobal,
+		MinimumSize:  lc.minSize(),
+		WantMouse:    widgetapi.MouseScopeGlobal,
+		WantKeyboard: ks,
+		// The crosshair follows the pointer on terminals that report hover.
+		WantHover: lc.opts.crosshair,
 	}
 }
 
@@ -522,8 +876,17 @@ func (lc *LineChart) Options() widgetapi.Options {
 // lc.mu must be held when calling this method.
 func (lc *LineChart) maxXValue() int {
 	maxLen := 0
+	for _, name := range lc.baselineNames() {
+		if l := len(lc.baselines[name].values); l > maxLen {
+			maxLen = l
+		}
+	}
 	for _, sv := range lc.series {
-		if l := len(sv.values); l > maxLen {
+		l := len(sv.values)
+		if sv.forecast != nil {
+			l += len(sv.forecast.values)
+		}
+		if l > maxLen {
 			maxLen = l
 		}
 	}
@@ -546,3 +909,10 @@ func minMax(values []float64) (x, y float64) {
 	}
 	return min, max
 }
+
+// clampY clamps the value to the range of the Y axis, so that values outside
+// of a pinned range are displayed at the edge of the graph, see YAxisPinned.
+// NaN values are returned unchanged.
+func clampY(yd *axes.YDetails, v float64) float64 {
+	return math.Max(yd.Scale.Min.Value, math.Min(yd.Scale.Max.Value, v))
+}
@@ -19,9 +19,11 @@ import (
 	"image"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/kylelemons/godebug/pretty"
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/crosshair"
 	"github.com/mum4k/termdash/mouse"
 	"github.com/mum4k/termdash/private/canvas"
 	"github.com/mum4k/termdash/private/canvas/braille/testbraille"
@@ -29,8 +31,10 @@ import (
 	"github.com/mum4k/termdash/private/draw"
 	"github.com/mum4k/termdash/private/draw/testdraw"
 	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/series"
 	"github.com/mum4k/termdash/terminal/terminalapi"
 	"github.com/mum4k/termdash/widgetapi"
+	"github.com/mum4k/termdash/widgets/linechart/internal/axes"
 )
 
 func TestLineChartDraws(t *testing.T) {
@@ -94,6 +98,38 @@ func TestLineChartDraws(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			desc:   "fails with pinned scale where min is NaN",
+			canvas: image.Rect(0, 0, 3, 4),
+			opts: []Option{
+				YAxisPinned(math.NaN(), 1),
+			},
+			wantErr: true,
+		},
+		{
+			desc:   "fails with pinned scale where min == max",
+			canvas: image.Rect(0, 0, 3, 4),
+			opts: []Option{
+				YAxisPinned(1, 1),
+			},
+			wantErr: true,
+		},
+		{
+			desc:   "fails with negative XLabelsEvery",
+			canvas: image.Rect(0, 0, 3, 4),
+			opts: []Option{
+				XLabelsEvery(-1),
+			},
+			wantErr: true,
+		},
+		{
+			desc:   "fails with negative XLabelsMaxWidth",
+			canvas: image.Rect(0, 0, 3, 4),
+			opts: []Option{
+				XLabelsMaxWidth(-1),
+			},
+			wantErr: true,
+		},
 		{
 			desc:   "series fails without name for the series",
 			canvas: image.Rect(0, 0, 3, 4),
@@ -313,6 +349,43 @@ func TestLineChartDraws(t *testing.T) {
 				return ft
 			},
 		},
+		{
+			desc: "pinned Y scale, values outside of the range are clamped",
+			opts: []Option{
+				YAxisPinned(0, 200),
+			},
+			canvas: image.Rect(0, 0, 20, 10),
+			writes: func(lc *LineChart) error {
+				return lc.Series("first", []float64{0, 400})
+			},
+			wantCapacity: 26,
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				// Y and X axis.
+				lines := []draw.HVLine{
+					{Start: image.Point{6, 0}, End: image.Point{6, 8}},
+					{Start: image.Point{6, 8}, End: image.Point{19, 8}},
+				}
+				testdraw.MustHVLines(c, lines)
+
+				// Value labels.
+				testdraw.MustText(c, "0", image.Point{5, 7})
+				testdraw.MustText(c, "103.36", image.Point{0, 3})
+				testdraw.MustText(c, "0", image.Point{7, 9})
+				testdraw.MustText(c, "1", image.Point{19, 9})
+
+				// Braille line.
+				graphAr := image.Rect(7, 0, 20, 8)
+				bc := testbraille.MustNew(graphAr)
+				testdraw.MustBrailleLine(bc, image.Point{0, 31}, image.Point{25, 0})
+				testbraille.MustCopyTo(bc, c)
+
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
 		{
 			desc: "custom Y scale, zero based negative, values fit",
 			opts: []Option{
@@ -532,6 +605,44 @@ func TestLineChartDraws(t *testing.T) {
 				return ft
 			},
 		},
+		{
+			desc: "formatted X labels",
+			opts: []Option{
+				XAxisFormattedValues(func(v float64) string {
+					return fmt.Sprintf("t%v", v)
+				}),
+			},
+			canvas: image.Rect(0, 0, 20, 10),
+			writes: func(lc *LineChart) error {
+				return lc.Series("first", []float64{0, 100})
+			},
+			wantCapacity: 28,
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				// Y and X axis.
+				lines := []draw.HVLine{
+					{Start: image.Point{5, 0}, End: image.Point{5, 8}},
+					{Start: image.Point{5, 8}, End: image.Point{19, 8}},
+				}
+				testdraw.MustHVLines(c, lines)
+
+				// Value labels.
+				testdraw.MustText(c, "0", image.Point{4, 7})
+				testdraw.MustText(c, "51.68", image.Point{0, 3})
+				testdraw.MustText(c, "t0", image.Point{6, 9})
+
+				// Braille line.
+				graphAr := image.Rect(6, 0, 20, 8)
+				bc := testbraille.MustNew(graphAr)
+				testdraw.MustBrailleLine(bc, image.Point{0, 31}, image.Point{26, 0})
+				testbraille.MustCopyTo(bc, c)
+
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
 		{
 			desc:   "custom X labels, horizontal by default",
 			canvas: image.Rect(0, 0, 20, 10),
@@ -1896,6 +2007,96 @@ func TestLineChartDraws(t *testing.T) {
 	}
 }
 
+func TestStream(t *testing.T) {
+	s, err := series.New(1000)
+	if err != nil {
+		t.Fatalf("series.New => unexpected error: %v", err)
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 1500; i++ {
+		s.Append(start.Add(time.Duration(i)*time.Second), math.Sin(float64(i)/50)*100)
+	}
+
+	t.Run("fails on invalid arguments", func(t *testing.T) {
+		lc, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := lc.Stream("", s); err == nil {
+			t.Errorf("Stream => got nil error, want one for an empty label")
+		}
+		if err := lc.Stream("series", nil); err == nil {
+			t.Errorf("Stream => got nil error, want one for a nil series")
+		}
+		if err := lc.Stream("series", s, SeriesXLabels(map[int]string{0: "a"})); err == nil {
+			t.Errorf("Stream => got nil error, want one for SeriesXLabels")
+		}
+	})
+
+	t.Run("draws the downsampled series", func(t *testing.T) {
+		lc, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := lc.Stream("series", s, SeriesCellOpts(cell.FgColor(cell.ColorBlue))); err != nil {
+			t.Fatalf("Stream => unexpected error: %v", err)
+		}
+		ar := image.Rect(0, 0, 40, 10)
+		got := faketerm.MustNew(ar.Size())
+		// The first draw determines the capacity.
+		for i := 0; i < 2; i++ {
+			cvs := testcanvas.MustNew(ar)
+			if err := lc.Draw(cvs, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			testcanvas.MustApply(cvs, got)
+		}
+
+		capacity := lc.ValueCapacity()
+		values := series.Values(s.Downsample(capacity))
+		if len(values) != capacity {
+			t.Fatalf("Downsample(%d) => got %d values, want %d", capacity, len(values), capacity)
+		}
+
+		mirror, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := mirror.Series("series", values, SeriesCellOpts(cell.FgColor(cell.ColorBlue))); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		want := faketerm.MustNew(ar.Size())
+		cvs := testcanvas.MustNew(ar)
+		if err := mirror.Draw(cvs, &widgetapi.Meta{}); err != nil {
+			t.Fatalf("Draw => unexpected error: %v", err)
+		}
+		testcanvas.MustApply(cvs, want)
+
+		if diff := faketerm.Diff(want, got); diff != "" {
+			t.Errorf("Draw => %v", diff)
+		}
+	})
+
+	t.Run("Series stops the streaming", func(t *testing.T) {
+		lc, err := New()
+		if err != nil {
+			t.Fatalf("New => unexpected error: %v", err)
+		}
+		if err := lc.Stream("series", s); err != nil {
+			t.Fatalf("Stream => unexpected error: %v", err)
+		}
+		if err := lc.Series("series", []float64{0, 1}); err != nil {
+			t.Fatalf("Series => unexpected error: %v", err)
+		}
+		if err := lc.Draw(testcanvas.MustNew(image.Rect(0, 0, 40, 10)), &widgetapi.Meta{}); err != nil {
+			t.Fatalf("Draw => unexpected error: %v", err)
+		}
+		if got, want := lc.series["series"].values, []float64{0, 1}; len(got) != len(want) {
+			t.Errorf("values => %v, want %v", got, want)
+		}
+	})
+}
+
 func TestKeyboard(t *testing.T) {
 	lc, err := New()
 	if err != nil {
@@ -1916,6 +2117,303 @@ func TestMouseDoesNothingWithoutZoomTracker(t *testing.T) {
 	}
 }
 
+// crosshairColumns returns the columns of the canvas whose cells on the first
+// row of the graph have the background color of the crosshair.
+func crosshairColumns(t *testing.T, lc *LineChart, cvs *canvas.Canvas) []int {
+	t.Helper()
+
+	var res []int
+	for x := lc.lastGraphAr.Min.X; x < lc.lastGraphAr.Max.X; x++ {
+		c := testcanvas.MustCell(cvs, image.Point{x, lc.lastGraphAr.Min.Y})
+		if c.Opts.BgColor == cell.ColorNumber(DefaultCrosshairColorNumber) {
+			res = append(res, x)
+		}
+	}
+	return res
+}
+
+// mustColumn returns the column of the canvas that displays the value at the
+// position on the X axis.
+func mustColumn(t *testing.T, lc *LineChart, x int) int {
+	t.Helper()
+
+	cellX, err := lc.lastXD.Scale.ValueToCell(x)
+	if err != nil {
+		t.Fatalf("ValueToCell => unexpected error: %v", err)
+	}
+	return lc.lastGraphAr.Min.X + cellX
+}
+
+func TestCrosshair(t *testing.T) {
+	link := crosshair.NewLink()
+	var published []float64
+	link.Subscribe(func(pos float64, ok bool) {
+		if !ok {
+			pos = -1
+		}
+		published = append(published, pos)
+	})
+
+	// The first chart has a value for every two seconds, the second chart for
+	// every second.
+	first, err := New(
+		CrosshairLink(link),
+		CrosshairKeys(func(x int) float64 { return float64(2 * x) }),
+	)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	second, err := New(CrosshairLink(link))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := first.Series("first", make([]float64, 10)); err != nil {
+		t.Fatalf("Series => unexpected error: %v", err)
+	}
+	if err := second.Series("second", make([]float64, 19)); err != nil {
+		t.Fatalf("Series => unexpected error: %v", err)
+	}
+
+	ar := image.Rect(0, 0, 30, 10)
+	firstCvs := testcanvas.MustNew(ar)
+	secondCvs := testcanvas.MustNew(ar)
+	for _, d := range []struct {
+		lc  *LineChart
+		cvs *canvas.Canvas
+	}{
+		{first, firstCvs},
+		{second, secondCvs},
+	} {
+		if err := d.lc.Draw(d.cvs, &widgetapi.Meta{}); err != nil {
+			t.Fatalf("Draw => unexpected error: %v", err)
+		}
+		if got := crosshairColumns(t, d.lc, d.cvs); len(got) != 0 {
+			t.Errorf("crosshair drawn in columns %v before the mouse moved, want none", got)
+		}
+	}
+
+	// Hover over the value at position 3 of the first chart.
+	hover := &terminalapi.Mouse{
+		Position: image.Point{mustColumn(t, first, 3), first.lastGraphAr.Min.Y},
+		Button:   mouse.ButtonNone,
+	}
+	if err := first.Mouse(hover); err != nil {
+		t.Fatalf("Mouse => unexpected error: %v", err)
+	}
+	// The second chart sees the pointer outside of its canvas, which must not
+	// remove the crosshair published by the first chart.
+	if err := second.Mouse(&terminalapi.Mouse{Position: image.Point{-1, -1}, Button: mouse.ButtonNone}); err != nil {
+		t.Fatalf("Mouse => unexpected error: %v", err)
+	}
+	if pos, ok := link.Position(); !ok || pos != 6 {
+		t.Errorf("link.Position => %v, %v, want 6, true", pos, ok)
+	}
+
+	firstCvs = testcanvas.MustNew(ar)
+	secondCvs = testcanvas.MustNew(ar)
+	if err := first.Draw(firstCvs, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if err := second.Draw(secondCvs, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare([]int{mustColumn(t, first, 3)}, crosshairColumns(t, first, firstCvs)); diff != "" {
+		t.Errorf("crosshair of the first chart => unexpected diff (-want, +got):\n%s", diff)
+	}
+	if diff := pretty.Compare([]int{mustColumn(t, second, 6)}, crosshairColumns(t, second, secondCvs)); diff != "" {
+		t.Errorf("crosshair of the second chart => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	// The pointer leaves the first chart.
+	if err := first.Mouse(&terminalapi.Mouse{Position: image.Point{-1, -1}, Button: mouse.ButtonNone}); err != nil {
+		t.Fatalf("Mouse => unexpected error: %v", err)
+	}
+	secondCvs = testcanvas.MustNew(ar)
+	if err := second.Draw(secondCvs, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if got := crosshairColumns(t, second, secondCvs); len(got) != 0 {
+		t.Errorf("crosshair drawn in columns %v after the pointer left, want none", got)
+	}
+
+	if diff := pretty.Compare([]float64{6, -1}, published); diff != "" {
+		t.Errorf("published positions => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestCrosshairUnlinked(t *testing.T) {
+	lc, err := New(Crosshair())
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := lc.Series("series", make([]float64, 10)); err != nil {
+		t.Fatalf("Series => unexpected error: %v", err)
+	}
+	ar := image.Rect(0, 0, 30, 10)
+	if err := lc.Draw(testcanvas.MustNew(ar), &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+
+	click := &terminalapi.Mouse{
+		Position: image.Point{mustColumn(t, lc, 9), lc.lastGraphAr.Min.Y},
+		Button:   mouse.ButtonLeft,
+	}
+	release := &terminalapi.Mouse{
+		Position: click.Position,
+		Button:   mouse.ButtonRelease,
+	}
+	for _, m := range []*terminalapi.Mouse{click, release} {
+		if err := lc.Mouse(m); err != nil {
+			t.Fatalf("Mouse => unexpected error: %v", err)
+		}
+	}
+	cvs := testcanvas.MustNew(ar)
+	if err := lc.Draw(cvs, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare([]int{mustColumn(t, lc, 9)}, crosshairColumns(t, lc, cvs)); diff != "" {
+		t.Errorf("crosshair => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestXLabels(t *testing.T) {
+	tests := []struct {
+		desc   string
+		opts   []Option
+		labels map[int]string
+		wantLO axes.LabelOrientation
+		want   []string
+	}{
+		{
+			desc:   "horizontal labels by default",
+			labels: map[int]string{0: "a long label", 1: "b"},
+			wantLO: axes.LabelOrientationHorizontal,
+			want:   []string{"a long label"},
+		},
+		{
+			desc:   "vertical labels when they collide",
+			opts:   []Option{XLabelsAuto()},
+			labels: map[int]string{0: "a long label", 1: "b"},
+			wantLO: axes.LabelOrientationVertical,
+			want:   []string{"a long label", "b", "2"},
+		},
+		{
+			desc:   "horizontal labels when they don't collide",
+			opts:   []Option{XLabelsAuto()},
+			labels: map[int]string{0: "a", 1: "b"},
+			wantLO: axes.LabelOrientationHorizontal,
+			want:   []string{"a", "b", "2"},
+		},
+		{
+			desc:   "trims long labels",
+			opts:   []Option{XLabelsMaxWidth(4)},
+			labels: map[int]string{0: "a long label", 1: "b"},
+			wantLO: axes.LabelOrientationHorizontal,
+			want:   []string{"a l…", "b", "2"},
+		},
+		{
+			desc:   "labels every nth position",
+			opts:   []Option{XLabelsEvery(2)},
+			wantLO: axes.LabelOrientationHorizontal,
+			want:   []string{"0", "2"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			lc, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := lc.Series("series", []float64{1, 2, 3}, SeriesXLabels(tc.labels)); err != nil {
+				t.Fatalf("Series => unexpected error: %v", err)
+			}
+			if err := lc.Draw(testcanvas.MustNew(image.Rect(0, 0, 20, 20)), &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			if got := lc.lastXD.Properties.LO; got != tc.wantLO {
+				t.Errorf("Draw => label orientation %v, want %v", got, tc.wantLO)
+			}
+			var got []string
+			for _, l := range lc.lastXD.Labels {
+				got = append(got, l.Value.Text())
+			}
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("Draw => unexpected labels diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestYAxisRange(t *testing.T) {
+	tests := []struct {
+		desc    string
+		opts    []Option
+		values  []float64
+		wantMin float64
+		wantMax float64
+	}{
+		{
+			desc:    "adapts to the values",
+			opts:    []Option{YAxisAdaptive()},
+			values:  []float64{10, 20},
+			wantMin: 10,
+			wantMax: 20,
+		},
+		{
+			desc:    "includes zero",
+			opts:    []Option{YAxisCustomScale(10, 15), YAxisIncludeZero()},
+			values:  []float64{10, 20},
+			wantMin: 0,
+			wantMax: 20,
+		},
+		{
+			desc:    "includes zero for negative values",
+			opts:    []Option{YAxisAdaptive(), YAxisIncludeZero()},
+			values:  []float64{-10, -20},
+			wantMin: -20,
+			wantMax: 0,
+		},
+		{
+			desc:    "symmetric around zero",
+			opts:    []Option{YAxisSymmetric()},
+			values:  []float64{-5, 20},
+			wantMin: -20,
+			wantMax: 20,
+		},
+		{
+			desc:    "symmetric with negative values",
+			opts:    []Option{YAxisAdaptive(), YAxisSymmetric()},
+			values:  []float64{-30, -10},
+			wantMin: -30,
+			wantMax: 30,
+		},
+		{
+			desc:    "pinned ignores the values and the other options",
+			opts:    []Option{YAxisPinned(0, 100), YAxisSymmetric(), YAxisCustomScale(-500, 500)},
+			values:  []float64{-50, 200},
+			wantMin: 0,
+			wantMax: 100,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			lc, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if err := lc.Series("series", tc.values); err != nil {
+				t.Fatalf("Series => unexpected error: %v", err)
+			}
+			if lc.yMin != tc.wantMin || lc.yMax != tc.wantMax {
+				t.Errorf("Series => Y axis range [%v, %v], want [%v, %v]", lc.yMin, lc.yMax, tc.wantMin, tc.wantMax)
+			}
+		})
+	}
+}
+
 func TestOptions(t *testing.T) {
 	tests := []struct {
 		desc string
@@ -1931,6 +2429,28 @@ func TestOptions(t *testing.T) {
 				WantMouse:   widgetapi.MouseScopeGlobal,
 			},
 		},
+		{
+			desc: "wants hover events with the crosshair",
+			opts: []Option{
+				Crosshair(),
+			},
+			want: widgetapi.Options{
+				MinimumSize: image.Point{3, 4},
+				WantMouse:   widgetapi.MouseScopeGlobal,
+				WantHover:   true,
+			},
+		},
+		{
+			desc: "wants keyboard events with the compare key",
+			opts: []Option{
+				CompareKey('c'),
+			},
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{3, 4},
+				WantMouse:    widgetapi.MouseScopeGlobal,
+				WantKeyboard: widgetapi.KeyScopeFocused,
+			},
+		},
 		{
 			desc: "reserves space for longer Y labels",
 			addSeries: func(lc *LineChart) error {
@@ -1998,3 +2518,63 @@ func TestOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestNotifies(t *testing.T) {
+	tests := []struct {
+		desc   string
+		opts   []Option
+		update func(lc *LineChart) error
+	}{
+		{
+			desc: "Series",
+			update: func(lc *LineChart) error {
+				return lc.Series("series", []float64{0, 1})
+			},
+		},
+		{
+			desc: "Baseline",
+			update: func(lc *LineChart) error {
+				return lc.Baseline("series", []float64{0, 1})
+			},
+		},
+		{
+			desc: "Compare",
+			update: func(lc *LineChart) error {
+				lc.Compare(true)
+				return nil
+			},
+		},
+		{
+			desc: "CompareKey",
+			opts: []Option{CompareKey('c')},
+			update: func(lc *LineChart) error {
+				return lc.Keyboard(&terminalapi.Keyboard{Key: 'c'})
+			},
+		},
+		{
+			desc: "Computed",
+			update: func(lc *LineChart) error {
+				return lc.Computed("sum", Sum(), []string{"series"})
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			lc, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			notified := 0
+			lc.SetNotifyFunc(func() {
+				notified++
+			})
+			if err := tc.update(lc); err != nil {
+				t.Fatalf("update => unexpected error: %v", err)
+			}
+			if want := 1; notified != want {
+				t.Errorf("notified %d times, want %d", notified, want)
+			}
+		})
+	}
+}
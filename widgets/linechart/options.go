@@ -19,6 +19,9 @@ import (
 	"math"
 
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/crosshair"
+	"github.com/mum4k/termdash/format"
+	"github.com/mum4k/termdash/keyboard"
 	"github.com/mum4k/termdash/widgets/linechart/internal/axes"
 	"github.com/mum4k/termdash/widgets/linechart/internal/zoom"
 )
@@ -33,16 +36,29 @@ type Option interface {
 
 // options stores the provided options.
 type options struct {
-	axesCellOpts        []cell.Option
-	xLabelCellOpts      []cell.Option
-	xLabelOrientation   axes.LabelOrientation
-	yLabelCellOpts      []cell.Option
-	xAxisUnscaled       bool
-	yAxisMode           axes.YScaleMode
-	yAxisCustomScale    *customScale
-	yAxisValueFormatter ValueFormatter
-	zoomHightlightColor cell.Color
-	zoomStepPercent     int
+	axesCellOpts         []cell.Option
+	xLabelCellOpts       []cell.Option
+	xLabelOrientation    axes.LabelOrientation
+	xLabelEvery          int
+	xLabelMaxWidth       int
+	yLabelCellOpts       []cell.Option
+	xAxisUnscaled        bool
+	yAxisMode            axes.YScaleMode
+	yAxisCustomScale     *customScale
+	yAxisPinned          *customScale
+	yAxisIncludeZero     bool
+	yAxisSymmetric       bool
+	yAxisValueFormatter  ValueFormatter
+	xAxisValueFormatter  ValueFormatter
+	zoomHightlightColor  cell.Color
+	zoomStepPercent      int
+	crosshair            bool
+	crosshairColor       cell.Color
+	crosshairLink        *crosshair.Link
+	crosshairKeys        func(x int) float64
+	baselineCellOpts     []cell.Option
+	compareStatsCellOpts []cell.Option
+	compareKey           keyboard.Key
 }
 
 // validate validates the provided options.
@@ -55,6 +71,20 @@ func (o *options) validate() error {
 			return fmt.Errorf("the min(%v) must be less than the max(%v) provided as custom Y scale", o.yAxisCustomScale.min, o.yAxisCustomScale.max)
 		}
 	}
+	if o.yAxisPinned != nil {
+		if math.IsNaN(o.yAxisPinned.min) || math.IsNaN(o.yAxisPinned.max) {
+			return fmt.Errorf("both the min(%v) and the max(%v) provided as pinned Y scale must be valid numbers", o.yAxisPinned.min, o.yAxisPinned.max)
+		}
+		if o.yAxisPinned.min >= o.yAxisPinned.max {
+			return fmt.Errorf("the min(%v) must be less than the max(%v) provided as pinned Y scale", o.yAxisPinned.min, o.yAxisPinned.max)
+		}
+	}
+	if o.xLabelEvery < 0 {
+		return fmt.Errorf("invalid XLabelsEvery(%d), must be a positive number", o.xLabelEvery)
+	}
+	if o.xLabelMaxWidth < 0 {
+		return fmt.Errorf("invalid XLabelsMaxWidth(%d), must be a positive number", o.xLabelMaxWidth)
+	}
 	if got, min, max := o.zoomStepPercent, 1, 100; got < min || got > max {
 		return fmt.Errorf("invalid ZoomStepPercent %d, must be in range %d <= value <= %d", got, min, max)
 	}
@@ -66,6 +96,10 @@ func newOptions(opts ...Option) *options {
 	opt := &options{
 		zoomHightlightColor: cell.ColorNumber(235),
 		zoomStepPercent:     zoom.DefaultScrollStep,
+		crosshairColor:      cell.ColorNumber(DefaultCrosshairColorNumber),
+		baselineCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorNumber(DefaultBaselineColorNumber)),
+		},
 	}
 	for _, o := range opts {
 		o.set(opt)
@@ -111,6 +145,36 @@ func XLabelsHorizontal() Option {
 	})
 }
 
+// XLabelsAuto makes the labels under the X axis flow horizontally, unless
+// they would collide with each other, in which case they flow vertically if
+// the LineChart is tall enough.
+// Defaults to labels that flow horizontally.
+func XLabelsAuto() Option {
+	return option(func(opts *options) {
+		opts.xLabelOrientation = axes.LabelOrientationAuto
+	})
+}
+
+// XLabelsEvery only labels the positions on the X axis that are multiples of
+// n, e.g. every tenth value. Positions whose labels would collide with the
+// previous label are skipped.
+// Defaults to labeling as many positions as fit under the X axis.
+func XLabelsEvery(n int) Option {
+	return option(func(opts *options) {
+		opts.xLabelEvery = n
+	})
+}
+
+// XLabelsMaxWidth trims the labels under the X axis that are longer than the
+// specified number of cells, the trimmed labels end with the horizontal
+// ellipsis '…' character. Useful with long custom labels, see SeriesXLabels.
+// Defaults to zero, which means the labels aren't trimmed.
+func XLabelsMaxWidth(cells int) Option {
+	return option(func(opts *options) {
+		opts.xLabelMaxWidth = cells
+	})
+}
+
 // YLabelCellOpts set the cell options for the labels on the Y axis.
 func YLabelCellOpts(co ...cell.Option) Option {
 	return option(func(opts *options) {
@@ -132,7 +196,8 @@ func YAxisAdaptive() Option {
 	})
 }
 
-// customScale is the custom scale provided via the YAxisCustomScale option.
+// customScale is the custom scale provided via the YAxisCustomScale or the
+// YAxisPinned option.
 type customScale struct {
 	min, max float64
 }
@@ -160,6 +225,47 @@ func YAxisCustomScale(min, max float64) Option {
 	})
 }
 
+// YAxisPinned pins the Y axis to the specified minimum and maximum value.
+// Unlike YAxisCustomScale, the Y axis is never rescaled, values outside of
+// the range are displayed at the edge of the graph. Useful to keep the Y axis
+// of a continuously updated LineChart from jumping between redraws when the
+// range of the values is known upfront, e.g. percentages.
+// Both the minimum and the maximum must be valid numbers and the minimum must
+// be smaller than the maximum.
+//
+// Providing this option also sets YAxisAdaptive and takes precedence over
+// YAxisCustomScale, YAxisIncludeZero and YAxisSymmetric.
+func YAxisPinned(min, max float64) Option {
+	return option(func(opts *options) {
+		opts.yAxisPinned = &customScale{
+			min: min,
+			max: max,
+		}
+		opts.yAxisMode = axes.YScaleModeAdaptive
+	})
+}
+
+// YAxisIncludeZero makes the Y axis always include the zero value, even if
+// the series don't contain it. This is the default unless YAxisAdaptive or
+// YAxisCustomScale are provided, use this option to include the zero value
+// together with a custom scale.
+func YAxisIncludeZero() Option {
+	return option(func(opts *options) {
+		opts.yAxisIncludeZero = true
+	})
+}
+
+// YAxisSymmetric makes the Y axis symmetric around the zero value, i.e. the
+// minimum on the axis is the negated maximum. The maximum is the largest
+// absolute value in the series. Useful to display deltas or errors where
+// the sign matters and the positive and negative values should be comparable
+// at a glance.
+func YAxisSymmetric() Option {
+	return option(func(opts *options) {
+		opts.yAxisSymmetric = true
+	})
+}
+
 // XAxisUnscaled when provided, stops the LineChart from rescaling the X axis
 // when it can't fit all the values in the series, instead the LineCharts only
 // displays the last n values that fit into its width. This is useful to create
@@ -206,7 +312,97 @@ func YAxisFormattedValues(vfmt ValueFormatter) Option {
 	})
 }
 
+// XAxisFormattedValues sets a value formatter for the labels on the X axis.
+// The formatter receives the positions of the values in the series, e.g. to
+// display the times of the values computed from the position.
+// The custom labels provided via SeriesXLabels are preferred over the
+// formatted labels.
+func XAxisFormattedValues(vfmt ValueFormatter) Option {
+	return option(func(opts *options) {
+		opts.xAxisValueFormatter = vfmt
+	})
+}
+
+// Crosshair highlights the column of the graph under the mouse pointer to
+// help inspecting the values. The crosshair follows the pointer on terminals
+// that report hover, see terminalapi.Capabilities.Hover, and moves to the
+// position where the left mouse button is pressed on all the terminals.
+// The line chart has no crosshair by default.
+func Crosshair() Option {
+	return option(func(opts *options) {
+		opts.crosshair = true
+	})
+}
+
+// DefaultCrosshairColorNumber is the default color number for the
+// CrosshairColor option.
+const DefaultCrosshairColorNumber = 238
+
+// CrosshairColor sets the background color of the crosshair.
+// Defaults to DefaultCrosshairColorNumber.
+func CrosshairColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.crosshairColor = c
+	})
+}
+
+// CrosshairLink links the crosshair with the crosshairs of other charts that
+// share the link. Moving the crosshair on any of the charts highlights the
+// same position on all of them, see CrosshairKeys.
+//
+// Providing this option also sets Crosshair.
+func CrosshairLink(l *crosshair.Link) Option {
+	return option(func(opts *options) {
+		opts.crosshair = true
+		opts.crosshairLink = l
+	})
+}
+
+// CrosshairKeys maps the positions of the values on the X axis to the
+// positions published through the crosshair link, e.g. the timestamps of the
+// values in seconds. This way the crosshair links charts that display the
+// values at different resolutions, the other charts highlight the value whose
+// key is the closest. The keys must grow with the positions.
+// Defaults to the positions on the X axis, i.e. the indexes of the values.
+func CrosshairKeys(keys func(x int) float64) Option {
+	return option(func(opts *options) {
+		opts.crosshairKeys = keys
+	})
+}
+
+// DefaultBaselineColorNumber is the default color number for the baselines,
+// see BaselineCellOpts.
+const DefaultBaselineColorNumber = 240
+
+// BaselineCellOpts sets the cell options for the baselines displayed in the
+// compare mode, see LineChart.Baseline.
+// Defaults to the foreground color DefaultBaselineColorNumber.
+func BaselineCellOpts(co ...cell.Option) Option {
+	return option(func(opts *options) {
+		opts.baselineCellOpts = co
+	})
+}
+
+// CompareStatsCellOpts sets the cell options for the statistics comparing
+// the series with their baselines displayed in the compare mode.
+func CompareStatsCellOpts(co ...cell.Option) Option {
+	return option(func(opts *options) {
+		opts.compareStatsCellOpts = co
+	})
+}
+
+// CompareKey configures the keyboard key that enables and disables the
+// compare mode while the line chart is focused, see LineChart.Compare.
+// The line chart doesn't use the keyboard by default.
+func CompareKey(k keyboard.Key) Option {
+	return option(func(opts *options) {
+		opts.compareKey = k
+	})
+}
+
 // ValueFormatter will be used to format values onto string based
 // representation.
 // The received float64 value could be a math.NaN value.
-type ValueFormatter func(value float64) string
+// This is the same type as format.Formatter, so the formatters from the
+// format package can be used directly.
+type ValueFormatter = format.Formatter
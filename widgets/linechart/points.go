@@ -0,0 +1,162 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linechart
+
+// points.go contains the metadata and the styling of individual values.
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/braille"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/widgetapi"
+	"github.com/mum4k/termdash/widgets/linechart/internal/axes"
+)
+
+// Point is the metadata of an individual value of a series.
+type Point struct {
+	// CellOpts mark the value on the graph, e.g. with a red color to
+	// highlight an anomaly. The value isn't marked if empty.
+	CellOpts []cell.Option
+	// Label describes the value. The label is displayed when the mouse
+	// pointer hovers over the value and when the crosshair is at its
+	// position, see Crosshair.
+	Label string
+}
+
+// SeriesPoints attaches metadata to individual values of the series. The
+// argument maps the positions of the values in the series to their
+// metadata.
+func SeriesPoints(points map[int]Point) SeriesOption {
+	return seriesOption(func(opts *seriesValues) {
+		// Copy to avoid external modifications. See #174.
+		opts.points = make(map[int]Point, len(points))
+		for pos, p := range points {
+			opts.points[pos] = p
+		}
+	})
+}
+
+// pointPixel returns the pixel on the braille canvas that displays the value
+// at the position. Returns false if the value isn't visible.
+func pointPixel(xd *axes.XDetails, yd *axes.YDetails, values []float64, pos int) (image.Point, bool, error) {
+	if pos < 0 || pos >= len(values) || math.IsNaN(values[pos]) {
+		return image.Point{}, false, nil
+	}
+	if fx := float64(pos); fx < xd.Scale.Min.Value || fx > xd.Scale.Max.Value {
+		return image.Point{}, false, nil
+	}
+	x, err := xd.Scale.ValueToPixel(pos)
+	if err != nil {
+		return image.Point{}, false, err
+	}
+	y, err := yd.Scale.ValueToPixel(clampY(yd, values[pos]))
+	if err != nil {
+		return image.Point{}, false, err
+	}
+	return image.Point{x, y}, true, nil
+}
+
+// drawPoints marks the values that have cell options and determines the
+// tooltips of the labeled values.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) drawPoints(bc *braille.Canvas, graphAr image.Rectangle, xd *axes.XDetails, yd *axes.YDetails, names []string) error {
+	lc.tooltips = nil
+	for _, name := range names {
+		sv := lc.series[name]
+		var positions []int
+		for pos := range sv.points {
+			positions = append(positions, pos)
+		}
+		sort.Ints(positions)
+
+		for _, pos := range positions {
+			p := sv.points[pos]
+			px, ok, err := pointPixel(xd, yd, sv.values, pos)
+			if err != nil {
+				return fmt.Errorf("failure for series %v[%d]: %v", name, pos, err)
+			}
+			if !ok {
+				continue
+			}
+			if len(p.CellOpts) > 0 {
+				if err := bc.SetPixel(px, p.CellOpts...); err != nil {
+					return fmt.Errorf("bc.SetPixel(%v) => %v", px, err)
+				}
+			}
+			if p.Label != "" {
+				c := graphAr.Min.Add(image.Point{px.X / braille.ColMult, px.Y / braille.RowMult})
+				lc.tooltips = append(lc.tooltips, widgetapi.Tooltip{
+					Area: image.Rectangle{c, c.Add(image.Point{1, 1})},
+					Text: fmt.Sprintf("%s: %s", name, p.Label),
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// drawCrosshairLabels displays the labels of the values at the position of
+// the crosshair right-aligned at the top of the graph.
+// lc.mu must be held when calling this method.
+func (lc *LineChart) drawCrosshairLabels(cvs *canvas.Canvas) error {
+	x, ok := lc.crosshairX()
+	if !ok {
+		return nil
+	}
+	var names []string
+	for name, sv := range lc.series {
+		if sv.points[x].Label != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	var labels []string
+	for _, name := range names {
+		labels = append(labels, fmt.Sprintf("%s: %s", name, lc.series[name].points[x].Label))
+	}
+	text := strings.Join(labels, ", ")
+	start := lc.lastGraphAr.Max.X - runewidth.StringWidth(text)
+	if start < lc.lastGraphAr.Min.X {
+		start = lc.lastGraphAr.Min.X
+	}
+	if err := draw.Text(cvs, text, image.Point{start, lc.lastGraphAr.Min.Y},
+		draw.TextMaxX(lc.lastGraphAr.Max.X),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+	); err != nil {
+		return fmt.Errorf("failed to draw the labels of the values: %v", err)
+	}
+	return nil
+}
+
+// Tooltips returns the labels of the values, displayed when the mouse
+// pointer hovers over the values, see SeriesPoints.
+// Implements widgetapi.Tooltipper.
+func (lc *LineChart) Tooltips() []widgetapi.Tooltip {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	return append([]widgetapi.Tooltip(nil), lc.tooltips...)
+}
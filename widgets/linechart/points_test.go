@@ -0,0 +1,85 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linechart
+
+import (
+	"image"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+func TestSeriesPoints(t *testing.T) {
+	lc, err := New(Crosshair())
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	points := map[int]Point{
+		1: {CellOpts: []cell.Option{cell.FgColor(cell.ColorRed)}, Label: "deploy"},
+		2: {Label: "hidden"},
+		9: {Label: "out of range"},
+	}
+	if err := lc.Series("series", []float64{0, 5, math.NaN(), 10}, SeriesPoints(points)); err != nil {
+		t.Fatalf("Series => unexpected error: %v", err)
+	}
+	// The chart copies the points.
+	delete(points, 1)
+
+	ar := image.Rect(0, 0, 30, 10)
+	cvs := testcanvas.MustNew(ar)
+	if err := lc.Draw(cvs, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if got := countFgColor(lc, cvs, cell.ColorRed); got != 1 {
+		t.Errorf("Draw => %d cells marked with the point color, want 1", got)
+	}
+
+	tooltips := lc.Tooltips()
+	if len(tooltips) != 1 {
+		t.Fatalf("Tooltips => got %d tooltips, want 1 for the only visible labeled value: %v", len(tooltips), tooltips)
+	}
+	tt := tooltips[0]
+	if got, want := tt.Text, "series: deploy"; got != want {
+		t.Errorf("Tooltips => text %q, want %q", got, want)
+	}
+	if got, want := tt.Area.Size(), (image.Point{1, 1}); got != want {
+		t.Errorf("Tooltips => area size %v, want %v", got, want)
+	}
+	if got := testcanvas.MustCell(cvs, tt.Area.Min).Opts.FgColor; got != cell.ColorRed {
+		t.Errorf("Tooltips => area %v has color %v, want the cell of the marked value", tt.Area, got)
+	}
+
+	for _, m := range []*terminalapi.Mouse{
+		{Position: image.Point{mustColumn(t, lc, 1), lc.lastGraphAr.Min.Y}, Button: mouse.ButtonLeft},
+		{Position: image.Point{mustColumn(t, lc, 1), lc.lastGraphAr.Min.Y}, Button: mouse.ButtonRelease},
+	} {
+		if err := lc.Mouse(m); err != nil {
+			t.Fatalf("Mouse => unexpected error: %v", err)
+		}
+	}
+	cvs = testcanvas.MustNew(ar)
+	if err := lc.Draw(cvs, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if got, want := rowText(cvs, lc.lastGraphAr.Min.Y), "series: deploy"; !strings.HasSuffix(got, want) {
+		t.Errorf("Draw => top row %q, want it to end with %q", got, want)
+	}
+}
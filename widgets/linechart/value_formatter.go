@@ -15,7 +15,8 @@
 package linechart
 
 // value_formatter.go provides common implementations of ValueFormatter that can be
-// used with the YAxisFormattedValues() LineChart option.
+// used with the YAxisFormattedValues() and XAxisFormattedValues() LineChart
+// options.
 
 import (
 	"fmt"
@@ -0,0 +1,570 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package menu implements a menu bar widget with drop-down menus.
+package menu
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/area"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/private/wrap"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// CallbackFn is the function called when a menu item is activated.
+//
+// The callback function must be thread-safe as the mouse or keyboard events
+// that activate the items are processed in a separate goroutine.
+//
+// If the function returns an error, the widget will forward it back to the
+// termdash infrastructure which causes a panic, unless the user provided a
+// termdash.ErrorHandler.
+type CallbackFn func() error
+
+// Item is an item in the menu.
+//
+// Items in the menu bar either open a drop-down menu with their Items or run
+// their Action when activated. Items in the drop-down menus must have an
+// Action.
+type Item struct {
+	// Label is the text displayed for the item.
+	Label string
+
+	// Action is called when the item is activated.
+	Action CallbackFn
+
+	// Items are the items of the drop-down menu this item opens.
+	// Only valid on items in the menu bar.
+	Items []*Item
+}
+
+// Menu displays a menu bar with drop-down menus.
+//
+// The drop-down menus are drawn within the area of the widget below the menu
+// bar, the widget requests enough space to fit the tallest and the widest of
+// them.
+//
+// While the widget is focused, the arrow keys select the items, Enter opens
+// the drop-down menu or activates the selected item and Esc closes the open
+// drop-down menu. Clicking the left mouse button on an item opens its
+// drop-down menu or activates it, clicking anywhere else within the widget
+// closes the open drop-down menu.
+//
+// If the ContextMenu option was provided, clicking the right mouse button
+// within the widget opens the context menu at the mouse pointer. The arrow
+// keys and Enter select and activate its items the same way as in the
+// drop-down menus, Esc or a click outside of the context menu closes it.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Menu struct {
+	// mu protects the widget.
+	mu sync.Mutex
+
+	// items are the items in the menu bar.
+	items []*Item
+
+	// selected is the index of the selected item in the menu bar.
+	selected int
+	// open indicates if the drop-down menu of the selected item is open.
+	open bool
+	// context indicates if the context menu is open.
+	context bool
+	// contextAt is the position of the mouse click that opened the context
+	// menu.
+	contextAt image.Point
+	// entry is the index of the selected item in the open drop-down menu or
+	// in the open context menu.
+	entry int
+
+	// barAreas are the areas occupied by the items in the menu bar last time
+	// Draw() was called.
+	barAreas []image.Rectangle
+	// entriesAr is the area occupied by the items of the open drop-down menu
+	// or context menu last time Draw() was called. Doesn't include the border.
+	entriesAr image.Rectangle
+
+	// opts are the provided options.
+	opts *options
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+}
+
+// New returns a new Menu with the provided items in the menu bar.
+func New(items []*Item, opts ...Option) (*Menu, error) {
+	if err := validateItems(items); err != nil {
+		return nil, err
+	}
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := validateContextItems(opt.contextItems); err != nil {
+		return nil, err
+	}
+	return &Menu{
+		items: items,
+		opts:  opt,
+	}, nil
+}
+
+// validateItems validates the items of the menu.
+func validateItems(items []*Item) error {
+	if len(items) == 0 {
+		return errors.New("the menu must have at least one item")
+	}
+	for i, it := range items {
+		if err := validateLabel(it); err != nil {
+			return fmt.Errorf("invalid menu bar item #%d: %v", i, err)
+		}
+		if it.Action != nil && len(it.Items) > 0 {
+			return fmt.Errorf("invalid menu bar item #%d %q: it must have either an Action or Items, not both", i, it.Label)
+		}
+		if it.Action == nil && len(it.Items) == 0 {
+			return fmt.Errorf("invalid menu bar item #%d %q: it must have an Action or Items", i, it.Label)
+		}
+
+		for j, sub := range it.Items {
+			if err := validateLabel(sub); err != nil {
+				return fmt.Errorf("invalid item #%d in the drop-down menu of %q: %v", j, it.Label, err)
+			}
+			if sub.Action == nil {
+				return fmt.Errorf("invalid item #%d %q in the drop-down menu of %q: it must have an Action", j, sub.Label, it.Label)
+			}
+			if len(sub.Items) > 0 {
+				return fmt.Errorf("invalid item #%d %q in the drop-down menu of %q: nested drop-down menus aren't supported", j, sub.Label, it.Label)
+			}
+		}
+	}
+	return nil
+}
+
+// validateContextItems validates the items of the context menu.
+func validateContextItems(items []*Item) error {
+	for i, it := range items {
+		if err := validateLabel(it); err != nil {
+			return fmt.Errorf("invalid context menu item #%d: %v", i, err)
+		}
+		if it.Action == nil {
+			return fmt.Errorf("invalid context menu item #%d %q: it must have an Action", i, it.Label)
+		}
+		if len(it.Items) > 0 {
+			return fmt.Errorf("invalid context menu item #%d %q: nested drop-down menus aren't supported", i, it.Label)
+		}
+	}
+	return nil
+}
+
+// validateLabel validates the label of the item.
+func validateLabel(it *Item) error {
+	if it == nil {
+		return errors.New("the item cannot be nil")
+	}
+	if err := wrap.ValidText(it.Label); err != nil {
+		return fmt.Errorf("invalid label: %v", err)
+	}
+	if strings.ContainsRune(it.Label, '\n') {
+		return fmt.Errorf("invalid label %q: it cannot contain newline characters", it.Label)
+	}
+	return nil
+}
+
+// itemPadding is the number of empty cells on each side of the labels.
+const itemPadding = 1
+
+// itemWidth returns the number of cells needed to display the item.
+func itemWidth(it *Item) int {
+	return runewidth.StringWidth(it.Label) + 2*itemPadding
+}
+
+// dropDownSize returns the size of a drop-down menu with the items including
+// its border.
+func dropDownSize(items []*Item) image.Point {
+	var width int
+	for _, it := range items {
+		if w := itemWidth(it); w > width {
+			width = w
+		}
+	}
+	return image.Point{width + 2, len(items) + 2}
+}
+
+// Draw draws the Menu widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (m *Menu) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ar := cvs.Area()
+	barAr := image.Rect(ar.Min.X, ar.Min.Y, ar.Max.X, ar.Min.Y+1)
+	if err := cvs.SetAreaCells(barAr, ' ', cell.BgColor(m.opts.barColor)); err != nil {
+		return err
+	}
+
+	m.barAreas = nil
+	x := barAr.Min.X
+	for i, it := range m.items {
+		itemAr := image.Rect(x, barAr.Min.Y, x+itemWidth(it), barAr.Max.Y)
+		m.barAreas = append(m.barAreas, itemAr)
+		highlight := i == m.selected && (meta.Focused || m.open)
+		if err := m.drawItem(cvs, it, itemAr, highlight); err != nil {
+			return err
+		}
+		x = itemAr.Max.X
+	}
+
+	m.entriesAr = image.ZR
+	switch {
+	case m.context:
+		return m.drawContext(cvs)
+	case m.open:
+		return m.drawDropDown(cvs)
+	}
+	return nil
+}
+
+// drawItem draws the label of the item in the area.
+func (m *Menu) drawItem(cvs *canvas.Canvas, it *Item, ar image.Rectangle, highlight bool) error {
+	bg := m.opts.barColor
+	if highlight {
+		bg = m.opts.highlightedColor
+	}
+	if err := cvs.SetAreaCells(ar, ' ', cell.BgColor(bg)); err != nil {
+		return err
+	}
+	return draw.Text(cvs, it.Label, image.Point{ar.Min.X + itemPadding, ar.Min.Y},
+		draw.TextMaxX(ar.Max.X),
+		draw.TextCellOpts(
+			cell.FgColor(m.opts.textColor),
+			cell.BgColor(bg),
+		),
+	)
+}
+
+// drawDropDown draws the drop-down menu of the selected item below its
+// position in the menu bar. The drop-down menu is shifted to the left if it
+// doesn't fit.
+func (m *Menu) drawDropDown(cvs *canvas.Canvas) error {
+	items := m.items[m.selected].Items
+	size := dropDownSize(items)
+	start := m.barAreas[m.selected].Min.Add(image.Point{0, 1})
+	if maxX := cvs.Area().Max.X - size.X; start.X > maxX {
+		start.X = maxX
+	}
+	return m.drawEntries(cvs, items, image.Rectangle{start, start.Add(size)})
+}
+
+// drawContext draws the context menu with its top left corner at the mouse
+// pointer. The context menu is shifted to the left and up if it doesn't fit.
+func (m *Menu) drawContext(cvs *canvas.Canvas) error {
+	items := m.opts.contextItems
+	size := dropDownSize(items)
+	start := m.contextAt
+	if maxX := cvs.Area().Max.X - size.X; start.X > maxX {
+		start.X = maxX
+	}
+	if maxY := cvs.Area().Max.Y - size.Y; start.Y > maxY {
+		start.Y = maxY
+	}
+	return m.drawEntries(cvs, items, image.Rectangle{start, start.Add(size)})
+}
+
+// drawEntries draws the items inside of the border and highlights the
+// selected entry.
+func (m *Menu) drawEntries(cvs *canvas.Canvas, items []*Item, border image.Rectangle) error {
+	if err := cvs.SetAreaCells(border, ' ', cell.BgColor(m.opts.barColor)); err != nil {
+		return err
+	}
+	if err := draw.Border(cvs, border,
+		draw.BorderLineStyle(m.opts.borderStyle),
+		draw.BorderCellOpts(
+			cell.FgColor(m.opts.borderColor),
+			cell.BgColor(m.opts.barColor),
+		),
+	); err != nil {
+		return err
+	}
+
+	m.entriesAr = area.ExcludeBorder(border)
+	for i, sub := range items {
+		entryAr := image.Rect(m.entriesAr.Min.X, m.entriesAr.Min.Y+i, m.entriesAr.Max.X, m.entriesAr.Min.Y+i+1)
+		if err := m.drawItem(cvs, sub, entryAr, i == m.entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wrapIdx returns the index wrapped around so that it falls into the range
+// 0 <= idx < length.
+func wrapIdx(idx, length int) int {
+	return (idx%length + length) % length
+}
+
+// selectBar selects the item in the menu bar at the index, wrapping around.
+// The drop-down menu stays open if the newly selected item has one.
+// Caller must hold m.mu.
+func (m *Menu) selectBar(idx int) {
+	m.selected = wrapIdx(idx, len(m.items))
+	m.entry = 0
+	if len(m.items[m.selected].Items) == 0 {
+		m.open = false
+	}
+}
+
+// activateBar opens the drop-down menu of the selected item in the menu bar
+// or returns its action if it doesn't have a drop-down menu.
+// Caller must hold m.mu.
+func (m *Menu) activateBar() CallbackFn {
+	it := m.items[m.selected]
+	if it.Action != nil {
+		m.close()
+		return it.Action
+	}
+	m.open = true
+	m.entry = 0
+	return nil
+}
+
+// entries returns the items of the open drop-down menu or context menu.
+// Caller must hold m.mu.
+func (m *Menu) entries() []*Item {
+	if m.context {
+		return m.opts.contextItems
+	}
+	return m.items[m.selected].Items
+}
+
+// activateEntry closes the open drop-down menu or context menu and returns
+// the action of its item at the index.
+// Caller must hold m.mu.
+func (m *Menu) activateEntry(idx int) CallbackFn {
+	action := m.entries()[idx].Action
+	m.close()
+	return action
+}
+
+// openContext closes the open drop-down menu and opens the context menu at
+// the position.
+// Caller must hold m.mu.
+func (m *Menu) openContext(p image.Point) {
+	m.close()
+	m.context = true
+	m.contextAt = p
+}
+
+// close closes the open drop-down menu or context menu.
+// Caller must hold m.mu.
+func (m *Menu) close() {
+	m.open = false
+	m.context = false
+	m.entry = 0
+}
+
+// keyboard processes keyboard events.
+// Returns the action of the activated item or nil if no item was activated.
+func (m *Menu) keyboard(k *terminalapi.Keyboard) CallbackFn {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.markChanged()
+
+	if m.context {
+		return m.contextKeyboard(k)
+	}
+
+	switch k.Key {
+	case keyboard.KeyArrowLeft:
+		m.selectBar(m.selected - 1)
+
+	case keyboard.KeyArrowRight:
+		m.selectBar(m.selected + 1)
+
+	case keyboard.KeyArrowUp:
+		if m.open {
+			m.entry = wrapIdx(m.entry-1, len(m.items[m.selected].Items))
+		}
+
+	case keyboard.KeyArrowDown:
+		if m.open {
+			m.entry = wrapIdx(m.entry+1, len(m.items[m.selected].Items))
+		} else if len(m.items[m.selected].Items) > 0 {
+			m.activateBar()
+		}
+
+	case keyboard.KeyEnter:
+		if m.open {
+			return m.activateEntry(m.entry)
+		}
+		return m.activateBar()
+
+	case keyboard.KeyEsc:
+		m.close()
+	}
+	return nil
+}
+
+// contextKeyboard processes keyboard events while the context menu is open.
+// Returns the action of the activated item or nil if no item was activated.
+// Caller must hold m.mu.
+func (m *Menu) contextKeyboard(k *terminalapi.Keyboard) CallbackFn {
+	switch k.Key {
+	case keyboard.KeyArrowUp:
+		m.entry = wrapIdx(m.entry-1, len(m.opts.contextItems))
+
+	case keyboard.KeyArrowDown:
+		m.entry = wrapIdx(m.entry+1, len(m.opts.contextItems))
+
+	case keyboard.KeyEnter:
+		return m.activateEntry(m.entry)
+
+	case keyboard.KeyEsc:
+		m.close()
+	}
+	return nil
+}
+
+// Keyboard processes keyboard events.
+// Implements widgetapi.Widget.Keyboard.
+func (m *Menu) Keyboard(k *terminalapi.Keyboard) error {
+	if action := m.keyboard(k); action != nil {
+		// Mutex must be released when calling the callback.
+		// Users might call container methods from the callback like the
+		// Container.Update, see #205.
+		return action()
+	}
+	return nil
+}
+
+// mouse processes mouse events.
+// Returns the action of the activated item or nil if no item was activated.
+func (m *Menu) mouse(ev *terminalapi.Mouse) CallbackFn {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case ev.Button == mouse.ButtonRight && len(m.opts.contextItems) > 0:
+		m.openContext(ev.Position)
+		m.markChanged()
+		return nil
+
+	case ev.Button != mouse.ButtonLeft:
+		return nil
+	}
+	defer m.markChanged()
+
+	if m.context {
+		if ev.Position.In(m.entriesAr) {
+			return m.activateEntry(ev.Position.Y - m.entriesAr.Min.Y)
+		}
+		// A click outside of the context menu only closes it.
+		m.close()
+		return nil
+	}
+
+	for i, ar := range m.barAreas {
+		if !ev.Position.In(ar) {
+			continue
+		}
+		if i == m.selected && m.open {
+			m.close()
+			return nil
+		}
+		m.selected = i
+		return m.activateBar()
+	}
+
+	if ev.Position.In(m.entriesAr) {
+		return m.activateEntry(ev.Position.Y - m.entriesAr.Min.Y)
+	}
+	m.close()
+	return nil
+}
+
+// Mouse processes mouse events.
+// Implements widgetapi.Widget.Mouse.
+func (m *Menu) Mouse(ev *terminalapi.Mouse) error {
+	if action := m.mouse(ev); action != nil {
+		// Mutex must be released when calling the callback.
+		// Users might call container methods from the callback like the
+		// Container.Update, see #205.
+		return action()
+	}
+	return nil
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (m *Menu) SetNotifyFunc(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notify = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// m.mu must be held when calling this method.
+func (m *Menu) markChanged() {
+	if m.notify != nil {
+		m.notify()
+	}
+}
+
+// Options implements widgetapi.Widget.Options.
+func (m *Menu) Options() widgetapi.Options {
+	// No need to lock, as the items and the context menu items get fixed
+	// when New is called.
+
+	var barWidth int
+	minSize := image.Point{0, 1}
+	for _, it := range m.items {
+		barWidth += itemWidth(it)
+		if len(it.Items) == 0 {
+			continue
+		}
+		dd := dropDownSize(it.Items)
+		if dd.X > minSize.X {
+			minSize.X = dd.X
+		}
+		if h := dd.Y + 1; h > minSize.Y {
+			minSize.Y = h
+		}
+	}
+	if len(m.opts.contextItems) > 0 {
+		cm := dropDownSize(m.opts.contextItems)
+		if cm.X > minSize.X {
+			minSize.X = cm.X
+		}
+		if cm.Y > minSize.Y {
+			minSize.Y = cm.Y
+		}
+	}
+	if barWidth > minSize.X {
+		minSize.X = barWidth
+	}
+	return widgetapi.Options{
+		MinimumSize:  minSize,
+		WantKeyboard: widgetapi.KeyScopeFocused,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+}
@@ -0,0 +1,816 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package menu
+
+import (
+	"errors"
+	"image"
+	"sync"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// callbackTracker tracks which actions were called.
+type callbackTracker struct {
+	// called are the labels of the called actions in the order of the calls.
+	called []string
+
+	// mu protects the tracker.
+	mu sync.Mutex
+}
+
+// action returns an action that records the label when called.
+func (ct *callbackTracker) action(label string) CallbackFn {
+	return func() error {
+		ct.mu.Lock()
+		defer ct.mu.Unlock()
+
+		ct.called = append(ct.called, label)
+		return nil
+	}
+}
+
+// testItems returns the items used in the tests.
+func testItems(ct *callbackTracker) []*Item {
+	return []*Item{
+		{
+			Label: "File",
+			Items: []*Item{
+				{Label: "Open", Action: ct.action("Open")},
+				{Label: "Save", Action: ct.action("Save")},
+			},
+		},
+		{Label: "Quit", Action: ct.action("Quit")},
+		{
+			Label: "Help",
+			Items: []*Item{
+				{Label: "About", Action: ct.action("About")},
+			},
+		},
+	}
+}
+
+// testContextItems returns the context menu items used in the tests.
+func testContextItems(ct *callbackTracker) []*Item {
+	return []*Item{
+		{Label: "Copy", Action: ct.action("Copy")},
+		{Label: "Paste", Action: ct.action("Paste")},
+	}
+}
+
+// mustDrawBar draws the expected menu bar with the item at the index
+// highlighted. No item is highlighted if the index is negative.
+func mustDrawBar(cvs *canvas.Canvas, width, highlight int) {
+	testcanvas.MustSetAreaCells(cvs, image.Rect(0, 0, width, 1), ' ', cell.BgColor(cell.ColorNumber(DefaultBarColorNumber)))
+	x := 0
+	for i, label := range []string{"File", "Quit", "Help"} {
+		bg := cell.ColorNumber(DefaultBarColorNumber)
+		if i == highlight {
+			bg = cell.ColorNumber(DefaultHighlightedColorNumber)
+		}
+		testcanvas.MustSetAreaCells(cvs, image.Rect(x, 0, x+6, 1), ' ', cell.BgColor(bg))
+		testdraw.MustText(cvs, label, image.Point{x + 1, 0}, draw.TextCellOpts(
+			cell.FgColor(cell.ColorDefault),
+			cell.BgColor(bg),
+		))
+		x += 6
+	}
+}
+
+// mustDrawDropDown draws the expected drop-down menu with the top left corner
+// at the point and the entry at the index highlighted.
+func mustDrawDropDown(cvs *canvas.Canvas, start image.Point, labels []string, highlight int) {
+	var width int
+	for _, label := range labels {
+		if w := len(label) + 4; w > width {
+			width = w
+		}
+	}
+	border := image.Rect(start.X, start.Y, start.X+width, start.Y+len(labels)+2)
+	testcanvas.MustSetAreaCells(cvs, border, ' ', cell.BgColor(cell.ColorNumber(DefaultBarColorNumber)))
+	testdraw.MustBorder(cvs, border, draw.BorderCellOpts(
+		cell.FgColor(cell.ColorDefault),
+		cell.BgColor(cell.ColorNumber(DefaultBarColorNumber)),
+	))
+	for i, label := range labels {
+		bg := cell.ColorNumber(DefaultBarColorNumber)
+		if i == highlight {
+			bg = cell.ColorNumber(DefaultHighlightedColorNumber)
+		}
+		y := start.Y + 1 + i
+		testcanvas.MustSetAreaCells(cvs, image.Rect(start.X+1, y, start.X+width-1, y+1), ' ', cell.BgColor(bg))
+		testdraw.MustText(cvs, label, image.Point{start.X + 2, y}, draw.TextCellOpts(
+			cell.FgColor(cell.ColorDefault),
+			cell.BgColor(bg),
+		))
+	}
+}
+
+func TestNew(t *testing.T) {
+	noop := func() error { return nil }
+	tests := []struct {
+		desc    string
+		items   []*Item
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			desc:    "fails without items",
+			wantErr: true,
+		},
+		{
+			desc:    "fails on a nil item",
+			items:   []*Item{nil},
+			wantErr: true,
+		},
+		{
+			desc: "fails on an empty label",
+			items: []*Item{
+				{Action: noop},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on a label with a newline",
+			items: []*Item{
+				{Label: "a\nb", Action: noop},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on a menu bar item without Action and Items",
+			items: []*Item{
+				{Label: "File"},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on a menu bar item with both Action and Items",
+			items: []*Item{
+				{
+					Label:  "File",
+					Action: noop,
+					Items: []*Item{
+						{Label: "Open", Action: noop},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on a drop-down item without Action",
+			items: []*Item{
+				{
+					Label: "File",
+					Items: []*Item{
+						{Label: "Open"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on a nested drop-down menu",
+			items: []*Item{
+				{
+					Label: "File",
+					Items: []*Item{
+						{
+							Label:  "Recent",
+							Action: noop,
+							Items: []*Item{
+								{Label: "a.txt", Action: noop},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			desc:  "fails on a context menu item without Action",
+			items: testItems(&callbackTracker{}),
+			opts: []Option{
+				ContextMenu(&Item{Label: "Copy"}),
+			},
+			wantErr: true,
+		},
+		{
+			desc:  "fails on a context menu item with Items",
+			items: testItems(&callbackTracker{}),
+			opts: []Option{
+				ContextMenu(&Item{
+					Label:  "Copy",
+					Action: noop,
+					Items: []*Item{
+						{Label: "a.txt", Action: noop},
+					},
+				}),
+			},
+			wantErr: true,
+		},
+		{
+			desc:  "fails on a context menu item with an empty label",
+			items: testItems(&callbackTracker{}),
+			opts: []Option{
+				ContextMenu(&Item{Action: noop}),
+			},
+			wantErr: true,
+		},
+		{
+			desc:  "succeeds on valid items",
+			items: testItems(&callbackTracker{}),
+		},
+		{
+			desc:  "succeeds on valid context menu items",
+			items: testItems(&callbackTracker{}),
+			opts: []Option{
+				ContextMenu(testContextItems(&callbackTracker{})...),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := New(tc.items, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMenu(t *testing.T) {
+	tests := []struct {
+		desc         string
+		opts         []Option
+		context      bool
+		canvas       image.Rectangle
+		meta         *widgetapi.Meta
+		events       []terminalapi.Event
+		want         func(size image.Point) *faketerm.Terminal
+		wantCalled   []string
+		wantEventErr bool
+	}{
+		{
+			desc:   "draws the menu bar",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "highlights the selected item when focused",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{Focused: true},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "enter opens the drop-down menu",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{Focused: true},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, 0)
+				mustDrawDropDown(cvs, image.Point{0, 1}, []string{"Open", "Save"}, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "arrow keys select items in the open drop-down menu",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{Focused: true},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowUp},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, 0)
+				mustDrawDropDown(cvs, image.Point{0, 1}, []string{"Open", "Save"}, 1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "selecting an item without a drop-down menu closes it",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{Focused: true},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, 1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "left arrow moves the open drop-down menu and shifts it left if it doesn't fit",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{Focused: true},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, 2)
+				mustDrawDropDown(cvs, image.Point{9, 1}, []string{"About"}, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "esc closes the drop-down menu",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{Focused: true},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				&terminalapi.Keyboard{Key: keyboard.KeyEsc},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "enter activates the selected drop-down item and closes the menu",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{Focused: true},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCalled: []string{"Save"},
+		},
+		{
+			desc:   "enter activates a menu bar item without a drop-down menu",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{Focused: true},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, 1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCalled: []string{"Quit"},
+		},
+		{
+			desc:   "mouse click opens the drop-down menu",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{13, 0}, Button: mouse.ButtonLeft},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, 2)
+				mustDrawDropDown(cvs, image.Point{9, 1}, []string{"About"}, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "mouse click on the open item closes the drop-down menu",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{13, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{13, 0}, Button: mouse.ButtonLeft},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "mouse click outside of the menu closes the drop-down menu",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{15, 3}, Button: mouse.ButtonLeft},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "mouse click activates a drop-down item",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{3, 3}, Button: mouse.ButtonLeft},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCalled: []string{"Save"},
+		},
+		{
+			desc:   "ignores other mouse buttons",
+			canvas: image.Rect(0, 0, 18, 5),
+			meta:   &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 0}, Button: mouse.ButtonRight},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "right click opens the context menu at the mouse pointer",
+			context: true,
+			canvas:  image.Rect(0, 0, 18, 5),
+			meta:    &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{2, 1}, Button: mouse.ButtonRight},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				mustDrawDropDown(cvs, image.Point{2, 1}, []string{"Copy", "Paste"}, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "shifts the context menu left and up if it doesn't fit",
+			context: true,
+			canvas:  image.Rect(0, 0, 18, 5),
+			meta:    &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{15, 3}, Button: mouse.ButtonRight},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				mustDrawDropDown(cvs, image.Point{9, 1}, []string{"Copy", "Paste"}, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "right click closes the open drop-down menu",
+			context: true,
+			canvas:  image.Rect(0, 0, 18, 5),
+			meta:    &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{13, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{2, 1}, Button: mouse.ButtonRight},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				mustDrawDropDown(cvs, image.Point{2, 1}, []string{"Copy", "Paste"}, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "arrow keys and enter activate a context menu item",
+			context: true,
+			canvas:  image.Rect(0, 0, 18, 5),
+			meta:    &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{2, 1}, Button: mouse.ButtonRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowUp},
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCalled: []string{"Paste"},
+		},
+		{
+			desc:    "esc closes the context menu",
+			context: true,
+			canvas:  image.Rect(0, 0, 18, 5),
+			meta:    &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{2, 1}, Button: mouse.ButtonRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyEsc},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:    "mouse click activates a context menu item",
+			context: true,
+			canvas:  image.Rect(0, 0, 18, 5),
+			meta:    &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{2, 1}, Button: mouse.ButtonRight},
+				&terminalapi.Mouse{Position: image.Point{4, 3}, Button: mouse.ButtonLeft},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCalled: []string{"Paste"},
+		},
+		{
+			desc:    "mouse click outside of the context menu only closes it",
+			context: true,
+			canvas:  image.Rect(0, 0, 18, 5),
+			meta:    &widgetapi.Meta{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{2, 1}, Button: mouse.ButtonRight},
+				&terminalapi.Mouse{Position: image.Point{7, 0}, Button: mouse.ButtonLeft},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawBar(cvs, 18, -1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ct := &callbackTracker{}
+			opts := tc.opts
+			if tc.context {
+				opts = append(opts, ContextMenu(testContextItems(ct)...))
+			}
+			m, err := New(testItems(ct), opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+
+			{
+				// Draw once so mouse events are acceptable.
+				c, err := canvas.New(tc.canvas)
+				if err != nil {
+					t.Fatalf("canvas.New => unexpected error: %v", err)
+				}
+				if err := m.Draw(c, tc.meta); err != nil {
+					t.Fatalf("Draw => unexpected error: %v", err)
+				}
+			}
+
+			for _, ev := range tc.events {
+				switch e := ev.(type) {
+				case *terminalapi.Mouse:
+					if err := m.Mouse(e); err != nil {
+						t.Fatalf("Mouse => unexpected error: %v", err)
+					}
+					// Draw after each mouse event, so the areas of the
+					// items are up to date.
+					c, err := canvas.New(tc.canvas)
+					if err != nil {
+						t.Fatalf("canvas.New => unexpected error: %v", err)
+					}
+					if err := m.Draw(c, tc.meta); err != nil {
+						t.Fatalf("Draw => unexpected error: %v", err)
+					}
+
+				case *terminalapi.Keyboard:
+					if err := m.Keyboard(e); err != nil {
+						t.Fatalf("Keyboard => unexpected error: %v", err)
+					}
+
+				default:
+					t.Fatalf("unsupported event type: %T", ev)
+				}
+			}
+
+			c, err := canvas.New(tc.canvas)
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := m.Draw(c, tc.meta); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+
+			if diff := pretty.Compare(tc.wantCalled, ct.called); diff != "" {
+				t.Errorf("called actions => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMenuActionError(t *testing.T) {
+	m, err := New([]*Item{
+		{
+			Label: "Fail",
+			Action: func() error {
+				return errors.New("action failed")
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := m.Keyboard(&terminalapi.Keyboard{Key: keyboard.KeyEnter}); err == nil {
+		t.Errorf("Keyboard => got nil error, want the error returned by the action")
+	}
+}
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		desc  string
+		items []*Item
+		opts  []Option
+		want  widgetapi.Options
+	}{
+		{
+			desc:  "fits the menu bar and the tallest drop-down menu",
+			items: testItems(&callbackTracker{}),
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{18, 5},
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+			},
+		},
+		{
+			desc: "fits the widest drop-down menu",
+			items: []*Item{
+				{
+					Label: "F",
+					Items: []*Item{
+						{Label: "Open recent", Action: func() error { return nil }},
+					},
+				},
+			},
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{15, 4},
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+			},
+		},
+		{
+			desc: "only the menu bar without drop-down menus",
+			items: []*Item{
+				{Label: "Quit", Action: func() error { return nil }},
+			},
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{6, 1},
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+			},
+		},
+		{
+			desc: "fits the context menu",
+			items: []*Item{
+				{Label: "Quit", Action: func() error { return nil }},
+			},
+			opts: []Option{
+				ContextMenu(testContextItems(&callbackTracker{})...),
+			},
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{9, 4},
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			m, err := New(tc.items, tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+
+			got := m.Options()
+			if diff := pretty.Compare(tc.want, got); diff != "" {
+				t.Errorf("Options => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNotifies(t *testing.T) {
+	m, err := New(testItems(&callbackTracker{}))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	m.SetNotifyFunc(func() {
+		notified++
+	})
+
+	if err := m.Keyboard(&terminalapi.Keyboard{Key: keyboard.KeyArrowRight}); err != nil {
+		t.Fatalf("Keyboard => unexpected error: %v", err)
+	}
+	if err := m.Mouse(&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft}); err != nil {
+		t.Fatalf("Mouse => unexpected error: %v", err)
+	}
+	if err := m.Mouse(&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonRelease}); err != nil {
+		t.Fatalf("Mouse => unexpected error: %v", err)
+	}
+	if want := 2; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary menudemo shows the functionality of the menu widget.
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/widgets/menu"
+	"github.com/mum4k/termdash/widgets/text"
+)
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	log, err := text.New(text.RollContent())
+	if err != nil {
+		panic(err)
+	}
+
+	// record returns an action that logs the label of the activated item.
+	record := func(label string) menu.CallbackFn {
+		return func() error {
+			return log.Write(fmt.Sprintf("activated %q\n", label))
+		}
+	}
+
+	m, err := menu.New([]*menu.Item{
+		{
+			Label: "File",
+			Items: []*menu.Item{
+				{Label: "New", Action: record("New")},
+				{Label: "Open", Action: record("Open")},
+				{Label: "Save", Action: record("Save")},
+			},
+		},
+		{
+			Label: "Edit",
+			Items: []*menu.Item{
+				{Label: "Cut", Action: record("Cut")},
+				{Label: "Copy", Action: record("Copy")},
+				{Label: "Paste", Action: record("Paste")},
+			},
+		},
+		{
+			Label: "Quit",
+			Action: func() error {
+				cancel()
+				return nil
+			},
+		},
+	}, menu.ContextMenu(
+		&menu.Item{Label: "Undo", Action: record("Undo")},
+		&menu.Item{Label: "Redo", Action: record("Redo")},
+	))
+	if err != nil {
+		panic(err)
+	}
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("USE THE MENU TO QUIT"),
+		container.SplitHorizontal(
+			container.Top(
+				container.PlaceWidget(m),
+			),
+			container.Bottom(
+				container.Border(linestyle.Light),
+				container.BorderTitle("Activated items"),
+				container.PlaceWidget(log),
+			),
+			container.SplitFixed(6),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.RedrawInterval(100*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package menu
+
+// options.go contains configurable options for Menu.
+
+import (
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/linestyle"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	barColor         cell.Color
+	textColor        cell.Color
+	highlightedColor cell.Color
+	borderStyle      linestyle.LineStyle
+	borderColor      cell.Color
+	contextItems     []*Item
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		barColor:         cell.ColorNumber(DefaultBarColorNumber),
+		highlightedColor: cell.ColorNumber(DefaultHighlightedColorNumber),
+		borderStyle:      DefaultBorderStyle,
+	}
+}
+
+// DefaultBarColorNumber is the default color number for the BarColor option.
+const DefaultBarColorNumber = 236
+
+// BarColor sets the background color of the menu bar and of the drop-down
+// menus.
+// Defaults to DefaultBarColorNumber.
+func BarColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.barColor = c
+	})
+}
+
+// TextColor sets the color of the labels of the menu items.
+// Defaults to the default terminal color.
+func TextColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.textColor = c
+	})
+}
+
+// DefaultHighlightedColorNumber is the default color number for the
+// HighlightedColor option.
+const DefaultHighlightedColorNumber = 33
+
+// HighlightedColor sets the background color of the selected menu item.
+// Defaults to DefaultHighlightedColorNumber.
+func HighlightedColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.highlightedColor = c
+	})
+}
+
+// DefaultBorderStyle is the default value for the BorderStyle option.
+const DefaultBorderStyle = linestyle.Light
+
+// BorderStyle sets the style of the border around the drop-down menus.
+// Defaults to DefaultBorderStyle.
+func BorderStyle(ls linestyle.LineStyle) Option {
+	return option(func(opts *options) {
+		opts.borderStyle = ls
+	})
+}
+
+// BorderColor sets the color of the border around the drop-down menus.
+// Defaults to the default terminal color.
+func BorderColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.borderColor = c
+	})
+}
+
+// ContextMenu adds a context menu with the items, which opens at the mouse
+// pointer when the right mouse button is clicked within the widget. The items
+// must have an Action and cannot have drop-down menus of their own.
+// Defaults to no context menu.
+func ContextMenu(items ...*Item) Option {
+	return option(func(opts *options) {
+		opts.contextItems = items
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radiogroup
+
+// history.go contains code that records changes of the selection so they can
+// be undone.
+
+import (
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// selectChange is a change of the selected radio button made by the user.
+// Implements undo.Change and undo.Reporter.
+type selectChange struct {
+	// radioGroup is the changed radio group.
+	radioGroup *RadioGroup
+	// from and to are the indexes of the selected radio buttons before and
+	// after the change, -1 if none was selected.
+	from, to int
+}
+
+// Undo implements undo.Change.Undo.
+func (sc *selectChange) Undo() {
+	sc.radioGroup.restore(sc.from)
+}
+
+// Redo implements undo.Change.Redo.
+func (sc *selectChange) Redo() {
+	sc.radioGroup.restore(sc.to)
+}
+
+// Report implements undo.Reporter.Report.
+func (sc *selectChange) Report() error {
+	return sc.radioGroup.notify(sc.radioGroup.Selected())
+}
+
+// restore selects the radio button when a change is undone or redone.
+func (rg *RadioGroup) restore(selected int) {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	if selected < 0 {
+		rg.group.Clear()
+	} else {
+		// The index was valid when the change was recorded and the labels
+		// cannot change.
+		_ = rg.group.Set(selected, true)
+	}
+	rg.markChanged()
+}
+
+// record records the change on the stack provided with the History option.
+// Caller must not hold rg.mu, the stack calls back into the radio group when
+// the change is undone.
+func (rg *RadioGroup) record(c *selectChange) {
+	if rg.opts.history != nil {
+		rg.opts.history.Record(c)
+	}
+}
+
+// undoKeyboard undoes or redoes the changes recorded on the stack provided
+// with the History option if the key is one of the UndoKeys.
+// Returns true if the key was handled.
+// Caller must not hold rg.mu.
+func (rg *RadioGroup) undoKeyboard(k *terminalapi.Keyboard) (bool, error) {
+	h := rg.opts.history
+	if h == nil || (k.Key != rg.opts.undoKey && k.Key != rg.opts.redoKey) {
+		return false, nil
+	}
+
+	rg.mu.Lock()
+	disabled := rg.group.Disabled()
+	rg.mu.Unlock()
+	if disabled {
+		// A disabled widget ignores all keyboard events.
+		return true, nil
+	}
+
+	var err error
+	if k.Key == rg.opts.undoKey {
+		_, err = h.Undo()
+	} else {
+		_, err = h.Redo()
+	}
+	return true, err
+}
@@ -0,0 +1,184 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radiogroup
+
+// options.go contains configurable options for RadioGroup.
+
+import (
+	"fmt"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/undo"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	textColor        cell.Color
+	highlightedColor cell.Color
+	disabledColor    cell.Color
+	labelAlign       align.Horizontal
+	selected         *int
+	disabled         bool
+	onChange         ChangeFn
+	history          *undo.Stack
+	undoKey          keyboard.Key
+	redoKey          keyboard.Key
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.undoKey == o.redoKey {
+		return fmt.Errorf("invalid UndoKeys(undo:%v, redo:%v), the keys must be unique", o.undoKey, o.redoKey)
+	}
+	return nil
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		highlightedColor: cell.ColorNumber(DefaultHighlightedColorNumber),
+		disabledColor:    cell.ColorNumber(DefaultDisabledColorNumber),
+		labelAlign:       DefaultLabelAlign,
+		undoKey:          DefaultUndoKey,
+		redoKey:          DefaultRedoKey,
+	}
+}
+
+// TextColor sets the color of the radio buttons and their labels.
+// Defaults to the default terminal color.
+func TextColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.textColor = c
+	})
+}
+
+// DefaultHighlightedColorNumber is the default color number for the
+// HighlightedColor option.
+const DefaultHighlightedColorNumber = 33
+
+// HighlightedColor sets the background color of the radio button under the cursor
+// while the widget is focused.
+// Defaults to DefaultHighlightedColorNumber.
+func HighlightedColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.highlightedColor = c
+	})
+}
+
+// DefaultDisabledColorNumber is the default color number for the
+// DisabledColor option.
+const DefaultDisabledColorNumber = 244
+
+// DisabledColor sets the color of the radio buttons and their labels while the
+// widget is disabled.
+// Defaults to DefaultDisabledColorNumber.
+func DisabledColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.disabledColor = c
+	})
+}
+
+// DefaultLabelAlign is the default value for the LabelAlign option.
+const DefaultLabelAlign = align.HorizontalLeft
+
+// LabelAlign sets the horizontal alignment of the radio buttons and their labels
+// within the widget.
+// Defaults to DefaultLabelAlign.
+func LabelAlign(la align.Horizontal) Option {
+	return option(func(opts *options) {
+		opts.labelAlign = la
+	})
+}
+
+// Selected sets the radio button at the index as initially selected.
+// No radio button is selected by default.
+func Selected(i int) Option {
+	return option(func(opts *options) {
+		opts.selected = &i
+	})
+}
+
+// Disabled makes the widget initially disabled. A disabled widget is drawn
+// using the DisabledColor and ignores all keyboard and mouse events.
+func Disabled() Option {
+	return option(func(opts *options) {
+		opts.disabled = true
+	})
+}
+
+// ChangeFn is called when the user selects a radio button, the argument is the
+// index of the selected radio button.
+//
+// The callback function must be thread-safe as the keyboard and mouse events
+// that select the radio buttons are processed in a separate goroutine.
+//
+// If the function returns an error, the widget will forward it back to the
+// termdash infrastructure which causes a panic, unless the user provided a
+// termdash.ErrorHandler.
+type ChangeFn func(selected int) error
+
+// OnChange sets a function that is called when the user selects a different
+// radio button. The function isn't called when the selection is changed by
+// calling the methods of RadioGroup.
+func OnChange(fn ChangeFn) Option {
+	return option(func(opts *options) {
+		opts.onChange = fn
+	})
+}
+
+// History records the changes made by the user on the undo stack, so that the
+// user can undo and redo them, see UndoKeys. Widgets that share the stack,
+// e.g. all the widgets of a form, have their changes undone in the order the
+// user made them. Changes made by calling the methods of RadioGroup aren't
+// recorded. Undoing or redoing a change calls the OnChange function.
+// The changes aren't recorded by default.
+func History(s *undo.Stack) Option {
+	return option(func(opts *options) {
+		opts.history = s
+	})
+}
+
+// The default keys that undo and redo changes.
+const (
+	DefaultUndoKey = keyboard.KeyCtrlZ
+	DefaultRedoKey = keyboard.KeyCtrlY
+)
+
+// UndoKeys configures the keyboard keys that undo and redo the changes
+// recorded on the stack provided with the History option. The keys must be
+// unique.
+// Defaults to DefaultUndoKey and DefaultRedoKey.
+func UndoKeys(undoKey, redoKey keyboard.Key) Option {
+	return option(func(opts *options) {
+		opts.undoKey = undoKey
+		opts.redoKey = redoKey
+	})
+}
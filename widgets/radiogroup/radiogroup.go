@@ -0,0 +1,244 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package radiogroup implements a widget that displays a group of radio
+// buttons.
+package radiogroup
+
+import (
+	"sync"
+
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/choice"
+	"github.com/mum4k/termdash/selection"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// The markers drawn in front of the labels.
+const (
+	selectedMarker   = "(*) "
+	unselectedMarker = "( ) "
+)
+
+// RadioGroup displays a group of radio buttons with labels, one per line. At
+// most one of the radio buttons is selected at any time.
+//
+// While the widget is focused, the up and down arrow keys move the cursor and
+// the space key selects the radio button under the cursor. Clicking the left
+// mouse button on a radio button or its label also selects it.
+// The changes can be undone, see the History option.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type RadioGroup struct {
+	// mu protects the widget.
+	mu sync.Mutex
+
+	// group tracks the state of the radio buttons.
+	group *choice.Group
+
+	// opts are the provided options.
+	opts *options
+
+	// redraw is called when the content changes, see SetNotifyFunc.
+	redraw func()
+}
+
+// New returns a new RadioGroup with one radio button for each of the labels.
+func New(labels []string, opts ...Option) (*RadioGroup, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+
+	group, err := choice.New(choice.Config{
+		Labels:           labels,
+		Mode:             selection.ModeSingle,
+		Selected:         selectedMarker,
+		Unselected:       unselectedMarker,
+		Align:            opt.labelAlign,
+		TextColor:        opt.textColor,
+		HighlightedColor: opt.highlightedColor,
+		DisabledColor:    opt.disabledColor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opt.selected != nil {
+		if err := group.Set(*opt.selected, true); err != nil {
+			return nil, err
+		}
+	}
+	group.SetDisabled(opt.disabled)
+	return &RadioGroup{
+		group: group,
+		opts:  opt,
+	}, nil
+}
+
+// Selected returns the index of the selected radio button or -1 if none is
+// selected.
+func (rg *RadioGroup) Selected() int {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	return rg.selected()
+}
+
+// selected returns the index of the selected radio button or -1 if none is
+// selected.
+// Caller must hold rg.mu.
+func (rg *RadioGroup) selected() int {
+	sel := rg.group.Selected()
+	if len(sel) == 0 {
+		return -1
+	}
+	return sel[0]
+}
+
+// Select selects the radio button at the index.
+func (rg *RadioGroup) Select(i int) error {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	if err := rg.group.Set(i, true); err != nil {
+		return err
+	}
+	rg.markChanged()
+	return nil
+}
+
+// Clear deselects the selected radio button.
+func (rg *RadioGroup) Clear() {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	rg.group.Clear()
+	rg.markChanged()
+}
+
+// SetDisabled disables or enables the widget. See the Disabled option.
+func (rg *RadioGroup) SetDisabled(disabled bool) {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	rg.group.SetDisabled(disabled)
+	rg.markChanged()
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (rg *RadioGroup) SetNotifyFunc(fn func()) {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+	rg.redraw = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// rg.mu must be held when calling this method.
+func (rg *RadioGroup) markChanged() {
+	if rg.redraw != nil {
+		rg.redraw()
+	}
+}
+
+// Draw draws the RadioGroup widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (rg *RadioGroup) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	return rg.group.Draw(cvs, meta.Focused)
+}
+
+// keyboard processes keyboard events.
+// Returns the change of the selection or nil if it didn't change.
+func (rg *RadioGroup) keyboard(k *terminalapi.Keyboard) (*selectChange, error) {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	from := rg.selected()
+	changed, err := rg.group.Keyboard(k)
+	if err != nil || !changed {
+		return nil, err
+	}
+	rg.markChanged()
+	return &selectChange{radioGroup: rg, from: from, to: rg.selected()}, nil
+}
+
+// Keyboard processes keyboard events.
+// Implements widgetapi.Widget.Keyboard.
+func (rg *RadioGroup) Keyboard(k *terminalapi.Keyboard) error {
+	if handled, err := rg.undoKeyboard(k); handled {
+		return err
+	}
+
+	c, err := rg.keyboard(k)
+	if err != nil || c == nil {
+		return err
+	}
+	rg.record(c)
+	return rg.notify(c.to)
+}
+
+// mouse processes mouse events.
+// Returns the change of the selection or nil if it didn't change.
+func (rg *RadioGroup) mouse(m *terminalapi.Mouse) (*selectChange, error) {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	from := rg.selected()
+	changed, err := rg.group.Mouse(m)
+	if err != nil || !changed {
+		return nil, err
+	}
+	rg.markChanged()
+	return &selectChange{radioGroup: rg, from: from, to: rg.selected()}, nil
+}
+
+// Mouse processes mouse events.
+// Implements widgetapi.Widget.Mouse.
+func (rg *RadioGroup) Mouse(m *terminalapi.Mouse) error {
+	c, err := rg.mouse(m)
+	if err != nil || c == nil {
+		return err
+	}
+	rg.record(c)
+	return rg.notify(c.to)
+}
+
+// notify calls the OnChange callback if one was provided.
+func (rg *RadioGroup) notify(selected int) error {
+	if rg.opts.onChange == nil {
+		return nil
+	}
+	// Mutex must be released when calling the callback.
+	// Users might call container methods from the callback like the
+	// Container.Update, see #205.
+	return rg.opts.onChange(selected)
+}
+
+// Options implements widgetapi.Widget.Options.
+func (rg *RadioGroup) Options() widgetapi.Options {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	return widgetapi.Options{
+		MinimumSize:  rg.group.MinimumSize(),
+		WantKeyboard: widgetapi.KeyScopeFocused,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+}
@@ -0,0 +1,451 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radiogroup
+
+import (
+	"errors"
+	"image"
+	"sync"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/undo"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// callbackTracker tracks the calls of the OnChange callback.
+type callbackTracker struct {
+	// wantErr when set to true, makes callback return an error.
+	wantErr bool
+
+	// calls are the arguments of the calls in the order of the calls.
+	calls []int
+
+	// mu protects the tracker.
+	mu sync.Mutex
+}
+
+// onChange is the callback function called OnChange.
+func (ct *callbackTracker) onChange(selected int) error {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	if ct.wantErr {
+		return errors.New("ct.wantErr set to true")
+	}
+	ct.calls = append(ct.calls, selected)
+	return nil
+}
+
+func TestRadioGroup(t *testing.T) {
+	tests := []struct {
+		desc         string
+		labels       []string
+		opts         []Option
+		callback     *callbackTracker
+		events       []terminalapi.Event
+		meta         *widgetapi.Meta
+		want         func(size image.Point) *faketerm.Terminal
+		wantSelected int
+		wantCallback *callbackTracker
+		wantNewErr   bool
+		wantEventErr bool
+	}{
+		{
+			desc:       "fails without labels",
+			wantNewErr: true,
+		},
+		{
+			desc:   "fails on an invalid index in Selected",
+			labels: []string{"a", "b"},
+			opts: []Option{
+				Selected(-1),
+			},
+			wantNewErr: true,
+		},
+		{
+			desc:   "nothing is selected by default",
+			labels: []string{"a", "b"},
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "( ) a", image.Point{0, 0})
+				testdraw.MustText(cvs, "( ) b", image.Point{0, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantSelected: -1,
+		},
+		{
+			desc:   "draws the initially selected radio button",
+			labels: []string{"a", "b"},
+			opts: []Option{
+				Selected(1),
+			},
+			meta: &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "( ) a", image.Point{0, 0})
+				testdraw.MustText(cvs, "(*) b", image.Point{0, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantSelected: 1,
+		},
+		{
+			desc:     "space selects the radio button under the cursor and calls the callback",
+			labels:   []string{"a", "b"},
+			callback: &callbackTracker{},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: ' '},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+				&terminalapi.Keyboard{Key: ' '},
+				&terminalapi.Keyboard{Key: ' '},
+			},
+			meta: &widgetapi.Meta{Focused: true},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "( ) a", image.Point{0, 0})
+				testdraw.MustText(cvs, "(*) b", image.Point{0, 1}, draw.TextCellOpts(
+					cell.FgColor(cell.ColorDefault),
+					cell.BgColor(cell.ColorNumber(DefaultHighlightedColorNumber)),
+				))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantSelected: 1,
+			wantCallback: &callbackTracker{
+				calls: []int{0, 1},
+			},
+		},
+		{
+			desc:     "mouse click selects the radio button",
+			labels:   []string{"a", "b"},
+			opts:     []Option{Selected(0)},
+			callback: &callbackTracker{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{4, 1}, Button: mouse.ButtonLeft},
+			},
+			meta: &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "( ) a", image.Point{0, 0})
+				testdraw.MustText(cvs, "(*) b", image.Point{0, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantSelected: 1,
+			wantCallback: &callbackTracker{
+				calls: []int{1},
+			},
+		},
+		{
+			desc:   "disabled widget ignores events",
+			labels: []string{"a", "b"},
+			opts: []Option{
+				Disabled(),
+			},
+			callback: &callbackTracker{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft},
+			},
+			meta: &widgetapi.Meta{Focused: true},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				opts := draw.TextCellOpts(cell.FgColor(cell.ColorNumber(DefaultDisabledColorNumber)))
+				testdraw.MustText(cvs, "( ) a", image.Point{0, 0}, opts)
+				testdraw.MustText(cvs, "( ) b", image.Point{0, 1}, opts)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantSelected: -1,
+			wantCallback: &callbackTracker{},
+		},
+		{
+			desc:   "forwards errors from the callback",
+			labels: []string{"a", "b"},
+			callback: &callbackTracker{
+				wantErr: true,
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: ' '},
+			},
+			wantEventErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			gotCallback := tc.callback
+			if gotCallback != nil {
+				tc.opts = append(tc.opts, OnChange(gotCallback.onChange))
+			}
+
+			rg, err := New(tc.labels, tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			c, err := canvas.New(image.Rect(0, 0, 8, 2))
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			// Draw once so mouse events are acceptable.
+			if err := rg.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			for i, ev := range tc.events {
+				var err error
+				switch e := ev.(type) {
+				case *terminalapi.Keyboard:
+					err = rg.Keyboard(e)
+				case *terminalapi.Mouse:
+					err = rg.Mouse(e)
+				default:
+					t.Fatalf("unsupported event type: %T", ev)
+				}
+				// Only the last event in test cases is the one that can fail.
+				if i == len(tc.events)-1 {
+					if (err != nil) != tc.wantEventErr {
+						t.Errorf("event %v => unexpected error: %v, wantEventErr: %v", ev, err, tc.wantEventErr)
+					}
+					if err != nil {
+						return
+					}
+				} else if err != nil {
+					t.Fatalf("event %v => unexpected error: %v", ev, err)
+				}
+			}
+
+			c, err = canvas.New(image.Rect(0, 0, 8, 2))
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := rg.Draw(c, tc.meta); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+
+			if got := rg.Selected(); got != tc.wantSelected {
+				t.Errorf("Selected => %d, want %d", got, tc.wantSelected)
+			}
+			if diff := pretty.Compare(tc.wantCallback, gotCallback); diff != "" {
+				t.Errorf("ChangeFn => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	ct := &callbackTracker{}
+	rg, err := New([]string{"a", "b", "c"}, OnChange(ct.onChange))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	if err := rg.Select(3); err == nil {
+		t.Errorf("Select(3) => got nil error, want an error for an invalid index")
+	}
+	for _, i := range []int{0, 2} {
+		if err := rg.Select(i); err != nil {
+			t.Fatalf("Select(%d) => unexpected error: %v", i, err)
+		}
+	}
+	if got, want := rg.Selected(), 2; got != want {
+		t.Errorf("Selected => %d, want %d", got, want)
+	}
+
+	rg.Clear()
+	if got, want := rg.Selected(), -1; got != want {
+		t.Errorf("Selected after Clear => %d, want %d", got, want)
+	}
+	if len(ct.calls) != 0 {
+		t.Errorf("Select and Clear called the callback %d times, want no calls", len(ct.calls))
+	}
+}
+
+func TestOptions(t *testing.T) {
+	rg, err := New([]string{"a", "long"})
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	want := widgetapi.Options{
+		MinimumSize:  image.Point{8, 2},
+		WantKeyboard: widgetapi.KeyScopeFocused,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+	if diff := pretty.Compare(want, rg.Options()); diff != "" {
+		t.Errorf("Options => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestNotifies(t *testing.T) {
+	rg, err := New([]string{"a", "b"})
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	rg.SetNotifyFunc(func() {
+		notified++
+	})
+
+	if err := rg.Select(1); err != nil {
+		t.Fatalf("Select => unexpected error: %v", err)
+	}
+	if err := rg.Select(2); err == nil {
+		t.Fatalf("Select => expected an error for an invalid index")
+	}
+	rg.Clear()
+	rg.SetDisabled(false)
+	if err := rg.Keyboard(&terminalapi.Keyboard{Key: keyboard.KeySpace}); err != nil {
+		t.Fatalf("Keyboard => unexpected error: %v", err)
+	}
+	if want := 4; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
+
+func TestUndo(t *testing.T) {
+	tests := []struct {
+		desc       string
+		opts       []Option
+		events     []*terminalapi.Keyboard
+		want       int
+		wantCalls  []int
+		wantNewErr bool
+	}{
+		{
+			desc: "fails on duplicate UndoKeys",
+			opts: []Option{
+				UndoKeys(keyboard.KeyCtrlZ, keyboard.KeyCtrlZ),
+			},
+			wantNewErr: true,
+		},
+		{
+			desc: "undoes changes one by one",
+			events: []*terminalapi.Keyboard{
+				{Key: keyboard.KeySpace},
+				{Key: keyboard.KeyArrowDown},
+				{Key: keyboard.KeySpace},
+				{Key: DefaultUndoKey},
+			},
+			want:      0,
+			wantCalls: []int{0, 1, 0},
+		},
+		{
+			desc: "undoes the first selection",
+			events: []*terminalapi.Keyboard{
+				{Key: keyboard.KeySpace},
+				{Key: DefaultUndoKey},
+			},
+			want:      -1,
+			wantCalls: []int{0, -1},
+		},
+		{
+			desc: "redoes an undone change",
+			events: []*terminalapi.Keyboard{
+				{Key: keyboard.KeySpace},
+				{Key: keyboard.KeyArrowDown},
+				{Key: keyboard.KeySpace},
+				{Key: DefaultUndoKey},
+				{Key: DefaultRedoKey},
+			},
+			want:      1,
+			wantCalls: []int{0, 1, 0, 1},
+		},
+		{
+			desc: "uses custom UndoKeys",
+			opts: []Option{
+				UndoKeys('u', 'r'),
+			},
+			events: []*terminalapi.Keyboard{
+				{Key: keyboard.KeySpace},
+				{Key: DefaultUndoKey},
+				{Key: 'u'},
+			},
+			want:      -1,
+			wantCalls: []int{0, -1},
+		},
+		{
+			desc: "disabled widget ignores the UndoKeys",
+			opts: []Option{
+				Selected(1),
+				Disabled(),
+			},
+			events: []*terminalapi.Keyboard{
+				{Key: DefaultUndoKey},
+			},
+			want: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			h, err := undo.New()
+			if err != nil {
+				t.Fatalf("undo.New => unexpected error: %v", err)
+			}
+			ct := &callbackTracker{}
+			opts := append([]Option{History(h), OnChange(ct.onChange)}, tc.opts...)
+			rg, err := New([]string{"a", "b", "c"}, opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, k := range tc.events {
+				if err := rg.Keyboard(k); err != nil {
+					t.Fatalf("Keyboard => unexpected error: %v", err)
+				}
+			}
+			if got := rg.Selected(); got != tc.want {
+				t.Errorf("Selected => %d, want %d", got, tc.want)
+			}
+			if diff := pretty.Compare(tc.wantCalls, ct.calls); diff != "" {
+				t.Errorf("OnChange => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rate
+
+// options.go contains configurable options for Rate.
+
+import (
+	"fmt"
+
+	"github.com/mum4k/termdash/cell"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	label         string
+	labelCellOpts []cell.Option
+	rateCellOpts  []cell.Option
+	unit          string
+	binary        bool
+	decimals      int
+	history       int
+	color         cell.Color
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		decimals: DefaultDecimals,
+		history:  DefaultHistory,
+		color:    DefaultColor,
+	}
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if min := 0; o.decimals < min {
+		return fmt.Errorf("invalid Decimals(%d), must be %d <= value", o.decimals, min)
+	}
+	if min := 1; o.history < min {
+		return fmt.Errorf("invalid History(%d), must be at least %d", o.history, min)
+	}
+	return nil
+}
+
+// Label sets the text displayed before the rate.
+func Label(text string, cOpts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.label = text
+		o.labelCellOpts = cOpts
+	})
+}
+
+// RateCellOpts sets the cell options of the displayed rate.
+func RateCellOpts(cOpts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.rateCellOpts = cOpts
+	})
+}
+
+// Unit sets the unit of the counter, e.g. "B" for a counter of bytes. The
+// rate is displayed with the unit prefixed by a metric prefix that keeps the
+// number small, e.g. "1.5 kB/s".
+// Defaults to no unit.
+func Unit(unit string) Option {
+	return option(func(o *options) {
+		o.unit = unit
+	})
+}
+
+// BinaryPrefixes scales the rate by powers of 1024 and displays it with the
+// binary prefixes, e.g. "1.5 KiB/s".
+// Defaults to scaling by powers of 1000.
+func BinaryPrefixes() Option {
+	return option(func(o *options) {
+		o.binary = true
+	})
+}
+
+// DefaultDecimals is the default value for the Decimals option.
+const DefaultDecimals = 1
+
+// Decimals sets the number of decimal places of the displayed rate.
+// Defaults to DefaultDecimals.
+func Decimals(n int) Option {
+	return option(func(o *options) {
+		o.decimals = n
+	})
+}
+
+// DefaultHistory is the default value for the History option.
+const DefaultHistory = 300
+
+// History sets the maximum number of rates kept for the sparkline, the
+// oldest rates are forgotten first.
+// Defaults to DefaultHistory.
+func History(rates int) Option {
+	return option(func(o *options) {
+		o.history = rates
+	})
+}
+
+// DefaultColor is the default value for the Color option.
+const DefaultColor = cell.ColorGreen
+
+// Color sets the color of the sparkline.
+// Defaults to DefaultColor.
+func Color(c cell.Color) Option {
+	return option(func(o *options) {
+		o.color = c
+	})
+}
@@ -0,0 +1,266 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rate implements a widget that displays the rate at which a counter
+// increases.
+package rate
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// timeNow can be replaced in tests.
+var timeNow = time.Now
+
+// sparks are the characters used to draw the sparkline.
+var sparks = []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// Rate displays the rate per second at which a monotonically increasing
+// counter grows, e.g. the number of bytes sent over a connection. The
+// callers provide the raw values of the counter and the widget computes the
+// rate from the differences between them.
+//
+// The first row displays the label and the last rate scaled to a metric
+// prefix of its unit, the rows below display a sparkline of the recent
+// rates.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Rate struct {
+	// last is the last value of the counter and lastAt is when it was
+	// provided. Valid only if started is true.
+	last    float64
+	lastAt  time.Time
+	started bool
+
+	// rates are the computed rates, the oldest first.
+	rates []float64
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the Rate.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new Rate.
+func New(opts ...Option) (*Rate, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Rate{
+		opts: opt,
+	}, nil
+}
+
+// Update provides the current value of the counter. The rate is computed
+// from the difference to the previous value and the time that passed since
+// it was provided, so the first value only starts the measurement.
+//
+// A value smaller than the previous one is treated as a reset of the
+// counter to zero, e.g. when the process that maintains it restarts.
+// Values provided at the same time as the previous value are merged with
+// the next one. The value must be a finite non-negative number.
+func (r *Rate) Update(value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) || value < 0 {
+		return fmt.Errorf("invalid counter value %v, must be a finite non-negative number", value)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := timeNow()
+	if !r.started {
+		r.last = value
+		r.lastAt = now
+		r.started = true
+		return nil
+	}
+	elapsed := now.Sub(r.lastAt)
+	if elapsed <= 0 {
+		// The counter is cumulative, so the increase is accounted for when
+		// the next value arrives.
+		return nil
+	}
+
+	delta := value - r.last
+	if delta < 0 {
+		// The counter was reset.
+		delta = value
+	}
+	r.last = value
+	r.lastAt = now
+	r.rates = append(r.rates, delta/elapsed.Seconds())
+	if over := len(r.rates) - r.opts.history; over > 0 {
+		r.rates = r.rates[over:]
+	}
+	if r.notify != nil {
+		r.notify()
+	}
+	return nil
+}
+
+// Rate returns the last computed rate per second. Returns false if fewer
+// than two values of the counter were provided.
+func (r *Rate) Rate() (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.rates) == 0 {
+		return 0, false
+	}
+	return r.rates[len(r.rates)-1], true
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (r *Rate) SetNotifyFunc(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notify = fn
+}
+
+// metricPrefixes and binaryPrefixes are the prefixes of the scaled units.
+var (
+	metricPrefixes = []string{"", "k", "M", "G", "T", "P", "E"}
+	binaryPrefixes = []string{"", "Ki", "Mi", "Gi", "Ti", "Pi", "Ei"}
+)
+
+// format formats the rate per second with the unit.
+func format(rate float64, unit string, binary bool, decimals int) string {
+	base, prefixes := 1000.0, metricPrefixes
+	if binary {
+		base, prefixes = 1024.0, binaryPrefixes
+	}
+	i := 0
+	for math.Abs(rate) >= base && i < len(prefixes)-1 {
+		rate /= base
+		i++
+	}
+
+	u := prefixes[i] + unit
+	if unit != "" {
+		u = " " + u
+	}
+	return fmt.Sprintf("%.*f%s/s", decimals, rate, u)
+}
+
+// Draw draws the Rate widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (r *Rate) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ar := cvs.Area()
+	x := 0
+	if r.opts.label != "" {
+		if err := draw.Text(cvs, r.opts.label, image.Point{0, 0},
+			draw.TextMaxX(ar.Dx()),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(r.opts.labelCellOpts...),
+		); err != nil {
+			return err
+		}
+		// One cell gap between the label and the rate.
+		x = runewidth.StringWidth(r.opts.label) + 1
+	}
+	if len(r.rates) > 0 && x < ar.Dx() {
+		text := format(r.rates[len(r.rates)-1], r.opts.unit, r.opts.binary, r.opts.decimals)
+		if err := draw.Text(cvs, text, image.Point{x, 0},
+			draw.TextMaxX(ar.Dx()),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(r.opts.rateCellOpts...),
+		); err != nil {
+			return err
+		}
+	}
+
+	if ar.Dy() > 1 {
+		return r.drawSparkLine(cvs, image.Rect(0, 1, ar.Dx(), ar.Dy()))
+	}
+	return nil
+}
+
+// drawSparkLine draws the sparkline of the most recent rates that fit into
+// the area, the last rate on the right.
+// The caller must hold r.mu.
+func (r *Rate) drawSparkLine(cvs *canvas.Canvas, ar image.Rectangle) error {
+	rates := r.rates
+	if len(rates) > ar.Dx() {
+		rates = rates[len(rates)-ar.Dx():]
+	}
+	max := 0.0
+	for _, v := range rates {
+		if v > max {
+			max = v
+		}
+	}
+	if max == 0 {
+		return nil
+	}
+
+	x := ar.Max.X - len(rates)
+	for _, v := range rates {
+		// The number of the smallest sparks needed to represent the value.
+		elements := int(math.Round(v / max * float64(ar.Dy()*len(sparks))))
+		y := ar.Max.Y - 1
+		for ; elements > 0; elements -= len(sparks) {
+			s := sparks[len(sparks)-1]
+			if elements < len(sparks) {
+				s = sparks[elements-1]
+			}
+			if _, err := cvs.SetCell(image.Point{x, y}, s, cell.FgColor(r.opts.color)); err != nil {
+				return err
+			}
+			y--
+		}
+		x++
+	}
+	return nil
+}
+
+// Keyboard input isn't supported on the Rate widget.
+func (*Rate) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the Rate widget doesn't support keyboard events")
+}
+
+// Mouse input isn't supported on the Rate widget.
+func (*Rate) Mouse(m *terminalapi.Mouse) error {
+	return errors.New("the Rate widget doesn't support mouse events")
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*Rate) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeNone,
+	}
+}
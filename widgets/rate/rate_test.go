@@ -0,0 +1,286 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rate
+
+import (
+	"image"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// update is a value of the counter provided after some time elapsed.
+type update struct {
+	elapsed time.Duration
+	value   float64
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		desc       string
+		opts       []Option
+		updates    []update
+		size       image.Point
+		want       func(size image.Point) *faketerm.Terminal
+		wantRate   float64
+		wantOK     bool
+		wantNewErr bool
+		wantErr    bool
+	}{
+		{
+			desc:       "fails on negative Decimals",
+			opts:       []Option{Decimals(-1)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on zero History",
+			opts:       []Option{History(0)},
+			wantNewErr: true,
+		},
+		{
+			desc: "fails on negative value",
+			updates: []update{
+				{value: -1},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails on NaN value",
+			updates: []update{
+				{value: math.NaN()},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "the first value only starts the measurement",
+			opts: []Option{Label("tx")},
+			updates: []update{
+				{value: 100},
+			},
+			size: image.Point{10, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "tx", image.Point{0, 0})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "displays the rate and the sparkline",
+			opts: []Option{
+				Label("tx"),
+				Unit("B"),
+				RateCellOpts(cell.FgColor(cell.ColorBlue)),
+			},
+			updates: []update{
+				{value: 0},
+				{elapsed: time.Second, value: 1500},
+				{elapsed: 2 * time.Second, value: 7500},
+			},
+			size: image.Point{20, 3},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "tx", image.Point{0, 0})
+				testdraw.MustText(cvs, "3.0 kB/s", image.Point{3, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorBlue)))
+				green := cell.FgColor(cell.ColorGreen)
+				testcanvas.MustSetCell(cvs, image.Point{18, 2}, '█', green)
+				testcanvas.MustSetCell(cvs, image.Point{19, 2}, '█', green)
+				testcanvas.MustSetCell(cvs, image.Point{19, 1}, '█', green)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantRate: 3000,
+			wantOK:   true,
+		},
+		{
+			desc: "treats a decrease as a reset of the counter",
+			opts: []Option{Color(cell.ColorRed)},
+			updates: []update{
+				{value: 1000},
+				{elapsed: time.Second, value: 2000},
+				{elapsed: time.Second, value: 250},
+			},
+			size: image.Point{10, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "250.0/s", image.Point{0, 0})
+				red := cell.FgColor(cell.ColorRed)
+				testcanvas.MustSetCell(cvs, image.Point{8, 1}, '█', red)
+				testcanvas.MustSetCell(cvs, image.Point{9, 1}, '▂', red)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantRate: 250,
+			wantOK:   true,
+		},
+		{
+			desc: "merges values provided at the same time",
+			opts: []Option{Decimals(0)},
+			updates: []update{
+				{value: 0},
+				{elapsed: time.Second, value: 10},
+				{value: 20},
+				{elapsed: time.Second, value: 40},
+			},
+			size: image.Point{10, 1},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "30/s", image.Point{0, 0})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantRate: 30,
+			wantOK:   true,
+		},
+		{
+			desc: "displays the most recent rates that fit",
+			opts: []Option{History(3)},
+			updates: []update{
+				{value: 0},
+				{elapsed: time.Second, value: 8},
+				{elapsed: time.Second, value: 12},
+				{elapsed: time.Second, value: 20},
+				{elapsed: time.Second, value: 22},
+			},
+			size: image.Point{10, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "2.0/s", image.Point{0, 0})
+				green := cell.FgColor(cell.ColorGreen)
+				testcanvas.MustSetCell(cvs, image.Point{7, 1}, '▄', green)
+				testcanvas.MustSetCell(cvs, image.Point{8, 1}, '█', green)
+				testcanvas.MustSetCell(cvs, image.Point{9, 1}, '▂', green)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantRate: 2,
+			wantOK:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			timeNow = func() time.Time { return now }
+			defer func() { timeNow = time.Now }()
+
+			r, err := New(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, u := range tc.updates {
+				now = now.Add(u.elapsed)
+				err := r.Update(u.value)
+				if (err != nil) != tc.wantErr {
+					t.Errorf("Update => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+				}
+				if err != nil {
+					return
+				}
+			}
+
+			gotRate, gotOK := r.Rate()
+			if gotRate != tc.wantRate || gotOK != tc.wantOK {
+				t.Errorf("Rate => %v, %v, want %v, %v", gotRate, gotOK, tc.wantRate, tc.wantOK)
+			}
+
+			c, err := canvas.New(image.Rectangle{Max: tc.size})
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := r.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		rate     float64
+		unit     string
+		binary   bool
+		decimals int
+		want     string
+	}{
+		{rate: 0, decimals: 1, want: "0.0/s"},
+		{rate: 999, decimals: 0, want: "999/s"},
+		{rate: 1500, decimals: 1, want: "1.5k/s"},
+		{rate: 1500, unit: "B", decimals: 1, want: "1.5 kB/s"},
+		{rate: 2.5e9, unit: "B", decimals: 2, want: "2.50 GB/s"},
+		{rate: 1536, unit: "B", binary: true, decimals: 1, want: "1.5 KiB/s"},
+		{rate: 512, unit: "B", binary: true, decimals: 0, want: "512 B/s"},
+		{rate: 3e21, unit: "B", decimals: 0, want: "3000 EB/s"},
+	}
+
+	for _, tc := range tests {
+		if got := format(tc.rate, tc.unit, tc.binary, tc.decimals); got != tc.want {
+			t.Errorf("format(%v, %q, %v, %d) => %q, want %q", tc.rate, tc.unit, tc.binary, tc.decimals, got, tc.want)
+		}
+	}
+}
+
+func TestNotifies(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	r.SetNotifyFunc(func() {
+		notified++
+	})
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+	for i := 0; i < 3; i++ {
+		if err := r.Update(float64(i)); err != nil {
+			t.Fatalf("Update => unexpected error: %v", err)
+		}
+		now = now.Add(time.Second)
+	}
+	// The first value doesn't produce a rate.
+	if want := 2; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary ratedemo displays the rates of two simulated counters.
+// Exits when 'q' is pressed.
+package main
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/rate"
+)
+
+// count increases the counter by a random amount up to max and provides
+// its value to the widget every 500ms. Exits when the context expires.
+func count(ctx context.Context, r *rate.Rate, max int) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	var counter float64
+	for {
+		counter += float64(rand.Intn(max))
+		if err := r.Update(counter); err != nil {
+			panic(err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sent, err := rate.New(
+		rate.Label("sent"),
+		rate.Unit("B"),
+		rate.BinaryPrefixes(),
+	)
+	if err != nil {
+		panic(err)
+	}
+	go count(ctx, sent, 4<<20)
+
+	requests, err := rate.New(
+		rate.Label("requests"),
+		rate.Decimals(0),
+		rate.Color(cell.ColorBlue),
+	)
+	if err != nil {
+		panic(err)
+	}
+	go count(ctx, requests, 200)
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS Q TO QUIT"),
+		container.SplitHorizontal(
+			container.Top(
+				container.Border(linestyle.Light),
+				container.PlaceWidget(sent),
+			),
+			container.Bottom(
+				container.Border(linestyle.Light),
+				container.PlaceWidget(requests),
+			),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == 'q' || k.Key == 'Q' {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter), termdash.RedrawInterval(250*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
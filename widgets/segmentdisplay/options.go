@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/private/segdisp"
 )
 
 // options.go contains configurable options for SegmentDisplay.
@@ -42,6 +43,7 @@ type options struct {
 	vAlign          align.Vertical
 	maximizeSegSize bool
 	gapPercent      int
+	maxSegHeight    int
 }
 
 // validate validates the provided options.
@@ -49,6 +51,9 @@ func (o *options) validate() error {
 	if min, max := 0, 100; o.gapPercent < min || o.gapPercent > max {
 		return fmt.Errorf("invalid GapPercent %d, must be %d <= value <= %d", o.gapPercent, min, max)
 	}
+	if min := segdisp.MinRows; o.maxSegHeight != 0 && o.maxSegHeight < min {
+		return fmt.Errorf("invalid MaxSegmentHeight %d, must be zero or at least %d", o.maxSegHeight, min)
+	}
 	return nil
 }
 
@@ -110,3 +115,16 @@ func GapPercent(perc int) Option {
 		opts.gapPercent = perc
 	})
 }
+
+// MaxSegmentHeight limits the height of the individual display segments to
+// the specified number of cells. The segments are scaled with the canvas,
+// i.e. they grow when the terminal is resized to a larger size, this option
+// prevents them from growing any further. The space the segments don't use
+// is distributed according to AlignHorizontal and AlignVertical.
+// Must be zero or at least segdisp.MinRows. Zero means no limit, which is the
+// default.
+func MaxSegmentHeight(rows int) Option {
+	return option(func(opts *options) {
+		opts.maxSegHeight = rows
+	})
+}
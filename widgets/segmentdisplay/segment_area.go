@@ -36,13 +36,22 @@ type segArea struct {
 	gaps int
 }
 
-// needArea returns the complete area required for all the segments that we can
-// fit and any gaps.
-func (sa *segArea) needArea() image.Rectangle {
+// needArea returns the area required for the segments that display text of
+// the specified length, i.e. the text that we can fit, and any gaps between
+// them.
+func (sa *segArea) needArea(textLen int) image.Rectangle {
+	segs := sa.canFit
+	if textLen < segs {
+		segs = textLen
+	}
+	gaps := sa.gaps
+	if gaps > segs-1 {
+		gaps = segs - 1
+	}
 	return image.Rect(
 		0,
 		0,
-		sa.segment.Dx()*sa.canFit+sa.gaps*sa.gapPixels,
+		sa.segment.Dx()*segs+gaps*sa.gapPixels,
 		sa.segment.Dy(),
 	)
 }
@@ -37,7 +37,9 @@ import (
 //
 // Automatically determines the size of individual segments with goal of
 // maximizing the segment size or with fitting the entire text depending on the
-// provided options.
+// provided options. The size is determined on each call to Draw, so the
+// segments scale as the terminal resizes. The space the segments don't use is
+// distributed according to the alignment options.
 //
 // Segment displays support only a subset of ASCII characters, provided options
 // determine the behavior when an unsupported character is encountered.
@@ -65,6 +67,9 @@ type SegmentDisplay struct {
 
 	// opts are the provided options.
 	opts *options
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
 }
 
 // New returns a new SegmentDisplay.
@@ -147,6 +152,7 @@ func (sd *SegmentDisplay) Write(chunks []*TextChunk, opts ...Option) error {
 		}
 		sd.buff.WriteString(text)
 	}
+	sd.markChanged()
 	return nil
 }
 
@@ -169,6 +175,22 @@ func (sd *SegmentDisplay) Reset() {
 	sd.mu.Lock()
 	defer sd.mu.Unlock()
 	sd.reset()
+	sd.markChanged()
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (sd *SegmentDisplay) SetNotifyFunc(fn func()) {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	sd.notify = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// sd.mu must be held when calling this method.
+func (sd *SegmentDisplay) markChanged() {
+	if sd.notify != nil {
+		sd.notify()
+	}
 }
 
 // reset is the implementation of Reset.
@@ -184,6 +206,10 @@ func (sd *SegmentDisplay) reset() {
 // Returns the area required for a single segment, the text that we can fit and
 // size of gaps between segments in cells.
 func (sd *SegmentDisplay) preprocess(cvsAr image.Rectangle) (*segArea, error) {
+	if max := sd.opts.maxSegHeight; max > 0 && cvsAr.Dy() > max {
+		cvsAr.Max.Y = cvsAr.Min.Y + max
+	}
+
 	textLen := sd.buff.Len() // We're guaranteed by Write to only have ASCII characters.
 	segAr, err := newSegArea(cvsAr, textLen, sd.opts.gapPercent)
 	if err != nil {
@@ -219,7 +245,7 @@ func (sd *SegmentDisplay) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	}
 
 	text := sd.buff.String()
-	aligned, err := alignfor.Rectangle(cvs.Area(), segAr.needArea(), sd.opts.hAlign, sd.opts.vAlign)
+	aligned, err := alignfor.Rectangle(cvs.Area(), segAr.needArea(len(text)), sd.opts.hAlign, sd.opts.vAlign)
 	if err != nil {
 		return fmt.Errorf("alignfor.Rectangle => %v", err)
 	}
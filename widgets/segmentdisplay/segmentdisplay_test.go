@@ -80,6 +80,14 @@ func TestSegmentDisplay(t *testing.T) {
 			canvas:     image.Rect(0, 0, segdisp.MinCols, segdisp.MinRows),
 			wantNewErr: true,
 		},
+		{
+			desc: "New fails on invalid MaxSegmentHeight",
+			opts: []Option{
+				MaxSegmentHeight(segdisp.MinRows - 1),
+			},
+			canvas:     image.Rect(0, 0, segdisp.MinCols, segdisp.MinRows),
+			wantNewErr: true,
+		},
 		{
 			desc:   "write fails on invalid GapPercent (too low)",
 			canvas: image.Rect(0, 0, segdisp.MinCols, segdisp.MinRows),
@@ -710,6 +718,65 @@ func TestSegmentDisplay(t *testing.T) {
 			},
 			wantCapacity: 3,
 		},
+		{
+			desc:   "scales the segment to the canvas by default",
+			canvas: image.Rect(0, 0, segdisp.MinCols*3, segdisp.MinRows*4),
+			update: func(sd *SegmentDisplay) error {
+				return sd.Write([]*TextChunk{NewChunk("1")})
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+
+				mustDrawChar(cvs, '1', image.Rect(0, 2, 18, 17))
+
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCapacity: 1,
+		},
+		{
+			desc: "limits the segment height with option",
+			opts: []Option{
+				MaxSegmentHeight(segdisp.MinRows),
+			},
+			canvas: image.Rect(0, 0, segdisp.MinCols*3, segdisp.MinRows*4),
+			update: func(sd *SegmentDisplay) error {
+				return sd.Write([]*TextChunk{NewChunk("1")})
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+
+				mustDrawChar(cvs, '1', image.Rect(6, 7, 12, 12))
+
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCapacity: 3,
+		},
+		{
+			desc: "limits the segment height and aligns the leftover space",
+			opts: []Option{
+				MaxSegmentHeight(segdisp.MinRows),
+				AlignHorizontal(align.HorizontalLeft),
+				AlignVertical(align.VerticalBottom),
+			},
+			canvas: image.Rect(0, 0, segdisp.MinCols*3, segdisp.MinRows*4),
+			update: func(sd *SegmentDisplay) error {
+				return sd.Write([]*TextChunk{NewChunk("1")})
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+
+				mustDrawChar(cvs, '1', image.Rect(0, 15, 6, 20))
+
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCapacity: 3,
+		},
 		{
 			desc:   "draws multiple segments with a gap by default",
 			canvas: image.Rect(0, 0, segdisp.MinCols*3+2, segdisp.MinRows),
@@ -1013,3 +1080,25 @@ func TestOptions(t *testing.T) {
 	}
 
 }
+
+func TestNotifies(t *testing.T) {
+	sd, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	sd.SetNotifyFunc(func() {
+		notified++
+	})
+
+	if err := sd.Write([]*TextChunk{NewChunk("1")}); err != nil {
+		t.Fatalf("Write => unexpected error: %v", err)
+	}
+	if err := sd.Write(nil); err == nil {
+		t.Fatalf("Write => expected an error for no chunks")
+	}
+	sd.Reset()
+	if want := 2; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
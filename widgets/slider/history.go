@@ -0,0 +1,78 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+// history.go contains code that records changes of the value so they can be
+// undone.
+
+import (
+	"github.com/mum4k/termdash/undo"
+)
+
+// valueChange is a change of the value made by the user.
+// Implements undo.Change, undo.Merger and undo.Reporter.
+type valueChange struct {
+	// slider is the slider whose value changed.
+	slider *Slider
+	// from and to are the values before and after the change.
+	from, to int
+	// drag indicates that the change was made by dragging the handle with
+	// the mouse.
+	drag bool
+}
+
+// Undo implements undo.Change.Undo.
+func (vc *valueChange) Undo() {
+	vc.slider.restore(vc.from)
+}
+
+// Redo implements undo.Change.Redo.
+func (vc *valueChange) Redo() {
+	vc.slider.restore(vc.to)
+}
+
+// Merge implements undo.Merger.Merge.
+// The changes made while the handle is dragged are undone together.
+func (vc *valueChange) Merge(next undo.Change) bool {
+	n, ok := next.(*valueChange)
+	if !ok || n.slider != vc.slider || !vc.drag || !n.drag {
+		return false
+	}
+	vc.to = n.to
+	return true
+}
+
+// Report implements undo.Reporter.Report.
+func (vc *valueChange) Report() error {
+	return vc.slider.notify(vc.slider.Value())
+}
+
+// restore sets the value when a change is undone or redone.
+func (s *Slider) restore(v int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.value = v
+	s.markChanged()
+}
+
+// record records the change on the stack provided with the History option.
+// Caller must not hold s.mu, the stack calls back into the slider when the
+// change is undone.
+func (s *Slider) record(c *valueChange) {
+	if s.opts.history != nil {
+		s.opts.history.Record(c)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+// options.go contains configurable options for Slider.
+
+import (
+	"fmt"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/undo"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	vertical         bool
+	step             int
+	value            *int
+	ticks            bool
+	tickInterval     int
+	trackColor       cell.Color
+	handleColor      cell.Color
+	highlightedColor cell.Color
+	onChange         ChangeFn
+	history          *undo.Stack
+	undoKey          keyboard.Key
+	redoKey          keyboard.Key
+}
+
+// validate validates the provided options against the range of the slider.
+func (o *options) validate(min, max int) error {
+	if min >= max {
+		return fmt.Errorf("invalid range min:%d, max:%d, min must be smaller than max", min, max)
+	}
+	if o.step < 1 || o.step > max-min {
+		return fmt.Errorf("invalid Step(%d), must be in range 1 <= step <= %d", o.step, max-min)
+	}
+	if o.value != nil && (*o.value < min || *o.value > max) {
+		return fmt.Errorf("invalid Value(%d), must be in range %d <= value <= %d", *o.value, min, max)
+	}
+	if o.ticks && o.tickInterval < 1 {
+		return fmt.Errorf("invalid Ticks(%d), must be a positive number", o.tickInterval)
+	}
+	if o.undoKey == o.redoKey {
+		return fmt.Errorf("invalid UndoKeys(undo:%v, redo:%v), the keys must be unique", o.undoKey, o.redoKey)
+	}
+	return nil
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		step:             DefaultStep,
+		highlightedColor: cell.ColorNumber(DefaultHighlightedColorNumber),
+		undoKey:          DefaultUndoKey,
+		redoKey:          DefaultRedoKey,
+	}
+}
+
+// Vertical makes the slider vertical with the minimum value at the bottom.
+// The slider is horizontal by default with the minimum value on the left.
+func Vertical() Option {
+	return option(func(opts *options) {
+		opts.vertical = true
+	})
+}
+
+// DefaultStep is the default value for the Step option.
+const DefaultStep = 1
+
+// Step sets the amount by which the value changes when a key is pressed.
+// Values set using the mouse are rounded to a multiple of the step from the
+// minimum value. Must be a positive number not larger than the range of the
+// slider.
+// Defaults to DefaultStep.
+func Step(step int) Option {
+	return option(func(opts *options) {
+		opts.step = step
+	})
+}
+
+// Value sets the initial value of the slider. Must be within the range of the
+// slider.
+// Defaults to the minimum value.
+func Value(v int) Option {
+	return option(func(opts *options) {
+		opts.value = &v
+	})
+}
+
+// Ticks draws tick marks on the track at every multiple of the interval from
+// the minimum value and labels them with their values. Labels that would
+// overlap are skipped. Must be a positive number.
+// The slider has no ticks by default.
+func Ticks(interval int) Option {
+	return option(func(opts *options) {
+		opts.ticks = true
+		opts.tickInterval = interval
+	})
+}
+
+// TrackColor sets the color of the track, the tick marks and their labels.
+// Defaults to the default terminal color.
+func TrackColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.trackColor = c
+	})
+}
+
+// HandleColor sets the color of the handle.
+// Defaults to the default terminal color.
+func HandleColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.handleColor = c
+	})
+}
+
+// DefaultHighlightedColorNumber is the default color number for the
+// HighlightedColor option.
+const DefaultHighlightedColorNumber = 33
+
+// HighlightedColor sets the color of the handle while the widget is focused.
+// Defaults to DefaultHighlightedColorNumber.
+func HighlightedColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.highlightedColor = c
+	})
+}
+
+// ChangeFn is called when the user changes the value of the slider, the
+// argument is the new value.
+//
+// The callback function must be thread-safe as the keyboard and mouse events
+// that change the value are processed in a separate goroutine.
+//
+// If the function returns an error, the widget will forward it back to the
+// termdash infrastructure which causes a panic, unless the user provided a
+// termdash.ErrorHandler.
+type ChangeFn func(value int) error
+
+// OnChange sets a function that is called when the user changes the value of
+// the slider. The function isn't called when the value is set by calling
+// Slider.SetValue.
+func OnChange(fn ChangeFn) Option {
+	return option(func(opts *options) {
+		opts.onChange = fn
+	})
+}
+
+// History records the changes of the value made by the user on the undo
+// stack, so that the user can undo and redo them, see UndoKeys. Widgets that
+// share the stack, e.g. all the widgets of a form, have their changes undone
+// in the order the user made them. Values set by calling Slider.SetValue
+// aren't recorded. Undoing or redoing a change calls the OnChange function.
+// The changes aren't recorded by default.
+func History(s *undo.Stack) Option {
+	return option(func(opts *options) {
+		opts.history = s
+	})
+}
+
+// The default keys that undo and redo changes.
+const (
+	DefaultUndoKey = keyboard.KeyCtrlZ
+	DefaultRedoKey = keyboard.KeyCtrlY
+)
+
+// UndoKeys configures the keyboard keys that undo and redo the changes
+// recorded on the stack provided with the History option. The keys must be
+// unique.
+// Defaults to DefaultUndoKey and DefaultRedoKey.
+func UndoKeys(undoKey, redoKey keyboard.Key) Option {
+	return option(func(opts *options) {
+		opts.undoKey = undoKey
+		opts.redoKey = redoKey
+	})
+}
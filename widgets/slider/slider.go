@@ -0,0 +1,412 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slider implements a widget that lets the user select a value from a
+// range by moving a handle along a track.
+package slider
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// The runes used to draw the slider.
+const (
+	handleRune      = '█'
+	trackHRune      = '─'
+	trackVRune      = '│'
+	tickHRune       = '┬'
+	tickVRune       = '├'
+	verticalLabelAt = 2
+)
+
+// Slider displays a track with a handle whose position represents a value
+// within a range.
+//
+// While the widget is focused, the right and up arrow keys increase the
+// value by the step, the left and down arrow keys decrease it and the home
+// and end keys set the minimum and the maximum value. Pressing or dragging
+// the left mouse button moves the handle to the mouse pointer. The changes can
+// be undone, see the History option.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Slider struct {
+	// mu protects the widget.
+	mu sync.Mutex
+
+	// min and max are the bounds of the range of values.
+	min, max int
+
+	// value is the current value.
+	value int
+
+	// size is the size of the canvas the slider was last drawn on, used to
+	// translate mouse positions into values.
+	size image.Point
+
+	// opts are the provided options.
+	opts *options
+
+	// redraw is called when the content changes, see SetNotifyFunc.
+	redraw func()
+}
+
+// New returns a new Slider with values in range min <= value <= max.
+func New(min, max int, opts ...Option) (*Slider, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(min, max); err != nil {
+		return nil, err
+	}
+
+	value := min
+	if opt.value != nil {
+		value = *opt.value
+	}
+	return &Slider{
+		min:   min,
+		max:   max,
+		value: value,
+		opts:  opt,
+	}, nil
+}
+
+// Value returns the current value.
+func (s *Slider) Value() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.value
+}
+
+// SetValue sets the value of the slider. The value must be within the range
+// of the slider.
+func (s *Slider) SetValue(v int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if v < s.min || v > s.max {
+		return fmt.Errorf("invalid value %d, must be in range %d <= value <= %d", v, s.min, s.max)
+	}
+	s.value = v
+	s.markChanged()
+	return nil
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (s *Slider) SetNotifyFunc(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.redraw = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// s.mu must be held when calling this method.
+func (s *Slider) markChanged() {
+	if s.redraw != nil {
+		s.redraw()
+	}
+}
+
+// length returns the number of cells along the track on the canvas of the
+// specified size.
+func (s *Slider) length(size image.Point) int {
+	if s.opts.vertical {
+		return size.Y
+	}
+	return size.X
+}
+
+// position returns the position of the value along a track of the length.
+func (s *Slider) position(v, length int) int {
+	span := s.max - s.min
+	return ((v-s.min)*(length-1)*2 + span) / (2 * span)
+}
+
+// valueAt returns the value that corresponds to the position along a track
+// of the length, rounded to the closest step.
+func (s *Slider) valueAt(pos, length int) int {
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > length-1 {
+		pos = length - 1
+	}
+	span := s.max - s.min
+	v := s.min + (pos*span*2+length-1)/(2*(length-1))
+	return s.snap(v)
+}
+
+// snap rounds the value to the closest multiple of the step from the minimum
+// value that is within the range.
+func (s *Slider) snap(v int) int {
+	step := s.opts.step
+	v = s.min + (v-s.min+step/2)/step*step
+	if v > s.max {
+		v -= step
+	}
+	return v
+}
+
+// ticks returns the values of the tick marks.
+func (s *Slider) ticks() []int {
+	if !s.opts.ticks {
+		return nil
+	}
+	var res []int
+	for v := s.min; v <= s.max; v += s.opts.tickInterval {
+		res = append(res, v)
+	}
+	return res
+}
+
+// point returns the point on the track at the position.
+func (s *Slider) point(pos int, size image.Point) image.Point {
+	if s.opts.vertical {
+		return image.Point{0, size.Y - 1 - pos}
+	}
+	return image.Point{pos, 0}
+}
+
+// Draw draws the Slider widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (s *Slider) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := cvs.Area().Size()
+	s.size = size
+	length := s.length(size)
+	if length < 2 {
+		return draw.ResizeNeeded(cvs)
+	}
+
+	trackOpts := []cell.Option{cell.FgColor(s.opts.trackColor)}
+	trackRune, tickRune := trackHRune, tickHRune
+	if s.opts.vertical {
+		trackRune, tickRune = trackVRune, tickVRune
+	}
+	for pos := 0; pos < length; pos++ {
+		if _, err := cvs.SetCell(s.point(pos, size), trackRune, trackOpts...); err != nil {
+			return err
+		}
+	}
+
+	// The labels are only drawn if the canvas has space for them.
+	labels := size.Y > 1
+	if s.opts.vertical {
+		labels = size.X > verticalLabelAt
+	}
+	lastLabel := -1
+	for _, v := range s.ticks() {
+		pos := s.position(v, length)
+		p := s.point(pos, size)
+		if _, err := cvs.SetCell(p, tickRune, trackOpts...); err != nil {
+			return err
+		}
+		if !labels {
+			continue
+		}
+
+		label := strconv.Itoa(v)
+		var start image.Point
+		if s.opts.vertical {
+			if p.Y == lastLabel {
+				continue
+			}
+			start = image.Point{verticalLabelAt, p.Y}
+			lastLabel = p.Y
+		} else {
+			x := p.X - len(label)/2
+			if x+len(label) > size.X {
+				x = size.X - len(label)
+			}
+			if x < 0 {
+				x = 0
+			}
+			if x <= lastLabel || x+len(label) > size.X {
+				continue
+			}
+			start = image.Point{x, 1}
+			lastLabel = x + len(label)
+		}
+		if err := draw.Text(cvs, label, start, draw.TextCellOpts(trackOpts...), draw.TextOverrunMode(draw.OverrunModeTrim)); err != nil {
+			return err
+		}
+	}
+
+	handleColor := s.opts.handleColor
+	if meta.Focused {
+		handleColor = s.opts.highlightedColor
+	}
+	p := s.point(s.position(s.value, length), size)
+	_, err := cvs.SetCell(p, handleRune, cell.FgColor(handleColor))
+	return err
+}
+
+// setValue sets the value if it differs from the current value.
+// Returns true if the value changed.
+func (s *Slider) setValue(v int) bool {
+	if v == s.value {
+		return false
+	}
+	s.value = v
+	s.markChanged()
+	return true
+}
+
+// keyboard processes keyboard events.
+// Returns the change of the value or nil if it didn't change.
+func (s *Slider) keyboard(k *terminalapi.Keyboard) *valueChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from := s.value
+	v := s.value
+	switch k.Key {
+	case keyboard.KeyArrowRight, keyboard.KeyArrowUp:
+		v += s.opts.step
+	case keyboard.KeyArrowLeft, keyboard.KeyArrowDown:
+		v -= s.opts.step
+	case keyboard.KeyHome:
+		v = s.min
+	case keyboard.KeyEnd:
+		v = s.max
+	}
+	if v < s.min {
+		v = s.min
+	}
+	if v > s.max {
+		v = s.max
+	}
+	if !s.setValue(v) {
+		return nil
+	}
+	return &valueChange{slider: s, from: from, to: v}
+}
+
+// Keyboard processes keyboard events.
+// Implements widgetapi.Widget.Keyboard.
+func (s *Slider) Keyboard(k *terminalapi.Keyboard) error {
+	if h := s.opts.history; h != nil {
+		// The stack must be used without holding s.mu, it calls back into
+		// the slider.
+		switch k.Key {
+		case s.opts.undoKey:
+			_, err := h.Undo()
+			return err
+		case s.opts.redoKey:
+			_, err := h.Redo()
+			return err
+		}
+	}
+
+	c := s.keyboard(k)
+	if c == nil {
+		return nil
+	}
+	s.record(c)
+	return s.notify(c.to)
+}
+
+// mouse processes mouse events.
+// Returns the change of the value or nil if it didn't change.
+func (s *Slider) mouse(m *terminalapi.Mouse) *valueChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	length := s.length(s.size)
+	if m.Button != mouse.ButtonLeft || length < 2 {
+		return nil
+	}
+
+	pos := m.Position.X
+	if s.opts.vertical {
+		pos = s.size.Y - 1 - m.Position.Y
+	}
+	from := s.value
+	v := s.valueAt(pos, length)
+	if !s.setValue(v) {
+		return nil
+	}
+	return &valueChange{slider: s, from: from, to: v, drag: true}
+}
+
+// Mouse processes mouse events.
+// Implements widgetapi.Widget.Mouse.
+func (s *Slider) Mouse(m *terminalapi.Mouse) error {
+	if h := s.opts.history; h != nil && m.Button == mouse.ButtonRelease {
+		// The next drag is undone separately.
+		h.Seal()
+	}
+
+	c := s.mouse(m)
+	if c == nil {
+		return nil
+	}
+	s.record(c)
+	return s.notify(c.to)
+}
+
+// notify calls the OnChange callback if one was provided.
+func (s *Slider) notify(v int) error {
+	if s.opts.onChange == nil {
+		return nil
+	}
+	// Mutex must be released when calling the callback.
+	// Users might call container methods from the callback like the
+	// Container.Update, see #205.
+	return s.opts.onChange(v)
+}
+
+// Options implements widgetapi.Widget.Options.
+func (s *Slider) Options() widgetapi.Options {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var labelWidth int
+	for _, v := range s.ticks() {
+		if w := len(strconv.Itoa(v)); w > labelWidth {
+			labelWidth = w
+		}
+	}
+
+	minSize := image.Point{2, 1}
+	if s.opts.ticks {
+		minSize.Y++
+	}
+	if s.opts.vertical {
+		minSize = image.Point{1, 2}
+		if s.opts.ticks {
+			minSize.X = verticalLabelAt + labelWidth
+		}
+	}
+	return widgetapi.Options{
+		MinimumSize:  minSize,
+		WantKeyboard: widgetapi.KeyScopeFocused,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+}
@@ -0,0 +1,778 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slider
+
+import (
+	"errors"
+	"image"
+	"sync"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/undo"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// callbackTracker tracks the calls of the OnChange callback.
+type callbackTracker struct {
+	// wantErr when set to true, makes callback return an error.
+	wantErr bool
+
+	// calls are the arguments of the calls in the order of the calls.
+	calls []int
+
+	// mu protects the tracker.
+	mu sync.Mutex
+}
+
+// onChange is the callback function called OnChange.
+func (ct *callbackTracker) onChange(value int) error {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	if ct.wantErr {
+		return errors.New("ct.wantErr set to true")
+	}
+	ct.calls = append(ct.calls, value)
+	return nil
+}
+
+// mustDrawTrack draws a horizontal track of the specified width with the
+// handle at the position.
+func mustDrawTrack(cvs *canvas.Canvas, width, handle int, handleOpts ...cell.Option) {
+	for x := 0; x < width; x++ {
+		testcanvas.MustSetCell(cvs, image.Point{x, 0}, trackHRune)
+	}
+	testcanvas.MustSetCell(cvs, image.Point{handle, 0}, handleRune, handleOpts...)
+}
+
+func TestSlider(t *testing.T) {
+	tests := []struct {
+		desc         string
+		min, max     int
+		opts         []Option
+		canvas       image.Rectangle
+		callback     *callbackTracker
+		events       []terminalapi.Event
+		meta         *widgetapi.Meta
+		want         func(size image.Point) *faketerm.Terminal
+		wantValue    int
+		wantCallback *callbackTracker
+		wantNewErr   bool
+		wantEventErr bool
+	}{
+		{
+			desc:       "fails when min isn't smaller than max",
+			min:        5,
+			max:        5,
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on Step too low",
+			max:        4,
+			opts:       []Option{Step(0)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on Step larger than the range",
+			max:        4,
+			opts:       []Option{Step(5)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on Value out of range",
+			max:        4,
+			opts:       []Option{Value(5)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on Ticks with zero interval",
+			max:        4,
+			opts:       []Option{Ticks(0)},
+			wantNewErr: true,
+		},
+		{
+			desc:   "draws the handle at the minimum by default",
+			max:    4,
+			canvas: image.Rect(0, 0, 5, 1),
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawTrack(cvs, 5, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "draws the initial value",
+			max:    4,
+			opts:   []Option{Value(2)},
+			canvas: image.Rect(0, 0, 5, 1),
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawTrack(cvs, 5, 2)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantValue: 2,
+		},
+		{
+			desc:   "highlights the handle while focused",
+			max:    4,
+			canvas: image.Rect(0, 0, 5, 1),
+			meta:   &widgetapi.Meta{Focused: true},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawTrack(cvs, 5, 0, cell.FgColor(cell.ColorNumber(DefaultHighlightedColorNumber)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "draws the value scaled to the track",
+			min:    -10,
+			max:    10,
+			opts:   []Option{Value(5)},
+			canvas: image.Rect(0, 0, 5, 1),
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawTrack(cvs, 5, 3)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantValue: 5,
+		},
+		{
+			desc:     "arrow keys change the value and call the callback",
+			max:      4,
+			canvas:   image.Rect(0, 0, 5, 1),
+			callback: &callbackTracker{},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowUp},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowDown},
+			},
+			meta: &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawTrack(cvs, 5, 1)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantValue: 1,
+			wantCallback: &callbackTracker{
+				calls: []int{1, 2, 1},
+			},
+		},
+		{
+			desc:     "home and end keys set the bounds",
+			max:      4,
+			canvas:   image.Rect(0, 0, 5, 1),
+			callback: &callbackTracker{},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEnd},
+				&terminalapi.Keyboard{Key: keyboard.KeyHome},
+				&terminalapi.Keyboard{Key: keyboard.KeyEnd},
+			},
+			meta: &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawTrack(cvs, 5, 4)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantValue: 4,
+			wantCallback: &callbackTracker{
+				calls: []int{4, 0, 4},
+			},
+		},
+		{
+			desc:     "keys don't move the value out of range",
+			max:      4,
+			opts:     []Option{Step(3)},
+			canvas:   image.Rect(0, 0, 5, 1),
+			callback: &callbackTracker{},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+			},
+			meta: &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawTrack(cvs, 5, 4)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantValue: 4,
+			wantCallback: &callbackTracker{
+				calls: []int{3, 4},
+			},
+		},
+		{
+			desc:     "mouse moves the handle to the pointer",
+			max:      4,
+			canvas:   image.Rect(0, 0, 5, 1),
+			callback: &callbackTracker{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{3, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{3, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{2, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{2, 0}, Button: mouse.ButtonRelease},
+			},
+			meta: &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawTrack(cvs, 5, 2)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantValue: 2,
+			wantCallback: &callbackTracker{
+				calls: []int{3, 2},
+			},
+		},
+		{
+			desc:     "mouse rounds the value to the step",
+			max:      10,
+			opts:     []Option{Step(5)},
+			canvas:   image.Rect(0, 0, 5, 1),
+			callback: &callbackTracker{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{1, 0}, Button: mouse.ButtonLeft},
+			},
+			meta: &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawTrack(cvs, 5, 2)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantValue: 5,
+			wantCallback: &callbackTracker{
+				calls: []int{5},
+			},
+		},
+		{
+			desc:     "ignores other mouse buttons",
+			max:      4,
+			canvas:   image.Rect(0, 0, 5, 1),
+			callback: &callbackTracker{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{3, 0}, Button: mouse.ButtonRight},
+			},
+			meta: &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawTrack(cvs, 5, 0)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantCallback: &callbackTracker{},
+		},
+		{
+			desc:     "vertical slider has the minimum at the bottom",
+			max:      2,
+			opts:     []Option{Vertical()},
+			canvas:   image.Rect(0, 0, 1, 3),
+			callback: &callbackTracker{},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{0, 1}, Button: mouse.ButtonLeft},
+			},
+			meta: &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetCell(cvs, image.Point{0, 0}, trackVRune)
+				testcanvas.MustSetCell(cvs, image.Point{0, 1}, handleRune)
+				testcanvas.MustSetCell(cvs, image.Point{0, 2}, trackVRune)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantValue: 1,
+			wantCallback: &callbackTracker{
+				calls: []int{1},
+			},
+		},
+		{
+			desc:   "draws labeled ticks below horizontal track",
+			max:    10,
+			opts:   []Option{Ticks(5)},
+			canvas: image.Rect(0, 0, 11, 2),
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				mustDrawTrack(cvs, 11, 0)
+				testcanvas.MustSetCell(cvs, image.Point{5, 0}, tickHRune)
+				testcanvas.MustSetCell(cvs, image.Point{10, 0}, tickHRune)
+				testdraw.MustText(cvs, "0", image.Point{0, 1})
+				testdraw.MustText(cvs, "5", image.Point{5, 1})
+				testdraw.MustText(cvs, "10", image.Point{9, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "skips labels that would overlap",
+			max:    100,
+			opts:   []Option{Ticks(10), Value(100)},
+			canvas: image.Rect(0, 0, 11, 2),
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				for x := 0; x < 10; x++ {
+					testcanvas.MustSetCell(cvs, image.Point{x, 0}, tickHRune)
+				}
+				testcanvas.MustSetCell(cvs, image.Point{10, 0}, handleRune)
+				testdraw.MustText(cvs, "0", image.Point{0, 1})
+				testdraw.MustText(cvs, "30", image.Point{2, 1})
+				testdraw.MustText(cvs, "60", image.Point{5, 1})
+				testdraw.MustText(cvs, "90", image.Point{8, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantValue: 100,
+		},
+		{
+			desc:   "draws labeled ticks next to vertical track",
+			max:    10,
+			opts:   []Option{Ticks(5), Vertical()},
+			canvas: image.Rect(0, 0, 4, 3),
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetCell(cvs, image.Point{0, 0}, tickVRune)
+				testcanvas.MustSetCell(cvs, image.Point{0, 1}, tickVRune)
+				testcanvas.MustSetCell(cvs, image.Point{0, 2}, handleRune)
+				testdraw.MustText(cvs, "10", image.Point{2, 0})
+				testdraw.MustText(cvs, "5", image.Point{2, 1})
+				testdraw.MustText(cvs, "0", image.Point{2, 2})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "requests resize when the track is too short",
+			max:    4,
+			canvas: image.Rect(0, 0, 1, 1),
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustResizeNeeded(cvs)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "forwards errors from the callback",
+			max:    4,
+			canvas: image.Rect(0, 0, 5, 1),
+			callback: &callbackTracker{
+				wantErr: true,
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+			},
+			wantEventErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			gotCallback := tc.callback
+			if gotCallback != nil {
+				tc.opts = append(tc.opts, OnChange(gotCallback.onChange))
+			}
+
+			s, err := New(tc.min, tc.max, tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			c, err := canvas.New(tc.canvas)
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			// Draw once so mouse events are acceptable.
+			if err := s.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			for i, ev := range tc.events {
+				var err error
+				switch e := ev.(type) {
+				case *terminalapi.Keyboard:
+					err = s.Keyboard(e)
+				case *terminalapi.Mouse:
+					err = s.Mouse(e)
+				default:
+					t.Fatalf("unsupported event type: %T", ev)
+				}
+				// Only the last event in test cases is the one that can fail.
+				if i == len(tc.events)-1 {
+					if (err != nil) != tc.wantEventErr {
+						t.Errorf("event %v => unexpected error: %v, wantEventErr: %v", ev, err, tc.wantEventErr)
+					}
+					if err != nil {
+						return
+					}
+				} else if err != nil {
+					t.Fatalf("event %v => unexpected error: %v", ev, err)
+				}
+			}
+
+			c, err = canvas.New(tc.canvas)
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := s.Draw(c, tc.meta); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+
+			if got := s.Value(); got != tc.wantValue {
+				t.Errorf("Value => %d, want %d", got, tc.wantValue)
+			}
+			if diff := pretty.Compare(tc.wantCallback, gotCallback); diff != "" {
+				t.Errorf("ChangeFn => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	ct := &callbackTracker{}
+	s, err := New(0, 10, OnChange(ct.onChange))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	for _, v := range []int{-1, 11} {
+		if err := s.SetValue(v); err == nil {
+			t.Errorf("SetValue(%d) => got nil error, want an error for a value out of range", v)
+		}
+	}
+	if err := s.SetValue(7); err != nil {
+		t.Fatalf("SetValue(7) => unexpected error: %v", err)
+	}
+	if got := s.Value(); got != 7 {
+		t.Errorf("Value => %d, want 7", got)
+	}
+	if len(ct.calls) != 0 {
+		t.Errorf("SetValue called the callback %d times, want no calls", len(ct.calls))
+	}
+}
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		want widgetapi.Options
+	}{
+		{
+			desc: "horizontal",
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{2, 1},
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+			},
+		},
+		{
+			desc: "horizontal with ticks",
+			opts: []Option{Ticks(50)},
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{2, 2},
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+			},
+		},
+		{
+			desc: "vertical",
+			opts: []Option{Vertical()},
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{1, 2},
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+			},
+		},
+		{
+			desc: "vertical with ticks",
+			opts: []Option{Vertical(), Ticks(50)},
+			want: widgetapi.Options{
+				MinimumSize:  image.Point{5, 2},
+				WantKeyboard: widgetapi.KeyScopeFocused,
+				WantMouse:    widgetapi.MouseScopeWidget,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := New(0, 100, tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			if diff := pretty.Compare(tc.want, s.Options()); diff != "" {
+				t.Errorf("Options => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNotifies(t *testing.T) {
+	s, err := New(0, 10)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	s.SetNotifyFunc(func() {
+		notified++
+	})
+
+	if err := s.SetValue(5); err != nil {
+		t.Fatalf("SetValue => unexpected error: %v", err)
+	}
+	if err := s.SetValue(11); err == nil {
+		t.Fatalf("SetValue => expected an error for a value out of range")
+	}
+	if err := s.Keyboard(&terminalapi.Keyboard{Key: keyboard.KeyArrowRight}); err != nil {
+		t.Fatalf("Keyboard => unexpected error: %v", err)
+	}
+	// Already at the maximum, the value doesn't change.
+	if err := s.Keyboard(&terminalapi.Keyboard{Key: keyboard.KeyEnd}); err != nil {
+		t.Fatalf("Keyboard => unexpected error: %v", err)
+	}
+	if err := s.Keyboard(&terminalapi.Keyboard{Key: keyboard.KeyEnd}); err != nil {
+		t.Fatalf("Keyboard => unexpected error: %v", err)
+	}
+	if want := 3; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
+
+func TestUndo(t *testing.T) {
+	tests := []struct {
+		desc      string
+		opts      []Option
+		events    []terminalapi.Event
+		want      int
+		wantCalls []int
+		// wantNewErr indicates that New is expected to fail.
+		wantNewErr bool
+	}{
+		{
+			desc: "fails on duplicate UndoKeys",
+			opts: []Option{
+				UndoKeys(keyboard.KeyCtrlZ, keyboard.KeyCtrlZ),
+			},
+			wantNewErr: true,
+		},
+		{
+			desc: "undoes key presses one by one",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+			},
+			want:      1,
+			wantCalls: []int{1, 2, 1},
+		},
+		{
+			desc: "redoes an undone adjustment",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyEnd},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+				&terminalapi.Keyboard{Key: DefaultRedoKey},
+			},
+			want:      10,
+			wantCalls: []int{10, 0, 10},
+		},
+		{
+			desc: "undoes a drag of the handle in one step",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Mouse{Position: image.Point{4, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{6, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{6, 0}, Button: mouse.ButtonRelease},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+			},
+			want:      1,
+			wantCalls: []int{1, 4, 6, 1},
+		},
+		{
+			desc: "undoes separate drags separately",
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{4, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{4, 0}, Button: mouse.ButtonRelease},
+				&terminalapi.Mouse{Position: image.Point{6, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{6, 0}, Button: mouse.ButtonRelease},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+			},
+			want:      4,
+			wantCalls: []int{4, 6, 4},
+		},
+		{
+			desc: "uses custom UndoKeys",
+			opts: []Option{
+				UndoKeys('u', 'r'),
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+				&terminalapi.Keyboard{Key: 'u'},
+			},
+			want:      0,
+			wantCalls: []int{1, 0},
+		},
+		{
+			desc: "nothing to undo",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+				&terminalapi.Keyboard{Key: DefaultRedoKey},
+			},
+			want: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			h, err := undo.New()
+			if err != nil {
+				t.Fatalf("undo.New => unexpected error: %v", err)
+			}
+			ct := &callbackTracker{}
+			opts := append([]Option{History(h), OnChange(ct.onChange)}, tc.opts...)
+			s, err := New(0, 10, opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			// Draw once so mouse events are acceptable.
+			if err := s.Draw(testcanvas.MustNew(image.Rect(0, 0, 11, 1)), &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			for _, ev := range tc.events {
+				switch e := ev.(type) {
+				case *terminalapi.Mouse:
+					if err := s.Mouse(e); err != nil {
+						t.Fatalf("Mouse => unexpected error: %v", err)
+					}
+
+				case *terminalapi.Keyboard:
+					if err := s.Keyboard(e); err != nil {
+						t.Fatalf("Keyboard => unexpected error: %v", err)
+					}
+
+				default:
+					t.Fatalf("unsupported event type: %T", ev)
+				}
+			}
+
+			if got := s.Value(); got != tc.want {
+				t.Errorf("Value => %d, want %d", got, tc.want)
+			}
+			if diff := pretty.Compare(tc.wantCalls, ct.calls); diff != "" {
+				t.Errorf("OnChange => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUndoSharedHistory(t *testing.T) {
+	h, err := undo.New()
+	if err != nil {
+		t.Fatalf("undo.New => unexpected error: %v", err)
+	}
+	first, err := New(0, 10, History(h))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	second, err := New(0, 10, History(h))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	if err := first.Keyboard(&terminalapi.Keyboard{Key: keyboard.KeyArrowRight}); err != nil {
+		t.Fatalf("Keyboard => unexpected error: %v", err)
+	}
+	if err := second.Keyboard(&terminalapi.Keyboard{Key: keyboard.KeyEnd}); err != nil {
+		t.Fatalf("Keyboard => unexpected error: %v", err)
+	}
+
+	// The undo key on the first slider undoes the most recent change, which
+	// was made on the second slider.
+	if err := first.Keyboard(&terminalapi.Keyboard{Key: DefaultUndoKey}); err != nil {
+		t.Fatalf("Keyboard => unexpected error: %v", err)
+	}
+	if got, want := first.Value(), 1; got != want {
+		t.Errorf("first.Value => %d, want %d", got, want)
+	}
+	if got, want := second.Value(), 0; got != want {
+		t.Errorf("second.Value => %d, want %d", got, want)
+	}
+
+	if _, err := h.Undo(); err != nil {
+		t.Fatalf("Undo => unexpected error: %v", err)
+	}
+	if got, want := first.Value(), 0; got != want {
+		t.Errorf("first.Value => %d, want %d", got, want)
+	}
+	if _, err := h.Redo(); err != nil {
+		t.Fatalf("Redo => unexpected error: %v", err)
+	}
+	if got, want := first.Value(), 1; got != want {
+		t.Errorf("first.Value => %d, want %d", got, want)
+	}
+}
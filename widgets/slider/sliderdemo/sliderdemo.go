@@ -0,0 +1,109 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary sliderdemo shows the functionality of the slider widget.
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/gauge"
+	"github.com/mum4k/termdash/widgets/slider"
+)
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, err := gauge.New(
+		gauge.Height(1),
+		gauge.Color(cell.ColorBlue),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	horizontal, err := slider.New(
+		0, 100,
+		slider.Step(5),
+		slider.Ticks(25),
+		slider.HandleColor(cell.ColorYellow),
+		slider.OnChange(func(v int) error {
+			return g.Percent(v)
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+	vertical, err := slider.New(
+		0, 10,
+		slider.Vertical(),
+		slider.Ticks(2),
+		slider.Value(5),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS Q TO QUIT"),
+		container.SplitVertical(
+			container.Left(
+				container.SplitHorizontal(
+					container.Top(
+						container.Border(linestyle.Light),
+						container.BorderTitle("Percent"),
+						container.PlaceWidget(horizontal),
+					),
+					container.Bottom(
+						container.Border(linestyle.Light),
+						container.PlaceWidget(g),
+					),
+				),
+			),
+			container.Right(
+				container.Border(linestyle.Light),
+				container.BorderTitle("Level"),
+				container.PlaceWidget(vertical),
+			),
+			container.SplitPercent(70),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == 'q' || k.Key == 'Q' {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter), termdash.RedrawInterval(100*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slo
+
+// options.go contains configurable options for SLO.
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mum4k/termdash/cell"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	label         string
+	labelCellOpts []cell.Option
+	target        float64
+	decimals      int
+	budgetWarn    float64
+	budgetCrit    float64
+	burnWarn      float64
+	burnCrit      float64
+	okColor       cell.Color
+	warnColor     cell.Color
+	critColor     cell.Color
+	history       int
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		target:     DefaultTarget,
+		decimals:   DefaultDecimals,
+		budgetWarn: DefaultBudgetWarning,
+		budgetCrit: DefaultBudgetCritical,
+		burnWarn:   DefaultBurnRateWarning,
+		burnCrit:   DefaultBurnRateCritical,
+		okColor:    cell.ColorGreen,
+		warnColor:  cell.ColorYellow,
+		critColor:  cell.ColorRed,
+		history:    DefaultHistory,
+	}
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if min, max := 0.0, 100.0; math.IsNaN(o.target) || o.target <= min || o.target >= max {
+		return fmt.Errorf("invalid Target(%v), must be in range %v < value < %v", o.target, min, max)
+	}
+	if min := 0; o.decimals < min {
+		return fmt.Errorf("invalid Decimals(%d), must be %d <= value", o.decimals, min)
+	}
+	if o.budgetWarn < o.budgetCrit {
+		return fmt.Errorf("invalid BudgetThresholds(%v, %v), the warning must be at least the critical threshold", o.budgetWarn, o.budgetCrit)
+	}
+	if o.burnWarn > o.burnCrit {
+		return fmt.Errorf("invalid BurnRateThresholds(%v, %v), the warning must be at most the critical threshold", o.burnWarn, o.burnCrit)
+	}
+	if min := 1; o.history < min {
+		return fmt.Errorf("invalid History(%d), must be at least %d", o.history, min)
+	}
+	return nil
+}
+
+// Label sets the text displayed on the first row before the target.
+func Label(text string, cOpts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.label = text
+		o.labelCellOpts = cOpts
+	})
+}
+
+// DefaultTarget is the default value for the Target option.
+const DefaultTarget = 99.9
+
+// Target sets the objective, the percentage of events that must be good,
+// e.g. 99.9.
+// Defaults to DefaultTarget.
+func Target(percent float64) Option {
+	return option(func(o *options) {
+		o.target = percent
+	})
+}
+
+// DefaultDecimals is the default value for the Decimals option.
+const DefaultDecimals = 2
+
+// Decimals sets the number of decimal places of the displayed compliance.
+// Defaults to DefaultDecimals.
+func Decimals(n int) Option {
+	return option(func(o *options) {
+		o.decimals = n
+	})
+}
+
+// Default thresholds of the remaining error budget, as a fraction of the
+// whole budget.
+const (
+	DefaultBudgetWarning  = 0.25
+	DefaultBudgetCritical = 0.0
+)
+
+// BudgetThresholds sets when the remaining error budget is displayed in the
+// warning and the critical color. The thresholds are fractions of the whole
+// budget, the budget is in the warning color when at or below warning and in
+// the critical color when at or below critical.
+// Defaults to DefaultBudgetWarning and DefaultBudgetCritical.
+func BudgetThresholds(warning, critical float64) Option {
+	return option(func(o *options) {
+		o.budgetWarn = warning
+		o.budgetCrit = critical
+	})
+}
+
+// Default thresholds of the burn rate.
+const (
+	DefaultBurnRateWarning  = 1.0
+	DefaultBurnRateCritical = 2.0
+)
+
+// BurnRateThresholds sets when the burn rate is displayed in the warning and
+// the critical color. The burn rate is in the warning color when above
+// warning and in the critical color when above critical. A burn rate of one
+// spends exactly the whole budget over the period of the objective.
+// Defaults to DefaultBurnRateWarning and DefaultBurnRateCritical.
+func BurnRateThresholds(warning, critical float64) Option {
+	return option(func(o *options) {
+		o.burnWarn = warning
+		o.burnCrit = critical
+	})
+}
+
+// Colors sets the colors used for values within the thresholds, above the
+// warning threshold and above the critical threshold.
+// Defaults to green, yellow and red.
+func Colors(ok, warning, critical cell.Color) Option {
+	return option(func(o *options) {
+		o.okColor = ok
+		o.warnColor = warning
+		o.critColor = critical
+	})
+}
+
+// DefaultHistory is the default value for the History option.
+const DefaultHistory = 300
+
+// History sets the maximum number of the remaining budget values kept for
+// the trend sparkline, the oldest values are forgotten first.
+// Defaults to DefaultHistory.
+func History(values int) Option {
+	return option(func(o *options) {
+		o.history = values
+	})
+}
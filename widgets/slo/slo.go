@@ -0,0 +1,330 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slo implements a widget that displays the compliance with a service
+// level objective and the remaining error budget.
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sync"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// sparks are the characters used to draw the sparkline.
+var sparks = []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// Stats are the values computed from the counters.
+type Stats struct {
+	// Compliance is the percentage of good events out of all the events.
+	Compliance float64
+	// Budget is the fraction of the error budget that remains, it is
+	// negative once the budget is exhausted.
+	Budget float64
+	// BurnRate is how fast the budget was spent between the last two
+	// updates with new events, relative to the rate that spends exactly the
+	// whole budget.
+	BurnRate float64
+}
+
+// SLO displays a service level objective, the current compliance with it,
+// the remaining error budget and the rate at which the budget burns. The
+// callers provide the raw values of two counters, the good events and all
+// the events.
+//
+// The values are colored according to the thresholds, the rows below them
+// display a sparkline of the remaining budget.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type SLO struct {
+	// lastGood and lastTotal are the last values of the counters.
+	lastGood  float64
+	lastTotal float64
+
+	// good and total are the events counted since the widget was created.
+	good  float64
+	total float64
+
+	// burnRate is the last computed burn rate.
+	burnRate float64
+
+	// budgets are the remaining budgets after each update, the oldest
+	// first.
+	budgets []float64
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the SLO.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new SLO.
+func New(opts ...Option) (*SLO, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &SLO{
+		opts: opt,
+	}, nil
+}
+
+// Update provides the current values of the counters of the good events and
+// of all the events. Both counters are expected to start at zero and only
+// increase, a decrease of either is treated as a reset of both counters to
+// zero, e.g. when the process that maintains them restarts.
+// The values must be finite non-negative numbers and good cannot be larger
+// than total.
+func (s *SLO) Update(good, total float64) error {
+	for _, v := range []float64{good, total} {
+		if math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+			return fmt.Errorf("invalid counter value %v, must be a finite non-negative number", v)
+		}
+	}
+	if good > total {
+		return fmt.Errorf("invalid counter values, good %v cannot be larger than total %v", good, total)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dGood, dTotal := good-s.lastGood, total-s.lastTotal
+	if dGood < 0 || dTotal < 0 {
+		// The counters were reset.
+		dGood, dTotal = good, total
+	}
+	s.lastGood, s.lastTotal = good, total
+	if dTotal == 0 {
+		return nil
+	}
+
+	s.good += dGood
+	s.total += dTotal
+	s.burnRate = (dTotal - dGood) / dTotal / s.allowed()
+	s.budgets = append(s.budgets, s.budget())
+	if over := len(s.budgets) - s.opts.history; over > 0 {
+		s.budgets = s.budgets[over:]
+	}
+	if s.notify != nil {
+		s.notify()
+	}
+	return nil
+}
+
+// Stats returns the values computed from the counters. Returns false if no
+// events were counted yet.
+func (s *SLO) Stats() (Stats, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.total == 0 {
+		return Stats{}, false
+	}
+	return Stats{
+		Compliance: s.good / s.total * 100,
+		Budget:     s.budget(),
+		BurnRate:   s.burnRate,
+	}, true
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (s *SLO) SetNotifyFunc(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notify = fn
+}
+
+// allowed returns the fraction of the events that are allowed to be bad.
+func (s *SLO) allowed() float64 {
+	return 1 - s.opts.target/100
+}
+
+// budget returns the remaining fraction of the error budget.
+// The caller must hold s.mu.
+func (s *SLO) budget() float64 {
+	if s.total == 0 {
+		return 1
+	}
+	bad := s.total - s.good
+	return 1 - bad/(s.total*s.allowed())
+}
+
+// budgetColor returns the color of the remaining budget.
+func (s *SLO) budgetColor(budget float64) cell.Color {
+	switch {
+	case budget <= s.opts.budgetCrit:
+		return s.opts.critColor
+	case budget <= s.opts.budgetWarn:
+		return s.opts.warnColor
+	default:
+		return s.opts.okColor
+	}
+}
+
+// burnColor returns the color of the burn rate.
+func (s *SLO) burnColor(rate float64) cell.Color {
+	switch {
+	case rate > s.opts.burnCrit:
+		return s.opts.critColor
+	case rate > s.opts.burnWarn:
+		return s.opts.warnColor
+	default:
+		return s.opts.okColor
+	}
+}
+
+// row is one of the rows with a name and a value.
+type row struct {
+	name  string
+	value string
+	color cell.Color
+}
+
+// Draw draws the SLO widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (s *SLO) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ar := cvs.Area()
+	y := 0
+	x := 0
+	if s.opts.label != "" {
+		if err := draw.Text(cvs, s.opts.label, image.Point{0, y},
+			draw.TextMaxX(ar.Dx()),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(s.opts.labelCellOpts...),
+		); err != nil {
+			return err
+		}
+		y++
+	}
+
+	rows := []row{
+		{name: "target", value: fmt.Sprintf("%g%%", s.opts.target), color: cell.ColorDefault},
+	}
+	if s.total > 0 {
+		compliance := s.good / s.total * 100
+		complianceColor := s.opts.okColor
+		if compliance < s.opts.target {
+			complianceColor = s.opts.critColor
+		}
+		budget := s.budget()
+		rows = append(rows,
+			row{name: "compliance", value: fmt.Sprintf("%.*f%%", s.opts.decimals, compliance), color: complianceColor},
+			row{name: "budget", value: fmt.Sprintf("%.1f%%", budget*100), color: s.budgetColor(budget)},
+			row{name: "burn rate", value: fmt.Sprintf("%.1fx", s.burnRate), color: s.burnColor(s.burnRate)},
+		)
+	}
+	for _, r := range rows {
+		if w := runewidth.StringWidth(r.name); w > x {
+			x = w
+		}
+	}
+	// One cell gap between the names and the values.
+	x++
+
+	for _, r := range rows {
+		if y >= ar.Dy() {
+			return nil
+		}
+		if err := draw.Text(cvs, r.name, image.Point{0, y},
+			draw.TextMaxX(ar.Dx()),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		); err != nil {
+			return err
+		}
+		if x < ar.Dx() {
+			if err := draw.Text(cvs, r.value, image.Point{x, y},
+				draw.TextMaxX(ar.Dx()),
+				draw.TextOverrunMode(draw.OverrunModeThreeDot),
+				draw.TextCellOpts(cell.FgColor(r.color)),
+			); err != nil {
+				return err
+			}
+		}
+		y++
+	}
+
+	if y < ar.Dy() && len(s.budgets) > 0 {
+		return s.drawSparkLine(cvs, image.Rect(0, y, ar.Dx(), ar.Dy()))
+	}
+	return nil
+}
+
+// drawSparkLine draws the sparkline of the most recent remaining budgets
+// that fit into the area, the last one on the right. An exhausted budget is
+// drawn as an empty column.
+// The caller must hold s.mu.
+func (s *SLO) drawSparkLine(cvs *canvas.Canvas, ar image.Rectangle) error {
+	budgets := s.budgets
+	if len(budgets) > ar.Dx() {
+		budgets = budgets[len(budgets)-ar.Dx():]
+	}
+
+	color := s.budgetColor(s.budget())
+	x := ar.Max.X - len(budgets)
+	for _, v := range budgets {
+		v = math.Max(0, math.Min(1, v))
+		// The number of the smallest sparks needed to represent the value.
+		elements := int(math.Round(v * float64(ar.Dy()*len(sparks))))
+		y := ar.Max.Y - 1
+		for ; elements > 0; elements -= len(sparks) {
+			sp := sparks[len(sparks)-1]
+			if elements < len(sparks) {
+				sp = sparks[elements-1]
+			}
+			if _, err := cvs.SetCell(image.Point{x, y}, sp, cell.FgColor(color)); err != nil {
+				return err
+			}
+			y--
+		}
+		x++
+	}
+	return nil
+}
+
+// Keyboard input isn't supported on the SLO widget.
+func (*SLO) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the SLO widget doesn't support keyboard events")
+}
+
+// Mouse input isn't supported on the SLO widget.
+func (*SLO) Mouse(m *terminalapi.Mouse) error {
+	return errors.New("the SLO widget doesn't support mouse events")
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*SLO) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeNone,
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slo
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// update are the values of the counters.
+type update struct {
+	good  float64
+	total float64
+}
+
+func TestSLO(t *testing.T) {
+	tests := []struct {
+		desc       string
+		opts       []Option
+		updates    []update
+		size       image.Point
+		want       func(size image.Point) *faketerm.Terminal
+		wantStats  Stats
+		wantOK     bool
+		wantNewErr bool
+		wantErr    bool
+	}{
+		{
+			desc:       "fails on Target of hundred percent",
+			opts:       []Option{Target(100)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on negative Decimals",
+			opts:       []Option{Decimals(-1)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on budget warning below critical",
+			opts:       []Option{BudgetThresholds(0.1, 0.2)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on burn rate warning above critical",
+			opts:       []Option{BurnRateThresholds(3, 2)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on zero History",
+			opts:       []Option{History(0)},
+			wantNewErr: true,
+		},
+		{
+			desc:    "fails on NaN value",
+			updates: []update{{good: math.NaN(), total: 1}},
+			wantErr: true,
+		},
+		{
+			desc:    "fails when good is larger than total",
+			updates: []update{{good: 2, total: 1}},
+			wantErr: true,
+		},
+		{
+			desc: "displays only the target without events",
+			size: image.Point{20, 3},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "target", image.Point{0, 0})
+				testdraw.MustText(cvs, "99.9%", image.Point{7, 0})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "displays the stats and the sparkline",
+			opts: []Option{
+				Label("api"),
+				Target(50),
+			},
+			updates: []update{
+				{good: 90, total: 100},
+				{good: 110, total: 140},
+				{good: 110, total: 180},
+			},
+			size: image.Point{20, 6},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				green := draw.TextCellOpts(cell.FgColor(cell.ColorGreen))
+				yellow := draw.TextCellOpts(cell.FgColor(cell.ColorYellow))
+				testdraw.MustText(cvs, "api", image.Point{0, 0})
+				testdraw.MustText(cvs, "target", image.Point{0, 1})
+				testdraw.MustText(cvs, "50%", image.Point{11, 1})
+				testdraw.MustText(cvs, "compliance", image.Point{0, 2})
+				testdraw.MustText(cvs, "61.11%", image.Point{11, 2}, green)
+				testdraw.MustText(cvs, "budget", image.Point{0, 3})
+				testdraw.MustText(cvs, "22.2%", image.Point{11, 3}, yellow)
+				testdraw.MustText(cvs, "burn rate", image.Point{0, 4})
+				testdraw.MustText(cvs, "2.0x", image.Point{11, 4}, yellow)
+				testdraw.MustText(cvs, "▆▅▂", image.Point{17, 5}, yellow)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantStats: Stats{
+				Compliance: 110.0 / 180 * 100,
+				Budget:     1 - 70.0/90,
+				BurnRate:   2,
+			},
+			wantOK: true,
+		},
+		{
+			desc: "colors an exhausted budget and a violated objective",
+			opts: []Option{
+				Target(50),
+				Colors(cell.ColorBlue, cell.ColorMagenta, cell.ColorRed),
+			},
+			updates: []update{
+				{good: 1, total: 4},
+			},
+			size: image.Point{20, 4},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				red := draw.TextCellOpts(cell.FgColor(cell.ColorRed))
+				testdraw.MustText(cvs, "target", image.Point{0, 0})
+				testdraw.MustText(cvs, "50%", image.Point{11, 0})
+				testdraw.MustText(cvs, "compliance", image.Point{0, 1})
+				testdraw.MustText(cvs, "25.00%", image.Point{11, 1}, red)
+				testdraw.MustText(cvs, "budget", image.Point{0, 2})
+				testdraw.MustText(cvs, "-50.0%", image.Point{11, 2}, red)
+				testdraw.MustText(cvs, "burn rate", image.Point{0, 3})
+				testdraw.MustText(cvs, "1.5x", image.Point{11, 3}, draw.TextCellOpts(cell.FgColor(cell.ColorMagenta)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantStats: Stats{
+				Compliance: 25,
+				Budget:     -0.5,
+				BurnRate:   1.5,
+			},
+			wantOK: true,
+		},
+		{
+			desc: "treats a decrease as a reset of the counters",
+			opts: []Option{Target(50)},
+			updates: []update{
+				{good: 10, total: 10},
+				{good: 5, total: 10},
+			},
+			size: image.Point{1, 1},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "…", image.Point{0, 0})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantStats: Stats{
+				Compliance: 75,
+				Budget:     0.5,
+				BurnRate:   1,
+			},
+			wantOK: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := New(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, u := range tc.updates {
+				err := s.Update(u.good, u.total)
+				if (err != nil) != tc.wantErr {
+					t.Errorf("Update => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+				}
+				if err != nil {
+					return
+				}
+			}
+
+			gotStats, gotOK := s.Stats()
+			if gotOK != tc.wantOK {
+				t.Errorf("Stats => ok %v, want %v", gotOK, tc.wantOK)
+			}
+			if diff := pretty.Compare(tc.wantStats, gotStats); diff != "" {
+				t.Errorf("Stats => unexpected diff (-want, +got):\n%s", diff)
+			}
+
+			c, err := canvas.New(image.Rectangle{Max: tc.size})
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := s.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestNotifies(t *testing.T) {
+	s, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	s.SetNotifyFunc(func() {
+		notified++
+	})
+	for _, total := range []float64{1, 1, 2} {
+		if err := s.Update(total, total); err != nil {
+			t.Fatalf("Update => unexpected error: %v", err)
+		}
+	}
+	// The second update doesn't contain any new events.
+	if want := 2; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
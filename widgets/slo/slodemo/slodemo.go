@@ -0,0 +1,100 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary slodemo displays the availability objective of a simulated service
+// with an occasional outage.
+// Exits when 'q' is pressed.
+package main
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/slo"
+)
+
+// serve counts simulated requests and provides the counters to the widget
+// every 500ms. Every now and then the service fails a larger share of the
+// requests for a while. Exits when the context expires.
+func serve(ctx context.Context, s *slo.SLO) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	var good, total float64
+	for step := 0; ; step++ {
+		failRate := 0.0005
+		if step%60 >= 50 {
+			failRate = 0.01
+		}
+		for i := 0; i < 1000; i++ {
+			total++
+			if rand.Float64() >= failRate {
+				good++
+			}
+		}
+		if err := s.Update(good, total); err != nil {
+			panic(err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := slo.New(
+		slo.Label("availability"),
+		slo.Target(99.9),
+		slo.Decimals(3),
+	)
+	if err != nil {
+		panic(err)
+	}
+	go serve(ctx, s)
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS Q TO QUIT"),
+		container.PlaceWidget(s),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == 'q' || k.Key == 'Q' {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter), termdash.RedrawInterval(250*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
@@ -19,12 +19,14 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"math"
 	"sync"
 
 	"github.com/mum4k/termdash/cell"
 	"github.com/mum4k/termdash/private/area"
 	"github.com/mum4k/termdash/private/canvas"
 	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/series"
 	"github.com/mum4k/termdash/terminal/terminalapi"
 	"github.com/mum4k/termdash/widgetapi"
 )
@@ -39,9 +41,20 @@ type SparkLine struct {
 	// data are the data points the SparkLine displays.
 	data []int
 
+	// stream is the streamed series the data points are downsampled from on
+	// each call to Draw, nil if the data points were provided via Add.
+	// streamRev is the revision of the stream as of the last call to Draw.
+	stream    *series.Series
+	streamRev uint64
+
 	// lastWidth is the width of the canvas as of the last time when Draw was called.
 	lastWidth int
 
+	// drawn indicates that the current content was drawn, see Changed.
+	drawn bool
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
 	// mu protects the SparkLine.
 	mu sync.Mutex
 
@@ -70,6 +83,17 @@ func (sl *SparkLine) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
 
+	if err := sl.draw(cvs); err != nil {
+		return err
+	}
+	// Only set after a successful draw, so that a failed one is retried.
+	sl.drawn = true
+	return nil
+}
+
+// draw draws the SparkLine onto the canvas.
+// Caller must hold sl.mu.
+func (sl *SparkLine) draw(cvs *canvas.Canvas) error {
 	sl.lastWidth = cvs.Area().Dx()
 	needAr, err := area.FromSize(sl.minSize())
 	if err != nil {
@@ -80,6 +104,11 @@ func (sl *SparkLine) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	}
 
 	ar := sl.area(cvs)
+	if sl.stream != nil {
+		if err := sl.refreshStream(ar.Dx()); err != nil {
+			return err
+		}
+	}
 	visible, max := visibleMax(sl.data, ar.Dx())
 	var curX int
 	if len(visible) < ar.Dx() {
@@ -160,26 +189,106 @@ func (sl *SparkLine) Add(data []int, opts ...Option) error {
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
 
-	for _, opt := range opts {
-		opt.set(sl.opts)
+	if sl.stream != nil {
+		return errors.New("the SparkLine displays a streamed series, append the data points to the series or call Clear first")
 	}
-
 	for i, d := range data {
 		if d < 0 {
 			return fmt.Errorf("data point[%d]: %v must be a positive integer", i, d)
 		}
 	}
+
+	sl.markChanged()
+	for _, opt := range opts {
+		opt.set(sl.opts)
+	}
 	sl.data = append(sl.data, data...)
 	return nil
 }
 
+// Stream displays the streamed series on the SparkLine instead of the data
+// points provided via Add. Values appended to the series are displayed on the
+// next redraw. The series is downsampled to the width of the SparkLine, so
+// each bar displays one value regardless of how many values the series
+// holds. The values are rounded to the nearest integer, missing (NaN) values
+// are represented as missing bars and negative values result in an error
+// when drawing.
+// Call Clear to stop the streaming.
+//
+// Provided options override values set when New() was called.
+func (sl *SparkLine) Stream(s *series.Series, opts ...Option) error {
+	if s == nil {
+		return errors.New("the series cannot be nil")
+	}
+
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.markChanged()
+
+	for _, opt := range opts {
+		opt.set(sl.opts)
+	}
+	sl.stream = s
+	sl.data = nil
+	return nil
+}
+
+// refreshStream replaces the data points with the streamed series
+// downsampled to the width.
+// The caller must hold sl.mu.
+func (sl *SparkLine) refreshStream(width int) error {
+	sl.streamRev = sl.stream.Revision()
+	values := series.Values(sl.stream.Downsample(width))
+	data := make([]int, len(values))
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		d := int(math.Round(v))
+		if d < 0 {
+			return fmt.Errorf("streamed value %v must not be negative", v)
+		}
+		data[i] = d
+	}
+	sl.data = data
+	return nil
+}
+
 // Clear removes all the data points in the SparkLine, effectively returning to
-// an empty graph.
+// an empty graph. Also stops streaming the series provided via Stream.
 func (sl *SparkLine) Clear() {
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
+	sl.markChanged()
 
 	sl.data = nil
+	sl.stream = nil
+}
+
+// Changed implements widgetapi.ChangeTracker.Changed.
+func (sl *SparkLine) Changed() bool {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	if sl.stream != nil && sl.stream.Revision() != sl.streamRev {
+		return true
+	}
+	return !sl.drawn
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (sl *SparkLine) SetNotifyFunc(fn func()) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.notify = fn
+}
+
+// markChanged records that the content changed and notifies the
+// infrastructure. The caller must hold sl.mu.
+func (sl *SparkLine) markChanged() {
+	sl.drawn = false
+	if sl.notify != nil {
+		sl.notify()
+	}
 }
 
 // Keyboard input isn't supported on the SparkLine widget.
@@ -16,7 +16,9 @@ package sparkline
 
 import (
 	"image"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/kylelemons/godebug/pretty"
 	"github.com/mum4k/termdash/cell"
@@ -25,9 +27,23 @@ import (
 	"github.com/mum4k/termdash/private/draw"
 	"github.com/mum4k/termdash/private/draw/testdraw"
 	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/series"
 	"github.com/mum4k/termdash/widgetapi"
 )
 
+// mustSeries returns a series with the values or panics.
+func mustSeries(values ...float64) *series.Series {
+	s, err := series.New(len(values))
+	if err != nil {
+		panic(err)
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, v := range values {
+		s.Append(start.Add(time.Duration(i)*time.Second), v)
+	}
+	return s
+}
+
 func TestSparkLine(t *testing.T) {
 	tests := []struct {
 		desc          string
@@ -110,6 +126,112 @@ func TestSparkLine(t *testing.T) {
 			},
 			wantCapacity: 9,
 		},
+		{
+			desc: "stream fails on nil series",
+			update: func(sl *SparkLine) error {
+				return sl.Stream(nil)
+			},
+			canvas: image.Rect(0, 0, 1, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+			wantUpdateErr: true,
+		},
+		{
+			desc: "add fails while streaming",
+			update: func(sl *SparkLine) error {
+				if err := sl.Stream(mustSeries(1)); err != nil {
+					return err
+				}
+				return sl.Add([]int{1})
+			},
+			canvas: image.Rect(0, 0, 1, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+			wantUpdateErr: true,
+		},
+		{
+			desc: "draws the streamed series",
+			update: func(sl *SparkLine) error {
+				return sl.Stream(mustSeries(0, 1, 2, 3, 4, 5, 6, 7, 8))
+			},
+			canvas: image.Rect(0, 0, 9, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustText(c, "▁▂▃▄▅▆▇█", image.Point{1, 0}, draw.TextCellOpts(
+					cell.FgColor(DefaultColor),
+				))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+			wantCapacity: 9,
+		},
+		{
+			desc: "downsamples the streamed series to the width",
+			update: func(sl *SparkLine) error {
+				return sl.Stream(mustSeries(0, 0, 0, 0, 0, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0, 0, 0))
+			},
+			canvas: image.Rect(0, 0, 3, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustText(c, "█", image.Point{1, 0}, draw.TextCellOpts(
+					cell.FgColor(DefaultColor),
+				))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+			wantCapacity: 3,
+		},
+		{
+			desc: "missing streamed values are missing bars",
+			update: func(sl *SparkLine) error {
+				return sl.Stream(mustSeries(1, math.NaN(), 0.6))
+			},
+			canvas: image.Rect(0, 0, 3, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustText(c, "█", image.Point{0, 0}, draw.TextCellOpts(
+					cell.FgColor(DefaultColor),
+				))
+				testdraw.MustText(c, "█", image.Point{2, 0}, draw.TextCellOpts(
+					cell.FgColor(DefaultColor),
+				))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+			wantCapacity: 3,
+		},
+		{
+			desc: "draw fails on negative streamed values",
+			update: func(sl *SparkLine) error {
+				return sl.Stream(mustSeries(1, -1))
+			},
+			canvas:       image.Rect(0, 0, 3, 1),
+			wantCapacity: 3,
+			wantDrawErr:  true,
+		},
+		{
+			desc: "clear stops the streaming",
+			update: func(sl *SparkLine) error {
+				if err := sl.Stream(mustSeries(1, 2, 3)); err != nil {
+					return err
+				}
+				sl.Clear()
+				return nil
+			},
+			canvas: image.Rect(0, 0, 3, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+			wantCapacity: 3,
+		},
 		{
 			desc: "sets sparkline color",
 			opts: []Option{
@@ -570,3 +692,47 @@ func TestOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestChanged(t *testing.T) {
+	sl, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := sl.Stream(mustSeries(1, -1)); err != nil {
+		t.Fatalf("Stream => unexpected error: %v", err)
+	}
+	cvs := testcanvas.MustNew(image.Rect(0, 0, 3, 2))
+	if err := sl.Draw(cvs, &widgetapi.Meta{}); err == nil {
+		t.Fatalf("Draw => got nil error, want one for a negative value")
+	}
+	if !sl.Changed() {
+		t.Errorf("Changed => false after a failed Draw, want true")
+	}
+
+	sl.Clear()
+	if err := sl.Add([]int{1, 2}); err != nil {
+		t.Fatalf("Add => unexpected error: %v", err)
+	}
+	if err := sl.Draw(cvs, &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if sl.Changed() {
+		t.Errorf("Changed => true after Draw, want false")
+	}
+
+	var notified int
+	sl.SetNotifyFunc(func() { notified++ })
+	wantOpts := sl.Options()
+	if err := sl.Add([]int{-1}, Height(5)); err == nil {
+		t.Fatalf("Add => got nil error, want one for a negative value")
+	}
+	if sl.Changed() {
+		t.Errorf("Changed => true after a failed Add, want false")
+	}
+	if notified != 0 {
+		t.Errorf("Add => notified %d times, want 0", notified)
+	}
+	if diff := pretty.Compare(wantOpts, sl.Options()); diff != "" {
+		t.Errorf("Options => unexpected diff after a failed Add (-want, +got):\n%s", diff)
+	}
+}
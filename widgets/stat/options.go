@@ -0,0 +1,154 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stat
+
+// options.go contains configurable options for Stat.
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	label          string
+	labelCellOpts  []cell.Option
+	valueCellOpts  []cell.Option
+	valueFormatter format.Formatter
+	upColor        cell.Color
+	downColor      cell.Color
+	lowerIsBetter  bool
+	sparkLine      bool
+	sparkLineColor cell.Color
+	history        int
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		valueFormatter: format.Number(),
+		upColor:        DefaultUpColor,
+		downColor:      DefaultDownColor,
+		sparkLineColor: DefaultSparkLineColor,
+		history:        DefaultHistory,
+	}
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if o.valueFormatter == nil {
+		return errors.New("the ValueFormatter cannot be nil")
+	}
+	if min := 2; o.history < min {
+		return fmt.Errorf("invalid History(%d), must be at least %d", o.history, min)
+	}
+	return nil
+}
+
+// Label sets the text displayed above the value.
+func Label(text string, cOpts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.label = text
+		o.labelCellOpts = cOpts
+	})
+}
+
+// ValueCellOpts sets the cell options of the displayed value.
+func ValueCellOpts(cOpts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.valueCellOpts = cOpts
+	})
+}
+
+// ValueFormatter sets the function that formats the value and the delta,
+// e.g. format.SI.
+// Defaults to format.Number.
+func ValueFormatter(f format.Formatter) Option {
+	return option(func(o *options) {
+		o.valueFormatter = f
+	})
+}
+
+// Default colors of the delta.
+const (
+	DefaultUpColor   = cell.ColorGreen
+	DefaultDownColor = cell.ColorRed
+)
+
+// DeltaColors sets the colors of the delta when the value increased and when
+// it decreased compared to the previous period.
+// Defaults to DefaultUpColor and DefaultDownColor.
+func DeltaColors(up, down cell.Color) Option {
+	return option(func(o *options) {
+		o.upColor = up
+		o.downColor = down
+	})
+}
+
+// LowerIsBetter swaps the colors of the delta, for metrics where a decrease
+// is an improvement, e.g. latencies or error rates.
+func LowerIsBetter() Option {
+	return option(func(o *options) {
+		o.lowerIsBetter = true
+	})
+}
+
+// SparkLine displays a sparkline of the recent values under the delta.
+// The sparkline isn't displayed by default.
+func SparkLine() Option {
+	return option(func(o *options) {
+		o.sparkLine = true
+	})
+}
+
+// DefaultSparkLineColor is the default value for the SparkLineColor option.
+const DefaultSparkLineColor = cell.ColorBlue
+
+// SparkLineColor sets the color of the sparkline.
+// Defaults to DefaultSparkLineColor.
+func SparkLineColor(c cell.Color) Option {
+	return option(func(o *options) {
+		o.sparkLineColor = c
+	})
+}
+
+// DefaultHistory is the default value for the History option.
+const DefaultHistory = 300
+
+// History sets the maximum number of values kept for the sparkline, the
+// oldest values are forgotten first. Must be at least two.
+// Defaults to DefaultHistory.
+func History(values int) Option {
+	return option(func(o *options) {
+		o.history = values
+	})
+}
@@ -0,0 +1,397 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stat implements a widget that displays a single metric, its change
+// compared to a previous period and its recent trend.
+package stat
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sync"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/segdisp"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+	"github.com/mum4k/termdash/widgets/segmentdisplay"
+)
+
+// sparks are the characters used to draw the sparkline.
+var sparks = []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// Arrows that indicate the direction of the delta.
+const (
+	upArrow   = '▲'
+	downArrow = '▼'
+	noChange  = '='
+)
+
+// Stat displays the current value of a metric in large characters, the delta
+// compared to the value of a previous period, e.g. the same time yesterday,
+// with an arrow and a color indicating its direction, and optionally a
+// sparkline of the recent values.
+//
+// The rows from the top display the label, the value, the delta and the
+// sparkline. The value is displayed with a segment display if the space
+// allows, otherwise as text. The label, the delta and the sparkline are
+// omitted in the reverse order if the space doesn't allow.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type Stat struct {
+	// values are the recent values, the oldest first.
+	values []float64
+
+	// previous is the value of the previous period. Valid only if
+	// hasPrevious is true.
+	previous    float64
+	hasPrevious bool
+
+	// drawn indicates that the current content was drawn, see Changed.
+	drawn bool
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the Stat.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new Stat.
+func New(opts ...Option) (*Stat, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Stat{
+		opts: opt,
+	}, nil
+}
+
+// validValue returns an error if the value isn't a finite number.
+func validValue(value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("invalid value %v, must be a finite number", value)
+	}
+	return nil
+}
+
+// Update sets the current value of the metric. The previous values are kept
+// for the sparkline, see SparkLine and History.
+// The value must be a finite number.
+func (s *Stat) Update(value float64) error {
+	if err := validValue(value); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = append(s.values, value)
+	if over := len(s.values) - s.opts.history; over > 0 {
+		s.values = s.values[over:]
+	}
+	s.markChanged()
+	return nil
+}
+
+// SetPrevious sets the value of the metric in the previous period, the
+// current value is compared to it.
+// The value must be a finite number.
+func (s *Stat) SetPrevious(value float64) error {
+	if err := validValue(value); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.previous = value
+	s.hasPrevious = true
+	s.markChanged()
+	return nil
+}
+
+// Value returns the current value. Returns false if Update wasn't called.
+func (s *Stat) Value() (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.values) == 0 {
+		return 0, false
+	}
+	return s.values[len(s.values)-1], true
+}
+
+// Delta returns the difference between the current value and the value of
+// the previous period and the difference as a percentage of the previous
+// value. The percentage is NaN if the previous value is zero.
+// Returns false if either of the values wasn't provided.
+func (s *Stat) Delta() (delta, percent float64, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.delta()
+}
+
+// delta implements Delta.
+// The caller must hold s.mu.
+func (s *Stat) delta() (delta, percent float64, ok bool) {
+	if len(s.values) == 0 || !s.hasPrevious {
+		return 0, 0, false
+	}
+	delta = s.values[len(s.values)-1] - s.previous
+	if s.previous == 0 {
+		return delta, math.NaN(), true
+	}
+	return delta, delta / math.Abs(s.previous) * 100, true
+}
+
+// Changed implements widgetapi.ChangeTracker.Changed.
+func (s *Stat) Changed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.drawn
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (s *Stat) SetNotifyFunc(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notify = fn
+}
+
+// markChanged records that the content changed and notifies the
+// infrastructure. The caller must hold s.mu.
+func (s *Stat) markChanged() {
+	s.drawn = false
+	if s.notify != nil {
+		s.notify()
+	}
+}
+
+// layout are the rows of the canvas the parts of the widget are drawn on, a
+// part isn't displayed if its row is negative.
+type layout struct {
+	label     int
+	value     image.Rectangle
+	delta     int
+	sparkLine int
+}
+
+// newLayout divides the canvas among the parts of the widget.
+// The caller must hold s.mu.
+func (s *Stat) newLayout(ar image.Rectangle) *layout {
+	_, _, hasDelta := s.delta()
+	// The value always gets a row, the other parts the remaining rows in the
+	// order of their importance.
+	free := ar.Dy() - 1
+	use := func(want bool) bool {
+		if !want || free <= 0 {
+			return false
+		}
+		free--
+		return true
+	}
+	showDelta := use(hasDelta)
+	showLabel := use(s.opts.label != "")
+	showSparkLine := use(s.opts.sparkLine && len(s.values) > 1)
+
+	lay := &layout{label: -1, delta: -1, sparkLine: -1}
+	y := ar.Min.Y
+	if showLabel {
+		lay.label = y
+		y++
+	}
+	lay.value = image.Rect(ar.Min.X, y, ar.Max.X, y+1+free)
+	y = lay.value.Max.Y
+	if showDelta {
+		lay.delta = y
+		y++
+	}
+	if showSparkLine {
+		lay.sparkLine = y
+	}
+	return lay
+}
+
+// Draw draws the Stat widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (s *Stat) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ar := cvs.Area()
+	lay := s.newLayout(ar)
+	if lay.label >= 0 {
+		if err := drawText(cvs, s.opts.label, image.Point{ar.Min.X, lay.label}, ar.Max.X, s.opts.labelCellOpts...); err != nil {
+			return err
+		}
+	}
+	if len(s.values) > 0 {
+		if err := s.drawValue(cvs, lay.value); err != nil {
+			return err
+		}
+	}
+	if lay.delta >= 0 {
+		if err := s.drawDelta(cvs, image.Point{ar.Min.X, lay.delta}, ar.Max.X); err != nil {
+			return err
+		}
+	}
+	if lay.sparkLine >= 0 {
+		if err := s.drawSparkLine(cvs, image.Rect(ar.Min.X, lay.sparkLine, ar.Max.X, lay.sparkLine+1)); err != nil {
+			return err
+		}
+	}
+	s.drawn = true
+	return nil
+}
+
+// drawText draws the text trimmed to the maxX coordinate.
+func drawText(cvs *canvas.Canvas, text string, start image.Point, maxX int, cOpts ...cell.Option) error {
+	return draw.Text(cvs, text, start,
+		draw.TextMaxX(maxX),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		draw.TextCellOpts(cOpts...),
+	)
+}
+
+// drawValue draws the current value into the area, with a segment display if
+// the whole value fits, otherwise as text on the first row.
+// The caller must hold s.mu.
+func (s *Stat) drawValue(cvs *canvas.Canvas, ar image.Rectangle) error {
+	text := s.opts.valueFormatter(s.values[len(s.values)-1])
+	if ar.Dx() >= segdisp.MinCols && ar.Dy() >= segdisp.MinRows {
+		drawn, err := s.drawSegments(cvs, ar, text)
+		if err != nil {
+			return err
+		}
+		if drawn {
+			return nil
+		}
+	}
+	return drawText(cvs, text, ar.Min, ar.Max.X, s.opts.valueCellOpts...)
+}
+
+// drawSegments draws the text into the area with a segment display. Returns
+// false without drawing anything if the whole text doesn't fit.
+func (s *Stat) drawSegments(cvs *canvas.Canvas, ar image.Rectangle, text string) (bool, error) {
+	sd, err := segmentdisplay.New(
+		segmentdisplay.AlignHorizontal(align.HorizontalLeft),
+		segmentdisplay.MaximizeDisplayedText(),
+	)
+	if err != nil {
+		return false, err
+	}
+	if err := sd.Write([]*segmentdisplay.TextChunk{
+		segmentdisplay.NewChunk(text, segmentdisplay.WriteCellOpts(s.opts.valueCellOpts...)),
+	}); err != nil {
+		return false, err
+	}
+
+	sdCvs, err := canvas.New(ar)
+	if err != nil {
+		return false, err
+	}
+	if err := sd.Draw(sdCvs, &widgetapi.Meta{}); err != nil {
+		return false, err
+	}
+	if sd.Capacity() < len([]rune(text)) {
+		return false, nil
+	}
+	return true, sdCvs.CopyTo(cvs)
+}
+
+// drawDelta draws the arrow, the delta and the percentage.
+// The caller must hold s.mu.
+func (s *Stat) drawDelta(cvs *canvas.Canvas, start image.Point, maxX int) error {
+	delta, percent, _ := s.delta()
+
+	arrow, up := noChange, true
+	switch {
+	case delta > 0:
+		arrow = upArrow
+	case delta < 0:
+		arrow, up = downArrow, false
+	}
+	text := fmt.Sprintf("%c %s", arrow, s.opts.valueFormatter(math.Abs(delta)))
+	if !math.IsNaN(percent) {
+		text += fmt.Sprintf(" (%+.1f%%)", percent)
+	}
+
+	var cOpts []cell.Option
+	if delta != 0 {
+		if up == s.opts.lowerIsBetter {
+			cOpts = append(cOpts, cell.FgColor(s.opts.downColor))
+		} else {
+			cOpts = append(cOpts, cell.FgColor(s.opts.upColor))
+		}
+	}
+	return drawText(cvs, text, start, maxX, cOpts...)
+}
+
+// drawSparkLine draws a sparkline of the most recent values that fit into
+// the single row area, scaled between the smallest and the largest of them.
+// The caller must hold s.mu.
+func (s *Stat) drawSparkLine(cvs *canvas.Canvas, ar image.Rectangle) error {
+	values := s.values
+	if len(values) > ar.Dx() {
+		values = values[len(values)-ar.Dx():]
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+
+	x := ar.Max.X - len(values)
+	for _, v := range values {
+		i := 0
+		if max > min {
+			i = int(math.Round((v - min) / (max - min) * float64(len(sparks)-1)))
+		}
+		if _, err := cvs.SetCell(image.Point{x, ar.Min.Y}, sparks[i], cell.FgColor(s.opts.sparkLineColor)); err != nil {
+			return err
+		}
+		x++
+	}
+	return nil
+}
+
+// Keyboard input isn't supported on the Stat widget.
+func (*Stat) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the Stat widget doesn't support keyboard events")
+}
+
+// Mouse input isn't supported on the Stat widget.
+func (*Stat) Mouse(m *terminalapi.Mouse) error {
+	return errors.New("the Stat widget doesn't support mouse events")
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*Stat) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeNone,
+	}
+}
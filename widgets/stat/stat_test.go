@@ -0,0 +1,355 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stat
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/widgetapi"
+	"github.com/mum4k/termdash/widgets/segmentdisplay"
+)
+
+func TestStat(t *testing.T) {
+	tests := []struct {
+		desc       string
+		opts       []Option
+		previous   *float64
+		values     []float64
+		size       image.Point
+		want       func(size image.Point) *faketerm.Terminal
+		wantNewErr bool
+		wantErr    bool
+	}{
+		{
+			desc:       "fails on nil ValueFormatter",
+			opts:       []Option{ValueFormatter(nil)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on too small History",
+			opts:       []Option{History(1)},
+			wantNewErr: true,
+		},
+		{
+			desc:    "fails on NaN value",
+			values:  []float64{math.NaN()},
+			wantErr: true,
+		},
+		{
+			desc:     "fails on infinite previous value",
+			previous: floatPtr(math.Inf(1)),
+			wantErr:  true,
+		},
+		{
+			desc: "draws only the label without a value",
+			opts: []Option{Label("rps")},
+			size: image.Point{10, 3},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "rps", image.Point{0, 0})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc: "displays the label, the value and an increase",
+			opts: []Option{
+				Label("rps", cell.FgColor(cell.ColorBlue)),
+				ValueCellOpts(cell.FgColor(cell.ColorYellow)),
+			},
+			previous: floatPtr(100),
+			values:   []float64{120},
+			size:     image.Point{20, 3},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "rps", image.Point{0, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorBlue)))
+				testdraw.MustText(cvs, "120", image.Point{0, 1}, draw.TextCellOpts(cell.FgColor(cell.ColorYellow)))
+				testdraw.MustText(cvs, "▲ 20 (+20.0%)", image.Point{0, 2}, draw.TextCellOpts(cell.FgColor(DefaultUpColor)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "displays a decrease",
+			opts:     []Option{DeltaColors(cell.ColorCyan, cell.ColorMagenta)},
+			previous: floatPtr(100),
+			values:   []float64{80},
+			size:     image.Point{20, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "80", image.Point{0, 0})
+				testdraw.MustText(cvs, "▼ 20 (-20.0%)", image.Point{0, 1}, draw.TextCellOpts(cell.FgColor(cell.ColorMagenta)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "swaps the colors when lower is better",
+			opts:     []Option{LowerIsBetter()},
+			previous: floatPtr(100),
+			values:   []float64{80},
+			size:     image.Point{20, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "80", image.Point{0, 0})
+				testdraw.MustText(cvs, "▼ 20 (-20.0%)", image.Point{0, 1}, draw.TextCellOpts(cell.FgColor(DefaultUpColor)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "omits the percentage when the previous value is zero",
+			opts:     []Option{ValueFormatter(format.SI("B"))},
+			previous: floatPtr(0),
+			values:   []float64{0, 1500},
+			size:     image.Point{20, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "1.5kB", image.Point{0, 0})
+				testdraw.MustText(cvs, "▲ 1.5kB", image.Point{0, 1}, draw.TextCellOpts(cell.FgColor(DefaultUpColor)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "no change has neither arrow nor color",
+			previous: floatPtr(5),
+			values:   []float64{5},
+			size:     image.Point{20, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "5", image.Point{0, 0})
+				testdraw.MustText(cvs, "= 0 (+0.0%)", image.Point{0, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:     "omits the label and the delta when the space doesn't allow",
+			opts:     []Option{Label("rps"), SparkLine()},
+			previous: floatPtr(1),
+			values:   []float64{1, 2},
+			size:     image.Point{20, 1},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "2", image.Point{0, 0})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "trims the value that doesn't fit",
+			values: []float64{123456},
+			size:   image.Point{4, 1},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "123…", image.Point{0, 0})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "displays the sparkline of the most recent values",
+			opts:   []Option{SparkLine(), SparkLineColor(cell.ColorRed), History(3)},
+			values: []float64{100, 1, 2, 3},
+			size:   image.Point{10, 2},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "3", image.Point{0, 0})
+				red := cell.FgColor(cell.ColorRed)
+				testcanvas.MustSetCell(cvs, image.Point{7, 1}, '▁', red)
+				testcanvas.MustSetCell(cvs, image.Point{8, 1}, '▅', red)
+				testcanvas.MustSetCell(cvs, image.Point{9, 1}, '█', red)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "displays the value with a segment display when it fits",
+			opts:   []Option{ValueCellOpts(cell.FgColor(cell.ColorGreen))},
+			values: []float64{42},
+			size:   image.Point{20, 5},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				sd, err := segmentdisplay.New(
+					segmentdisplay.AlignHorizontal(align.HorizontalLeft),
+					segmentdisplay.MaximizeDisplayedText(),
+				)
+				if err != nil {
+					t.Fatalf("segmentdisplay.New => unexpected error: %v", err)
+				}
+				if err := sd.Write([]*segmentdisplay.TextChunk{
+					segmentdisplay.NewChunk("42", segmentdisplay.WriteCellOpts(cell.FgColor(cell.ColorGreen))),
+				}); err != nil {
+					t.Fatalf("Write => unexpected error: %v", err)
+				}
+				if err := sd.Draw(cvs, &widgetapi.Meta{}); err != nil {
+					t.Fatalf("Draw => unexpected error: %v", err)
+				}
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "falls back to text when the segment display can't fit the value",
+			values: []float64{123},
+			size:   image.Point{6, 5},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "123", image.Point{0, 0})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := New(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if tc.previous != nil {
+				err := s.SetPrevious(*tc.previous)
+				if (err != nil) != tc.wantErr {
+					t.Errorf("SetPrevious => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+				}
+				if err != nil {
+					return
+				}
+			}
+			for _, v := range tc.values {
+				err := s.Update(v)
+				if (err != nil) != tc.wantErr {
+					t.Errorf("Update => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+				}
+				if err != nil {
+					return
+				}
+			}
+
+			c, err := canvas.New(image.Rectangle{Max: tc.size})
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := s.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+// floatPtr returns a pointer to the value.
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestDelta(t *testing.T) {
+	s, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if _, ok := s.Value(); ok {
+		t.Errorf("Value => ok before Update, want !ok")
+	}
+	if _, _, ok := s.Delta(); ok {
+		t.Errorf("Delta => ok before Update, want !ok")
+	}
+
+	if err := s.Update(150); err != nil {
+		t.Fatalf("Update => unexpected error: %v", err)
+	}
+	if got, ok := s.Value(); !ok || got != 150 {
+		t.Errorf("Value => %v, %v, want 150, true", got, ok)
+	}
+	if _, _, ok := s.Delta(); ok {
+		t.Errorf("Delta => ok without a previous value, want !ok")
+	}
+
+	if err := s.SetPrevious(-200); err != nil {
+		t.Fatalf("SetPrevious => unexpected error: %v", err)
+	}
+	delta, percent, ok := s.Delta()
+	if !ok || delta != 350 || percent != 175 {
+		t.Errorf("Delta => %v, %v, %v, want 350, 175, true", delta, percent, ok)
+	}
+}
+
+func TestNotifies(t *testing.T) {
+	s, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	s.SetNotifyFunc(func() {
+		notified++
+	})
+
+	if err := s.Update(1); err != nil {
+		t.Fatalf("Update => unexpected error: %v", err)
+	}
+	if err := s.SetPrevious(2); err != nil {
+		t.Fatalf("SetPrevious => unexpected error: %v", err)
+	}
+	if want := 2; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+
+	if !s.Changed() {
+		t.Errorf("Changed => false before Draw, want true")
+	}
+	if err := s.Draw(testcanvas.MustNew(image.Rect(0, 0, 10, 2)), &widgetapi.Meta{}); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if s.Changed() {
+		t.Errorf("Changed => true after Draw, want false")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary statdemo displays two simulated metrics compared to their values in
+// the previous period.
+// Exits when 'q' is pressed.
+package main
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/format"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/stat"
+)
+
+// walk provides values randomly walking around the previous value to the
+// widget every 500ms. Exits when the context expires.
+func walk(ctx context.Context, s *stat.Stat, previous, step float64) {
+	if err := s.SetPrevious(previous); err != nil {
+		panic(err)
+	}
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	value := previous
+	for {
+		value += (rand.Float64() - 0.5) * step
+		if value < 0 {
+			value = 0
+		}
+		if err := s.Update(value); err != nil {
+			panic(err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	revenue, err := stat.New(
+		stat.Label("revenue today"),
+		stat.ValueFormatter(format.Currency("$")),
+		stat.ValueCellOpts(cell.FgColor(cell.ColorYellow)),
+		stat.SparkLine(),
+	)
+	if err != nil {
+		panic(err)
+	}
+	go walk(ctx, revenue, 1200, 100)
+
+	latency, err := stat.New(
+		stat.Label("p99 latency"),
+		stat.ValueFormatter(format.Duration(time.Millisecond)),
+		stat.LowerIsBetter(),
+		stat.SparkLine(),
+		stat.SparkLineColor(cell.ColorMagenta),
+	)
+	if err != nil {
+		panic(err)
+	}
+	go walk(ctx, latency, 250, 40)
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS Q TO QUIT"),
+		container.SplitVertical(
+			container.Left(
+				container.Border(linestyle.Light),
+				container.PlaceWidget(revenue),
+			),
+			container.Right(
+				container.Border(linestyle.Light),
+				container.PlaceWidget(latency),
+			),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == 'q' || k.Key == 'Q' {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter), termdash.RedrawInterval(250*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
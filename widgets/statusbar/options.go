@@ -0,0 +1,97 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statusbar
+
+// options.go contains configurable options for StatusBar.
+
+import (
+	"fmt"
+
+	"github.com/mum4k/termdash/cell"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	cellOpts        []cell.Option
+	messageCellOpts []cell.Option
+	gap             int
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		cellOpts: []cell.Option{
+			cell.FgColor(cell.ColorBlack),
+			cell.BgColor(cell.ColorWhite),
+		},
+		messageCellOpts: []cell.Option{
+			cell.FgColor(cell.ColorBlack),
+			cell.BgColor(cell.ColorYellow),
+		},
+		gap: DefaultGap,
+	}
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if min := 0; o.gap < min {
+		return fmt.Errorf("invalid Gap(%d), must be %d <= value", o.gap, min)
+	}
+	return nil
+}
+
+// CellOpts sets the cell options of the status bar, i.e. of the background
+// and of sections that don't have their own cell options.
+// Defaults to black text on a white background.
+func CellOpts(opts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.cellOpts = opts
+	})
+}
+
+// MessageCellOpts sets the cell options of messages that don't have their own
+// cell options.
+// Defaults to black text on a yellow background.
+func MessageCellOpts(opts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.messageCellOpts = opts
+	})
+}
+
+// DefaultGap is the default value for the Gap option.
+const DefaultGap = 1
+
+// Gap sets the minimum number of cells between the sections. The sections are
+// truncated to keep the gap when the status bar is too narrow.
+// Defaults to DefaultGap.
+func Gap(cells int) Option {
+	return option(func(o *options) {
+		o.gap = cells
+	})
+}
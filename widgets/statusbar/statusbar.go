@@ -0,0 +1,343 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package statusbar implements a widget that displays a status bar.
+package statusbar
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/private/wrap"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// timeNow can be replaced in tests.
+var timeNow = time.Now
+
+// section is text displayed in the status bar.
+type section struct {
+	text     string
+	cellOpts []cell.Option
+}
+
+// message is a section that disappears once it expires.
+type message struct {
+	section
+	expires time.Time
+}
+
+// StatusBar displays a single row with three sections of text aligned to the
+// left, the center and the right. The sections are truncated when they don't
+// fit. A transient message can be displayed in place of the left section
+// for a limited time.
+//
+// The status bar can be placed into a container like any other widget or
+// attached to the top or bottom of the terminal outside of the layout using
+// the container.StatusBar option.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type StatusBar struct {
+	// sections are the displayed sections.
+	sections map[align.Horizontal]*section
+
+	// msg is the displayed message or nil if there is none.
+	msg *message
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
+	// mu protects the StatusBar.
+	mu sync.Mutex
+
+	// opts are the provided options.
+	opts *options
+}
+
+// New returns a new StatusBar.
+func New(opts ...Option) (*StatusBar, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &StatusBar{
+		sections: map[align.Horizontal]*section{},
+		opts:     opt,
+	}, nil
+}
+
+// validateText validates the text of a section or a message.
+func validateText(text string) error {
+	if err := wrap.ValidText(text); err != nil {
+		return err
+	}
+	if strings.Contains(text, "\n") {
+		return fmt.Errorf("the text %q cannot contain newline characters", text)
+	}
+	return nil
+}
+
+// sectionNames are the supported alignments of the sections.
+var sectionNames = map[align.Horizontal]bool{
+	align.HorizontalLeft:   true,
+	align.HorizontalCenter: true,
+	align.HorizontalRight:  true,
+}
+
+// Set sets the text of the section with the alignment. An empty text removes
+// the section. The cell options apply on top of the options provided via
+// CellOpts.
+func (sb *StatusBar) Set(h align.Horizontal, text string, opts ...cell.Option) error {
+	if text != "" {
+		if err := validateText(text); err != nil {
+			return err
+		}
+	}
+	if _, ok := sectionNames[h]; !ok {
+		return fmt.Errorf("unsupported alignment %v of the section", h)
+	}
+
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if text == "" {
+		delete(sb.sections, h)
+	} else {
+		sb.sections[h] = &section{
+			text:     text,
+			cellOpts: opts,
+		}
+	}
+	sb.markChanged()
+	return nil
+}
+
+// SetValue sets the text of the section with the alignment to the label
+// followed by the value formatted by the formatter, e.g. format.Percent.
+// The label is optional. The cell options apply on top of the options
+// provided via CellOpts.
+func (sb *StatusBar) SetValue(h align.Horizontal, label string, v float64, f format.Formatter, opts ...cell.Option) error {
+	if f == nil {
+		return errors.New("the formatter cannot be nil")
+	}
+	text := f(v)
+	if label != "" {
+		text = fmt.Sprintf("%s %s", label, text)
+	}
+	return sb.Set(h, text, opts...)
+}
+
+// Message displays the text in place of the left section for the duration,
+// replacing any previous message. The cell options apply on top of the
+// options provided via MessageCellOpts.
+func (sb *StatusBar) Message(text string, d time.Duration, opts ...cell.Option) error {
+	if err := validateText(text); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("invalid message duration %v, must be positive", d)
+	}
+
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	msg := &message{
+		section: section{
+			text:     text,
+			cellOpts: opts,
+		},
+		expires: timeNow().Add(d),
+	}
+	sb.msg = msg
+	sb.markChanged()
+
+	time.AfterFunc(d, func() {
+		sb.mu.Lock()
+		defer sb.mu.Unlock()
+		if sb.msg == msg {
+			sb.msg = nil
+			sb.markChanged()
+		}
+	})
+	return nil
+}
+
+// ClearMessage removes the displayed message before it expires.
+func (sb *StatusBar) ClearMessage() {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.msg != nil {
+		sb.msg = nil
+		sb.markChanged()
+	}
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (sb *StatusBar) SetNotifyFunc(fn func()) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	sb.notify = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// The caller must hold sb.mu.
+func (sb *StatusBar) markChanged() {
+	if sb.notify != nil {
+		sb.notify()
+	}
+}
+
+// span is a part of the status bar row.
+type span struct {
+	start int
+	width int
+}
+
+// layout positions the left, center and right sections of the widths on a
+// row of the width. The right section is truncated last and the center
+// section first.
+func layout(width, left, center, right, gap int) (l, c, r span) {
+	r.width = minInt(right, width)
+	r.start = width - r.width
+
+	avail := r.start
+	if r.width > 0 {
+		avail -= gap
+	}
+	l.width = minInt(left, maxInt(avail, 0))
+
+	from, to := 0, r.start
+	if l.width > 0 {
+		from = l.width + gap
+	}
+	if r.width > 0 {
+		to -= gap
+	}
+	if to <= from {
+		return l, span{}, r
+	}
+	c.width = minInt(center, to-from)
+	c.start = (width - c.width) / 2
+	if c.start < from {
+		c.start = from
+	}
+	if c.start+c.width > to {
+		c.start = to - c.width
+	}
+	return l, c, r
+}
+
+// Draw draws the StatusBar widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (sb *StatusBar) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	if sb.msg != nil && !timeNow().Before(sb.msg.expires) {
+		sb.msg = nil
+	}
+	if err := cvs.SetAreaCells(cvs.Area(), ' ', sb.opts.cellOpts...); err != nil {
+		return err
+	}
+
+	left := sb.sections[align.HorizontalLeft]
+	leftOpts := sb.opts.cellOpts
+	if sb.msg != nil {
+		left = &sb.msg.section
+		leftOpts = sb.opts.messageCellOpts
+	}
+	center := sb.sections[align.HorizontalCenter]
+	right := sb.sections[align.HorizontalRight]
+
+	l, c, r := layout(cvs.Area().Dx(), textWidth(left), textWidth(center), textWidth(right), sb.opts.gap)
+	for _, s := range []struct {
+		sec      *section
+		span     span
+		baseOpts []cell.Option
+	}{
+		{left, l, leftOpts},
+		{center, c, sb.opts.cellOpts},
+		{right, r, sb.opts.cellOpts},
+	} {
+		if s.sec == nil || s.span.width == 0 {
+			continue
+		}
+		cellOpts := append(append([]cell.Option{}, s.baseOpts...), s.sec.cellOpts...)
+		if err := draw.Text(cvs, s.sec.text, image.Point{s.span.start, 0},
+			draw.TextMaxX(s.span.start+s.span.width),
+			draw.TextOverrunMode(draw.OverrunModeThreeDot),
+			draw.TextCellOpts(cellOpts...),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// textWidth returns the width of the text of the section in cells, zero if
+// the section is nil.
+func textWidth(s *section) int {
+	if s == nil {
+		return 0
+	}
+	return runewidth.StringWidth(s.text)
+}
+
+// Keyboard input isn't supported on the StatusBar widget.
+func (*StatusBar) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the StatusBar widget doesn't support keyboard events")
+}
+
+// Mouse input isn't supported on the StatusBar widget.
+func (*StatusBar) Mouse(m *terminalapi.Mouse) error {
+	return errors.New("the StatusBar widget doesn't support mouse events")
+}
+
+// Options implements widgetapi.Widget.Options.
+func (*StatusBar) Options() widgetapi.Options {
+	return widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		MaximumSize:  image.Point{0, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeNone,
+	}
+}
+
+// minInt returns the smaller of the two integers.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the larger of the two integers.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
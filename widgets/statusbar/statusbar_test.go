@@ -0,0 +1,346 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statusbar
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// The default cell options of the bar and of the messages.
+var (
+	barOpts = []cell.Option{cell.FgColor(cell.ColorBlack), cell.BgColor(cell.ColorWhite)}
+	msgOpts = []cell.Option{cell.FgColor(cell.ColorBlack), cell.BgColor(cell.ColorYellow)}
+)
+
+// mustText draws the text truncated to the width starting at the column.
+func mustText(cvs *canvas.Canvas, text string, x, width int, opts ...cell.Option) {
+	testdraw.MustText(cvs, text, image.Point{x, 0},
+		draw.TextMaxX(x+width),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+		draw.TextCellOpts(opts...),
+	)
+}
+
+func TestStatusBar(t *testing.T) {
+	tests := []struct {
+		desc   string
+		opts   []Option
+		update func(*StatusBar) error
+		// elapsed is the time that passes between the update and Draw.
+		elapsed       time.Duration
+		width         int
+		want          func(size image.Point) *faketerm.Terminal
+		wantNewErr    bool
+		wantUpdateErr bool
+	}{
+		{
+			desc:       "fails on negative gap",
+			opts:       []Option{Gap(-1)},
+			wantNewErr: true,
+		},
+		{
+			desc: "fails on section with newline",
+			update: func(sb *StatusBar) error {
+				return sb.Set(align.HorizontalLeft, "a\nb")
+			},
+			wantUpdateErr: true,
+		},
+		{
+			desc: "fails on unsupported alignment",
+			update: func(sb *StatusBar) error {
+				return sb.Set(align.Horizontal(-1), "a")
+			},
+			wantUpdateErr: true,
+		},
+		{
+			desc: "fails on message without duration",
+			update: func(sb *StatusBar) error {
+				return sb.Message("a", 0)
+			},
+			wantUpdateErr: true,
+		},
+		{
+			desc:  "empty status bar",
+			width: 10,
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ', barOpts...)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:  "aligns the sections",
+			width: 20,
+			update: func(sb *StatusBar) error {
+				if err := sb.Set(align.HorizontalLeft, "ab"); err != nil {
+					return err
+				}
+				if err := sb.Set(align.HorizontalCenter, "cd", cell.FgColor(cell.ColorRed)); err != nil {
+					return err
+				}
+				return sb.Set(align.HorizontalRight, "ef")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ', barOpts...)
+				mustText(cvs, "ab", 0, 2, barOpts...)
+				mustText(cvs, "cd", 9, 2, append(barOpts, cell.FgColor(cell.ColorRed))...)
+				mustText(cvs, "ef", 18, 2, barOpts...)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:          "fails on a value without a formatter",
+			update:        func(sb *StatusBar) error { return sb.SetValue(align.HorizontalLeft, "cpu", 1, nil) },
+			wantUpdateErr: true,
+		},
+		{
+			desc:  "displays formatted values",
+			width: 20,
+			update: func(sb *StatusBar) error {
+				if err := sb.SetValue(align.HorizontalLeft, "cpu", 42.54, format.Percent()); err != nil {
+					return err
+				}
+				return sb.SetValue(align.HorizontalRight, "", 1536, format.IEC("B"))
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ', barOpts...)
+				mustText(cvs, "cpu 42.5%", 0, 9, barOpts...)
+				mustText(cvs, "1.5KiB", 14, 6, barOpts...)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:  "an empty text removes the section",
+			width: 10,
+			update: func(sb *StatusBar) error {
+				if err := sb.Set(align.HorizontalLeft, "ab"); err != nil {
+					return err
+				}
+				return sb.Set(align.HorizontalLeft, "")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ', barOpts...)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:  "moves the center section to keep the gap",
+			width: 12,
+			update: func(sb *StatusBar) error {
+				if err := sb.Set(align.HorizontalLeft, "abcdef"); err != nil {
+					return err
+				}
+				return sb.Set(align.HorizontalCenter, "gh")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ', barOpts...)
+				mustText(cvs, "abcdef", 0, 6, barOpts...)
+				mustText(cvs, "gh", 7, 2, barOpts...)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:  "truncates the center section first and the left one next",
+			opts:  []Option{Gap(2)},
+			width: 10,
+			update: func(sb *StatusBar) error {
+				if err := sb.Set(align.HorizontalLeft, "abcdef"); err != nil {
+					return err
+				}
+				if err := sb.Set(align.HorizontalCenter, "gh"); err != nil {
+					return err
+				}
+				return sb.Set(align.HorizontalRight, "ijk")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ', barOpts...)
+				mustText(cvs, "abcdef", 0, 5, barOpts...)
+				mustText(cvs, "ijk", 7, 3, barOpts...)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:  "displays the message in place of the left section",
+			opts:  []Option{MessageCellOpts(cell.FgColor(cell.ColorRed))},
+			width: 10,
+			update: func(sb *StatusBar) error {
+				if err := sb.Set(align.HorizontalLeft, "ab"); err != nil {
+					return err
+				}
+				return sb.Message("saved", time.Second, cell.BgColor(cell.ColorGreen))
+			},
+			elapsed: 500 * time.Millisecond,
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ', barOpts...)
+				mustText(cvs, "saved", 0, 5, cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorGreen))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:  "the message disappears after the duration",
+			width: 10,
+			update: func(sb *StatusBar) error {
+				if err := sb.Set(align.HorizontalLeft, "ab"); err != nil {
+					return err
+				}
+				return sb.Message("saved", time.Second)
+			},
+			elapsed: time.Second,
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ', barOpts...)
+				mustText(cvs, "ab", 0, 2, barOpts...)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:  "clears the message",
+			width: 10,
+			update: func(sb *StatusBar) error {
+				if err := sb.Message("saved", time.Second); err != nil {
+					return err
+				}
+				sb.ClearMessage()
+				return nil
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), ' ', barOpts...)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			timeNow = func() time.Time { return now }
+			defer func() { timeNow = time.Now }()
+
+			sb, err := New(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if tc.update != nil {
+				err := tc.update(sb)
+				if (err != nil) != tc.wantUpdateErr {
+					t.Errorf("update => unexpected error: %v, wantUpdateErr: %v", err, tc.wantUpdateErr)
+				}
+				if err != nil {
+					return
+				}
+			}
+			now = now.Add(tc.elapsed)
+
+			c, err := canvas.New(image.Rect(0, 0, tc.width, 1))
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := sb.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestNotify(t *testing.T) {
+	sb, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := make(chan struct{}, 10)
+	sb.SetNotifyFunc(func() { notified <- struct{}{} })
+
+	if err := sb.Message("saved", time.Millisecond); err != nil {
+		t.Fatalf("Message => unexpected error: %v", err)
+	}
+	// Once when the message is displayed and once when it expires.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-notified:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the notify function wasn't called after the message expired")
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	sb, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	got := sb.Options()
+	want := widgetapi.Options{
+		MinimumSize:  image.Point{1, 1},
+		MaximumSize:  image.Point{0, 1},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeNone,
+	}
+	if got != want {
+		t.Errorf("Options => got %+v, want %+v", got, want)
+	}
+}
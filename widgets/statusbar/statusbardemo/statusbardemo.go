@@ -0,0 +1,105 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary statusbardemo displays a status bar at the bottom of the terminal.
+// Exits when 'q' is pressed.
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/align"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/statusbar"
+	"github.com/mum4k/termdash/widgets/text"
+)
+
+// playClock displays the current time in the right section of the status bar
+// once every second. Exits when the context expires.
+func playClock(ctx context.Context, sb *statusbar.StatusBar) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		if err := sb.Set(align.HorizontalRight, time.Now().Format("15:04:05")); err != nil {
+			panic(err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sb, err := statusbar.New()
+	if err != nil {
+		panic(err)
+	}
+	if err := sb.Set(align.HorizontalLeft, "statusbardemo"); err != nil {
+		panic(err)
+	}
+	if err := sb.Set(align.HorizontalCenter, "m: message", cell.FgColor(cell.ColorBlue)); err != nil {
+		panic(err)
+	}
+	go playClock(ctx, sb)
+
+	txt, err := text.New()
+	if err != nil {
+		panic(err)
+	}
+	if err := txt.Write("Press 'm' to display a message in the status bar for two seconds."); err != nil {
+		panic(err)
+	}
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS Q TO QUIT"),
+		container.PlaceWidget(txt),
+		container.StatusBar(sb, align.VerticalBottom),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	keys := func(k *terminalapi.Keyboard) {
+		switch k.Key {
+		case 'm', 'M':
+			if err := sb.Message(time.Now().Format("15:04:05")+" message received", 2*time.Second); err != nil {
+				panic(err)
+			}
+		case 'q', 'Q':
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(keys), termdash.RedrawInterval(100*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+// fold.go contains code that folds the collapsible sections of the text.
+
+import (
+	"github.com/mum4k/termdash/private/canvas/buffer"
+	"github.com/mum4k/termdash/private/wrap"
+)
+
+// gutterWidth is the width of the gutter with the section markers, i.e. the
+// marker followed by a space.
+const gutterWidth = 2
+
+// section is a collapsible section of the text.
+type section struct {
+	// header is the index of the first line of the section, it remains
+	// visible when the section is collapsed.
+	header int
+	// last is the index of the last line of the section.
+	last int
+	// collapsed indicates whether the lines after the header are hidden.
+	collapsed bool
+}
+
+// foldLines splits the content into lines, omits lines hidden in collapsed
+// sections and wraps the remaining lines to the specified width.
+// Returns the wrapped lines and a map of indexes of wrapped lines that start
+// the headers of sections to the indexes of the sections.
+func foldLines(content []*buffer.Cell, sections []*section, width int, m wrap.Mode) ([][]*buffer.Cell, map[int]int, error) {
+	hidden := map[int]bool{}
+	headerOf := map[int]int{}
+	for i, s := range sections {
+		headerOf[s.header] = i
+		if s.collapsed {
+			for l := s.header + 1; l <= s.last; l++ {
+				hidden[l] = true
+			}
+		}
+	}
+
+	var (
+		res     [][]*buffer.Cell
+		line    int
+		start   int
+		headers = map[int]int{}
+	)
+	addLine := func(cells []*buffer.Cell) error {
+		if hidden[line] {
+			return nil
+		}
+		wr := [][]*buffer.Cell{nil} // An empty line.
+		if len(cells) > 0 {
+			var err error
+			if wr, err = wrap.Cells(cells, width, m); err != nil {
+				return err
+			}
+		}
+		if si, ok := headerOf[line]; ok {
+			headers[len(res)] = si
+		}
+		res = append(res, wr...)
+		return nil
+	}
+
+	for i, c := range content {
+		if c.Rune != '\n' {
+			continue
+		}
+		if err := addLine(content[start:i]); err != nil {
+			return nil, nil, err
+		}
+		start = i + 1
+		line++
+	}
+	if err := addLine(content[start:]); err != nil {
+		return nil, nil, err
+	}
+	return res, headers, nil
+}
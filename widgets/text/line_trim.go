@@ -47,7 +47,7 @@ func drawTrimChar(cvs *canvas.Canvas, line int) error {
 			return err
 		}
 
-		if runewidth.RuneWidth(prev.Rune) == 2 {
+		if runewidth.CellWidth(prev.Rune, prev.Combining) == 2 {
 			if _, err := cvs.SetCell(penUlt, 0); err != nil {
 				return err
 			}
@@ -85,8 +85,22 @@ func lineTrim(cvs *canvas.Canvas, curPoint image.Point, curRune rune, opts *opti
 		}, nil
 	}
 
+	// Combining characters and the rest of emoji sequences are drawn over
+	// the previous cell, they never need trimming on their own.
+	if cvs.Joins(curPoint, curRune) {
+		return &trimResult{
+			trimmed:  false,
+			curPoint: curPoint,
+		}, nil
+	}
+
 	width := cvs.Area().Dx()
 	rw := runewidth.RuneWidth(curRune)
+	if rw == 0 && runewidth.IsCombining(curRune) {
+		// Without a previous cell, the canvas places the combining
+		// character into a cell of its own.
+		rw = 1
+	}
 	switch {
 	case rw == 1:
 		if curPoint.X == width {
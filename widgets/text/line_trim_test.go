@@ -54,6 +54,22 @@ func TestLineTrim(t *testing.T) {
 				return faketerm.MustNew(size)
 			},
 		},
+		{
+			desc:     "combining character after the last cell isn't trimmed",
+			cvs:      testcanvas.MustNew(cvsArea),
+			curPoint: image.Point{10, 0},
+			curRune:  '\u0301',
+			opts: &options{
+				wrapMode: wrap.Never,
+			},
+			wantRes: &trimResult{
+				trimmed:  false,
+				curPoint: image.Point{10, 0},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+		},
 		{
 			desc:     "half-width rune, end of the canvas, fits",
 			cvs:      testcanvas.MustNew(cvsArea),
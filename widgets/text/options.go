@@ -16,9 +16,12 @@ package text
 
 import (
 	"fmt"
+	"regexp"
 
+	"github.com/mum4k/termdash/cell"
 	"github.com/mum4k/termdash/keyboard"
 	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/runewidth"
 	"github.com/mum4k/termdash/private/wrap"
 )
 
@@ -43,6 +46,13 @@ type options struct {
 	keyDown          keyboard.Key
 	keyPgUp          keyboard.Key
 	keyPgDown        keyboard.Key
+	styleRules       []*styleRule
+	foldExpanded     rune
+	foldCollapsed    rune
+	foldSelectedOpts []cell.Option
+	keyFoldPrev      keyboard.Key
+	keyFoldNext      keyboard.Key
+	keyFoldToggle    keyboard.Key
 }
 
 // newOptions returns a new options instance.
@@ -56,6 +66,14 @@ func newOptions(opts ...Option) *options {
 		keyDown:         DefaultScrollKeyDown,
 		keyPgUp:         DefaultScrollKeyPageUp,
 		keyPgDown:       DefaultScrollKeyPageDown,
+		foldExpanded:    DefaultFoldExpandedRune,
+		foldCollapsed:   DefaultFoldCollapsedRune,
+		foldSelectedOpts: []cell.Option{
+			cell.FgColor(DefaultFoldSelectedColor),
+		},
+		keyFoldPrev:   DefaultFoldKeyPrev,
+		keyFoldNext:   DefaultFoldKeyNext,
+		keyFoldToggle: DefaultFoldKeyToggle,
 	}
 	for _, o := range opts {
 		o.set(opt)
@@ -74,9 +92,25 @@ func (o *options) validate() error {
 	if len(keys) != 4 {
 		return fmt.Errorf("invalid ScrollKeys(up:%v, down:%v, pageUp:%v, pageDown:%v), the keys must be unique", o.keyUp, o.keyDown, o.keyPgUp, o.keyPgDown)
 	}
+	keys[o.keyFoldPrev] = true
+	keys[o.keyFoldNext] = true
+	keys[o.keyFoldToggle] = true
+	if len(keys) != 7 {
+		return fmt.Errorf("invalid FoldKeys(prev:%v, next:%v, toggle:%v), the keys must be unique and differ from the scroll keys", o.keyFoldPrev, o.keyFoldNext, o.keyFoldToggle)
+	}
+	for _, r := range []rune{o.foldExpanded, o.foldCollapsed} {
+		if got := runewidth.RuneWidth(r); got != 1 {
+			return fmt.Errorf("invalid FoldMarkers rune %q, it occupies %d cells, the markers must occupy exactly one cell", r, got)
+		}
+	}
 	if o.mouseUpButton == o.mouseDownButton {
 		return fmt.Errorf("invalid ScrollMouseButtons(up:%v, down:%v), the buttons must be unique", o.mouseUpButton, o.mouseDownButton)
 	}
+	for i, sr := range o.styleRules {
+		if sr.pred == nil && sr.re == nil {
+			return fmt.Errorf("invalid style rule #%d, the predicate or the regular expression must not be nil", i)
+		}
+	}
 	return nil
 }
 
@@ -174,3 +208,86 @@ func ScrollKeys(up, down, pageUp, pageDown keyboard.Key) Option {
 		opts.keyPgDown = pageDown
 	})
 }
+
+// StyleLines adds a styling rule that sets the cell options on entire lines
+// of text for which the predicate returns true. The predicate receives the
+// lines as written, i.e. before they are wrapped to the width of the widget,
+// along with any metadata provided via the WriteMeta option.
+//
+// The rules are evaluated when the text is drawn, so the styling remains
+// correct regardless of line wrapping or scrolling. Rules are applied in the
+// order they were provided, options of later rules override options of the
+// earlier rules and the options provided via WriteCellOpts.
+// This option can be provided multiple times.
+func StyleLines(pred LinePredicate, opts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.styleRules = append(o.styleRules, &styleRule{
+			pred:     pred,
+			cellOpts: opts,
+		})
+	})
+}
+
+// StyleMatches adds a styling rule that sets the cell options on all parts of
+// lines that match the regular expression. The regular expression is matched
+// against individual lines as written, i.e. before they are wrapped to the
+// width of the widget.
+//
+// See StyleLines for details on the order in which the rules apply.
+// This option can be provided multiple times.
+func StyleMatches(re *regexp.Regexp, opts ...cell.Option) Option {
+	return option(func(o *options) {
+		o.styleRules = append(o.styleRules, &styleRule{
+			re:       re,
+			cellOpts: opts,
+		})
+	})
+}
+
+// The default runes used as markers of collapsible sections.
+const (
+	DefaultFoldExpandedRune  = '▾'
+	DefaultFoldCollapsedRune = '▸'
+)
+
+// FoldMarkers configures the runes drawn in the gutter next to the headers of
+// expanded and collapsed sections. See the WriteCollapsible write option.
+// Both runes must occupy exactly one cell.
+func FoldMarkers(expanded, collapsed rune) Option {
+	return option(func(opts *options) {
+		opts.foldExpanded = expanded
+		opts.foldCollapsed = collapsed
+	})
+}
+
+// DefaultFoldSelectedColor is the default color of the marker of the selected
+// section.
+const DefaultFoldSelectedColor = cell.ColorYellow
+
+// FoldSelectedCellOpts sets the cell options of the marker of the selected
+// section, which is highlighted while the widget is focused.
+// Defaults to DefaultFoldSelectedColor as the foreground color.
+func FoldSelectedCellOpts(cOpts ...cell.Option) Option {
+	return option(func(opts *options) {
+		opts.foldSelectedOpts = cOpts
+	})
+}
+
+// The default keys for working with collapsible sections.
+const (
+	DefaultFoldKeyPrev   = '['
+	DefaultFoldKeyNext   = ']'
+	DefaultFoldKeyToggle = keyboard.KeyEnter
+)
+
+// FoldKeys configures the keyboard keys that select the previous or the next
+// collapsible section and the key that collapses or expands the selected
+// section. The provided keys must be unique and differ from the keys set by
+// ScrollKeys.
+func FoldKeys(prev, next, toggle keyboard.Key) Option {
+	return option(func(opts *options) {
+		opts.keyFoldPrev = prev
+		opts.keyFoldNext = next
+		opts.keyFoldToggle = toggle
+	})
+}
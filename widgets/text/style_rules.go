@@ -0,0 +1,121 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+// style_rules.go contains code that applies styling rules to the text.
+
+import (
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/private/canvas/buffer"
+)
+
+// Line is a single line of text as written to the widget, i.e. before the
+// widget wraps it to the width of the canvas.
+type Line struct {
+	// Index is the zero-based position of the line within the content.
+	Index int
+
+	// Text is the text on the line excluding the newline character.
+	Text string
+
+	// Meta is the metadata attached to the line by the WriteMeta option or
+	// nil if the line has no metadata.
+	Meta interface{}
+}
+
+// LinePredicate decides whether a styling rule applies to the line.
+// The predicate is called while the widget holds its lock, it must not call
+// methods of the widget.
+type LinePredicate func(*Line) bool
+
+// styleRule is a single styling rule.
+type styleRule struct {
+	// pred when set, the rule applies to entire lines for which it returns
+	// true.
+	pred LinePredicate
+
+	// re when set, the rule applies to the parts of lines that match the
+	// regular expression.
+	re *regexp.Regexp
+
+	// cellOpts are the cell options applied to the matching cells.
+	cellOpts []cell.Option
+}
+
+// apply applies the rule to the cells of a single line.
+func (sr *styleRule) apply(line *Line, cells []*buffer.Cell) {
+	if sr.pred != nil {
+		if sr.pred(line) {
+			for _, c := range cells {
+				c.Apply(sr.cellOpts...)
+			}
+		}
+		return
+	}
+
+	for _, m := range sr.re.FindAllStringIndex(line.Text, -1) {
+		// The regexp returns byte offsets, cells are indexed by runes.
+		from := utf8.RuneCountInString(line.Text[:m[0]])
+		to := from + utf8.RuneCountInString(line.Text[m[0]:m[1]])
+		for _, c := range cells[from:to] {
+			c.Apply(sr.cellOpts...)
+		}
+	}
+}
+
+// styleLines applies the styling rules to the content and returns the styled
+// copy of the content. The provided content isn't modified.
+// The argument meta maps line indexes to their metadata.
+func styleLines(content []*buffer.Cell, meta map[int]interface{}, rules []*styleRule) []*buffer.Cell {
+	if len(rules) == 0 {
+		return content
+	}
+
+	styled := make([]*buffer.Cell, len(content))
+	for i, c := range content {
+		styled[i] = c.Copy()
+	}
+
+	var (
+		index int // Index of the current line.
+		start int // Position of the first cell of the current line.
+	)
+	for i := 0; i <= len(styled); i++ {
+		if i < len(styled) && styled[i].Rune != '\n' {
+			continue
+		}
+
+		cells := styled[start:i]
+		runes := make([]rune, len(cells))
+		for j, c := range cells {
+			runes[j] = c.Rune
+		}
+		line := &Line{
+			Index: index,
+			Text:  string(runes),
+			Meta:  meta[index],
+		}
+		for _, r := range rules {
+			r.apply(line, cells)
+		}
+
+		index++
+		start = i + 1
+	}
+	return styled
+}
@@ -18,9 +18,12 @@ package text
 import (
 	"fmt"
 	"image"
+	"strings"
 	"sync"
 
 	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/mouse"
 	"github.com/mum4k/termdash/private/canvas/buffer"
 	"github.com/mum4k/termdash/private/wrap"
 	"github.com/mum4k/termdash/terminal/terminalapi"
@@ -41,9 +44,32 @@ type Text struct {
 	// content is the text content that will be displayed in the widget as
 	// provided by the caller (i.e. not wrapped or pre-processed).
 	content []*buffer.Cell
+	// lineMeta maps indexes of lines in the content to the metadata provided
+	// via the WriteMeta option.
+	lineMeta map[int]interface{}
+	// lastLine is the index of the last line in the content.
+	lastLine int
 	// wrapped is the content wrapped to the current width of the canvas.
 	wrapped [][]*buffer.Cell
 
+	// sections are the collapsible sections in the order they were written.
+	sections []*section
+	// selected is the index of the selected section.
+	selected int
+	// reveal indicates that the header of the selected section should be
+	// scrolled into view on the next draw.
+	reveal bool
+	// headers maps indexes of the wrapped lines that start section headers to
+	// indexes of the sections.
+	headers map[int]int
+	// lastFromLine is the index of the first wrapped line that was drawn on
+	// the last canvas.
+	lastFromLine int
+	// lastGutter is the width of the gutter on the last canvas.
+	lastGutter int
+	// lastHeight is the height of the last canvas.
+	lastHeight int
+
 	// scroll tracks scrolling the position.
 	scroll *scrollTracker
 
@@ -55,6 +81,9 @@ type Text struct {
 	// invalidated.
 	contentChanged bool
 
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+
 	// mu protects the Text widget.
 	mu sync.Mutex
 
@@ -69,8 +98,9 @@ func New(opts ...Option) (*Text, error) {
 		return nil, err
 	}
 	return &Text{
-		scroll: newScrollTracker(opt),
-		opts:   opt,
+		lineMeta: map[int]interface{}{},
+		scroll:   newScrollTracker(opt),
+		opts:     opt,
 	}, nil
 }
 
@@ -79,12 +109,24 @@ func (t *Text) Reset() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.reset()
+	if t.notify != nil {
+		t.notify()
+	}
 }
 
 // reset implements Reset, caller must hold t.mu.
 func (t *Text) reset() {
 	t.content = nil
+	t.lineMeta = map[int]interface{}{}
+	t.lastLine = 0
 	t.wrapped = nil
+	t.sections = nil
+	t.selected = 0
+	t.reveal = false
+	t.headers = nil
+	t.lastFromLine = 0
+	t.lastGutter = 0
+	t.lastHeight = 0
 	t.scroll = newScrollTracker(t.opts)
 	t.lastWidth = 0
 	t.contentChanged = true
@@ -108,13 +150,44 @@ func (t *Text) Write(text string, wOpts ...WriteOption) error {
 	if opts.replace {
 		t.reset()
 	}
+	header := t.lastLine
 	for _, r := range text {
 		t.content = append(t.content, buffer.NewCell(r, opts.cellOpts))
+		if opts.meta != nil {
+			t.lineMeta[t.lastLine] = opts.meta
+		}
+		if r == '\n' {
+			t.lastLine++
+		}
+	}
+
+	if opts.collapsible {
+		last := t.lastLine
+		if strings.HasSuffix(text, "\n") {
+			last--
+		}
+		if last > header {
+			t.sections = append(t.sections, &section{
+				header:    header,
+				last:      last,
+				collapsed: opts.collapsed,
+			})
+		}
 	}
 	t.contentChanged = true
+	if t.notify != nil {
+		t.notify()
+	}
 	return nil
 }
 
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (t *Text) SetNotifyFunc(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.notify = fn
+}
+
 // minLinesForMarkers are the minimum amount of lines required on the canvas in
 // order to draw the scroll markers ('⇧' and '⇩').
 const minLinesForMarkers = 3
@@ -156,11 +229,73 @@ func (t *Text) drawScrollDown(cvs *canvas.Canvas, cur image.Point, fromLine int)
 	return false, nil
 }
 
+// scrollMarkerAt returns true if a scroll marker replaces the line of text at
+// the specified row of a canvas with the provided height.
+func (t *Text) scrollMarkerAt(row, height, fromLine int) bool {
+	if height < minLinesForMarkers {
+		return false
+	}
+	return (row == 0 && fromLine > 0) || (row == height-1 && height < len(t.wrapped)-fromLine)
+}
+
+// revealSelected scrolls the header of the selected section into view on a
+// canvas of the specified height. The rows with scroll markers are avoided.
+func (t *Text) revealSelected(height int) {
+	for idx, si := range t.headers {
+		if si != t.selected {
+			continue
+		}
+
+		first := t.scroll.first
+		switch {
+		case idx <= first:
+			t.scroll.scroll = idx - 1 - first
+		case idx >= first+height-1:
+			t.scroll.scroll = idx - height + 2 - first
+		}
+		return
+	}
+}
+
+// drawGutter draws the markers of the sections whose headers are visible on
+// the canvas. The marker of the selected section is highlighted if the widget
+// is focused.
+func (t *Text) drawGutter(cvs *canvas.Canvas, focused bool) error {
+	height := cvs.Area().Dy()
+	for row := 0; row < height; row++ {
+		si, ok := t.headers[t.lastFromLine+row]
+		if !ok || t.scrollMarkerAt(row, height, t.lastFromLine) {
+			continue
+		}
+
+		r := t.opts.foldExpanded
+		if t.sections[si].collapsed {
+			r = t.opts.foldCollapsed
+		}
+		var opts []cell.Option
+		if focused && si == t.selected {
+			opts = t.opts.foldSelectedOpts
+		}
+		if _, err := cvs.SetCell(image.Point{0, row}, r, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toggle collapses or expands the specified section and selects it.
+func (t *Text) toggle(si int) {
+	t.sections[si].collapsed = !t.sections[si].collapsed
+	t.selected = si
+	t.contentChanged = true
+}
+
 // draw draws the text context on the canvas starting at the specified line.
 func (t *Text) draw(cvs *canvas.Canvas) error {
 	var cur image.Point // Tracks the current drawing position on the canvas.
 	height := cvs.Area().Dy()
 	fromLine := t.scroll.firstLine(len(t.wrapped), height)
+	t.lastFromLine = fromLine
 
 	for _, line := range t.wrapped[fromLine:] {
 		// Scroll up marker.
@@ -211,22 +346,62 @@ func (t *Text) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	defer t.mu.Unlock()
 
 	width := cvs.Area().Dx()
+	gutter := 0
+	if len(t.sections) > 0 && width > gutterWidth {
+		gutter = gutterWidth
+	}
 	if len(t.content) > 0 && (t.contentChanged || t.lastWidth != width) {
 		// The previous text preprocessing (line wrapping) is invalidated when
 		// new text is added or the width of the canvas changed.
-		wr, err := wrap.Cells(t.content, width, t.opts.wrapMode)
-		if err != nil {
-			return err
+		styled := styleLines(t.content, t.lineMeta, t.opts.styleRules)
+		if len(t.sections) > 0 {
+			wr, headers, err := foldLines(styled, t.sections, width-gutter, t.opts.wrapMode)
+			if err != nil {
+				return err
+			}
+			t.wrapped = wr
+			t.headers = headers
+		} else {
+			wr, err := wrap.Cells(styled, width, t.opts.wrapMode)
+			if err != nil {
+				return err
+			}
+			t.wrapped = wr
 		}
-		t.wrapped = wr
 	}
 	t.lastWidth = width
+	t.lastGutter = gutter
+	t.lastHeight = cvs.Area().Dy()
 
 	if len(t.wrapped) == 0 {
 		return nil // Nothing to draw if there's no text.
 	}
 
-	if err := t.draw(cvs); err != nil {
+	if t.reveal {
+		t.revealSelected(cvs.Area().Dy())
+		t.reveal = false
+	}
+
+	if gutter == 0 {
+		if err := t.draw(cvs); err != nil {
+			return err
+		}
+		t.contentChanged = false
+		return nil
+	}
+
+	ar := cvs.Area()
+	textCvs, err := canvas.New(image.Rect(ar.Min.X+gutter, ar.Min.Y, ar.Max.X, ar.Max.Y))
+	if err != nil {
+		return err
+	}
+	if err := t.draw(textCvs); err != nil {
+		return err
+	}
+	if err := textCvs.CopyTo(cvs); err != nil {
+		return err
+	}
+	if err := t.drawGutter(cvs, meta.Focused); err != nil {
 		return err
 	}
 	t.contentChanged = false
@@ -247,6 +422,21 @@ func (t *Text) Keyboard(k *terminalapi.Keyboard) error {
 		t.scroll.upOnePage()
 	case k.Key == t.opts.keyPgDown:
 		t.scroll.downOnePage()
+	case len(t.sections) == 0:
+		return nil
+	case k.Key == t.opts.keyFoldPrev:
+		if t.selected > 0 {
+			t.selected--
+		}
+		t.reveal = true
+	case k.Key == t.opts.keyFoldNext:
+		if t.selected < len(t.sections)-1 {
+			t.selected++
+		}
+		t.reveal = true
+	case k.Key == t.opts.keyFoldToggle:
+		t.toggle(t.selected)
+		t.reveal = true
 	}
 	return nil
 }
@@ -261,6 +451,16 @@ func (t *Text) Mouse(m *terminalapi.Mouse) error {
 		t.scroll.upOneLine()
 	case b == t.opts.mouseDownButton:
 		t.scroll.downOneLine()
+	case b == mouse.ButtonLeft && m.Position.X < t.lastGutter:
+		// A click in the gutter toggles the section whose marker is on the
+		// clicked row.
+		row := m.Position.Y
+		if t.scrollMarkerAt(row, t.lastHeight, t.lastFromLine) {
+			return nil
+		}
+		if si, ok := t.headers[t.lastFromLine+row]; ok {
+			t.toggle(si)
+		}
 	}
 	return nil
 }
@@ -16,6 +16,7 @@ package text
 
 import (
 	"image"
+	"regexp"
 	"testing"
 
 	"github.com/kylelemons/godebug/pretty"
@@ -248,6 +249,23 @@ func TestTextDraws(t *testing.T) {
 				return ft
 			},
 		},
+		{
+			desc:   "combining characters don't take cells",
+			canvas: image.Rect(0, 0, 5, 3),
+			writes: func(widget *Text) error {
+				return widget.Write("cafe\u0301!\nabcde\u0301\nabcdef\u0301")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustText(c, "cafe\u0301!", image.Point{0, 0})
+				testdraw.MustText(c, "abcde\u0301", image.Point{0, 1})
+				testdraw.MustText(c, "abcd…", image.Point{0, 2})
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
 		{
 			desc:   "trims content when longer than canvas, no scroll marker on small canvas",
 			canvas: image.Rect(0, 0, 10, 2),
@@ -768,6 +786,307 @@ func TestTextDraws(t *testing.T) {
 				return ft
 			},
 		},
+		{
+			desc:   "fails on a style rule without a regular expression",
+			canvas: image.Rect(0, 0, 1, 1),
+			opts: []Option{
+				StyleMatches(nil, cell.FgColor(cell.ColorRed)),
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+			wantErr: true,
+		},
+		{
+			desc:   "styles lines based on their metadata",
+			canvas: image.Rect(0, 0, 10, 3),
+			opts: []Option{
+				StyleLines(func(l *Line) bool {
+					return l.Meta == "error"
+				}, cell.FgColor(cell.ColorRed)),
+			},
+			writes: func(widget *Text) error {
+				if err := widget.Write("info\n"); err != nil {
+					return err
+				}
+				if err := widget.Write("err\nerr2", WriteMeta("error")); err != nil {
+					return err
+				}
+				return nil
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustText(c, "info", image.Point{0, 0})
+				testdraw.MustText(c, "err", image.Point{0, 1}, draw.TextCellOpts(cell.FgColor(cell.ColorRed)))
+				testdraw.MustText(c, "err2", image.Point{0, 2}, draw.TextCellOpts(cell.FgColor(cell.ColorRed)))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "styles lines based on their text",
+			canvas: image.Rect(0, 0, 10, 2),
+			opts: []Option{
+				StyleLines(func(l *Line) bool {
+					return l.Index == 1 && l.Text == "second"
+				}, cell.BgColor(cell.ColorBlue)),
+			},
+			writes: func(widget *Text) error {
+				return widget.Write("first\nsecond")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustText(c, "first", image.Point{0, 0})
+				testdraw.MustText(c, "second", image.Point{0, 1}, draw.TextCellOpts(cell.BgColor(cell.ColorBlue)))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "styles regular expression matches across wrapped lines",
+			canvas: image.Rect(0, 0, 5, 2),
+			opts: []Option{
+				WrapAtRunes(),
+				StyleMatches(regexp.MustCompile("ERROR"), cell.FgColor(cell.ColorRed)),
+			},
+			writes: func(widget *Text) error {
+				return widget.Write("a ERROR", WriteCellOpts(cell.BgColor(cell.ColorBlue)))
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustText(c, "a ", image.Point{0, 0}, draw.TextCellOpts(cell.BgColor(cell.ColorBlue)))
+				testdraw.MustText(c, "ERR", image.Point{2, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorBlue)))
+				testdraw.MustText(c, "OR", image.Point{0, 1}, draw.TextCellOpts(cell.FgColor(cell.ColorRed), cell.BgColor(cell.ColorBlue)))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "later rules override earlier rules",
+			canvas: image.Rect(0, 0, 10, 1),
+			opts: []Option{
+				StyleMatches(regexp.MustCompile("ab"), cell.FgColor(cell.ColorRed)),
+				StyleMatches(regexp.MustCompile("b"), cell.FgColor(cell.ColorGreen)),
+			},
+			writes: func(widget *Text) error {
+				return widget.Write("你ab")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustText(c, "你", image.Point{0, 0})
+				testdraw.MustText(c, "a", image.Point{2, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorRed)))
+				testdraw.MustText(c, "b", image.Point{3, 0}, draw.TextCellOpts(cell.FgColor(cell.ColorGreen)))
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc: "fails when fold keys aren't unique",
+			opts: []Option{
+				FoldKeys('[', keyboard.KeyArrowUp, 'x'),
+			},
+			canvas: image.Rect(0, 0, 1, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+			wantErr: true,
+		},
+		{
+			desc: "fails when a fold marker is a full-width rune",
+			opts: []Option{
+				FoldMarkers('世', '+'),
+			},
+			canvas: image.Rect(0, 0, 1, 1),
+			want: func(size image.Point) *faketerm.Terminal {
+				return faketerm.MustNew(size)
+			},
+			wantErr: true,
+		},
+		{
+			desc:   "draws an expanded section with a marker in the gutter",
+			canvas: image.Rect(0, 0, 10, 4),
+			meta:   &widgetapi.Meta{},
+			writes: func(widget *Text) error {
+				if err := widget.Write("head\nbody\n", WriteCollapsible(false)); err != nil {
+					return err
+				}
+				return widget.Write("tail")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetCell(c, image.Point{0, 0}, DefaultFoldExpandedRune)
+				testdraw.MustText(c, "head", image.Point{2, 0})
+				testdraw.MustText(c, "body", image.Point{2, 1})
+				testdraw.MustText(c, "tail", image.Point{2, 2})
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "a collapsed section only shows its header",
+			canvas: image.Rect(0, 0, 10, 4),
+			meta:   &widgetapi.Meta{},
+			writes: func(widget *Text) error {
+				if err := widget.Write("head\nbody\n", WriteCollapsible(true)); err != nil {
+					return err
+				}
+				return widget.Write("tail")
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetCell(c, image.Point{0, 0}, DefaultFoldCollapsedRune)
+				testdraw.MustText(c, "head", image.Point{2, 0})
+				testdraw.MustText(c, "tail", image.Point{2, 1})
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "a single line doesn't form a section",
+			canvas: image.Rect(0, 0, 10, 4),
+			meta:   &widgetapi.Meta{},
+			writes: func(widget *Text) error {
+				return widget.Write("one\n", WriteCollapsible(true))
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustText(c, "one", image.Point{0, 0})
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "keyboard selects and collapses a section",
+			canvas: image.Rect(0, 0, 10, 4),
+			meta:   &widgetapi.Meta{},
+			writes: func(widget *Text) error {
+				if err := widget.Write("a\nb\n", WriteCollapsible(false)); err != nil {
+					return err
+				}
+				return widget.Write("c\nd\n", WriteCollapsible(false))
+			},
+			events: func(widget *Text) {
+				widget.Keyboard(&terminalapi.Keyboard{Key: DefaultFoldKeyNext})
+				widget.Keyboard(&terminalapi.Keyboard{Key: DefaultFoldKeyToggle})
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetCell(c, image.Point{0, 0}, DefaultFoldExpandedRune)
+				testdraw.MustText(c, "a", image.Point{2, 0})
+				testdraw.MustText(c, "b", image.Point{2, 1})
+				testcanvas.MustSetCell(c, image.Point{0, 2}, DefaultFoldCollapsedRune)
+				testdraw.MustText(c, "c", image.Point{2, 2})
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "highlights the marker of the selected section when focused",
+			canvas: image.Rect(0, 0, 10, 4),
+			meta:   &widgetapi.Meta{Focused: true},
+			writes: func(widget *Text) error {
+				if err := widget.Write("a\nb\n", WriteCollapsible(false)); err != nil {
+					return err
+				}
+				return widget.Write("c\nd", WriteCollapsible(false))
+			},
+			events: func(widget *Text) {
+				widget.Keyboard(&terminalapi.Keyboard{Key: DefaultFoldKeyNext})
+				widget.Keyboard(&terminalapi.Keyboard{Key: DefaultFoldKeyNext})
+				widget.Keyboard(&terminalapi.Keyboard{Key: DefaultFoldKeyPrev})
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetCell(c, image.Point{0, 0}, DefaultFoldExpandedRune, cell.FgColor(DefaultFoldSelectedColor))
+				testdraw.MustText(c, "a", image.Point{2, 0})
+				testdraw.MustText(c, "b", image.Point{2, 1})
+				testcanvas.MustSetCell(c, image.Point{0, 2}, DefaultFoldExpandedRune)
+				testdraw.MustText(c, "c", image.Point{2, 2})
+				testdraw.MustText(c, "d", image.Point{2, 3})
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "clicking on a marker toggles the section",
+			canvas: image.Rect(0, 0, 10, 4),
+			meta:   &widgetapi.Meta{},
+			writes: func(widget *Text) error {
+				if err := widget.Write("a\nb\n", WriteCollapsible(true)); err != nil {
+					return err
+				}
+				return widget.Write("c\nd\n", WriteCollapsible(false))
+			},
+			events: func(widget *Text) {
+				cvs := testcanvas.MustNew(image.Rect(0, 0, 10, 4))
+				if err := widget.Draw(cvs, &widgetapi.Meta{}); err != nil {
+					panic(err)
+				}
+				widget.Mouse(&terminalapi.Mouse{
+					Position: image.Point{0, 0},
+					Button:   mouse.ButtonLeft,
+				})
+				widget.Mouse(&terminalapi.Mouse{
+					Position: image.Point{0, 1},
+					Button:   mouse.ButtonLeft,
+				})
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetCell(c, image.Point{0, 0}, DefaultFoldExpandedRune)
+				testdraw.MustText(c, "a", image.Point{2, 0})
+				testdraw.MustText(c, "b", image.Point{2, 1})
+				testcanvas.MustSetCell(c, image.Point{0, 2}, DefaultFoldCollapsedRune)
+				testdraw.MustText(c, "c", image.Point{2, 2})
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "scrolls the selected section into view",
+			canvas: image.Rect(0, 0, 10, 3),
+			meta:   &widgetapi.Meta{},
+			writes: func(widget *Text) error {
+				if err := widget.Write("1\n2\n3\n4\n5\n"); err != nil {
+					return err
+				}
+				return widget.Write("h\nb\n", WriteCollapsible(false))
+			},
+			events: func(widget *Text) {
+				widget.Keyboard(&terminalapi.Keyboard{Key: DefaultFoldKeyNext})
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				c := testcanvas.MustNew(ft.Area())
+
+				testdraw.MustText(c, "⇧", image.Point{2, 0})
+				testcanvas.MustSetCell(c, image.Point{0, 1}, DefaultFoldExpandedRune)
+				testdraw.MustText(c, "h", image.Point{2, 1})
+				testdraw.MustText(c, "⇩", image.Point{2, 2})
+				testcanvas.MustApply(c, ft)
+				return ft
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -30,6 +30,10 @@ type WriteOption interface {
 type writeOptions struct {
 	cellOpts *cell.Options
 	replace  bool
+	meta     interface{}
+
+	collapsible bool
+	collapsed   bool
 }
 
 // newWriteOptions returns new writeOptions instance.
@@ -65,3 +69,33 @@ func WriteReplace() WriteOption {
 		wOpts.replace = true
 	})
 }
+
+// WriteMeta attaches the metadata to all the lines that contain text from
+// this write. The metadata is provided to the predicates of styling rules,
+// see the StyleLines option.
+// If a line contains text from multiple writes, the metadata of the last
+// write wins.
+func WriteMeta(meta interface{}) WriteOption {
+	return writeOption(func(wOpts *writeOptions) {
+		wOpts.meta = meta
+	})
+}
+
+// WriteCollapsible makes the text from this write a collapsible section. The
+// first line of the text is the header of the section, it remains visible when
+// the section is collapsed, while the remaining lines are hidden. The section
+// starts collapsed if collapsed is true.
+//
+// Sections are toggled using the keyboard or by clicking on the marker in the
+// gutter that the widget draws on the left when it contains any sections.
+// See the FoldKeys and FoldMarkers options.
+//
+// The text should end with a newline, otherwise the last line of the section
+// is shared with any text written afterwards. Text with a single line doesn't
+// form a section, since there is nothing to collapse.
+func WriteCollapsible(collapsed bool) WriteOption {
+	return writeOption(func(wOpts *writeOptions) {
+		wOpts.collapsible = true
+		wOpts.collapsed = collapsed
+	})
+}
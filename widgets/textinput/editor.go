@@ -25,26 +25,25 @@ import (
 )
 
 // fieldData are the data currently present inside the text input field.
-type fieldData []rune
+// Each element is a single grapheme cluster, i.e. a base rune followed by any
+// combining characters, see runewidth.Clusters. The cursor moves and the
+// editing operations work on whole clusters.
+type fieldData []string
 
 // String implements fmt.Stringer.
 func (fd fieldData) String() string {
-	var b strings.Builder
-	for _, r := range fd {
-		b.WriteRune(r)
-	}
-	return fmt.Sprintf("%q", b.String())
+	return fmt.Sprintf("%q", strings.Join(fd, ""))
 }
 
-// insertAt inserts rune at the specified index.
-func (fd *fieldData) insertAt(idx int, r rune) {
+// insertAt inserts the cluster at the specified index.
+func (fd *fieldData) insertAt(idx int, c string) {
 	*fd = append(
 		(*fd)[:idx],
-		append(fieldData{r}, (*fd)[idx:]...)...,
+		append(fieldData{c}, (*fd)[idx:]...)...,
 	)
 }
 
-// deleteAt deletes rune at the specified index.
+// deleteAt deletes the cluster at the specified index.
 func (fd *fieldData) deleteAt(idx int) {
 	*fd = append((*fd)[:idx], (*fd)[idx+1:]...)
 }
@@ -59,7 +58,7 @@ func (fd *fieldData) cellsBefore(cells, endIdx int) int {
 	usedCells := 0
 	for i := endIdx; i > 0; i-- {
 		prev := (*fd)[i-1]
-		width := runewidth.RuneWidth(prev)
+		width := runewidth.StringWidth(prev)
 
 		if usedCells+width > cells {
 			return i
@@ -77,10 +76,9 @@ func (fd *fieldData) cellsAfter(cells, startIdx int) int {
 	}
 
 	first := (*fd)[startIdx]
-	usedCells := runewidth.RuneWidth(first)
+	usedCells := runewidth.StringWidth(first)
 	for i := startIdx + 1; i < len(*fd); i++ {
-		r := (*fd)[i]
-		width := runewidth.RuneWidth(r)
+		width := runewidth.StringWidth((*fd)[i])
 		if usedCells+width > cells {
 			return i
 		}
@@ -181,16 +179,16 @@ func (fd *fieldData) lastVisible(end int) bool {
 	return end-1 >= len(*fd)
 }
 
-// runesIn returns all the runes in the visible range.
+// runesIn returns all the clusters in the visible range.
 // The visible range includes all fieldData indexes
 // in range start <= idx < end.
-func (fd *fieldData) runesIn(start, end int) []rune {
-	var runes []rune
-	for i, r := range (*fd)[start:] {
+func (fd *fieldData) runesIn(start, end int) []string {
+	var runes []string
+	for i, c := range (*fd)[start:] {
 		if i+start > end-2 { // One last space is for the cursor after the text.
 			break
 		}
-		runes = append(runes, r)
+		runes = append(runes, c)
 	}
 	return runes
 }
@@ -230,20 +228,20 @@ func (fd *fieldData) fitRunes(firstRune, curPos, cells int) (string, int, int) {
 	runes := fd.runesIn(start, end)
 	useArrows := cells >= minForArrows
 	var b strings.Builder
-	for i, r := range runes {
+	for i, c := range runes {
 		switch {
 		case useArrows && i == 0 && start > 0:
 			// Indicate that start is hidden by replacing the first visible
 			// rune with an arrow.
 			b.WriteRune('⇦')
-			if rw := runewidth.RuneWidth(r); rw == 2 {
+			if rw := runewidth.StringWidth(c); rw == 2 {
 				// If the replaced rune was a full-width rune, place two arrows
 				// to keep the same space allocation as pre-calculated.
 				b.WriteRune('⇦')
 			}
 
 		default:
-			b.WriteRune(r)
+			b.WriteString(c)
 		}
 	}
 
@@ -294,7 +292,7 @@ func (fe *fieldEditor) curCell(width int) int {
 
 	cellNum := 0
 	rn := 0
-	for i, r := range fe.data {
+	for i, c := range fe.data {
 		if i < fe.firstRune {
 			continue
 		}
@@ -302,7 +300,7 @@ func (fe *fieldEditor) curCell(width int) int {
 			break
 		}
 		rn++
-		cellNum += runewidth.RuneWidth(r)
+		cellNum += runewidth.StringWidth(c)
 	}
 	return cellNum
 }
@@ -321,7 +319,7 @@ func (fe *fieldEditor) viewFor(width int) (string, int, error) {
 
 // content returns the string content in the field editor.
 func (fe *fieldEditor) content() string {
-	return string(fe.data)
+	return strings.Join(fe.data, "")
 }
 
 // reset resets the content back to zero.
@@ -330,13 +328,30 @@ func (fe *fieldEditor) reset() {
 }
 
 // insert inserts the rune at the current position of the cursor.
+// Combining characters and the rest of emoji sequences are added to the
+// cluster before the cursor.
 func (fe *fieldEditor) insert(r rune) {
+	if fe.curDataPos > 0 {
+		var t runewidth.Tracker
+		for _, cr := range fe.data[fe.curDataPos-1] {
+			t.Add(cr)
+		}
+		if t.Joins(r) {
+			fe.data[fe.curDataPos-1] += string(r)
+			return
+		}
+	}
+	if runewidth.IsCombining(r) {
+		// Nothing to combine with.
+		return
+	}
+
 	rw := runewidth.RuneWidth(r)
 	if rw == 0 {
 		// Don't insert invisible runes.
 		return
 	}
-	fe.data.insertAt(fe.curDataPos, r)
+	fe.data.insertAt(fe.curDataPos, string(r))
 	fe.curDataPos++
 }
 
@@ -394,8 +409,8 @@ func (fe *fieldEditor) cursorRelCell(cellIdx int) {
 	// range.
 	var relRuneIdx int
 	var cell int
-	for _, r := range runes {
-		cell += runewidth.RuneWidth(r)
+	for _, c := range runewidth.Clusters(runes) {
+		cell += runewidth.StringWidth(c)
 		if cell > cellIdx {
 			break
 		}
@@ -415,3 +430,53 @@ func (fe *fieldEditor) cursorRelCell(cellIdx int) {
 		fe.curDataPos = dataIdx
 	}
 }
+
+// withPreedit returns a copy of the editor with the preedit text inserted at
+// the cursor and the cursor placed at the position within the preedit text in
+// runes. Also returns the range of data indexes the preedit text occupies
+// within the copy.
+func (fe *fieldEditor) withPreedit(preedit string, cursor int) (*fieldEditor, int, int) {
+	cp := &fieldEditor{
+		data:       append(fieldData(nil), fe.data...),
+		curDataPos: fe.curDataPos,
+		firstRune:  fe.firstRune,
+		width:      fe.width,
+	}
+	start := cp.curDataPos
+	// Cursor positions after each of the runes of the preedit text.
+	var after []int
+	for _, r := range preedit {
+		cp.insert(r)
+		after = append(after, cp.curDataPos)
+	}
+	end := cp.curDataPos
+
+	switch {
+	case cursor <= 0:
+		cp.curDataPos = start
+	case cursor < len(after):
+		cp.curDataPos = after[cursor-1]
+	}
+	return cp, start, end
+}
+
+// cellsOf returns the range of cells within the text input field occupied by
+// the data between the indexes as of the last call to viewFor. The range is
+// limited to the visible data.
+func (fe *fieldEditor) cellsOf(start, end int) (int, int) {
+	if start < fe.firstRune {
+		start = fe.firstRune
+	}
+	if end <= start {
+		return 0, 0
+	}
+	width := func(from, to int) int {
+		w := 0
+		for i := from; i < to && i < len(fe.data); i++ {
+			w += runewidth.StringWidth(fe.data[i])
+		}
+		return w
+	}
+	from := width(fe.firstRune, start)
+	return from, from + width(start, end)
+}
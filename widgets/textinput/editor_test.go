@@ -31,31 +31,31 @@ func TestData(t *testing.T) {
 		{
 			desc: "appends to empty data",
 			ops: func(fd *fieldData) {
-				fd.insertAt(0, 'a')
+				fd.insertAt(0, "a")
 			},
-			want: fieldData{'a'},
+			want: fieldData{"a"},
 		},
 		{
 			desc: "appends at the end of non-empty data",
-			data: fieldData{'a'},
+			data: fieldData{"a"},
 			ops: func(fd *fieldData) {
-				fd.insertAt(1, 'b')
-				fd.insertAt(2, 'c')
+				fd.insertAt(1, "b")
+				fd.insertAt(2, "c")
 			},
-			want: fieldData{'a', 'b', 'c'},
+			want: fieldData{"a", "b", "c"},
 		},
 		{
 			desc: "appends at the beginning of non-empty data",
-			data: fieldData{'a'},
+			data: fieldData{"a"},
 			ops: func(fd *fieldData) {
-				fd.insertAt(0, 'b')
-				fd.insertAt(0, 'c')
+				fd.insertAt(0, "b")
+				fd.insertAt(0, "c")
 			},
-			want: fieldData{'c', 'b', 'a'},
+			want: fieldData{"c", "b", "a"},
 		},
 		{
 			desc: "deletes the last rune, result in empty",
-			data: fieldData{'a'},
+			data: fieldData{"a"},
 			ops: func(fd *fieldData) {
 				fd.deleteAt(0)
 			},
@@ -63,20 +63,20 @@ func TestData(t *testing.T) {
 		},
 		{
 			desc: "deletes the last rune, result in non-empty",
-			data: fieldData{'a', 'b'},
+			data: fieldData{"a", "b"},
 			ops: func(fd *fieldData) {
 				fd.deleteAt(1)
 			},
-			want: fieldData{'a'},
+			want: fieldData{"a"},
 		},
 		{
 			desc: "deletes runes in the middle",
-			data: fieldData{'a', 'b', 'c', 'd'},
+			data: fieldData{"a", "b", "c", "d"},
 			ops: func(fd *fieldData) {
 				fd.deleteAt(1)
 				fd.deleteAt(1)
 			},
-			want: fieldData{'a', 'd'},
+			want: fieldData{"a", "d"},
 		},
 	}
 
@@ -110,56 +110,56 @@ func TestCellsBefore(t *testing.T) {
 		},
 		{
 			desc:   "requesting zero cells",
-			data:   fieldData{'a', 'b', '世', 'd'},
+			data:   fieldData{"a", "b", "世", "d"},
 			cells:  0,
 			endIdx: 1,
 			want:   1,
 		},
 		{
 			desc:   "data only has one rune",
-			data:   fieldData{'a'},
+			data:   fieldData{"a"},
 			cells:  1,
 			endIdx: 1,
 			want:   0,
 		},
 		{
 			desc:   "non-empty data and empty range",
-			data:   fieldData{'a', 'b', '世', 'd'},
+			data:   fieldData{"a", "b", "世", "d"},
 			cells:  1,
 			endIdx: 0,
 			want:   0,
 		},
 		{
 			desc:   "more cells than runes from endIdx",
-			data:   fieldData{'a', 'b', '世', 'd'},
+			data:   fieldData{"a", "b", "世", "d"},
 			cells:  10,
 			endIdx: 1,
 			want:   0,
 		},
 		{
 			desc:   "less cells than runes from endIdx, stops on half-width rune",
-			data:   fieldData{'a', 'b', '世', 'd'},
+			data:   fieldData{"a", "b", "世", "d"},
 			cells:  1,
 			endIdx: 2,
 			want:   1,
 		},
 		{
 			desc:   "less cells than runes from endIdx, stops on full-width rune",
-			data:   fieldData{'a', 'b', '世', 'd'},
+			data:   fieldData{"a", "b", "世", "d"},
 			cells:  2,
 			endIdx: 3,
 			want:   2,
 		},
 		{
 			desc:   "less cells than runes from endIdx, full-width rune doesn't fit, no space for arrows",
-			data:   fieldData{'a', 'b', '世', 'd'},
+			data:   fieldData{"a", "b", "世", "d"},
 			cells:  2,
 			endIdx: 4,
 			want:   3,
 		},
 		{
 			desc:   "full-width runes only",
-			data:   fieldData{'你', '好', '世', '界'},
+			data:   fieldData{"你", "好", "世", "界"},
 			cells:  7,
 			endIdx: 4,
 			want:   1,
@@ -198,49 +198,49 @@ func TestCellsAfter(t *testing.T) {
 		},
 		{
 			desc:     "data only has one rune",
-			data:     fieldData{'a'},
+			data:     fieldData{"a"},
 			cells:    1,
 			startIdx: 0,
 			want:     1,
 		},
 		{
 			desc:     "non-empty data and empty range",
-			data:     fieldData{'a', 'b', '世', 'd'},
+			data:     fieldData{"a", "b", "世", "d"},
 			cells:    0,
 			startIdx: 1,
 			want:     1,
 		},
 		{
 			desc:     "more cells than runes from startIdx",
-			data:     fieldData{'a', 'b', '世', 'd'},
+			data:     fieldData{"a", "b", "世", "d"},
 			cells:    10,
 			startIdx: 1,
 			want:     4,
 		},
 		{
 			desc:     "less cells than runes from startIdx, stops on half-width rune",
-			data:     fieldData{'a', 'b', '世', 'd', 'e', 'f'},
+			data:     fieldData{"a", "b", "世", "d", "e", "f"},
 			cells:    2,
 			startIdx: 3,
 			want:     5,
 		},
 		{
 			desc:     "less cells than runes from startIdx, stops on full-width rune",
-			data:     fieldData{'a', 'b', '世', 'd'},
+			data:     fieldData{"a", "b", "世", "d"},
 			cells:    3,
 			startIdx: 1,
 			want:     3,
 		},
 		{
 			desc:     "less cells than runes from startIdx, full-width rune doesn't fit",
-			data:     fieldData{'a', 'b', '世', 'd'},
+			data:     fieldData{"a", "b", "世", "d"},
 			cells:    3,
 			startIdx: 0,
 			want:     2,
 		},
 		{
 			desc:     "full-width runes only",
-			data:     fieldData{'你', '好', '世', '界'},
+			data:     fieldData{"你", "好", "世", "界"},
 			cells:    7,
 			startIdx: 0,
 			want:     3,
@@ -275,7 +275,7 @@ func TestCurCell(t *testing.T) {
 		},
 		{
 			desc:       "cursor within the first page of data",
-			data:       fieldData{'a', 'b', 'c', 'd'},
+			data:       fieldData{"a", "b", "c", "d"},
 			firstRune:  1,
 			curDataPos: 2,
 			width:      3,
@@ -283,7 +283,7 @@ func TestCurCell(t *testing.T) {
 		},
 		{
 			desc:       "cursor within the first page of data, after full-width rune",
-			data:       fieldData{'a', '世', 'c', 'd'},
+			data:       fieldData{"a", "世", "c", "d"},
 			firstRune:  1,
 			curDataPos: 2,
 			width:      3,
@@ -291,7 +291,7 @@ func TestCurCell(t *testing.T) {
 		},
 		{
 			desc:       "cursor within the second page of data",
-			data:       fieldData{'a', 'b', 'c', 'd', 'e', 'f'},
+			data:       fieldData{"a", "b", "c", "d", "e", "f"},
 			firstRune:  3,
 			curDataPos: 4,
 			width:      3,
@@ -299,7 +299,7 @@ func TestCurCell(t *testing.T) {
 		},
 		{
 			desc:       "cursor within the second page of data, after full-width rune",
-			data:       fieldData{'a', 'b', 'c', '世', 'e', 'f'},
+			data:       fieldData{"a", "b", "c", "世", "e", "f"},
 			firstRune:  3,
 			curDataPos: 4,
 			width:      3,
@@ -356,6 +356,53 @@ func TestFieldEditor(t *testing.T) {
 			wantContent: "abc",
 			wantCurIdx:  3,
 		},
+		{
+			desc:  "combining characters join the previous rune",
+			width: 4,
+			ops: func(fe *fieldEditor) error {
+				fe.insert('\u0301') // Nothing to combine with, ignored.
+				fe.insert('a')
+				fe.insert('\u0301')
+				fe.insert('b')
+				fe.insert('c')
+				fe.cursorLeft()
+				fe.cursorLeft()
+				fe.deleteBefore()
+				return nil
+			},
+			wantView:    "bc",
+			wantContent: "bc",
+			wantCurIdx:  0,
+		},
+		{
+			desc:  "cursor moves over combining characters",
+			width: 4,
+			ops: func(fe *fieldEditor) error {
+				fe.insert('a')
+				fe.insert('\u0301')
+				fe.insert('b')
+				fe.cursorLeft()
+				return nil
+			},
+			wantView:    "a\u0301b",
+			wantContent: "a\u0301b",
+			wantCurIdx:  1,
+		},
+		{
+			desc:  "emoji sequences are edited as one rune",
+			width: 6,
+			ops: func(fe *fieldEditor) error {
+				for _, r := range "a👨\u200d👩b" {
+					fe.insert(r)
+				}
+				fe.cursorLeft()
+				fe.cursorLeft()
+				return nil
+			},
+			wantView:    "a👨\u200d👩b",
+			wantContent: "a👨\u200d👩b",
+			wantCurIdx:  1,
+		},
 		{
 			desc:  "longer data than the width, cursor at the end",
 			width: 4,
@@ -0,0 +1,105 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textinput
+
+// history.go contains code that records edits of the text input field so they
+// can be undone.
+
+import (
+	"strings"
+
+	"github.com/mum4k/termdash/undo"
+)
+
+// editorState is a snapshot of the content of the field editor.
+type editorState struct {
+	// data are the data in the text input field.
+	data fieldData
+	// curDataPos is the position of the cursor within the data.
+	curDataPos int
+}
+
+// state returns a snapshot of the current content of the field editor.
+func (fe *fieldEditor) state() editorState {
+	data := make(fieldData, len(fe.data))
+	copy(data, fe.data)
+	return editorState{
+		data:       data,
+		curDataPos: fe.curDataPos,
+	}
+}
+
+// restore restores the content of the field editor from the snapshot.
+func (fe *fieldEditor) restore(st editorState) {
+	data := make(fieldData, len(st.data))
+	copy(data, st.data)
+	fe.data = data
+	fe.curDataPos = st.curDataPos
+	// The visible range is recalculated on the next draw so that it includes
+	// the cursor.
+	fe.firstRune = 0
+}
+
+// editorChange is an edit of the text input field.
+// Implements undo.Change and undo.Merger.
+type editorChange struct {
+	// editor is the edited field editor.
+	editor *fieldEditor
+	// before and after are the snapshots of the content before and after the
+	// edit.
+	before editorState
+	after  editorState
+	// typed indicates that the change inserted a typed rune.
+	typed bool
+}
+
+// Undo implements undo.Change.Undo.
+func (ec *editorChange) Undo() {
+	ec.editor.restore(ec.before)
+}
+
+// Redo implements undo.Change.Redo.
+func (ec *editorChange) Redo() {
+	ec.editor.restore(ec.after)
+}
+
+// Merge implements undo.Merger.Merge.
+// Consecutive typed runes are undone together.
+func (ec *editorChange) Merge(next undo.Change) bool {
+	n, ok := next.(*editorChange)
+	if !ok || !ec.typed || !n.typed {
+		return false
+	}
+	ec.after = n.after
+	return true
+}
+
+// edit applies the edit to the field editor and records it in the history if
+// it changed the content.
+// Caller must hold ti.mu.
+func (ti *TextInput) edit(typed bool, fn func()) {
+	before := ti.editor.state()
+	fn()
+	after := ti.editor.state()
+	if strings.Join(before.data, "") == strings.Join(after.data, "") {
+		return
+	}
+	ti.history.Record(&editorChange{
+		editor: ti.editor,
+		before: before,
+		after:  after,
+		typed:  typed,
+	})
+}
@@ -21,9 +21,11 @@ import (
 
 	"github.com/mum4k/termdash/align"
 	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
 	"github.com/mum4k/termdash/linestyle"
 	"github.com/mum4k/termdash/private/runewidth"
 	"github.com/mum4k/termdash/private/wrap"
+	"github.com/mum4k/termdash/undo"
 )
 
 // Option is used to provide options.
@@ -47,6 +49,7 @@ type options struct {
 	placeHolderColor cell.Color
 	highlightedColor cell.Color
 	cursorColor      cell.Color
+	preeditColor     cell.Color
 	border           linestyle.LineStyle
 	borderColor      cell.Color
 
@@ -58,10 +61,16 @@ type options struct {
 
 	placeHolder  string
 	hideTextWith rune
+	sensitive    bool
 
 	filter        FilterFn
 	onSubmit      SubmitFn
 	clearOnSubmit bool
+	clipboard     ClipboardFn
+
+	undoDepth int
+	undoKey   keyboard.Key
+	redoKey   keyboard.Key
 }
 
 // validate validates the provided options.
@@ -80,6 +89,9 @@ func (o *options) validate() error {
 			return fmt.Errorf("invalid HideTextWidth rune %c(%d), has rune width of %d cells, only runes with width of %d are accepted", r, r, got, want)
 		}
 	}
+	if o.undoKey == o.redoKey {
+		return fmt.Errorf("invalid UndoKeys(undo:%v, redo:%v), the keys must be unique", o.undoKey, o.redoKey)
+	}
 	return nil
 }
 
@@ -90,7 +102,11 @@ func newOptions() *options {
 		placeHolderColor: cell.ColorNumber(DefaultPlaceHolderColorNumber),
 		highlightedColor: cell.ColorNumber(DefaultHighlightedColorNumber),
 		cursorColor:      cell.ColorNumber(DefaultCursorColorNumber),
+		preeditColor:     cell.ColorNumber(DefaultPreeditColorNumber),
 		labelAlign:       DefaultLabelAlign,
+		undoDepth:        DefaultUndoDepth,
+		undoKey:          DefaultUndoKey,
+		redoKey:          DefaultRedoKey,
 	}
 }
 
@@ -137,6 +153,19 @@ func CursorColor(c cell.Color) Option {
 	})
 }
 
+// DefaultPreeditColorNumber is the default color number for the
+// PreeditColor option.
+const DefaultPreeditColorNumber = 240
+
+// PreeditColor sets the background color of the text the user composes with an
+// input method editor, before the text is committed into the field.
+// Defaults to DefaultPreeditColorNumber.
+func PreeditColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.preeditColor = c
+	})
+}
+
 // Border adds a border around the text input field.
 func Border(ls linestyle.LineStyle) Option {
 	return option(func(opts *options) {
@@ -223,6 +252,15 @@ func HideTextWith(r rune) Option {
 	})
 }
 
+// Sensitive marks the text input field as sensitive content, which is masked
+// while the privacy mode is enabled, see widgetapi.Sensitive. Unlike
+// HideTextWith, the text is displayed while the privacy mode is disabled.
+func Sensitive() Option {
+	return option(func(opts *options) {
+		opts.sensitive = true
+	})
+}
+
 // FilterFn if provided can be used to filter runes that are allowed in the
 // text input field. Any rune for which this function returns false will be
 // rejected.
@@ -263,3 +301,53 @@ func ClearOnSubmit() Option {
 		opts.clearOnSubmit = true
 	})
 }
+
+// ClipboardFn if provided is used to read the content of the clipboard.
+//
+// The function must be thread-safe as the mouse event that triggers the read
+// comes from a separate goroutine.
+type ClipboardFn func() (string, error)
+
+// PasteOnMiddleClick sets a function that reads the clipboard. When provided,
+// clicking the middle mouse button within the input field moves the cursor to
+// the clicked position and pastes the content of the clipboard there.
+// The clipboard is read once per press, holding the button down doesn't
+// paste repeatedly.
+//
+// The pasted text is processed the same way as text provided to the Paste
+// method, i.e. it respects the Filter option.
+// Any error returned by the function is returned from the mouse event
+// handler.
+func PasteOnMiddleClick(fn ClipboardFn) Option {
+	return option(func(opts *options) {
+		opts.clipboard = fn
+	})
+}
+
+// DefaultUndoDepth is the default value for the UndoDepth option.
+const DefaultUndoDepth = undo.DefaultDepth
+
+// UndoDepth sets the maximum number of edits that can be undone. Consecutive
+// typed characters count as one edit.
+// Defaults to DefaultUndoDepth.
+func UndoDepth(edits int) Option {
+	return option(func(opts *options) {
+		opts.undoDepth = edits
+	})
+}
+
+// The default keys that undo and redo edits.
+const (
+	DefaultUndoKey = keyboard.KeyCtrlZ
+	DefaultRedoKey = keyboard.KeyCtrlY
+)
+
+// UndoKeys configures the keyboard keys that undo and redo edits of the text
+// input field. The keys must be unique.
+// Defaults to DefaultUndoKey and DefaultRedoKey.
+func UndoKeys(undoKey, redoKey keyboard.Key) Option {
+	return option(func(opts *options) {
+		opts.undoKey = undoKey
+		opts.redoKey = redoKey
+	})
+}
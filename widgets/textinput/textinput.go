@@ -16,6 +16,7 @@
 package textinput
 
 import (
+	"fmt"
 	"image"
 	"strings"
 	"sync"
@@ -32,6 +33,7 @@ import (
 	"github.com/mum4k/termdash/private/runewidth"
 	"github.com/mum4k/termdash/private/wrap"
 	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/undo"
 	"github.com/mum4k/termdash/widgetapi"
 )
 
@@ -42,7 +44,12 @@ import (
 //
 // The text can be submitted by pressing enter or read at any time by calling
 // Read. The text input field can be navigated using arrows, the Home and End
-// button and using mouse.
+// button and using mouse. Edits can be undone and redone, see the UndoKeys
+// option.
+//
+// Text composed with an input method editor is displayed at the cursor until
+// it is committed, on terminals that report the composition, see
+// terminalapi.Capabilities.Composition.
 //
 // Implements widgetapi.Widget. This object is thread-safe.
 type TextInput struct {
@@ -52,12 +59,28 @@ type TextInput struct {
 	// editor tracks the edits and the state of the text input field.
 	editor *fieldEditor
 
+	// history records the edits so they can be undone.
+	history *undo.Stack
+
+	// preedit is the text being composed with an input method editor.
+	preedit string
+	// preeditCursor is the position of the cursor within preedit in runes.
+	preeditCursor int
+
 	// forField is the area that was occupied by the text input field last
 	// time Draw() was called.
 	forField image.Rectangle
 
+	// middlePressed indicates that the middle mouse button is held down.
+	// Terminals repeat the press event while the button is held, the
+	// clipboard is only pasted on the first one.
+	middlePressed bool
+
 	// opts are the provided options.
 	opts *options
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
 }
 
 // New returns a new TextInput.
@@ -69,9 +92,14 @@ func New(opts ...Option) (*TextInput, error) {
 	if err := opt.validate(); err != nil {
 		return nil, err
 	}
+	history, err := undo.New(undo.Depth(opt.undoDepth))
+	if err != nil {
+		return nil, fmt.Errorf("invalid UndoDepth: %v", err)
+	}
 	return &TextInput{
-		editor: newFieldEditor(),
-		opts:   opt,
+		editor:  newFieldEditor(),
+		history: history,
+		opts:    opt,
 	}, nil
 }
 
@@ -101,9 +129,26 @@ func (ti *TextInput) ReadAndClear() string {
 
 	c := ti.editor.content()
 	ti.editor.reset()
+	ti.history.Clear()
+	ti.markChanged()
 	return c
 }
 
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (ti *TextInput) SetNotifyFunc(fn func()) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	ti.notify = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// ti.mu must be held when calling this method.
+func (ti *TextInput) markChanged() {
+	if ti.notify != nil {
+		ti.notify()
+	}
+}
+
 // drawLabel draws the text label in the area.
 func (ti *TextInput) drawLabel(cvs *canvas.Canvas, labelAr image.Rectangle) error {
 	start, err := alignfor.Text(labelAr, ti.opts.label, ti.opts.labelAlign, align.VerticalMiddle)
@@ -156,6 +201,25 @@ func (ti *TextInput) drawCursor(cvs *canvas.Canvas, curPos int) error {
 	return nil
 }
 
+// drawPreedit highlights the cells of the text input field between the
+// indexes that contain the composed text.
+func (ti *TextInput) drawPreedit(cvs *canvas.Canvas, from, to int) error {
+	if to > ti.forField.Dx() {
+		to = ti.forField.Dx()
+	}
+	for x := from; x < to; x++ {
+		p := image.Point{x + ti.forField.Min.X, ti.forField.Min.Y}
+		if err := cvs.SetCellOpts(
+			p,
+			cell.FgColor(ti.opts.textColor),
+			cell.BgColor(ti.opts.preeditColor),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Draw draws the TextInput widget onto the canvas.
 // Implements widgetapi.Widget.Draw.
 func (ti *TextInput) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
@@ -189,7 +253,12 @@ func (ti *TextInput) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 		}
 	}
 
-	text, curPos, err := ti.editor.viewFor(ti.forField.Dx())
+	editor := ti.editor
+	var preStart, preEnd int
+	if ti.preedit != "" {
+		editor, preStart, preEnd = ti.editor.withPreedit(ti.preedit, ti.preeditCursor)
+	}
+	text, curPos, err := editor.viewFor(ti.forField.Dx())
 	if err != nil {
 		return err
 	}
@@ -197,6 +266,12 @@ func (ti *TextInput) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	if err := ti.drawField(cvs, text); err != nil {
 		return err
 	}
+	if ti.preedit != "" {
+		from, to := editor.cellsOf(preStart, preEnd)
+		if err := ti.drawPreedit(cvs, from, to); err != nil {
+			return err
+		}
+	}
 
 	if meta.Focused {
 		if err := ti.drawCursor(cvs, curPos); err != nil {
@@ -214,6 +289,70 @@ func (ti *TextInput) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 	return nil
 }
 
+// Paste inserts the text at the current position of the cursor as if the
+// user typed it. Line breaks and tabs are replaced with spaces, runes that
+// cannot be displayed or that are rejected by the Filter option are dropped.
+// The paste is undone as a single edit.
+//
+// Use this to insert text the application obtained from a clipboard or
+// received as a terminal paste.
+func (ti *TextInput) Paste(text string) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+
+	ti.paste(text)
+	ti.markChanged()
+}
+
+// Compose displays the text the user composes with an input method editor at
+// the current position of the cursor, the cursor is displayed at the
+// position within the composed text in runes. The text isn't part of the
+// content until it is committed. Empty text ends the composition.
+// Implements widgetapi.Composer.Compose.
+func (ti *TextInput) Compose(preedit string, cursor int) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+
+	ti.preedit = pasteReplacer.Replace(preedit)
+	ti.preeditCursor = cursor
+	ti.markChanged()
+}
+
+// Commit ends the composition and inserts the composed text at the current
+// position of the cursor the same way as Paste.
+// Implements widgetapi.Composer.Commit.
+func (ti *TextInput) Commit(text string) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+
+	ti.preedit = ""
+	ti.preeditCursor = 0
+	ti.paste(text)
+	ti.markChanged()
+}
+
+// pasteReplacer replaces whitespace that cannot be part of a single line of
+// text.
+var pasteReplacer = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ", "\t", " ")
+
+// paste inserts the text at the current position of the cursor.
+// Caller must hold ti.mu.
+func (ti *TextInput) paste(text string) {
+	ti.edit(false, func() {
+		for _, r := range pasteReplacer.Replace(text) {
+			if err := wrap.ValidText(string(r)); err != nil {
+				// Ignore unsupported runes.
+				continue
+			}
+			if ti.opts.filter != nil && !ti.opts.filter(r) {
+				// Ignore filtered runes.
+				continue
+			}
+			ti.editor.insert(r)
+		}
+	})
+}
+
 // keyboard processes keyboard events.
 // Returns a bool indicating if the content was submitted and the text in the
 // field at submission time.
@@ -221,30 +360,46 @@ func (ti *TextInput) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
 func (ti *TextInput) keyboard(k *terminalapi.Keyboard) (bool, string) {
 	ti.mu.Lock()
 	defer ti.mu.Unlock()
+	defer ti.markChanged()
+
+	switch k.Key {
+	case ti.opts.undoKey:
+		ti.history.Undo()
+		return false, ""
+
+	case ti.opts.redoKey:
+		ti.history.Redo()
+		return false, ""
+	}
 
 	switch k.Key {
 	case keyboard.KeyBackspace, keyboard.KeyBackspace2:
-		ti.editor.deleteBefore()
+		ti.edit(false, ti.editor.deleteBefore)
 
 	case keyboard.KeyDelete:
-		ti.editor.delete()
+		ti.edit(false, ti.editor.delete)
 
 	case keyboard.KeyArrowLeft:
 		ti.editor.cursorLeft()
+		ti.history.Seal()
 
 	case keyboard.KeyArrowRight:
 		ti.editor.cursorRight()
+		ti.history.Seal()
 
 	case keyboard.KeyHome, keyboard.KeyCtrlA:
 		ti.editor.cursorStart()
+		ti.history.Seal()
 
 	case keyboard.KeyEnd, keyboard.KeyCtrlE:
 		ti.editor.cursorEnd()
+		ti.history.Seal()
 
 	case keyboard.KeyEnter:
 		text := ti.editor.content()
 		if ti.opts.clearOnSubmit {
 			ti.editor.reset()
+			ti.history.Clear()
 		}
 		if ti.opts.onSubmit != nil {
 			return true, text
@@ -259,12 +414,27 @@ func (ti *TextInput) keyboard(k *terminalapi.Keyboard) (bool, string) {
 			// Ignore filtered runes.
 			return false, ""
 		}
-		ti.editor.insert(rune(k.Key))
+		ti.edit(true, func() {
+			ti.editor.insert(rune(k.Key))
+		})
 	}
 
 	return false, ""
 }
 
+// SensitiveAreas returns the area of the text input field if the Sensitive
+// option was provided.
+// Implements widgetapi.Sensitive.SensitiveAreas.
+func (ti *TextInput) SensitiveAreas() []image.Rectangle {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+
+	if !ti.opts.sensitive || ti.forField.Empty() {
+		return nil
+	}
+	return []image.Rectangle{ti.forField}
+}
+
 // Keyboard processes keyboard events.
 // Implements widgetapi.Widget.Keyboard.
 func (ti *TextInput) Keyboard(k *terminalapi.Keyboard) error {
@@ -277,18 +447,61 @@ func (ti *TextInput) Keyboard(k *terminalapi.Keyboard) error {
 	return nil
 }
 
+// middleClicked determines if the mouse event is the first press of the
+// middle mouse button within the text input field and tracks the state of
+// the button.
+func (ti *TextInput) middleClicked(m *terminalapi.Mouse) bool {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+
+	if m.Button != mouse.ButtonMiddle {
+		ti.middlePressed = false
+		return false
+	}
+	if ti.middlePressed {
+		return false
+	}
+	ti.middlePressed = true
+	return m.Position.In(ti.forField)
+}
+
 // Mouse processes mouse events.
 // Implements widgetapi.Widget.Mouse.
 func (ti *TextInput) Mouse(m *terminalapi.Mouse) error {
+	var clip string
+	paste := ti.middleClicked(m) && ti.opts.clipboard != nil
+	if paste {
+		// The clipboard is read without holding the mutex, since reading
+		// it might block.
+		text, err := ti.opts.clipboard()
+		if err != nil {
+			return fmt.Errorf("unable to read the clipboard: %v", err)
+		}
+		clip = text
+	}
+
 	ti.mu.Lock()
 	defer ti.mu.Unlock()
 
-	if m.Button != mouse.ButtonLeft || !m.Position.In(ti.forField) {
+	if !m.Position.In(ti.forField) {
 		return nil
 	}
+	defer ti.markChanged()
+
+	switch m.Button {
+	case mouse.ButtonLeft:
+		cellIdx := m.Position.X - ti.forField.Min.X
+		ti.editor.cursorRelCell(cellIdx)
+		ti.history.Seal()
 
-	cellIdx := m.Position.X - ti.forField.Min.X
-	ti.editor.cursorRelCell(cellIdx)
+	case mouse.ButtonMiddle:
+		if !paste {
+			return nil
+		}
+		cellIdx := m.Position.X - ti.forField.Min.X
+		ti.editor.cursorRelCell(cellIdx)
+		ti.paste(clip)
+	}
 	return nil
 }
 
@@ -367,8 +580,10 @@ func hideText(text string, hr rune) string {
 
 	i := 0
 	sw := runewidth.StringWidth(text)
-	for _, r := range text {
-		rw := runewidth.RuneWidth(r)
+	for _, c := range runewidth.Clusters(text) {
+		// Combining characters and emoji sequences are hidden as a whole.
+		r := []rune(c)[0]
+		rw := runewidth.StringWidth(c)
 		switch {
 		case i == 0 && r == '⇦':
 			b.WriteRune(r)
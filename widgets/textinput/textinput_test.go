@@ -1323,6 +1323,217 @@ func TestTextInput(t *testing.T) {
 				return ft
 			},
 		},
+		{
+			desc:   "middle mouse button pastes from the clipboard at the clicked position",
+			canvas: image.Rect(0, 0, 10, 1),
+			opts: []Option{
+				PasteOnMiddleClick(func() (string, error) {
+					return "xy", nil
+				}),
+			},
+			meta: &widgetapi.Meta{
+				Focused: true,
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: 'b'},
+				&terminalapi.Keyboard{Key: 'c'},
+				&terminalapi.Mouse{
+					Button:   mouse.ButtonMiddle,
+					Position: image.Point{1, 0},
+				},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetAreaCells(
+					cvs,
+					image.Rect(0, 0, 10, 1),
+					textFieldRune,
+					cell.BgColor(cell.ColorNumber(DefaultFillColorNumber)),
+				)
+				testdraw.MustText(
+					cvs,
+					"axybc",
+					image.Point{0, 0},
+				)
+				testcanvas.MustSetCell(
+					cvs,
+					image.Point{3, 0},
+					cursorRune,
+					cell.BgColor(cell.ColorNumber(DefaultCursorColorNumber)),
+					cell.FgColor(cell.ColorNumber(DefaultHighlightedColorNumber)),
+				)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "middle mouse button fails when the clipboard cannot be read",
+			canvas: image.Rect(0, 0, 10, 1),
+			opts: []Option{
+				PasteOnMiddleClick(func() (string, error) {
+					return "", errors.New("no clipboard")
+				}),
+			},
+			meta: &widgetapi.Meta{
+				Focused: true,
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{
+					Button:   mouse.ButtonMiddle,
+					Position: image.Point{1, 0},
+				},
+			},
+			wantEventErr: true,
+		},
+		{
+			desc:   "middle mouse button outside of the text field doesn't read the clipboard",
+			canvas: image.Rect(0, 0, 10, 1),
+			opts: []Option{
+				PasteOnMiddleClick(func() (string, error) {
+					return "", errors.New("no clipboard")
+				}),
+			},
+			meta: &widgetapi.Meta{
+				Focused: true,
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{
+					Button:   mouse.ButtonMiddle,
+					Position: image.Point{5, 15},
+				},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetAreaCells(
+					cvs,
+					image.Rect(0, 0, 10, 1),
+					textFieldRune,
+					cell.BgColor(cell.ColorNumber(DefaultFillColorNumber)),
+				)
+				testcanvas.MustSetCell(
+					cvs,
+					image.Point{0, 0},
+					cursorRune,
+					cell.BgColor(cell.ColorNumber(DefaultCursorColorNumber)),
+					cell.FgColor(cell.ColorNumber(DefaultHighlightedColorNumber)),
+				)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "middle mouse button held down pastes only once",
+			canvas: image.Rect(0, 0, 10, 1),
+			opts: []Option{
+				PasteOnMiddleClick(func() (string, error) {
+					return "xy", nil
+				}),
+			},
+			meta: &widgetapi.Meta{
+				Focused: true,
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: 'b'},
+				&terminalapi.Keyboard{Key: 'c'},
+				&terminalapi.Mouse{
+					Button:   mouse.ButtonMiddle,
+					Position: image.Point{1, 0},
+				},
+				&terminalapi.Mouse{
+					Button:   mouse.ButtonMiddle,
+					Position: image.Point{1, 0},
+				},
+				&terminalapi.Mouse{
+					Button:   mouse.ButtonRelease,
+					Position: image.Point{1, 0},
+				},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetAreaCells(
+					cvs,
+					image.Rect(0, 0, 10, 1),
+					textFieldRune,
+					cell.BgColor(cell.ColorNumber(DefaultFillColorNumber)),
+				)
+				testdraw.MustText(
+					cvs,
+					"axybc",
+					image.Point{0, 0},
+				)
+				testcanvas.MustSetCell(
+					cvs,
+					image.Point{3, 0},
+					cursorRune,
+					cell.BgColor(cell.ColorNumber(DefaultCursorColorNumber)),
+					cell.FgColor(cell.ColorNumber(DefaultHighlightedColorNumber)),
+				)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "middle mouse button pastes again after a release",
+			canvas: image.Rect(0, 0, 10, 1),
+			opts: []Option{
+				PasteOnMiddleClick(func() (string, error) {
+					return "xy", nil
+				}),
+			},
+			meta: &widgetapi.Meta{
+				Focused: true,
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: 'b'},
+				&terminalapi.Keyboard{Key: 'c'},
+				&terminalapi.Mouse{
+					Button:   mouse.ButtonMiddle,
+					Position: image.Point{1, 0},
+				},
+				&terminalapi.Mouse{
+					Button:   mouse.ButtonRelease,
+					Position: image.Point{1, 0},
+				},
+				&terminalapi.Mouse{
+					Button:   mouse.ButtonMiddle,
+					Position: image.Point{1, 0},
+				},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+
+				testcanvas.MustSetAreaCells(
+					cvs,
+					image.Rect(0, 0, 10, 1),
+					textFieldRune,
+					cell.BgColor(cell.ColorNumber(DefaultFillColorNumber)),
+				)
+				testdraw.MustText(
+					cvs,
+					"axyxybc",
+					image.Point{0, 0},
+				)
+				testcanvas.MustSetCell(
+					cvs,
+					image.Point{3, 0},
+					cursorRune,
+					cell.BgColor(cell.ColorNumber(DefaultCursorColorNumber)),
+					cell.FgColor(cell.ColorNumber(DefaultHighlightedColorNumber)),
+				)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
 		{
 			desc:   "ignores other mouse buttons",
 			canvas: image.Rect(0, 0, 10, 1),
@@ -1588,6 +1799,233 @@ func TestTextInputRead(t *testing.T) {
 	}
 }
 
+func TestPaste(t *testing.T) {
+	tests := []struct {
+		desc   string
+		opts   []Option
+		events []terminalapi.Event
+		paste  string
+		want   string
+	}{
+		{
+			desc:  "pastes into an empty field",
+			paste: "abc",
+			want:  "abc",
+		},
+		{
+			desc: "pastes at the cursor position",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: 'b'},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+			},
+			paste: "xy",
+			want:  "axyb",
+		},
+		{
+			desc:  "replaces line breaks and tabs with spaces",
+			paste: "a\r\nb\nc\td",
+			want:  "a b c d",
+		},
+		{
+			desc:  "drops runes that cannot be displayed",
+			paste: "a\x00b\x1bc",
+			want:  "abc",
+		},
+		{
+			desc: "drops runes rejected by the filter",
+			opts: []Option{
+				Filter(func(r rune) bool {
+					return r >= '0' && r <= '9'
+				}),
+			},
+			paste: "1a2 b3",
+			want:  "123",
+		},
+		{
+			desc: "hidden text is pasted as written",
+			opts: []Option{
+				HideTextWith('*'),
+			},
+			paste: "secret",
+			want:  "secret",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ti, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+
+			for _, ev := range tc.events {
+				k, ok := ev.(*terminalapi.Keyboard)
+				if !ok {
+					t.Fatalf("unsupported event type: %T", ev)
+				}
+				if err := ti.Keyboard(k); err != nil {
+					t.Fatalf("Keyboard => unexpected error: %v", err)
+				}
+			}
+
+			ti.Paste(tc.paste)
+			if got := ti.Read(); got != tc.want {
+				t.Errorf("Read => %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUndo(t *testing.T) {
+	tests := []struct {
+		desc       string
+		opts       []Option
+		events     []terminalapi.Event
+		paste      string
+		want       string
+		wantNewErr bool
+	}{
+		{
+			desc: "fails on zero UndoDepth",
+			opts: []Option{
+				UndoDepth(0),
+			},
+			wantNewErr: true,
+		},
+		{
+			desc: "fails on duplicate UndoKeys",
+			opts: []Option{
+				UndoKeys(keyboard.KeyCtrlZ, keyboard.KeyCtrlZ),
+			},
+			wantNewErr: true,
+		},
+		{
+			desc: "undoes consecutive typed runes in one step",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: 'b'},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+				&terminalapi.Keyboard{Key: 'c'},
+				&terminalapi.Keyboard{Key: 'd'},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+			},
+			want: "ab",
+		},
+		{
+			desc: "undoes deletions one by one",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: 'b'},
+				&terminalapi.Keyboard{Key: 'c'},
+				&terminalapi.Keyboard{Key: keyboard.KeyBackspace},
+				&terminalapi.Keyboard{Key: keyboard.KeyBackspace},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+			},
+			want: "ab",
+		},
+		{
+			desc: "redoes undone edits",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: keyboard.KeyBackspace},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+				&terminalapi.Keyboard{Key: DefaultRedoKey},
+			},
+			want: "a",
+		},
+		{
+			desc: "undo restores the cursor position",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: 'b'},
+				&terminalapi.Keyboard{Key: keyboard.KeyHome},
+				&terminalapi.Keyboard{Key: keyboard.KeyDelete},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+				&terminalapi.Keyboard{Key: 'x'},
+			},
+			want: "xab",
+		},
+		{
+			desc: "ignores edits that don't change the content",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: keyboard.KeyDelete},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+			},
+			want: "",
+		},
+		{
+			desc: "forgets edits beyond the depth",
+			opts: []Option{
+				UndoDepth(1),
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+				&terminalapi.Keyboard{Key: 'b'},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+			},
+			want: "a",
+		},
+		{
+			desc: "custom undo keys",
+			opts: []Option{
+				UndoKeys(keyboard.KeyCtrlU, keyboard.KeyCtrlR),
+			},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+				&terminalapi.Keyboard{Key: keyboard.KeyCtrlU},
+				&terminalapi.Keyboard{Key: keyboard.KeyCtrlR},
+				&terminalapi.Keyboard{Key: DefaultUndoKey},
+			},
+			want: "a",
+		},
+		{
+			desc: "undoes a paste in one step",
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: 'a'},
+			},
+			paste: "bcd",
+			want:  "a",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ti, err := New(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, ev := range tc.events {
+				k, ok := ev.(*terminalapi.Keyboard)
+				if !ok {
+					t.Fatalf("unsupported event type: %T", ev)
+				}
+				if err := ti.Keyboard(k); err != nil {
+					t.Fatalf("Keyboard => unexpected error: %v", err)
+				}
+			}
+			if tc.paste != "" {
+				ti.Paste(tc.paste)
+				if err := ti.Keyboard(&terminalapi.Keyboard{Key: DefaultUndoKey}); err != nil {
+					t.Fatalf("Keyboard => unexpected error: %v", err)
+				}
+			}
+
+			if got := ti.Read(); got != tc.want {
+				t.Errorf("Read => %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestOptions(t *testing.T) {
 	tests := []struct {
 		desc string
@@ -1830,3 +2268,179 @@ func TestSplit(t *testing.T) {
 		})
 	}
 }
+
+func TestComposition(t *testing.T) {
+	textFieldRune = '_'
+	cursorRune = 0
+
+	tests := []struct {
+		desc     string
+		opts     []Option
+		compose  []string
+		cursor   int
+		commit   *string
+		want     func(size image.Point) *faketerm.Terminal
+		wantRead string
+	}{
+		{
+			desc:    "displays the composed text at the cursor",
+			opts:    []Option{PreeditColor(cell.ColorRed)},
+			compose: []string{"n", "ni"},
+			cursor:  1,
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), textFieldRune,
+					cell.BgColor(cell.ColorNumber(DefaultFillColorNumber)))
+				testdraw.MustText(cvs, "anib", image.Point{0, 0})
+				testcanvas.MustSetCell(cvs, image.Point{1, 0}, 'n', cell.BgColor(cell.ColorRed))
+				testcanvas.MustSetCell(cvs, image.Point{2, 0}, 'i',
+					cell.FgColor(cell.ColorNumber(DefaultHighlightedColorNumber)),
+					cell.BgColor(cell.ColorNumber(DefaultCursorColorNumber)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantRead: "ab",
+		},
+		{
+			desc:    "empty composition ends it",
+			compose: []string{"ni", ""},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), textFieldRune,
+					cell.BgColor(cell.ColorNumber(DefaultFillColorNumber)))
+				testdraw.MustText(cvs, "ab", image.Point{0, 0})
+				testcanvas.MustSetCell(cvs, image.Point{1, 0}, 'b',
+					cell.FgColor(cell.ColorNumber(DefaultHighlightedColorNumber)),
+					cell.BgColor(cell.ColorNumber(DefaultCursorColorNumber)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantRead: "ab",
+		},
+		{
+			desc:    "commits the composed text",
+			compose: []string{"ni"},
+			cursor:  2,
+			commit:  func() *string { s := "你"; return &s }(),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testcanvas.MustSetAreaCells(cvs, cvs.Area(), textFieldRune,
+					cell.BgColor(cell.ColorNumber(DefaultFillColorNumber)))
+				testdraw.MustText(cvs, "a你b", image.Point{0, 0})
+				testcanvas.MustSetCell(cvs, image.Point{3, 0}, 'b',
+					cell.FgColor(cell.ColorNumber(DefaultHighlightedColorNumber)),
+					cell.BgColor(cell.ColorNumber(DefaultCursorColorNumber)))
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantRead: "a你b",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ti, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			for _, k := range []keyboard.Key{'a', 'b', keyboard.KeyArrowLeft} {
+				if err := ti.Keyboard(&terminalapi.Keyboard{Key: k}); err != nil {
+					t.Fatalf("Keyboard => unexpected error: %v", err)
+				}
+			}
+			for _, text := range tc.compose {
+				ti.Compose(text, tc.cursor)
+			}
+			if tc.commit != nil {
+				ti.Commit(*tc.commit)
+			}
+
+			c, err := canvas.New(image.Rect(0, 0, 10, 1))
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := ti.Draw(c, &widgetapi.Meta{Focused: true}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+			if got := ti.Read(); got != tc.wantRead {
+				t.Errorf("Read => %q, want %q", got, tc.wantRead)
+			}
+		})
+	}
+}
+
+func TestSensitiveAreas(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		want []image.Rectangle
+	}{
+		{
+			desc: "not sensitive by default",
+			opts: []Option{Label("ab")},
+		},
+		{
+			desc: "reports the text input field",
+			opts: []Option{Label("ab"), Sensitive()},
+			want: []image.Rectangle{image.Rect(2, 0, 10, 3)},
+		},
+		{
+			desc: "excludes the border",
+			opts: []Option{Border(linestyle.Light), Sensitive()},
+			want: []image.Rectangle{image.Rect(1, 1, 9, 2)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ti, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			cvs := testcanvas.MustNew(image.Rect(0, 0, 10, 3))
+			if err := ti.Draw(cvs, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			if diff := pretty.Compare(tc.want, ti.SensitiveAreas()); diff != "" {
+				t.Errorf("SensitiveAreas => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNotifies(t *testing.T) {
+	ti, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	ti.SetNotifyFunc(func() {
+		notified++
+	})
+
+	if err := ti.Keyboard(&terminalapi.Keyboard{Key: 'a'}); err != nil {
+		t.Fatalf("Keyboard => unexpected error: %v", err)
+	}
+	ti.Paste("bc")
+	ti.Compose("d", 1)
+	ti.Commit("d")
+	if got, want := ti.ReadAndClear(), "abcd"; got != want {
+		t.Errorf("ReadAndClear => %q, want %q", got, want)
+	}
+	if want := 5; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
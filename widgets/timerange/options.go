@@ -0,0 +1,203 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timerange
+
+// options.go contains configurable options for TimeRange.
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	presets          []Preset
+	selected         int
+	customLabel      string
+	layout           string
+	textColor        cell.Color
+	selectedColor    cell.Color
+	highlightedColor cell.Color
+	errorColor       cell.Color
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if len(o.presets) == 0 {
+		return errors.New("at least one Preset must be provided")
+	}
+	for i, p := range o.presets {
+		if p.Label == "" {
+			return fmt.Errorf("invalid Preset[%d], the label must not be empty", i)
+		}
+		if p.Last <= 0 {
+			return fmt.Errorf("invalid Preset[%d] %q, the duration %v must be positive", i, p.Label, p.Last)
+		}
+	}
+	if o.selected < 0 || o.selected >= len(o.presets) {
+		return fmt.Errorf("invalid Selected(%d), must be in range 0 <= selected < %d", o.selected, len(o.presets))
+	}
+	if o.customLabel == "" {
+		return errors.New("the CustomLabel must not be empty")
+	}
+	if o.layout == "" {
+		return errors.New("the TimeLayout must not be empty")
+	}
+	return nil
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		presets:          DefaultPresets,
+		customLabel:      DefaultCustomLabel,
+		layout:           format.CurrentLocale().DateTimeLayout(),
+		selectedColor:    DefaultSelectedColor,
+		highlightedColor: cell.ColorNumber(DefaultHighlightedColorNumber),
+		errorColor:       DefaultErrorColor,
+	}
+}
+
+// Preset is a range of the specified duration that ends at the current time.
+type Preset struct {
+	// Label is displayed by the widget, e.g. "1h".
+	Label string
+	// Last is the duration of the range.
+	Last time.Duration
+}
+
+// DefaultPresets are the default value for the Presets option.
+var DefaultPresets = []Preset{
+	{Label: "5m", Last: 5 * time.Minute},
+	{Label: "15m", Last: 15 * time.Minute},
+	{Label: "1h", Last: time.Hour},
+	{Label: "6h", Last: 6 * time.Hour},
+	{Label: "24h", Last: 24 * time.Hour},
+	{Label: "7d", Last: 7 * 24 * time.Hour},
+}
+
+// Presets sets the ranges the user can select, displayed in the provided
+// order. At least one preset must be provided.
+// Defaults to DefaultPresets.
+func Presets(presets ...Preset) Option {
+	return option(func(opts *options) {
+		opts.presets = presets
+	})
+}
+
+// Selected sets the index of the preset that is initially selected.
+// Defaults to the first preset.
+func Selected(i int) Option {
+	return option(func(opts *options) {
+		opts.selected = i
+	})
+}
+
+// DefaultCustomLabel is the default value for the CustomLabel option.
+const DefaultCustomLabel = "custom"
+
+// CustomLabel sets the label of the entry that lets the user type a custom
+// range.
+// Defaults to DefaultCustomLabel.
+func CustomLabel(label string) Option {
+	return option(func(opts *options) {
+		opts.customLabel = label
+	})
+}
+
+// DefaultTimeLayout is the layout of the format.DefaultLocale, i.e. the
+// default value for the TimeLayout option unless format.SetLocale was called.
+const DefaultTimeLayout = "2006-01-02 15:04"
+
+// TimeLayout sets the layout as accepted by time.Parse used to parse the
+// start and the end of custom ranges and to display the selected range.
+// Defaults to the layout of the date and time of the current locale, see
+// format.SetLocale, which is DefaultTimeLayout unless the locale was set.
+func TimeLayout(layout string) Option {
+	return option(func(opts *options) {
+		opts.layout = layout
+	})
+}
+
+// Locale sets the TimeLayout to the layout of the date and time of the
+// locale, e.g. "01/02/2006 3:04PM" for format.EnUS.
+func Locale(l format.Locale) Option {
+	return option(func(opts *options) {
+		opts.layout = l.DateTimeLayout()
+	})
+}
+
+// TextColor sets the color of the labels and of the selected range.
+// Defaults to the default terminal color.
+func TextColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.textColor = c
+	})
+}
+
+// DefaultSelectedColor is the default value for the SelectedColor option.
+const DefaultSelectedColor = cell.ColorBlue
+
+// SelectedColor sets the background color of the label of the selected
+// range.
+// Defaults to DefaultSelectedColor.
+func SelectedColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.selectedColor = c
+	})
+}
+
+// DefaultHighlightedColorNumber is the default color number for the
+// HighlightedColor option.
+const DefaultHighlightedColorNumber = 33
+
+// HighlightedColor sets the color of the label under the cursor and of the
+// custom range being typed while the widget is focused.
+// Defaults to DefaultHighlightedColorNumber.
+func HighlightedColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.highlightedColor = c
+	})
+}
+
+// DefaultErrorColor is the default value for the ErrorColor option.
+const DefaultErrorColor = cell.ColorRed
+
+// ErrorColor sets the color of the error displayed when the typed custom
+// range is invalid.
+// Defaults to DefaultErrorColor.
+func ErrorColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.errorColor = c
+	})
+}
@@ -0,0 +1,625 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package timerange implements a widget that selects the time range displayed
+// by the other widgets on the dashboard.
+package timerange
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/bus"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// Range is a time range selected by the user.
+type Range struct {
+	// Start is the beginning of the range.
+	Start time.Time
+	// End is the end of the range.
+	End time.Time
+	// Last is the duration of ranges that end at the current time, e.g. the
+	// presets. Zero for ranges with a fixed start and end.
+	Last time.Duration
+}
+
+// Duration returns the duration of the range.
+func (r Range) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// String implements fmt.Stringer.
+func (r Range) String() string {
+	if r.Last > 0 {
+		return fmt.Sprintf("last %v", r.Last)
+	}
+	return fmt.Sprintf("%v - %v", r.Start, r.End)
+}
+
+// SubscriberFn is called with the selected range when the selection changes.
+//
+// The function must be thread-safe as the keyboard and mouse events that
+// change the selection are processed in a separate goroutine.
+//
+// If the function returns an error, the widget will forward it back to the
+// termdash infrastructure which causes a panic, unless the user provided a
+// termdash.ErrorHandler.
+type SubscriberFn func(Range) error
+
+// The markers drawn around the label under the cursor while the widget is
+// focused.
+const (
+	cursorLeft  = '['
+	cursorRight = ']'
+)
+
+// customPrompt precedes the custom range being typed.
+const customPrompt = "range: "
+
+// TimeRange displays a row of preset ranges, e.g. the last 5m, 1h or 24h, and
+// an entry that lets the user type a custom range. The selected range is
+// broadcast to all the subscribers, e.g. functions that query the data shown
+// by charts, so that all the panels display the same range. The range is
+// also published as bus.RangeChanged on the message bus of the termdash
+// instance.
+//
+// While the widget is focused, the left and right arrow keys move the cursor
+// and the enter or the space key select the range under the cursor. Clicking
+// the left mouse button on a label also selects it.
+//
+// Selecting the custom entry lets the user type either a duration, e.g.
+// "90m" or "2d", that ends at the current time, or a start and an end in the
+// TimeLayout separated by " - ". The enter key applies the typed range and
+// the escape key cancels the entry.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type TimeRange struct {
+	// mu protects the widget.
+	mu sync.Mutex
+
+	// selected is the selected range.
+	selected Range
+	// selectedIdx is the index of the selected label, the custom entry has
+	// index len(opts.presets).
+	selectedIdx int
+	// cursor is the index of the label under the cursor.
+	cursor int
+
+	// editing is true while the user types a custom range.
+	editing bool
+	// input is the custom range being typed.
+	input []rune
+	// inputErr is the reason why the typed custom range was rejected.
+	inputErr error
+
+	// subscribers are called when the selection changes, keyed by the
+	// subscription IDs.
+	subscribers map[int]SubscriberFn
+	// nextID is the ID of the next subscription.
+	nextID int
+	// bus is the message bus of the termdash instance or nil, see SetBus.
+	bus *bus.Bus
+
+	// now returns the current time.
+	now func() time.Time
+
+	// opts are the provided options.
+	opts *options
+
+	// redraw is called when the content changes, see SetNotifyFunc.
+	redraw func()
+}
+
+// New returns a new TimeRange.
+func New(opts ...Option) (*TimeRange, error) {
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	tr := &TimeRange{
+		selectedIdx: opt.selected,
+		cursor:      opt.selected,
+		subscribers: map[int]SubscriberFn{},
+		now:         time.Now,
+		opts:        opt,
+	}
+	tr.selected = tr.presetRange(opt.selected)
+	return tr, nil
+}
+
+// Subscribe registers a function that is called with the selected range each
+// time the selection changes. The function isn't called with the range that
+// is selected when subscribing, see Range.
+// Returns a function that cancels the subscription.
+func (tr *TimeRange) Subscribe(fn SubscriberFn) func() {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	id := tr.nextID
+	tr.nextID++
+	tr.subscribers[id] = fn
+	return func() {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		delete(tr.subscribers, id)
+	}
+}
+
+// Range returns the selected range. Ranges that end at the current time are
+// computed from the current time on each call.
+func (tr *TimeRange) Range() Range {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	return tr.current()
+}
+
+// current returns the selected range at the current time.
+// Caller must hold tr.mu.
+func (tr *TimeRange) current() Range {
+	if tr.selected.Last == 0 {
+		return tr.selected
+	}
+	return lastRange(tr.selected.Last, tr.now())
+}
+
+// Select selects the preset at the index and notifies the subscribers.
+func (tr *TimeRange) Select(i int) error {
+	tr.mu.Lock()
+	if i < 0 || i >= len(tr.opts.presets) {
+		tr.mu.Unlock()
+		return fmt.Errorf("invalid preset index %d, must be in range 0 <= i < %d", i, len(tr.opts.presets))
+	}
+	tr.cursor = i
+	r := tr.selectPreset(i)
+	tr.mu.Unlock()
+
+	return tr.notify(r)
+}
+
+// SetRange selects a custom range with a fixed start and end and notifies the
+// subscribers. The start must be before the end.
+func (tr *TimeRange) SetRange(start, end time.Time) error {
+	tr.mu.Lock()
+	if !start.Before(end) {
+		tr.mu.Unlock()
+		return fmt.Errorf("invalid range, start(%v) must be before end(%v)", start, end)
+	}
+	tr.cursor = len(tr.opts.presets)
+	r := tr.selectCustom(Range{Start: start, End: end})
+	tr.mu.Unlock()
+
+	return tr.notify(r)
+}
+
+// SetLast selects a custom range of the duration that ends at the current
+// time and notifies the subscribers. The duration must be positive.
+func (tr *TimeRange) SetLast(d time.Duration) error {
+	tr.mu.Lock()
+	if d <= 0 {
+		tr.mu.Unlock()
+		return fmt.Errorf("invalid duration %v, must be positive", d)
+	}
+	tr.cursor = len(tr.opts.presets)
+	r := tr.selectCustom(lastRange(d, tr.now()))
+	tr.mu.Unlock()
+
+	return tr.notify(r)
+}
+
+// Refresh notifies the subscribers about the selected range again. Ranges
+// that end at the current time move forward, so calling Refresh periodically
+// keeps the panels scrolling with the time.
+func (tr *TimeRange) Refresh() error {
+	tr.mu.Lock()
+	r := tr.current()
+	tr.markChanged()
+	tr.mu.Unlock()
+
+	return tr.notify(r)
+}
+
+// lastRange returns a range of the duration that ends at the time.
+func lastRange(d time.Duration, now time.Time) Range {
+	return Range{
+		Start: now.Add(-d),
+		End:   now,
+		Last:  d,
+	}
+}
+
+// presetRange returns the range of the preset at the index.
+// Caller must hold tr.mu.
+func (tr *TimeRange) presetRange(i int) Range {
+	return lastRange(tr.opts.presets[i].Last, tr.now())
+}
+
+// selectPreset selects the preset at the index and returns its range.
+// Caller must hold tr.mu.
+func (tr *TimeRange) selectPreset(i int) Range {
+	tr.editing = false
+	tr.selectedIdx = i
+	tr.selected = tr.presetRange(i)
+	tr.markChanged()
+	return tr.selected
+}
+
+// selectCustom selects the custom range and returns it.
+// Caller must hold tr.mu.
+func (tr *TimeRange) selectCustom(r Range) Range {
+	tr.editing = false
+	tr.selectedIdx = len(tr.opts.presets)
+	tr.selected = r
+	tr.markChanged()
+	return r
+}
+
+// parseCustom parses the custom range typed by the user.
+// Caller must hold tr.mu.
+func (tr *TimeRange) parseCustom(s string) (Range, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Range{}, errors.New("empty range")
+	}
+
+	now := tr.now()
+	if parts := strings.Split(s, " - "); len(parts) == 2 {
+		start, err := time.ParseInLocation(tr.opts.layout, strings.TrimSpace(parts[0]), now.Location())
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid start, want %q", tr.opts.layout)
+		}
+		end, err := time.ParseInLocation(tr.opts.layout, strings.TrimSpace(parts[1]), now.Location())
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid end, want %q", tr.opts.layout)
+		}
+		if !start.Before(end) {
+			return Range{}, errors.New("start must be before end")
+		}
+		return Range{Start: start, End: end}, nil
+	}
+
+	d, err := parseDuration(s)
+	if err != nil {
+		return Range{}, err
+	}
+	return lastRange(d, now), nil
+}
+
+// parseDuration parses a positive duration as accepted by
+// time.ParseDuration. Additionally accepts a number of days, e.g. "2d".
+func parseDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+	if d <= 0 {
+		return 0, errors.New("duration must be positive")
+	}
+	return d, nil
+}
+
+// labels returns the labels displayed by the widget, the presets followed by
+// the custom entry.
+// Caller must hold tr.mu.
+func (tr *TimeRange) labels() []string {
+	var res []string
+	for _, p := range tr.opts.presets {
+		res = append(res, p.Label)
+	}
+	return append(res, tr.opts.customLabel)
+}
+
+// span is the horizontal position of a label including its padding.
+type span struct {
+	start, end int
+}
+
+// spans returns the positions of the labels on the first line of the canvas.
+// Each label is padded with a space on both sides, the space holds the cursor
+// markers.
+// Caller must hold tr.mu.
+func (tr *TimeRange) spans() []span {
+	var res []span
+	x := 0
+	for _, l := range tr.labels() {
+		w := runewidth.StringWidth(l) + 2
+		res = append(res, span{start: x, end: x + w})
+		x += w + 1
+	}
+	return res
+}
+
+// formatRange formats the range for display.
+// Caller must hold tr.mu.
+func (tr *TimeRange) formatRange(r Range) string {
+	return fmt.Sprintf("%s - %s", r.Start.Format(tr.opts.layout), r.End.Format(tr.opts.layout))
+}
+
+// Draw draws the TimeRange widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (tr *TimeRange) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	ar := cvs.Area()
+	if tr.editing {
+		return tr.drawInput(cvs, meta.Focused)
+	}
+
+	labels := tr.labels()
+	for i, s := range tr.spans() {
+		if s.start >= ar.Dx() {
+			break
+		}
+		text := fmt.Sprintf(" %s ", labels[i])
+		if meta.Focused && i == tr.cursor {
+			text = fmt.Sprintf("%c%s%c", cursorLeft, labels[i], cursorRight)
+		}
+		cellOpts := []cell.Option{cell.FgColor(tr.opts.textColor)}
+		if meta.Focused && i == tr.cursor {
+			cellOpts = []cell.Option{cell.FgColor(tr.opts.highlightedColor)}
+		}
+		if i == tr.selectedIdx {
+			cellOpts = append(cellOpts, cell.BgColor(tr.opts.selectedColor))
+		}
+		if err := draw.Text(cvs, text, image.Point{s.start, 0},
+			draw.TextCellOpts(cellOpts...),
+			draw.TextOverrunMode(draw.OverrunModeTrim),
+		); err != nil {
+			return err
+		}
+	}
+
+	if ar.Dy() < 2 {
+		return nil
+	}
+	return draw.Text(cvs, tr.formatRange(tr.current()), image.Point{0, 1},
+		draw.TextCellOpts(cell.FgColor(tr.opts.textColor)),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+	)
+}
+
+// drawInput draws the custom range being typed and the reason why it was
+// rejected.
+// Caller must hold tr.mu.
+func (tr *TimeRange) drawInput(cvs *canvas.Canvas, focused bool) error {
+	color := tr.opts.textColor
+	if focused {
+		color = tr.opts.highlightedColor
+	}
+	text := customPrompt + string(tr.input) + "_"
+	if err := draw.Text(cvs, text, image.Point{0, 0},
+		draw.TextCellOpts(cell.FgColor(color)),
+		draw.TextOverrunMode(draw.OverrunModeTrim),
+	); err != nil {
+		return err
+	}
+	if tr.inputErr == nil {
+		return nil
+	}
+
+	errPos := image.Point{runewidth.StringWidth(text) + 1, 0}
+	if cvs.Area().Dy() > 1 {
+		errPos = image.Point{0, 1}
+	}
+	if errPos.X >= cvs.Area().Dx() {
+		return nil
+	}
+	return draw.Text(cvs, tr.inputErr.Error(), errPos,
+		draw.TextCellOpts(cell.FgColor(tr.opts.errorColor)),
+		draw.TextOverrunMode(draw.OverrunModeThreeDot),
+	)
+}
+
+// activate selects the label at the index, the custom entry starts the
+// editing of a custom range.
+// Returns true and the range if the selection changed.
+// Caller must hold tr.mu.
+func (tr *TimeRange) activate(i int) (bool, Range) {
+	tr.cursor = i
+	if i == len(tr.opts.presets) {
+		tr.editing = true
+		tr.input = nil
+		tr.inputErr = nil
+		return false, Range{}
+	}
+	return true, tr.selectPreset(i)
+}
+
+// keyboard processes keyboard events.
+// Returns true and the range if the selection changed.
+func (tr *TimeRange) keyboard(k *terminalapi.Keyboard) (bool, Range) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if tr.editing {
+		return tr.editKeyboard(k)
+	}
+
+	switch k.Key {
+	case keyboard.KeyArrowLeft:
+		if tr.cursor > 0 {
+			tr.cursor--
+		}
+	case keyboard.KeyArrowRight:
+		if tr.cursor < len(tr.opts.presets) {
+			tr.cursor++
+		}
+	case keyboard.KeyEnter, keyboard.KeySpace:
+		return tr.activate(tr.cursor)
+	}
+	return false, Range{}
+}
+
+// editKeyboard processes keyboard events while the user types a custom range.
+// Returns true and the range if the selection changed.
+// Caller must hold tr.mu.
+func (tr *TimeRange) editKeyboard(k *terminalapi.Keyboard) (bool, Range) {
+	switch k.Key {
+	case keyboard.KeyEsc:
+		tr.editing = false
+	case keyboard.KeyBackspace, keyboard.KeyBackspace2:
+		if len(tr.input) > 0 {
+			tr.input = tr.input[:len(tr.input)-1]
+		}
+		tr.inputErr = nil
+	case keyboard.KeyEnter:
+		r, err := tr.parseCustom(string(tr.input))
+		if err != nil {
+			tr.inputErr = err
+			return false, Range{}
+		}
+		return true, tr.selectCustom(r)
+	default:
+		if k.Key >= keyboard.KeySpace {
+			tr.input = append(tr.input, rune(k.Key))
+			tr.inputErr = nil
+		}
+	}
+	return false, Range{}
+}
+
+// Keyboard processes keyboard events.
+// Implements widgetapi.Widget.Keyboard.
+func (tr *TimeRange) Keyboard(k *terminalapi.Keyboard) error {
+	changed, r := tr.keyboard(k)
+	if !changed {
+		return nil
+	}
+	return tr.notify(r)
+}
+
+// mouse processes mouse events.
+// Returns true and the range if the selection changed.
+func (tr *TimeRange) mouse(m *terminalapi.Mouse) (bool, Range) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if m.Button != mouse.ButtonLeft || m.Position.Y != 0 || tr.editing {
+		return false, Range{}
+	}
+	for i, s := range tr.spans() {
+		if m.Position.X >= s.start && m.Position.X < s.end {
+			return tr.activate(i)
+		}
+	}
+	return false, Range{}
+}
+
+// Mouse processes mouse events.
+// Implements widgetapi.Widget.Mouse.
+func (tr *TimeRange) Mouse(m *terminalapi.Mouse) error {
+	changed, r := tr.mouse(m)
+	if !changed {
+		return nil
+	}
+	return tr.notify(r)
+}
+
+// notify calls the subscribers in the order they subscribed. Stops on the
+// first error.
+func (tr *TimeRange) notify(r Range) error {
+	tr.mu.Lock()
+	var ids []int
+	for id := range tr.subscribers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	var fns []SubscriberFn
+	for _, id := range ids {
+		fns = append(fns, tr.subscribers[id])
+	}
+	b := tr.bus
+	tr.mu.Unlock()
+
+	// Mutex must be released when calling the subscribers.
+	// Users might call container methods from the callback like the
+	// Container.Update, see #205.
+	for _, fn := range fns {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	if b != nil {
+		b.Publish(bus.RangeChanged{
+			Source: tr,
+			Start:  r.Start,
+			End:    r.End,
+			Last:   r.Last,
+		})
+	}
+	return nil
+}
+
+// SetBus implements widgetapi.BusUser.SetBus.
+func (tr *TimeRange) SetBus(b *bus.Bus) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.bus = b
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (tr *TimeRange) SetNotifyFunc(fn func()) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.redraw = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// tr.mu must be held when calling this method.
+func (tr *TimeRange) markChanged() {
+	if tr.redraw != nil {
+		tr.redraw()
+	}
+}
+
+// Options implements widgetapi.Widget.Options.
+func (tr *TimeRange) Options() widgetapi.Options {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	spans := tr.spans()
+	return widgetapi.Options{
+		MinimumSize:  image.Point{spans[len(spans)-1].end, 1},
+		WantKeyboard: widgetapi.KeyScopeFocused,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+}
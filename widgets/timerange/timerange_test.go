@@ -0,0 +1,642 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timerange
+
+import (
+	"errors"
+	"image"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/bus"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/format"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// now is the fixed current time in the tests.
+var now = time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// testPresets are the presets used in the tests.
+var testPresets = []Preset{
+	{Label: "5m", Last: 5 * time.Minute},
+	{Label: "1h", Last: time.Hour},
+}
+
+// subscriberTracker tracks the calls of a subscriber.
+type subscriberTracker struct {
+	// wantErr when set to true, makes the subscriber return an error.
+	wantErr bool
+
+	// calls are the arguments of the calls in the order of the calls.
+	calls []Range
+
+	// mu protects the tracker.
+	mu sync.Mutex
+}
+
+// subscriber is the function subscribed to the widget.
+func (st *subscriberTracker) subscriber(r Range) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.wantErr {
+		return errors.New("st.wantErr set to true")
+	}
+	st.calls = append(st.calls, r)
+	return nil
+}
+
+// typeText returns keyboard events that type the text.
+func typeText(text string) []terminalapi.Event {
+	var res []terminalapi.Event
+	for _, r := range text {
+		res = append(res, &terminalapi.Keyboard{Key: keyboard.Key(r)})
+	}
+	return res
+}
+
+// events concatenates events.
+func events(evs ...[]terminalapi.Event) []terminalapi.Event {
+	var res []terminalapi.Event
+	for _, e := range evs {
+		res = append(res, e...)
+	}
+	return res
+}
+
+func TestTimeRange(t *testing.T) {
+	tests := []struct {
+		desc         string
+		opts         []Option
+		tracker      *subscriberTracker
+		events       []terminalapi.Event
+		wantRange    Range
+		wantCalls    []Range
+		wantNewErr   bool
+		wantEventErr bool
+	}{
+		{
+			desc:       "fails without presets",
+			opts:       []Option{Presets()},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on a preset with an empty label",
+			opts:       []Option{Presets(Preset{Last: time.Minute})},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on a preset with a zero duration",
+			opts:       []Option{Presets(Preset{Label: "x"})},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on Selected out of range",
+			opts:       []Option{Presets(testPresets...), Selected(2)},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on an empty CustomLabel",
+			opts:       []Option{CustomLabel("")},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on an empty TimeLayout",
+			opts:       []Option{TimeLayout("")},
+			wantNewErr: true,
+		},
+		{
+			desc:      "selects the first preset by default",
+			opts:      []Option{Presets(testPresets...)},
+			wantRange: lastRange(5*time.Minute, now),
+		},
+		{
+			desc:      "selects the initial preset",
+			opts:      []Option{Presets(testPresets...), Selected(1)},
+			wantRange: lastRange(time.Hour, now),
+		},
+		{
+			desc: "selects a preset with the keyboard",
+			opts: []Option{Presets(testPresets...)},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+			},
+			wantRange: lastRange(time.Hour, now),
+			wantCalls: []Range{lastRange(time.Hour, now)},
+		},
+		{
+			desc: "cursor stops at the first label",
+			opts: []Option{Presets(testPresets...), Selected(1)},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowLeft},
+				&terminalapi.Keyboard{Key: keyboard.KeySpace},
+			},
+			wantRange: lastRange(5*time.Minute, now),
+			wantCalls: []Range{lastRange(5*time.Minute, now)},
+		},
+		{
+			desc: "selects a preset with the mouse",
+			opts: []Option{Presets(testPresets...)},
+			events: []terminalapi.Event{
+				// The labels are " 5m ", " 1h " and " custom ".
+				&terminalapi.Mouse{Position: image.Point{5, 0}, Button: mouse.ButtonLeft},
+			},
+			wantRange: lastRange(time.Hour, now),
+			wantCalls: []Range{lastRange(time.Hour, now)},
+		},
+		{
+			desc: "ignores clicks between the labels",
+			opts: []Option{Presets(testPresets...)},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{4, 0}, Button: mouse.ButtonLeft},
+			},
+			wantRange: lastRange(5*time.Minute, now),
+		},
+		{
+			desc: "ignores other mouse buttons",
+			opts: []Option{Presets(testPresets...)},
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{5, 0}, Button: mouse.ButtonRight},
+			},
+			wantRange: lastRange(5*time.Minute, now),
+		},
+		{
+			desc: "types a custom duration",
+			opts: []Option{Presets(testPresets...)},
+			events: events(
+				[]terminalapi.Event{
+					&terminalapi.Mouse{Position: image.Point{10, 0}, Button: mouse.ButtonLeft},
+				},
+				typeText("90m"),
+				[]terminalapi.Event{
+					&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				},
+			),
+			wantRange: lastRange(90*time.Minute, now),
+			wantCalls: []Range{lastRange(90*time.Minute, now)},
+		},
+		{
+			desc: "types a custom duration in days and corrects a typo",
+			opts: []Option{Presets(testPresets...)},
+			events: events(
+				[]terminalapi.Event{
+					&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+					&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+					&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				},
+				typeText("3x"),
+				[]terminalapi.Event{
+					&terminalapi.Keyboard{Key: keyboard.KeyBackspace2},
+				},
+				typeText("d"),
+				[]terminalapi.Event{
+					&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				},
+			),
+			wantRange: lastRange(72*time.Hour, now),
+			wantCalls: []Range{lastRange(72*time.Hour, now)},
+		},
+		{
+			desc: "types a custom range with a start and an end",
+			opts: []Option{Presets(testPresets...), TimeLayout("15:04")},
+			events: events(
+				[]terminalapi.Event{
+					&terminalapi.Mouse{Position: image.Point{10, 0}, Button: mouse.ButtonLeft},
+				},
+				typeText("10:00 - 11:30"),
+				[]terminalapi.Event{
+					&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				},
+			),
+			wantRange: Range{
+				Start: time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC),
+				End:   time.Date(0, 1, 1, 11, 30, 0, 0, time.UTC),
+			},
+			wantCalls: []Range{
+				{
+					Start: time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC),
+					End:   time.Date(0, 1, 1, 11, 30, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			desc: "rejects an invalid custom range",
+			opts: []Option{Presets(testPresets...)},
+			events: events(
+				[]terminalapi.Event{
+					&terminalapi.Mouse{Position: image.Point{10, 0}, Button: mouse.ButtonLeft},
+				},
+				typeText("soon"),
+				[]terminalapi.Event{
+					&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				},
+			),
+			wantRange: lastRange(5*time.Minute, now),
+		},
+		{
+			desc: "escape cancels the custom range",
+			opts: []Option{Presets(testPresets...)},
+			events: events(
+				[]terminalapi.Event{
+					&terminalapi.Mouse{Position: image.Point{10, 0}, Button: mouse.ButtonLeft},
+				},
+				typeText("2h"),
+				[]terminalapi.Event{
+					&terminalapi.Keyboard{Key: keyboard.KeyEsc},
+					&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				},
+			),
+			wantRange: lastRange(5*time.Minute, now),
+		},
+		{
+			desc:    "forwards errors from the subscribers",
+			opts:    []Option{Presets(testPresets...)},
+			tracker: &subscriberTracker{wantErr: true},
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+				&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+			},
+			wantRange:    lastRange(time.Hour, now),
+			wantEventErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			tr, err := New(tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+			tr.now = func() time.Time { return now }
+
+			tracker := tc.tracker
+			if tracker == nil {
+				tracker = &subscriberTracker{}
+			}
+			tr.Subscribe(tracker.subscriber)
+
+			for i, ev := range tc.events {
+				var err error
+				switch e := ev.(type) {
+				case *terminalapi.Keyboard:
+					err = tr.Keyboard(e)
+				case *terminalapi.Mouse:
+					err = tr.Mouse(e)
+				default:
+					t.Fatalf("unsupported event type: %T", ev)
+				}
+				// Only the last event in test cases is the one that can fail.
+				if i == len(tc.events)-1 {
+					if (err != nil) != tc.wantEventErr {
+						t.Errorf("event %v => unexpected error: %v, wantEventErr: %v", ev, err, tc.wantEventErr)
+					}
+				} else if err != nil {
+					t.Fatalf("event %v => unexpected error: %v", ev, err)
+				}
+			}
+
+			if diff := pretty.Compare(tc.wantRange, tr.Range()); diff != "" {
+				t.Errorf("Range => unexpected diff (-want, +got):\n%s", diff)
+			}
+			if diff := pretty.Compare(tc.wantCalls, tracker.calls); diff != "" {
+				t.Errorf("SubscriberFn => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDraw(t *testing.T) {
+	highlighted := cell.ColorNumber(DefaultHighlightedColorNumber)
+	tests := []struct {
+		desc   string
+		opts   []Option
+		canvas image.Rectangle
+		events []terminalapi.Event
+		meta   *widgetapi.Meta
+		want   func(size image.Point) *faketerm.Terminal
+	}{
+		{
+			desc:   "draws the labels and the selected range",
+			opts:   []Option{Presets(testPresets...), TimeLayout("15:04")},
+			canvas: image.Rect(0, 0, 20, 2),
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, " 5m ", image.Point{0, 0},
+					draw.TextCellOpts(cell.BgColor(DefaultSelectedColor)),
+				)
+				testdraw.MustText(cvs, " 1h ", image.Point{5, 0})
+				testdraw.MustText(cvs, " custom ", image.Point{10, 0})
+				testdraw.MustText(cvs, "11:55 - 12:00", image.Point{0, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "draws the selected range in the locale",
+			opts:   []Option{Presets(testPresets...), Locale(format.EnUS)},
+			canvas: image.Rect(0, 0, 45, 2),
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, " 5m ", image.Point{0, 0},
+					draw.TextCellOpts(cell.BgColor(DefaultSelectedColor)),
+				)
+				testdraw.MustText(cvs, " 1h ", image.Point{5, 0})
+				testdraw.MustText(cvs, " custom ", image.Point{10, 0})
+				testdraw.MustText(cvs, "05/01/2020 11:55AM - 05/01/2020 12:00PM", image.Point{0, 1})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "draws the cursor while focused",
+			opts:   []Option{Presets(testPresets...)},
+			canvas: image.Rect(0, 0, 20, 1),
+			events: []terminalapi.Event{
+				&terminalapi.Keyboard{Key: keyboard.KeyArrowRight},
+			},
+			meta: &widgetapi.Meta{Focused: true},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, " 5m ", image.Point{0, 0},
+					draw.TextCellOpts(cell.BgColor(DefaultSelectedColor)),
+				)
+				testdraw.MustText(cvs, "[1h]", image.Point{5, 0},
+					draw.TextCellOpts(cell.FgColor(highlighted)),
+				)
+				testdraw.MustText(cvs, " custom ", image.Point{10, 0})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "draws the custom range being typed and the error",
+			opts:   []Option{Presets(testPresets...)},
+			canvas: image.Rect(0, 0, 30, 2),
+			events: events(
+				[]terminalapi.Event{
+					&terminalapi.Mouse{Position: image.Point{10, 0}, Button: mouse.ButtonLeft},
+				},
+				typeText("0m"),
+				[]terminalapi.Event{
+					&terminalapi.Keyboard{Key: keyboard.KeyEnter},
+				},
+			),
+			meta: &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, "range: 0m_", image.Point{0, 0})
+				testdraw.MustText(cvs, "duration must be positive", image.Point{0, 1},
+					draw.TextCellOpts(cell.FgColor(DefaultErrorColor)),
+				)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+		{
+			desc:   "trims the labels that don't fit",
+			opts:   []Option{Presets(testPresets...)},
+			canvas: image.Rect(0, 0, 7, 1),
+			meta:   &widgetapi.Meta{},
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustText(cvs, " 5m ", image.Point{0, 0},
+					draw.TextCellOpts(cell.BgColor(DefaultSelectedColor)),
+				)
+				testdraw.MustText(cvs, " 1", image.Point{5, 0})
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			tr, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("New => unexpected error: %v", err)
+			}
+			tr.now = func() time.Time { return now }
+
+			for _, ev := range tc.events {
+				var err error
+				switch e := ev.(type) {
+				case *terminalapi.Keyboard:
+					err = tr.Keyboard(e)
+				case *terminalapi.Mouse:
+					err = tr.Mouse(e)
+				}
+				if err != nil {
+					t.Fatalf("event %v => unexpected error: %v", ev, err)
+				}
+			}
+
+			c, err := canvas.New(tc.canvas)
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := tr.Draw(c, tc.meta); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+		})
+	}
+}
+
+func TestAPI(t *testing.T) {
+	tr, err := New(Presets(testPresets...))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	current := now
+	tr.now = func() time.Time { return current }
+
+	first := &subscriberTracker{}
+	second := &subscriberTracker{}
+	tr.Subscribe(first.subscriber)
+	unsubscribe := tr.Subscribe(second.subscriber)
+
+	if err := tr.Select(2); err == nil {
+		t.Errorf("Select(2) => got nil error, want an error for an index out of range")
+	}
+	if err := tr.Select(1); err != nil {
+		t.Fatalf("Select(1) => unexpected error: %v", err)
+	}
+
+	start, end := now.Add(-2*time.Hour), now.Add(-time.Hour)
+	if err := tr.SetRange(end, start); err == nil {
+		t.Errorf("SetRange => got nil error, want an error when start isn't before end")
+	}
+	if err := tr.SetRange(start, end); err != nil {
+		t.Fatalf("SetRange => unexpected error: %v", err)
+	}
+
+	unsubscribe()
+	if err := tr.SetLast(0); err == nil {
+		t.Errorf("SetLast(0) => got nil error, want an error for a zero duration")
+	}
+	if err := tr.SetLast(time.Minute); err != nil {
+		t.Fatalf("SetLast => unexpected error: %v", err)
+	}
+
+	// Ranges that end at the current time move forward with the time.
+	current = now.Add(time.Minute)
+	if err := tr.Refresh(); err != nil {
+		t.Fatalf("Refresh => unexpected error: %v", err)
+	}
+
+	wantFirst := []Range{
+		lastRange(time.Hour, now),
+		{Start: start, End: end},
+		lastRange(time.Minute, now),
+		lastRange(time.Minute, current),
+	}
+	if diff := pretty.Compare(wantFirst, first.calls); diff != "" {
+		t.Errorf("first subscriber => unexpected diff (-want, +got):\n%s", diff)
+	}
+	wantSecond := []Range{
+		lastRange(time.Hour, now),
+		{Start: start, End: end},
+	}
+	if diff := pretty.Compare(wantSecond, second.calls); diff != "" {
+		t.Errorf("second subscriber => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBus(t *testing.T) {
+	tr, err := New(Presets(testPresets...))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	tr.now = func() time.Time { return now }
+
+	b := bus.New()
+	var got []bus.RangeChanged
+	b.Subscribe(bus.RangeChanged{}, func(msg interface{}) {
+		got = append(got, msg.(bus.RangeChanged))
+	})
+	tr.SetBus(b)
+
+	if err := tr.Select(1); err != nil {
+		t.Fatalf("Select => unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("published %d messages, want 1", len(got))
+	}
+	if got[0].Source != tr {
+		t.Errorf("published Source %v, want the widget", got[0].Source)
+	}
+	if want := lastRange(time.Hour, now); !got[0].Start.Equal(want.Start) || !got[0].End.Equal(want.End) || got[0].Last != want.Last {
+		t.Errorf("published %v - %v last %v, want %v - %v last %v", got[0].Start, got[0].End, got[0].Last, want.Start, want.End, want.Last)
+	}
+}
+
+func TestRangeString(t *testing.T) {
+	if got, want := lastRange(time.Hour, now).String(), "last 1h0m0s"; got != want {
+		t.Errorf("String => %q, want %q", got, want)
+	}
+	if got, want := lastRange(time.Hour, now).Duration(), time.Hour; got != want {
+		t.Errorf("Duration => %v, want %v", got, want)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	tr, err := New(Presets(testPresets...))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	want := widgetapi.Options{
+		MinimumSize:  image.Point{18, 1},
+		WantKeyboard: widgetapi.KeyScopeFocused,
+		WantMouse:    widgetapi.MouseScopeWidget,
+	}
+	if diff := pretty.Compare(want, tr.Options()); diff != "" {
+		t.Errorf("Options => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestDefaultLayoutFollowsTheLocale(t *testing.T) {
+	defer format.SetLocale(format.DefaultLocale)
+
+	format.SetLocale(format.DeDE)
+	tr, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if got, want := tr.opts.layout, "02.01.2006 15:04"; got != want {
+		t.Errorf("layout => %q, want %q", got, want)
+	}
+}
+
+func TestNotifies(t *testing.T) {
+	tr, err := New()
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	tr.SetNotifyFunc(func() {
+		notified++
+	})
+
+	if err := tr.Select(1); err != nil {
+		t.Fatalf("Select => unexpected error: %v", err)
+	}
+	if err := tr.SetLast(time.Minute); err != nil {
+		t.Fatalf("SetLast => unexpected error: %v", err)
+	}
+	now := time.Now()
+	if err := tr.SetRange(now.Add(-time.Hour), now); err != nil {
+		t.Fatalf("SetRange => unexpected error: %v", err)
+	}
+	if err := tr.Refresh(); err != nil {
+		t.Fatalf("Refresh => unexpected error: %v", err)
+	}
+	if want := 4; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
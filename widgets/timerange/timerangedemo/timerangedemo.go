@@ -0,0 +1,168 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary timerangedemo shows the functionality of the time range widget.
+package main
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/linechart"
+	"github.com/mum4k/termdash/widgets/timerange"
+)
+
+// points is the number of points displayed for any range.
+const points = 60
+
+// metric simulates a data source that returns the values of a metric sampled
+// over the range.
+type metric func(t time.Time) float64
+
+// query returns the values of the metric in the range and the labels of the
+// X axis.
+func (m metric) query(r timerange.Range) ([]float64, map[int]string) {
+	layout := "15:04"
+	if r.Duration() > 24*time.Hour {
+		layout = "01-02"
+	}
+
+	step := r.Duration() / points
+	var values []float64
+	labels := map[int]string{}
+	for i := 0; i < points; i++ {
+		t := r.Start.Add(time.Duration(i) * step)
+		values = append(values, m(t))
+		labels[i] = t.Format(layout)
+	}
+	return values, labels
+}
+
+// panel subscribes a line chart to the time range.
+func panel(tr *timerange.TimeRange, label string, m metric, color cell.Color) (*linechart.LineChart, error) {
+	lc, err := linechart.New(
+		linechart.AxesCellOpts(cell.FgColor(cell.ColorRed)),
+		linechart.YLabelCellOpts(cell.FgColor(cell.ColorGreen)),
+		linechart.XLabelCellOpts(cell.FgColor(cell.ColorCyan)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	update := func(r timerange.Range) error {
+		values, labels := m.query(r)
+		return lc.Series(label, values,
+			linechart.SeriesCellOpts(cell.FgColor(color)),
+			linechart.SeriesXLabels(labels),
+		)
+	}
+	if err := update(tr.Range()); err != nil {
+		return nil, err
+	}
+	tr.Subscribe(update)
+	return lc, nil
+}
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tr, err := timerange.New(timerange.Selected(2))
+	if err != nil {
+		panic(err)
+	}
+
+	cpu, err := panel(tr, "cpu", func(t time.Time) float64 {
+		h := float64(t.Unix()) / 3600
+		return 50 + 30*math.Sin(h*2*math.Pi/24) + 10*math.Sin(h*7)
+	}, cell.ColorBlue)
+	if err != nil {
+		panic(err)
+	}
+	qps, err := panel(tr, "qps", func(t time.Time) float64 {
+		m := float64(t.Unix()) / 60
+		return 1000 + 400*math.Cos(m/90) + 150*math.Sin(m/7)
+	}, cell.ColorYellow)
+	if err != nil {
+		panic(err)
+	}
+
+	c, err := container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS CTRL-Q TO QUIT"),
+		container.SplitHorizontal(
+			container.Top(
+				container.Border(linestyle.Light),
+				container.BorderTitle("Time range"),
+				container.PlaceWidget(tr),
+			),
+			container.Bottom(
+				container.SplitVertical(
+					container.Left(
+						container.Border(linestyle.Light),
+						container.BorderTitle("CPU %"),
+						container.PlaceWidget(cpu),
+					),
+					container.Right(
+						container.Border(linestyle.Light),
+						container.BorderTitle("Queries per second"),
+						container.PlaceWidget(qps),
+					),
+				),
+			),
+			container.SplitFixed(4),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := tr.Refresh(); err != nil {
+					panic(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == keyboard.KeyCtrlQ {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter), termdash.RedrawInterval(100*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
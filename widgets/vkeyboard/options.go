@@ -0,0 +1,154 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vkeyboard
+
+// options.go contains configurable options for VKeyboard.
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/private/wrap"
+)
+
+// Option is used to provide options.
+type Option interface {
+	// set sets the provided option.
+	set(*options)
+}
+
+// option implements Option.
+type option func(*options)
+
+// set implements Option.set.
+func (o option) set(opts *options) {
+	o(opts)
+}
+
+// options holds the provided options.
+type options struct {
+	layout       [][]Key
+	keyColor     cell.Color
+	textColor    cell.Color
+	pressedColor cell.Color
+}
+
+// validate validates the provided options.
+func (o *options) validate() error {
+	if len(o.layout) == 0 {
+		return errors.New("invalid Layout, must contain at least one row")
+	}
+	for r, row := range o.layout {
+		if len(row) == 0 {
+			return fmt.Errorf("invalid Layout, row #%d must contain at least one key", r)
+		}
+		for _, k := range row {
+			if err := wrap.ValidText(k.Label); err != nil {
+				return fmt.Errorf("invalid Layout, the label of key %v in row #%d is invalid: %v", k.Key, r, err)
+			}
+		}
+	}
+	return nil
+}
+
+// newOptions returns options with the default values set.
+func newOptions() *options {
+	return &options{
+		layout:       DefaultLayout(),
+		keyColor:     cell.ColorNumber(DefaultKeyColorNumber),
+		pressedColor: cell.ColorNumber(DefaultPressedColorNumber),
+	}
+}
+
+// Key is a key on the on-screen keyboard.
+type Key struct {
+	// Label is the text displayed on the key.
+	Label string
+	// Key is the key emitted when the key is pressed.
+	Key keyboard.Key
+	// Shift when true makes this key a shift key, which doesn't emit any key
+	// and instead makes the next letter key emit an upper case letter.
+	Shift bool
+}
+
+// DefaultLayout returns the default layout of the keys, a simplified QWERTY
+// keyboard.
+func DefaultLayout() [][]Key {
+	return [][]Key{
+		append(runeKeys("1234567890-="), Key{Label: "Bksp", Key: keyboard.KeyBackspace2}),
+		append([]Key{{Label: "Tab", Key: keyboard.KeyTab}}, runeKeys("qwertyuiop")...),
+		append(runeKeys("asdfghjkl;'"), Key{Label: "Enter", Key: keyboard.KeyEnter}),
+		append([]Key{{Label: "Shift", Shift: true}}, runeKeys("zxcvbnm,./")...),
+		{
+			{Label: "Esc", Key: keyboard.KeyEsc},
+			{Label: "Space", Key: keyboard.KeySpace},
+			{Label: "←", Key: keyboard.KeyArrowLeft},
+			{Label: "→", Key: keyboard.KeyArrowRight},
+			{Label: "↑", Key: keyboard.KeyArrowUp},
+			{Label: "↓", Key: keyboard.KeyArrowDown},
+		},
+	}
+}
+
+// runeKeys returns keys that emit the runes and are labeled with them.
+func runeKeys(runes string) []Key {
+	var keys []Key
+	for _, r := range runes {
+		keys = append(keys, Key{Label: string(r), Key: keyboard.Key(r)})
+	}
+	return keys
+}
+
+// Layout sets the keys displayed on the keyboard, one slice of keys per row.
+// Defaults to DefaultLayout.
+func Layout(rows ...[]Key) Option {
+	return option(func(opts *options) {
+		opts.layout = rows
+	})
+}
+
+// DefaultKeyColorNumber is the default color number for the KeyColor option.
+const DefaultKeyColorNumber = 238
+
+// KeyColor sets the background color of the keys.
+// Defaults to DefaultKeyColorNumber.
+func KeyColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.keyColor = c
+	})
+}
+
+// TextColor sets the color of the labels on the keys.
+// Defaults to the default terminal color.
+func TextColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.textColor = c
+	})
+}
+
+// DefaultPressedColorNumber is the default color number for the PressedColor
+// option.
+const DefaultPressedColorNumber = 33
+
+// PressedColor sets the background color of the key while the mouse button is
+// pressed on it and of the shift key while it is active.
+// Defaults to DefaultPressedColorNumber.
+func PressedColor(c cell.Color) Option {
+	return option(func(opts *options) {
+		opts.pressedColor = c
+	})
+}
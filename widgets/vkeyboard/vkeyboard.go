@@ -0,0 +1,288 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vkeyboard implements an on-screen keyboard widget.
+package vkeyboard
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/runewidth"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// KeyFn is called with a keyboard event each time the user clicks a key on the
+// on-screen keyboard. Usually the function passes the event to
+// container.Container.Inject, which delivers it to the focused widget.
+//
+// The callback function must be thread-safe as the mouse events that press
+// the keys are processed in a separate goroutine.
+//
+// If the function returns an error, the widget will forward it back to the
+// termdash infrastructure which causes a panic, unless the user provided a
+// termdash.ErrorHandler.
+type KeyFn func(k *terminalapi.Keyboard) error
+
+// keyGap is the number of cells between two keys in a row.
+const keyGap = 1
+
+// noKey is used when no key is pressed.
+var noKey = image.Point{-1, -1}
+
+// VKeyboard is an on-screen keyboard that emits keyboard events when its keys
+// are clicked with the mouse. Useful on touch screen terminals where a
+// physical keyboard might not be available.
+//
+// The keyboard is meant to be placed into a container with the
+// container.NoFocus option, so that clicking it doesn't move the keyboard
+// focus away from the widget that should receive the keys.
+//
+// Implements widgetapi.Widget. This object is thread-safe.
+type VKeyboard struct {
+	// mu protects the widget.
+	mu sync.Mutex
+
+	// keyFn is called when a key is clicked.
+	keyFn KeyFn
+
+	// areas are the areas of the keys on the canvas, indexed the same way as
+	// the layout.
+	areas [][]image.Rectangle
+
+	// pressed is the column and the row of the key the mouse button was
+	// pressed on or noKey.
+	pressed image.Point
+	// shifted indicates that the next letter is emitted in upper case.
+	shifted bool
+
+	// opts are the provided options.
+	opts *options
+
+	// notify is called when the content changes, see SetNotifyFunc.
+	notify func()
+}
+
+// New returns a new VKeyboard that calls the function when a key is clicked.
+func New(fn KeyFn, opts ...Option) (*VKeyboard, error) {
+	if fn == nil {
+		return nil, errors.New("the KeyFn cannot be nil")
+	}
+	opt := newOptions()
+	for _, o := range opts {
+		o.set(opt)
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+
+	var areas [][]image.Rectangle
+	for y, row := range opt.layout {
+		var rowAreas []image.Rectangle
+		x := 0
+		for _, k := range row {
+			// One cell of padding on each side of the label.
+			width := runewidth.StringWidth(k.Label) + 2
+			rowAreas = append(rowAreas, image.Rect(x, y, x+width, y+1))
+			x += width + keyGap
+		}
+		areas = append(areas, rowAreas)
+	}
+	return &VKeyboard{
+		keyFn:   fn,
+		areas:   areas,
+		pressed: noKey,
+		opts:    opt,
+	}, nil
+}
+
+// Shifted determines if the shift key is active, i.e. if the next letter will
+// be emitted in upper case.
+func (vk *VKeyboard) Shifted() bool {
+	vk.mu.Lock()
+	defer vk.mu.Unlock()
+
+	return vk.shifted
+}
+
+// isLetter determines if the key emits a letter.
+func isLetter(k keyboard.Key) bool {
+	return k >= 0 && unicode.IsLetter(rune(k))
+}
+
+// Draw draws the VKeyboard widget onto the canvas.
+// Implements widgetapi.Widget.Draw.
+func (vk *VKeyboard) Draw(cvs *canvas.Canvas, meta *widgetapi.Meta) error {
+	vk.mu.Lock()
+	defer vk.mu.Unlock()
+
+	needAr := image.Rectangle{image.ZP, vk.minSize()}
+	if !needAr.In(cvs.Area()) {
+		return draw.ResizeNeeded(cvs)
+	}
+
+	for y, row := range vk.opts.layout {
+		for x, k := range row {
+			bg := vk.opts.keyColor
+			if (image.Point{x, y}) == vk.pressed || k.Shift && vk.shifted {
+				bg = vk.opts.pressedColor
+			}
+			ar := vk.areas[y][x]
+			if err := cvs.SetAreaCells(ar, ' ', cell.BgColor(bg)); err != nil {
+				return err
+			}
+
+			label := k.Label
+			if vk.shifted && isLetter(k.Key) {
+				label = strings.ToUpper(label)
+			}
+			if err := draw.Text(cvs, label, ar.Min.Add(image.Point{1, 0}), draw.TextCellOpts(
+				cell.FgColor(vk.opts.textColor),
+				cell.BgColor(bg),
+			)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Keyboard input isn't supported on the VKeyboard widget.
+func (*VKeyboard) Keyboard(k *terminalapi.Keyboard) error {
+	return errors.New("the VKeyboard widget doesn't support keyboard events")
+}
+
+// keyAt returns the column and the row of the key at the point or noKey.
+func (vk *VKeyboard) keyAt(p image.Point) image.Point {
+	for y, row := range vk.areas {
+		for x, ar := range row {
+			if p.In(ar) {
+				return image.Point{x, y}
+			}
+		}
+	}
+	return noKey
+}
+
+// mouse processes mouse events.
+// Returns the keyboard event to emit or nil.
+func (vk *VKeyboard) mouse(m *terminalapi.Mouse) *terminalapi.Keyboard {
+	vk.mu.Lock()
+	defer vk.mu.Unlock()
+
+	pressed, shifted := vk.pressed, vk.shifted
+	defer func() {
+		if vk.pressed != pressed || vk.shifted != shifted {
+			vk.markChanged()
+		}
+	}()
+
+	switch m.Button {
+	case mouse.ButtonLeft:
+		// Terminals repeat the press event while the mouse moves with the
+		// button held, only the key under the initial press counts.
+		if vk.pressed == noKey {
+			vk.pressed = vk.keyAt(m.Position)
+		} else if vk.keyAt(m.Position) != vk.pressed {
+			vk.pressed = noKey
+		}
+		return nil
+
+	case mouse.ButtonRelease:
+		pressed := vk.pressed
+		vk.pressed = noKey
+		if pressed == noKey || vk.keyAt(m.Position) != pressed {
+			return nil
+		}
+
+		k := vk.opts.layout[pressed.Y][pressed.X]
+		if k.Shift {
+			vk.shifted = !vk.shifted
+			return nil
+		}
+		key := k.Key
+		if vk.shifted && isLetter(key) {
+			key = keyboard.Key(unicode.ToUpper(rune(key)))
+			vk.shifted = false
+		}
+		return &terminalapi.Keyboard{Key: key}
+	}
+	return nil
+}
+
+// Mouse processes mouse events, a key is clicked when the left mouse button
+// is pressed and released on it.
+// Implements widgetapi.Widget.Mouse.
+func (vk *VKeyboard) Mouse(m *terminalapi.Mouse) error {
+	k := vk.mouse(m)
+	if k == nil {
+		return nil
+	}
+	// Mutex must be released when calling the callback.
+	// Users might call container methods from the callback like the
+	// Container.Inject, see #205.
+	return vk.keyFn(k)
+}
+
+// SetNotifyFunc implements widgetapi.Notifier.SetNotifyFunc.
+func (vk *VKeyboard) SetNotifyFunc(fn func()) {
+	vk.mu.Lock()
+	defer vk.mu.Unlock()
+	vk.notify = fn
+}
+
+// markChanged notifies the infrastructure that the content changed.
+// vk.mu must be held when calling this method.
+func (vk *VKeyboard) markChanged() {
+	if vk.notify != nil {
+		vk.notify()
+	}
+}
+
+// minSize returns the size the keyboard needs.
+// Caller must hold vk.mu.
+func (vk *VKeyboard) minSize() image.Point {
+	var size image.Point
+	for _, row := range vk.areas {
+		last := row[len(row)-1]
+		if last.Max.X > size.X {
+			size.X = last.Max.X
+		}
+		size.Y = last.Max.Y
+	}
+	return size
+}
+
+// Options implements widgetapi.Widget.Options.
+func (vk *VKeyboard) Options() widgetapi.Options {
+	vk.mu.Lock()
+	defer vk.mu.Unlock()
+
+	return widgetapi.Options{
+		MinimumSize:  vk.minSize(),
+		WantKeyboard: widgetapi.KeyScopeNone,
+		// The global scope ensures the widget sees the release of the mouse
+		// button even if it happens outside of its canvas.
+		WantMouse: widgetapi.MouseScopeGlobal,
+	}
+}
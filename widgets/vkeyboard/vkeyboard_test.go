@@ -0,0 +1,424 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vkeyboard
+
+import (
+	"errors"
+	"image"
+	"sync"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/mouse"
+	"github.com/mum4k/termdash/private/canvas"
+	"github.com/mum4k/termdash/private/canvas/testcanvas"
+	"github.com/mum4k/termdash/private/draw"
+	"github.com/mum4k/termdash/private/draw/testdraw"
+	"github.com/mum4k/termdash/private/faketerm"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+)
+
+// keyTracker tracks the calls of the KeyFn callback.
+type keyTracker struct {
+	// wantErr when set to true, makes callback return an error.
+	wantErr bool
+
+	// keys are the emitted keys in the order they were emitted.
+	keys []keyboard.Key
+
+	// mu protects the tracker.
+	mu sync.Mutex
+}
+
+// keyFn is the KeyFn callback.
+func (kt *keyTracker) keyFn(k *terminalapi.Keyboard) error {
+	kt.mu.Lock()
+	defer kt.mu.Unlock()
+
+	if kt.wantErr {
+		return errors.New("kt.wantErr set to true")
+	}
+	kt.keys = append(kt.keys, k.Key)
+	return nil
+}
+
+// testLayout is the layout used in tests. The first row has key "a" at
+// (0,0)-(3,1) and key "b" at (4,0)-(7,1), the second row has the shift key at
+// (0,1)-(7,2).
+var testLayout = Layout(
+	[]Key{
+		{Label: "a", Key: 'a'},
+		{Label: "b", Key: 'b'},
+	},
+	[]Key{
+		{Label: "Shift", Shift: true},
+	},
+)
+
+// mustDrawKey draws a key with the label at the area.
+func mustDrawKey(cvs *canvas.Canvas, ar image.Rectangle, label string, bgColorNumber int) {
+	bg := cell.BgColor(cell.ColorNumber(bgColorNumber))
+	testcanvas.MustSetAreaCells(cvs, ar, ' ', bg)
+	testdraw.MustText(cvs, label, ar.Min.Add(image.Point{1, 0}), draw.TextCellOpts(
+		cell.FgColor(cell.ColorDefault),
+		bg,
+	))
+}
+
+// mustDrawKeys draws the keys of the testLayout, the pressed key and the
+// shift key are drawn with the pressed color.
+func mustDrawKeys(size image.Point, shifted bool, pressed string) *faketerm.Terminal {
+	ft := faketerm.MustNew(size)
+	cvs := testcanvas.MustNew(ft.Area())
+
+	a, b := "a", "b"
+	if shifted {
+		a, b = "A", "B"
+	}
+	color := func(label string, active bool) int {
+		if label == pressed || active {
+			return DefaultPressedColorNumber
+		}
+		return DefaultKeyColorNumber
+	}
+	mustDrawKey(cvs, image.Rect(0, 0, 3, 1), a, color("a", false))
+	mustDrawKey(cvs, image.Rect(4, 0, 7, 1), b, color("b", false))
+	mustDrawKey(cvs, image.Rect(0, 1, 7, 2), "Shift", color("Shift", shifted))
+	testcanvas.MustApply(cvs, ft)
+	return ft
+}
+
+// click returns the mouse events that click the point.
+func click(p image.Point) []terminalapi.Event {
+	return []terminalapi.Event{
+		&terminalapi.Mouse{Position: p, Button: mouse.ButtonLeft},
+		&terminalapi.Mouse{Position: p, Button: mouse.ButtonRelease},
+	}
+}
+
+// concat concatenates the events.
+func concat(evs ...[]terminalapi.Event) []terminalapi.Event {
+	var res []terminalapi.Event
+	for _, ev := range evs {
+		res = append(res, ev...)
+	}
+	return res
+}
+
+func TestVKeyboard(t *testing.T) {
+	tests := []struct {
+		desc         string
+		opts         []Option
+		tracker      *keyTracker
+		canvas       image.Rectangle
+		events       []terminalapi.Event
+		want         func(size image.Point) *faketerm.Terminal
+		wantKeys     *keyTracker
+		wantShifted  bool
+		wantNewErr   bool
+		wantEventErr bool
+	}{
+		{
+			desc:       "fails without a KeyFn",
+			opts:       []Option{testLayout},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on a layout without rows",
+			opts:       []Option{Layout()},
+			tracker:    &keyTracker{},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on a layout with an empty row",
+			opts:       []Option{Layout([]Key{{Label: "a", Key: 'a'}}, nil)},
+			tracker:    &keyTracker{},
+			wantNewErr: true,
+		},
+		{
+			desc:       "fails on a key without a label",
+			opts:       []Option{Layout([]Key{{Key: 'a'}})},
+			tracker:    &keyTracker{},
+			wantNewErr: true,
+		},
+		{
+			desc:    "draws the keys",
+			opts:    []Option{testLayout},
+			tracker: &keyTracker{},
+			canvas:  image.Rect(0, 0, 7, 2),
+			want: func(size image.Point) *faketerm.Terminal {
+				return mustDrawKeys(size, false, "")
+			},
+			wantKeys: &keyTracker{},
+		},
+		{
+			desc:    "requests resize when the canvas is too small",
+			opts:    []Option{testLayout},
+			tracker: &keyTracker{},
+			canvas:  image.Rect(0, 0, 6, 2),
+			want: func(size image.Point) *faketerm.Terminal {
+				ft := faketerm.MustNew(size)
+				cvs := testcanvas.MustNew(ft.Area())
+				testdraw.MustResizeNeeded(cvs)
+				testcanvas.MustApply(cvs, ft)
+				return ft
+			},
+			wantKeys: &keyTracker{},
+		},
+		{
+			desc:    "highlights the key while the mouse button is pressed",
+			opts:    []Option{testLayout},
+			tracker: &keyTracker{},
+			canvas:  image.Rect(0, 0, 7, 2),
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{5, 0}, Button: mouse.ButtonLeft},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return mustDrawKeys(size, false, "b")
+			},
+			wantKeys: &keyTracker{},
+		},
+		{
+			desc:    "clicking keys emits them",
+			opts:    []Option{testLayout},
+			tracker: &keyTracker{},
+			canvas:  image.Rect(0, 0, 7, 2),
+			events: concat(
+				click(image.Point{0, 0}),
+				click(image.Point{6, 0}),
+				click(image.Point{2, 0}),
+			),
+			want: func(size image.Point) *faketerm.Terminal {
+				return mustDrawKeys(size, false, "")
+			},
+			wantKeys: &keyTracker{
+				keys: []keyboard.Key{'a', 'b', 'a'},
+			},
+		},
+		{
+			desc:    "repeated press events while the button is held emit one key",
+			opts:    []Option{testLayout},
+			tracker: &keyTracker{},
+			canvas:  image.Rect(0, 0, 7, 2),
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{1, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{2, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{2, 0}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return mustDrawKeys(size, false, "")
+			},
+			wantKeys: &keyTracker{
+				keys: []keyboard.Key{'a'},
+			},
+		},
+		{
+			desc:    "moving off the key cancels the press",
+			opts:    []Option{testLayout},
+			tracker: &keyTracker{},
+			canvas:  image.Rect(0, 0, 7, 2),
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{4, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{4, 0}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return mustDrawKeys(size, false, "")
+			},
+			wantKeys: &keyTracker{},
+		},
+		{
+			desc:    "releasing outside of the widget doesn't emit",
+			opts:    []Option{testLayout},
+			tracker: &keyTracker{},
+			canvas:  image.Rect(0, 0, 7, 2),
+			events: []terminalapi.Event{
+				&terminalapi.Mouse{Position: image.Point{0, 0}, Button: mouse.ButtonLeft},
+				&terminalapi.Mouse{Position: image.Point{-1, -1}, Button: mouse.ButtonRelease},
+			},
+			want: func(size image.Point) *faketerm.Terminal {
+				return mustDrawKeys(size, false, "")
+			},
+			wantKeys: &keyTracker{},
+		},
+		{
+			desc:    "shift displays upper case letters",
+			opts:    []Option{testLayout},
+			tracker: &keyTracker{},
+			canvas:  image.Rect(0, 0, 7, 2),
+			events:  click(image.Point{0, 1}),
+			want: func(size image.Point) *faketerm.Terminal {
+				return mustDrawKeys(size, true, "")
+			},
+			wantKeys:    &keyTracker{},
+			wantShifted: true,
+		},
+		{
+			desc:    "shift applies to the next letter only",
+			opts:    []Option{testLayout},
+			tracker: &keyTracker{},
+			canvas:  image.Rect(0, 0, 7, 2),
+			events: concat(
+				click(image.Point{0, 1}),
+				click(image.Point{0, 0}),
+				click(image.Point{0, 0}),
+			),
+			want: func(size image.Point) *faketerm.Terminal {
+				return mustDrawKeys(size, false, "")
+			},
+			wantKeys: &keyTracker{
+				keys: []keyboard.Key{'A', 'a'},
+			},
+		},
+		{
+			desc:    "clicking shift again deactivates it",
+			opts:    []Option{testLayout},
+			tracker: &keyTracker{},
+			canvas:  image.Rect(0, 0, 7, 2),
+			events: concat(
+				click(image.Point{0, 1}),
+				click(image.Point{0, 1}),
+			),
+			want: func(size image.Point) *faketerm.Terminal {
+				return mustDrawKeys(size, false, "")
+			},
+			wantKeys: &keyTracker{},
+		},
+		{
+			desc: "forwards errors from the KeyFn",
+			opts: []Option{testLayout},
+			tracker: &keyTracker{
+				wantErr: true,
+			},
+			canvas:       image.Rect(0, 0, 7, 2),
+			events:       click(image.Point{0, 0}),
+			wantEventErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			var fn KeyFn
+			if tc.tracker != nil {
+				fn = tc.tracker.keyFn
+			}
+			vk, err := New(fn, tc.opts...)
+			if (err != nil) != tc.wantNewErr {
+				t.Errorf("New => unexpected error: %v, wantNewErr: %v", err, tc.wantNewErr)
+			}
+			if err != nil {
+				return
+			}
+
+			for i, ev := range tc.events {
+				err := vk.Mouse(ev.(*terminalapi.Mouse))
+				// Only the last event in test cases is the one that can fail.
+				if i == len(tc.events)-1 {
+					if (err != nil) != tc.wantEventErr {
+						t.Errorf("Mouse(%v) => unexpected error: %v, wantEventErr: %v", ev, err, tc.wantEventErr)
+					}
+					if err != nil {
+						return
+					}
+				} else if err != nil {
+					t.Fatalf("Mouse(%v) => unexpected error: %v", ev, err)
+				}
+			}
+
+			c, err := canvas.New(tc.canvas)
+			if err != nil {
+				t.Fatalf("canvas.New => unexpected error: %v", err)
+			}
+			if err := vk.Draw(c, &widgetapi.Meta{}); err != nil {
+				t.Fatalf("Draw => unexpected error: %v", err)
+			}
+			got, err := faketerm.New(c.Size())
+			if err != nil {
+				t.Fatalf("faketerm.New => unexpected error: %v", err)
+			}
+			if err := c.Apply(got); err != nil {
+				t.Fatalf("Apply => unexpected error: %v", err)
+			}
+			if diff := faketerm.Diff(tc.want(c.Size()), got); diff != "" {
+				t.Errorf("Draw => %v", diff)
+			}
+
+			if diff := pretty.Compare(tc.wantKeys, tc.tracker); diff != "" {
+				t.Errorf("KeyFn => unexpected diff (-want, +got):\n%s", diff)
+			}
+			if got := vk.Shifted(); got != tc.wantShifted {
+				t.Errorf("Shifted => %v, want %v", got, tc.wantShifted)
+			}
+		})
+	}
+}
+
+func TestKeyboard(t *testing.T) {
+	vk, err := New(func(*terminalapi.Keyboard) error { return nil })
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if err := vk.Keyboard(&terminalapi.Keyboard{}); err == nil {
+		t.Errorf("Keyboard => got nil err, wanted one")
+	}
+}
+
+func TestOptions(t *testing.T) {
+	vk, err := New(func(*terminalapi.Keyboard) error { return nil }, testLayout)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+
+	want := widgetapi.Options{
+		MinimumSize:  image.Point{7, 2},
+		WantKeyboard: widgetapi.KeyScopeNone,
+		WantMouse:    widgetapi.MouseScopeGlobal,
+	}
+	if diff := pretty.Compare(want, vk.Options()); diff != "" {
+		t.Errorf("Options => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestDefaultLayout(t *testing.T) {
+	if _, err := New(func(*terminalapi.Keyboard) error { return nil }); err != nil {
+		t.Fatalf("New => unexpected error with the DefaultLayout: %v", err)
+	}
+}
+
+func TestNotifies(t *testing.T) {
+	kt := &keyTracker{}
+	vk, err := New(kt.keyFn)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	notified := 0
+	vk.SetNotifyFunc(func() {
+		notified++
+	})
+
+	for _, b := range []mouse.Button{mouse.ButtonLeft, mouse.ButtonLeft, mouse.ButtonRelease} {
+		if err := vk.Mouse(&terminalapi.Mouse{Position: image.Point{0, 0}, Button: b}); err != nil {
+			t.Fatalf("Mouse => unexpected error: %v", err)
+		}
+	}
+	// The repeated press doesn't change the state.
+	if want := 2; notified != want {
+		t.Errorf("notified %d times, want %d", notified, want)
+	}
+}
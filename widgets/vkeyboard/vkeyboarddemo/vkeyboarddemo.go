@@ -0,0 +1,106 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary vkeyboarddemo shows the functionality of the on-screen keyboard
+// widget.
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/termbox"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/text"
+	"github.com/mum4k/termdash/widgets/textinput"
+	"github.com/mum4k/termdash/widgets/vkeyboard"
+)
+
+func main() {
+	t, err := termbox.New()
+	if err != nil {
+		panic(err)
+	}
+	defer t.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	log, err := text.New(text.RollContent())
+	if err != nil {
+		panic(err)
+	}
+	input, err := textinput.New(
+		textinput.Label("Message: "),
+		textinput.PlaceHolder("click here, then type on the keyboard below"),
+		textinput.ClearOnSubmit(),
+		textinput.OnSubmit(func(text string) error {
+			return log.Write(fmt.Sprintf("submitted: %s\n", text))
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	var c *container.Container
+	vk, err := vkeyboard.New(func(k *terminalapi.Keyboard) error {
+		return c.Inject(k)
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	c, err = container.New(
+		t,
+		container.Border(linestyle.Light),
+		container.BorderTitle("PRESS ESC TO QUIT"),
+		container.SplitHorizontal(
+			container.Top(
+				container.SplitHorizontal(
+					container.Top(
+						container.PlaceWidget(input),
+					),
+					container.Bottom(
+						container.Border(linestyle.Light),
+						container.PlaceWidget(log),
+					),
+					container.SplitFixed(3),
+				),
+			),
+			container.Bottom(
+				container.NoFocus(),
+				container.Border(linestyle.Light),
+				container.BorderTitle("Keyboard"),
+				container.PlaceWidget(vk),
+			),
+			container.SplitPercent(50),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	quitter := func(k *terminalapi.Keyboard) {
+		if k.Key == keyboard.KeyEsc {
+			cancel()
+		}
+	}
+
+	if err := termdash.Run(ctx, t, c, termdash.KeyboardSubscriber(quitter), termdash.RedrawInterval(100*time.Millisecond)); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termdash
+
+// workspace.go contains code that saves and restores the workspace slots.
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mum4k/termdash/keybinding"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/workspace"
+)
+
+// WorkspaceSlots is the number of workspace slots bound by WorkspaceKeys.
+const WorkspaceSlots = 9
+
+// WorkspaceKeys binds the key sequences that save the arrangement of the
+// containers on the displayed screen into the workspace slots and restore it
+// from them. The slots are selected by the digit keys 1 to 9 pressed after
+// the save or the restore sequence, e.g. with save set to Ctrl-S, Ctrl-S 2
+// saves the arrangement into the second slot.
+// The sequences are bound in the registry provided via the KeyBindings
+// option, which is required.
+func WorkspaceKeys(s *workspace.Slots, save, restore keybinding.Sequence) Option {
+	return option(func(td *termdash) {
+		td.workspace = s
+		td.workspaceSaveKey = save
+		td.workspaceRestoreKey = restore
+	})
+}
+
+// bindWorkspaceKeys binds the WorkspaceKeys sequences in the key bindings.
+func (td *termdash) bindWorkspaceKeys() error {
+	if td.workspaceSaveKey == nil && td.workspaceRestoreKey == nil {
+		return nil
+	}
+	if td.workspace == nil {
+		return errors.New("the slots of the WorkspaceKeys cannot be nil")
+	}
+	if len(td.workspaceSaveKey) == 0 || len(td.workspaceRestoreKey) == 0 {
+		return fmt.Errorf("invalid WorkspaceKeys(%v, %v), both sequences must be provided", td.workspaceSaveKey, td.workspaceRestoreKey)
+	}
+
+	for slot := 1; slot <= WorkspaceSlots; slot++ {
+		slot := slot
+		digit := keyboard.Key('0' + slot)
+		save := append(append(keybinding.Sequence(nil), td.workspaceSaveKey...), digit)
+		if err := td.keyBindings.Bind(save, func() error {
+			return td.workspace.Save(slot, td.activeContainer())
+		}, keybinding.Description(fmt.Sprintf("Save the workspace into slot %d", slot))); err != nil {
+			return fmt.Errorf("invalid WorkspaceKeys: %v", err)
+		}
+
+		restore := append(append(keybinding.Sequence(nil), td.workspaceRestoreKey...), digit)
+		if err := td.keyBindings.Bind(restore, func() error {
+			return td.restoreWorkspace(slot)
+		}, keybinding.Description(fmt.Sprintf("Restore the workspace from slot %d", slot))); err != nil {
+			return fmt.Errorf("invalid WorkspaceKeys: %v", err)
+		}
+	}
+	return nil
+}
+
+// restoreWorkspace arranges the containers on the displayed screen as saved
+// in the slot and redraws the terminal.
+func (td *termdash) restoreWorkspace(slot int) error {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	if err := td.workspace.Restore(slot, td.container); err != nil {
+		return err
+	}
+	return td.redraw()
+}
@@ -0,0 +1,241 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package workspace saves the runtime arrangements of the containers, e.g.
+// the sizes of resized splits and the maximized container, into numbered
+// slots, so that the user can switch between them. See
+// container.Arrangement and termdash.WorkspaceKeys.
+//
+// The slots can be persisted between the runs of the dashboard:
+//
+//	s, err := workspace.New(workspace.File("/home/user/.dashboard-workspaces"))
+//	...
+//	err := termdash.Run(ctx, t, c, termdash.KeyBindings(r), termdash.WorkspaceKeys(s, save, restore))
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/mum4k/termdash/container"
+)
+
+// Store persists the slots.
+type Store interface {
+	// Load returns the data saved last or nil if nothing was saved yet.
+	Load() ([]byte, error)
+	// Save replaces the saved data.
+	Save(data []byte) error
+}
+
+// fileStore implements Store with a file.
+type fileStore struct {
+	// path is the path to the file.
+	path string
+}
+
+// File returns a Store that keeps the slots in the file at the path. The
+// file is created when the first slot is saved.
+func File(path string) Store {
+	return &fileStore{path: path}
+}
+
+// Load implements Store.Load.
+func (fs *fileStore) Load() ([]byte, error) {
+	b, err := ioutil.ReadFile(fs.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// Save implements Store.Save.
+// The file is replaced atomically, so a failed save keeps the slots saved
+// before.
+func (fs *fileStore) Save(data []byte) error {
+	tmp := fs.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, fs.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// saved is the JSON encoding of the slots.
+type saved struct {
+	Slots map[int]*container.Arrangement `json:"slots"`
+}
+
+// Slots are numbered slots that hold arrangements of the containers.
+//
+// This object is thread-safe.
+type Slots struct {
+	// store persists the slots, nil if the slots are only kept in memory.
+	store Store
+
+	// mu protects slots.
+	mu sync.Mutex
+	// slots maps the slot numbers to the saved arrangements.
+	slots map[int]*container.Arrangement
+}
+
+// New returns new slots persisted in the store, loading the slots saved in
+// it. The store can be nil, in which case the slots are only kept in memory.
+func New(store Store) (*Slots, error) {
+	s := &Slots{
+		store: store,
+		slots: map[int]*container.Arrangement{},
+	}
+	if store == nil {
+		return s, nil
+	}
+
+	b, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("store.Load => %v", err)
+	}
+	if b == nil {
+		return s, nil
+	}
+	var sv saved
+	if err := json.Unmarshal(b, &sv); err != nil {
+		return nil, fmt.Errorf("invalid saved slots: %v", err)
+	}
+	for slot, a := range sv.Slots {
+		if err := validSlot(slot); err != nil {
+			return nil, fmt.Errorf("invalid saved slots: %v", err)
+		}
+		if a != nil {
+			s.slots[slot] = a
+		}
+	}
+	return s, nil
+}
+
+// validSlot validates the slot number.
+func validSlot(slot int) error {
+	if slot < 1 {
+		return fmt.Errorf("invalid slot %d, must be a positive integer", slot)
+	}
+	return nil
+}
+
+// Save saves the current arrangement of the containers into the slot,
+// replacing the arrangement saved in it before. The slots don't change if
+// they cannot be persisted in the store.
+func (s *Slots) Save(slot int, c *container.Container) error {
+	if err := validSlot(slot); err != nil {
+		return err
+	}
+	a, err := c.Arrangement()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slots := s.copySlots()
+	slots[slot] = a
+	if err := s.persist(slots); err != nil {
+		return err
+	}
+	s.slots = slots
+	return nil
+}
+
+// Restore arranges the containers as saved in the slot. Does nothing if the
+// slot is empty. The containers must be drawn again for the arrangement to
+// take effect.
+func (s *Slots) Restore(slot int, c *container.Container) error {
+	if err := validSlot(slot); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	a := s.slots[slot]
+	s.mu.Unlock()
+	if a == nil {
+		return nil
+	}
+	return c.Arrange(a)
+}
+
+// Delete empties the slot. The slot isn't emptied if the change cannot be
+// persisted in the store.
+func (s *Slots) Delete(slot int) error {
+	if err := validSlot(slot); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.slots[slot]; !ok {
+		return nil
+	}
+	slots := s.copySlots()
+	delete(slots, slot)
+	if err := s.persist(slots); err != nil {
+		return err
+	}
+	s.slots = slots
+	return nil
+}
+
+// Saved returns the numbers of the slots that hold an arrangement in
+// ascending order.
+func (s *Slots) Saved() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var res []int
+	for slot := range s.slots {
+		res = append(res, slot)
+	}
+	sort.Ints(res)
+	return res
+}
+
+// copySlots returns a copy of the slots that can be modified and persisted
+// before it replaces them.
+// Caller must hold s.mu.
+func (s *Slots) copySlots() map[int]*container.Arrangement {
+	slots := make(map[int]*container.Arrangement, len(s.slots))
+	for slot, a := range s.slots {
+		slots[slot] = a
+	}
+	return slots
+}
+
+// persist saves the provided slots into the store.
+// Caller must hold s.mu.
+func (s *Slots) persist(slots map[int]*container.Arrangement) error {
+	if s.store == nil {
+		return nil
+	}
+	b, err := json.MarshalIndent(&saved{Slots: slots}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := s.store.Save(b); err != nil {
+		return fmt.Errorf("store.Save => %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"errors"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/private/faketerm"
+)
+
+// memStore is a Store that keeps the data in memory.
+type memStore struct {
+	data    []byte
+	loadErr error
+	saveErr error
+}
+
+// Load implements Store.Load.
+func (ms *memStore) Load() ([]byte, error) {
+	return ms.data, ms.loadErr
+}
+
+// Save implements Store.Save.
+func (ms *memStore) Save(data []byte) error {
+	if ms.saveErr != nil {
+		return ms.saveErr
+	}
+	ms.data = data
+	return nil
+}
+
+// newCont returns a container split into the "left" and "right" containers.
+func newCont(t *testing.T) *container.Container {
+	t.Helper()
+	c, err := container.New(
+		faketerm.MustNew(image.Point{20, 4}),
+		container.ID("root"),
+		container.SplitVertical(
+			container.Left(container.ID("left")),
+			container.Right(container.ID("right")),
+		),
+	)
+	if err != nil {
+		t.Fatalf("container.New => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	return c
+}
+
+// mustArrangement returns the arrangement of the container.
+func mustArrangement(t *testing.T, c *container.Container) *container.Arrangement {
+	t.Helper()
+	a, err := c.Arrangement()
+	if err != nil {
+		t.Fatalf("Arrangement => unexpected error: %v", err)
+	}
+	return a
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		desc      string
+		store     Store
+		wantSaved []int
+		wantErr   bool
+	}{
+		{
+			desc: "works without a store",
+		},
+		{
+			desc:  "works with an empty store",
+			store: &memStore{},
+		},
+		{
+			desc:      "loads the saved slots",
+			store:     &memStore{data: []byte(`{"slots": {"3": {"maximized": "left"}, "1": {}}}`)},
+			wantSaved: []int{1, 3},
+		},
+		{
+			desc:    "fails when the store fails",
+			store:   &memStore{loadErr: errors.New("load error")},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on invalid data",
+			store:   &memStore{data: []byte(`{"slots": [}`)},
+			wantErr: true,
+		},
+		{
+			desc:    "fails on an invalid slot number",
+			store:   &memStore{data: []byte(`{"slots": {"0": {}}}`)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := New(tc.store)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New => unexpected error: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := pretty.Compare(tc.wantSaved, s.Saved()); diff != "" {
+				t.Errorf("Saved => unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSlots(t *testing.T) {
+	store := &memStore{}
+	s, err := New(store)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	c := newCont(t)
+
+	if err := s.Save(0, c); err == nil {
+		t.Errorf("Save => got nil error, want one for an invalid slot")
+	}
+	if err := s.Restore(-1, c); err == nil {
+		t.Errorf("Restore => got nil error, want one for an invalid slot")
+	}
+	if err := s.Delete(0); err == nil {
+		t.Errorf("Delete => got nil error, want one for an invalid slot")
+	}
+
+	saved := mustArrangement(t, c)
+	if err := s.Save(1, c); err != nil {
+		t.Fatalf("Save => unexpected error: %v", err)
+	}
+	if err := c.Maximize("right"); err != nil {
+		t.Fatalf("Maximize => unexpected error: %v", err)
+	}
+	if err := s.Save(2, c); err != nil {
+		t.Fatalf("Save => unexpected error: %v", err)
+	}
+
+	// Restoring an empty slot does nothing.
+	if err := s.Restore(3, c); err != nil {
+		t.Fatalf("Restore => unexpected error: %v", err)
+	}
+	if id, ok := c.Maximized(); !ok || id != "right" {
+		t.Errorf("Maximized => %q, %v, want %q, true", id, ok, "right")
+	}
+
+	if err := s.Restore(1, c); err != nil {
+		t.Fatalf("Restore => unexpected error: %v", err)
+	}
+	if err := c.Draw(); err != nil {
+		t.Fatalf("Draw => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare(saved, mustArrangement(t, c)); diff != "" {
+		t.Errorf("Arrangement => unexpected diff (-want, +got):\n%s", diff)
+	}
+
+	// The slots are persisted in the store.
+	loaded, err := New(store)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare([]int{1, 2}, loaded.Saved()); diff != "" {
+		t.Errorf("Saved => unexpected diff (-want, +got):\n%s", diff)
+	}
+	if err := loaded.Restore(2, c); err != nil {
+		t.Fatalf("Restore => unexpected error: %v", err)
+	}
+	if id, ok := c.Maximized(); !ok || id != "right" {
+		t.Errorf("Maximized => %q, %v, want %q, true", id, ok, "right")
+	}
+
+	if err := s.Delete(2); err != nil {
+		t.Fatalf("Delete => unexpected error: %v", err)
+	}
+	loaded, err = New(store)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare([]int{1}, loaded.Saved()); diff != "" {
+		t.Errorf("Saved => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSlotsStoreFails(t *testing.T) {
+	store := &memStore{}
+	s, err := New(store)
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	c := newCont(t)
+	if err := s.Save(1, c); err != nil {
+		t.Fatalf("Save => unexpected error: %v", err)
+	}
+
+	store.saveErr = errors.New("disk full")
+	if err := s.Save(2, c); err == nil {
+		t.Errorf("Save => got nil error, want one when the store fails")
+	}
+	if err := s.Delete(1); err == nil {
+		t.Errorf("Delete => got nil error, want one when the store fails")
+	}
+	// The slots are the same as in the store.
+	if diff := pretty.Compare([]int{1}, s.Saved()); diff != "" {
+		t.Errorf("Saved => unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir => unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "slots.json")
+
+	s, err := New(File(path))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if got := s.Saved(); len(got) != 0 {
+		t.Errorf("Saved => %v, want no slots before the file exists", got)
+	}
+	if err := s.Save(4, newCont(t)); err != nil {
+		t.Fatalf("Save => unexpected error: %v", err)
+	}
+
+	loaded, err := New(File(path))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare([]int{4}, loaded.Saved()); diff != "" {
+		t.Errorf("Saved => unexpected diff (-want, +got):\n%s", diff)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("os.Stat => %v, want the temporary file to be renamed", err)
+	}
+
+	// A file that cannot be written doesn't replace the saved slots.
+	if err := os.Mkdir(path+".tmp", 0700); err != nil {
+		t.Fatalf("os.Mkdir => unexpected error: %v", err)
+	}
+	if err := loaded.Save(5, newCont(t)); err == nil {
+		t.Errorf("Save => got nil error, want one when the file cannot be written")
+	}
+	loaded, err = New(File(path))
+	if err != nil {
+		t.Fatalf("New => unexpected error: %v", err)
+	}
+	if diff := pretty.Compare([]int{4}, loaded.Saved()); diff != "" {
+		t.Errorf("Saved => unexpected diff (-want, +got):\n%s", diff)
+	}
+}